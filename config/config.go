@@ -2,27 +2,91 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Log      LogConfig      `yaml:"log"`
-	Auth     AuthConfig     `yaml:"auth"`
-	Network  NetworkConfig  `yaml:"network"`
-	Ethernet EthernetConfig `yaml:"ethernet"`
-	Web      WebConfig      `yaml:"web"`
-	Camera   CameraConfig   `yaml:"camera"`
+	Log      LogConfig        `yaml:"log"`
+	Auth     AuthConfig       `yaml:"auth"`
+	Network  NetworkConfig    `yaml:"network"`
+	Ethernet EthernetConfig   `yaml:"ethernet"`
+	Serial   SerialConfig     `yaml:"serial"`
+	Web      WebConfig        `yaml:"web"`
+	Camera   CameraConfig     `yaml:"camera"`
+	Health   HealthConfig     `yaml:"health"`
+	Alerts   AlertsConfig     `yaml:"alerts"`
+	Traffic  TrafficConfig    `yaml:"traffic"`
+	Mesh     MeshConfig       `yaml:"mesh"`
+	Metrics  MetricsConfig    `yaml:"metrics"`
+	Storage  StorageConfig    `yaml:"storage"`
+	Watchdog WatchdogConfig   `yaml:"watchdog"`
+	Control  ControlConfig    `yaml:"control"`
+	Params   ParamsConfig     `yaml:"params"`
+	Firmware FirmwareConfig   `yaml:"firmware"`
+	Schedule ScheduleConfig   `yaml:"schedule"`
+	Guided   GuidedConfig     `yaml:"guided"`
+	Drone    DroneConfig      `yaml:"drone"`
+	Features SubsystemsConfig `yaml:"features"`
+	Paths    PathsConfig      `yaml:"paths"`
+	Audit    AuditConfig      `yaml:"audit"`
+	Payload  PayloadConfig    `yaml:"payload"`
+	Sensors  SensorsConfig    `yaml:"sensors"`
+	Power    PowerConfig      `yaml:"power"`
+	Upload   UploadConfig     `yaml:"upload"`
+	Journal  JournalConfig    `yaml:"journal"`
+	Tiles    TilesConfig      `yaml:"tiles"`
+	Soak     SoakConfig       `yaml:"soak"`
+	Files    FilesConfig      `yaml:"files"`
+	Tokens   TokensConfig     `yaml:"tokens"`
+
+	Forwarding   ForwardingConfig   `yaml:"forwarding"`
+	Discovery    DiscoveryConfig    `yaml:"discovery"`
+	Maintenance  MaintenanceConfig  `yaml:"maintenance"`
+	Battery      BatteryConfig      `yaml:"battery"`
+	Deadman      DeadmanConfig      `yaml:"deadman"`
+	Safety       SafetyConfig       `yaml:"safety"`
+	Signing      SigningConfig      `yaml:"signing"`
+	Entitlements EntitlementsConfig `yaml:"entitlements"`
+}
+
+// SubsystemsConfig switches whole subsystems on or off at startup. Every
+// subsystem is enabled unless the config explicitly sets it to false, so a
+// telemetry-only relay can skip the camera, MediaMTX and cloud dependencies.
+type SubsystemsConfig struct {
+	Camera  bool `yaml:"camera"`  // Camera manager and capture devices
+	Video   bool `yaml:"video"`   // Video streaming to MediaMTX (requires camera)
+	Web     bool `yaml:"web"`     // Local web dashboard and REST API
+	Auth    bool `yaml:"auth"`    // Cloud authentication and session forwarding
+	Landing bool `yaml:"landing"` // Landing-pad detection in the video pipeline (requires video)
+}
+
+// PathsConfig contains writable locations. Point them at mounted volumes when
+// running in a container so secrets and state survive image upgrades.
+type PathsConfig struct {
+	DataDir   string `yaml:"data_dir"`   // Base for relative state files (default: working directory, /data in container mode)
+	SecretDir string `yaml:"secret_dir"` // Directory holding .drone_secret_<uuid> files (default: data_dir)
+	LogFile   string `yaml:"log_file"`   // Also append logs to this file (empty = stdout only)
+}
+
+// AuditConfig contains the audit log used for usage reports
+type AuditConfig struct {
+	File string `yaml:"file"` // JSON-lines audit log (default: .drone_audit.jsonl)
 }
 
 // LogConfig contains logging settings
 type LogConfig struct {
-	Level           string `yaml:"level"`            // debug, info, warn, error
-	Verbose         bool   `yaml:"verbose"`          // Enable verbose parsing of received messages
-	TimestampFormat string `yaml:"timestamp_format"` // "time" or "unix"
-	StatsInterval   int    `yaml:"stats_interval"`   // Interval in seconds for printing stats (default: 30)
+	Level           string   `yaml:"level"`            // debug, info, warn, error
+	Verbose         bool     `yaml:"verbose"`          // Enable verbose parsing of received messages
+	VerboseMessages []string `yaml:"verbose_messages"` // Only parse these types (e.g. HEARTBEAT), in both directions
+	TimestampFormat string   `yaml:"timestamp_format"` // "time" or "unix"
+	StatsInterval   int      `yaml:"stats_interval"`   // Interval in seconds for printing stats (default: 30)
 }
 
 // EthernetConfig contains ethernet interface settings for Pixhawk connection
@@ -35,6 +99,29 @@ type EthernetConfig struct {
 	Subnet                   string `yaml:"subnet"`                     // Subnet mask (e.g., "24" for /24)
 	AllowMissingPixhawk      bool   `yaml:"allow_missing_pixhawk"`      // DEBUG: Allow auth without Pixhawk connection (for testing)
 	PixhawkConnectionTimeout int    `yaml:"pixhawk_connection_timeout"` // Timeout in seconds to wait for Pixhawk connection (default: 30s)
+	Hotplug                  bool   `yaml:"hotplug"`                    // Add/remove the broadcast endpoint when the ethernet link comes up/goes down after startup
+
+	DiscoveryCandidates []DiscoveryCandidate `yaml:"discovery_candidates"` // Extra networks probed in parallel during Pixhawk discovery
+
+	PinFC   bool   `yaml:"pin_fc"`   // Bind to the first FC by system ID and autopilot UID; refuse other FCs until re-paired
+	PinFile string `yaml:"pin_file"` // Pinned FC identity (default: .drone_fc_pin)
+}
+
+// SerialConfig connects to the Pixhawk over a UART (e.g. TELEM2) instead of
+// UDP, so no mavlink-router is needed in front of the bridge
+type SerialConfig struct {
+	Enabled     bool   `yaml:"enabled"`      // Use the serial port instead of UDP discovery
+	Device      string `yaml:"device"`       // e.g. /dev/serial0, /dev/ttyTHS1, /dev/ttyUSB0, COM3
+	Baud        int    `yaml:"baud"`         // Must match SERIALx_BAUD on the FC (default: 921600)
+	FlowControl bool   `yaml:"flow_control"` // RTS/CTS hardware flow control (Linux only; BRD_SERx_RTSCTS on the FC)
+}
+
+// DiscoveryCandidate is an additional place to look for the Pixhawk during
+// discovery: a subnet to broadcast on, or a host (e.g. mavlink-router) to probe
+type DiscoveryCandidate struct {
+	BroadcastIP string `yaml:"broadcast_ip"` // Subnet broadcast address, e.g. 192.168.144.255 (needs a local interface on that subnet)
+	Host        string `yaml:"host"`         // Unicast host instead of a broadcast, e.g. a mavlink-router UDP server
+	Port        int    `yaml:"port"`         // Destination port (default: network.local_listen_port)
 }
 
 // AuthConfig contains authentication settings
@@ -44,23 +131,133 @@ type AuthConfig struct {
 	Port         int    `yaml:"port"`
 	UUID         string `yaml:"uuid"`          // Drone UUID from drones_v2.id
 	SharedSecret string `yaml:"shared_secret"` // Shared secret for registration (REPLACES Secret)
-	// Secret field removed - secret key is now stored in .drone_secret file
-	KeepaliveInterval         int     `yaml:"keepalive_interval"`          // seconds
-	SessionHeartbeatFrequency float64 `yaml:"session_heartbeat_frequency"` // Hz
+	// Secret field removed - secret key is now stored in the .drone_secret_<uuid> file
+	KeepaliveInterval         int       `yaml:"keepalive_interval"`          // seconds
+	SessionHeartbeatFrequency float64   `yaml:"session_heartbeat_frequency"` // Hz
+	SessionAckWait            int       `yaml:"session_ack_wait"`            // seconds to forward only priority messages until the router acknowledges (default 10)
+	SessionHeartbeatID        uint32    `yaml:"session_heartbeat_id"`        // SESSION_HEARTBEAT message ID (default 42999, the router may assign another)
+	SessionHeartbeatAckID     uint32    `yaml:"session_heartbeat_ack_id"`    // SESSION_HEARTBEAT_ACK message ID (default 42998)
+	SessionHeartbeatLayout    int       `yaml:"session_heartbeat_layout"`    // SESSION_HEARTBEAT field layout: 1 (default) or 2 (64-bit expiry)
+	Mode                      string    `yaml:"mode"`                        // "hmac" (default) or "mtls"
+	TLS                       TLSConfig `yaml:"tls"`                         // Client certificate settings for mtls mode
+	StartupJitter             int       `yaml:"startup_jitter"`              // seconds, max random delay before first AUTH (default 5)
+	MaxReauthPerMinute        int       `yaml:"max_reauth_per_minute"`       // Re-auth cap per drone (default 4)
+	APIKeyCacheTTL            int       `yaml:"api_key_cache_ttl"`           // seconds an API key status is served from cache (default 30)
+	PolicyReportInterval      int       `yaml:"policy_report_interval"`      // seconds between POLICY_REPORTs, changes are sent at once (default 300, -1 = off)
+	Trace                     bool      `yaml:"trace"`                       // Log each handshake step with timing, sizes and error codes (no secrets)
+}
+
+// TLSConfig contains client certificate settings for mtls auth mode
+type TLSConfig struct {
+	CertFile   string `yaml:"cert_file"`   // PEM client certificate
+	KeyFile    string `yaml:"key_file"`    // PEM private key
+	CAFile     string `yaml:"ca_file"`     // PEM CA bundle for the router (empty = system roots)
+	ServerName string `yaml:"server_name"` // Expected router certificate name (empty = auth.host)
 }
 
 // NetworkConfig contains network settings
 type NetworkConfig struct {
-	LocalListenPort int    `yaml:"local_listen_port"`
-	BroadcastPort   int    `yaml:"broadcast_port"`
-	TargetHost      string `yaml:"target_host"`
-	TargetPort      int    `yaml:"target_port"`
-	Protocol        string `yaml:"protocol"`
+	LocalListenPort int        `yaml:"local_listen_port"`
+	BroadcastPort   int        `yaml:"broadcast_port"`
+	TargetHost      string     `yaml:"target_host"`
+	TargetPort      int        `yaml:"target_port"`
+	Protocol        string     `yaml:"protocol"` // Uplink transport: "udp" (default) or "quic"
+	QUIC            QUICConfig `yaml:"quic"`     // TLS settings when protocol is "quic"
+
+	// Servers lists fallback routers; when set it replaces target_host/target_port
+	// and the uplink fails over to the next server when the current one goes quiet
+	Servers          []ServerConfig `yaml:"servers"`
+	FailoverTimeout  int            `yaml:"failover_timeout"`  // Seconds without traffic from the server before failing over (default: 15)
+	FailbackInterval int            `yaml:"failback_interval"` // Seconds between probes of preferred servers while on a backup (default: 60)
+}
+
+// QUICConfig contains the TLS settings of the QUIC uplink. The router must accept
+// QUIC with datagrams (ALPN "dronebridge-mavlink") on the target port.
+type QUICConfig struct {
+	ServerName string `yaml:"server_name"` // Expected router certificate name (empty = the router host)
+	CAFile     string `yaml:"ca_file"`     // PEM CA bundle for the router (empty = system roots)
+	CertFile   string `yaml:"cert_file"`   // Optional PEM client certificate
+	KeyFile    string `yaml:"key_file"`    // Its private key
+}
+
+// ServerConfig is one upstream router in network.servers
+type ServerConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Priority int    `yaml:"priority"` // Lower is preferred; equal priorities keep list order
+}
+
+// ForwardingConfig contains the named policies that decide which messages reach the server
+type ForwardingConfig struct {
+	Policy   string              `yaml:"policy"`   // Active policy name: minimal, standard, full or a custom name (default: full)
+	Policies map[string][]uint32 `yaml:"policies"` // Custom/overridden policies: name -> allowed message IDs ("full" is reserved)
+	Batching BatchingConfig      `yaml:"batching"` // Compressed batching of low-priority telemetry
+
+	WriteQueueSize int                `yaml:"write_queue_size"` // Pending writes per direction before new ones are dropped (default: 256)
+	StaleCommand   StaleCommandConfig `yaml:"stale_command"`    // Age check for commands relayed to the FC
+	PassUnknown    bool               `yaml:"pass_unknown"`     // Forward message IDs outside the dialect as raw frames (default: true)
+	LocalParams    LocalParamsConfig  `yaml:"local_params"`     // Uplink handling of replies to the dashboard's parameter requests
+
+	Filters    []FilterRuleConfig           `yaml:"filters"`    // Uplink filter table, first matching rule wins
+	Profiles   map[string]RateProfileConfig `yaml:"profiles"`   // Per-consumer downsampling: "cloud", "websocket"
+	Transforms []TransformConfig            `yaml:"transforms"` // Message transformations applied in order
+	Privacy    PrivacyConfig                `yaml:"privacy"`    // Position fuzzing for the cloud (toggle at runtime via /api/privacy)
+}
+
+// PrivacyConfig degrades the position sent to the cloud, e.g. for customer sites
+// that must not be located precisely. Local consumers keep full precision.
+type PrivacyConfig struct {
+	Enabled    bool    `yaml:"enabled"`     // Start in privacy mode
+	PrecisionM float64 `yaml:"precision_m"` // Grid coordinates are rounded to (default: 100)
+}
+
+// TransformConfig modifies FC messages in flight for some consumers
+type TransformConfig struct {
+	Type      string   `yaml:"type"`      // altitude_offset, heading_offset or redact_home
+	Value     float64  `yaml:"value"`     // Meters, degrees or grid size in meters (redact_home, 0 = drop)
+	Consumers []string `yaml:"consumers"` // "cloud" and/or "websocket" (default: cloud)
+}
+
+// FilterRuleConfig is one row of the uplink filter table
+type FilterRuleConfig struct {
+	SysID    int      `yaml:"sysid"`    // Source system ID (0 = any)
+	Messages []uint32 `yaml:"messages"` // Message IDs (empty = any)
+	Action   string   `yaml:"action"`   // "allow" (default) or "deny"
+	MaxHz    float64  `yaml:"max_hz"`   // Rate cap for allow rules (0 = unlimited)
+}
+
+// RateProfileConfig caps the telemetry rates one consumer receives
+type RateProfileConfig struct {
+	DefaultHz float64            `yaml:"default_hz"` // Cap for messages not listed in rates (0 = unlimited)
+	Rates     map[uint32]float64 `yaml:"rates"`      // Message ID -> max Hz (0 = unlimited)
+}
+
+// LocalParamsConfig controls PARAM_VALUE replies to locally originated parameter
+// requests (dashboard, /ws/mavlink clients). A concurrent cloud request always gets its replies.
+type LocalParamsConfig struct {
+	Action string `yaml:"action"` // "forward" (default), "drop" or "limit"
+	Rate   int    `yaml:"rate"`   // PARAM_VALUE per second forwarded with "limit" (default: 10)
+}
+
+// StaleCommandConfig controls flagging (or, opt-in, dropping) of command-class messages delayed before reaching the FC
+type StaleCommandConfig struct {
+	MaxAge int    `yaml:"max_age"` // Maximum age in ms since the bridge received the command (default: 2000)
+	Action string `yaml:"action"`  // "flag" (default: forward and count), "drop" (opt-in) or "off"
+}
+
+// BatchingConfig controls aggregation+compression of high-rate, low-priority messages.
+// Batching only activates when the router agrees on a compression algorithm.
+type BatchingConfig struct {
+	Enabled   bool     `yaml:"enabled"`
+	MaxFrames int      `yaml:"max_frames"` // Frames per datagram before flushing (default: 20)
+	MaxDelay  int      `yaml:"max_delay"`  // Maximum time a frame waits in a batch in ms (default: 500)
+	Messages  []uint32 `yaml:"messages"`   // Message IDs eligible for batching (default: IMU/RC/servo/pressure/vibration)
 }
 
 // WebConfig contains web server settings
 type WebConfig struct {
-	Port int `yaml:"port"`
+	Port             int      `yaml:"port"`
+	WSAllowedOrigins []string `yaml:"ws_allowed_origins"` // Browser origins besides the dashboard's own that may open /ws/mavlink
 }
 
 // CameraConfig contains camera streaming settings
@@ -71,6 +268,10 @@ type CameraConfig struct {
 	Framerate  int              `yaml:"framerate"`
 	Format     string           `yaml:"format"`
 	MediaMTX   MediaMTXConfig   `yaml:"mediamtx"`
+	Output     string           `yaml:"output"`    // rtsp (default) or srt
+	OnDemand   bool             `yaml:"on_demand"` // Stream only while the router requests it (VIDEO_CONTROL)
+	SRT        SRTConfig        `yaml:"srt"`
+	Audio      AudioConfig      `yaml:"audio"`
 	Encoder    EncoderConfig    `yaml:"encoder"`
 	Features   FeaturesConfig   `yaml:"features"`
 }
@@ -83,12 +284,38 @@ type CameraResolution struct {
 
 // MediaMTXConfig contains RTSP server settings
 type MediaMTXConfig struct {
-	Host string `yaml:"host"`
-	Port int    `yaml:"port"`
+	Host         string `yaml:"host"`
+	Port         int    `yaml:"port"`
+	PathTemplate string `yaml:"path_template"` // Stream path; {uuid}, {org_id} and {drone_id} are substituted (default: "{uuid}")
+	OrgID        string `yaml:"org_id"`        // Organisation ID for {org_id}
+	PublishAuth  string `yaml:"publish_auth"`  // none (default), password or apikey (API key as password)
+	Username     string `yaml:"username"`      // Publish user (apikey mode defaults to the drone UUID)
+	Password     string `yaml:"password"`      // Publish password for password mode
+}
+
+// SRTConfig contains SRT publisher settings (camera.output: srt)
+type SRTConfig struct {
+	Host       string `yaml:"host"`       // SRT listener (default: camera.mediamtx.host)
+	Port       int    `yaml:"port"`       // SRT port (default: 8890)
+	Latency    int    `yaml:"latency"`    // Receiver buffer / ARQ window in ms (default: 500)
+	Passphrase string `yaml:"passphrase"` // AES passphrase, 10-79 characters (empty = unencrypted)
+	KeyLength  int    `yaml:"key_length"` // AES key length in bytes: 16, 24 or 32 (default: 16)
+}
+
+// AudioConfig contains optional audio capture settings
+type AudioConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Source     string `yaml:"source"`      // alsa (default) or pulse
+	Device     string `yaml:"device"`      // Capture device, e.g. "hw:1,0" (empty = default)
+	SampleRate int    `yaml:"sample_rate"` // Hz (default: 48000)
+	Channels   int    `yaml:"channels"`    // 1 or 2 (default: 1)
+	Codec      string `yaml:"codec"`       // opus (default) or aac
+	Bitrate    int    `yaml:"bitrate"`     // kbps (default: 64)
 }
 
 // EncoderConfig contains H.264 encoding settings
 type EncoderConfig struct {
+	Type             string `yaml:"type"` // auto (default), x264, v4l2, omx, vaapi or nvenc
 	Bitrate          int    `yaml:"bitrate"`
 	Preset           string `yaml:"preset"`
 	Tune             string `yaml:"tune"`
@@ -101,6 +328,378 @@ type FeaturesConfig struct {
 	Detection bool `yaml:"detection"`
 }
 
+// HealthConfig contains flight controller health thresholds
+type HealthConfig struct {
+	VibrationWarn     float64 `yaml:"vibration_warn"`     // Vibration warning level in m/s/s (default: 30)
+	VibrationCritical float64 `yaml:"vibration_critical"` // Vibration critical level in m/s/s (default: 60)
+	EKFVarianceWarn   float64 `yaml:"ekf_variance_warn"`  // EKF variance warning level (default: 0.8)
+}
+
+// MeshConfig contains position exchange with other bridges on the local network
+type MeshConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	Port         int    `yaml:"port"`          // UDP port shared by all bridges (default: 14600)
+	BroadcastIP  string `yaml:"broadcast_ip"`  // Destination address (default: 255.255.255.255)
+	Key          string `yaml:"key"`           // Shared fleet key used to sign reports (required when enabled)
+	Interval     int    `yaml:"interval"`      // Broadcast interval in milliseconds (default: 1000)
+	StaleTimeout int    `yaml:"stale_timeout"` // Drop peers not heard for this many seconds (default: 10)
+}
+
+// DiscoveryConfig contains the LAN beacon ground tools use to enumerate drones
+type DiscoveryConfig struct {
+	Enabled     bool   `yaml:"enabled"`      // Also toggled at runtime via POST /api/discovery
+	Port        int    `yaml:"port"`         // UDP port ground tools listen on (default: 14650)
+	BroadcastIP string `yaml:"broadcast_ip"` // Destination address (default: 255.255.255.255)
+	Interval    int    `yaml:"interval"`     // Beacon interval in seconds (default: 5)
+}
+
+// MaintenanceConfig contains service reminders driven by accumulated armed time (see /api/maintenance)
+type MaintenanceConfig struct {
+	File      string                      `yaml:"file"`      // Service log and counters (default: .drone_maintenance)
+	Reminders []MaintenanceReminderConfig `yaml:"reminders"` // Items to track; any item name may be logged
+
+	// Maintenance mode (POST /api/maintenance/enter): cloud forwarding paused, dead-man suspended
+	ModeMinutes    int `yaml:"mode_minutes"`     // Duration when the request names none (default: 30)
+	ModeMaxMinutes int `yaml:"mode_max_minutes"` // Longest allowed before it must be entered again (default: 240)
+}
+
+// MaintenanceReminderConfig is the service interval of one item; whichever limit is reached first is due
+type MaintenanceReminderConfig struct {
+	Item    string  `yaml:"item"`    // e.g. airframe, motors, props, battery
+	Hours   float64 `yaml:"hours"`   // Flight hours since last service (0 = no limit)
+	Flights int     `yaml:"flights"` // Flights (arm cycles) since last service (0 = no limit)
+}
+
+// BatteryConfig contains battery pack tracking settings (see /api/batteries)
+type BatteryConfig struct {
+	File      string  `yaml:"file"`       // Pack history (default: .drone_batteries)
+	SagFactor float64 `yaml:"sag_factor"` // Flag a pack whose takeoff sag exceeds its average by this factor (default: 1.5)
+}
+
+// DeadmanConfig contains the dead-man uplink watchdog: actions run once when neither a
+// session refresh nor any server traffic succeeded for Timeout while armed
+type DeadmanConfig struct {
+	Enabled   bool     `yaml:"enabled"`
+	Timeout   int      `yaml:"timeout"`    // seconds without uplink (default: 180)
+	Actions   []string `yaml:"actions"`    // In order: statustext, rtl, record (default: statustext)
+	RecordDir string   `yaml:"record_dir"` // Local recordings for "record" (relative to storage.data_dir, default: dvr)
+}
+
+// SafetyConfig contains in-flight safety interlocks
+type SafetyConfig struct {
+	FreezeParamsInFlight bool     `yaml:"freeze_params_in_flight"` // Reject PARAM_SET from the web and the server while armed
+	ParamAllowlist       []string `yaml:"param_allowlist"`         // Parameters that may still be changed in flight
+}
+
+// SigningConfig contains MAVLink 2 message signing of the forwarded traffic
+type SigningConfig struct {
+	Enabled         bool   `yaml:"enabled"`          // Sign frames to the server and verify frames from it
+	KeyFile         string `yaml:"key_file"`         // Hex secret key, provisioned by the router (default: .drone_signing_key)
+	LinkID          int    `yaml:"link_id"`          // Signature link ID of our frames (0-255)
+	TimestampWindow int    `yaml:"timestamp_window"` // Seconds an incoming timestamp may be off our clock (default: 60, -1 = replay check only)
+	RequireIncoming bool   `yaml:"require_incoming"` // Reject unsigned frames from the server
+	SignToFC        bool   `yaml:"sign_to_fc"`       // Also sign frames to the FC (needs the key set up on the FC)
+}
+
+// EntitlementsConfig gates premium subsystems (video, precision_landing, relay)
+// on the signed feature list the fleet server issues for this drone
+type EntitlementsConfig struct {
+	File string `yaml:"file"` // Offline cache of the last signed list (default: .drone_entitlements)
+}
+
+// PayloadConfig contains companion GPIO/PWM outputs exposed at /api/payload/<name>
+type PayloadConfig struct {
+	Actions []PayloadActionConfig `yaml:"actions"`
+}
+
+// PayloadActionConfig is one named payload output (drop release, light, relay, ...)
+type PayloadActionConfig struct {
+	Name      string                 `yaml:"name"`        // Used in /api/payload/<name>
+	Type      string                 `yaml:"type"`        // gpio or pwm (default: gpio)
+	Driver    string                 `yaml:"driver"`      // gpio only: sysfs or gpiochip (default: sysfs)
+	Chip      string                 `yaml:"chip"`        // gpiochip name (e.g. gpiochip0) or PWM chip number
+	Pin       int                    `yaml:"pin"`         // GPIO number/line or PWM channel
+	ActiveLow bool                   `yaml:"active_low"`  // gpio only: drive low for "on"
+	PulseMs   int                    `yaml:"pulse_ms"`    // On time of a "trigger" command (0 = trigger not allowed)
+	PeriodUs  int                    `yaml:"period_us"`   // pwm only: period in microseconds (default: 20000)
+	OnDutyUs  int                    `yaml:"on_duty_us"`  // pwm only: pulse width for "on" (e.g. servo open)
+	OffDutyUs int                    `yaml:"off_duty_us"` // pwm only: pulse width for "off" (e.g. servo closed)
+	Interlock PayloadInterlockConfig `yaml:"interlock"`
+}
+
+// PayloadInterlockConfig restricts when an action may be switched on. Every
+// action declares one; "interlock: none" lets it switch at any time.
+type PayloadInterlockConfig struct {
+	None            bool    `yaml:"-"` // Declared as "interlock: none"
+	RequireArmed    bool    `yaml:"require_armed"`
+	RequireDisarmed bool    `yaml:"require_disarmed"`
+	MinAltitude     float64 `yaml:"min_altitude"` // Meters above home (0 = no limit)
+	MaxAltitude     float64 `yaml:"max_altitude"` // Meters above home (0 = no limit)
+}
+
+// UnmarshalYAML accepts "none" in place of the mapping
+func (il *PayloadInterlockConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if node.Value != "none" {
+			return fmt.Errorf("line %d: interlock must be a mapping or \"none\", got %q", node.Line, node.Value)
+		}
+		*il = PayloadInterlockConfig{None: true}
+		return nil
+	}
+	type plain PayloadInterlockConfig // Without this method
+	return node.Decode((*plain)(il))
+}
+
+// Declared reports whether the interlock restricts anything or is explicitly none
+func (il PayloadInterlockConfig) Declared() bool {
+	return il.None || il.RequireArmed || il.RequireDisarmed || il.MinAltitude > 0 || il.MaxAltitude > 0
+}
+
+// SensorsConfig contains external sensors published as MAVLink (GET /api/sensors)
+type SensorsConfig struct {
+	Inputs []SensorInputConfig `yaml:"inputs"`
+}
+
+// SensorInputConfig is one external sensor read over I2C or a serial port
+type SensorInputConfig struct {
+	Name        string  `yaml:"name"`         // NAMED_VALUE_FLOAT name (max 10 characters for kind value)
+	Kind        string  `yaml:"kind"`         // distance (DISTANCE_SENSOR) or value (NAMED_VALUE_FLOAT)
+	Bus         string  `yaml:"bus"`          // i2c or serial
+	Device      string  `yaml:"device"`       // e.g. /dev/i2c-1 or /dev/ttyUSB0
+	Address     int     `yaml:"address"`      // i2c: 7-bit device address
+	Register    int     `yaml:"register"`     // i2c: register holding the reading
+	Length      int     `yaml:"length"`       // i2c: register size in bytes, 1, 2 or 4 (default: 2)
+	Baud        int     `yaml:"baud"`         // serial: baud rate (0 = keep port settings)
+	Scale       float64 `yaml:"scale"`        // value = raw * scale + offset (default: 1)
+	Offset      float64 `yaml:"offset"`       // Added after scaling
+	Interval    int     `yaml:"interval"`     // Poll interval in milliseconds (default: 200)
+	ToFC        bool    `yaml:"to_fc"`        // Inject into the flight controller link
+	ToServer    bool    `yaml:"to_server"`    // Send with the telemetry stream to the router
+	ID          int     `yaml:"id"`           // distance: DISTANCE_SENSOR id
+	Orientation string  `yaml:"orientation"`  // distance: down, up, forward, back, left or right (default: down)
+	SensorType  string  `yaml:"sensor_type"`  // distance: laser, ultrasound, infrared, radar or unknown (default: laser)
+	MinDistance float64 `yaml:"min_distance"` // distance: meters
+	MaxDistance float64 `yaml:"max_distance"` // distance: meters
+}
+
+// PowerConfig watches the companion computer's supply voltage (UPS HAT or ADC)
+// and shuts the OS down cleanly before the supply collapses
+type PowerConfig struct {
+	Enabled           bool     `yaml:"enabled"`
+	Bus               string   `yaml:"bus"`                 // i2c, serial or file (a sysfs value such as hwmon in1_input)
+	Device            string   `yaml:"device"`              // e.g. /dev/i2c-1, /dev/ttyUSB0 or /sys/class/hwmon/hwmon0/in1_input
+	Address           int      `yaml:"address"`             // i2c: 7-bit device address
+	Register          int      `yaml:"register"`            // i2c: register holding the reading
+	Length            int      `yaml:"length"`              // i2c: register size in bytes, 1, 2 or 4 (default: 2)
+	Baud              int      `yaml:"baud"`                // serial: baud rate (0 = keep port settings)
+	Scale             float64  `yaml:"scale"`               // volts = raw * scale + offset (default: 1)
+	Offset            float64  `yaml:"offset"`              // Added after scaling
+	Interval          int      `yaml:"interval"`            // Poll interval in milliseconds (default: 1000)
+	MinVoltage        float64  `yaml:"min_voltage"`         // Shut down below this supply voltage (V)
+	Samples           int      `yaml:"samples"`             // Consecutive low readings before shutting down (default: 5)
+	ShutdownWhenArmed bool     `yaml:"shutdown_when_armed"` // Also shut down in flight (default: hold until disarmed)
+	ShutdownCommand   []string `yaml:"shutdown_command"`    // Run after the bridge stopped (default: systemctl poweroff)
+}
+
+// AlertsConfig contains alert delivery settings
+type AlertsConfig struct {
+	WebhookURL string `yaml:"webhook_url"` // URL to POST alerts to as JSON (empty = disabled)
+}
+
+// TrafficConfig contains ADS-B traffic separation limits
+type TrafficConfig struct {
+	HorizontalLimit float64 `yaml:"horizontal_limit"` // Minimum horizontal separation at CPA in meters (default: 500)
+	VerticalLimit   float64 `yaml:"vertical_limit"`   // Minimum vertical separation at CPA in meters (default: 100)
+	LookaheadSec    float64 `yaml:"lookahead"`        // CPA lookahead horizon in seconds (default: 60)
+	StaleTimeout    int     `yaml:"stale_timeout"`    // Drop contacts not seen for this many seconds (default: 30)
+}
+
+// MetricsConfig contains counter persistence settings
+type MetricsConfig struct {
+	CheckpointFile     string `yaml:"checkpoint_file"`     // Where counters are persisted across restarts (default: .drone_metrics)
+	CheckpointInterval int    `yaml:"checkpoint_interval"` // Checkpoint interval in seconds (default: 60)
+
+	RollupFile string `yaml:"rollup_file"` // Daily/per-flight statistics rollups (default: .drone_rollup)
+	RollupDays int    `yaml:"rollup_days"` // Days of daily rollups kept (default: 400)
+}
+
+// StorageConfig contains disk-space guard and pruning settings
+type StorageConfig struct {
+	DataDir       string               `yaml:"data_dir"`       // Data partition to monitor (default: ".")
+	MinFreeMB     int                  `yaml:"min_free_mb"`    // Refuse new recordings below this free space (default: 200)
+	TargetFreeMB  int                  `yaml:"target_free_mb"` // Prune until this much space is free (default: 500)
+	CheckInterval int                  `yaml:"check_interval"` // Check interval in seconds (default: 60)
+	Classes       []StorageClassConfig `yaml:"classes"`        // Prunable file classes
+}
+
+// StorageClassConfig describes one class of prunable files
+type StorageClassConfig struct {
+	Name          string `yaml:"name"`
+	Dir           string `yaml:"dir"`            // Directory (relative to data_dir unless absolute)
+	Pattern       string `yaml:"pattern"`        // File name glob (e.g. "*.tlog")
+	Priority      int    `yaml:"priority"`       // Lower priority is pruned first
+	RetentionDays int    `yaml:"retention_days"` // Always delete files older than this (0 = only when space is low)
+}
+
+// validStorageClass keeps a prunable class to one file type in a directory
+// below dataDir, so pruning cannot reach the config, secrets or system files
+func validStorageClass(dataDir string, cl StorageClassConfig) error {
+	if cl.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if cl.Priority < 0 || cl.RetentionDays < 0 {
+		return fmt.Errorf("priority and retention_days must not be negative")
+	}
+	ext := filepath.Ext(cl.Pattern)
+	if strings.ContainsRune(cl.Pattern, '/') || len(ext) < 2 || strings.ContainsAny(ext, "*?[]\\") {
+		return fmt.Errorf("pattern must be a file name glob with a fixed extension (e.g. \"*.tlog\"), got %q", cl.Pattern)
+	}
+	root, err := filepath.Abs(dataDir)
+	if err != nil {
+		return err
+	}
+	dir := cl.Dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	if rel, err := filepath.Rel(root, filepath.Clean(dir)); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("dir %q must be a directory below storage.data_dir", cl.Dir)
+	}
+	return nil
+}
+
+// JournalConfig contains the flight data journal served by /api/export
+type JournalConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	Dir            string `yaml:"dir"`             // Daily YYYY-MM-DD.jsonl files (relative to storage.data_dir, default: journal)
+	SampleInterval int    `yaml:"sample_interval"` // Seconds between gps/battery samples (default: 1)
+}
+
+// TilesConfig contains the map tile cache served at /api/tiles/{z}/{x}/{y}.png
+type TilesConfig struct {
+	Enabled    bool            `yaml:"enabled"`
+	Dir        string          `yaml:"dir"`          // Cached tiles (relative to storage.data_dir, default: tiles)
+	Upstream   string          `yaml:"upstream"`     // Tile URL template with {z}, {x}, {y} (empty = serve cached tiles only)
+	UserAgent  string          `yaml:"user_agent"`   // Sent upstream (OSM requires an identifying User-Agent)
+	MaxAgeDays int             `yaml:"max_age_days"` // Refresh cached tiles older than this when online (0 = never)
+	MaxSeed    int             `yaml:"max_seed"`     // Maximum tiles one pre-seed may cover (default: 5000)
+	Seed       TilesSeedConfig `yaml:"seed"`
+}
+
+// TilesSeedConfig is an area whose tiles are fetched in the background at startup
+type TilesSeedConfig struct {
+	MinLat  float64 `yaml:"min_lat"`
+	MinLon  float64 `yaml:"min_lon"`
+	MaxLat  float64 `yaml:"max_lat"`
+	MaxLon  float64 `yaml:"max_lon"`
+	MinZoom int     `yaml:"min_zoom"`
+	MaxZoom int     `yaml:"max_zoom"` // 0 = no pre-seed
+}
+
+// SoakConfig contains the leak thresholds of the --soak stability test
+type SoakConfig struct {
+	Rate               int     `yaml:"rate"`                 // Simulated FC messages per second (default: 500)
+	ReconnectInterval  int     `yaml:"reconnect_interval"`   // Seconds between forced sender node recreations (default: 60, -1 = never)
+	SampleInterval     int     `yaml:"sample_interval"`      // Seconds between measurements (default: 30)
+	Warmup             int     `yaml:"warmup"`               // Seconds before the baseline is taken (default: 300)
+	MaxGoroutineGrowth int     `yaml:"max_goroutine_growth"` // Over baseline (default: 50)
+	MaxHeapGrowthMB    float64 `yaml:"max_heap_growth_mb"`   // Over baseline (default: 64)
+	MaxFDGrowth        int     `yaml:"max_fd_growth"`        // Over baseline, Linux only (default: 32)
+	MaxQueueDepth      int     `yaml:"max_queue_depth"`      // Pending writes per direction (0 = not checked)
+	ReportFile         string  `yaml:"report_file"`          // Default: logs/soak_report.json
+}
+
+// UploadConfig contains shipping of completed artifacts to S3-compatible storage
+type UploadConfig struct {
+	Enabled         bool     `yaml:"enabled"`
+	Endpoint        string   `yaml:"endpoint"`         // e.g. https://s3.eu-central-1.amazonaws.com or http://minio.local:9000
+	Region          string   `yaml:"region"`           // Signing region (default: us-east-1)
+	Bucket          string   `yaml:"bucket"`           // Objects are stored as <prefix><uuid>/<class>/<file>
+	AccessKey       string   `yaml:"access_key"`       // Access key ID
+	SecretKey       string   `yaml:"secret_key"`       // Secret access key
+	Prefix          string   `yaml:"prefix"`           // Object key prefix
+	Classes         []string `yaml:"classes"`          // storage.classes names to upload (empty = all)
+	DiagnosticsDir  string   `yaml:"diagnostics_dir"`  // upload_diagnostics also writes its report here (relative to storage.data_dir, default: diagnostics)
+	MaxKbps         int      `yaml:"max_kbps"`         // Bandwidth limit in kilobits/s (0 = unlimited)
+	WiFiInterface   string   `yaml:"wifi_interface"`   // Upload only while this interface is up (empty = any network)
+	RequireDisarmed bool     `yaml:"require_disarmed"` // Upload only while the FC reports disarmed (default: true)
+	SettleTime      int      `yaml:"settle_time"`      // Seconds a file must be unmodified to count as complete (default: 60)
+	Interval        int      `yaml:"interval"`         // Seconds between scans for new artifacts (default: 60)
+}
+
+// WatchdogConfig contains stuck event loop detection settings
+type WatchdogConfig struct {
+	StallTimeout int `yaml:"stall_timeout"` // Seconds without progress before a loop is restarted (default: 30)
+}
+
+// ControlConfig contains arbitration settings between a local GCS and cloud control
+type ControlConfig struct {
+	Arbitration string `yaml:"arbitration"` // local_priority (default), cloud_priority, takeover or last_writer
+	HoldTime    int    `yaml:"hold_time"`   // Seconds a source stays in control after its last control message (default: 3)
+
+	RCOverrideGuard bool   `yaml:"rc_override_guard"` // Block cloud MANUAL_CONTROL/RC_CHANNELS_OVERRIDE unless remote piloting is enabled
+	APIToken        string `yaml:"api_token"`         // Admin credential for /api/control only (optional, any admin token works)
+}
+
+// ParamsConfig contains flight controller parameter tooling settings
+type ParamsConfig struct {
+	ProfileDir string `yaml:"profile_dir"` // Directory for golden airframe profiles (default: profiles)
+}
+
+// FirmwareConfig contains FC firmware flashing settings
+type FirmwareConfig struct {
+	UploadDir  string `yaml:"upload_dir"`   // Where uploaded images are stored (default: firmware)
+	Uploader   string `yaml:"uploader"`     // Uploader command, {file} and {port} are substituted (empty = disabled)
+	SerialPort string `yaml:"serial_port"`  // FC bootloader serial port (e.g. /dev/ttyACM0)
+	MaxImageMB int    `yaml:"max_image_mb"` // Largest accepted upload (default: 16)
+}
+
+// FilesConfig contains the workspace file manager (/api/files), which replaces
+// SSH for routine updates of landing configs, templates and mission files
+type FilesConfig struct {
+	Root      string `yaml:"root"`        // Workspace directory (default: Find_landing)
+	APIToken  string `yaml:"api_token"`   // Admin credential for /api/files only (optional, any admin token works)
+	MaxFileMB int    `yaml:"max_file_mb"` // Largest single upload (default: 10)
+	QuotaMB   int    `yaml:"quota_mb"`    // Total workspace size uploads may reach (default: 200)
+}
+
+// TokensConfig contains role-scoped API tokens for machine integrations (/api/tokens)
+type TokensConfig struct {
+	File       string `yaml:"file"`        // Issued tokens, hashed (default: .drone_tokens)
+	AdminToken string `yaml:"admin_token"` // Bootstrap admin credential for creating the first tokens (empty = none)
+	Required   bool   `yaml:"required"`    // Reject API requests without a token (the dashboard then needs one too)
+}
+
+// ScheduleConfig contains scheduled maintenance tasks
+type ScheduleConfig struct {
+	DiagnosticsURL string                `yaml:"diagnostics_url"` // Where upload_diagnostics POSTs its report (empty = disabled)
+	Tasks          []ScheduledTaskConfig `yaml:"tasks"`
+}
+
+// ScheduledTaskConfig describes one scheduled task
+type ScheduledTaskConfig struct {
+	Name   string `yaml:"name"`
+	Action string `yaml:"action"` // renew_api_key, rotate_logs, upload_diagnostics, preflight_selftest or restart_camera
+	Every  int    `yaml:"every"`  // Interval in seconds (0 = use at)
+	At     string `yaml:"at"`     // Daily local time "HH:MM"
+}
+
+// GuidedConfig contains safety limits for guided-mode commands from the web API
+type GuidedConfig struct {
+	MaxDistance float64 `yaml:"max_distance"` // Max meters from the current position per goto (default: 1000)
+	MaxAltitude float64 `yaml:"max_altitude"` // Max meters above home (default: 120)
+	MaxSpeed    float64 `yaml:"max_speed"`    // Max requested ground speed in m/s (default: 15)
+}
+
+// DroneConfig contains human-readable identity metadata
+type DroneConfig struct {
+	Name         string `yaml:"name"`          // Friendly name shown instead of the UUID
+	Airframe     string `yaml:"airframe"`      // Airframe type (e.g. "quad-x 450")
+	Operator     string `yaml:"operator"`      // Operating organisation or pilot
+	TailNumber   string `yaml:"tail_number"`   // Registration / tail number
+	MetadataFile string `yaml:"metadata_file"` // Local file for values edited via /api/identity (default: .drone_identity)
+}
+
 // FrequencyConfig contains message sending frequencies in Hz
 type FrequencyConfig struct {
 	Heartbeat      float64 `yaml:"heartbeat"`
@@ -177,11 +776,40 @@ func Load(filename string) (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
+	return parse(data, false)
+}
+
+// LoadContainer reads configuration in container mode: the YAML file is optional,
+// DRONEBRIDGE_* environment variables override it, interface auto-setup is turned
+// off and state files default to the /data volume
+func LoadContainer(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return parse(data, true)
+}
 
-	var cfg Config
+// parse decodes YAML, applies defaults and validates the result
+func parse(data []byte, container bool) (*Config, error) {
+	// Subsystems default to enabled; yaml only overwrites the keys that are present
+	cfg := Config{
+		Features:   SubsystemsConfig{Camera: true, Video: true, Web: true, Auth: true, Landing: true},
+		Upload:     UploadConfig{RequireDisarmed: true},
+		Forwarding: ForwardingConfig{PassUnknown: true},
+	}
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	if container {
+		if err := cfg.applyEnv(); err != nil {
+			return nil, fmt.Errorf("invalid environment: %w", err)
+		}
+		cfg.Ethernet.AutoSetup = false // The container cannot (and must not) reconfigure host interfaces
+		if cfg.Paths.DataDir == "" {
+			cfg.Paths.DataDir = "/data"
+		}
+	}
 
 	// Set defaults
 	if cfg.Log.Level == "" {
@@ -196,6 +824,374 @@ func Load(filename string) (*Config, error) {
 	if cfg.Ethernet.PixhawkConnectionTimeout <= 0 {
 		cfg.Ethernet.PixhawkConnectionTimeout = 30 // Default 30 seconds
 	}
+	for i := range cfg.Ethernet.DiscoveryCandidates {
+		if cfg.Ethernet.DiscoveryCandidates[i].Port == 0 {
+			cfg.Ethernet.DiscoveryCandidates[i].Port = cfg.Network.LocalListenPort
+		}
+	}
+	if cfg.Health.VibrationWarn <= 0 {
+		cfg.Health.VibrationWarn = 30
+	}
+	if cfg.Health.VibrationCritical <= 0 {
+		cfg.Health.VibrationCritical = 60
+	}
+	if cfg.Health.EKFVarianceWarn <= 0 {
+		cfg.Health.EKFVarianceWarn = 0.8
+	}
+	if cfg.Metrics.CheckpointFile == "" {
+		cfg.Metrics.CheckpointFile = ".drone_metrics"
+	}
+	if cfg.Metrics.CheckpointInterval <= 0 {
+		cfg.Metrics.CheckpointInterval = 60
+	}
+	if cfg.Metrics.RollupFile == "" {
+		cfg.Metrics.RollupFile = ".drone_rollup"
+	}
+	if cfg.Metrics.RollupDays <= 0 {
+		cfg.Metrics.RollupDays = 400
+	}
+	if cfg.Maintenance.File == "" {
+		cfg.Maintenance.File = ".drone_maintenance"
+	}
+	if cfg.Maintenance.ModeMinutes == 0 {
+		cfg.Maintenance.ModeMinutes = 30
+	}
+	if cfg.Maintenance.ModeMaxMinutes == 0 {
+		cfg.Maintenance.ModeMaxMinutes = 240
+	}
+	if cfg.Battery.File == "" {
+		cfg.Battery.File = ".drone_batteries"
+	}
+	if cfg.Battery.SagFactor == 0 {
+		cfg.Battery.SagFactor = 1.5
+	}
+	if cfg.Deadman.Timeout == 0 {
+		cfg.Deadman.Timeout = 180
+	}
+	if len(cfg.Deadman.Actions) == 0 {
+		cfg.Deadman.Actions = []string{"statustext"}
+	}
+	if cfg.Deadman.RecordDir == "" {
+		cfg.Deadman.RecordDir = "dvr"
+	}
+	if cfg.Storage.DataDir == "" {
+		cfg.Storage.DataDir = "."
+	}
+	if cfg.Storage.MinFreeMB == 0 {
+		cfg.Storage.MinFreeMB = 200
+	}
+	if cfg.Storage.TargetFreeMB == 0 {
+		cfg.Storage.TargetFreeMB = 500
+	}
+	if cfg.Storage.CheckInterval <= 0 {
+		cfg.Storage.CheckInterval = 60
+	}
+	if cfg.Watchdog.StallTimeout <= 0 {
+		cfg.Watchdog.StallTimeout = 30
+	}
+	if cfg.Control.Arbitration == "" {
+		cfg.Control.Arbitration = "local_priority"
+	}
+	if cfg.Control.HoldTime <= 0 {
+		cfg.Control.HoldTime = 3
+	}
+	if cfg.Params.ProfileDir == "" {
+		cfg.Params.ProfileDir = "profiles"
+	}
+	if cfg.Guided.MaxDistance <= 0 {
+		cfg.Guided.MaxDistance = 1000
+	}
+	if cfg.Guided.MaxAltitude <= 0 {
+		cfg.Guided.MaxAltitude = 120
+	}
+	if cfg.Guided.MaxSpeed <= 0 {
+		cfg.Guided.MaxSpeed = 15
+	}
+	if cfg.Drone.MetadataFile == "" {
+		cfg.Drone.MetadataFile = ".drone_identity"
+	}
+	if cfg.Signing.KeyFile == "" {
+		cfg.Signing.KeyFile = ".drone_signing_key"
+	}
+	if cfg.Entitlements.File == "" {
+		cfg.Entitlements.File = ".drone_entitlements"
+	}
+	if cfg.Signing.TimestampWindow == 0 {
+		cfg.Signing.TimestampWindow = 60
+	}
+	if cfg.Serial.Baud == 0 {
+		cfg.Serial.Baud = 921600
+	}
+	if cfg.Ethernet.PinFile == "" {
+		cfg.Ethernet.PinFile = ".drone_fc_pin"
+	}
+	if cfg.Camera.MediaMTX.PathTemplate == "" {
+		cfg.Camera.MediaMTX.PathTemplate = "{uuid}"
+	}
+	if cfg.Camera.Encoder.Type == "" {
+		cfg.Camera.Encoder.Type = "auto"
+	}
+	if cfg.Camera.Audio.Source == "" {
+		cfg.Camera.Audio.Source = "alsa"
+	}
+	if cfg.Camera.Audio.SampleRate <= 0 {
+		cfg.Camera.Audio.SampleRate = 48000
+	}
+	if cfg.Camera.Audio.Channels <= 0 {
+		cfg.Camera.Audio.Channels = 1
+	}
+	if cfg.Camera.Audio.Codec == "" {
+		cfg.Camera.Audio.Codec = "opus"
+	}
+	if cfg.Camera.Audio.Bitrate <= 0 {
+		cfg.Camera.Audio.Bitrate = 64
+	}
+	if cfg.Camera.Output == "" {
+		cfg.Camera.Output = "rtsp"
+	}
+	if cfg.Camera.SRT.Host == "" {
+		cfg.Camera.SRT.Host = cfg.Camera.MediaMTX.Host
+	}
+	if cfg.Camera.SRT.Port <= 0 {
+		cfg.Camera.SRT.Port = 8890
+	}
+	if cfg.Camera.SRT.Latency <= 0 {
+		cfg.Camera.SRT.Latency = 500
+	}
+	if cfg.Camera.SRT.KeyLength == 0 {
+		cfg.Camera.SRT.KeyLength = 16
+	}
+	if cfg.Camera.MediaMTX.PublishAuth == "" {
+		cfg.Camera.MediaMTX.PublishAuth = "none"
+	}
+	if !cfg.Features.Auth {
+		cfg.Auth.Enabled = false
+	}
+	if !cfg.Features.Camera {
+		cfg.Features.Video = false
+	}
+	if !cfg.Features.Video {
+		cfg.Features.Landing = false // Detection runs inside the video pipeline
+	}
+	if cfg.Mesh.Port == 0 {
+		cfg.Mesh.Port = 14600
+	}
+	if cfg.Mesh.Interval <= 0 {
+		cfg.Mesh.Interval = 1000
+	}
+	if cfg.Mesh.StaleTimeout <= 0 {
+		cfg.Mesh.StaleTimeout = 10
+	}
+	if cfg.Audit.File == "" {
+		cfg.Audit.File = ".drone_audit.jsonl"
+	}
+	if cfg.Web.Port == 0 {
+		cfg.Web.Port = 8080
+	}
+	for i := range cfg.Payload.Actions {
+		p := &cfg.Payload.Actions[i]
+		if p.Type == "" {
+			p.Type = "gpio"
+		}
+		if p.Type == "gpio" && p.Driver == "" {
+			p.Driver = "sysfs"
+		}
+		if p.Type == "pwm" && p.PeriodUs == 0 {
+			p.PeriodUs = 20000
+		}
+	}
+	if cfg.Power.Length == 0 {
+		cfg.Power.Length = 2
+	}
+	if cfg.Power.Scale == 0 {
+		cfg.Power.Scale = 1
+	}
+	if cfg.Power.Interval == 0 {
+		cfg.Power.Interval = 1000
+	}
+	if cfg.Power.Samples == 0 {
+		cfg.Power.Samples = 5
+	}
+	if len(cfg.Power.ShutdownCommand) == 0 {
+		cfg.Power.ShutdownCommand = []string{"systemctl", "poweroff"}
+	}
+	for i := range cfg.Sensors.Inputs {
+		in := &cfg.Sensors.Inputs[i]
+		if in.Length == 0 {
+			in.Length = 2
+		}
+		if in.Scale == 0 {
+			in.Scale = 1
+		}
+		if in.Interval == 0 {
+			in.Interval = 200
+		}
+		if in.Kind == "distance" {
+			if in.Orientation == "" {
+				in.Orientation = "down"
+			}
+			if in.SensorType == "" {
+				in.SensorType = "laser"
+			}
+		}
+	}
+	if cfg.Upload.Region == "" {
+		cfg.Upload.Region = "us-east-1"
+	}
+	if cfg.Upload.DiagnosticsDir == "" {
+		cfg.Upload.DiagnosticsDir = "diagnostics"
+	}
+	if cfg.Upload.SettleTime == 0 {
+		cfg.Upload.SettleTime = 60
+	}
+	if cfg.Upload.Interval == 0 {
+		cfg.Upload.Interval = 60
+	}
+	if cfg.Journal.Dir == "" {
+		cfg.Journal.Dir = "journal"
+	}
+	if cfg.Journal.SampleInterval == 0 {
+		cfg.Journal.SampleInterval = 1
+	}
+	if cfg.Tiles.Dir == "" {
+		cfg.Tiles.Dir = "tiles"
+	}
+	if cfg.Tiles.MaxSeed == 0 {
+		cfg.Tiles.MaxSeed = 5000
+	}
+	if cfg.Firmware.MaxImageMB == 0 {
+		cfg.Firmware.MaxImageMB = 16
+	}
+	if cfg.Files.Root == "" {
+		cfg.Files.Root = "Find_landing"
+	}
+	if cfg.Files.MaxFileMB == 0 {
+		cfg.Files.MaxFileMB = 10
+	}
+	if cfg.Files.QuotaMB == 0 {
+		cfg.Files.QuotaMB = 200
+	}
+	if cfg.Tokens.File == "" {
+		cfg.Tokens.File = ".drone_tokens"
+	}
+	if cfg.Auth.SessionAckWait == 0 {
+		cfg.Auth.SessionAckWait = 10
+	}
+	if cfg.Auth.SessionHeartbeatID == 0 {
+		cfg.Auth.SessionHeartbeatID = 42999
+	}
+	if cfg.Auth.SessionHeartbeatAckID == 0 {
+		cfg.Auth.SessionHeartbeatAckID = 42998
+	}
+	if cfg.Auth.SessionHeartbeatLayout == 0 {
+		cfg.Auth.SessionHeartbeatLayout = 1
+	}
+	if cfg.Discovery.Port == 0 {
+		cfg.Discovery.Port = 14650
+	}
+	if cfg.Discovery.Interval == 0 {
+		cfg.Discovery.Interval = 5
+	}
+	if cfg.Soak.Rate == 0 {
+		cfg.Soak.Rate = 500
+	}
+	if cfg.Soak.ReconnectInterval == 0 {
+		cfg.Soak.ReconnectInterval = 60
+	}
+	if cfg.Soak.SampleInterval == 0 {
+		cfg.Soak.SampleInterval = 30
+	}
+	if cfg.Soak.Warmup == 0 {
+		cfg.Soak.Warmup = 300
+	}
+	if cfg.Soak.MaxGoroutineGrowth == 0 {
+		cfg.Soak.MaxGoroutineGrowth = 50
+	}
+	if cfg.Soak.MaxHeapGrowthMB == 0 {
+		cfg.Soak.MaxHeapGrowthMB = 64
+	}
+	if cfg.Soak.MaxFDGrowth == 0 {
+		cfg.Soak.MaxFDGrowth = 32
+	}
+	if cfg.Soak.ReportFile == "" {
+		cfg.Soak.ReportFile = "logs/soak_report.json"
+	}
+	if cfg.Auth.Mode == "" {
+		cfg.Auth.Mode = "hmac"
+	}
+	if cfg.Auth.StartupJitter == 0 {
+		cfg.Auth.StartupJitter = 5
+	}
+	if cfg.Auth.MaxReauthPerMinute == 0 {
+		cfg.Auth.MaxReauthPerMinute = 4
+	}
+	if cfg.Auth.APIKeyCacheTTL == 0 {
+		cfg.Auth.APIKeyCacheTTL = 30
+	}
+	if cfg.Auth.PolicyReportInterval == 0 {
+		cfg.Auth.PolicyReportInterval = 300
+	}
+	if cfg.Network.Protocol == "" {
+		cfg.Network.Protocol = "udp"
+	}
+	if cfg.Network.FailoverTimeout == 0 {
+		cfg.Network.FailoverTimeout = 15
+	}
+	if cfg.Network.FailbackInterval == 0 {
+		cfg.Network.FailbackInterval = 60
+	}
+	if len(cfg.Network.Servers) > 0 {
+		// The highest-priority server is the starting target
+		sort.SliceStable(cfg.Network.Servers, func(i, j int) bool {
+			return cfg.Network.Servers[i].Priority < cfg.Network.Servers[j].Priority
+		})
+		cfg.Network.TargetHost = cfg.Network.Servers[0].Host
+		cfg.Network.TargetPort = cfg.Network.Servers[0].Port
+	}
+	if cfg.Forwarding.WriteQueueSize <= 0 {
+		cfg.Forwarding.WriteQueueSize = 256
+	}
+	if cfg.Forwarding.Privacy.PrecisionM == 0 {
+		cfg.Forwarding.Privacy.PrecisionM = 100
+	}
+	if cfg.Forwarding.StaleCommand.MaxAge <= 0 {
+		cfg.Forwarding.StaleCommand.MaxAge = 2000
+	}
+	if cfg.Forwarding.StaleCommand.Action == "" {
+		cfg.Forwarding.StaleCommand.Action = "flag"
+	}
+	if cfg.Forwarding.LocalParams.Action == "" {
+		cfg.Forwarding.LocalParams.Action = "forward"
+	}
+	if cfg.Forwarding.LocalParams.Rate <= 0 {
+		cfg.Forwarding.LocalParams.Rate = 10
+	}
+	if cfg.Forwarding.Policy == "" {
+		cfg.Forwarding.Policy = "full"
+	}
+	if cfg.Forwarding.Batching.MaxFrames <= 0 {
+		cfg.Forwarding.Batching.MaxFrames = 20
+	}
+	if cfg.Forwarding.Batching.MaxDelay <= 0 {
+		cfg.Forwarding.Batching.MaxDelay = 500
+	}
+	if len(cfg.Forwarding.Batching.Messages) == 0 {
+		// SCALED_IMU, RAW_IMU, SCALED_PRESSURE, SERVO_OUTPUT_RAW, RC_CHANNELS, SCALED_IMU2, SCALED_IMU3, VIBRATION
+		cfg.Forwarding.Batching.Messages = []uint32{26, 27, 29, 36, 65, 116, 129, 241}
+	}
+	if cfg.Traffic.HorizontalLimit <= 0 {
+		cfg.Traffic.HorizontalLimit = 500
+	}
+	if cfg.Traffic.VerticalLimit <= 0 {
+		cfg.Traffic.VerticalLimit = 100
+	}
+	if cfg.Traffic.LookaheadSec <= 0 {
+		cfg.Traffic.LookaheadSec = 60
+	}
+	if cfg.Traffic.StaleTimeout <= 0 {
+		cfg.Traffic.StaleTimeout = 30
+	}
+	cfg.resolvePaths()
 
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -232,7 +1228,7 @@ func (c *Config) Validate() error {
 		if c.Auth.Port <= 0 || c.Auth.Port > 65535 {
 			return fmt.Errorf("auth.port must be between 1 and 65535")
 		}
-		// UUID and SharedSecret can be empty if drone is already registered (read from .drone_secret)
+		// UUID and SharedSecret can be empty if drone is already registered (read from .drone_secret_<uuid>)
 		// Or UUID/SharedSecret must be present for first-time registration
 		if c.Auth.KeepaliveInterval <= 0 {
 			return fmt.Errorf("auth.keepalive_interval must be greater than 0 when auth is enabled")
@@ -240,16 +1236,477 @@ func (c *Config) Validate() error {
 		if c.Auth.SessionHeartbeatFrequency <= 0 {
 			return fmt.Errorf("auth.session_heartbeat_frequency must be greater than 0 when auth is enabled")
 		}
+		if c.Auth.SessionAckWait < 1 || c.Auth.SessionAckWait > 300 {
+			return fmt.Errorf("auth.session_ack_wait must be between 1 and 300 seconds")
+		}
+		if c.Auth.SessionHeartbeatID > 0xFFFFFF || c.Auth.SessionHeartbeatAckID > 0xFFFFFF {
+			return fmt.Errorf("auth.session_heartbeat_id and session_heartbeat_ack_id must fit in 24 bits (max 16777215)")
+		}
+		if c.Auth.SessionHeartbeatID == c.Auth.SessionHeartbeatAckID {
+			return fmt.Errorf("auth.session_heartbeat_id and session_heartbeat_ack_id must differ")
+		}
+		if c.Auth.SessionHeartbeatLayout != 1 && c.Auth.SessionHeartbeatLayout != 2 {
+			return fmt.Errorf("auth.session_heartbeat_layout must be 1 or 2")
+		}
+		switch c.Auth.Mode {
+		case "hmac":
+		case "mtls":
+			if c.Auth.TLS.CertFile == "" || c.Auth.TLS.KeyFile == "" {
+				return fmt.Errorf("auth.tls.cert_file and auth.tls.key_file are required when auth.mode is mtls")
+			}
+		default:
+			return fmt.Errorf("auth.mode must be \"hmac\" or \"mtls\", got %q", c.Auth.Mode)
+		}
+		if c.Auth.StartupJitter < 0 {
+			return fmt.Errorf("auth.startup_jitter must not be negative")
+		}
+		if c.Auth.MaxReauthPerMinute < 0 {
+			return fmt.Errorf("auth.max_reauth_per_minute must not be negative")
+		}
+		if c.Auth.APIKeyCacheTTL < 0 {
+			return fmt.Errorf("auth.api_key_cache_ttl must not be negative")
+		}
+		if c.Auth.PolicyReportInterval < -1 || (c.Auth.PolicyReportInterval > 0 && c.Auth.PolicyReportInterval < 10) {
+			return fmt.Errorf("auth.policy_report_interval must be -1 (off) or at least 10 seconds")
+		}
+	}
+	switch c.Forwarding.LocalParams.Action {
+	case "forward", "drop", "limit":
+	default:
+		return fmt.Errorf("forwarding.local_params.action must be \"forward\", \"drop\" or \"limit\", got %q", c.Forwarding.LocalParams.Action)
+	}
+	for i, f := range c.Forwarding.Filters {
+		if f.SysID < 0 || f.SysID > 255 {
+			return fmt.Errorf("forwarding.filters[%d].sysid must be between 0 and 255", i)
+		}
+		switch f.Action {
+		case "", "allow":
+		case "deny":
+			if f.MaxHz != 0 {
+				return fmt.Errorf("forwarding.filters[%d].max_hz only applies to allow rules", i)
+			}
+		default:
+			return fmt.Errorf("forwarding.filters[%d].action must be \"allow\" or \"deny\", got %q", i, f.Action)
+		}
+		if f.MaxHz < 0 {
+			return fmt.Errorf("forwarding.filters[%d].max_hz must not be negative", i)
+		}
+	}
+	for name, p := range c.Forwarding.Profiles {
+		if name != "cloud" && name != "websocket" {
+			return fmt.Errorf("forwarding.profiles.%s: unknown consumer (use \"cloud\" or \"websocket\")", name)
+		}
+		if p.DefaultHz < 0 {
+			return fmt.Errorf("forwarding.profiles.%s.default_hz must not be negative", name)
+		}
+		for id, hz := range p.Rates {
+			if hz < 0 {
+				return fmt.Errorf("forwarding.profiles.%s.rates[%d] must not be negative", name, id)
+			}
+		}
+	}
+	for i, t := range c.Forwarding.Transforms {
+		switch t.Type {
+		case "altitude_offset", "heading_offset":
+		case "redact_home":
+			if t.Value < 0 {
+				return fmt.Errorf("forwarding.transforms[%d].value (grid size in meters) must not be negative", i)
+			}
+		default:
+			return fmt.Errorf("forwarding.transforms[%d].type must be altitude_offset, heading_offset or redact_home, got %q", i, t.Type)
+		}
+		for _, consumer := range t.Consumers {
+			if consumer != "cloud" && consumer != "websocket" {
+				return fmt.Errorf("forwarding.transforms[%d].consumers: unknown consumer %q (use \"cloud\" or \"websocket\")", i, consumer)
+			}
+		}
+	}
+	if c.Serial.Enabled && c.Serial.Device == "" {
+		return fmt.Errorf("serial.device is required when serial.enabled is true")
+	}
+	if c.Serial.Baud < 0 {
+		return fmt.Errorf("serial.baud must be positive, got %d", c.Serial.Baud)
+	}
+	if c.Signing.LinkID < 0 || c.Signing.LinkID > 255 {
+		return fmt.Errorf("signing.link_id must be between 0 and 255, got %d", c.Signing.LinkID)
+	}
+	if c.Signing.TimestampWindow < -1 {
+		return fmt.Errorf("signing.timestamp_window must be positive or -1, got %d", c.Signing.TimestampWindow)
+	}
+	if c.Forwarding.Privacy.PrecisionM < 0 {
+		return fmt.Errorf("forwarding.privacy.precision_m must be positive, got %g", c.Forwarding.Privacy.PrecisionM)
+	}
+	switch c.Forwarding.StaleCommand.Action {
+	case "drop", "flag", "off":
+	default:
+		return fmt.Errorf("forwarding.stale_command.action must be \"drop\", \"flag\" or \"off\", got %q", c.Forwarding.StaleCommand.Action)
+	}
+	switch c.Control.Arbitration {
+	case "local_priority", "cloud_priority", "takeover", "last_writer":
+	default:
+		return fmt.Errorf("control.arbitration must be local_priority, cloud_priority, takeover or last_writer, got %q", c.Control.Arbitration)
+	}
+	for i, t := range c.Schedule.Tasks {
+		if t.Action == "" {
+			return fmt.Errorf("schedule.tasks[%d].action cannot be empty", i)
+		}
+		if (t.Every > 0) == (t.At != "") {
+			return fmt.Errorf("schedule.tasks[%d] must set exactly one of every or at", i)
+		}
+	}
+	switch c.Camera.MediaMTX.PublishAuth {
+	case "none", "apikey":
+	case "password":
+		if c.Camera.MediaMTX.Username == "" || c.Camera.MediaMTX.Password == "" {
+			return fmt.Errorf("camera.mediamtx.username and password are required when publish_auth is password")
+		}
+	default:
+		return fmt.Errorf("camera.mediamtx.publish_auth must be none, password or apikey, got %q", c.Camera.MediaMTX.PublishAuth)
+	}
+	switch c.Camera.Encoder.Type {
+	case "auto", "x264", "v4l2", "omx", "vaapi", "nvenc":
+	default:
+		return fmt.Errorf("camera.encoder.type must be auto, x264, v4l2, omx, vaapi or nvenc, got %q", c.Camera.Encoder.Type)
+	}
+	if c.Camera.Audio.Enabled {
+		switch c.Camera.Audio.Source {
+		case "alsa", "pulse":
+		default:
+			return fmt.Errorf("camera.audio.source must be alsa or pulse, got %q", c.Camera.Audio.Source)
+		}
+		switch c.Camera.Audio.Codec {
+		case "opus":
+			switch c.Camera.Audio.SampleRate {
+			case 8000, 12000, 16000, 24000, 48000:
+			default:
+				return fmt.Errorf("camera.audio.sample_rate must be 8000, 12000, 16000, 24000 or 48000 for opus")
+			}
+		case "aac":
+		default:
+			return fmt.Errorf("camera.audio.codec must be opus or aac, got %q", c.Camera.Audio.Codec)
+		}
+		if c.Camera.Audio.Channels > 2 {
+			return fmt.Errorf("camera.audio.channels must be 1 or 2")
+		}
+	}
+	switch c.Camera.Output {
+	case "rtsp":
+	case "srt":
+		if n := len(c.Camera.SRT.Passphrase); n > 0 && (n < 10 || n > 79) {
+			return fmt.Errorf("camera.srt.passphrase must be 10-79 characters")
+		}
+		if k := c.Camera.SRT.KeyLength; k != 16 && k != 24 && k != 32 {
+			return fmt.Errorf("camera.srt.key_length must be 16, 24 or 32")
+		}
+	default:
+		return fmt.Errorf("camera.output must be \"rtsp\" or \"srt\", got %q", c.Camera.Output)
+	}
+	if c.Mesh.Enabled {
+		if c.Mesh.Key == "" {
+			return fmt.Errorf("mesh.key is required when mesh is enabled")
+		}
+		if c.Mesh.Port <= 0 || c.Mesh.Port > 65535 {
+			return fmt.Errorf("mesh.port must be between 1 and 65535")
+		}
+		if c.Mesh.Port == c.Network.LocalListenPort {
+			return fmt.Errorf("mesh.port must differ from network.local_listen_port")
+		}
+	}
+	seenPayload := make(map[string]bool)
+	for i, p := range c.Payload.Actions {
+		if p.Name == "" || strings.ContainsAny(p.Name, "/ ") {
+			return fmt.Errorf("payload.actions[%d].name must be set and contain no slashes or spaces", i)
+		}
+		if seenPayload[p.Name] {
+			return fmt.Errorf("payload action %q is defined twice", p.Name)
+		}
+		seenPayload[p.Name] = true
+		switch p.Type {
+		case "gpio":
+			if p.Driver != "sysfs" && p.Driver != "gpiochip" {
+				return fmt.Errorf("payload action %q: driver must be sysfs or gpiochip", p.Name)
+			}
+			if p.Driver == "gpiochip" && p.Chip == "" {
+				return fmt.Errorf("payload action %q: chip is required for the gpiochip driver", p.Name)
+			}
+		case "pwm":
+			if p.PeriodUs <= 0 || p.OnDutyUs < 0 || p.OffDutyUs < 0 || p.OnDutyUs > p.PeriodUs || p.OffDutyUs > p.PeriodUs {
+				return fmt.Errorf("payload action %q: duty cycles must be between 0 and period_us", p.Name)
+			}
+		default:
+			return fmt.Errorf("payload action %q: type must be gpio or pwm", p.Name)
+		}
+		if p.Pin < 0 || p.PulseMs < 0 {
+			return fmt.Errorf("payload action %q: pin and pulse_ms must not be negative", p.Name)
+		}
+		if !p.Interlock.Declared() {
+			return fmt.Errorf("payload action %q: interlock must be set (require_armed, require_disarmed, min_altitude, max_altitude) or \"none\"", p.Name)
+		}
+		if p.Interlock.RequireArmed && p.Interlock.RequireDisarmed {
+			return fmt.Errorf("payload action %q: require_armed and require_disarmed are exclusive", p.Name)
+		}
+		if p.Interlock.MaxAltitude > 0 && p.Interlock.MaxAltitude < p.Interlock.MinAltitude {
+			return fmt.Errorf("payload action %q: max_altitude must not be below min_altitude", p.Name)
+		}
+	}
+	seenSensor := make(map[string]bool)
+	for i, in := range c.Sensors.Inputs {
+		if in.Name == "" {
+			return fmt.Errorf("sensors.inputs[%d].name is required", i)
+		}
+		if seenSensor[in.Name] {
+			return fmt.Errorf("sensor %q is defined twice", in.Name)
+		}
+		seenSensor[in.Name] = true
+		switch in.Kind {
+		case "value":
+			if len(in.Name) > 10 {
+				return fmt.Errorf("sensor %q: name must be at most 10 characters for NAMED_VALUE_FLOAT", in.Name)
+			}
+		case "distance":
+			if in.MaxDistance <= in.MinDistance || in.MinDistance < 0 {
+				return fmt.Errorf("sensor %q: max_distance must be above min_distance", in.Name)
+			}
+			if in.ID < 0 || in.ID > 255 {
+				return fmt.Errorf("sensor %q: id must be between 0 and 255", in.Name)
+			}
+			switch in.Orientation {
+			case "down", "up", "forward", "back", "left", "right":
+			default:
+				return fmt.Errorf("sensor %q: orientation must be down, up, forward, back, left or right", in.Name)
+			}
+			switch in.SensorType {
+			case "laser", "ultrasound", "infrared", "radar", "unknown":
+			default:
+				return fmt.Errorf("sensor %q: sensor_type must be laser, ultrasound, infrared, radar or unknown", in.Name)
+			}
+		default:
+			return fmt.Errorf("sensor %q: kind must be distance or value", in.Name)
+		}
+		if in.Device == "" {
+			return fmt.Errorf("sensor %q: device is required", in.Name)
+		}
+		switch in.Bus {
+		case "i2c":
+			if in.Address < 0x03 || in.Address > 0x77 {
+				return fmt.Errorf("sensor %q: address must be a 7-bit I2C address", in.Name)
+			}
+			if in.Register < 0 || in.Register > 0xFF {
+				return fmt.Errorf("sensor %q: register must be between 0 and 255", in.Name)
+			}
+			if in.Length != 1 && in.Length != 2 && in.Length != 4 {
+				return fmt.Errorf("sensor %q: length must be 1, 2 or 4", in.Name)
+			}
+		case "serial":
+			if in.Baud < 0 {
+				return fmt.Errorf("sensor %q: baud must not be negative", in.Name)
+			}
+		default:
+			return fmt.Errorf("sensor %q: bus must be i2c or serial", in.Name)
+		}
+		if in.Interval < 10 {
+			return fmt.Errorf("sensor %q: interval must be at least 10 ms", in.Name)
+		}
+	}
+	if c.Power.Enabled {
+		if c.Power.Device == "" {
+			return fmt.Errorf("power.device is required when power.enabled is set")
+		}
+		switch c.Power.Bus {
+		case "i2c":
+			if c.Power.Address < 0x03 || c.Power.Address > 0x77 {
+				return fmt.Errorf("power.address must be a 7-bit I2C address")
+			}
+			if c.Power.Register < 0 || c.Power.Register > 0xFF {
+				return fmt.Errorf("power.register must be between 0 and 255")
+			}
+			if c.Power.Length != 1 && c.Power.Length != 2 && c.Power.Length != 4 {
+				return fmt.Errorf("power.length must be 1, 2 or 4")
+			}
+		case "serial":
+			if c.Power.Baud < 0 {
+				return fmt.Errorf("power.baud must not be negative")
+			}
+		case "file":
+		default:
+			return fmt.Errorf("power.bus must be i2c, serial or file")
+		}
+		if c.Power.MinVoltage <= 0 {
+			return fmt.Errorf("power.min_voltage must be above 0")
+		}
+		if c.Power.Interval < 100 || c.Power.Samples < 1 {
+			return fmt.Errorf("power.interval must be at least 100 ms and power.samples at least 1")
+		}
+	}
+	if c.Journal.SampleInterval < 0 {
+		return fmt.Errorf("journal.sample_interval must not be negative")
+	}
+	if c.Upload.Enabled {
+		if c.Upload.Endpoint == "" || c.Upload.Bucket == "" {
+			return fmt.Errorf("upload.endpoint and upload.bucket are required when upload is enabled")
+		}
+		if !strings.HasPrefix(c.Upload.Endpoint, "http://") && !strings.HasPrefix(c.Upload.Endpoint, "https://") {
+			return fmt.Errorf("upload.endpoint must start with http:// or https://")
+		}
+		if c.Upload.AccessKey == "" || c.Upload.SecretKey == "" {
+			return fmt.Errorf("upload.access_key and upload.secret_key are required when upload is enabled")
+		}
+		if c.Upload.MaxKbps < 0 || c.Upload.SettleTime < 0 || c.Upload.Interval < 0 {
+			return fmt.Errorf("upload.max_kbps, upload.settle_time and upload.interval must not be negative")
+		}
+		known := make(map[string]bool, len(c.Storage.Classes))
+		for _, cl := range c.Storage.Classes {
+			known[cl.Name] = true
+		}
+		for _, name := range c.Upload.Classes {
+			if !known[name] {
+				return fmt.Errorf("upload.classes: %q is not a storage class", name)
+			}
+		}
+	}
+	if c.Tiles.MaxAgeDays < 0 || c.Tiles.MaxSeed < 0 {
+		return fmt.Errorf("tiles.max_age_days and tiles.max_seed must not be negative")
+	}
+	if seed := c.Tiles.Seed; seed.MaxZoom > 0 {
+		if seed.MinZoom < 0 || seed.MinZoom > seed.MaxZoom || seed.MaxZoom > 19 {
+			return fmt.Errorf("tiles.seed zoom range must be within 0-19 with min_zoom <= max_zoom")
+		}
+		if seed.MinLat >= seed.MaxLat || seed.MinLon >= seed.MaxLon {
+			return fmt.Errorf("tiles.seed requires min_lat < max_lat and min_lon < max_lon")
+		}
+	}
+	if c.Firmware.MaxImageMB < 0 {
+		return fmt.Errorf("firmware.max_image_mb must not be negative")
+	}
+	if c.Files.MaxFileMB < 0 || c.Files.QuotaMB < 0 {
+		return fmt.Errorf("files.max_file_mb and files.quota_mb must not be negative")
+	}
+	if c.Discovery.Port <= 0 || c.Discovery.Port > 65535 {
+		return fmt.Errorf("discovery.port must be between 1 and 65535")
+	}
+	if c.Mesh.Enabled && c.Discovery.Port == c.Mesh.Port {
+		return fmt.Errorf("discovery.port must differ from mesh.port")
+	}
+	if c.Discovery.Interval < 1 || c.Discovery.Interval > 3600 {
+		return fmt.Errorf("discovery.interval must be between 1 and 3600 seconds")
+	}
+	seenItems := make(map[string]bool)
+	for i, rem := range c.Maintenance.Reminders {
+		if rem.Item == "" {
+			return fmt.Errorf("maintenance.reminders[%d].item cannot be empty", i)
+		}
+		if seenItems[rem.Item] {
+			return fmt.Errorf("maintenance.reminders: duplicate item %q", rem.Item)
+		}
+		seenItems[rem.Item] = true
+		if rem.Hours < 0 || rem.Flights < 0 {
+			return fmt.Errorf("maintenance.reminders[%d] (%s): hours and flights must not be negative", i, rem.Item)
+		}
+		if rem.Hours == 0 && rem.Flights == 0 {
+			return fmt.Errorf("maintenance.reminders[%d] (%s): set hours and/or flights", i, rem.Item)
+		}
+	}
+	if c.Maintenance.ModeMaxMinutes < 1 || c.Maintenance.ModeMaxMinutes > 1440 {
+		return fmt.Errorf("maintenance.mode_max_minutes must be between 1 and 1440")
+	}
+	if c.Maintenance.ModeMinutes < 1 || c.Maintenance.ModeMinutes > c.Maintenance.ModeMaxMinutes {
+		return fmt.Errorf("maintenance.mode_minutes must be between 1 and maintenance.mode_max_minutes")
+	}
+	if c.Battery.SagFactor <= 1 {
+		return fmt.Errorf("battery.sag_factor must be greater than 1")
+	}
+	for i, name := range c.Safety.ParamAllowlist {
+		if name == "" || len(name) > 16 {
+			return fmt.Errorf("safety.param_allowlist[%d]: parameter names are 1-16 characters", i)
+		}
+	}
+	if c.Deadman.Timeout < 10 {
+		return fmt.Errorf("deadman.timeout must be at least 10 seconds")
+	}
+	seenActions := make(map[string]bool)
+	for _, a := range c.Deadman.Actions {
+		if a != "statustext" && a != "rtl" && a != "record" {
+			return fmt.Errorf("deadman.actions: unknown action %q (statustext, rtl or record)", a)
+		}
+		if seenActions[a] {
+			return fmt.Errorf("deadman.actions: duplicate action %q", a)
+		}
+		seenActions[a] = true
+	}
+	if c.Soak.Rate < 0 || c.Soak.Rate > 100000 {
+		return fmt.Errorf("soak.rate must be 1-100000")
+	}
+	if c.Soak.SampleInterval < 0 || c.Soak.Warmup < 0 || c.Soak.MaxQueueDepth < 0 {
+		return fmt.Errorf("soak.sample_interval, soak.warmup and soak.max_queue_depth must not be negative")
+	}
+	if c.Storage.MinFreeMB < 0 || c.Storage.TargetFreeMB < c.Storage.MinFreeMB {
+		return fmt.Errorf("storage.target_free_mb must be at least storage.min_free_mb (and both non-negative)")
+	}
+	for i, cl := range c.Storage.Classes {
+		if err := validStorageClass(c.Storage.DataDir, cl); err != nil {
+			return fmt.Errorf("storage.classes[%d]: %w", i, err)
+		}
 	}
 	if c.Network.LocalListenPort <= 0 || c.Network.LocalListenPort > 65535 {
 		return fmt.Errorf("local_listen_port must be between 1 and 65535")
 	}
+	for i, cand := range c.Ethernet.DiscoveryCandidates {
+		if (cand.BroadcastIP == "") == (cand.Host == "") {
+			return fmt.Errorf("ethernet.discovery_candidates[%d] needs exactly one of broadcast_ip or host", i)
+		}
+		if cand.BroadcastIP != "" {
+			if ip := net.ParseIP(cand.BroadcastIP); ip == nil || ip.To4() == nil {
+				return fmt.Errorf("ethernet.discovery_candidates[%d].broadcast_ip %q is not an IPv4 address", i, cand.BroadcastIP)
+			}
+		}
+		if cand.Port <= 0 || cand.Port > 65535 {
+			return fmt.Errorf("ethernet.discovery_candidates[%d].port must be between 1 and 65535", i)
+		}
+	}
+	for i, srv := range c.Network.Servers {
+		if srv.Host == "" {
+			return fmt.Errorf("network.servers[%d].host cannot be empty", i)
+		}
+		if srv.Port <= 0 || srv.Port > 65535 {
+			return fmt.Errorf("network.servers[%d].port must be between 1 and 65535", i)
+		}
+	}
+	if c.Network.FailoverTimeout < 0 {
+		return fmt.Errorf("network.failover_timeout must not be negative")
+	}
+	if c.Network.FailbackInterval < 0 {
+		return fmt.Errorf("network.failback_interval must not be negative")
+	}
 	if c.Network.TargetHost == "" {
 		return fmt.Errorf("target_host cannot be empty")
 	}
 	if c.Network.TargetPort <= 0 || c.Network.TargetPort > 65535 {
 		return fmt.Errorf("target_port must be between 1 and 65535")
 	}
+	switch c.Network.Protocol {
+	case "udp":
+	case "quic":
+		if (c.Network.QUIC.CertFile == "") != (c.Network.QUIC.KeyFile == "") {
+			return fmt.Errorf("network.quic.cert_file and network.quic.key_file must be set together")
+		}
+	default:
+		return fmt.Errorf("network.protocol must be \"udp\" or \"quic\", got %q", c.Network.Protocol)
+	}
+	for name, ids := range c.Forwarding.Policies {
+		if name == "full" {
+			return fmt.Errorf("forwarding.policies: \"full\" is built in and cannot be redefined")
+		}
+		if len(ids) == 0 {
+			return fmt.Errorf("forwarding.policies.%s: list at least one message ID (an empty policy would forward nothing)", name)
+		}
+	}
+	switch c.Forwarding.Policy {
+	case "minimal", "standard", "full":
+	default:
+		if _, ok := c.Forwarding.Policies[c.Forwarding.Policy]; !ok {
+			return fmt.Errorf("forwarding.policy %q is not defined", c.Forwarding.Policy)
+		}
+	}
 	if c.Web.Port <= 0 || c.Web.Port > 65535 {
 		return fmt.Errorf("web.port must be between 1 and 65535")
 	}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPayloadInterlockRequired(t *testing.T) {
+	base, err := os.ReadFile("config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const action = "\n  - name: drop\n    pin: 17\n"
+	tests := []struct {
+		name      string
+		interlock string
+		wantErr   string
+		wantNone  bool
+	}{
+		{"missing", "", "interlock must be set", false},
+		{"empty", "    interlock:\n", "interlock must be set", false},
+		{"nothing restricted", "    interlock:\n      require_armed: false\n", "interlock must be set", false},
+		{"explicitly none", "    interlock: none\n", "", true},
+		{"other scalar", "    interlock: off\n", "mapping or \"none\"", false},
+		{"require armed", "    interlock:\n      require_armed: true\n", "", false},
+		{"altitude", "    interlock:\n      min_altitude: 5\n", "", false},
+	}
+	for _, tt := range tests {
+		data := strings.Replace(string(base), "payload:\n  actions: []\n", "payload:\n  actions:"+action+tt.interlock, 1)
+		cfg, err := parse([]byte(data), false)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: parse() = %v, want an error containing %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: parse() = %v", tt.name, err)
+			continue
+		}
+		if got := cfg.Payload.Actions[0].Interlock.None; got != tt.wantNone {
+			t.Errorf("%s: interlock none = %v, want %v", tt.name, got, tt.wantNone)
+		}
+	}
+}
+
+func TestValidStorageClass(t *testing.T) {
+	dataDir := t.TempDir()
+	tests := []struct {
+		name    string
+		class   StorageClassConfig
+		wantErr bool
+	}{
+		{"relative dir", StorageClassConfig{Name: "video", Dir: "recordings", Pattern: "*.ts"}, false},
+		{"absolute dir below data_dir", StorageClassConfig{Name: "video", Dir: dataDir + "/recordings", Pattern: "*.ts"}, false},
+		{"nested dir", StorageClassConfig{Name: "logs", Dir: "logs/2025", Pattern: "flight-*.tlog"}, false},
+		{"data_dir itself", StorageClassConfig{Name: "all", Dir: "", Pattern: "*.yaml"}, true},
+		{"absolute dir elsewhere", StorageClassConfig{Name: "etc", Dir: "/etc", Pattern: "*.conf"}, true},
+		{"escapes data_dir", StorageClassConfig{Name: "up", Dir: "logs/../..", Pattern: "*.tlog"}, true},
+		{"sibling with the same prefix", StorageClassConfig{Name: "sib", Dir: dataDir + "-other", Pattern: "*.ts"}, true},
+		{"any file", StorageClassConfig{Name: "video", Dir: "recordings", Pattern: "*"}, true},
+		{"any extension", StorageClassConfig{Name: "video", Dir: "recordings", Pattern: "*.*"}, true},
+		{"pattern with a path", StorageClassConfig{Name: "video", Dir: "recordings", Pattern: "../*.ts"}, true},
+		{"negative retention", StorageClassConfig{Name: "video", Dir: "recordings", Pattern: "*.ts", RetentionDays: -1}, true},
+		{"no name", StorageClassConfig{Dir: "recordings", Pattern: "*.ts"}, true},
+	}
+	for _, tt := range tests {
+		if err := validStorageClass(dataDir, tt.class); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validStorageClass() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestForwardingPolicies(t *testing.T) {
+	base, err := os.ReadFile("config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		policies string
+		active   string
+		wantErr  string
+	}{
+		{"none", "{}", "full", ""},
+		{"custom", "{no_camera: [0, 1, 33]}", "no_camera", ""},
+		{"empty list", "{blackout: []}", "full", "list at least one message ID"},
+		{"null list", "{blackout: }", "full", "list at least one message ID"},
+		{"full redefined", "{full: [0]}", "full", "cannot be redefined"},
+		{"undefined active", "{no_camera: [0]}", "other", "is not defined"},
+	}
+	for _, tt := range tests {
+		data := strings.Replace(string(base), "  policy: \"full\"\n  policies: {}", "  policy: \""+tt.active+"\"\n  policies: "+tt.policies, 1)
+		_, err := parse([]byte(data), false)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: parse() = %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: parse() = %v, want an error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
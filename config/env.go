@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// EnvPrefix is the prefix of environment variables read in container mode
+const EnvPrefix = "DRONEBRIDGE_"
+
+// envVars maps environment variables (without EnvPrefix) to config fields.
+// Only the settings that differ between deployments of the same image are exposed.
+func (c *Config) envVars() map[string]interface{} {
+	return map[string]interface{}{
+		"LOG_LEVEL":              &c.Log.Level,
+		"AUTH_ENABLED":           &c.Auth.Enabled,
+		"AUTH_HOST":              &c.Auth.Host,
+		"AUTH_PORT":              &c.Auth.Port,
+		"AUTH_UUID":              &c.Auth.UUID,
+		"AUTH_SHARED_SECRET":     &c.Auth.SharedSecret,
+		"AUTH_MODE":              &c.Auth.Mode,
+		"AUTH_TLS_CERT_FILE":     &c.Auth.TLS.CertFile,
+		"AUTH_TLS_KEY_FILE":      &c.Auth.TLS.KeyFile,
+		"AUTH_TLS_CA_FILE":       &c.Auth.TLS.CAFile,
+		"NETWORK_LISTEN_PORT":    &c.Network.LocalListenPort,
+		"NETWORK_BROADCAST_PORT": &c.Network.BroadcastPort,
+		"NETWORK_TARGET_HOST":    &c.Network.TargetHost,
+		"NETWORK_TARGET_PORT":    &c.Network.TargetPort,
+		"NETWORK_PROTOCOL":       &c.Network.Protocol,
+		"NETWORK_QUIC_CA_FILE":   &c.Network.QUIC.CAFile,
+		"ETHERNET_INTERFACE":     &c.Ethernet.Interface,
+		"ETHERNET_LOCAL_IP":      &c.Ethernet.LocalIP,
+		"ETHERNET_BROADCAST_IP":  &c.Ethernet.BroadcastIP,
+		"ETHERNET_PIXHAWK_IP":    &c.Ethernet.PixhawkIP,
+		"ETHERNET_ALLOW_MISSING": &c.Ethernet.AllowMissingPixhawk,
+		"WEB_PORT":               &c.Web.Port,
+		"CAMERA_ENABLED":         &c.Camera.Enabled,
+		"MEDIAMTX_HOST":          &c.Camera.MediaMTX.Host,
+		"MEDIAMTX_PORT":          &c.Camera.MediaMTX.Port,
+		"MEDIAMTX_USERNAME":      &c.Camera.MediaMTX.Username,
+		"MEDIAMTX_PASSWORD":      &c.Camera.MediaMTX.Password,
+		"CONTROL_API_TOKEN":      &c.Control.APIToken,
+		"TOKENS_ADMIN_TOKEN":     &c.Tokens.AdminToken,
+		"DRONE_NAME":             &c.Drone.Name,
+		"FEATURES_CAMERA":        &c.Features.Camera,
+		"FEATURES_VIDEO":         &c.Features.Video,
+		"FEATURES_WEB":           &c.Features.Web,
+		"FEATURES_AUTH":          &c.Features.Auth,
+		"FEATURES_LANDING":       &c.Features.Landing,
+		"PATHS_DATA_DIR":         &c.Paths.DataDir,
+		"PATHS_SECRET_DIR":       &c.Paths.SecretDir,
+		"PATHS_LOG_FILE":         &c.Paths.LogFile,
+	}
+}
+
+// applyEnv overrides config fields from DRONEBRIDGE_* environment variables
+func (c *Config) applyEnv() error {
+	for name, field := range c.envVars() {
+		value, ok := os.LookupEnv(EnvPrefix + name)
+		if !ok {
+			continue
+		}
+		switch f := field.(type) {
+		case *string:
+			*f = value
+		case *int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s%s must be an integer, got %q", EnvPrefix, name, value)
+			}
+			*f = n
+		case *bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s%s must be a boolean, got %q", EnvPrefix, name, value)
+			}
+			*f = b
+		}
+	}
+	return nil
+}
+
+// resolvePaths places relative state files under paths.data_dir
+func (c *Config) resolvePaths() {
+	if c.Paths.DataDir == "" {
+		return
+	}
+	for _, p := range []*string{
+		&c.Metrics.CheckpointFile,
+		&c.Metrics.RollupFile,
+		&c.Maintenance.File,
+		&c.Battery.File,
+		&c.Drone.MetadataFile,
+		&c.Ethernet.PinFile,
+		&c.Signing.KeyFile,
+		&c.Entitlements.File,
+		&c.Tokens.File,
+		&c.Audit.File,
+		&c.Params.ProfileDir,
+		&c.Firmware.UploadDir,
+		&c.Storage.DataDir,
+		&c.Files.Root,
+		&c.Soak.ReportFile,
+		&c.Paths.SecretDir,
+		&c.Paths.LogFile,
+	} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(c.Paths.DataDir, *p)
+		}
+	}
+	if c.Paths.SecretDir == "" {
+		c.Paths.SecretDir = c.Paths.DataDir
+	}
+	// Directories documented as relative to storage.data_dir, which is now under data_dir
+	for _, p := range []*string{
+		&c.Journal.Dir,
+		&c.Tiles.Dir,
+		&c.Upload.DiagnosticsDir,
+		&c.Deadman.RecordDir,
+	} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(c.Storage.DataDir, *p)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProtectedKeys may not be changed by a router overlay: credentials and identity,
+// where secrets live, commands and pipelines the bridge runs, what it deletes,
+// the actuator outputs with their interlocks and the privacy the cloud is held
+// to. An entry covers the keys below it. Forwarding policies may only narrow
+// (checked when the push is applied).
+var ProtectedKeys = []string{
+	"auth",
+	"tokens",
+	"signing",
+	"paths",
+	"files",
+	"firmware",
+	"payload",
+	"upload",
+	"storage",
+	"camera",
+	"control.api_token",
+	"power.shutdown_command",
+	"forwarding.privacy",
+}
+
+// ApplyOverlay merges a YAML overlay into the config file and validates the result.
+// Mappings merge key by key; scalars and lists in the overlay replace the file's
+// values. It returns the merged YAML without writing it, so nothing changes on
+// disk when the overlay is invalid or touches a ProtectedKeys entry.
+func ApplyOverlay(filename string, overlay []byte, container bool) (*Config, []byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil && !(container && os.IsNotExist(err)) {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var base, over yaml.Node
+	if err := yaml.Unmarshal(data, &base); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if err := yaml.Unmarshal(overlay, &over); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse overlay: %w", err)
+	}
+	if len(over.Content) == 0 || over.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("overlay must be a YAML mapping")
+	}
+	if key := protectedKey(over.Content[0], ""); key != "" {
+		return nil, nil, fmt.Errorf("overlay may not change %s", key)
+	}
+	if len(base.Content) == 0 {
+		base = over
+	} else {
+		if base.Content[0].Kind != yaml.MappingNode {
+			return nil, nil, fmt.Errorf("config file is not a YAML mapping")
+		}
+		mergeNode(base.Content[0], over.Content[0])
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&base); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode merged config: %w", err)
+	}
+	enc.Close()
+
+	cfg, err := parse(buf.Bytes(), container)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, buf.Bytes(), nil
+}
+
+// protectedKey returns the first ProtectedKeys entry the overlay mapping m
+// (at path prefix) would change, or ""
+func protectedKey(m *yaml.Node, prefix string) string {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		path := prefix + m.Content[i].Value
+		val := m.Content[i+1]
+		if m.Content[i].Value == "<<" || val.Kind == yaml.AliasNode {
+			return path + " (aliases and merge keys could reach any key)"
+		}
+		for _, p := range ProtectedKeys {
+			if path == p || strings.HasPrefix(path, p+".") {
+				return p
+			}
+			// Replacing a parent with a scalar or list would drop the protected key
+			if strings.HasPrefix(p, path+".") && val.Kind != yaml.MappingNode {
+				return p
+			}
+		}
+		if val.Kind == yaml.MappingNode {
+			if key := protectedKey(val, path+"."); key != "" {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+// mergeNode merges the overlay mapping src into dst, keeping dst's comments and key order
+func mergeNode(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, val := src.Content[i], src.Content[i+1]
+		j := mappingIndex(dst, key.Value)
+		switch {
+		case j < 0:
+			dst.Content = append(dst.Content, key, val)
+		case dst.Content[j+1].Kind == yaml.MappingNode && val.Kind == yaml.MappingNode:
+			mergeNode(dst.Content[j+1], val)
+		default:
+			val.HeadComment, val.LineComment = dst.Content[j+1].HeadComment, dst.Content[j+1].LineComment
+			dst.Content[j+1] = val
+		}
+	}
+}
+
+// mappingIndex returns the index of key in a mapping node's content, or -1
+func mappingIndex(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
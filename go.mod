@@ -4,6 +4,11 @@ go 1.23.0
 
 require (
 	github.com/bluenviron/gomavlib/v3 v3.2.1
+	github.com/klauspost/compress v1.18.2
+	github.com/pierrec/lz4/v4 v4.1.22
+	github.com/quic-go/quic-go v0.54.1
+	golang.org/x/net v0.33.0
+	golang.org/x/sys v0.28.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -12,6 +17,9 @@ require (
 	github.com/pion/logging v0.2.2 // indirect
 	github.com/pion/transport/v2 v2.2.10 // indirect
 	go.bug.st/serial v1.6.2 // indirect
-	golang.org/x/net v0.33.0 // indirect
-	golang.org/x/sys v0.28.0 // indirect
+	go.uber.org/mock v0.5.0 // indirect
+	golang.org/x/crypto v0.31.0 // indirect
+	golang.org/x/mod v0.18.0 // indirect
+	golang.org/x/sync v0.8.0 // indirect
+	golang.org/x/tools v0.22.0 // indirect
 )
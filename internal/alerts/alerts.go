@@ -0,0 +1,130 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// Severity levels for alerts
+const (
+	SeverityInfo     = "INFO"
+	SeverityWarning  = "WARN"
+	SeverityCritical = "CRITICAL"
+)
+
+// Alert represents a single notification raised by a subsystem
+type Alert struct {
+	Time     time.Time `json:"time"`
+	Source   string    `json:"source"`   // Subsystem that raised the alert (e.g. "fc_health")
+	Severity string    `json:"severity"` // INFO, WARN, CRITICAL
+	Message  string    `json:"message"`
+}
+
+// Notifier dispatches alerts to the dashboard log and an optional webhook
+type Notifier struct {
+	mu         sync.RWMutex
+	webhookURL string
+	recent     []Alert
+	client     *http.Client
+	observer   func(Alert)
+}
+
+// Global is the process-wide notifier used by all subsystems
+var Global = New()
+
+// New creates a new notifier without a webhook
+func New() *Notifier {
+	return &Notifier{
+		recent: make([]Alert, 0, 100),
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// SetWebhook configures the URL alerts are POSTed to (empty disables webhook delivery)
+func (n *Notifier) SetWebhook(url string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.webhookURL = url
+}
+
+// SetObserver sets a function called with every raised alert (e.g. the flight journal)
+func (n *Notifier) SetObserver(fn func(Alert)) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.observer = fn
+}
+
+// Raise records an alert, mirrors it into the dashboard log and delivers it to the webhook
+func (n *Notifier) Raise(source, severity, message string) {
+	alert := Alert{
+		Time:     time.Now(),
+		Source:   source,
+		Severity: severity,
+		Message:  message,
+	}
+
+	n.mu.Lock()
+	// Keep last 100 alerts
+	if len(n.recent) >= 100 {
+		n.recent = n.recent[1:]
+	}
+	n.recent = append(n.recent, alert)
+	webhookURL := n.webhookURL
+	observer := n.observer
+	n.mu.Unlock()
+
+	if observer != nil {
+		observer(alert)
+	}
+
+	switch severity {
+	case SeverityCritical:
+		logger.Error("[ALERT] [%s] %s", source, message)
+	case SeverityWarning:
+		logger.Warn("[ALERT] [%s] %s", source, message)
+	default:
+		logger.Info("[ALERT] [%s] %s", source, message)
+	}
+	metrics.Global.AddLog(severity, "["+source+"] "+message)
+
+	if webhookURL != "" {
+		go n.deliver(webhookURL, alert)
+	}
+}
+
+// Recent returns a copy of the most recent alerts (oldest first)
+func (n *Notifier) Recent() []Alert {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	out := make([]Alert, len(n.recent))
+	copy(out, n.recent)
+	return out
+}
+
+// deliver POSTs the alert as JSON to the webhook
+func (n *Notifier) deliver(url string, alert Alert) {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return
+	}
+	resp, err := n.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logger.Debug("[ALERT] Webhook delivery failed: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		logger.Debug("[ALERT] Webhook returned status %d", resp.StatusCode)
+	}
+}
+
+// Raise records an alert on the global notifier
+func Raise(source, severity, message string) {
+	Global.Raise(source, severity, message)
+}
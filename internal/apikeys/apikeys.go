@@ -0,0 +1,307 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/auth"
+	"DroneBridge/internal/logger"
+)
+
+// Operations a job can run against the router
+const (
+	OpStatus  = "status"
+	OpRequest = "request"
+	OpRevoke  = "revoke"
+	OpDelete  = "delete"
+)
+
+// Job states
+const (
+	StateQueued  = "queued"  // Waiting for an authenticated session
+	StateRunning = "running" // Request sent to the router
+	StateDone    = "done"
+	StateFailed  = "failed"
+)
+
+const (
+	// maxJobs is the number of jobs kept for polling
+	maxJobs = 50
+	// sessionWait is how long a job waits for the session before failing
+	sessionWait = 2 * time.Minute
+	// maxAttempts bounds retries of a router request that timed out
+	maxAttempts = 3
+	retryDelay  = time.Second
+)
+
+// Client is the part of the auth client the manager uses
+type Client interface {
+	IsAuthenticated() bool
+	CachedAPIKeyStatus() (*auth.APIKeyStatusResponse, time.Duration, bool)
+	GetAPIKeyStatus() (*auth.APIKeyStatusResponse, error)
+	RequestAPIKey(expirationHours int) (*auth.APIKeyResponse, error)
+	RevokeAPIKey() error
+	DeleteAPIKey() error
+}
+
+// Job is one queued API key operation
+type Job struct {
+	ID              string     `json:"id"`
+	Op              string     `json:"op"`
+	State           string     `json:"state"`
+	ExpirationHours int        `json:"expirationHours,omitempty"`
+	Attempts        int        `json:"attempts"`
+	Created         time.Time  `json:"created"`
+	Finished        *time.Time `json:"finished,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	NoSession       bool       `json:"noSession,omitempty"` // Failed waiting for an authenticated session
+
+	// Result is *auth.APIKeyStatusResponse (status) or *auth.APIKeyResponse (request)
+	Result interface{} `json:"-"`
+}
+
+// Manager runs API key operations one at a time once the session is
+// authenticated, so web handlers never block on the router
+type Manager struct {
+	mu      sync.Mutex
+	client  Client
+	jobs    map[string]*Job
+	order   []string // Job IDs, oldest first
+	pending chan string
+	subs    map[chan Job]struct{}
+}
+
+// Global is the process-wide API key manager
+var Global = New()
+
+// New creates a manager without a client
+func New() *Manager {
+	return &Manager{
+		jobs:    make(map[string]*Job),
+		pending: make(chan string, maxJobs),
+		subs:    make(map[chan Job]struct{}),
+	}
+}
+
+// SetClient sets the auth client jobs run against
+func (m *Manager) SetClient(c Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.client = c
+}
+
+// Enabled reports whether a client is set
+func (m *Manager) Enabled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client != nil
+}
+
+// CachedStatus returns the client's cached API key status and its age, if fresh
+func (m *Manager) CachedStatus() (*auth.APIKeyStatusResponse, time.Duration, bool) {
+	m.mu.Lock()
+	client := m.client
+	m.mu.Unlock()
+	if client == nil {
+		return nil, 0, false
+	}
+	return client.CachedAPIKeyStatus()
+}
+
+// Submit queues an operation. A status request joins an unfinished status job
+// instead of queueing another, so dashboard polling can't pile up.
+func (m *Manager) Submit(op string, expirationHours int) (Job, error) {
+	switch op {
+	case OpStatus, OpRequest, OpRevoke, OpDelete:
+	default:
+		return Job{}, fmt.Errorf("unknown API key operation %q", op)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.client == nil {
+		return Job{}, fmt.Errorf("auth client not initialized")
+	}
+	if op == OpStatus {
+		for _, id := range m.order {
+			if j := m.jobs[id]; j.Op == OpStatus && (j.State == StateQueued || j.State == StateRunning) {
+				return *j, nil
+			}
+		}
+	}
+
+	j := &Job{ID: newJobID(), Op: op, State: StateQueued, ExpirationHours: expirationHours, Created: time.Now()}
+	select {
+	case m.pending <- j.ID:
+	default:
+		return Job{}, fmt.Errorf("too many pending API key operations")
+	}
+	m.jobs[j.ID] = j
+	m.order = append(m.order, j.ID)
+	m.pruneLocked()
+	logger.Debug("[APIKEY] Queued %s job %s", op, j.ID)
+	return *j, nil
+}
+
+// pruneLocked drops the oldest finished jobs beyond maxJobs (caller holds lock)
+func (m *Manager) pruneLocked() {
+	for i := 0; len(m.order) > maxJobs && i < len(m.order); {
+		id := m.order[i]
+		if j := m.jobs[id]; j.State == StateDone || j.State == StateFailed {
+			delete(m.jobs, id)
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			continue
+		}
+		i++
+	}
+}
+
+// Get returns a job by ID
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *j, true
+}
+
+// Subscribe returns a channel receiving every job that finishes, and a
+// function that ends the subscription
+func (m *Manager) Subscribe() (<-chan Job, func()) {
+	ch := make(chan Job, 8)
+	m.mu.Lock()
+	m.subs[ch] = struct{}{}
+	m.mu.Unlock()
+	return ch, func() {
+		m.mu.Lock()
+		delete(m.subs, ch)
+		m.mu.Unlock()
+	}
+}
+
+// Run processes queued jobs until stopCh is closed
+func (m *Manager) Run(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case id := <-m.pending:
+			m.process(id, stopCh)
+		}
+	}
+}
+
+// process waits for the session, then runs a job with retries
+func (m *Manager) process(id string, stopCh <-chan struct{}) {
+	m.mu.Lock()
+	j, ok := m.jobs[id]
+	client := m.client
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	deadline := j.Created.Add(sessionWait)
+	for !client.IsAuthenticated() {
+		if time.Now().After(deadline) {
+			m.mu.Lock()
+			j.NoSession = true
+			m.mu.Unlock()
+			m.finish(j, nil, fmt.Errorf("session not authenticated after %v", sessionWait))
+			return
+		}
+		select {
+		case <-stopCh:
+			m.finish(j, nil, fmt.Errorf("shutting down"))
+			return
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+
+	m.mu.Lock()
+	j.State = StateRunning
+	m.mu.Unlock()
+
+	var result interface{}
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		m.mu.Lock()
+		j.Attempts = attempt
+		m.mu.Unlock()
+
+		result, err = m.run(client, j)
+		if err == nil || !retryable(err) || attempt == maxAttempts {
+			break
+		}
+		logger.Debug("[APIKEY] %s job %s attempt %d failed: %v", j.Op, j.ID, attempt, err)
+		select {
+		case <-stopCh:
+			m.finish(j, nil, fmt.Errorf("shutting down"))
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+	m.finish(j, result, err)
+}
+
+func (m *Manager) run(client Client, j *Job) (interface{}, error) {
+	switch j.Op {
+	case OpStatus:
+		return client.GetAPIKeyStatus()
+	case OpRequest:
+		return client.RequestAPIKey(j.ExpirationHours)
+	case OpRevoke:
+		return nil, client.RevokeAPIKey()
+	default:
+		return nil, client.DeleteAPIKey()
+	}
+}
+
+// retryable reports whether a failure was the router not answering in time or
+// the session dropping between the check and the request
+func retryable(err error) bool {
+	msg := err.Error()
+	return strings.HasPrefix(msg, "timeout waiting") || msg == "no active session" || strings.Contains(msg, "reconnect failed")
+}
+
+// finish records the outcome and notifies subscribers
+func (m *Manager) finish(j *Job, result interface{}, err error) {
+	now := time.Now()
+	m.mu.Lock()
+	j.Finished = &now
+	j.Result = result
+	if err != nil {
+		j.State = StateFailed
+		j.Error = err.Error()
+	} else {
+		j.State = StateDone
+	}
+	snapshot := *j
+	for ch := range m.subs {
+		select {
+		case ch <- snapshot:
+		default: // Slow subscriber; it can still poll
+		}
+	}
+	m.mu.Unlock()
+
+	if err != nil {
+		logger.Warn("[APIKEY] %s job %s failed: %v", j.Op, j.ID, err)
+	} else {
+		logger.Info("[APIKEY] %s job %s done", j.Op, j.ID)
+	}
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/logger"
+)
+
+// Entry is one audited event
+type Entry struct {
+	Time     time.Time              `json:"time"`
+	Category string                 `json:"category"` // e.g. "viewer", "payload"
+	Event    string                 `json:"event"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Store appends audit entries to a JSON-lines file that survives restarts and
+// can be exported for billing and usage reports
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// Global is the process-wide audit store
+var Global = &Store{}
+
+// Open sets the file entries are appended to (empty = audit disabled)
+func (s *Store) Open(path string) error {
+	if path != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create audit directory: %w", err)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path = path
+	return nil
+}
+
+// Record appends an entry. Failures are logged, never returned, so auditing
+// can't block the action being audited.
+func (s *Store) Record(category, event string, fields map[string]interface{}) {
+	data, err := json.Marshal(Entry{Time: time.Now().UTC(), Category: category, Event: event, Fields: fields})
+	if err != nil {
+		logger.Warn("[AUDIT] Failed to encode %s/%s: %v", category, event, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" {
+		return
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logger.Warn("[AUDIT] Failed to open %s: %v", s.path, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		logger.Warn("[AUDIT] Failed to write %s/%s: %v", category, event, err)
+	}
+}
+
+// Query returns entries of a category (empty = all) at or after since, newest
+// first, at most limit (0 = no limit)
+func (s *Store) Query(category string, since time.Time, limit int) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := []Entry{}
+	if s.path == "" {
+		return out, nil
+	}
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // Torn write from a power loss
+		}
+		if (category == "" || e.Category == category) && !e.Time.Before(since) {
+			out = append(out, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
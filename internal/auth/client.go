@@ -1,18 +1,20 @@
 package auth
 
 import (
-	"crypto/rand"
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/hex"
 	"fmt"
 	"log"
 	"net"
-	"os"
-	"strings"
 	"sync"
 	"time"
 
+	"DroneBridge/internal/clock"
+	"DroneBridge/internal/deadman"
+	"DroneBridge/internal/dialer"
 	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/watchdog"
 )
 
 // Client handles drone authentication with the router
@@ -32,7 +34,8 @@ type Client struct {
 	running           bool
 	stopCh            chan struct{}
 	mu                sync.RWMutex
-	tcpMu             sync.Mutex // For synchronizing TCP operations
+	tcpMu             sync.Mutex    // For synchronizing TCP operations
+	reader            *routerReader // Sole reader of conn (see client_reader.go)
 	reconnectDelay    time.Duration
 	previousLocalIP   string        // Track previous local IP for change detection
 	lastIPChangeTime  time.Time     // Track last IP change time
@@ -45,15 +48,88 @@ type Client struct {
 	apiKeyDeleteAckCh   chan *APIKeyDeleteAck
 	sessionRefreshAckCh chan *SessionRefreshAck
 
-	OnNetworkError func() // Callback when network error is detected
+	// Last API key status from the router (see client_apikey_cache.go)
+	apiKeyStatus    *APIKeyStatusResponse
+	apiKeyStatusAt  time.Time
+	apiKeyStatusTTL time.Duration
+
+	// Rate negotiation state
+	ratePolicy       *RatePolicy
+	rateRetryAt      time.Time     // No RATE_NEGOTIATE before this (router didn't answer)
+	rateBackoff      time.Duration // Current wait after an unanswered RATE_NEGOTIATE
+	compressionCaps  byte
+	heartbeatID      uint32 // SESSION_HEARTBEAT identity offered in RATE_NEGOTIATE
+	heartbeatAckID   uint32
+	heartbeatLayouts byte
+
+	// Feature entitlements (see client_entitlements.go)
+	entitlementsAsked time.Time // Last ENTITLEMENT_REQUEST (zero = due)
+
+	// Status flags for the router (see client_status.go)
+	statusFlags   byte
+	statusUntil   time.Time
+	statusPending bool // DRONE_STATUS not yet delivered on this session
+
+	// Forwarding configuration reports (see client_policy.go)
+	policyReport     func() ([]byte, error)
+	policyEvery      time.Duration
+	policyReportedAt time.Time // Last POLICY_REPORT (zero = due)
+	policyDigest     [32]byte  // Digest of the last report sent
+
+	// Monotonic counters for replay protection (persisted across restarts)
+	replay *ReplayState
+
+	// Certificate identity (nil = HMAC challenge mode)
+	tlsConfig *tls.Config
+
+	// Re-auth storm protection
+	startupJitter    time.Duration // Max random delay before the first AUTH
+	reauthPerMinute  int           // Re-auth cap (0 = unlimited)
+	reauthTimes      []time.Time   // Re-auth attempts within the last minute
+	authBlockedUntil time.Time     // Router-requested backoff (AUTH_ACK WaitSec)
+
+	// Handshake tracing (see trace.go)
+	traceEnabled bool
+	traceSink    func(map[string]interface{})
+	traceCount   uint64
+
+	// Lifecycle events (see events.go)
+	eventSink      func(kind, severity, message string, fields map[string]interface{})
+	expiryWarned   time.Time // Session expiry already warned about
+	apiKeyLastSeen string    // Last API key status, to report changes
+
+	// Injectable time and network (defaults to the real ones)
+	clock  clock.Clock
+	dialer dialer.Dialer
+
+	OnNetworkError func()                    // Callback when network error is detected
+	OnRatePolicy   func(*RatePolicy)         // Callback when the router advertises a rate policy
+	OnVideoControl func(*VideoControl) error // Callback when the router starts/stops/reconfigures video
+	OnUserConnect  func(userUUID string)     // Callback when a user starts watching this drone
+	OnUserLeave    func(userUUID string)     // Callback when a user stops watching this drone
+
+	// Callback applying a verified CONFIG_PUSH overlay; the returned note goes into the ACK
+	OnConfigPush func(overlay []byte) (string, error)
+
+	// Callback installing a MAVLink signing key from REGISTER_ACK or a SIGNING_KEY push
+	OnSigningKey func(key []byte) error
+
+	// Callback applying a verified ENTITLEMENTS list; set it to request lists from the router
+	OnEntitlements func(*Entitlements) error
 }
 
 // NewClient creates a new authentication client using UUID-based protocol
 func NewClient(host string, port int, droneUUID string, sharedSecret string, keepaliveInterval int) *Client {
 	// If UUID is empty, try to get or generate one
 	if droneUUID == "" {
-		droneUUID = getOrGenerateUUID()
-		log.Printf("[AUTH] No UUID provided in config, using auto-generated: %s", droneUUID)
+		droneUUID = ProvisionUUID()
+		log.Printf("[AUTH] No UUID provided in config, using auto-provisioned: %s", droneUUID)
+	}
+
+	replay, err := LoadReplayState()
+	if err != nil {
+		log.Printf("[AUTH] Warn: %v - replay counters will not persist across restarts", err)
+		replay = &ReplayState{}
 	}
 
 	return &Client{
@@ -72,63 +148,27 @@ func NewClient(host string, port int, droneUUID string, sharedSecret string, kee
 		apiKeyStatusCh:      make(chan *APIKeyStatusResponse, 1),
 		apiKeyDeleteAckCh:   make(chan *APIKeyDeleteAck, 1),
 		sessionRefreshAckCh: make(chan *SessionRefreshAck, 1),
+		apiKeyStatusTTL:     defaultAPIKeyStatusTTL,
+		replay:              replay,
+		clock:               clock.Real,
+		dialer:              dialer.Real,
 	}
 }
 
-// getOrGenerateUUID attempts to retrieve a persistent UUID for this drone
-func getOrGenerateUUID() string {
-	uuidFile := ".drone_uuid"
-
-	// 1. Try to read from file
-	data, err := os.ReadFile(uuidFile)
-	if err == nil {
-		id := strings.TrimSpace(string(data))
-		if id != "" {
-			return id
-		}
-	}
-
-	// 2. Try to generate from MAC address
-	id := getIDFromMAC()
-	if id == "" {
-		// 3. Fallback to random UUID
-		id = generateRandomUUID()
-	}
-
-	// Save for next time
-	os.WriteFile(uuidFile, []byte(id), 0644)
-	return id
-}
-
-func getIDFromMAC() string {
-	ifaces, err := net.Interfaces()
-	if err != nil {
-		return ""
-	}
-	for _, iface := range ifaces {
-		if iface.Flags&net.FlagLoopback == 0 && iface.HardwareAddr != nil {
-			mac := iface.HardwareAddr.String()
-			if mac != "" {
-				// Format: 00:11:22:33:44:55 -> 00112233-4455-0000-0000-000000000000 (just an example)
-				cleanMAC := strings.ReplaceAll(mac, ":", "")
-				if len(cleanMAC) >= 12 {
-					return fmt.Sprintf("%s-%s-%s-%s-%s",
-						cleanMAC[:8], cleanMAC[8:12], "5555", "8888", "999999999999")
-				}
-			}
-		}
-	}
-	return ""
+// SetClock replaces the time source used for session expiry and refresh timing.
+// Must be called before Start().
+func (c *Client) SetClock(clk clock.Clock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clock = clk
 }
 
-func generateRandomUUID() string {
-	b := make([]byte, 16)
-	_, err := rand.Read(b)
-	if err != nil {
-		return "static-fallback-uuid-0001"
-	}
-	return fmt.Sprintf("%x-%x-%x-%x-%x",
-		b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+// SetDialer replaces the dialer used for the auth TCP connection.
+// Must be called before Register() or Start().
+func (c *Client) SetDialer(d dialer.Dialer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dialer = d
 }
 
 // Start begins authentication and keepalive
@@ -145,15 +185,25 @@ func (c *Client) Start() error {
 
 	// Check if already have valid session from REGISTER
 	c.mu.RLock()
-	hasValidSession := c.sessionToken != "" && time.Now().Before(c.expiresAt)
+	hasValidSession := c.sessionToken != "" && c.clock.Now().Before(c.expiresAt)
 	c.mu.RUnlock()
 
 	if hasValidSession {
 		// Session created during REGISTER - just start keepalive
 		log.Printf("[AUTH] ✓ Valid session from REGISTER flow, starting keepalive")
 	} else {
-		// No session yet - perform AUTH
+		// No session yet - perform AUTH after a random delay, honoring router backoff
+		c.startupDelay()
 		err := c.authenticate()
+		for attempt := 1; err != nil && attempt < 3; attempt++ {
+			wait := c.serverWait()
+			if wait == 0 {
+				break
+			}
+			log.Printf("[AUTH] ⏳ Initial authentication rejected, retrying in %v", wait.Round(time.Second))
+			<-c.clock.After(wait)
+			err = c.authenticate()
+		}
 		if err != nil {
 			return fmt.Errorf("initial authentication failed: %w", err)
 		}
@@ -170,114 +220,6 @@ func (c *Client) Start() error {
 	return nil
 }
 
-// Register performs the one-time registration process
-// Flow: REGISTER_INIT(UUID) → REGISTER_CHALLENGE → REGISTER_RESPONSE(HMAC-Shared) → REGISTER_ACK(Secret+Session)
-func (c *Client) Register() error {
-	if c.sharedSecret == "" {
-		return fmt.Errorf("shared secret is required for registration")
-	}
-
-	log.Printf("[REGISTER] Starting registration for drone UUID=%s...", c.droneUUID)
-	log.Printf("[REGISTER] Connecting to %s:%d...", c.host, c.port)
-
-	// Connect to auth server
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", c.host, c.port), 10*time.Second)
-	if err != nil {
-		return fmt.Errorf("connection failed: %w", err)
-	}
-	// DO NOT defer conn.Close() - we want to keep this connection alive!
-
-	// Enable TCP keepalive to prevent disconnects
-	if tcpConn, ok := conn.(*net.TCPConn); ok {
-		tcpConn.SetKeepAlive(true)
-		tcpConn.SetKeepAlivePeriod(30 * time.Second)
-		log.Printf("[REGISTER] ✓ TCP keepalive enabled (30s interval)")
-	}
-
-	// Step 1: Send REGISTER_INIT
-	init := &RegisterInit{
-		DroneUUID: c.droneUUID,
-	}
-
-	packet := SerializeRegisterInit(init)
-	if _, err := conn.Write(packet); err != nil {
-		return fmt.Errorf("failed to send REGISTER_INIT: %w", err)
-	}
-	log.Printf("[REGISTER] ✓ Sent REGISTER_INIT")
-
-	// Step 2: Receive REGISTER_CHALLENGE
-	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
-	buf := make([]byte, 4096)
-	n, err := conn.Read(buf)
-	if err != nil {
-		return fmt.Errorf("failed to receive REGISTER_CHALLENGE: %w", err)
-	}
-
-	challenge, err := ParseRegisterChallenge(buf[:n])
-	if err != nil {
-		return fmt.Errorf("failed to parse REGISTER_CHALLENGE: %w", err)
-	}
-	log.Printf("[REGISTER] ✓ Received challenge")
-
-	// Step 3: Compute HMAC with SHARED SECRET
-	timestamp := uint64(time.Now().Unix())
-	hmacSig := ComputeHMAC(c.sharedSecret, c.droneUUID, challenge.Nonce, timestamp)
-
-	// Step 4: Send REGISTER_RESPONSE
-	resp := &RegisterResponse{
-		DroneUUID: c.droneUUID,
-		HMAC:      hmacSig,
-		Timestamp: timestamp,
-	}
-
-	packet = SerializeRegisterResponse(resp)
-	if _, err := conn.Write(packet); err != nil {
-		return fmt.Errorf("failed to send REGISTER_RESPONSE: %w", err)
-	}
-	log.Printf("[REGISTER] ✓ Sent REGISTER_RESPONSE")
-
-	// Step 5: Receive REGISTER_ACK with SECRET (no session)
-	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
-	n, err = conn.Read(buf)
-	if err != nil {
-		return fmt.Errorf("failed to receive REGISTER_ACK: %w", err)
-	}
-
-	ack, err := ParseRegisterAck(buf[:n])
-	if err != nil {
-		return fmt.Errorf("failed to parse REGISTER_ACK: %w", err)
-	}
-
-	if ack.Result != ResultSuccess {
-		return fmt.Errorf("registration failed (error=%d)", ack.ErrorCode)
-	}
-
-	log.Printf("[REGISTER] ✅ Registration successful!")
-	log.Printf("[REGISTER] 🔑 Received SECRET KEY (len=%d)", len(ack.SecretKey))
-
-	// Step 6: Save Secret Key
-	if err := SaveSecret(c.droneUUID, ack.SecretKey); err != nil {
-		return fmt.Errorf("failed to save secret key: %w", err)
-	}
-	log.Printf("[REGISTER] 💾 Secret key saved to '%s'", SecretFileName)
-
-	// Step 7: Close connection - session will be obtained via AUTH flow
-	// This prevents duplicate session creation issue
-	conn.Close()
-	log.Printf("[REGISTER] ✅ Registration complete. Connection closed.")
-
-	c.mu.Lock()
-	c.secret = ack.SecretKey
-	c.conn = nil        // Connection closed, will reconnect during AUTH
-	c.sessionToken = "" // No session from REGISTER (will get from AUTH)
-	c.expiresAt = time.Time{}
-	c.mu.Unlock()
-
-	log.Printf("[REGISTER] ℹ️  Session will be obtained during AUTH flow (Start())")
-
-	return nil
-}
-
 // computeCombinedKey generates a combined key from shared and private keys
 // Logic: SHA256(shared_key + private_key) -> Hex String
 func computeCombinedKey(sharedKey, privateKey string) string {
@@ -309,22 +251,22 @@ func (c *Client) IsAuthenticated() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return c.sessionToken != "" && time.Now().Before(c.expiresAt)
+	return c.sessionToken != "" && c.clock.Now().Before(c.expiresAt)
 }
 
 // authenticate performs the authentication handshake (UUID-based with Secret Key)
 // Flow: AUTH_INIT(UUID) → AUTH_CHALLENGE → AUTH_RESPONSE(HMAC-Combined) → AUTH_ACK(Session)
-func (c *Client) authenticate() error {
-	// 1. Ensure we have secret key
-	if c.secret == "" {
+func (c *Client) authenticate() (err error) {
+	tr := c.newTrace()
+	defer func() { tr.finish(err) }()
+
+	// 1. Ensure we have secret key (not needed when the client certificate proves identity)
+	if c.secret == "" && c.tlsConfig == nil {
 		// Try to load from storage
-		uuid, key, err := LoadSecret()
+		key, err := LoadSecret(c.droneUUID)
 		if err != nil {
 			return fmt.Errorf("failed to load secret key (drone not registered?): %w. Run with --register first", err)
 		}
-		if uuid != c.droneUUID {
-			log.Printf("[AUTH] Warn: Secret file UUID (%s) doesn't match config UUID (%s)", uuid, c.droneUUID)
-		}
 		c.secret = key
 		log.Printf("[AUTH] Loaded secret key from storage")
 	}
@@ -338,10 +280,11 @@ func (c *Client) authenticate() error {
 		// No existing connection - create new one
 		log.Printf("[AUTH] Connecting to %s:%d...", c.host, c.port)
 
-		newConn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", c.host, c.port), 10*time.Second)
+		newConn, err := c.dialAuth()
 		if err != nil {
 			return fmt.Errorf("connection failed: %w", err)
 		}
+		tr.step("connected", map[string]interface{}{"remote": newConn.RemoteAddr().String(), "local": newConn.LocalAddr().String(), "mtls": c.tlsConfig != nil})
 
 		// Enable TCP keepalive to prevent disconnects
 		if tcpConn, ok := newConn.(*net.TCPConn); ok {
@@ -353,39 +296,116 @@ func (c *Client) authenticate() error {
 		c.mu.Lock()
 		c.conn = newConn
 		c.previousLocalIP = newConn.LocalAddr().(*net.TCPAddr).IP.String()
-		c.lastIPChangeTime = time.Now()
+		c.lastIPChangeTime = c.clock.Now()
 		c.mu.Unlock()
 
 		conn = newConn
 		log.Printf("[AUTH] ✓ Connected from local IP: %s", c.previousLocalIP)
 	} else {
 		log.Printf("[AUTH] ✓ Reusing existing connection from REGISTER")
+		tr.step("connected", map[string]interface{}{"reused": true})
 	}
 
 	// Step 2: Send AUTH_INIT with UUID
 	init := &AuthInit{
 		DroneUUID: c.droneUUID,
+		Flags:     AuthInitFlagFramed,
 	}
 
+	// The router answers with AUTH_CHALLENGE, or AUTH_ACK right away (mTLS or rejection)
+	reader := c.readerFor(conn)
+	reply := reader.expect(MsgAuthChallenge, MsgAuthAck)
 	packet := SerializeAuthInit(init)
 	if _, err := conn.Write(packet); err != nil {
+		reply.cancel()
 		return fmt.Errorf("failed to send AUTH_INIT: %w", err)
 	}
 	log.Printf("[AUTH] ✓ Sent AUTH_INIT (UUID=%s)", c.droneUUID)
+	tr.step("AUTH_INIT sent", map[string]interface{}{"bytes": len(packet)})
+
+	data, err := reply.wait(10 * time.Second)
+	if err != nil {
+		expected := "AUTH_CHALLENGE"
+		if c.tlsConfig != nil {
+			expected = "AUTH_ACK"
+		}
+		return fmt.Errorf("failed to receive %s: %w", expected, err)
+	}
 
-	// Step 3: Receive AUTH_CHALLENGE
-	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
-	buf := make([]byte, 4096)
-	n, err := conn.Read(buf)
+	// Steps 3-5: Solve the HMAC challenge (skipped with mTLS - the certificate is the proof)
+	if data[0] == MsgAuthChallenge {
+		if data, err = c.respondToChallenge(conn, reader, data, tr); err != nil {
+			return err
+		}
+	} else if c.tlsConfig != nil {
+		log.Printf("[AUTH] ✓ Identity proven by client certificate (mTLS), skipping challenge")
+	}
+
+	// Step 6: AUTH_ACK with SESSION
+	ack, err := ParseAuthAck(data)
 	if err != nil {
-		return fmt.Errorf("failed to receive AUTH_CHALLENGE: %w", err)
+		return fmt.Errorf("failed to parse AUTH_ACK: %w", err)
+	}
+	ackFields := map[string]interface{}{"bytes": len(data), "result": ack.Result}
+	if ack.Result != ResultSuccess {
+		ackFields["error"] = errorCodeName(ack.ErrorCode)
+		ackFields["waitSec"] = ack.WaitSec
+	} else {
+		ackFields["expiresIn"] = time.Until(time.Unix(int64(ack.ExpiresAt), 0)).Round(time.Second).String()
+		ackFields["interval"] = ack.Interval
+	}
+	tr.step("AUTH_ACK received", ackFields)
+
+	if ack.Result != ResultSuccess {
+		c.noteServerWait(ack.WaitSec)
+		return fmt.Errorf("authentication failed (error=%d, wait=%ds)", ack.ErrorCode, ack.WaitSec)
+	}
+
+	// AUTH_ACK now contains session token directly
+	if ack.SessionToken == "" {
+		return fmt.Errorf("authentication successful but no session token received")
 	}
 
-	challenge, err := ParseAuthChallenge(buf[:n])
+	log.Printf("[AUTH] ✅ Authentication successful! (identity verified)")
+	metrics.Global.SetAuthStatus("Authenticated")
+	metrics.Global.AddLog("INFO", "Authentication successful - UUID: "+c.droneUUID)
+
+	// Store session info
+	c.mu.Lock()
+	c.sessionToken = ack.SessionToken
+	c.expiresAt = time.Unix(int64(ack.ExpiresAt), 0)
+	c.refreshInterval = time.Duration(ack.Interval) * time.Second
+	c.mu.Unlock()
+
+	metrics.Global.SetSessionInfo(c.expiresAt, c.refreshInterval)
+
+	log.Printf("[SESSION] ✅ Session ready!")
+	log.Printf("[SESSION]    Token: %s...", c.sessionToken[:20])
+	log.Printf("[SESSION]    Expires: %s", c.expiresAt.Format("2006-01-02 15:04:05"))
+	c.emit(EventSessionStarted, "INFO", "Session started", map[string]interface{}{"expiresAt": c.expiresAt})
+
+	// Ask the router for its telemetry rate policy
+	c.negotiateRates(conn)
+
+	// A new session may come with a new feature list
+	c.mu.Lock()
+	c.entitlementsAsked = time.Time{}
+	c.statusPending = c.statusFlags != 0 // The router forgets our flags with the old session
+	c.policyReportedAt = time.Time{}
+	c.mu.Unlock()
+
+	return nil
+}
+
+// respondToChallenge answers AUTH_CHALLENGE with the HMAC AUTH_RESPONSE and returns the AUTH_ACK
+func (c *Client) respondToChallenge(conn net.Conn, reader *routerReader, data []byte, tr *authTrace) ([]byte, error) {
+	// Step 3: AUTH_CHALLENGE
+	challenge, err := ParseAuthChallenge(data)
 	if err != nil {
-		return fmt.Errorf("failed to parse AUTH_CHALLENGE: %w", err)
+		return nil, fmt.Errorf("failed to parse AUTH_CHALLENGE: %w", err)
 	}
 	log.Printf("[AUTH] ✓ Received challenge")
+	tr.step("AUTH_CHALLENGE received", map[string]interface{}{"bytes": len(data), "nonceLen": len(challenge.Nonce), "timeoutSec": challenge.TimeoutSec})
 
 	// Step 4: Compute HMAC (Combined Key = SHA256(Secret + Shared))
 	// If shared secret is not configured, we might use just secret?
@@ -402,8 +422,12 @@ func (c *Client) authenticate() error {
 		log.Printf("[AUTH] Warn: No shared secret in config, using RAW SECRET KEY")
 	}
 
-	timestamp := uint64(time.Now().Unix())
+	timestamp := uint64(c.clock.Now().Unix())
 	hmacSig := ComputeHMAC(authKey, c.droneUUID, challenge.Nonce, timestamp)
+	counter, err := c.replay.NextAuthCounter()
+	if err != nil {
+		return nil, err
+	}
 
 	// Step 5: Send AUTH_RESPONSE
 	resp := &AuthResponse{
@@ -411,53 +435,33 @@ func (c *Client) authenticate() error {
 		HMAC:      hmacSig,
 		Timestamp: timestamp,
 		IP:        "0.0.0.0",
+		Counter:   counter,
 	}
 
-	packet = SerializeAuthResponse(resp)
+	reply := reader.expect(MsgAuthAck)
+	packet := SerializeAuthResponse(resp)
 	if _, err := conn.Write(packet); err != nil {
-		return fmt.Errorf("failed to send AUTH_RESPONSE: %w", err)
+		reply.cancel()
+		return nil, fmt.Errorf("failed to send AUTH_RESPONSE: %w", err)
 	}
 	log.Printf("[AUTH] ✓ Sent AUTH_RESPONSE")
-
-	// Step 6: Receive AUTH_ACK with SESSION
-	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
-	n, err = conn.Read(buf)
-	if err != nil {
-		return fmt.Errorf("failed to receive AUTH_ACK: %w", err)
+	keyMode := "raw"
+	if c.sharedSecret != "" {
+		keyMode = "combined"
 	}
+	tr.step("AUTH_RESPONSE sent", map[string]interface{}{
+		"bytes":     len(packet),
+		"keyMode":   keyMode,
+		"keyFp":     keyFingerprint(authKey),
+		"timestamp": timestamp,
+		"counter":   resp.Counter,
+	})
 
-	ack, err := ParseAuthAck(buf[:n])
+	ack, err := reply.wait(10 * time.Second)
 	if err != nil {
-		return fmt.Errorf("failed to parse AUTH_ACK: %w", err)
+		return nil, fmt.Errorf("failed to receive AUTH_ACK: %w", err)
 	}
-
-	if ack.Result != ResultSuccess {
-		return fmt.Errorf("authentication failed (error=%d, wait=%ds)", ack.ErrorCode, ack.WaitSec)
-	}
-
-	// AUTH_ACK now contains session token directly
-	if ack.SessionToken == "" {
-		return fmt.Errorf("authentication successful but no session token received")
-	}
-
-	log.Printf("[AUTH] ✅ Authentication successful! (identity verified)")
-	metrics.Global.SetAuthStatus("Authenticated")
-	metrics.Global.AddLog("INFO", "Authentication successful - UUID: "+c.droneUUID)
-
-	// Store session info
-	c.mu.Lock()
-	c.sessionToken = ack.SessionToken
-	c.expiresAt = time.Unix(int64(ack.ExpiresAt), 0)
-	c.refreshInterval = time.Duration(ack.Interval) * time.Second
-	c.mu.Unlock()
-
-	metrics.Global.SetSessionInfo(c.expiresAt, c.refreshInterval)
-
-	log.Printf("[SESSION] ✅ Session ready!")
-	log.Printf("[SESSION]    Token: %s...", c.sessionToken[:20])
-	log.Printf("[SESSION]    Expires: %s", c.expiresAt.Format("2006-01-02 15:04:05"))
-
-	return nil
+	return ack, nil
 }
 
 // requestSession requests a session token from the server (after authentication)
@@ -475,22 +479,22 @@ func (c *Client) requestSession(conn net.Conn) error {
 		OldSessionToken: oldToken, // Server may reuse if still valid
 	}
 
+	reply := c.readerFor(conn).expect(MsgSessionAck)
 	packet := SerializeSessionRequest(sessionReq)
 	if _, err := conn.Write(packet); err != nil {
+		reply.cancel()
 		return fmt.Errorf("failed to send SESSION_NEW: %w", err)
 	}
 	log.Printf("[SESSION] ✓ Sent SESSION_NEW (UUID=%s, oldToken=%s)",
 		c.droneUUID, truncateToken(oldToken))
 
 	// Receive SESSION_ACK
-	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
-	buf := make([]byte, 4096)
-	n, err := conn.Read(buf)
+	data, err := reply.wait(10 * time.Second)
 	if err != nil {
 		return fmt.Errorf("failed to receive SESSION_ACK: %w", err)
 	}
 
-	sessionAck, err := ParseSessionAck(buf[:n])
+	sessionAck, err := ParseSessionAck(data)
 	if err != nil {
 		return fmt.Errorf("failed to parse SESSION_ACK: %w", err)
 	}
@@ -512,6 +516,7 @@ func (c *Client) requestSession(conn net.Conn) error {
 	log.Printf("[SESSION] ✅ Session ready!")
 	log.Printf("[SESSION]    Token: %s...", c.sessionToken[:20])
 	log.Printf("[SESSION]    Expires: %s", c.expiresAt.Format("2006-01-02 15:04:05"))
+	c.emit(EventSessionStarted, "INFO", "Session started", map[string]interface{}{"expiresAt": c.expiresAt})
 	log.Printf("[SESSION]    Refresh Interval: %.0fs", c.refreshInterval.Seconds())
 
 	return nil
@@ -547,7 +552,7 @@ func (c *Client) sendRefresh() error {
 	c.mu.RLock()
 	token := c.sessionToken
 	conn := c.conn
-	timeSinceIPChange := time.Since(c.lastIPChangeTime)
+	timeSinceIPChange := c.clock.Since(c.lastIPChangeTime)
 	c.mu.RUnlock()
 
 	// Skip refresh if IP changed too recently (avoid re-auth loop)
@@ -577,23 +582,21 @@ func (c *Client) sendRefresh() error {
 		DroneUUID:    c.droneUUID,
 	}
 
+	reply := c.readerFor(conn).expect(MsgSessionRefreshAck)
 	packet := SerializeSessionRefresh(refreshReq)
 	if _, err := conn.Write(packet); err != nil {
+		reply.cancel()
 		return &RefreshError{Message: fmt.Sprintf("failed to send SESSION_REFRESH: %v", err)}
 	}
 	log.Printf("[SESSION_REFRESH] ✓ Sent SESSION_REFRESH")
 
 	// Receive SESSION_REFRESH_ACK - use shorter timeout to avoid blocking other operations
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
-	buf := make([]byte, 4096)
-	n, err := conn.Read(buf)
-	conn.SetReadDeadline(time.Time{}) // Reset deadline
-
+	data, err := reply.wait(5 * time.Second)
 	if err != nil {
 		return &RefreshError{Message: fmt.Sprintf("failed to receive SESSION_REFRESH_ACK: %v", err)}
 	}
 
-	ackResp, err := ParseSessionRefreshAck(buf[:n])
+	ackResp, err := ParseSessionRefreshAck(data)
 	if err != nil {
 		return &RefreshError{Message: fmt.Sprintf("failed to parse SESSION_REFRESH_ACK: %v", err)}
 	}
@@ -613,9 +616,11 @@ func (c *Client) sendRefresh() error {
 
 	// Update metrics
 	metrics.Global.SetSessionInfo(c.expiresAt, refreshInterval)
+	deadman.Global.Touch()
 
 	log.Printf("[SESSION_REFRESH] ✓ Session extended (expires: %s)",
 		time.Unix(int64(ackResp.ExpiresAt), 0).Format("15:04:05"))
+	c.emit(EventSessionRefreshed, "INFO", "Session refreshed", map[string]interface{}{"expiresAt": time.Unix(int64(ackResp.ExpiresAt), 0)})
 
 	return nil
 }
@@ -630,25 +635,45 @@ func (c *Client) keepaliveLoop() {
 	}
 	c.mu.RUnlock()
 
-	refreshTicker := time.NewTicker(refreshInterval)
+	refreshTicker := c.clock.NewTicker(refreshInterval)
 	defer refreshTicker.Stop()
 
 	log.Printf("[KEEPALIVE] Starting refresh every %.0fs", refreshInterval.Seconds())
 
+	// A refresh blocked on a dead connection is recovered by closing the connection
+	wd := watchdog.Global.Register("keepaliveLoop", c.abortIO)
+	defer watchdog.Global.Unregister(wd)
+	beat := c.clock.NewTicker(watchdog.BeatInterval)
+	defer beat.Stop()
+
 	for {
+		wd.Beat()
 		select {
 		case <-c.stopCh:
 			return
 
-		case <-refreshTicker.C:
+		case <-beat.C():
+			c.checkExpiry()
+			c.requestEntitlements()
+			c.reportStatus()
+			c.reportPolicy()
+
+		case <-refreshTicker.C():
 			// Send TCP refresh to maintain session
 			c.mu.RLock()
 			running := c.running
 			c.mu.RUnlock()
 
+			// Router asked us to back off - don't add to its load until the wait expires
+			if wait := c.serverWait(); wait > 0 {
+				log.Printf("[REFRESH] ⏸️ Router backoff active for %v, skipping this cycle", wait.Round(time.Second))
+				continue
+			}
+
 			if running {
 				if err := c.sendRefresh(); err != nil {
 					log.Printf("[REFRESH] ❌ Failed: %v", err)
+					c.emit(EventRefreshFailed, "WARN", "Session refresh failed: "+err.Error(), map[string]interface{}{"error": err.Error()})
 
 					// Check if this is a RefreshError with specific error code
 					var needReauth bool
@@ -679,10 +704,17 @@ func (c *Client) keepaliveLoop() {
 						c.mu.Unlock()
 					}
 
+					// Network recovery is owned by the caller's reconnect coordinator when one is wired
+					if isNetworkError && c.OnNetworkError != nil {
+						log.Printf("[REFRESH] 🔌 Reporting network error to reconnect coordinator")
+						c.OnNetworkError()
+						continue
+					}
+
 					if needReauth {
 						// Session not found on server - re-authenticate immediately
 						log.Printf("[REFRESH] 🔄 Re-authenticating (session not found on server)...")
-						if err := c.authenticate(); err != nil {
+						if err := c.reauthenticate("session not found"); err != nil {
 							log.Printf("[AUTH] ❌ Re-authentication failed: %v", err)
 						} else {
 							log.Printf("[AUTH] ✅ Re-authentication successful - Session recovered!")
@@ -690,14 +722,14 @@ func (c *Client) keepaliveLoop() {
 					} else if isNetworkError {
 						// Network error - try reconnecting TCP (reuse token if still valid locally)
 						c.mu.RLock()
-						tokenValid := c.sessionToken != "" && time.Now().Before(c.expiresAt)
+						tokenValid := c.sessionToken != "" && c.clock.Now().Before(c.expiresAt)
 						c.mu.RUnlock()
 
 						if tokenValid {
 							log.Printf("[REFRESH] 🔄 Token still valid locally, reconnecting TCP...")
 							if err := c.reconnectTCP(); err != nil {
 								log.Printf("[REFRESH] ❌ TCP reconnect failed: %v - re-authenticating", err)
-								if err := c.authenticate(); err != nil {
+								if err := c.reauthenticate("TCP reconnect failed"); err != nil {
 									log.Printf("[AUTH] ❌ Authentication failed: %v", err)
 								} else {
 									log.Printf("[AUTH] ✅ Authentication successful - Session recovered!")
@@ -707,7 +739,7 @@ func (c *Client) keepaliveLoop() {
 							}
 						} else {
 							log.Printf("[REFRESH] ⚠️ Token expired, re-authenticating...")
-							if err := c.authenticate(); err != nil {
+							if err := c.reauthenticate("token expired"); err != nil {
 								log.Printf("[AUTH] ❌ Re-authentication failed: %v", err)
 							} else {
 								log.Printf("[AUTH] ♻️ Re-authentication successful - Session recovered!")
@@ -724,7 +756,7 @@ func (c *Client) keepaliveLoop() {
 // This does full auth + session request
 func (c *Client) TriggerReauth() error {
 	log.Printf("[REAUTH] 🔄 Triggering immediate re-authentication...")
-	return c.authenticate()
+	return c.reauthenticate("session recovery")
 }
 
 // TriggerSessionRecovery attempts session refresh first, falls back to re-auth if needed
@@ -742,7 +774,7 @@ func (c *Client) TriggerSessionRecovery() error {
 	}
 
 	// Check if we have a valid session that can be refreshed
-	if token != "" && time.Now().Before(expiresAt) {
+	if token != "" && c.clock.Now().Before(expiresAt) {
 		log.Printf("[SESSION_RECOVERY] 🔄 Session still valid (expires %s), attempting refresh...",
 			expiresAt.Format("15:04:05"))
 
@@ -789,6 +821,13 @@ func (c *Client) TriggerSessionRecovery() error {
 	return c.TriggerReauth()
 }
 
+// NextHeartbeatSequence returns the next SESSION_HEARTBEAT sequence number.
+// Sequences keep increasing across restarts so the router can reject replays;
+// it fails when the next reservation cannot be persisted.
+func (c *Client) NextHeartbeatSequence() (uint16, error) {
+	return c.replay.NextHeartbeatSequence()
+}
+
 // GetSessionInfo returns current session information
 func (c *Client) GetSessionInfo() (token string, expiresAt time.Time) {
 	c.mu.RLock()
@@ -808,7 +847,7 @@ func (c *Client) reconnectTCP() error {
 	c.mu.RUnlock()
 
 	// Create new connection
-	conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", c.host, c.port), 10*time.Second)
+	conn, err := c.dialAuth()
 	if err != nil {
 		return fmt.Errorf("reconnection failed: %w", err)
 	}
@@ -829,16 +868,29 @@ func (c *Client) reconnectTCP() error {
 		msg := fmt.Sprintf("TCP Local IP changed from %s to %s", c.previousLocalIP, currentLocalIP)
 		log.Printf("[IP_CHANGE] 🔄 %s", msg)
 		metrics.Global.AddLog("WARN", msg)
-		c.lastIPChangeTime = time.Now() // Record IP change time to skip next refresh
+		c.lastIPChangeTime = c.clock.Now() // Record IP change time to skip next refresh
 	}
 	c.previousLocalIP = currentLocalIP
 	metrics.Global.SetIP(currentLocalIP)
 	c.mu.Unlock()
 
+	c.readerFor(conn) // Pushes can arrive before the next request
 	log.Printf("[RECONNECT] ✓ TCP reconnected successfully from local IP: %s", currentLocalIP)
 	return nil
 }
 
+// abortIO closes the current connection without waiting for tcpMu, unblocking
+// any read or write stuck on it (used by the watchdog)
+func (c *Client) abortIO() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		log.Println("[AUTH] Watchdog: closing stuck auth connection")
+		c.conn.Close()
+		c.conn = nil
+	}
+}
+
 // ForceReconnect closes the current connection to trigger an immediate reconnect
 func (c *Client) ForceReconnect() {
 	c.tcpMu.Lock()
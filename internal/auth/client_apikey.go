@@ -54,18 +54,18 @@ func (c *Client) RequestAPIKey(expirationHours int) (*APIKeyResponse, error) {
 		ExpirationHours: uint16(expirationHours),
 	}
 
+	reply := c.readerFor(conn).expect(MsgAPIKeyResponse)
 	packet := SerializeAPIKeyRequest(req)
 	if _, err := conn.Write(packet); err != nil {
+		reply.cancel()
 		c.tcpMu.Unlock()
 		return nil, fmt.Errorf("failed to send API_KEY_REQUEST: %w", err)
 	}
 	log.Printf("[API_KEY] ✓ Sent API_KEY_REQUEST (expiration: %d hours)", expirationHours)
+	c.InvalidateAPIKeyStatus()
 
 	// Read API_KEY_RESPONSE with short timeout before releasing lock
-	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
-	buf := make([]byte, 4096)
-	n, err := conn.Read(buf)
-	conn.SetReadDeadline(time.Time{}) // Reset deadline
+	data, err := reply.wait(3 * time.Second)
 
 	c.tcpMu.Unlock() // Release lock immediately after reading
 
@@ -75,7 +75,7 @@ func (c *Client) RequestAPIKey(expirationHours int) (*APIKeyResponse, error) {
 		return nil, fmt.Errorf("timeout waiting for API_KEY_RESPONSE")
 	}
 
-	resp, err := ParseAPIKeyResponse(buf[:n])
+	resp, err := ParseAPIKeyResponse(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse API_KEY_RESPONSE: %w", err)
 	}
@@ -87,6 +87,7 @@ func (c *Client) RequestAPIKey(expirationHours int) (*APIKeyResponse, error) {
 	log.Printf("[API_KEY] ✅ Received API key (expires: %s)",
 		time.Unix(int64(resp.ExpiresAt), 0).Format("2006-01-02 15:04:05"))
 	metrics.Global.AddLog("INFO", "API key generated successfully")
+	c.emit(EventAPIKeyCreated, "INFO", "API key created", map[string]interface{}{"expiresAt": time.Unix(int64(resp.ExpiresAt), 0)})
 	return resp, nil
 }
 
@@ -126,18 +127,18 @@ func (c *Client) RevokeAPIKey() error {
 		SessionToken: token,
 	}
 
+	reply := c.readerFor(conn).expect(MsgAPIKeyRevokeAck)
 	packet := SerializeAPIKeyRevoke(req)
 	if _, err := conn.Write(packet); err != nil {
+		reply.cancel()
 		c.tcpMu.Unlock()
 		return fmt.Errorf("failed to send API_KEY_REVOKE: %w", err)
 	}
 	log.Printf("[API_KEY] ✓ Sent API_KEY_REVOKE")
+	c.InvalidateAPIKeyStatus()
 
 	// Read API_KEY_REVOKE_ACK with short timeout before releasing lock
-	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
-	buf := make([]byte, 4096)
-	n, err := conn.Read(buf)
-	conn.SetReadDeadline(time.Time{}) // Reset deadline
+	data, err := reply.wait(3 * time.Second)
 
 	c.tcpMu.Unlock() // Release lock immediately after reading
 
@@ -146,7 +147,7 @@ func (c *Client) RevokeAPIKey() error {
 		return fmt.Errorf("timeout waiting for API_KEY_REVOKE_ACK")
 	}
 
-	ack, err := ParseAPIKeyRevokeAck(buf[:n])
+	ack, err := ParseAPIKeyRevokeAck(data)
 	if err != nil {
 		return fmt.Errorf("failed to parse API_KEY_REVOKE_ACK: %w", err)
 	}
@@ -157,6 +158,7 @@ func (c *Client) RevokeAPIKey() error {
 
 	log.Printf("[API_KEY] ✅ API key revoked successfully")
 	metrics.Global.AddLog("INFO", "API key revoked successfully")
+	c.emit(EventAPIKeyRevoked, "INFO", "API key revoked", nil)
 	return nil
 }
 
@@ -196,18 +198,17 @@ func (c *Client) GetAPIKeyStatus() (*APIKeyStatusResponse, error) {
 		SessionToken: token,
 	}
 
+	reply := c.readerFor(conn).expect(MsgAPIKeyStatusResp)
 	packet := SerializeAPIKeyStatus(req)
 	if _, err := conn.Write(packet); err != nil {
+		reply.cancel()
 		c.tcpMu.Unlock()
 		return nil, fmt.Errorf("failed to send API_KEY_STATUS: %w", err)
 	}
 	log.Printf("[API_KEY] ✓ Sent API_KEY_STATUS request")
 
 	// Read API_KEY_STATUS_RESP with short timeout before releasing lock
-	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
-	buf := make([]byte, 4096)
-	n, err := conn.Read(buf)
-	conn.SetReadDeadline(time.Time{}) // Reset deadline
+	data, err := reply.wait(3 * time.Second)
 
 	c.tcpMu.Unlock() // Release lock immediately after reading
 
@@ -216,12 +217,14 @@ func (c *Client) GetAPIKeyStatus() (*APIKeyStatusResponse, error) {
 		return nil, fmt.Errorf("timeout waiting for API_KEY_STATUS_RESP")
 	}
 
-	resp, err := ParseAPIKeyStatusResponse(buf[:n])
+	resp, err := ParseAPIKeyStatusResponse(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse API_KEY_STATUS_RESP: %w", err)
 	}
 
 	log.Printf("[API_KEY] ✓ Received API key status: %s", resp.Status)
+	c.cacheAPIKeyStatus(resp)
+	c.noteAPIKeyStatus(resp.Status)
 	return resp, nil
 }
 
@@ -261,18 +264,18 @@ func (c *Client) DeleteAPIKey() error {
 		SessionToken: token,
 	}
 
+	reply := c.readerFor(conn).expect(MsgAPIKeyDeleteAck)
 	packet := SerializeAPIKeyDelete(req)
 	if _, err := conn.Write(packet); err != nil {
+		reply.cancel()
 		c.tcpMu.Unlock()
 		return fmt.Errorf("failed to send API_KEY_DELETE: %w", err)
 	}
 	log.Printf("[API_KEY] ✓ Sent API_KEY_DELETE")
+	c.InvalidateAPIKeyStatus()
 
 	// Read API_KEY_DELETE_ACK with short timeout before releasing lock
-	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
-	buf := make([]byte, 4096)
-	n, err := conn.Read(buf)
-	conn.SetReadDeadline(time.Time{}) // Reset deadline
+	data, err := reply.wait(3 * time.Second)
 
 	c.tcpMu.Unlock() // Release lock immediately after reading
 
@@ -281,7 +284,7 @@ func (c *Client) DeleteAPIKey() error {
 		return fmt.Errorf("timeout waiting for API_KEY_DELETE_ACK")
 	}
 
-	ack, err := ParseAPIKeyDeleteAck(buf[:n])
+	ack, err := ParseAPIKeyDeleteAck(data)
 	if err != nil {
 		return fmt.Errorf("failed to parse API_KEY_DELETE_ACK: %w", err)
 	}
@@ -292,5 +295,6 @@ func (c *Client) DeleteAPIKey() error {
 
 	log.Printf("[API_KEY] ✅ API key deleted successfully")
 	metrics.Global.AddLog("INFO", "API key deleted successfully")
+	c.emit(EventAPIKeyDeleted, "INFO", "API key deleted", nil)
 	return nil
 }
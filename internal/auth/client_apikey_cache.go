@@ -0,0 +1,46 @@
+package auth
+
+import "time"
+
+// defaultAPIKeyStatusTTL is how long an API key status is served without asking the router
+const defaultAPIKeyStatusTTL = 30 * time.Second
+
+// SetAPIKeyStatusTTL sets how long CachedAPIKeyStatus serves the last status
+func (c *Client) SetAPIKeyStatusTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.apiKeyStatusTTL = ttl
+}
+
+// CachedAPIKeyStatus returns the last status received from the router and its
+// age, or ok=false if there is none or it is older than the TTL
+func (c *Client) CachedAPIKeyStatus() (status *APIKeyStatusResponse, age time.Duration, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.apiKeyStatus == nil {
+		return nil, 0, false
+	}
+	age = c.clock.Now().Sub(c.apiKeyStatusAt)
+	if age > c.apiKeyStatusTTL {
+		return nil, 0, false
+	}
+	copied := *c.apiKeyStatus
+	return &copied, age, true
+}
+
+// cacheAPIKeyStatus stores a status just received from the router
+func (c *Client) cacheAPIKeyStatus(status *APIKeyStatusResponse) {
+	copied := *status
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.apiKeyStatus = &copied
+	c.apiKeyStatusAt = c.clock.Now()
+}
+
+// InvalidateAPIKeyStatus drops the cached status so the next read asks the router.
+// Called after request/revoke/delete and on user connect/disconnect notifications.
+func (c *Client) InvalidateAPIKeyStatus() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.apiKeyStatus = nil
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"DroneBridge/internal/metrics"
+)
+
+// reauthWindow is the sliding window the per-drone re-auth cap applies to
+const reauthWindow = time.Minute
+
+// SetReauthLimits configures storm protection: a random delay of up to startupJitter
+// before the first AUTH and at most perMinute re-authentications per minute
+// (0 = unlimited). Must be called before Start().
+func (c *Client) SetReauthLimits(startupJitter time.Duration, perMinute int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.startupJitter = startupJitter
+	c.reauthPerMinute = perMinute
+}
+
+// startupDelay spreads the initial AUTH of a fleet over startupJitter so drones
+// powered on together don't hit the router in the same instant
+func (c *Client) startupDelay() {
+	c.mu.RLock()
+	jitter := c.startupJitter
+	clk := c.clock
+	c.mu.RUnlock()
+
+	if jitter <= 0 {
+		return
+	}
+	delay := time.Duration(rand.Int63n(int64(jitter)))
+	log.Printf("[AUTH] ⏳ Startup jitter: waiting %v before authenticating", delay.Round(time.Millisecond))
+	select {
+	case <-clk.After(delay):
+	case <-c.stopCh:
+	}
+}
+
+// noteServerWait records the router's WaitSec backoff from a rejected AUTH_ACK
+func (c *Client) noteServerWait(waitSec uint16) {
+	if waitSec == 0 {
+		return
+	}
+	c.mu.Lock()
+	until := c.clock.Now().Add(time.Duration(waitSec) * time.Second)
+	if until.After(c.authBlockedUntil) {
+		c.authBlockedUntil = until
+	}
+	c.mu.Unlock()
+
+	log.Printf("[AUTH] ⏸️ Router requested backoff of %ds", waitSec)
+	metrics.Global.AddLog("WARN", fmt.Sprintf("Router requested auth backoff of %ds", waitSec))
+}
+
+// serverWait returns how long the router asked us to hold off (0 = not blocked)
+func (c *Client) serverWait() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if wait := c.authBlockedUntil.Sub(c.clock.Now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// reauthenticate runs authenticate() unless the router's backoff is still active
+// or the per-minute re-auth cap has been reached
+func (c *Client) reauthenticate(reason string) error {
+	if wait := c.serverWait(); wait > 0 {
+		return fmt.Errorf("re-auth (%s) deferred: router backoff active for %v", reason, wait.Round(time.Second))
+	}
+
+	c.mu.Lock()
+	now := c.clock.Now()
+	i := 0
+	for i < len(c.reauthTimes) && now.Sub(c.reauthTimes[i]) >= reauthWindow {
+		i++
+	}
+	c.reauthTimes = c.reauthTimes[i:]
+	if c.reauthPerMinute > 0 && len(c.reauthTimes) >= c.reauthPerMinute {
+		retryIn := reauthWindow - now.Sub(c.reauthTimes[0])
+		c.mu.Unlock()
+		return fmt.Errorf("re-auth (%s) deferred: limit of %d per minute reached, next slot in %v",
+			reason, c.reauthPerMinute, retryIn.Round(time.Second))
+	}
+	c.reauthTimes = append(c.reauthTimes, now)
+	c.mu.Unlock()
+
+	if err := c.authenticate(); err != nil {
+		c.emit(EventReauthFailed, "ERROR", fmt.Sprintf("Re-authentication (%s) failed: %v", reason, err), map[string]interface{}{"reason": reason, "error": err.Error()})
+		return err
+	}
+	c.emit(EventReauth, "INFO", fmt.Sprintf("Re-authenticated (%s)", reason), map[string]interface{}{"reason": reason})
+	return nil
+}
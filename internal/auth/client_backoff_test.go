@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"DroneBridge/internal/testutil"
+)
+
+var testStart = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestClient builds a client around fakes without touching the replay state on disk
+func newTestClient(clk *testutil.FakeClock, d *testutil.FakeDialer) *Client {
+	return &Client{
+		host:      "router.test",
+		port:      5770,
+		droneUUID: "test-drone",
+		secret:    "test-secret",
+		clock:     clk,
+		dialer:    d,
+		stopCh:    make(chan struct{}),
+	}
+}
+
+func TestIsAuthenticatedExpires(t *testing.T) {
+	clk := testutil.NewFakeClock(testStart)
+	c := newTestClient(clk, testutil.NewFakeDialer("10.0.0.2"))
+
+	if c.IsAuthenticated() {
+		t.Fatal("authenticated without a session token")
+	}
+
+	c.sessionToken = "token"
+	c.expiresAt = testStart.Add(time.Minute)
+	if !c.IsAuthenticated() {
+		t.Fatal("not authenticated with a fresh session")
+	}
+	clk.Advance(59 * time.Second)
+	if !c.IsAuthenticated() {
+		t.Fatal("session expired a second early")
+	}
+	clk.Advance(time.Second)
+	if c.IsAuthenticated() {
+		t.Fatal("session still valid at its expiry time")
+	}
+}
+
+func TestServerWait(t *testing.T) {
+	clk := testutil.NewFakeClock(testStart)
+	c := newTestClient(clk, testutil.NewFakeDialer("10.0.0.2"))
+
+	tests := []struct {
+		name    string
+		waitSec uint16
+		advance time.Duration
+		want    time.Duration
+	}{
+		{"no backoff", 0, 0, 0},
+		{"router asks for 30s", 30, 0, 30 * time.Second},
+		{"counts down", 0, 10 * time.Second, 20 * time.Second},
+		{"shorter request keeps the longer wait", 5, 0, 20 * time.Second},
+		{"longer request extends it", 60, 0, 60 * time.Second},
+		{"expires", 0, 60 * time.Second, 0},
+	}
+	for _, tt := range tests {
+		c.noteServerWait(tt.waitSec)
+		clk.Advance(tt.advance)
+		if got := c.serverWait(); got != tt.want {
+			t.Errorf("%s: serverWait() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReauthenticateDeferredByServerWait(t *testing.T) {
+	clk := testutil.NewFakeClock(testStart)
+	d := testutil.NewFakeDialer("10.0.0.2")
+	d.FailWith(errors.New("router unreachable"))
+	c := newTestClient(clk, d)
+
+	c.noteServerWait(10)
+	err := c.reauthenticate("test")
+	if err == nil || !strings.Contains(err.Error(), "router backoff") {
+		t.Fatalf("reauthenticate() = %v, want router backoff error", err)
+	}
+	if n := len(d.Dials()); n != 0 {
+		t.Fatalf("dialed %d times during router backoff", n)
+	}
+
+	clk.Advance(10 * time.Second)
+	err = c.reauthenticate("test")
+	if err == nil || !strings.Contains(err.Error(), "connection failed") {
+		t.Fatalf("reauthenticate() = %v, want connection failure", err)
+	}
+	if n := len(d.Dials()); n != 1 {
+		t.Fatalf("dialed %d times after backoff expired, want 1", n)
+	}
+}
+
+func TestReauthenticatePerMinuteCap(t *testing.T) {
+	clk := testutil.NewFakeClock(testStart)
+	d := testutil.NewFakeDialer("10.0.0.2")
+	d.FailWith(errors.New("router unreachable"))
+	c := newTestClient(clk, d)
+	c.SetReauthLimits(0, 2)
+
+	for i := 0; i < 2; i++ {
+		if err := c.reauthenticate("test"); err == nil || !strings.Contains(err.Error(), "connection failed") {
+			t.Fatalf("attempt %d: reauthenticate() = %v, want connection failure", i+1, err)
+		}
+		clk.Advance(10 * time.Second)
+	}
+
+	err := c.reauthenticate("test")
+	if err == nil || !strings.Contains(err.Error(), "limit of 2 per minute") {
+		t.Fatalf("third attempt: reauthenticate() = %v, want per-minute limit", err)
+	}
+	if n := len(d.Dials()); n != 2 {
+		t.Fatalf("dialed %d times, want 2", n)
+	}
+
+	// The first attempt leaves the window 60s after it was made
+	clk.Advance(40 * time.Second)
+	if err := c.reauthenticate("test"); err == nil || !strings.Contains(err.Error(), "connection failed") {
+		t.Fatalf("after window: reauthenticate() = %v, want connection failure", err)
+	}
+	if n := len(d.Dials()); n != 3 {
+		t.Fatalf("dialed %d times after the window slid, want 3", n)
+	}
+}
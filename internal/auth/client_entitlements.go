@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"DroneBridge/internal/metrics"
+)
+
+// entitlementRefresh is how often the signed feature list is requested again
+const entitlementRefresh = time.Hour
+
+// EntitlementsPublicKey is the fleet server's Ed25519 public key (hex) that
+// ENTITLEMENTS lists are signed with. It is built into the binary so nothing
+// stored on the drone can produce a valid list; builds for another fleet
+// server set it with -ldflags "-X DroneBridge/internal/auth.EntitlementsPublicKey=<hex>".
+var EntitlementsPublicKey = "31278a295585d547e2a48d1098f9cf87f407817019bc34099f4a19832b497893"
+
+// requestEntitlements asks the router for the signed feature list after every new
+// session and then once per entitlementRefresh. The answer arrives like a push and
+// is handled by dispatchPush, so routers can also send a new list at any time.
+func (c *Client) requestEntitlements() {
+	c.mu.RLock()
+	callback := c.OnEntitlements
+	token := c.sessionToken
+	asked := c.entitlementsAsked
+	c.mu.RUnlock()
+	now := c.clock.Now()
+	if callback == nil || token == "" || (!asked.IsZero() && now.Sub(asked) < entitlementRefresh) {
+		return
+	}
+
+	if !c.tcpMu.TryLock() {
+		return
+	}
+	defer c.tcpMu.Unlock()
+	c.mu.Lock()
+	conn := c.conn
+	c.entitlementsAsked = now
+	c.mu.Unlock()
+	if conn == nil {
+		return
+	}
+	if _, err := conn.Write(SerializeEntitlementRequest(c.droneUUID, token)); err != nil {
+		log.Printf("[ENTITLEMENTS] Failed to send ENTITLEMENT_REQUEST: %v", err)
+	}
+}
+
+// handleEntitlements verifies an ENTITLEMENTS list and hands it to OnEntitlements
+func (c *Client) handleEntitlements(ent *Entitlements) {
+	if err := c.VerifyEntitlements(ent); err != nil {
+		log.Printf("[ENTITLEMENTS] Rejected list %d: %v", ent.Serial, err)
+		metrics.Global.AddLog("WARN", fmt.Sprintf("Rejected entitlements %d: %v", ent.Serial, err))
+		return
+	}
+
+	c.mu.RLock()
+	callback := c.OnEntitlements
+	c.mu.RUnlock()
+	if callback == nil {
+		return
+	}
+	if err := callback(ent); err != nil {
+		log.Printf("[ENTITLEMENTS] List %d not applied: %v", ent.Serial, err)
+	}
+}
+
+// VerifyEntitlements checks that a feature list was signed for this drone by
+// the fleet server, e.g. one loaded from the offline cache
+func (c *Client) VerifyEntitlements(ent *Entitlements) error {
+	return VerifyEntitlements(c.droneUUID, ent)
+}
+
+// VerifyEntitlements checks the Ed25519 signature of a feature list for droneUUID
+// against EntitlementsPublicKey
+func VerifyEntitlements(droneUUID string, ent *Entitlements) error {
+	key, err := hex.DecodeString(EntitlementsPublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("no valid entitlements public key in this build")
+	}
+	if len(ent.Signature) != ed25519.SignatureSize {
+		return fmt.Errorf("signature is %d bytes, want %d", len(ent.Signature), ed25519.SignatureSize)
+	}
+	if !ed25519.Verify(key, EntitlementsMessage(droneUUID, ent.Serial, ent.IssuedAt, ent.ExpiresAt, ent.List), ent.Signature) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// EntitlementsMessage is what the fleet server signs for an ENTITLEMENTS list:
+// "DroneUUID:Serial:IssuedAt:ExpiresAt:" followed by the raw feature list
+func EntitlementsMessage(droneUUID string, serial, issuedAt, expiresAt uint64, features []byte) []byte {
+	msg := fmt.Appendf(nil, "%s:%d:%d:%d:", droneUUID, serial, issuedAt, expiresAt)
+	return append(msg, features...)
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+// useEntitlementsKey swaps the built-in key for a test key and returns its private half
+func useEntitlementsKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := EntitlementsPublicKey
+	EntitlementsPublicKey = hex.EncodeToString(pub)
+	t.Cleanup(func() { EntitlementsPublicKey = old })
+	return priv
+}
+
+func TestVerifyEntitlements(t *testing.T) {
+	priv := useEntitlementsKey(t)
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	const drone = "drone-1"
+	sign := func(key ed25519.PrivateKey, uuid string, ent Entitlements) *Entitlements {
+		ent.Signature = ed25519.Sign(key, EntitlementsMessage(uuid, ent.Serial, ent.IssuedAt, ent.ExpiresAt, ent.List))
+		return &ent
+	}
+	base := Entitlements{Serial: 7, IssuedAt: 1700000000, ExpiresAt: 1800000000, List: []byte("video,relay")}
+
+	widened := sign(priv, drone, base)
+	widened.List = []byte("video,relay,precision_landing")
+	extended := sign(priv, drone, base)
+	extended.ExpiresAt++
+
+	tests := []struct {
+		name    string
+		ent     *Entitlements
+		wantErr bool
+	}{
+		{"signed for this drone", sign(priv, drone, base), false},
+		{"signed for another drone", sign(priv, "drone-2", base), true},
+		{"signed with another key", sign(otherKey, drone, base), true},
+		{"features added after signing", widened, true},
+		{"expiry moved after signing", extended, true},
+		{"missing signature", &base, true},
+	}
+	for _, tt := range tests {
+		if err := VerifyEntitlements(drone, tt.ent); (err != nil) != tt.wantErr {
+			t.Errorf("%s: VerifyEntitlements() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	EntitlementsPublicKey = ""
+	if err := VerifyEntitlements(drone, sign(priv, drone, base)); err == nil {
+		t.Error("VerifyEntitlements() without a built-in key accepted a list")
+	}
+}
+
+func TestParseEntitlements(t *testing.T) {
+	priv := useEntitlementsKey(t)
+	list := []byte("video, relay")
+	b := []byte{MsgEntitlements}
+	b = binary.LittleEndian.AppendUint64(b, 3)
+	b = binary.LittleEndian.AppendUint64(b, 1700000000)
+	b = binary.LittleEndian.AppendUint64(b, 1800000000)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(list)))
+	b = append(b, list...)
+	b = append(b, ed25519.Sign(priv, EntitlementsMessage("drone-1", 3, 1700000000, 1800000000, list))...)
+
+	if n, _, err := frameLength(b); err != nil || n != len(b) {
+		t.Fatalf("frameLength() = (%d, %v), want %d", n, err, len(b))
+	}
+	ent, err := ParseEntitlements(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ent.Features) != 2 || ent.Features[0] != "video" || ent.Features[1] != "relay" {
+		t.Errorf("Features = %q", ent.Features)
+	}
+	if err := VerifyEntitlements("drone-1", ent); err != nil {
+		t.Errorf("VerifyEntitlements() = %v", err)
+	}
+	if _, err := ParseEntitlements(b[:len(b)-1]); err == nil {
+		t.Error("ParseEntitlements() accepted a truncated signature")
+	}
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+)
+
+// LoadTLSConfig builds a client TLS config for certificate-based (mTLS) identity.
+// caFile may be empty to use the system roots; serverName defaults to the dialed host.
+func LoadTLSConfig(certFile, keyFile, caFile, serverName string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
+
+// SetTLS switches the client to certificate-based identity: the auth channel runs over
+// TLS with a client certificate and the HMAC challenge is skipped. Sessions and API keys
+// work exactly as in HMAC mode. Must be called before Start().
+func (c *Client) SetTLS(cfg *tls.Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tlsConfig = cfg
+}
+
+// dialAuth opens the auth TCP connection, wrapped in TLS when certificate identity is enabled
+func (c *Client) dialAuth() (net.Conn, error) {
+	addr := fmt.Sprintf("%s:%d", c.host, c.port)
+	conn, err := c.dialer.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil || c.tlsConfig == nil {
+		return conn, err
+	}
+
+	// Keepalive must be set on the raw socket before it is wrapped
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.SetKeepAlive(true)
+		tcpConn.SetKeepAlivePeriod(30 * time.Second)
+	}
+
+	cfg := c.tlsConfig.Clone()
+	if cfg.ServerName == "" {
+		cfg.ServerName = c.host
+	}
+
+	tlsConn := tls.Client(conn, cfg)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("TLS handshake failed: %w", err)
+	}
+
+	log.Printf("[AUTH] 🔐 mTLS established (%s)", tls.VersionName(tlsConn.ConnectionState().Version))
+	return tlsConn, nil
+}
+
+// pushExporterLabel names the TLS exporter (RFC 5705) that keys router pushes in mTLS mode
+const pushExporterLabel = "EXPORTER-DroneBridge-push"
+
+// tlsPushKey derives the HMAC key of CONFIG_PUSH and SIGNING_KEY in mTLS mode
+// from the TLS session they arrived on (exporter pushExporterLabel, context =
+// drone UUID, 32 bytes). Only the router whose certificate was verified in
+// that handshake shares it, and nothing stored on the drone can produce it.
+func tlsPushKey(conn net.Conn, droneUUID string) (string, error) {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return "", fmt.Errorf("push did not arrive over the mTLS connection")
+	}
+	state := tlsConn.ConnectionState()
+	if len(state.VerifiedChains) == 0 {
+		return "", fmt.Errorf("router certificate not verified")
+	}
+	key, err := state.ExportKeyingMaterial(pushExporterLabel, []byte(droneUUID), 32)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive push key: %w", err)
+	}
+	return string(key), nil
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// tlsPair returns both ends of an established TLS connection whose server
+// certificate the client verified
+func tlsPair(t *testing.T) (client, server *tls.Conn) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "router"},
+		DNSNames:     []string{"router"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	a, b := net.Pipe()
+	t.Cleanup(func() { a.Close(); b.Close() })
+	client = tls.Client(a, &tls.Config{RootCAs: roots, ServerName: "router"})
+	server = tls.Server(b, &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}})
+	errc := make(chan error, 1)
+	go func() { errc <- server.Handshake() }()
+	if err := client.Handshake(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	return client, server
+}
+
+func TestConfigPushKeyInMTLSMode(t *testing.T) {
+	client, server := tlsPair(t)
+	c := &Client{droneUUID: "drone-1", tlsConfig: &tls.Config{}}
+
+	key, err := c.configPushKey(client)
+	if err != nil {
+		t.Fatalf("configPushKey() = %v", err)
+	}
+
+	// The router derives the same key from its end of the session
+	state := server.ConnectionState()
+	routerKey, err := state.ExportKeyingMaterial(pushExporterLabel, []byte("drone-1"), 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay := []byte("log:\n  level: debug\n")
+	sig := ComputeConfigPushHMAC(string(routerKey), "drone-1", 1, 1700000000, overlay)
+	if !hmac.Equal(ComputeConfigPushHMAC(key, "drone-1", 1, 1700000000, overlay), sig) {
+		t.Fatal("drone and router derive different push keys")
+	}
+
+	// Another session yields another key, so pushes can't be replayed into it
+	other, _ := tlsPair(t)
+	otherKey, err := c.configPushKey(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherKey == key {
+		t.Error("two TLS sessions share a push key")
+	}
+
+	plain, _ := net.Pipe()
+	defer plain.Close()
+	if _, err := c.configPushKey(plain); err == nil {
+		t.Error("configPushKey() accepted a connection without TLS")
+	}
+}
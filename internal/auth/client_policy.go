@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"log"
+	"time"
+)
+
+// SetPolicyReporter reports the forwarding configuration built by build to the
+// router every interval, after every new session and whenever it changes, so
+// operations can audit what each drone forwards. Must be called before Start().
+func (c *Client) SetPolicyReporter(interval time.Duration, build func() ([]byte, error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policyEvery = interval
+	c.policyReport = build
+}
+
+// reportPolicy sends a POLICY_REPORT when one is due or the configuration changed
+func (c *Client) reportPolicy() {
+	c.mu.RLock()
+	build := c.policyReport
+	token := c.sessionToken
+	every := c.policyEvery
+	last := c.policyReportedAt
+	lastDigest := c.policyDigest
+	c.mu.RUnlock()
+	if build == nil || token == "" {
+		return
+	}
+
+	report, err := build()
+	if err != nil {
+		log.Printf("[POLICY] Failed to build POLICY_REPORT: %v", err)
+		return
+	}
+	digest := sha256.Sum256(report)
+	now := c.clock.Now()
+	if digest == lastDigest && !last.IsZero() && now.Sub(last) < every {
+		return
+	}
+
+	if !c.tcpMu.TryLock() {
+		return
+	}
+	defer c.tcpMu.Unlock()
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+	if conn == nil {
+		return
+	}
+	if _, err := conn.Write(SerializePolicyReport(c.droneUUID, token, uint64(now.Unix()), digest, report)); err != nil {
+		log.Printf("[POLICY] Failed to send POLICY_REPORT: %v", err)
+		return
+	}
+
+	c.mu.Lock()
+	c.policyReportedAt = now
+	c.policyDigest = digest
+	c.mu.Unlock()
+	if digest != lastDigest {
+		log.Printf("[POLICY] Reported forwarding configuration %x (%d bytes)", digest[:4], len(report))
+	}
+}
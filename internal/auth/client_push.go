@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"log"
+	"net"
+
+	"DroneBridge/internal/metrics"
+)
+
+// dispatchPush handles one router-initiated message (VIDEO_CONTROL, CONFIG_PUSH,
+// SIGNING_KEY, ENTITLEMENTS, USER_CONNECTED/USER_DISCONNECTED). It runs on the
+// reader of conn, so slow work is moved to its own goroutine.
+func (c *Client) dispatchPush(conn net.Conn, data []byte) {
+	switch data[0] {
+	case MsgVideoControl:
+		vc, err := ParseVideoControl(data)
+		if err != nil {
+			log.Printf("[VIDEO_CONTROL] Failed to parse VIDEO_CONTROL: %v", err)
+			return
+		}
+		c.mu.RLock()
+		callback := c.OnVideoControl
+		c.mu.RUnlock()
+
+		// Starting a pipeline takes seconds - don't hold the connection meanwhile
+		go func() {
+			ack := &VideoControlAck{Action: vc.Action, CameraID: vc.CameraID, Result: ResultSuccess}
+			if callback == nil {
+				ack.Result, ack.Message = ResultFailure, "video control not supported"
+			} else if err := callback(vc); err != nil {
+				ack.Result, ack.Message = ResultFailure, err.Error()
+			}
+			c.sendPushReply(SerializeVideoControlAck(ack))
+		}()
+
+	case MsgConfigPush:
+		push, err := ParseConfigPush(data)
+		if err != nil {
+			log.Printf("[CONFIG_PUSH] Failed to parse CONFIG_PUSH: %v", err)
+			return
+		}
+		go c.handleConfigPush(conn, push)
+
+	case MsgSigningKey:
+		push, err := ParseSigningKey(data)
+		if err != nil {
+			log.Printf("[SIGNING_KEY] Failed to parse SIGNING_KEY: %v", err)
+			return
+		}
+		go c.handleSigningKey(conn, push)
+
+	case MsgEntitlements:
+		ent, err := ParseEntitlements(data)
+		if err != nil {
+			log.Printf("[ENTITLEMENTS] Failed to parse ENTITLEMENTS: %v", err)
+			return
+		}
+		go c.handleEntitlements(ent)
+
+	case MsgUserConnected, MsgUserDisconnected:
+		un, err := ParseUserNotification(data)
+		if err != nil {
+			log.Printf("[USER] Failed to parse user notification: %v", err)
+			return
+		}
+		// A user taking or releasing the key changes its status
+		c.InvalidateAPIKeyStatus()
+		if un.Connected {
+			c.emit(EventAPIKeyUser, "INFO", "User connected with the API key", map[string]interface{}{"user": un.UserUUID, "connected": true})
+		} else {
+			c.emit(EventAPIKeyUser, "INFO", "User disconnected", map[string]interface{}{"user": un.UserUUID, "connected": false})
+		}
+		c.mu.RLock()
+		callback := c.OnUserLeave
+		if un.Connected {
+			callback = c.OnUserConnect
+		}
+		c.mu.RUnlock()
+		if callback != nil {
+			callback(un.UserUUID)
+		}
+
+	default:
+		log.Printf("[ROUTER] Ignoring unexpected message 0x%02x (%d bytes)", data[0], len(data))
+	}
+}
+
+// handleConfigPush verifies a CONFIG_PUSH, hands the overlay to OnConfigPush and acknowledges it
+func (c *Client) handleConfigPush(conn net.Conn, push *ConfigPush) {
+	ack := &ConfigPushAck{PushID: push.PushID, Result: ResultFailure}
+	defer func() { c.sendPushReply(SerializeConfigPushAck(ack)) }()
+
+	key, err := c.configPushKey(conn)
+	if err != nil {
+		ack.Message = err.Error()
+		log.Printf("[CONFIG_PUSH] Rejected push %d: %v", push.PushID, err)
+		return
+	}
+	expected := ComputeConfigPushHMAC(key, c.droneUUID, push.PushID, push.Timestamp, push.Overlay)
+	if !hmac.Equal(expected, push.HMAC) {
+		ack.Message = "invalid signature"
+		log.Printf("[CONFIG_PUSH] Rejected push %d: invalid signature", push.PushID)
+		metrics.Global.AddLog("WARN", fmt.Sprintf("Rejected config push %d: invalid signature", push.PushID))
+		return
+	}
+	if ok, err := c.replay.AcceptConfigPush(push.PushID); err != nil {
+		ack.Message = err.Error()
+		log.Printf("[CONFIG_PUSH] Rejected push %d: %v", push.PushID, err)
+		return
+	} else if !ok {
+		ack.Message = "push ID already used"
+		log.Printf("[CONFIG_PUSH] Rejected replayed push %d", push.PushID)
+		return
+	}
+
+	c.mu.RLock()
+	callback := c.OnConfigPush
+	c.mu.RUnlock()
+	if callback == nil {
+		ack.Message = "remote configuration not supported"
+		return
+	}
+	note, err := callback(push.Overlay)
+	if err != nil {
+		ack.Message = err.Error()
+		log.Printf("[CONFIG_PUSH] Push %d not applied: %v", push.PushID, err)
+		return
+	}
+	ack.Result, ack.Message = ResultSuccess, note
+	log.Printf("[CONFIG_PUSH] ✅ Push %d applied", push.PushID)
+}
+
+// handleSigningKey verifies a SIGNING_KEY push, hands the key to OnSigningKey and acknowledges it
+func (c *Client) handleSigningKey(conn net.Conn, push *SigningKeyPush) {
+	ack := &SigningKeyAck{KeyID: push.KeyID, Result: ResultFailure}
+	defer func() { c.sendPushReply(SerializeSigningKeyAck(ack)) }()
+
+	key, err := c.configPushKey(conn)
+	if err != nil {
+		ack.Message = err.Error()
+		log.Printf("[SIGNING_KEY] Rejected key %d: %v", push.KeyID, err)
+		return
+	}
+	expected := ComputeSigningKeyHMAC(key, c.droneUUID, push.KeyID, push.Timestamp, push.Key)
+	if !hmac.Equal(expected, push.HMAC) {
+		ack.Message = "invalid signature"
+		log.Printf("[SIGNING_KEY] Rejected key %d: invalid signature", push.KeyID)
+		metrics.Global.AddLog("WARN", fmt.Sprintf("Rejected signing key %d: invalid signature", push.KeyID))
+		return
+	}
+	if ok, err := c.replay.AcceptSigningKey(push.KeyID); err != nil {
+		ack.Message = err.Error()
+		log.Printf("[SIGNING_KEY] Rejected key %d: %v", push.KeyID, err)
+		return
+	} else if !ok {
+		ack.Message = "key ID already used"
+		log.Printf("[SIGNING_KEY] Rejected replayed key %d", push.KeyID)
+		return
+	}
+
+	c.mu.RLock()
+	callback := c.OnSigningKey
+	c.mu.RUnlock()
+	if callback == nil {
+		ack.Message = "MAVLink signing not enabled"
+		return
+	}
+	if err := callback(push.Key); err != nil {
+		ack.Message = err.Error()
+		log.Printf("[SIGNING_KEY] Key %d not installed: %v", push.KeyID, err)
+		return
+	}
+	ack.Result = ResultSuccess
+	log.Printf("[SIGNING_KEY] ✅ Key %d installed", push.KeyID)
+}
+
+// configPushKey returns the key pushes arriving on conn are signed with: the
+// combined key of AUTH_RESPONSE, or in mTLS mode (no secret) one derived from
+// the TLS session (see tlsPushKey)
+func (c *Client) configPushKey(conn net.Conn) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tlsConfig != nil {
+		return tlsPushKey(conn, c.droneUUID)
+	}
+	if c.secret == "" {
+		key, err := LoadSecret(c.droneUUID)
+		if err != nil {
+			return "", fmt.Errorf("no secret key to verify the push: %w", err)
+		}
+		c.secret = key
+	}
+	if c.sharedSecret != "" {
+		return computeCombinedKey(c.sharedSecret, c.secret), nil
+	}
+	return c.secret, nil
+}
+
+// sendPushReply writes a reply to a router-initiated message
+func (c *Client) sendPushReply(packet []byte) {
+	c.tcpMu.Lock()
+	defer c.tcpMu.Unlock()
+
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+	if conn == nil {
+		log.Printf("[ROUTER] Reply 0x%02x dropped: not connected", packet[0])
+		return
+	}
+	if _, err := conn.Write(packet); err != nil {
+		log.Printf("[ROUTER] Failed to send reply 0x%02x: %v", packet[0], err)
+	}
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net"
+	"time"
+
+	"DroneBridge/internal/metrics"
+)
+
+// Routers that don't implement RATE_NEGOTIATE simply don't answer. After a timeout
+// new sessions skip the request for a while so re-authentication isn't slowed
+// down, but a router that was only busy (or gets upgraded) is asked again later.
+const (
+	rateRetryMin = time.Minute
+	rateRetryMax = 30 * time.Minute
+)
+
+// negotiateRates asks the router for its desired telemetry rates on the auth connection
+func (c *Client) negotiateRates(conn net.Conn) {
+	c.mu.RLock()
+	token := c.sessionToken
+	skip := c.clock.Now().Before(c.rateRetryAt)
+	caps := c.compressionCaps
+	hbID, hbAckID, hbLayouts := c.heartbeatID, c.heartbeatAckID, c.heartbeatLayouts
+	c.mu.RUnlock()
+
+	if skip || token == "" {
+		return
+	}
+
+	req := &RateNegotiateRequest{
+		DroneUUID:        c.droneUUID,
+		SessionToken:     token,
+		CompressionCaps:  caps,
+		HeartbeatID:      hbID,
+		HeartbeatAckID:   hbAckID,
+		HeartbeatLayouts: hbLayouts,
+	}
+
+	reply := c.readerFor(conn).expect(MsgRatePolicy)
+	if _, err := conn.Write(SerializeRateNegotiate(req)); err != nil {
+		reply.cancel()
+		log.Printf("[RATES] Failed to send RATE_NEGOTIATE: %v", err)
+		return
+	}
+
+	data, err := reply.wait(2 * time.Second)
+	if err != nil {
+		if errors.Is(err, errReplyTimeout) {
+			c.mu.Lock()
+			c.rateBackoff = min(max(2*c.rateBackoff, rateRetryMin), rateRetryMax)
+			c.rateRetryAt = c.clock.Now().Add(c.rateBackoff)
+			backoff := c.rateBackoff
+			c.mu.Unlock()
+			log.Printf("[RATES] ⓘ Router did not answer RATE_NEGOTIATE - using local limits, asking again after %v", backoff)
+		} else {
+			log.Printf("[RATES] Failed to read RATE_POLICY: %v", err)
+		}
+		return
+	}
+
+	policy, err := ParseRatePolicy(data)
+	if err != nil {
+		log.Printf("[RATES] Failed to parse RATE_POLICY: %v", err)
+		return
+	}
+
+	log.Printf("[RATES] ✅ Router rate policy: max %d B/s, %d message caps", policy.MaxBytesPerSec, len(policy.Rates))
+	metrics.Global.AddLog("INFO", "Router rate policy received")
+
+	c.mu.Lock()
+	c.ratePolicy = policy
+	c.rateBackoff, c.rateRetryAt = 0, time.Time{}
+	callback := c.OnRatePolicy
+	c.mu.Unlock()
+
+	if callback != nil {
+		callback(policy)
+	}
+}
+
+// SetCompressionCaps sets the batch compression algorithms offered to the router
+// (bitmask, bit N = algorithm N). Must be called before Start().
+func (c *Client) SetCompressionCaps(caps byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.compressionCaps = caps
+}
+
+// SetHeartbeatOffer sets the SESSION_HEARTBEAT identity offered to the router
+// (message IDs and a bitmask of supported layouts). Must be called before Start().
+func (c *Client) SetHeartbeatOffer(id, ackID uint32, layouts byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.heartbeatID = id
+	c.heartbeatAckID = ackID
+	c.heartbeatLayouts = layouts
+}
+
+// GetRatePolicy returns the last rate policy advertised by the router (nil if none)
+func (c *Client) GetRatePolicy() *RatePolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ratePolicy
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"DroneBridge/internal/testutil"
+)
+
+// negotiateAsync runs negotiateRates and reports whether the router saw a RATE_NEGOTIATE
+func negotiateAsync(t *testing.T, c *Client, clk *testutil.FakeClock, router net.Conn, answer []byte) bool {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		c.negotiateRates(c.conn)
+		close(done)
+	}()
+
+	asked := make(chan bool, 1)
+	go func() {
+		buf := make([]byte, 4096)
+		router.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, err := router.Read(buf)
+		router.SetReadDeadline(time.Time{})
+		asked <- err == nil && n > 0 && buf[0] == MsgRateNegotiate
+		if err == nil && answer != nil {
+			router.Write(answer)
+		}
+	}()
+
+	sent := <-asked
+	if sent && answer == nil {
+		// Let the request time out
+		for clk.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		clk.Advance(2 * time.Second)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("negotiateRates did not return")
+	}
+	return sent
+}
+
+func TestRateNegotiationRetriesAfterBackoff(t *testing.T) {
+	clk := testutil.NewFakeClock(testStart)
+	c, router, _ := newPipeClient(t)
+	c.clock = clk
+
+	if !negotiateAsync(t, c, clk, router, nil) {
+		t.Fatal("first session did not send RATE_NEGOTIATE")
+	}
+	if c.rateBackoff != rateRetryMin {
+		t.Fatalf("backoff = %v, want %v", c.rateBackoff, rateRetryMin)
+	}
+
+	if negotiateAsync(t, c, clk, router, nil) {
+		t.Fatal("RATE_NEGOTIATE sent again during the backoff")
+	}
+
+	clk.Advance(rateRetryMin)
+	if !negotiateAsync(t, c, clk, router, nil) {
+		t.Fatal("RATE_NEGOTIATE not retried after the backoff")
+	}
+	if c.rateBackoff != 2*rateRetryMin {
+		t.Fatalf("backoff = %v, want %v", c.rateBackoff, 2*rateRetryMin)
+	}
+
+	clk.Advance(2 * rateRetryMin)
+	policy := []byte{MsgRatePolicy, 0x10, 0x27, 0, 0, 0, 0}
+	if !negotiateAsync(t, c, clk, router, policy) {
+		t.Fatal("RATE_NEGOTIATE not retried after the second backoff")
+	}
+	if c.ratePolicy == nil || c.ratePolicy.MaxBytesPerSec != 10000 {
+		t.Fatalf("rate policy = %+v, want max 10000 B/s", c.ratePolicy)
+	}
+	if c.rateBackoff != 0 || !c.rateRetryAt.IsZero() {
+		t.Fatal("backoff not reset by an answer")
+	}
+}
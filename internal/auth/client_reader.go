@@ -0,0 +1,372 @@
+package auth
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+// errReplyTimeout is returned when the router does not answer a request in time
+var errReplyTimeout = errors.New("timed out waiting for the router")
+
+// routerReader is the only reader of an auth connection. Routers answering
+// AuthInitFlagFramed wrap every message in a length-prefixed MsgFrame; older
+// ones send bare messages, which are framed by type (see frameLength). Replies
+// go to the request waiting for them and everything else to dispatchPush. A
+// push arriving during a request/response exchange is therefore never taken
+// for the reply, and messages split over or packed into TCP segments arrive whole.
+type routerReader struct {
+	c    *Client
+	conn net.Conn
+
+	framed bool // The router sent a MsgFrame: bare messages are a protocol error from now on
+
+	mu      sync.Mutex
+	waiters map[byte]*replyWaiter // Reply type -> request waiting for it
+	err     error                 // Why the connection stopped (set before done closes)
+	done    chan struct{}
+}
+
+// replyWaiter is one request waiting for its reply
+type replyWaiter struct {
+	r     *routerReader
+	types []byte
+	ch    chan []byte
+}
+
+// readerFor returns the reader of conn, starting one if conn is new
+func (c *Client) readerFor(conn net.Conn) *routerReader {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reader == nil || c.reader.conn != conn {
+		c.reader = &routerReader{
+			c:       c,
+			conn:    conn,
+			waiters: make(map[byte]*replyWaiter),
+			done:    make(chan struct{}),
+		}
+		go c.reader.run()
+	}
+	return c.reader
+}
+
+// expect registers interest in the next message of any of types. Call it before
+// sending the request so a fast reply can't arrive unclaimed.
+func (r *routerReader) expect(types ...byte) *replyWaiter {
+	w := &replyWaiter{r: r, types: types, ch: make(chan []byte, 1)}
+	r.mu.Lock()
+	for _, t := range types {
+		r.waiters[t] = w
+	}
+	r.mu.Unlock()
+	return w
+}
+
+// wait returns the reply, the connection's error, or errReplyTimeout
+func (w *replyWaiter) wait(timeout time.Duration) ([]byte, error) {
+	defer w.cancel()
+	select {
+	case data := <-w.ch:
+		return data, nil
+	case <-w.r.done:
+		return nil, w.r.err
+	case <-w.r.c.clock.After(timeout):
+		return nil, errReplyTimeout
+	}
+}
+
+// cancel withdraws the waiter (a reply arriving later is logged and dropped)
+func (w *replyWaiter) cancel() {
+	w.r.mu.Lock()
+	defer w.r.mu.Unlock()
+	for _, t := range w.types {
+		if w.r.waiters[t] == w {
+			delete(w.r.waiters, t)
+		}
+	}
+}
+
+// run reads the connection until it fails
+func (r *routerReader) run() {
+	var pending []byte
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.conn.Read(buf)
+		pending = append(pending, buf[:n]...)
+		for len(pending) > 0 {
+			size, msgType, msg, ferr := r.next(pending)
+			if size == 0 {
+				break // Rest of the message is still in flight
+			}
+			if ferr != nil {
+				log.Printf("[ROUTER] Discarding %d bytes: %v", size, ferr)
+			} else {
+				r.deliver(msgType, append([]byte(nil), msg...))
+			}
+			pending = append(pending[:0], pending[size:]...)
+		}
+		if err != nil {
+			r.mu.Lock()
+			r.err = err
+			r.mu.Unlock()
+			close(r.done)
+			return
+		}
+	}
+}
+
+// next splits the message at the start of b off: it returns how many bytes it
+// takes, its type and the message, 0 when more bytes are needed, or an error
+// with the number of bytes to drop - only the bad frame, or up to where the
+// next message may start when bare bytes can't be framed
+func (r *routerReader) next(b []byte) (int, byte, []byte, error) {
+	if b[0] == MsgFrame {
+		r.framed = true
+		if len(b) < 3 {
+			return 0, 0, nil, nil
+		}
+		size := 3 + int(binary.LittleEndian.Uint16(b[1:3]))
+		if len(b) < size {
+			return 0, 0, nil, nil
+		}
+		msg := b[3:size]
+		if len(msg) == 0 || msg[0] == MsgFrame || !isRouterMessage(msg[0]) {
+			return size, 0, nil, fmt.Errorf("frame of %d bytes holds no router message", len(msg))
+		}
+		return size, msg[0], msg, nil
+	}
+
+	if r.framed {
+		return resync(b, func(c byte) bool { return c == MsgFrame }), 0, nil, fmt.Errorf("bare message 0x%02x from a framing router", b[0])
+	}
+	size, msgType, err := frameLength(b)
+	if err != nil {
+		return resync(b, func(c byte) bool { return c == MsgFrame || isRouterMessage(c) }), 0, nil, err
+	}
+	return size, msgType, b[:size], nil
+}
+
+// resync returns how many bytes to drop before the next byte that may start a message
+func resync(b []byte, start func(byte) bool) int {
+	for i := 1; i < len(b); i++ {
+		if start(b[i]) {
+			return i
+		}
+	}
+	return len(b)
+}
+
+// deliver hands a message to the request waiting for its type, or to dispatchPush
+func (r *routerReader) deliver(msgType byte, data []byte) {
+	r.mu.Lock()
+	w := r.waiters[msgType]
+	r.mu.Unlock()
+	if w == nil {
+		r.c.dispatchPush(r.conn, data)
+		return
+	}
+	w.cancel()
+	w.ch <- data
+}
+
+// isRouterMessage reports whether b starts a message the router sends
+func isRouterMessage(b byte) bool {
+	switch b {
+	case MsgAuthChallenge, MsgAuthAck, MsgSessionAck, MsgSessionRefreshAck,
+		MsgAPIKeyResponse, MsgAPIKeyRevokeAck, MsgAPIKeyStatusResp, MsgAPIKeyDeleteAck,
+		MsgUserConnected, MsgUserDisconnected, MsgRatePolicy, MsgVideoControl,
+		MsgConfigPush, MsgSigningKey, MsgEntitlements, MsgRegisterChallenge, MsgRegisterAck:
+		return true
+	}
+	return false
+}
+
+// frameScan walks the fields of a message at the start of b
+type frameScan struct {
+	b     []byte
+	n     int
+	short bool // b ends inside the message
+}
+
+// need consumes k bytes
+func (s *frameScan) need(k int) {
+	if s.short || len(s.b) < s.n+k {
+		s.short = true
+		return
+	}
+	s.n += k
+}
+
+// u16 consumes a 2-byte little-endian length and returns it
+func (s *frameScan) u16() int {
+	s.need(2)
+	if s.short {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint16(s.b[s.n-2 : s.n]))
+}
+
+// lenPrefixed consumes [LEN:2][DATA:LEN]
+func (s *frameScan) lenPrefixed() {
+	s.need(s.u16())
+}
+
+// optional consumes a trailing field of k bytes that older routers omit. A
+// message ending exactly here, or followed by the start of another message,
+// has no such field.
+func (s *frameScan) optional(k int) bool {
+	if s.short {
+		return false
+	}
+	rest := len(s.b) - s.n
+	switch {
+	case rest == 0:
+		return false
+	case rest >= k && (rest == k || isRouterMessage(s.b[s.n+k])):
+		s.n += k
+		return true
+	case isRouterMessage(s.b[s.n]):
+		return false
+	case rest >= k:
+		s.n += k
+		return true
+	}
+	s.short = true
+	return false
+}
+
+// optionalLenPrefixed consumes an optional trailing [LEN:2][DATA:LEN]
+func (s *frameScan) optionalLenPrefixed() bool {
+	if s.short || len(s.b)-s.n < 2 {
+		return s.optional(2)
+	}
+	return s.optional(2 + int(binary.LittleEndian.Uint16(s.b[s.n:s.n+2])))
+}
+
+// frameLength returns the length and type of the bare router message at the
+// start of b, 0 when more bytes are needed, or an error when b doesn't start
+// with one. Formats are those parsed in protocol.go. Routers that don't frame
+// leave trailing optional fields to be guessed (see frameScan.optional).
+func frameLength(b []byte) (int, byte, error) {
+	if len(b) == 0 {
+		return 0, 0, nil
+	}
+
+	// API_KEY_RESPONSE may come as [LENGTH:2][TYPE:1]...
+	if !isRouterMessage(b[0]) {
+		if len(b) < 3 {
+			return 0, 0, nil
+		}
+		if b[2] != MsgAPIKeyResponse {
+			return 0, 0, fmt.Errorf("unknown message type 0x%02x", b[0])
+		}
+		size := 2 + int(binary.LittleEndian.Uint16(b[0:2]))
+		if len(b) < size {
+			return 0, 0, nil
+		}
+		return size, MsgAPIKeyResponse, nil
+	}
+
+	s := &frameScan{b: b, n: 1}
+	switch b[0] {
+	case MsgAuthChallenge, MsgRegisterChallenge:
+		s.lenPrefixed() // Nonce
+		s.need(2)       // Timeout
+
+	case MsgAuthAck:
+		s.need(1)
+		if !s.short && b[1] == ResultSuccess {
+			s.lenPrefixed() // Token
+			s.need(8 + 2)   // Expires, interval
+		} else if s.optional(1) { // Error code
+			s.optional(2) // Wait seconds
+		}
+
+	case MsgSessionAck:
+		s.need(1)
+		if !s.short && b[1] == ResultSuccess {
+			s.lenPrefixed()
+			s.need(8 + 2)
+		} else {
+			s.optional(1)
+		}
+
+	case MsgSessionRefreshAck:
+		s.need(1)
+		if !s.short && b[1] == ResultSuccess {
+			s.need(8 + 2)
+		} else {
+			s.optional(1)
+		}
+
+	case MsgRegisterAck:
+		s.need(1)
+		if !s.short && b[1] == ResultSuccess {
+			s.lenPrefixed() // Secret
+			s.lenPrefixed() // Token
+			s.need(8 + 2)
+			s.optional(32) // Signing key
+		} else {
+			s.optional(1)
+		}
+
+	case MsgAPIKeyResponse:
+		s.need(2) // Result, error code
+		if !s.short && b[1] == ResultSuccess {
+			s.lenPrefixed() // Key
+			s.need(8)
+		}
+
+	case MsgAPIKeyRevokeAck:
+		s.need(1)
+		if !s.short && b[1] != ResultSuccess {
+			s.optional(1)
+		}
+
+	case MsgAPIKeyStatusResp:
+		s.need(1)
+		s.lenPrefixed() // Status
+		s.lenPrefixed() // Key
+		if !s.short && b[1] == 0x01 && s.optional(8) && s.optional(8) && s.optionalLenPrefixed() {
+			s.optional(8)
+		}
+
+	case MsgAPIKeyDeleteAck:
+		s.need(2)
+
+	case MsgUserConnected, MsgUserDisconnected:
+		s.lenPrefixed()
+
+	case MsgRatePolicy:
+		s.need(4)
+		s.need(6 * s.u16())
+		if s.optional(1) { // Compression
+			s.optional(9) // SESSION_HEARTBEAT identity
+		}
+
+	case MsgVideoControl:
+		s.need(11)
+
+	case MsgConfigPush:
+		s.need(16)
+		s.lenPrefixed() // Overlay
+		s.need(32)
+
+	case MsgSigningKey:
+		s.need(80)
+
+	case MsgEntitlements:
+		s.need(24)
+		s.lenPrefixed() // Feature list
+		s.need(64)      // Ed25519 signature
+	}
+
+	if s.short {
+		return 0, 0, nil
+	}
+	return s.n, b[0], nil
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"DroneBridge/internal/clock"
+)
+
+func userPush(msgType byte, uuid string) []byte {
+	b := []byte{msgType}
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(uuid)))
+	return append(b, uuid...)
+}
+
+func refreshAck(expires uint64) []byte {
+	b := []byte{MsgSessionRefreshAck, ResultSuccess}
+	b = binary.LittleEndian.AppendUint64(b, expires)
+	return binary.LittleEndian.AppendUint16(b, 30)
+}
+
+func TestFrameLength(t *testing.T) {
+	ack := refreshAck(1700000000)
+	push := userPush(MsgUserConnected, "user-1")
+	authFail := []byte{MsgAuthAck, ResultFailure, ErrSessionExpired, 10, 0}
+	policy := []byte{MsgRatePolicy, 0, 0, 0, 0, 0, 0}
+	apiKeyEnvelope := []byte{3, 0, MsgAPIKeyResponse, ResultFailure, 0x05}
+
+	tests := []struct {
+		name     string
+		in       []byte
+		wantLen  int
+		wantType byte
+		wantErr  bool
+	}{
+		{"empty", nil, 0, 0, false},
+		{"complete reply", ack, len(ack), MsgSessionRefreshAck, false},
+		{"partial reply", ack[:5], 0, 0, false},
+		{"reply followed by push", append(append([]byte(nil), ack...), push...), len(ack), MsgSessionRefreshAck, false},
+		{"push", push, len(push), MsgUserConnected, false},
+		{"failure with wait", authFail, len(authFail), MsgAuthAck, false},
+		{"failure without optional fields", authFail[:2], 2, MsgAuthAck, false},
+		{"failure followed by push", append([]byte{MsgSessionAck, ResultFailure}, push...), 2, MsgSessionAck, false},
+		{"rate policy without compression", policy, len(policy), MsgRatePolicy, false},
+		{"rate policy with compression", append(append([]byte(nil), policy...), 1), len(policy) + 1, MsgRatePolicy, false},
+		{"length-prefixed API key response", apiKeyEnvelope, len(apiKeyEnvelope), MsgAPIKeyResponse, false},
+		{"unknown type", []byte{0xEE, 0x01, 0x02}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		n, msgType, err := frameLength(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if n != tt.wantLen || msgType != tt.wantType {
+			t.Errorf("%s: frameLength() = (%d, 0x%02x), want (%d, 0x%02x)", tt.name, n, msgType, tt.wantLen, tt.wantType)
+		}
+	}
+}
+
+// newPipeClient returns a running client whose auth connection is one end of a pipe
+func newPipeClient(t *testing.T) (*Client, net.Conn, chan string) {
+	t.Helper()
+	local, router := net.Pipe()
+	t.Cleanup(func() { local.Close(); router.Close() })
+
+	users := make(chan string, 8)
+	c := &Client{
+		droneUUID:    "test-drone",
+		sessionToken: "token",
+		conn:         local,
+		running:      true,
+		clock:        clock.Real,
+		stopCh:       make(chan struct{}),
+	}
+	c.OnUserConnect = func(uuid string) { users <- uuid }
+	return c, router, users
+}
+
+func expectUser(t *testing.T, users chan string, want string) {
+	t.Helper()
+	select {
+	case got := <-users:
+		if got != want {
+			t.Fatalf("user push %q, want %q", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("user push %q not delivered", want)
+	}
+}
+
+func TestPushDuringRefreshIsNotTakenForTheReply(t *testing.T) {
+	c, router, users := newPipeClient(t)
+
+	go func() {
+		buf := make([]byte, 4096)
+		if _, err := router.Read(buf); err != nil {
+			return
+		}
+		// Two pushes and the reply in one segment
+		var out []byte
+		out = append(out, userPush(MsgUserConnected, "user-1")...)
+		out = append(out, userPush(MsgUserConnected, "user-2")...)
+		out = append(out, refreshAck(uint64(time.Now().Add(time.Hour).Unix()))...)
+		router.Write(out)
+	}()
+
+	if err := c.sendRefresh(); err != nil {
+		t.Fatalf("sendRefresh() = %v", err)
+	}
+	expectUser(t, users, "user-1")
+	expectUser(t, users, "user-2")
+}
+
+func TestSplitAndLargePushes(t *testing.T) {
+	c, router, users := newPipeClient(t)
+	c.readerFor(c.conn)
+
+	// Split inside the header and inside the UUID
+	push := userPush(MsgUserConnected, "user-split")
+	for _, part := range [][]byte{push[:2], push[2:7], push[7:]} {
+		router.Write(part)
+	}
+	expectUser(t, users, "user-split")
+
+	// Larger than a single read
+	big := strings.Repeat("u", 6000)
+	router.Write(userPush(MsgUserConnected, big))
+	expectUser(t, users, big)
+}
+
+func TestReplyWaitTimesOutAndLateReplyIsDropped(t *testing.T) {
+	c, router, users := newPipeClient(t)
+	r := c.readerFor(c.conn)
+
+	w := r.expect(MsgSessionRefreshAck)
+	if _, err := w.wait(10 * time.Millisecond); err != errReplyTimeout {
+		t.Fatalf("wait() = %v, want errReplyTimeout", err)
+	}
+
+	// The late reply must not be handed to the next request for something else
+	next := r.expect(MsgAPIKeyStatusResp)
+	defer next.cancel()
+	router.Write(append(refreshAck(1), userPush(MsgUserConnected, "after")...))
+	expectUser(t, users, "after")
+	select {
+	case data := <-next.ch:
+		t.Fatalf("unrelated reply delivered: % x", data)
+	default:
+	}
+
+	router.Close()
+	if _, err := r.expect(MsgSessionRefreshAck).wait(time.Second); err == nil || err == errReplyTimeout {
+		t.Fatalf("wait() on a closed connection = %v, want the read error", err)
+	}
+}
+
+func framed(msg []byte) []byte {
+	b := []byte{MsgFrame}
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(msg)))
+	return append(b, msg...)
+}
+
+func TestReaderNext(t *testing.T) {
+	push := userPush(MsgUserConnected, "user-1")
+	authFail := []byte{MsgAuthAck, ResultFailure, ErrSessionExpired, 10, 0}
+	// A wait-seconds field whose first byte is a message type is still part of the frame
+	ambiguous := []byte{MsgAuthAck, ResultFailure, ErrRateLimited, MsgUserConnected, 0}
+
+	tests := []struct {
+		name     string
+		framed   bool // Reader already saw a MsgFrame
+		in       []byte
+		wantSize int
+		wantMsg  []byte
+		wantErr  bool
+	}{
+		{"framed push", false, framed(push), len(push) + 3, push, false},
+		{"framed header in flight", false, framed(push)[:2], 0, nil, false},
+		{"framed body in flight", false, framed(push)[:6], 0, nil, false},
+		{"frame ending before an optional field", false, framed(authFail[:2]), 5, authFail[:2], false},
+		{"optional field starting like a message", false, framed(ambiguous), len(ambiguous) + 3, ambiguous, false},
+		{"bad frame dropped alone", false, append(framed([]byte{0xEE, 1}), framed(push)...), 5, nil, true},
+		{"empty frame", false, framed(nil), 3, nil, true},
+		{"bare message from a framing router", true, append([]byte{0xEE, 0xEE}, framed(push)...), 2, nil, true},
+		{"legacy garbage before a push", false, append([]byte{0xEE, 0xEE}, push...), 2, nil, true},
+		{"legacy push", false, push, len(push), push, false},
+	}
+	for _, tt := range tests {
+		r := &routerReader{framed: tt.framed}
+		size, _, msg, err := r.next(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if size != tt.wantSize || string(msg) != string(tt.wantMsg) {
+			t.Errorf("%s: next() = (%d, % x), want (%d, % x)", tt.name, size, msg, tt.wantSize, tt.wantMsg)
+		}
+	}
+}
+
+func TestFramedPushesAfterABadFrame(t *testing.T) {
+	c, router, users := newPipeClient(t)
+	c.readerFor(c.conn)
+
+	out := framed([]byte{0xEE, 1, 2})
+	out = append(out, framed(userPush(MsgUserConnected, "after-bad"))...)
+	router.Write(out)
+	expectUser(t, users, "after-bad")
+
+	// Split inside the length prefix
+	f := framed(userPush(MsgUserConnected, "split"))
+	router.Write(f[:2])
+	router.Write(f[2:])
+	expectUser(t, users, "split")
+}
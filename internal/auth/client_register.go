@@ -0,0 +1,286 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+)
+
+// Registration retry policy for transient failures (network errors, rate limiting,
+// router-side errors). Rejections such as a bad shared secret are not retried.
+const (
+	registerAttempts  = 5
+	registerBaseDelay = 2 * time.Second
+	registerMaxDelay  = 30 * time.Second
+)
+
+// Registration outcomes (RegisterResult.Result)
+const (
+	RegisterNew      = "registered"         // First secret issued
+	RegisterReplaced = "re_registered"      // Secret replaced with --re-register
+	RegisterSkipped  = "already_registered" // A secret was already stored; nothing sent
+)
+
+// ErrSecretStore marks a registration whose secret could not be written
+var ErrSecretStore = errors.New("secret store")
+
+// RegisterResult describes a completed registration
+type RegisterResult struct {
+	UUID       string     `json:"uuid"`
+	Result     string     `json:"result"`
+	SecretPath string     `json:"secretPath"`
+	ExpiresAt  *time.Time `json:"expiresAt"`  // From REGISTER_ACK; null when the router sets none
+	Attempts   int        `json:"attempts"`   // Handshakes sent (0 when skipped)
+	SigningKey bool       `json:"signingKey"` // A MAVLink signing key was provisioned
+}
+
+// RegisterError is a REGISTER_ACK rejection from the router
+type RegisterError struct {
+	Code byte
+}
+
+func (e *RegisterError) Error() string {
+	switch e.Code {
+	case ErrAlreadyRegistered:
+		return "drone is already registered"
+	case ErrInvalidHMAC:
+		return "invalid shared secret (error=0)"
+	case ErrUnknownDroneID:
+		return "unknown drone UUID (error=2)"
+	}
+	return fmt.Sprintf("registration failed (error=%d)", e.Code)
+}
+
+// transient reports whether the same request may succeed when retried
+func (e *RegisterError) transient() bool {
+	return e.Code == ErrRateLimited || e.Code == ErrInternalError
+}
+
+// Register performs the one-time registration process
+// Flow: REGISTER_INIT(UUID) → REGISTER_CHALLENGE → REGISTER_RESPONSE(HMAC-Shared) → REGISTER_ACK(Secret+Session)
+//
+// Registration is idempotent: if a secret is already stored for the UUID nothing is
+// sent unless force is set. Transient failures are retried with backoff. Once a
+// REGISTER_RESPONSE has been sent the router may have stored a secret we never
+// received, so later attempts ask the router to replace it instead of failing
+// with "already registered".
+//
+// force (--re-register) replaces the secret on the router. The stored secret is
+// kept until the new one has been received and written, so a failed
+// re-registration leaves the drone with its previous credentials.
+func (c *Client) Register(force bool) (*RegisterResult, error) {
+	if c.tlsConfig != nil {
+		return nil, fmt.Errorf("registration is not used in mtls mode - identity comes from the client certificate")
+	}
+	if c.sharedSecret == "" {
+		return nil, fmt.Errorf("shared secret is required for registration")
+	}
+
+	res := &RegisterResult{UUID: c.droneUUID, Result: RegisterNew}
+	res.SecretPath, _ = SecretPath(c.droneUUID)
+	if existing, err := LoadSecret(c.droneUUID); err == nil {
+		if !force {
+			log.Printf("[REGISTER] Secret for %s is already stored - skipping registration (use --re-register to replace it)", c.droneUUID)
+			c.mu.Lock()
+			c.secret = existing
+			c.mu.Unlock()
+			res.Result = RegisterSkipped
+			return res, nil
+		}
+		res.Result = RegisterReplaced
+		log.Printf("[REGISTER] Re-registering %s - the current secret stays in use until a new one is saved", c.droneUUID)
+	}
+
+	log.Printf("[REGISTER] Starting registration for drone UUID=%s...", c.droneUUID)
+
+	delay := registerBaseDelay
+	responseSent := false
+	for attempt := 1; ; attempt++ {
+		res.Attempts = attempt
+		replace := force || responseSent
+		ack, sent, err := c.registerOnce(replace)
+		responseSent = responseSent || sent
+		if err == nil {
+			if ack.ExpiresAt > 0 {
+				expires := time.Unix(int64(ack.ExpiresAt), 0)
+				res.ExpiresAt = &expires
+			}
+			if err := c.storeRegisteredSecret(ack.SecretKey); err != nil {
+				return nil, err
+			}
+			res.SigningKey = c.installRegisteredSigningKey(ack.SigningKey)
+			return res, nil
+		}
+
+		var regErr *RegisterError
+		if errors.As(err, &regErr) {
+			if regErr.Code == ErrAlreadyRegistered {
+				if replace {
+					return nil, fmt.Errorf("router refused to replace the secret of %s: %w", c.droneUUID, err)
+				}
+				return nil, fmt.Errorf("router reports %s is already registered but no secret is stored locally - run with --re-register to replace it: %w", c.droneUUID, err)
+			}
+			if !regErr.transient() {
+				return nil, err
+			}
+		}
+		if attempt >= registerAttempts {
+			return nil, fmt.Errorf("registration failed after %d attempts: %w", attempt, err)
+		}
+
+		log.Printf("[REGISTER] ⚠️ Attempt %d/%d failed: %v - retrying in %v", attempt, registerAttempts, err, delay)
+		select {
+		case <-c.clock.After(delay):
+		case <-c.stopCh:
+			return nil, fmt.Errorf("registration cancelled: %w", err)
+		}
+		delay *= 2
+		if delay > registerMaxDelay {
+			delay = registerMaxDelay
+		}
+	}
+}
+
+// registerOnce runs a single registration handshake. responseSent reports whether
+// REGISTER_RESPONSE reached the wire, after which the router may have committed
+// a secret even if the ACK is lost.
+func (c *Client) registerOnce(replace bool) (ack *RegisterAck, responseSent bool, err error) {
+	log.Printf("[REGISTER] Connecting to %s:%d...", c.host, c.port)
+
+	conn, err := c.dialAuth()
+	if err != nil {
+		return nil, false, fmt.Errorf("connection failed: %w", err)
+	}
+	// Registration uses its own connection - the session is obtained via AUTH (Start())
+	defer conn.Close()
+
+	// Enable TCP keepalive to prevent disconnects
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		tcpConn.SetKeepAlive(true)
+		tcpConn.SetKeepAlivePeriod(30 * time.Second)
+	}
+
+	// Step 1: Send REGISTER_INIT
+	init := &RegisterInit{
+		DroneUUID: c.droneUUID,
+		Replace:   replace,
+	}
+	// A router that rejects the request outright answers REGISTER_INIT with an ACK
+	reader := c.readerFor(conn)
+	reply := reader.expect(MsgRegisterChallenge, MsgRegisterAck)
+	if _, err := conn.Write(SerializeRegisterInit(init)); err != nil {
+		reply.cancel()
+		return nil, false, fmt.Errorf("failed to send REGISTER_INIT: %w", err)
+	}
+	if replace {
+		log.Printf("[REGISTER] ✓ Sent REGISTER_INIT (replace existing secret)")
+	} else {
+		log.Printf("[REGISTER] ✓ Sent REGISTER_INIT")
+	}
+
+	// Step 2: Receive REGISTER_CHALLENGE
+	data, err := reply.wait(10 * time.Second)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to receive REGISTER_CHALLENGE: %w", err)
+	}
+	if data[0] == MsgRegisterAck {
+		ack, err := ParseRegisterAck(data)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to parse REGISTER_ACK: %w", err)
+		}
+		if ack.Result != ResultSuccess {
+			return nil, false, &RegisterError{Code: ack.ErrorCode}
+		}
+		return nil, false, fmt.Errorf("unexpected REGISTER_ACK before challenge")
+	}
+
+	challenge, err := ParseRegisterChallenge(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse REGISTER_CHALLENGE: %w", err)
+	}
+	log.Printf("[REGISTER] ✓ Received challenge")
+
+	// Step 3: Compute HMAC with SHARED SECRET
+	timestamp := uint64(c.clock.Now().Unix())
+	hmacSig := ComputeHMAC(c.sharedSecret, c.droneUUID, challenge.Nonce, timestamp)
+
+	// Step 4: Send REGISTER_RESPONSE
+	resp := &RegisterResponse{
+		DroneUUID: c.droneUUID,
+		HMAC:      hmacSig,
+		Timestamp: timestamp,
+	}
+	reply = reader.expect(MsgRegisterAck)
+	if _, err := conn.Write(SerializeRegisterResponse(resp)); err != nil {
+		reply.cancel()
+		// A partial write may still have reached the router
+		return nil, true, fmt.Errorf("failed to send REGISTER_RESPONSE: %w", err)
+	}
+	log.Printf("[REGISTER] ✓ Sent REGISTER_RESPONSE")
+
+	// Step 5: Receive REGISTER_ACK with SECRET (no session)
+	data, err = reply.wait(10 * time.Second)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to receive REGISTER_ACK: %w", err)
+	}
+
+	ack, err = ParseRegisterAck(data)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to parse REGISTER_ACK: %w", err)
+	}
+	if ack.Result != ResultSuccess {
+		return nil, true, &RegisterError{Code: ack.ErrorCode}
+	}
+	if ack.SecretKey == "" {
+		return nil, true, fmt.Errorf("REGISTER_ACK carried no secret key")
+	}
+
+	log.Printf("[REGISTER] ✅ Registration successful!")
+	log.Printf("[REGISTER] 🔑 Received SECRET KEY (len=%d)", len(ack.SecretKey))
+	return ack, true, nil
+}
+
+// storeRegisteredSecret saves a newly issued secret. The write is atomic, so the
+// previous secret (if any) is only replaced once the new one is on disk.
+func (c *Client) storeRegisteredSecret(secretKey string) error {
+	if err := SaveSecret(c.droneUUID, secretKey); err != nil {
+		return fmt.Errorf("%w: failed to save secret key (the router has issued a new one - run --re-register once storage is fixed): %w", ErrSecretStore, err)
+	}
+	if path, err := SecretPath(c.droneUUID); err == nil {
+		log.Printf("[REGISTER] 💾 Secret key saved to '%s'", path)
+	}
+
+	c.mu.Lock()
+	c.secret = secretKey
+	c.conn = nil        // Registration connection is closed, will reconnect during AUTH
+	c.sessionToken = "" // No session from REGISTER (will get from AUTH)
+	c.expiresAt = time.Time{}
+	c.mu.Unlock()
+
+	log.Printf("[REGISTER] ✅ Registration complete. Session will be obtained during AUTH flow (Start())")
+	return nil
+}
+
+// installRegisteredSigningKey hands the signing key from REGISTER_ACK to
+// OnSigningKey. A failure does not fail the registration: the router can push
+// the key again later.
+func (c *Client) installRegisteredSigningKey(key []byte) bool {
+	if len(key) == 0 {
+		return false
+	}
+	c.mu.RLock()
+	callback := c.OnSigningKey
+	c.mu.RUnlock()
+	if callback == nil {
+		log.Printf("[REGISTER] Router provisioned a MAVLink signing key, but signing is not enabled - ignoring it")
+		return false
+	}
+	if err := callback(key); err != nil {
+		log.Printf("[REGISTER] ⚠️ Failed to install the MAVLink signing key: %v", err)
+		return false
+	}
+	log.Printf("[REGISTER] 🔑 MAVLink signing key provisioned")
+	return true
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"log"
+	"time"
+)
+
+// SetStatus changes the status flags reported to the router (StatusMaintenance)
+// and when they end on their own (zero = no limit). They are sent right away
+// when the connection is free, otherwise on the next keepalive beat, and again
+// after every new session while any flag is set.
+func (c *Client) SetStatus(flags byte, until time.Time) {
+	c.mu.Lock()
+	c.statusFlags = flags
+	c.statusUntil = until
+	c.statusPending = true
+	c.mu.Unlock()
+
+	c.reportStatus()
+}
+
+// reportStatus sends a pending DRONE_STATUS
+func (c *Client) reportStatus() {
+	c.mu.RLock()
+	pending := c.statusPending
+	token := c.sessionToken
+	c.mu.RUnlock()
+	if !pending || token == "" {
+		return
+	}
+
+	if !c.tcpMu.TryLock() {
+		return
+	}
+	defer c.tcpMu.Unlock()
+	c.mu.Lock()
+	conn := c.conn
+	flags, untilAt := c.statusFlags, c.statusUntil
+	var until uint64
+	if !untilAt.IsZero() {
+		until = uint64(untilAt.Unix())
+	}
+	c.mu.Unlock()
+	if conn == nil {
+		return
+	}
+	if _, err := conn.Write(SerializeDroneStatus(c.droneUUID, token, flags, until)); err != nil {
+		log.Printf("[STATUS] Failed to send DRONE_STATUS: %v", err)
+		return
+	}
+
+	c.mu.Lock()
+	// A change while sending stays pending for the next beat
+	if c.statusFlags == flags && c.statusUntil.Equal(untilAt) {
+		c.statusPending = false
+	}
+	c.mu.Unlock()
+	log.Printf("[STATUS] Reported flags 0x%02x to the router", flags)
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// uuidFileName caches the provisioned UUID in the secret store directory
+const uuidFileName = ".drone_uuid"
+
+// uuidNamespace is the UUIDv5 namespace of DroneBridge hardware identities.
+// Changing it changes every auto-provisioned UUID.
+var uuidNamespace = [16]byte{0x6f, 0x0b, 0x3e, 0x52, 0x9d, 0x41, 0x4c, 0x7a, 0xb2, 0x0e, 0x5d, 0x83, 0x17, 0xc9, 0xa4, 0x60}
+
+var uuidPattern = regexp.MustCompile("^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}$")
+
+// ValidUUID reports whether s is an 8-4-4-4-12 hex UUID
+func ValidUUID(s string) bool {
+	return uuidPattern.MatchString(s)
+}
+
+// uuidV5 returns the RFC 4122 name-based (SHA-1) UUID of name in namespace
+func uuidV5(namespace [16]byte, name string) string {
+	h := sha1.New()
+	h.Write(namespace[:])
+	h.Write([]byte(name))
+	b := h.Sum(nil)[:16]
+	b[6] = b[6]&0x0f | 0x50 // Version 5
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// hardwareID returns a stable identifier of this machine, prefixed with its
+// source so IDs from different sources never map to the same UUID. It prefers
+// the CPU serial (survives reflashing), then the machine-id, then a MAC address.
+func hardwareID() (string, error) {
+	if serial := cpuSerial(); serial != "" {
+		return "cpu-serial:" + serial, nil
+	}
+	for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(path); err == nil {
+			if id := strings.TrimSpace(string(data)); id != "" {
+				return "machine-id:" + id, nil
+			}
+		}
+	}
+	if mac := primaryMAC(); mac != "" {
+		return "mac:" + mac, nil
+	}
+	return "", fmt.Errorf("no CPU serial, machine-id or MAC address found")
+}
+
+// cpuSerial returns the SoC serial number (Raspberry Pi, Jetson and other ARM boards)
+func cpuSerial() string {
+	if data, err := os.ReadFile("/sys/firmware/devicetree/base/serial-number"); err == nil {
+		if serial := strings.TrimSpace(string(bytes.TrimRight(data, "\x00"))); serial != "" {
+			return strings.ToLower(serial)
+		}
+	}
+	f, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(key) != "Serial" {
+			continue
+		}
+		// Boards without a serial report all zeros
+		if serial := strings.TrimSpace(value); strings.Trim(serial, "0") != "" {
+			return strings.ToLower(serial)
+		}
+	}
+	return ""
+}
+
+// primaryMAC returns the MAC of the first non-loopback interface by name, so the
+// choice does not depend on the order the kernel lists interfaces in
+func primaryMAC() string {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+	sort.Slice(ifaces, func(i, j int) bool { return ifaces[i].Name < ifaces[j].Name })
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 && len(iface.HardwareAddr) >= 6 {
+			return iface.HardwareAddr.String()
+		}
+	}
+	return ""
+}
+
+// HardwareUUID derives this drone's UUID from its hardware identity. The same
+// board always yields the same UUID, so it is stable across reflashes.
+func HardwareUUID() (string, error) {
+	id, err := hardwareID()
+	if err != nil {
+		return "", err
+	}
+	return uuidV5(uuidNamespace, id), nil
+}
+
+// ProvisionUUID returns the UUID to use when none is configured: the one cached
+// in the secret store (or, from older versions, in the working directory), else
+// one derived from the hardware (a random UUIDv4 when the hardware has no
+// identity). A cached ID is kept as long as it is well-formed - including the
+// MAC-padded IDs of older versions, which the router knows the drone by.
+func ProvisionUUID() string {
+	path := uuidFileName
+	if dir, err := secretStoreDir(); err == nil {
+		path = filepath.Join(dir, uuidFileName)
+	}
+
+	for _, cached := range []string{path, uuidFileName} {
+		data, err := os.ReadFile(cached)
+		if err != nil {
+			continue
+		}
+		id := strings.TrimSpace(string(data))
+		if !ValidUUID(id) {
+			if id != "" {
+				log.Printf("[AUTH] Cached drone UUID %q is not a valid UUID, ignoring it", id)
+			}
+			continue
+		}
+		if strings.HasSuffix(id, "-5555-8888-999999999999") {
+			log.Printf("[AUTH] Keeping legacy MAC-based drone UUID %s", id)
+		}
+		if cached != path {
+			if err := os.WriteFile(path, []byte(id+"\n"), 0644); err != nil {
+				log.Printf("[AUTH] Warn: failed to cache drone UUID: %v", err)
+			}
+		}
+		return id
+	}
+
+	id, err := HardwareUUID()
+	if err != nil {
+		log.Printf("[AUTH] Warn: %v - using a random drone UUID", err)
+		id = randomUUID()
+	}
+	if err := os.WriteFile(path, []byte(id+"\n"), 0644); err != nil {
+		log.Printf("[AUTH] Warn: failed to cache drone UUID: %v", err)
+	}
+	return id
+}
+
+// randomUUID returns an RFC 4122 version 4 UUID
+func randomUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// Still unique per boot and well-formed
+		return uuidV5(uuidNamespace, fmt.Sprintf("random:%d:%d", time.Now().UnixNano(), os.Getpid()))
+	}
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,84 @@
+package auth
+
+import "time"
+
+// expiryWarning is how long before the session expires a warning event is
+// raised when no refresh has extended it yet
+const expiryWarning = 2 * time.Minute
+
+// Session and API key event kinds passed to the event sink
+const (
+	EventSessionStarted   = "session_started"
+	EventSessionRefreshed = "session_refreshed"
+	EventRefreshFailed    = "session_refresh_failed"
+	EventSessionExpiring  = "session_expiring"
+	EventReauth           = "reauth"
+	EventReauthFailed     = "reauth_failed"
+	EventAPIKeyCreated    = "apikey_created"
+	EventAPIKeyRevoked    = "apikey_revoked"
+	EventAPIKeyDeleted    = "apikey_deleted"
+	EventAPIKeyStatus     = "apikey_status"
+	EventAPIKeyUser       = "apikey_user"
+)
+
+// SetEventSink sets the function receiving session and API key lifecycle
+// events (e.g. to push them to the dashboard). Severity is INFO, WARN or ERROR.
+func (c *Client) SetEventSink(sink func(kind, severity, message string, fields map[string]interface{})) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.eventSink = sink
+}
+
+// emit passes an event to the sink, if any
+func (c *Client) emit(kind, severity, message string, fields map[string]interface{}) {
+	c.mu.RLock()
+	sink := c.eventSink
+	c.mu.RUnlock()
+	if sink != nil {
+		sink(kind, severity, message, fields)
+	}
+}
+
+// checkExpiry raises one warning per session expiry time once it is closer
+// than expiryWarning, so a failing refresh shows up before the session is gone
+func (c *Client) checkExpiry() {
+	c.mu.Lock()
+	expiresAt := c.expiresAt
+	if c.sessionToken == "" || expiresAt.IsZero() || expiresAt.Equal(c.expiryWarned) {
+		c.mu.Unlock()
+		return
+	}
+	remaining := expiresAt.Sub(c.clock.Now())
+	if remaining > expiryWarning {
+		c.mu.Unlock()
+		return
+	}
+	c.expiryWarned = expiresAt
+	c.mu.Unlock()
+
+	severity, message := "WARN", "Session expires in "+remaining.Round(time.Second).String()
+	if remaining <= 0 {
+		severity, message = "ERROR", "Session expired"
+	}
+	c.emit(EventSessionExpiring, severity, message, map[string]interface{}{
+		"expiresAt":    expiresAt,
+		"remainingSec": int64(remaining.Seconds()),
+	})
+}
+
+// noteAPIKeyStatus raises an event when the router reports a different API key
+// status than last time (e.g. pending -> connected, connected -> expired)
+func (c *Client) noteAPIKeyStatus(status string) {
+	c.mu.Lock()
+	prev := c.apiKeyLastSeen
+	c.apiKeyLastSeen = status
+	c.mu.Unlock()
+	if prev == "" || prev == status {
+		return
+	}
+	severity := "INFO"
+	if status == "expired" {
+		severity = "WARN"
+	}
+	c.emit(EventAPIKeyStatus, severity, "API key status: "+prev+" -> "+status, map[string]interface{}{"from": prev, "to": status})
+}
@@ -23,3 +23,43 @@ func VerifyHMAC(secret string, droneUUID string, nonce []byte, timestamp uint64,
 	expected := ComputeHMAC(secret, droneUUID, nonce, timestamp)
 	return hmac.Equal(expected, signature)
 }
+
+// ComputeConfigPushHMAC signs a CONFIG_PUSH with the drone's combined key
+// Message format: "DroneUUID:PushID:Timestamp:" followed by the raw overlay
+func ComputeConfigPushHMAC(secret string, droneUUID string, pushID, timestamp uint64, overlay []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(h, "%s:%d:%d:", droneUUID, pushID, timestamp)
+	h.Write(overlay)
+	return h.Sum(nil)
+}
+
+// ComputeSigningKeyHMAC signs a SIGNING_KEY push with the drone's combined key
+// Message format: "DroneUUID:KeyID:Timestamp:" followed by the raw key
+func ComputeSigningKeyHMAC(secret string, droneUUID string, keyID, timestamp uint64, key []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(h, "%s:%d:%d:", droneUUID, keyID, timestamp)
+	h.Write(key)
+	return h.Sum(nil)
+}
+
+// hmacKnownAnswer is HMAC-SHA256 over "00000000-0000-4000-8000-000000000000:
+// 000102...0f:1700000000" with the combined key of "shared-key" and "secret-key"
+const hmacKnownAnswer = "13adee084302b73cc5498055b1ad4347a077b6566c13b31c4e034f0924998f6b"
+
+// SelfTestHMAC checks key derivation and signing against a known answer, so a
+// broken build or crypto library shows up before the router rejects the drone
+func SelfTestHMAC() error {
+	nonce := make([]byte, 16)
+	for i := range nonce {
+		nonce[i] = byte(i)
+	}
+	key := computeCombinedKey("shared-key", "secret-key")
+	sig := ComputeHMAC(key, "00000000-0000-4000-8000-000000000000", nonce, 1700000000)
+	if got := hex.EncodeToString(sig); got != hmacKnownAnswer {
+		return fmt.Errorf("HMAC self-test mismatch: got %s", got)
+	}
+	if !VerifyHMAC(key, "00000000-0000-4000-8000-000000000000", nonce, 1700000000, sig) {
+		return fmt.Errorf("HMAC self-test: signature does not verify")
+	}
+	return nil
+}
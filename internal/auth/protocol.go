@@ -3,6 +3,7 @@ package auth
 import (
 	"encoding/binary"
 	"fmt"
+	"strings"
 )
 
 // Message Types
@@ -35,6 +36,35 @@ const (
 	MsgUserConnected    = 0x30 // Router → Drone: user connected
 	MsgUserDisconnected = 0x31 // Router → Drone: user disconnected
 
+	// Rate negotiation
+	MsgRateNegotiate = 0x40 // Drone → Router: request telemetry rate policy
+	MsgRatePolicy    = 0x41 // Router → Drone: desired max rates / bandwidth
+
+	// Video control
+	MsgVideoControl    = 0x50 // Router → Drone: start/stop/reconfigure video
+	MsgVideoControlAck = 0x51 // Drone → Router: video control result
+
+	// Remote configuration
+	MsgConfigPush    = 0x60 // Router → Drone: signed config overlay
+	MsgConfigPushAck = 0x61 // Drone → Router: config push result
+
+	// MAVLink signing key rotation
+	MsgSigningKey    = 0x70 // Router → Drone: new MAVLink 2 signing key
+	MsgSigningKeyAck = 0x71 // Drone → Router: signing key result
+
+	// Feature entitlements
+	MsgEntitlementRequest = 0x80 // Drone → Router: request the signed feature list
+	MsgEntitlements       = 0x81 // Router → Drone: signed feature list (answer or push)
+
+	// Operating status
+	MsgDroneStatus = 0x90 // Drone → Router: status flags (on change and after every new session)
+
+	// Forwarding configuration audit
+	MsgPolicyReport = 0xB0 // Drone → Router: active filter/shaper/policy configuration (periodic and on change)
+
+	// Framing
+	MsgFrame = 0xF0 // Router → Drone: [TYPE:1][LEN:2][MESSAGE:LEN] around any message (drones sending AuthInitFlagFramed)
+
 	// Registration messages
 	MsgRegisterInit      uint8 = 0xA0 // 160
 	MsgRegisterChallenge uint8 = 0xA1 // 161
@@ -42,6 +72,11 @@ const (
 	MsgRegisterAck       uint8 = 0xA3 // 163
 )
 
+// DRONE_STATUS flags
+const (
+	StatusMaintenance = 0x01 // Bench work: telemetry paused on purpose, don't alert on the silence
+)
+
 // Result Codes
 const (
 	ResultSuccess = 0x00
@@ -54,6 +89,7 @@ const (
 	ErrTimestampOutOfRange = 0x01
 	ErrUnknownDroneID      = 0x02
 	ErrRateLimited         = 0x03
+	ErrAlreadyRegistered   = 0x04 // REGISTER for a UUID that already has a secret (without the replace flag)
 	ErrSessionExpired      = 0x06
 	ErrInvalidToken        = 0x07 // Session not found or invalid token
 	ErrInternalError       = 0x05
@@ -166,13 +202,141 @@ type APIKeyDeleteAck struct {
 	ErrorCode byte // Error code if failed
 }
 
+// ============================================================================
+// RATE NEGOTIATION STRUCTURES
+// ============================================================================
+
+// RateNegotiateRequest represents RATE_NEGOTIATE message to router
+type RateNegotiateRequest struct {
+	DroneUUID       string // Drone UUID
+	SessionToken    string // Current session token for verification
+	CompressionCaps byte   // Bitmask of supported batch compression algorithms (0 = none)
+
+	// SESSION_HEARTBEAT identity the drone is configured with; the router may assign another
+	HeartbeatID      uint32 // SESSION_HEARTBEAT message ID
+	HeartbeatAckID   uint32 // SESSION_HEARTBEAT_ACK message ID
+	HeartbeatLayouts byte   // Bitmask of supported SESSION_HEARTBEAT field layouts (bit N = layout N)
+}
+
+// MessageRate is a per-message rate cap advertised by the router
+type MessageRate struct {
+	MsgID uint32  // MAVLink message ID
+	MaxHz float32 // Maximum forwarding rate (0 = unlimited)
+}
+
+// RatePolicy represents RATE_POLICY from router
+type RatePolicy struct {
+	MaxBytesPerSec uint32        // Uplink bandwidth budget (0 = unlimited)
+	Rates          []MessageRate // Per-message rate caps
+	Compression    byte          // Batch compression algorithm chosen by the router (0 = no batching)
+
+	// SESSION_HEARTBEAT identity assigned by the router (HeartbeatID 0 = keep the configured one)
+	HeartbeatID     uint32
+	HeartbeatAckID  uint32
+	HeartbeatLayout byte
+}
+
+// ============================================================================
+// USER NOTIFICATION STRUCTURES
+// ============================================================================
+
+// UserNotification represents USER_CONNECTED / USER_DISCONNECTED from router
+type UserNotification struct {
+	Connected bool   // true for USER_CONNECTED
+	UserUUID  string // Viewer's user UUID
+}
+
+// ============================================================================
+// VIDEO CONTROL STRUCTURES
+// ============================================================================
+
+// Video control actions
+const (
+	VideoStart       = 0x01
+	VideoStop        = 0x02
+	VideoReconfigure = 0x03
+)
+
+// VideoAllCameras addresses every camera in VIDEO_CONTROL
+const VideoAllCameras = 0xFF
+
+// VideoControl represents VIDEO_CONTROL from router. Zero stream settings keep the current value.
+type VideoControl struct {
+	Action      byte   // VideoStart, VideoStop or VideoReconfigure
+	CameraID    byte   // Camera ID or VideoAllCameras
+	Width       uint16 // Pixels
+	Height      uint16 // Pixels
+	Framerate   byte   // Frames per second
+	BitrateKbps uint32 // Encoder bitrate
+}
+
+// VideoControlAck represents VIDEO_CONTROL_ACK to router
+type VideoControlAck struct {
+	Action   byte
+	CameraID byte
+	Result   byte   // 0x00 = success, 0x01 = failure
+	Message  string // Failure reason
+}
+
+// ============================================================================
+// CONFIG PUSH STRUCTURES
+// ============================================================================
+
+// ConfigPush represents CONFIG_PUSH from router: a YAML overlay merged into the config file
+type ConfigPush struct {
+	PushID    uint64 // Increases with every push; older IDs are rejected as replays
+	Timestamp uint64 // Unix time the push was signed
+	Overlay   []byte // YAML mapping, e.g. "network:\n  target_host: 10.0.0.2\n"
+	HMAC      []byte // ComputeConfigPushHMAC with the drone's combined key
+}
+
+// ConfigPushAck represents CONFIG_PUSH_ACK to router
+type ConfigPushAck struct {
+	PushID  uint64
+	Result  byte   // 0x00 = applied, 0x01 = rejected
+	Message string // Rejection reason, or settings that take effect after a restart
+}
+
+// SigningKeyPush represents SIGNING_KEY from router: the MAVLink 2 signing key to use from now on
+type SigningKeyPush struct {
+	KeyID     uint64 // Increases with every rotation; older IDs are rejected as replays
+	Timestamp uint64 // Unix time the push was signed
+	Key       []byte // 32-byte MAVLink 2 secret key
+	HMAC      []byte // ComputeSigningKeyHMAC with the drone's combined key
+}
+
+// SigningKeyAck represents SIGNING_KEY_ACK to router
+type SigningKeyAck struct {
+	KeyID   uint64
+	Result  byte   // 0x00 = installed, 0x01 = rejected
+	Message string // Rejection reason
+}
+
+// ============================================================================
+// ENTITLEMENT STRUCTURES
+// ============================================================================
+
+// Entitlements represents ENTITLEMENTS from router: the premium features this drone may use
+type Entitlements struct {
+	Serial    uint64   // Increases with every issued list; older serials are rejected as replays
+	IssuedAt  uint64   // Unix time the list was signed
+	ExpiresAt uint64   // Unix time after which the features are locked again
+	Features  []string // e.g. "video", "precision_landing", "relay"
+	List      []byte   // Features as signed (comma-separated)
+	Signature []byte   // Ed25519 over EntitlementsMessage by the fleet server (EntitlementsPublicKey)
+}
+
 // ============================================================================
 // REGISTRATION PROTOCOL STRUCTURES (NEW)
 // ============================================================================
 
-// RegisterInit represents REGISTER_INIT packet (UUID only)
+// RegisterInitFlagReplace asks the router to replace an existing secret (--re-register)
+const RegisterInitFlagReplace byte = 0x01
+
+// RegisterInit represents REGISTER_INIT packet (UUID and optional flags)
 type RegisterInit struct {
 	DroneUUID string
+	Replace   bool // Replace a secret the router already holds for this UUID
 }
 
 // RegisterChallenge represents REGISTER_CHALLENGE packet (from server)
@@ -196,15 +360,21 @@ type RegisterAck struct {
 	SessionToken string
 	ExpiresAt    uint64
 	Interval     uint16
+	SigningKey   []byte // Optional MAVLink 2 signing key (routers with signing enabled)
 }
 
 // ============================================================================
 // UUID-BASED PROTOCOL STRUCTURES (PRIMARY)
 // ============================================================================
 
+// AuthInitFlagFramed tells the router this drone reads MsgFrame envelopes, so
+// trailing optional fields no longer have to be told apart from the next message
+const AuthInitFlagFramed byte = 0x01
+
 // AuthInit represents AUTH_INIT message - just UUID, no HMAC
 type AuthInit struct {
 	DroneUUID string // UUID string (e.g., "970cbc93-d7df-49dc-8ee0-91c138e7ec98")
+	Flags     byte   // AuthInitFlag* capabilities
 }
 
 // AuthResponse represents AUTH_RESPONSE message - UUID + HMAC after challenge
@@ -213,6 +383,7 @@ type AuthResponse struct {
 	HMAC      []byte // HMAC-SHA256 signature
 	Timestamp uint64 // Unix timestamp
 	IP        string // Optional current IP
+	Counter   uint64 // Monotonic counter (persists across restarts) to complement the timestamp window
 }
 
 // ============================================================================
@@ -220,10 +391,11 @@ type AuthResponse struct {
 // ============================================================================
 
 // SerializeAuthInit creates AUTH_INIT packet (UUID only, no HMAC)
-// Format: [TYPE:1][UUID_LEN:2][UUID:var]
+// Format: [TYPE:1][UUID_LEN:2][UUID:var][FLAGS:1 optional]
+// FLAGS is a trailing field (only sent when non-zero) that older routers ignore
 func SerializeAuthInit(init *AuthInit) []byte {
 	uuidBytes := []byte(init.DroneUUID)
-	packet := make([]byte, 0, 1+2+len(uuidBytes))
+	packet := make([]byte, 0, 1+2+len(uuidBytes)+1)
 
 	// Message type
 	packet = append(packet, MsgAuthInit)
@@ -236,15 +408,21 @@ func SerializeAuthInit(init *AuthInit) []byte {
 	// UUID
 	packet = append(packet, uuidBytes...)
 
+	// Flags
+	if init.Flags != 0 {
+		packet = append(packet, init.Flags)
+	}
+
 	return packet
 }
 
 // SerializeAuthResponse creates AUTH_RESPONSE packet (after challenge)
-// Format: [TYPE:1][UUID_LEN:2][UUID:var][HMAC_LEN:2][HMAC:32][TIMESTAMP:8][IP_LEN:2][IP:var]
+// Format: [TYPE:1][UUID_LEN:2][UUID:var][HMAC_LEN:2][HMAC:32][TIMESTAMP:8][IP_LEN:2][IP:var][COUNTER:8]
+// COUNTER is a trailing field so routers that don't check it can ignore it
 func SerializeAuthResponse(resp *AuthResponse) []byte {
 	uuidBytes := []byte(resp.DroneUUID)
 	ipBytes := []byte(resp.IP)
-	packet := make([]byte, 0, 1+2+len(uuidBytes)+2+len(resp.HMAC)+8+2+len(ipBytes))
+	packet := make([]byte, 0, 1+2+len(uuidBytes)+2+len(resp.HMAC)+8+2+len(ipBytes)+8)
 
 	// Message type
 	packet = append(packet, MsgAuthResponse)
@@ -278,6 +456,11 @@ func SerializeAuthResponse(resp *AuthResponse) []byte {
 	// IP
 	packet = append(packet, ipBytes...)
 
+	// Counter (8 bytes)
+	buf = make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, resp.Counter)
+	packet = append(packet, buf...)
+
 	return packet
 }
 
@@ -554,10 +737,11 @@ func ParseSessionRefreshAck(data []byte) (*SessionRefreshAck, error) {
 // ============================================================================
 
 // SerializeRegisterInit creates REGISTER_INIT packet
-// Format: [TYPE:1][UUID_LEN:2][UUID:var]
+// Format: [TYPE:1][UUID_LEN:2][UUID:var][FLAGS:1 optional]
+// FLAGS is only sent when non-zero so plain registrations stay byte-identical for older routers.
 func SerializeRegisterInit(init *RegisterInit) []byte {
 	uuidBytes := []byte(init.DroneUUID)
-	packet := make([]byte, 0, 1+2+len(uuidBytes))
+	packet := make([]byte, 0, 1+2+len(uuidBytes)+1)
 
 	// Message type
 	packet = append(packet, MsgRegisterInit)
@@ -570,6 +754,11 @@ func SerializeRegisterInit(init *RegisterInit) []byte {
 	// UUID
 	packet = append(packet, uuidBytes...)
 
+	// Flags
+	if init.Replace {
+		packet = append(packet, RegisterInitFlagReplace)
+	}
+
 	return packet
 }
 
@@ -646,7 +835,7 @@ func SerializeRegisterResponse(resp *RegisterResponse) []byte {
 }
 
 // ParseRegisterAck parses REGISTER_ACK packet
-// Format: [TYPE:1][RESULT:1][SECRET_KEY_LEN:2][SECRET_KEY:var][SESSION_TOKEN_LEN:2][SESSION_TOKEN:var][EXPIRES_AT:8][INTERVAL:2]
+// Format: [TYPE:1][RESULT:1][SECRET_KEY_LEN:2][SECRET_KEY:var][SESSION_TOKEN_LEN:2][SESSION_TOKEN:var][EXPIRES_AT:8][INTERVAL:2][SIGNING_KEY:32, optional]
 func ParseRegisterAck(data []byte) (*RegisterAck, error) {
 	if len(data) < 1 {
 		return nil, fmt.Errorf("packet too short")
@@ -717,6 +906,11 @@ func ParseRegisterAck(data []byte) (*RegisterAck, error) {
 	ack.Interval = binary.LittleEndian.Uint16(data[offset : offset+2])
 	offset += 2
 
+	// SIGNING_KEY (32 bytes, optional - older routers end the packet here)
+	if len(data) >= offset+32 {
+		ack.SigningKey = data[offset : offset+32]
+	}
+
 	return ack, nil
 }
 
@@ -1060,3 +1254,367 @@ func ParseAPIKeyDeleteAck(data []byte) (*APIKeyDeleteAck, error) {
 
 	return ack, nil
 }
+
+// ============================================================================
+// RATE NEGOTIATION SERIALIZATION/PARSING
+// ============================================================================
+
+// SerializeRateNegotiate creates RATE_NEGOTIATE packet
+// Format: [TYPE:1][UUID_LEN:2][UUID:var][TOKEN_LEN:2][TOKEN:var][COMPRESSION_CAPS:1][HB_ID:4][HB_ACK_ID:4][HB_LAYOUTS:1]
+func SerializeRateNegotiate(req *RateNegotiateRequest) []byte {
+	uuidBytes := []byte(req.DroneUUID)
+	tokenBytes := []byte(req.SessionToken)
+	packet := make([]byte, 0, 1+2+len(uuidBytes)+2+len(tokenBytes)+1+9)
+
+	// Message type
+	packet = append(packet, MsgRateNegotiate)
+
+	// UUID length (2 bytes)
+	buf := make([]byte, 2)
+	binary.LittleEndian.PutUint16(buf, uint16(len(uuidBytes)))
+	packet = append(packet, buf...)
+
+	// UUID
+	packet = append(packet, uuidBytes...)
+
+	// Token length (2 bytes)
+	buf = make([]byte, 2)
+	binary.LittleEndian.PutUint16(buf, uint16(len(tokenBytes)))
+	packet = append(packet, buf...)
+
+	// Token
+	packet = append(packet, tokenBytes...)
+
+	// Compression capabilities (1 byte)
+	packet = append(packet, req.CompressionCaps)
+
+	// SESSION_HEARTBEAT identity (4+4 bytes) and supported layouts (1 byte)
+	packet = binary.LittleEndian.AppendUint32(packet, req.HeartbeatID)
+	packet = binary.LittleEndian.AppendUint32(packet, req.HeartbeatAckID)
+	packet = append(packet, req.HeartbeatLayouts)
+
+	return packet
+}
+
+// ParseRatePolicy parses RATE_POLICY from router
+// Format: [TYPE:1][MAX_BPS:4][COUNT:2]{[MSG_ID:4][MAX_HZ_x100:2]}*COUNT[COMPRESSION:1 optional]
+// [HB_ID:4][HB_ACK_ID:4][HB_LAYOUT:1 optional]
+func ParseRatePolicy(data []byte) (*RatePolicy, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("packet too short")
+	}
+
+	if data[0] != MsgRatePolicy {
+		return nil, fmt.Errorf("invalid message type: 0x%02x (expected 0x%02x)", data[0], MsgRatePolicy)
+	}
+
+	offset := 1
+	if len(data) < offset+4+2 {
+		return nil, fmt.Errorf("packet too short for rate policy header")
+	}
+
+	policy := &RatePolicy{}
+
+	// MAX_BPS (4 bytes)
+	policy.MaxBytesPerSec = binary.LittleEndian.Uint32(data[offset : offset+4])
+	offset += 4
+
+	// COUNT (2 bytes)
+	count := int(binary.LittleEndian.Uint16(data[offset : offset+2]))
+	offset += 2
+
+	if len(data) < offset+count*6 {
+		return nil, fmt.Errorf("packet too short for %d rate entries", count)
+	}
+
+	policy.Rates = make([]MessageRate, 0, count)
+	for i := 0; i < count; i++ {
+		msgID := binary.LittleEndian.Uint32(data[offset : offset+4])
+		hz := binary.LittleEndian.Uint16(data[offset+4 : offset+6])
+		offset += 6
+		policy.Rates = append(policy.Rates, MessageRate{MsgID: msgID, MaxHz: float32(hz) / 100})
+	}
+
+	// COMPRESSION (1 byte, optional - older routers omit it)
+	if len(data) >= offset+1 {
+		policy.Compression = data[offset]
+		offset++
+	}
+
+	// SESSION_HEARTBEAT identity (9 bytes, optional - routers that keep the default omit it)
+	if len(data) >= offset+9 {
+		policy.HeartbeatID = binary.LittleEndian.Uint32(data[offset : offset+4])
+		policy.HeartbeatAckID = binary.LittleEndian.Uint32(data[offset+4 : offset+8])
+		policy.HeartbeatLayout = data[offset+8]
+	}
+
+	return policy, nil
+}
+
+// ============================================================================
+// USER NOTIFICATION PARSING
+// ============================================================================
+
+// ParseUserNotification parses USER_CONNECTED / USER_DISCONNECTED from router
+// Format: [TYPE:1][USER_UUID_LEN:2][USER_UUID:var]
+func ParseUserNotification(data []byte) (*UserNotification, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("packet too short")
+	}
+
+	if data[0] != MsgUserConnected && data[0] != MsgUserDisconnected {
+		return nil, fmt.Errorf("invalid message type: 0x%02x (expected 0x%02x or 0x%02x)", data[0], MsgUserConnected, MsgUserDisconnected)
+	}
+
+	offset := 1
+	if len(data) < offset+2 {
+		return nil, fmt.Errorf("packet too short for user UUID length")
+	}
+	uuidLen := int(binary.LittleEndian.Uint16(data[offset : offset+2]))
+	offset += 2
+
+	if uuidLen == 0 || len(data) < offset+uuidLen {
+		return nil, fmt.Errorf("packet too short for user UUID")
+	}
+
+	return &UserNotification{
+		Connected: data[0] == MsgUserConnected,
+		UserUUID:  string(data[offset : offset+uuidLen]),
+	}, nil
+}
+
+// ============================================================================
+// VIDEO CONTROL SERIALIZATION/PARSING
+// ============================================================================
+
+// ParseVideoControl parses VIDEO_CONTROL from router
+// Format: [TYPE:1][ACTION:1][CAMERA_ID:1][WIDTH:2][HEIGHT:2][FPS:1][BITRATE_KBPS:4]
+func ParseVideoControl(data []byte) (*VideoControl, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("packet too short")
+	}
+
+	if data[0] != MsgVideoControl {
+		return nil, fmt.Errorf("invalid message type: 0x%02x (expected 0x%02x)", data[0], MsgVideoControl)
+	}
+
+	if len(data) < 12 {
+		return nil, fmt.Errorf("packet too short for video control")
+	}
+
+	vc := &VideoControl{
+		Action:      data[1],
+		CameraID:    data[2],
+		Width:       binary.LittleEndian.Uint16(data[3:5]),
+		Height:      binary.LittleEndian.Uint16(data[5:7]),
+		Framerate:   data[7],
+		BitrateKbps: binary.LittleEndian.Uint32(data[8:12]),
+	}
+
+	if vc.Action < VideoStart || vc.Action > VideoReconfigure {
+		return nil, fmt.Errorf("unknown video action 0x%02x", vc.Action)
+	}
+
+	return vc, nil
+}
+
+// ============================================================================
+// CONFIG PUSH SERIALIZATION/PARSING
+// ============================================================================
+
+// ParseConfigPush parses CONFIG_PUSH from router
+// Format: [TYPE:1][PUSH_ID:8][TIMESTAMP:8][LEN:2][OVERLAY:var][HMAC:32]
+func ParseConfigPush(data []byte) (*ConfigPush, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("packet too short")
+	}
+
+	if data[0] != MsgConfigPush {
+		return nil, fmt.Errorf("invalid message type: 0x%02x (expected 0x%02x)", data[0], MsgConfigPush)
+	}
+
+	if len(data) < 19 {
+		return nil, fmt.Errorf("packet too short for config push")
+	}
+
+	overlayLen := int(binary.LittleEndian.Uint16(data[17:19]))
+	if len(data) < 19+overlayLen+32 {
+		return nil, fmt.Errorf("packet too short for overlay (%d bytes) and HMAC", overlayLen)
+	}
+
+	return &ConfigPush{
+		PushID:    binary.LittleEndian.Uint64(data[1:9]),
+		Timestamp: binary.LittleEndian.Uint64(data[9:17]),
+		Overlay:   data[19 : 19+overlayLen],
+		HMAC:      data[19+overlayLen : 19+overlayLen+32],
+	}, nil
+}
+
+// SerializeConfigPushAck creates CONFIG_PUSH_ACK packet
+// Format: [TYPE:1][PUSH_ID:8][RESULT:1][MSG_LEN:2][MSG:var]
+func SerializeConfigPushAck(ack *ConfigPushAck) []byte {
+	msgBytes := []byte(ack.Message)
+	packet := make([]byte, 0, 1+8+1+2+len(msgBytes))
+
+	packet = append(packet, MsgConfigPushAck)
+	packet = binary.LittleEndian.AppendUint64(packet, ack.PushID)
+	packet = append(packet, ack.Result)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(msgBytes)))
+	packet = append(packet, msgBytes...)
+
+	return packet
+}
+
+// SerializeVideoControlAck creates VIDEO_CONTROL_ACK packet
+// Format: [TYPE:1][ACTION:1][CAMERA_ID:1][RESULT:1][MSG_LEN:2][MSG:var]
+func SerializeVideoControlAck(ack *VideoControlAck) []byte {
+	msgBytes := []byte(ack.Message)
+	packet := make([]byte, 0, 4+2+len(msgBytes))
+
+	packet = append(packet, MsgVideoControlAck, ack.Action, ack.CameraID, ack.Result)
+
+	// Message length (2 bytes)
+	buf := make([]byte, 2)
+	binary.LittleEndian.PutUint16(buf, uint16(len(msgBytes)))
+	packet = append(packet, buf...)
+
+	// Message
+	packet = append(packet, msgBytes...)
+
+	return packet
+}
+
+// ParseSigningKey parses SIGNING_KEY from router
+// Format: [TYPE:1][KEY_ID:8][TIMESTAMP:8][KEY:32][HMAC:32]
+func ParseSigningKey(data []byte) (*SigningKeyPush, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("packet too short")
+	}
+
+	if data[0] != MsgSigningKey {
+		return nil, fmt.Errorf("invalid message type: 0x%02x (expected 0x%02x)", data[0], MsgSigningKey)
+	}
+
+	if len(data) < 1+8+8+32+32 {
+		return nil, fmt.Errorf("packet too short for signing key")
+	}
+
+	return &SigningKeyPush{
+		KeyID:     binary.LittleEndian.Uint64(data[1:9]),
+		Timestamp: binary.LittleEndian.Uint64(data[9:17]),
+		Key:       data[17:49],
+		HMAC:      data[49:81],
+	}, nil
+}
+
+// SerializeSigningKeyAck creates SIGNING_KEY_ACK packet
+// Format: [TYPE:1][KEY_ID:8][RESULT:1][MSG_LEN:2][MSG:var]
+func SerializeSigningKeyAck(ack *SigningKeyAck) []byte {
+	msgBytes := []byte(ack.Message)
+	packet := make([]byte, 0, 1+8+1+2+len(msgBytes))
+
+	packet = append(packet, MsgSigningKeyAck)
+	packet = binary.LittleEndian.AppendUint64(packet, ack.KeyID)
+	packet = append(packet, ack.Result)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(msgBytes)))
+	packet = append(packet, msgBytes...)
+
+	return packet
+}
+
+// ============================================================================
+// ENTITLEMENT SERIALIZATION/PARSING
+// ============================================================================
+
+// SerializeEntitlementRequest creates ENTITLEMENT_REQUEST packet
+// Format: [TYPE:1][UUID_LEN:2][UUID:var][TOKEN_LEN:2][TOKEN:var]
+func SerializeEntitlementRequest(droneUUID, sessionToken string) []byte {
+	packet := make([]byte, 0, 1+2+len(droneUUID)+2+len(sessionToken))
+
+	packet = append(packet, MsgEntitlementRequest)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(droneUUID)))
+	packet = append(packet, droneUUID...)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(sessionToken)))
+	packet = append(packet, sessionToken...)
+
+	return packet
+}
+
+// ParseEntitlements parses ENTITLEMENTS from router
+// Format: [TYPE:1][SERIAL:8][ISSUED:8][EXPIRES:8][LEN:2][FEATURES:var, comma-separated][SIGNATURE:64]
+func ParseEntitlements(data []byte) (*Entitlements, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("packet too short")
+	}
+
+	if data[0] != MsgEntitlements {
+		return nil, fmt.Errorf("invalid message type: 0x%02x (expected 0x%02x)", data[0], MsgEntitlements)
+	}
+
+	if len(data) < 27 {
+		return nil, fmt.Errorf("packet too short for entitlements")
+	}
+
+	listLen := int(binary.LittleEndian.Uint16(data[25:27]))
+	if len(data) < 27+listLen+64 {
+		return nil, fmt.Errorf("packet too short for feature list (%d bytes) and signature", listLen)
+	}
+
+	ent := &Entitlements{
+		Serial:    binary.LittleEndian.Uint64(data[1:9]),
+		IssuedAt:  binary.LittleEndian.Uint64(data[9:17]),
+		ExpiresAt: binary.LittleEndian.Uint64(data[17:25]),
+		List:      append([]byte(nil), data[27:27+listLen]...), // Copied: handled after the read buffer is reused
+		Signature: append([]byte(nil), data[27+listLen:27+listLen+64]...),
+	}
+	for _, f := range strings.Split(string(ent.List), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			ent.Features = append(ent.Features, f)
+		}
+	}
+	return ent, nil
+}
+
+// ============================================================================
+// DRONE STATUS SERIALIZATION
+// ============================================================================
+
+// SerializeDroneStatus creates DRONE_STATUS packet. UNTIL is when the flags end
+// on their own (Unix time, 0 = no limit).
+// Format: [TYPE:1][UUID_LEN:2][UUID:var][TOKEN_LEN:2][TOKEN:var][FLAGS:1][UNTIL:8]
+func SerializeDroneStatus(droneUUID, sessionToken string, flags byte, until uint64) []byte {
+	packet := make([]byte, 0, 1+2+len(droneUUID)+2+len(sessionToken)+1+8)
+
+	packet = append(packet, MsgDroneStatus)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(droneUUID)))
+	packet = append(packet, droneUUID...)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(sessionToken)))
+	packet = append(packet, sessionToken...)
+	packet = append(packet, flags)
+	packet = binary.LittleEndian.AppendUint64(packet, until)
+
+	return packet
+}
+
+// ============================================================================
+// POLICY REPORT SERIALIZATION
+// ============================================================================
+
+// SerializePolicyReport creates POLICY_REPORT packet. DIGEST is the SHA-256 of
+// REPORT (JSON), so the router can compare drones without parsing it.
+// Format: [TYPE:1][UUID_LEN:2][UUID:var][TOKEN_LEN:2][TOKEN:var][TIMESTAMP:8][DIGEST:32][LEN:4][REPORT:var]
+func SerializePolicyReport(droneUUID, sessionToken string, timestamp uint64, digest [32]byte, report []byte) []byte {
+	packet := make([]byte, 0, 1+2+len(droneUUID)+2+len(sessionToken)+8+32+4+len(report))
+
+	packet = append(packet, MsgPolicyReport)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(droneUUID)))
+	packet = append(packet, droneUUID...)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(sessionToken)))
+	packet = append(packet, sessionToken...)
+	packet = binary.LittleEndian.AppendUint64(packet, timestamp)
+	packet = append(packet, digest[:]...)
+	packet = binary.LittleEndian.AppendUint32(packet, uint32(len(report)))
+	packet = append(packet, report...)
+
+	return packet
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	ReplayStateFileName = ".drone_replay"
+)
+
+// replayReserve is how far ahead counters are persisted, so the state file is
+// written once per block instead of on every heartbeat. After a restart the
+// counters resume from the reserved high-water mark and never go backwards.
+const replayReserve = 1000
+
+// replayStateFile is the on-disk form of ReplayState
+type replayStateFile struct {
+	AuthCounter       uint64 `json:"auth_counter"`
+	HeartbeatSequence uint64 `json:"heartbeat_sequence"`
+	LastConfigPush    uint64 `json:"last_config_push,omitempty"`
+	LastSigningKey    uint64 `json:"last_signing_key,omitempty"`
+}
+
+// ReplayState hands out monotonic counters for AUTH_RESPONSE and SESSION_HEARTBEAT
+// that survive restarts, so the router can reject replayed packets
+type ReplayState struct {
+	mu       sync.Mutex
+	path     string
+	current  replayStateFile
+	reserved replayStateFile
+}
+
+// LoadReplayState loads (or initializes) the replay counters next to the secret file
+func LoadReplayState() (*ReplayState, error) {
+	dir, err := secretStoreDir()
+	if err != nil {
+		return nil, err
+	}
+	s := &ReplayState{path: filepath.Join(dir, ReplayStateFileName)}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		// Older versions kept the counters in the working directory; resume from
+		// there so they never go backwards when the secret directory is moved
+		if wd, wdErr := os.Getwd(); wdErr == nil && wd != dir {
+			data, err = os.ReadFile(filepath.Join(wd, ReplayStateFileName))
+		}
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read replay state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &s.reserved); err != nil {
+			return nil, fmt.Errorf("failed to parse replay state: %w", err)
+		}
+	}
+
+	// Resume from the reserved high-water mark - anything below may have been used
+	s.current = s.reserved
+	return s, nil
+}
+
+// NextAuthCounter returns the next AUTH_RESPONSE counter. It fails rather than
+// hand out a counter past the last reservation that reached the disk.
+func (s *ReplayState) NextAuthCounter() (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := s.current.AuthCounter + 1
+	if next > s.reserved.AuthCounter {
+		reserved := s.reserved
+		reserved.AuthCounter = next + replayReserve
+		if err := s.saveLocked(reserved); err != nil {
+			return 0, err
+		}
+	}
+	s.current.AuthCounter = next
+	return next, nil
+}
+
+// NextHeartbeatSequence returns the next SESSION_HEARTBEAT sequence (wraps at 16 bits on the wire).
+// It fails rather than hand out a sequence past the last persisted reservation.
+func (s *ReplayState) NextHeartbeatSequence() (uint16, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := s.current.HeartbeatSequence + 1
+	if next > s.reserved.HeartbeatSequence {
+		reserved := s.reserved
+		reserved.HeartbeatSequence = next + replayReserve
+		if err := s.saveLocked(reserved); err != nil {
+			return 0, err
+		}
+	}
+	s.current.HeartbeatSequence = next
+	return uint16(next), nil
+}
+
+// AcceptConfigPush reports whether a CONFIG_PUSH ID is newer than every push
+// accepted before and records it (persisted immediately, pushes are rare).
+// A push that could not be recorded is not accepted.
+func (s *ReplayState) AcceptConfigPush(id uint64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id <= s.current.LastConfigPush {
+		return false, nil
+	}
+	reserved := s.reserved
+	reserved.LastConfigPush = id
+	if err := s.saveLocked(reserved); err != nil {
+		return false, err
+	}
+	s.current.LastConfigPush = id
+	return true, nil
+}
+
+// AcceptSigningKey reports whether a SIGNING_KEY ID is newer than every key
+// accepted before and records it. A key that could not be recorded is not accepted.
+func (s *ReplayState) AcceptSigningKey(id uint64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id <= s.current.LastSigningKey {
+		return false, nil
+	}
+	reserved := s.reserved
+	reserved.LastSigningKey = id
+	if err := s.saveLocked(reserved); err != nil {
+		return false, err
+	}
+	s.current.LastSigningKey = id
+	return true, nil
+}
+
+// saveLocked persists reserved and makes it the reservation in effect; on
+// failure the previous reservation stays (caller holds lock)
+func (s *ReplayState) saveLocked(reserved replayStateFile) error {
+	if s.path == "" {
+		s.reserved = reserved // In-memory only
+		return nil
+	}
+	err := writeReplayState(s.path, reserved)
+	if err != nil {
+		log.Printf("[AUTH] ❌ Replay state not saved: %v", err)
+		return fmt.Errorf("failed to save replay state: %w", err)
+	}
+	s.reserved = reserved
+	return nil
+}
+
+// writeReplayState replaces the state file atomically
+func writeReplayState(path string, state replayStateFile) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// SequenceGuard detects replayed or stale 16-bit sequences using serial number
+// arithmetic (RFC 1982), so wrap-around is accepted but old values are not
+type SequenceGuard struct {
+	mu   sync.Mutex
+	last map[uint8]uint16 // SystemID -> last accepted sequence
+}
+
+// NewSequenceGuard creates an empty guard
+func NewSequenceGuard() *SequenceGuard {
+	return &SequenceGuard{last: make(map[uint8]uint16)}
+}
+
+// Accept reports whether seq from sysID is newer than anything seen before and records it
+func (g *SequenceGuard) Accept(sysID uint8, seq uint16) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	last, seen := g.last[sysID]
+	if seen && int16(seq-last) <= 0 {
+		return false
+	}
+	g.last[sysID] = seq
+	return true
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readReplayState(t *testing.T, path string) replayStateFile {
+	t.Helper()
+	var state replayStateFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func TestReplayStateReservesAhead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ReplayStateFileName)
+	s := &ReplayState{path: path}
+
+	for want := uint64(1); want <= replayReserve+2; want++ {
+		got, err := s.NextAuthCounter()
+		if err != nil || got != want {
+			t.Fatalf("NextAuthCounter() = %d, %v, want %d", got, err, want)
+		}
+		if reserved := readReplayState(t, path).AuthCounter; reserved < got {
+			t.Fatalf("counter %d handed out past the persisted reservation %d", got, reserved)
+		}
+	}
+
+	// A restart resumes from the reservation, never below a counter handed out
+	resumed := &ReplayState{path: path, reserved: readReplayState(t, path)}
+	resumed.current = resumed.reserved
+	if got, _ := resumed.NextAuthCounter(); got <= replayReserve+2 {
+		t.Errorf("NextAuthCounter() after restart = %d, want more than %d", got, replayReserve+2)
+	}
+}
+
+func TestReplayStateSaveFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	s := &ReplayState{path: filepath.Join(dir, ReplayStateFileName)}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"auth counter", func() error { _, err := s.NextAuthCounter(); return err }},
+		{"heartbeat sequence", func() error { _, err := s.NextHeartbeatSequence(); return err }},
+		{"config push", func() error {
+			ok, err := s.AcceptConfigPush(7)
+			if ok {
+				t.Error("config push: accepted without being recorded")
+			}
+			return err
+		}},
+		{"signing key", func() error {
+			ok, err := s.AcceptSigningKey(7)
+			if ok {
+				t.Error("signing key: accepted without being recorded")
+			}
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		// Refused every time, not only on the first call
+		for i := 0; i < 2; i++ {
+			if err := tt.call(); err == nil {
+				t.Errorf("%s: no error while the state file cannot be written", tt.name)
+			}
+		}
+	}
+	if s.current != (replayStateFile{}) || s.reserved != (replayStateFile{}) {
+		t.Errorf("state advanced without being persisted: current %+v, reserved %+v", s.current, s.reserved)
+	}
+
+	// Once the disk is back, counting resumes where it stopped
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.NextAuthCounter(); err != nil || got != 1 {
+		t.Errorf("NextAuthCounter() = %d, %v, want 1", got, err)
+	}
+	if ok, err := s.AcceptConfigPush(7); err != nil || !ok {
+		t.Errorf("AcceptConfigPush(7) = %v, %v, want true", ok, err)
+	}
+	if ok, _ := s.AcceptConfigPush(7); ok {
+		t.Error("AcceptConfigPush(7) accepted twice")
+	}
+}
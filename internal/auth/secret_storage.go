@@ -3,19 +3,29 @@ package auth
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
-var (
-	SecretFileName = ".drone_secret"
+const (
+	// secretFilePrefix names per-UUID secret files: .drone_secret_<uuid>
+	secretFilePrefix = ".drone_secret_"
+	// legacySecretFileName is the single-drone file used before the keyed store
+	legacySecretFileName = ".drone_secret"
 )
 
-// SetSecretFileName sets the filename used for storing the secret
-// This is used in Test Mode to avoid overwriting the production secret
-func SetSecretFileName(name string) {
-	SecretFileName = name
+// secretDir is the directory holding the secret files (empty = working directory)
+var secretDir = ""
+
+// SetSecretDir sets the directory secret files are stored in. Without it the
+// working directory is used, which is wrong for services started from another
+// directory (systemd units, Windows services, launchd agents).
+func SetSecretDir(dir string) {
+	secretDir = dir
 }
 
 // DroneSecret represents the stored secret key data
@@ -25,64 +35,94 @@ type DroneSecret struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-// getSecretFilePath returns the absolute path to the secret file
-func getSecretFilePath() (string, error) {
-	// Use current working directory (where the app is run from)
-	// This ensures .drone_secret is saved in the project directory
-	dir, err := os.Getwd()
-	if err != nil {
-		return "", err
+// secretStoreDir returns the absolute directory of the secret store
+func secretStoreDir() (string, error) {
+	if secretDir != "" {
+		return secretDir, nil
 	}
-	return filepath.Join(dir, SecretFileName), nil
+	// Use current working directory (where the app is run from)
+	return os.Getwd()
 }
 
-// LoadSecret loads the secret key from storage
-// Returns (uuid, secretKey, error)
-func LoadSecret() (string, string, error) {
-	filePath, err := getSecretFilePath()
+// executableDir returns the directory of the running binary, checked as a
+// fallback when a secret is not found in the store directory
+func executableDir() string {
+	exe, err := os.Executable()
 	if err != nil {
-		return "", "", err
+		return ""
 	}
-
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		// Fallback: try current directory if executable dir fails/is different
-		filePath = SecretFileName
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			return "", "", fmt.Errorf("secret file not found: %s", filePath)
-		}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
 	}
+	return filepath.Dir(exe)
+}
 
-	data, err := os.ReadFile(filePath)
+// SecretPath returns the secret file of a drone UUID
+func SecretPath(droneUUID string) (string, error) {
+	dir, err := secretStoreDir()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to read secret file: %w", err)
+		return "", err
 	}
+	return filepath.Join(dir, secretFilePrefix+droneUUID), nil
+}
 
+// readSecretFile parses a secret file
+func readSecretFile(path string) (*DroneSecret, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
 	var secret DroneSecret
 	if err := json.Unmarshal(data, &secret); err != nil {
-		return "", "", fmt.Errorf("failed to parse secret file: %w", err)
+		return nil, fmt.Errorf("failed to parse secret file %s: %w", path, err)
 	}
-
 	if secret.DroneUUID == "" || secret.SecretKey == "" {
-		return "", "", fmt.Errorf("invalid secret file: missing uuid or key")
+		return nil, fmt.Errorf("invalid secret file %s: missing uuid or key", path)
+	}
+	return &secret, nil
+}
+
+// LoadSecret loads the secret key of a drone UUID from the store
+func LoadSecret(droneUUID string) (string, error) {
+	path, err := SecretPath(droneUUID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		// Fallback: try the executable directory if the working directory is different
+		if exeDir := executableDir(); exeDir != "" {
+			path = filepath.Join(exeDir, secretFilePrefix+droneUUID)
+		}
 	}
 
-	return secret.DroneUUID, secret.SecretKey, nil
+	secret, err := readSecretFile(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no secret stored for %s", droneUUID)
+	}
+	if err != nil {
+		return "", err
+	}
+	if secret.DroneUUID != droneUUID {
+		return "", fmt.Errorf("secret file %s belongs to %s", path, secret.DroneUUID)
+	}
+	return secret.SecretKey, nil
 }
 
-// SaveSecret saves the secret key to storage with restricted permissions
+// SaveSecret stores the secret key of a drone UUID with owner-only permissions
 func SaveSecret(droneUUID, secretKey string) error {
-	filePath, err := getSecretFilePath()
+	path, err := SecretPath(droneUUID)
 	if err != nil {
 		return err
 	}
-
-	secret := DroneSecret{
+	return writeSecretFile(path, DroneSecret{
 		DroneUUID: droneUUID,
 		SecretKey: secretKey,
 		CreatedAt: time.Now(),
-	}
+	})
+}
 
+// writeSecretFile writes a secret atomically so a power loss can't leave a torn file
+func writeSecretFile(path string, secret DroneSecret) error {
 	data, err := json.MarshalIndent(secret, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal secret data: %w", err)
@@ -90,45 +130,99 @@ func SaveSecret(droneUUID, secretKey string) error {
 
 	// Write with 0600 permissions (read/write by owner only)
 	// Note: On Windows, permissions are limited, but Go handles basic mapping
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secret file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
 		return fmt.Errorf("failed to write secret file: %w", err)
 	}
-
 	return nil
 }
 
-// SecretExists checks if the secret file exists
-func SecretExists() bool {
-	filePath, err := getSecretFilePath()
+// SecretExists checks if a secret is stored for a drone UUID
+func SecretExists(droneUUID string) bool {
+	_, err := LoadSecret(droneUUID)
+	return err == nil
+}
+
+// DeleteSecret removes the secret of a drone UUID
+func DeleteSecret(droneUUID string) error {
+	path, err := SecretPath(droneUUID)
 	if err != nil {
-		return false
+		return err
 	}
-
-	if _, err := os.Stat(filePath); err == nil {
-		return true
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
 	}
 
-	// Check current dir as fallback
-	if _, err := os.Stat(SecretFileName); err == nil {
-		return true
+	// Also try executable dir
+	if exeDir := executableDir(); exeDir != "" {
+		os.Remove(filepath.Join(exeDir, secretFilePrefix+droneUUID))
 	}
-
-	return false
+	return nil
 }
 
-// DeleteSecret deletes the secret file
-func DeleteSecret() error {
-	filePath, err := getSecretFilePath()
+// ListSecrets returns the drone UUIDs with a stored secret, sorted
+func ListSecrets() ([]string, error) {
+	dir, err := secretStoreDir()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	matches, err := filepath.Glob(filepath.Join(dir, secretFilePrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+	uuids := []string{}
+	for _, m := range matches {
+		name := strings.TrimPrefix(filepath.Base(m), secretFilePrefix)
+		if strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		uuids = append(uuids, name)
+	}
+	sort.Strings(uuids)
+	return uuids, nil
+}
 
-	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+// MigrateLegacySecret moves a single-drone .drone_secret (from the store directory
+// or next to the executable) into the keyed store under the UUID it contains. The
+// legacy file is renamed to .drone_secret.migrated so the migration runs once.
+func MigrateLegacySecret() error {
+	dir, err := secretStoreDir()
+	if err != nil {
 		return err
 	}
+	candidates := []string{filepath.Join(dir, legacySecretFileName)}
+	if exeDir := executableDir(); exeDir != "" && exeDir != dir {
+		candidates = append(candidates, filepath.Join(exeDir, legacySecretFileName))
+	}
 
-	// Also try current dir
-	os.Remove(SecretFileName)
+	for _, legacy := range candidates {
+		secret, err := readSecretFile(legacy)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", legacy, err)
+		}
 
+		path, err := SecretPath(secret.DroneUUID)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err == nil {
+			log.Printf("[AUTH] Legacy %s ignored: a secret for %s is already stored", legacy, secret.DroneUUID)
+		} else {
+			if err := writeSecretFile(path, *secret); err != nil {
+				return fmt.Errorf("failed to migrate %s: %w", legacy, err)
+			}
+			log.Printf("[AUTH] Migrated legacy %s to %s", legacy, path)
+		}
+		if err := os.Rename(legacy, legacy+".migrated"); err != nil {
+			log.Printf("[AUTH] Warn: failed to rename migrated %s: %v", legacy, err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// authTrace times the steps of one handshake when tracing is on (auth.trace).
+// Steps carry sizes, timings, result codes and key fingerprints only - never
+// keys, nonces, signatures or session tokens.
+type authTrace struct {
+	c     *Client
+	n     uint64 // Handshake number since start
+	start time.Time
+	last  time.Time
+}
+
+// SetTrace turns handshake tracing on or off. Each step is logged and passed to
+// sink (may be nil), e.g. to keep it in the diagnostics journal.
+func (c *Client) SetTrace(enabled bool, sink func(fields map[string]interface{})) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.traceEnabled = enabled
+	c.traceSink = sink
+}
+
+// newTrace starts tracing a handshake (nil when tracing is off; a nil trace ignores steps)
+func (c *Client) newTrace() *authTrace {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.traceEnabled {
+		return nil
+	}
+	c.traceCount++
+	now := time.Now()
+	return &authTrace{c: c, n: c.traceCount, start: now, last: now}
+}
+
+// step records one handshake step with the time since the previous one
+func (t *authTrace) step(name string, fields map[string]interface{}) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	entry := map[string]interface{}{
+		"handshake": t.n,
+		"step":      name,
+		"stepMs":    now.Sub(t.last).Milliseconds(),
+		"totalMs":   now.Sub(t.start).Milliseconds(),
+	}
+	t.last = now
+
+	keys := make([]string, 0, len(fields))
+	for k, v := range fields {
+		entry[k] = v
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, fields[k]))
+	}
+	log.Printf("[AUTH_TRACE] #%d %s +%dms (%dms) %s", t.n, name, entry["stepMs"], entry["totalMs"], strings.Join(parts, " "))
+
+	t.c.mu.RLock()
+	sink := t.c.traceSink
+	t.c.mu.RUnlock()
+	if sink != nil {
+		sink(entry)
+	}
+}
+
+// finish records the outcome of the handshake
+func (t *authTrace) finish(err error) {
+	if err != nil {
+		t.step("failed", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	t.step("done", nil)
+}
+
+// keyFingerprint identifies a key without revealing it (first 4 bytes of its SHA-256),
+// so the drone and router sides can be compared when HMACs don't match
+func keyFingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:4])
+}
+
+// errorCodeName names an AUTH_ACK/SESSION error code for traces
+func errorCodeName(code byte) string {
+	switch code {
+	case ErrInvalidHMAC:
+		return "INVALID_HMAC"
+	case ErrTimestampOutOfRange:
+		return "TIMESTAMP_OUT_OF_RANGE"
+	case ErrUnknownDroneID:
+		return "UNKNOWN_DRONE_ID"
+	case ErrRateLimited:
+		return "RATE_LIMITED"
+	case ErrAlreadyRegistered:
+		return "ALREADY_REGISTERED"
+	case ErrInternalError:
+		return "INTERNAL_ERROR"
+	case ErrSessionExpired:
+		return "SESSION_EXPIRED"
+	case ErrInvalidToken:
+		return "INVALID_TOKEN"
+	case ErrNotAuthenticated:
+		return "NOT_AUTHENTICATED"
+	}
+	return fmt.Sprintf("0x%02x", code)
+}
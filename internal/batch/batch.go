@@ -0,0 +1,324 @@
+package batch
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialect"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+
+	"DroneBridge/internal/dialer"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+	"DroneBridge/internal/metrics"
+)
+
+// Compression algorithms (negotiated with the router during RATE_NEGOTIATE)
+const (
+	AlgoNone    byte = 0x00
+	AlgoDeflate byte = 0x01 // Raw DEFLATE (RFC 1951)
+	AlgoZstd    byte = 0x02 // Zstandard frame (RFC 8878)
+	AlgoLZ4     byte = 0x03 // LZ4 frame
+)
+
+// SupportedAlgorithms is the capability bitmask advertised to the router (bit N = algorithm N)
+const SupportedAlgorithms byte = 1<<AlgoDeflate | 1<<AlgoZstd | 1<<AlgoLZ4
+
+// Datagram header
+// Format: [MAGIC:2 "DB"][VERSION:1][ALGO:1][TOKEN_LEN:1][TOKEN:var][COUNT:2][RAW_LEN:4][PAYLOAD:var]
+const (
+	magic0  = 'D'
+	magic1  = 'B'
+	version = 0x01
+
+	// maxRawBytes bounds a batch so the compressed datagram stays well under typical 4G MTU
+	maxRawBytes = 4096
+
+	// dropWarnEvery limits warnings about lost batches; every loss is counted in metrics
+	dropWarnEvery = 30 * time.Second
+)
+
+// Config controls which messages are batched and how often batches are flushed
+type Config struct {
+	Address   string        // Server UDP address (same as the MAVLink target)
+	MaxFrames int           // Flush after this many frames
+	MaxDelay  time.Duration // Flush at least this often
+	Messages  []uint32      // Message IDs eligible for batching
+}
+
+// Batcher aggregates low-priority frames and sends them compressed in a single datagram
+type Batcher struct {
+	mu sync.Mutex
+
+	cfg     Config
+	ids     map[uint32]struct{}
+	tokenFn func() string
+	dialer  dialer.Dialer
+
+	conn   net.Conn
+	algo   byte
+	zstd   *zstd.Encoder // Created on first use, reused across batches
+	buf    bytes.Buffer
+	writer *frame.Writer
+	count  int
+	timer  *time.Timer
+
+	// Frames lost since the last warning
+	pendingDropped int
+	lastWarn       time.Time
+}
+
+// New creates a batcher. Batching stays inactive until the router agrees on an algorithm.
+// tokenFn returns the current session token so the router can attribute datagrams.
+func New(cfg Config, tokenFn func() string) (*Batcher, error) {
+	rw, err := dialect.NewReadWriter(mavlink_custom.GetCombinedDialect())
+	if err != nil {
+		return nil, fmt.Errorf("failed to init dialect: %w", err)
+	}
+
+	b := &Batcher{
+		cfg:     cfg,
+		ids:     make(map[uint32]struct{}, len(cfg.Messages)),
+		tokenFn: tokenFn,
+		dialer:  dialer.Real,
+	}
+	for _, id := range cfg.Messages {
+		b.ids[id] = struct{}{}
+	}
+
+	b.writer = &frame.Writer{ByteWriter: &b.buf, DialectRW: rw}
+	if err := b.writer.Initialize(); err != nil {
+		return nil, fmt.Errorf("failed to init frame writer: %w", err)
+	}
+
+	return b, nil
+}
+
+// SetAlgorithm activates batching with the algorithm chosen by the router (AlgoNone disables it)
+func (b *Batcher) SetAlgorithm(algo byte) error {
+	if algo != AlgoNone && SupportedAlgorithms&(1<<algo) == 0 {
+		return fmt.Errorf("unsupported compression algorithm 0x%02x", algo)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.flushLocked()
+	b.algo = algo
+	if algo == AlgoZstd && b.zstd == nil {
+		enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			b.algo = AlgoNone
+			return fmt.Errorf("failed to init zstd encoder: %w", err)
+		}
+		b.zstd = enc
+	}
+	if algo == AlgoNone {
+		b.closeLocked()
+		return nil
+	}
+	return b.dialLocked()
+}
+
+// SetDialer replaces the dialer used for the batch socket; takes effect on the next dial
+func (b *Batcher) SetDialer(d dialer.Dialer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.dialer = d
+}
+
+// Wants reports whether a message should go through the batcher instead of being sent directly
+func (b *Batcher) Wants(msgID uint32) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.algo == AlgoNone || b.conn == nil {
+		return false
+	}
+	_, ok := b.ids[msgID]
+	return ok
+}
+
+// Add encodes a frame into the pending batch, flushing when the batch is full
+func (b *Batcher) Add(fr frame.Frame) error {
+	// Encoding replaces the frame's message with its raw form - work on a copy
+	switch ff := fr.(type) {
+	case *frame.V2Frame:
+		c := *ff
+		fr = &c
+	case *frame.V1Frame:
+		c := *ff
+		fr = &c
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.writer.Write(fr); err != nil {
+		return err
+	}
+	b.count++
+
+	if b.count >= b.cfg.MaxFrames || b.buf.Len() >= maxRawBytes {
+		b.flushLocked()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.cfg.MaxDelay, b.Flush)
+	}
+	return nil
+}
+
+// Flush sends any pending frames immediately
+func (b *Batcher) Flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushLocked()
+}
+
+// Reconnect re-dials the server socket (e.g. after a local IP change)
+func (b *Batcher) Reconnect() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.algo == AlgoNone {
+		return
+	}
+	b.closeLocked()
+	if err := b.dialLocked(); err != nil {
+		logger.Warn("[BATCH] Reconnect failed: %v", err)
+	}
+}
+
+// SetAddress points the batcher at a new server; takes effect on the next Reconnect
+func (b *Batcher) SetAddress(addr string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg.Address = addr
+}
+
+// Close flushes pending frames and releases the socket
+func (b *Batcher) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.flushLocked()
+	b.closeLocked()
+}
+
+func (b *Batcher) dialLocked() error {
+	conn, err := b.dialer.Dial("udp", b.cfg.Address)
+	if err != nil {
+		return fmt.Errorf("failed to dial %s: %w", b.cfg.Address, err)
+	}
+	b.conn = conn
+	return nil
+}
+
+func (b *Batcher) closeLocked() {
+	if b.conn != nil {
+		b.conn.Close()
+		b.conn = nil
+	}
+}
+
+// flushLocked compresses and sends the pending batch (caller holds lock)
+func (b *Batcher) flushLocked() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if b.count == 0 {
+		return
+	}
+
+	raw := b.buf.Bytes()
+	count := b.count
+	defer func() {
+		b.buf.Reset()
+		b.count = 0
+	}()
+
+	if b.conn == nil {
+		b.droppedLocked("no_socket", count, fmt.Errorf("socket not connected"))
+		return
+	}
+
+	payload, err := b.compressLocked(raw)
+	if err != nil {
+		b.droppedLocked("compress", count, fmt.Errorf("compression failed: %w", err))
+		return
+	}
+
+	token := []byte(b.tokenFn())
+	if len(token) > 255 {
+		token = token[:255]
+	}
+
+	packet := make([]byte, 0, 5+len(token)+6+len(payload))
+	packet = append(packet, magic0, magic1, version, b.algo, byte(len(token)))
+	packet = append(packet, token...)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(count))
+	packet = binary.LittleEndian.AppendUint32(packet, uint32(len(raw)))
+	packet = append(packet, payload...)
+
+	if _, err := b.conn.Write(packet); err != nil {
+		b.droppedLocked("send", count, fmt.Errorf("send failed: %w", err))
+		return
+	}
+
+	logger.Debug("[BATCH] Sent %d frames: %d -> %d bytes", count, len(raw), len(packet))
+}
+
+// droppedLocked counts a lost batch and warns at most every dropWarnEvery (caller holds lock)
+func (b *Batcher) droppedLocked(reason string, count int, err error) {
+	metrics.Global.IncBatchDropped(reason, count)
+	logger.Debug("[BATCH] Dropped batch of %d frames: %v", count, err)
+	b.pendingDropped += count
+
+	now := time.Now()
+	if now.Sub(b.lastWarn) < dropWarnEvery {
+		return
+	}
+	msg := fmt.Sprintf("Batched telemetry lost: %d frames since the last warning (%v)", b.pendingDropped, err)
+	logger.Warn("[BATCH] %s", msg)
+	metrics.Global.AddLog("WARN", msg)
+	b.pendingDropped = 0
+	b.lastWarn = now
+}
+
+// compressLocked encodes a raw batch with the negotiated algorithm (caller holds lock)
+func (b *Batcher) compressLocked(raw []byte) ([]byte, error) {
+	var payload bytes.Buffer
+	switch b.algo {
+	case AlgoDeflate:
+		zw, err := flate.NewWriter(&payload, flate.BestSpeed)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := zw.Write(raw); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	case AlgoZstd:
+		return b.zstd.EncodeAll(raw, nil), nil
+	case AlgoLZ4:
+		zw := lz4.NewWriter(&payload)
+		if err := zw.Apply(lz4.CompressionLevelOption(lz4.Fast)); err != nil {
+			return nil, err
+		}
+		if _, err := zw.Write(raw); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm 0x%02x", b.algo)
+	}
+	return payload.Bytes(), nil
+}
@@ -0,0 +1,47 @@
+package batch
+
+import (
+	"net"
+	"testing"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+
+	"DroneBridge/internal/metrics"
+)
+
+func batchDropped(reason string) int64 {
+	snap := metrics.Global.GetSnapshot()
+	return snap["batch_dropped"].(map[string]int64)[reason]
+}
+
+func TestFailedSendIsCounted(t *testing.T) {
+	b, err := New(Config{MaxFrames: 3, Messages: []uint32{30}}, func() string { return "token" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, remote := net.Pipe()
+	remote.Close() // Every write fails
+	b.algo, b.conn = AlgoDeflate, local
+	defer b.Close()
+
+	before := batchDropped("send")
+	for round := 1; round <= 2; round++ {
+		for i := 0; i < 3; i++ {
+			if err := b.Add(&frame.V2Frame{SystemID: 1, ComponentID: 1, Message: &common.MessageAttitude{}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := batchDropped("send") - before; got != int64(3*round) {
+			t.Errorf("round %d: batch_dropped[send] = %d, want %d", round, got, 3*round)
+		}
+	}
+
+	// The first loss warns at once; the second is held for the next warning
+	b.mu.Lock()
+	pending, warned := b.pendingDropped, !b.lastWarn.IsZero()
+	b.mu.Unlock()
+	if !warned || pending != 3 {
+		t.Errorf("warned = %v, pending = %d, want true, 3", warned, pending)
+	}
+}
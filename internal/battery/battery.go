@@ -0,0 +1,313 @@
+package battery
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+const (
+	maxHistory    = 20               // Flights kept per pack
+	baselineMin   = 3                // Flights needed before sag is judged
+	sagWindow     = 60 * time.Second // Takeoff load window after arming
+	sagMinExcessV = 0.2              // Sag must exceed the baseline by at least this much (V)
+)
+
+// FlightRecord is one flight on a pack
+type FlightRecord struct {
+	Start       time.Time `json:"start"`
+	Seconds     float64   `json:"seconds"`
+	RestVoltage float64   `json:"restVoltage"` // Before arming (V)
+	MinVoltage  float64   `json:"minVoltage"`  // Lowest during the flight (V)
+	Sag         float64   `json:"sag"`         // Rest voltage minus the lowest voltage in the takeoff window (V)
+	PeakCurrent float64   `json:"peakCurrent"` // A
+	ConsumedMAh int32     `json:"consumedMah"`
+}
+
+// Pack is one battery pack tracked across flights
+type Pack struct {
+	Key            string         `json:"key"`
+	Identified     bool           `json:"identified"` // Keyed by serial number (otherwise by battery ID only)
+	Serial         string         `json:"serial,omitempty"`
+	DeviceName     string         `json:"deviceName,omitempty"`
+	Cycles         int            `json:"cycles"`         // Reported by the smart battery (-1 = not provided)
+	CapacityFull   int32          `json:"capacityFull"`   // mAh, -1 = not provided
+	CapacityDesign int32          `json:"capacityDesign"` // mAh, -1 = not provided
+	Health         int            `json:"health"`         // CapacityFull relative to design in % (-1 = unknown)
+	Flights        int            `json:"flights"`        // Flights observed by the bridge
+	FirstSeen      time.Time      `json:"firstSeen"`
+	LastSeen       time.Time      `json:"lastSeen"`
+	SagBaseline    float64        `json:"sagBaseline"` // Mean sag of earlier flights (V, 0 = not enough history)
+	AbnormalSag    bool           `json:"abnormalSag"`
+	History        []FlightRecord `json:"history"`
+}
+
+// live is the in-flight state of a battery ID
+type live struct {
+	voltage  float64
+	current  float64
+	consumed int32
+	flight   *FlightRecord
+}
+
+// Tracker follows battery packs by battery ID, identified by the serial number in
+// SMART_BATTERY_INFO when the FC sends it
+type Tracker struct {
+	mu        sync.Mutex
+	path      string
+	sagFactor float64 // Sag above baseline × this is abnormal
+	packs     map[string]*Pack
+	keys      map[uint8]string // Battery ID -> pack key
+	live      map[uint8]*live
+	armed     bool
+	armedAt   time.Time
+}
+
+// Global is the process-wide battery pack tracker
+var Global = &Tracker{
+	sagFactor: 1.5,
+	packs:     make(map[string]*Pack),
+	keys:      make(map[uint8]string),
+	live:      make(map[uint8]*live),
+}
+
+// Configure sets the sag factor and loads pack history from path. A missing file is not an error.
+func (t *Tracker) Configure(path string, sagFactor float64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.path = path
+	t.sagFactor = sagFactor
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read battery history: %w", err)
+	}
+	packs := make(map[string]*Pack)
+	if err := json.Unmarshal(data, &packs); err != nil {
+		return fmt.Errorf("failed to parse battery history: %w", err)
+	}
+	t.packs = packs
+	return nil
+}
+
+// UpdateInfo identifies the pack behind a battery ID from SMART_BATTERY_INFO
+func (t *Tracker) UpdateInfo(m *common.MessageSmartBatteryInfo) {
+	serial := strings.TrimRight(m.SerialNumber, "\x00")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := fmt.Sprintf("id%d", m.Id)
+	if serial != "" {
+		key = "sn:" + serial
+	}
+	if t.keys[m.Id] != key {
+		logger.Info("[BATTERY] Battery %d is pack %s", m.Id, key)
+	}
+	t.keys[m.Id] = key
+	p := t.packLocked(key)
+	p.Identified = serial != ""
+	p.Serial = serial
+	p.DeviceName = strings.TrimRight(m.DeviceName, "\x00")
+	p.Cycles = -1
+	if m.CycleCount != math.MaxUint16 {
+		p.Cycles = int(m.CycleCount)
+	}
+	p.CapacityFull = m.CapacityFull
+	p.CapacityDesign = m.CapacityFullSpecification
+	p.Health = -1
+	if p.CapacityFull > 0 && p.CapacityDesign > 0 {
+		p.Health = int(p.CapacityFull * 100 / p.CapacityDesign)
+	}
+}
+
+// UpdateStatus records voltage and current from BATTERY_STATUS
+func (t *Tracker) UpdateStatus(m *common.MessageBatteryStatus) {
+	voltage := packVoltage(m)
+	if voltage <= 0 {
+		return
+	}
+	current := -1.0
+	if m.CurrentBattery >= 0 {
+		current = float64(m.CurrentBattery) / 100
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	l := t.live[m.Id]
+	if l == nil {
+		l = &live{}
+		t.live[m.Id] = l
+	}
+	l.voltage = voltage
+	l.current = current
+	l.consumed = m.CurrentConsumed
+	if p := t.packs[t.keyLocked(m.Id)]; p != nil {
+		p.LastSeen = time.Now()
+	}
+
+	if f := l.flight; f != nil {
+		if voltage < f.MinVoltage {
+			f.MinVoltage = voltage
+		}
+		if time.Since(t.armedAt) <= sagWindow && f.RestVoltage-voltage > f.Sag {
+			f.Sag = f.RestVoltage - voltage
+		}
+		if current > f.PeakCurrent {
+			f.PeakCurrent = current
+		}
+	}
+}
+
+// ObserveArmed follows the armed state from heartbeats; each armed period is a flight on every reporting pack
+func (t *Tracker) ObserveArmed(armed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if armed == t.armed {
+		return
+	}
+	t.armed = armed
+	now := time.Now()
+	if armed {
+		t.armedAt = now
+		for _, l := range t.live {
+			l.flight = &FlightRecord{Start: now, RestVoltage: l.voltage, MinVoltage: l.voltage}
+		}
+		return
+	}
+
+	for id, l := range t.live {
+		if l.flight == nil {
+			continue
+		}
+		f := *l.flight
+		l.flight = nil
+		f.Seconds = now.Sub(f.Start).Seconds()
+		f.ConsumedMAh = l.consumed
+		t.finishFlightLocked(t.packLocked(t.keyLocked(id)), f)
+	}
+	t.saveLocked()
+}
+
+// finishFlightLocked adds a flight to the pack history and judges its sag against earlier flights
+func (t *Tracker) finishFlightLocked(p *Pack, f FlightRecord) {
+	var sum float64
+	for _, h := range p.History {
+		sum += h.Sag
+	}
+	p.SagBaseline = 0
+	if len(p.History) >= baselineMin {
+		p.SagBaseline = sum / float64(len(p.History))
+	}
+
+	abnormal := p.SagBaseline > 0 && f.Sag > p.SagBaseline*t.sagFactor && f.Sag-p.SagBaseline >= sagMinExcessV
+	if abnormal && !p.AbnormalSag {
+		msg := fmt.Sprintf("Battery %s sagged %.2f V on takeoff (usually %.2f V) - check the pack", p.Key, f.Sag, p.SagBaseline)
+		logger.Warn("[BATTERY] %s", msg)
+		metrics.Global.AddLog("WARN", msg)
+		alerts.Raise("battery", alerts.SeverityWarning, msg)
+	}
+	p.AbnormalSag = abnormal
+
+	p.Flights++
+	p.History = append(p.History, f)
+	if len(p.History) > maxHistory {
+		p.History = p.History[len(p.History)-maxHistory:]
+	}
+}
+
+// keyLocked returns the pack key of a battery ID (ID-only until SMART_BATTERY_INFO arrives)
+func (t *Tracker) keyLocked(id uint8) string {
+	if key, ok := t.keys[id]; ok {
+		return key
+	}
+	return fmt.Sprintf("id%d", id)
+}
+
+func (t *Tracker) packLocked(key string) *Pack {
+	p := t.packs[key]
+	if p == nil {
+		now := time.Now()
+		p = &Pack{Key: key, Cycles: -1, CapacityFull: -1, CapacityDesign: -1, Health: -1, FirstSeen: now, LastSeen: now}
+		t.packs[key] = p
+	}
+	return p
+}
+
+// packVoltage sums the cell voltages (V). Unused cells are UINT16_MAX; the
+// extension cells use 0 for not provided.
+func packVoltage(m *common.MessageBatteryStatus) float64 {
+	var mv float64
+	for _, v := range m.Voltages {
+		if v != math.MaxUint16 {
+			mv += float64(v)
+		}
+	}
+	for _, v := range m.VoltagesExt {
+		if v != 0 && v != math.MaxUint16 {
+			mv += float64(v)
+		}
+	}
+	return mv / 1000
+}
+
+// saveLocked persists the pack history atomically (caller holds t.mu)
+func (t *Tracker) saveLocked() {
+	if t.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(t.packs, "", "  ")
+	if err != nil {
+		logger.Warn("[BATTERY] Failed to encode battery history: %v", err)
+		return
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		logger.Warn("[BATTERY] Failed to write battery history: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		logger.Warn("[BATTERY] Failed to write battery history: %v", err)
+	}
+}
+
+// Snapshot returns all known packs (most recently seen first) and the ones installed now
+func (t *Tracker) Snapshot() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	packs := make([]Pack, 0, len(t.packs))
+	for _, p := range t.packs {
+		cp := *p
+		cp.History = append([]FlightRecord(nil), p.History...)
+		packs = append(packs, cp)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].LastSeen.After(packs[j].LastSeen) })
+
+	installed := make(map[string]interface{}, len(t.live))
+	for id, l := range t.live {
+		installed[fmt.Sprint(id)] = map[string]interface{}{
+			"pack":     t.keyLocked(id),
+			"voltage":  l.voltage,
+			"current":  l.current,
+			"consumed": l.consumed,
+		}
+	}
+	return map[string]interface{}{
+		"packs":     packs,
+		"installed": installed,
+	}
+}
@@ -0,0 +1,74 @@
+package camera
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"DroneBridge/internal/logger"
+)
+
+// dvrSegment is the length of one local recording file
+const dvrSegment = 5 * time.Minute
+
+// dvrSink returns a sink that writes the encoded stream to segmented Matroska
+// files (Matroska takes both Opus and AAC audio on the "out" pad)
+func (s *Streamer) dvrSink() string {
+	name := fmt.Sprintf("dvr-cam%d-%s-%%05d.mkv", s.config.CameraID, time.Now().Format("20060102-150405"))
+	return fmt.Sprintf("splitmuxsink name=out muxer-factory=matroskamux max-size-time=%d location=%s",
+		dvrSegment.Nanoseconds(), filepath.Join(s.dvrDir, name))
+}
+
+// setDVR switches the streamer between local recording (dir set) and streaming
+func (s *Streamer) setDVR(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dvrDir = dir
+}
+
+// StartRecording restarts every loaded camera to record locally into dir instead
+// of streaming, e.g. when the uplink is lost. Cameras that were stopped are started.
+func StartRecording(dir string) error {
+	if strings.ContainsAny(dir, " \t") {
+		return fmt.Errorf("recording directory must not contain whitespace")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	return switchDVR(dir)
+}
+
+// StopRecording returns every camera from local recording to streaming
+func StopRecording() error {
+	return switchDVR("")
+}
+
+func switchDVR(dir string) error {
+	mgr := GetManager()
+	cameras := mgr.GetAllCameras()
+	if len(cameras) == 0 {
+		return fmt.Errorf("no cameras loaded")
+	}
+
+	var failed []string
+	for _, camera := range cameras {
+		if err := mgr.StopCamera(camera.ID); err != nil {
+			logger.Warn("[CAMERA] Error stopping camera %d: %v", camera.ID, err)
+		}
+		camera.Streamer.setDVR(dir)
+		if err := mgr.StartCamera(camera.ID); err != nil {
+			failed = append(failed, fmt.Sprintf("camera %d: %v", camera.ID, err))
+		}
+	}
+	if dir != "" {
+		logger.Info("[CAMERA] Recording locally to %s", dir)
+	} else {
+		logger.Info("[CAMERA] Local recording stopped, streaming resumed")
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("switch failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
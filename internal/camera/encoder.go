@@ -0,0 +1,93 @@
+package camera
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"DroneBridge/internal/logger"
+)
+
+// Encoder names accepted in StreamingConfig.Encoder
+const (
+	EncoderAuto  = "auto"
+	EncoderX264  = "x264"  // x264enc (software)
+	EncoderV4L2  = "v4l2"  // v4l2h264enc (Raspberry Pi 4 and other V4L2 M2M devices)
+	EncoderOMX   = "omx"   // omxh264enc (older Raspberry Pi OS)
+	EncoderVAAPI = "vaapi" // vaapih264enc (Intel/AMD)
+	EncoderNVENC = "nvenc" // nvh264enc (NVIDIA)
+)
+
+// encoderElements maps encoder names to their GStreamer element
+var encoderElements = map[string]string{
+	EncoderX264:  "x264enc",
+	EncoderV4L2:  "v4l2h264enc",
+	EncoderOMX:   "omxh264enc",
+	EncoderVAAPI: "vaapih264enc",
+	EncoderNVENC: "nvh264enc",
+}
+
+var (
+	detectOnce sync.Once
+	detected   string
+)
+
+// isRaspberryPi reports whether we run on a Raspberry Pi
+func isRaspberryPi() bool {
+	model, err := os.ReadFile("/proc/device-tree/model")
+	return err == nil && strings.Contains(string(model), "Raspberry Pi")
+}
+
+// hasElement reports whether a GStreamer element is installed
+func hasElement(element string) bool {
+	return exec.Command("gst-inspect-1.0", "--exists", element).Run() == nil
+}
+
+// detectEncoder picks the first available hardware encoder for this platform
+func detectEncoder() string {
+	detectOnce.Do(func() {
+		candidates := []string{EncoderVAAPI, EncoderNVENC}
+		if isRaspberryPi() {
+			candidates = []string{EncoderV4L2, EncoderOMX}
+		}
+		detected = EncoderX264
+		for _, name := range candidates {
+			if hasElement(encoderElements[name]) {
+				detected = name
+				break
+			}
+		}
+		logger.Info("[STREAMING] Encoder auto-detection selected %s (%s)", detected, encoderElements[detected])
+	})
+	return detected
+}
+
+// selectEncoder resolves the configured encoder ("" or auto = detect)
+func selectEncoder(configured string) string {
+	if configured == "" || configured == EncoderAuto {
+		return detectEncoder()
+	}
+	if _, ok := encoderElements[configured]; !ok {
+		logger.Warn("[STREAMING] Unknown encoder %q, using x264", configured)
+		return EncoderX264
+	}
+	return configured
+}
+
+// encoderElement returns the pipeline fragment for an encoder. Bitrate is in kbps.
+func encoderElement(name string, bitrate, keyframe int, tune, preset string) string {
+	switch name {
+	case EncoderV4L2:
+		return fmt.Sprintf("v4l2h264enc extra-controls=controls,video_bitrate=%d,h264_i_frame_period=%d", bitrate*1000, keyframe)
+	case EncoderOMX:
+		return fmt.Sprintf("omxh264enc target-bitrate=%d control-rate=variable periodicity-idr=%d", bitrate*1000, keyframe)
+	case EncoderVAAPI:
+		return fmt.Sprintf("vaapih264enc bitrate=%d keyframe-period=%d", bitrate, keyframe)
+	case EncoderNVENC:
+		return fmt.Sprintf("nvh264enc bitrate=%d gop-size=%d", bitrate, keyframe)
+	default:
+		return fmt.Sprintf("x264enc tune=%s speed-preset=%s bitrate=%d key-int-max=%d", tune, preset, bitrate, keyframe)
+	}
+}
@@ -2,6 +2,8 @@ package camera
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,6 +19,14 @@ type Camera struct {
 	mu       sync.RWMutex
 }
 
+// startGate refuses camera starts, e.g. while video is not entitled (nil = always allowed)
+var startGate func() error
+
+// SetStartGate sets a check that must pass before any camera starts streaming
+func SetStartGate(fn func() error) {
+	startGate = fn
+}
+
 // Manager manages multiple cameras
 type Manager struct {
 	cameras map[int]*Camera
@@ -95,6 +105,11 @@ func (m *Manager) StartCamera(cameraID int) error {
 	if camera.Streamer == nil {
 		return fmt.Errorf("camera %d streamer not initialized", cameraID)
 	}
+	if startGate != nil {
+		if err := startGate(); err != nil {
+			return fmt.Errorf("camera %d not started: %w", cameraID, err)
+		}
+	}
 
 	logger.Info("[CAMERA] Starting camera %d...", cameraID)
 	if err := camera.Streamer.Start(); err != nil {
@@ -191,6 +206,46 @@ func (c *Camera) IsRunning() bool {
 	return c.Streamer.IsRunning()
 }
 
+// CameraStatus is a snapshot of one camera for the web API
+type CameraStatus struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Running   bool       `json:"running"`
+	Output    string     `json:"output"`
+	Encoder   string     `json:"encoder"`
+	Recording bool       `json:"recording"` // Recording locally instead of streaming
+	Stats     VideoStats `json:"stats"`
+}
+
+// Status returns the camera's running state and encoder statistics
+func (c *Camera) Status() CameraStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	st := CameraStatus{ID: c.ID, Name: c.Name, Output: "rtsp"}
+	if c.Config != nil && c.Config.Output != "" {
+		st.Output = c.Config.Output
+	}
+	if c.Streamer != nil {
+		st.Running = c.Streamer.IsRunning()
+		st.Encoder = c.Streamer.Encoder()
+		st.Recording = c.Streamer.Recording()
+		st.Stats = c.Streamer.Stats()
+	}
+	return st
+}
+
+// StatusAll returns the status of every loaded camera sorted by ID
+func StatusAll() []CameraStatus {
+	cameras := GetManager().GetAllCameras()
+	out := make([]CameraStatus, 0, len(cameras))
+	for _, c := range cameras {
+		out = append(out, c.Status())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
 // UpdateConfig updates camera configuration
 func (c *Camera) UpdateConfig(newConfig *StreamingConfig) error {
 	c.mu.Lock()
@@ -286,6 +341,147 @@ func StartAllCameras() error {
 	return nil
 }
 
+// RestartAllCameras stops and restarts every loaded camera pipeline
+func RestartAllCameras() error {
+	mgr := GetManager()
+	cameras := mgr.GetAllCameras()
+	if len(cameras) == 0 {
+		return fmt.Errorf("no cameras loaded")
+	}
+
+	var failed []string
+	for _, camera := range cameras {
+		if err := mgr.StopCamera(camera.ID); err != nil {
+			logger.Warn("[CAMERA] Error stopping camera %d for restart: %v", camera.ID, err)
+		}
+		if err := mgr.StartCamera(camera.ID); err != nil {
+			failed = append(failed, fmt.Sprintf("camera %d: %v", camera.ID, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("restart failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// StreamSettings are the stream parameters that can be changed remotely.
+// Zero values keep the current setting.
+type StreamSettings struct {
+	Width       int
+	Height      int
+	Framerate   int
+	BitrateKbps int
+}
+
+// Reconfigure applies new stream settings to a camera and restarts it if it was streaming
+func (m *Manager) Reconfigure(cameraID int, settings StreamSettings) error {
+	if (settings.Width == 0) != (settings.Height == 0) {
+		return fmt.Errorf("width and height must be set together")
+	}
+	if settings.Framerate < 0 || settings.Framerate > 120 {
+		return fmt.Errorf("framerate must be between 1 and 120")
+	}
+	if settings.BitrateKbps != 0 && (settings.BitrateKbps < 100 || settings.BitrateKbps > 50000) {
+		return fmt.Errorf("bitrate must be between 100 and 50000 kbps")
+	}
+
+	camera, err := m.GetCamera(cameraID)
+	if err != nil {
+		return err
+	}
+	wasRunning := camera.IsRunning()
+	if wasRunning {
+		if err := m.StopCamera(cameraID); err != nil {
+			return err
+		}
+	}
+
+	// The streamer shares the camera's config, so the next start picks this up
+	camera.mu.Lock()
+	if settings.Width > 0 {
+		camera.Config.Size = []int{settings.Width, settings.Height}
+	}
+	if settings.Framerate > 0 {
+		camera.Config.Framerate = settings.Framerate
+	}
+	if settings.BitrateKbps > 0 {
+		camera.Config.Bitrate = settings.BitrateKbps
+	}
+	logger.Info("[CAMERA] Camera %d reconfigured: %dx%d@%d, %d kbps", cameraID,
+		camera.Config.Size[0], camera.Config.Size[1], camera.Config.Framerate, camera.Config.Bitrate)
+	camera.mu.Unlock()
+
+	if wasRunning {
+		return m.StartCamera(cameraID)
+	}
+	return nil
+}
+
+// SetDetection turns landing-pad detection on or off for every camera and
+// restarts the ones that are streaming so their pipeline picks it up
+func SetDetection(enabled bool) error {
+	mgr := GetManager()
+	var failed []string
+	for _, camera := range mgr.GetAllCameras() {
+		camera.mu.Lock()
+		changed := camera.Config.DetectionEnabled != enabled
+		camera.Config.DetectionEnabled = enabled
+		camera.mu.Unlock()
+		if !changed || !camera.IsRunning() {
+			continue
+		}
+		if err := mgr.StopCamera(camera.ID); err != nil {
+			logger.Warn("[CAMERA] Error stopping camera %d for detection change: %v", camera.ID, err)
+		}
+		if err := mgr.StartCamera(camera.ID); err != nil {
+			failed = append(failed, fmt.Sprintf("camera %d: %v", camera.ID, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("restart failed: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// ControlCameras starts, stops or reconfigures one camera, or all cameras when
+// cameraID is negative. Used for on-demand streaming requested by the router.
+func ControlCameras(action string, cameraID int, settings StreamSettings) error {
+	mgr := GetManager()
+	var cameras []*Camera
+	if cameraID < 0 {
+		cameras = mgr.GetAllCameras()
+	} else if camera, err := mgr.GetCamera(cameraID); err == nil {
+		cameras = []*Camera{camera}
+	}
+	if len(cameras) == 0 {
+		return fmt.Errorf("no matching camera loaded")
+	}
+
+	var failed []string
+	for _, camera := range cameras {
+		var err error
+		switch action {
+		case "start":
+			if !camera.IsRunning() {
+				err = mgr.StartCamera(camera.ID)
+			}
+		case "stop":
+			err = mgr.StopCamera(camera.ID)
+		case "reconfigure":
+			err = mgr.Reconfigure(camera.ID, settings)
+		default:
+			return fmt.Errorf("unknown video action %q", action)
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("camera %d: %v", camera.ID, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%s failed: %s", action, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
 // WaitForCameras waits for all cameras to be ready
 func WaitForCameras(timeout time.Duration) error {
 	mgr := GetManager()
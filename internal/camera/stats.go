@@ -0,0 +1,138 @@
+package camera
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statsWindow is the window fps and bitrate are averaged over
+const statsWindow = 5 * time.Second
+
+// Patterns for gst-launch -v property notifications
+var (
+	// identity name=encstats silent=false: "... (12345 bytes, dts: ..., flags: 00004000 delta-unit )"
+	encFramePattern = regexp.MustCompile(`GstIdentity:encstats: last-message = .*?\((\d+) bytes,.*flags: [0-9a-f]+([^)]*)\)`)
+	// videorate: "GstVideoRate:rate: drop = 12" / "duplicate = 3"
+	ratePattern = regexp.MustCompile(`GstVideoRate:rate: (drop|duplicate) = (\d+)`)
+)
+
+// VideoStats is a snapshot of the encoded stream
+type VideoStats struct {
+	FPS              float64    `json:"fps"`                 // Encoded frames per second
+	BitrateKbps      float64    `json:"bitrateKbps"`         // Encoded bitrate
+	KeyframeInterval float64    `json:"keyframeIntervalSec"` // Average time between keyframes
+	Frames           uint64     `json:"frames"`
+	Keyframes        uint64     `json:"keyframes"`
+	DroppedFrames    uint64     `json:"droppedFrames"`    // Dropped by videorate (capture faster than the encoder keeps up)
+	DuplicatedFrames uint64     `json:"duplicatedFrames"` // Duplicated by videorate (camera starving the encoder)
+	LastFrame        *time.Time `json:"lastFrame,omitempty"`
+}
+
+type frameSample struct {
+	at       time.Time
+	bytes    int
+	keyframe bool
+}
+
+// videoStats accumulates encoder output parsed from the GStreamer pipeline
+type videoStats struct {
+	mu         sync.Mutex
+	samples    []frameSample // Within statsWindow
+	frames     uint64
+	keyframes  uint64
+	keyTimes   []time.Time // Recent keyframe times for cadence
+	dropped    uint64
+	duplicated uint64
+	lastFrame  time.Time
+}
+
+// parseLine consumes one line of gst-launch -v output and reports whether it was a stats line
+func (v *videoStats) parseLine(line string) bool {
+	if m := encFramePattern.FindStringSubmatch(line); m != nil {
+		size, _ := strconv.Atoi(m[1])
+		v.frame(time.Now(), size, !strings.Contains(m[2], "delta-unit"))
+		return true
+	}
+	if m := ratePattern.FindStringSubmatch(line); m != nil {
+		n, _ := strconv.ParseUint(m[2], 10, 64)
+		v.mu.Lock()
+		if m[1] == "drop" {
+			v.dropped = n
+		} else {
+			v.duplicated = n
+		}
+		v.mu.Unlock()
+		return true
+	}
+	return false
+}
+
+func (v *videoStats) frame(now time.Time, size int, keyframe bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.frames++
+	v.lastFrame = now
+	v.samples = append(v.samples, frameSample{at: now, bytes: size, keyframe: keyframe})
+	if keyframe {
+		v.keyframes++
+		v.keyTimes = append(v.keyTimes, now)
+		if len(v.keyTimes) > 10 {
+			v.keyTimes = v.keyTimes[1:]
+		}
+	}
+	v.pruneLocked(now)
+}
+
+// pruneLocked drops samples outside the window (caller holds lock)
+func (v *videoStats) pruneLocked(now time.Time) {
+	i := 0
+	for i < len(v.samples) && now.Sub(v.samples[i].at) > statsWindow {
+		i++
+	}
+	v.samples = v.samples[i:]
+}
+
+// reset clears counters when the pipeline restarts
+func (v *videoStats) reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.samples, v.keyTimes = nil, nil
+	v.frames, v.keyframes, v.dropped, v.duplicated = 0, 0, 0, 0
+	v.lastFrame = time.Time{}
+}
+
+func (v *videoStats) snapshot() VideoStats {
+	now := time.Now()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.pruneLocked(now)
+
+	s := VideoStats{
+		Frames:           v.frames,
+		Keyframes:        v.keyframes,
+		DroppedFrames:    v.dropped,
+		DuplicatedFrames: v.duplicated,
+	}
+	if !v.lastFrame.IsZero() {
+		last := v.lastFrame
+		s.LastFrame = &last
+	}
+	if len(v.samples) > 1 {
+		span := now.Sub(v.samples[0].at).Seconds()
+		if span > 0 {
+			total := 0
+			for _, f := range v.samples {
+				total += f.bytes
+			}
+			s.FPS = float64(len(v.samples)) / span
+			s.BitrateKbps = float64(total) * 8 / 1000 / span
+		}
+	}
+	if n := len(v.keyTimes); n > 1 {
+		s.KeyframeInterval = v.keyTimes[n-1].Sub(v.keyTimes[0]).Seconds() / float64(n-1)
+	}
+	return s
+}
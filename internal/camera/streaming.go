@@ -1,8 +1,10 @@
 package camera
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/url"
 	"os"
@@ -28,9 +30,42 @@ type StreamingConfig struct {
 	OverlayEnabled   bool   `json:"overlay_enabled"`
 	DetectionEnabled bool   `json:"detection_enabled"`
 	KeyframeInterval int    `json:"keyframe_interval"`
-	Preset           string `json:"preset"` // ultrafast, superfast, veryfast
-	Tune             string `json:"tune"`   // zerolatency
+	Preset           string `json:"preset"`  // ultrafast, superfast, veryfast
+	Tune             string `json:"tune"`    // zerolatency
+	Encoder          string `json:"encoder"` // auto, x264, v4l2, omx, vaapi or nvenc
 	Enabled          bool   `json:"enabled"`
+
+	// Stream path and publish authentication
+	PathTemplate string `json:"path_template"` // e.g. "org/{org_id}/{uuid}/main" (default "{uuid}")
+	OrgID        string `json:"org_id"`
+	PublishAuth  string `json:"publish_auth"` // none, password or apikey
+	PublishUser  string `json:"publish_user"`
+	PublishPass  string `json:"publish_pass"`
+
+	// Output protocol: rtsp (default) or srt (caller mode, better over lossy long-haul links)
+	Output        string `json:"output"`
+	SRTHost       string `json:"srt_host"`
+	SRTPort       int    `json:"srt_port"`
+	SRTLatency    int    `json:"srt_latency"`    // ms
+	SRTPassphrase string `json:"srt_passphrase"` // AES encryption (empty = unencrypted)
+	SRTKeyLength  int    `json:"srt_key_length"` // 16, 24 or 32
+
+	// Optional audio muxed into the output
+	AudioEnabled    bool   `json:"audio_enabled"`
+	AudioSource     string `json:"audio_source"` // alsa or pulse
+	AudioDevice     string `json:"audio_device"` // e.g. hw:1,0 (empty = default)
+	AudioSampleRate int    `json:"audio_sample_rate"`
+	AudioChannels   int    `json:"audio_channels"`
+	AudioCodec      string `json:"audio_codec"`   // opus or aac
+	AudioBitrate    int    `json:"audio_bitrate"` // kbps
+}
+
+// publishTokenSource returns the current API key for publish_auth "apikey"
+var publishTokenSource func() (string, error)
+
+// SetPublishTokenSource sets where the API key used as the publish password comes from
+func SetPublishTokenSource(fn func() (string, error)) {
+	publishTokenSource = fn
 }
 
 // LoadConfig loads configuration from JSON file
@@ -87,6 +122,10 @@ type Streamer struct {
 	mu       sync.Mutex
 	authHost string
 	uuid     string
+	secrets  []string // Publish password / SRT passphrase, masked in logs
+	stats    videoStats
+	encoder  string // Selected encoder (see encoder.go)
+	dvrDir   string // Record to local files here instead of streaming (see dvr.go)
 }
 
 // NewStreamer creates a new streamer instance
@@ -99,7 +138,8 @@ func NewStreamer(cfg *StreamingConfig, authHost, uuid string) *Streamer {
 	}
 }
 
-// Start begins the H.264 video streaming
+// Start begins the H.264 video streaming. A hardware encoder that fails to
+// start falls back to software x264enc.
 func (s *Streamer) Start() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -113,42 +153,87 @@ func (s *Streamer) Start() error {
 		return nil
 	}
 
-	logger.Info("[STREAMING] Starting H.264 stream (device=%d, resolution=%dx%d, bitrate=%d kbps)",
-		s.config.CameraID, s.config.Size[0], s.config.Size[1], s.config.Bitrate)
+	if s.encoder == "" {
+		s.encoder = selectEncoder(s.config.Encoder)
+	}
+	err := s.startLocked()
+	if err != nil && s.encoder != EncoderX264 {
+		logger.Warn("[STREAMING] Hardware encoder %s failed (%v), falling back to x264", s.encoder, err)
+		s.encoder = EncoderX264
+		err = s.startLocked()
+	}
+	return err
+}
+
+// startLocked launches the pipeline and waits for it to stabilize (caller holds mu)
+func (s *Streamer) startLocked() error {
+	logger.Info("[STREAMING] Starting H.264 stream (device=%d, resolution=%dx%d, bitrate=%d kbps, encoder=%s)",
+		s.config.CameraID, s.config.Size[0], s.config.Size[1], s.config.Bitrate, s.encoder)
 
 	// Build GStreamer pipeline
-	pipeline := s.buildPipeline()
+	sink, err := s.buildSink()
+	if err != nil {
+		return err
+	}
+	audio, err := s.buildAudio()
+	if err != nil {
+		return err
+	}
+	pipeline := s.buildPipeline(sink + audio)
 	if pipeline == "" {
 		return fmt.Errorf("unsupported platform")
 	}
 
-	// Start GStreamer
-	args := strings.Split(pipeline, " ")
+	// Start GStreamer (-v prints property notifications used for video stats)
+	args := append([]string{"-v"}, strings.Split(pipeline, " ")...)
 	s.cmd = exec.Command("gst-launch-1.0", args...)
 
 	// Redirect GStreamer output to log file instead of stdout/stderr
+	var logOut io.Writer = os.Stdout
 	logFile, err := os.OpenFile("logs/gstreamer.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		logger.Warn("[STREAMING] Failed to open GStreamer log file: %v, using stdout", err)
-		s.cmd.Stdout = os.Stdout
 		s.cmd.Stderr = os.Stderr
 	} else {
-		s.cmd.Stdout = logFile
+		logOut = logFile
 		s.cmd.Stderr = logFile
 	}
+	stdout, err := s.cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to attach to GStreamer output: %w", err)
+	}
 
 	if err := s.cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start GStreamer: %w", err)
 	}
+	s.stats.reset()
+
+	// Per-frame stats lines are consumed here, everything else goes to the log
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			if !s.stats.parseLine(scanner.Text()) {
+				fmt.Fprintln(logOut, scanner.Text())
+			}
+		}
+	}()
 
 	s.running = true
 	logger.Info("[STREAMING] ✅ H.264 streaming started (PID: %d)", s.cmd.Process.Pid)
 
 	// Monitor process in background
+	cmd := s.cmd
+	exited := make(chan struct{})
 	go func() {
-		err := s.cmd.Wait()
+		<-outputDone
+		err := cmd.Wait()
+		close(exited)
 		s.mu.Lock()
-		s.running = false
+		if s.cmd == cmd {
+			s.running = false
+		}
 		s.mu.Unlock()
 
 		if err != nil {
@@ -159,29 +244,148 @@ func (s *Streamer) Start() error {
 	}()
 
 	// Wait for pipeline to stabilize
-	time.Sleep(2 * time.Second)
+	select {
+	case <-exited:
+		s.running = false
+		return fmt.Errorf("pipeline exited during startup (see logs/gstreamer.log)")
+	case <-time.After(2 * time.Second):
+	}
 
 	return nil
 }
 
+// streamPath expands the path template. Each segment is escaped separately so
+// template slashes are kept but values can't inject extra path segments.
+func (s *Streamer) streamPath() string {
+	tmpl := s.config.PathTemplate
+	if tmpl == "" {
+		tmpl = "{uuid}"
+	}
+	segments := strings.Split(tmpl, "/")
+	for i, seg := range segments {
+		seg = strings.ReplaceAll(seg, "{uuid}", s.uuid)
+		seg = strings.ReplaceAll(seg, "{org_id}", s.config.OrgID)
+		seg = strings.ReplaceAll(seg, "{drone_id}", s.config.DroneID)
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// publishCredentials returns the RTSP publish user and password ("" = no auth)
+func (s *Streamer) publishCredentials() (string, string, error) {
+	switch s.config.PublishAuth {
+	case "", "none":
+		return "", "", nil
+	case "password":
+		return s.config.PublishUser, s.config.PublishPass, nil
+	case "apikey":
+		if publishTokenSource == nil {
+			return "", "", fmt.Errorf("publish_auth apikey: no API key source")
+		}
+		token, err := publishTokenSource()
+		if err != nil {
+			return "", "", fmt.Errorf("publish_auth apikey: %w", err)
+		}
+		user := s.config.PublishUser
+		if user == "" {
+			user = s.uuid
+		}
+		return user, token, nil
+	default:
+		return "", "", fmt.Errorf("unknown publish_auth %q", s.config.PublishAuth)
+	}
+}
+
+// buildSink returns the output element of the pipeline
+func (s *Streamer) buildSink() (string, error) {
+	s.secrets = nil
+	if s.dvrDir != "" {
+		return s.dvrSink(), nil
+	}
+	user, pass, err := s.publishCredentials()
+	if err != nil {
+		return "", err
+	}
+	// The pipeline is passed as space-separated arguments
+	if strings.ContainsAny(user+pass+s.config.SRTPassphrase, " \t") {
+		return "", fmt.Errorf("publish credentials must not contain whitespace")
+	}
+	if pass != "" {
+		s.secrets = append(s.secrets, pass)
+	}
+
+	switch s.config.Output {
+	case "", "rtsp":
+		rtspURL := fmt.Sprintf("rtsp://%s:%d/%s", s.config.MediaMTXHost, s.config.MediaMTXPort, s.streamPath())
+		logger.Info("[STREAMING] RTSP URL: %s", rtspURL)
+		if user == "" && pass == "" {
+			return "rtspclientsink name=out location=" + rtspURL, nil
+		}
+		return fmt.Sprintf("rtspclientsink name=out location=%s user-id=%s user-pw=%s", rtspURL, user, pass), nil
+
+	case "srt":
+		// Caller mode to the SRT listener; MediaMTX expects streamid publish:<path>[:user:pass]
+		srtURL := fmt.Sprintf("srt://%s:%d?mode=caller", s.config.SRTHost, s.config.SRTPort)
+		logger.Info("[STREAMING] SRT URL: %s (latency %d ms, encrypted: %v)", srtURL, s.config.SRTLatency, s.config.SRTPassphrase != "")
+		streamID := "publish:" + s.streamPath()
+		if user != "" || pass != "" {
+			streamID += ":" + user + ":" + pass
+		}
+		sink := fmt.Sprintf("mpegtsmux name=out ! srtsink uri=%s latency=%d streamid=%s", srtURL, s.config.SRTLatency, streamID)
+		if s.config.SRTPassphrase != "" {
+			s.secrets = append(s.secrets, s.config.SRTPassphrase)
+			sink += fmt.Sprintf(" passphrase=%s pbkeylen=%d", s.config.SRTPassphrase, s.config.SRTKeyLength)
+		}
+		return sink, nil
+
+	default:
+		return "", fmt.Errorf("unknown output %q", s.config.Output)
+	}
+}
+
+// buildAudio returns the optional audio branch, linked to the "out" sink/muxer
+func (s *Streamer) buildAudio() (string, error) {
+	if !s.config.AudioEnabled {
+		return "", nil
+	}
+
+	var src string
+	switch s.config.AudioSource {
+	case "", "alsa":
+		src = "alsasrc"
+		if s.config.AudioDevice != "" {
+			src += " device=" + s.config.AudioDevice
+		}
+	case "pulse":
+		src = "pulsesrc"
+		if s.config.AudioDevice != "" {
+			src += " device=" + s.config.AudioDevice
+		}
+	default:
+		return "", fmt.Errorf("unknown audio source %q", s.config.AudioSource)
+	}
+
+	var enc string
+	switch s.config.AudioCodec {
+	case "", "opus":
+		enc = fmt.Sprintf("opusenc bitrate=%d", s.config.AudioBitrate*1000)
+	case "aac":
+		enc = fmt.Sprintf("avenc_aac bitrate=%d ! aacparse", s.config.AudioBitrate*1000)
+	default:
+		return "", fmt.Errorf("unknown audio codec %q", s.config.AudioCodec)
+	}
+
+	return fmt.Sprintf(" %s ! queue ! audioconvert ! audioresample ! audio/x-raw,rate=%d,channels=%d ! %s ! out.",
+		src, s.config.AudioSampleRate, s.config.AudioChannels, enc), nil
+}
+
 // buildPipeline constructs the GStreamer pipeline based on platform
-func (s *Streamer) buildPipeline() string {
+func (s *Streamer) buildPipeline(sink string) string {
 	width := s.config.Size[0]
 	height := s.config.Size[1]
 	fps := s.config.Framerate
 	cameraID := s.config.CameraID
-	bitrate := s.config.Bitrate
-	preset := s.config.Preset
-	tune := s.config.Tune
-	keyframe := s.config.KeyframeInterval
-
-	// Build RTSP URL
-	rtspURL := fmt.Sprintf("rtsp://%s:%d/%s",
-		s.config.MediaMTXHost,
-		s.config.MediaMTXPort,
-		url.QueryEscape(s.uuid))
-
-	logger.Info("[STREAMING] RTSP URL: %s", rtspURL)
+	encoder := encoderElement(s.encoder, s.config.Bitrate, s.config.KeyframeInterval, s.config.Tune, s.config.Preset)
 
 	osName := runtime.GOOS
 	var pipeline string
@@ -194,12 +398,13 @@ func (s *Streamer) buildPipeline() string {
 				"video/x-raw,width=%d,height=%d,framerate=%d/1 ! "+
 				"videoconvert ! "+
 				"video/x-raw,format=I420 ! "+
-				"x264enc tune=%s speed-preset=%s bitrate=%d key-int-max=%d ! "+
+				"%s ! "+
+				"identity name=encstats silent=false ! "+
 				"h264parse ! "+
-				"rtspclientsink location=%s",
+				"%s",
 			cameraID, width, height, fps,
-			tune, preset, bitrate, keyframe,
-			rtspURL)
+			encoder,
+			sink)
 
 	case "linux":
 		// Linux: Use Video4Linux2 source
@@ -207,15 +412,16 @@ func (s *Streamer) buildPipeline() string {
 			"v4l2src device=/dev/video%d io-mode=mmap ! "+
 				"image/jpeg,width=%d,height=%d ! "+
 				"jpegdec ! "+
-				"videorate ! "+
+				"videorate name=rate silent=false ! "+
 				"video/x-raw,framerate=%d/1 ! "+
 				"videoconvert ! "+
-				"x264enc tune=%s speed-preset=%s bitrate=%d key-int-max=%d ! "+
+				"%s ! "+
+				"identity name=encstats silent=false ! "+
 				"h264parse ! "+
-				"rtspclientsink location=%s",
+				"%s",
 			cameraID, width, height, fps,
-			tune, preset, bitrate, keyframe,
-			rtspURL)
+			encoder,
+			sink)
 
 	case "darwin":
 		// macOS: Use AVFoundation source
@@ -224,19 +430,24 @@ func (s *Streamer) buildPipeline() string {
 				"video/x-raw,width=%d,height=%d,framerate=%d/1 ! "+
 				"videoconvert ! "+
 				"video/x-raw,format=I420 ! "+
-				"x264enc tune=%s speed-preset=%s bitrate=%d key-int-max=%d ! "+
+				"%s ! "+
+				"identity name=encstats silent=false ! "+
 				"h264parse ! "+
-				"rtspclientsink location=%s",
+				"%s",
 			width, height, fps,
-			tune, preset, bitrate, keyframe,
-			rtspURL)
+			encoder,
+			sink)
 
 	default:
 		logger.Warn("[STREAMING] Unsupported platform: %s", osName)
 		return ""
 	}
 
-	logger.Info("[STREAMING] Pipeline: %s", pipeline)
+	logged := pipeline
+	for _, secret := range s.secrets {
+		logged = strings.ReplaceAll(logged, secret, "***")
+	}
+	logger.Info("[STREAMING] Pipeline: %s", logged)
 	return pipeline
 }
 
@@ -260,6 +471,25 @@ func (s *Streamer) Stop() error {
 	return nil
 }
 
+// Stats returns encoder statistics of the running pipeline
+func (s *Streamer) Stats() VideoStats {
+	return s.stats.snapshot()
+}
+
+// Encoder returns the encoder in use (empty before the first start)
+func (s *Streamer) Encoder() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.encoder
+}
+
+// Recording reports whether the pipeline records locally instead of streaming
+func (s *Streamer) Recording() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dvrDir != ""
+}
+
 // IsRunning returns whether streaming is active
 func (s *Streamer) IsRunning() bool {
 	s.mu.Lock()
@@ -0,0 +1,185 @@
+package channels
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3"
+
+	"DroneBridge/internal/alerts"
+)
+
+const (
+	flapWindow    = 60 * time.Second // Closes within this window count towards flapping
+	flapThreshold = 3                // Closes within flapWindow that mark an endpoint as flapping
+	maxRemotes    = 20               // Remote address history kept per endpoint
+)
+
+// RemoteEntry is one remote address seen on an endpoint
+type RemoteEntry struct {
+	Address   string    `json:"address"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Opens     int       `json:"opens"`
+}
+
+// EndpointStats aggregates channel events of a single endpoint
+type EndpointStats struct {
+	Node        string        `json:"node"`     // "listener" or "sender"
+	Endpoint    string        `json:"endpoint"` // Endpoint configuration
+	OpenCount   int           `json:"openChannels"`
+	Opens       int           `json:"opens"`
+	Closes      int           `json:"closes"`
+	Flaps       int           `json:"flaps"` // Closes within the last flap window
+	Flapping    bool          `json:"flapping"`
+	LastOpen    *time.Time    `json:"lastOpen,omitempty"`
+	LastClose   *time.Time    `json:"lastClose,omitempty"`
+	OpenSeconds float64       `json:"openSeconds"` // Total time channels have been open (including current)
+	Remotes     []RemoteEntry `json:"remotes"`
+
+	open        map[*gomavlib.Channel]time.Time
+	closedTotal time.Duration
+	recentClose []time.Time
+}
+
+// Tracker collects per-endpoint channel statistics from gomavlib events
+type Tracker struct {
+	mu        sync.Mutex
+	endpoints map[string]*EndpointStats
+}
+
+// Global is the process-wide channel tracker
+var Global = New()
+
+// New creates an empty tracker
+func New() *Tracker {
+	return &Tracker{endpoints: make(map[string]*EndpointStats)}
+}
+
+func endpointKey(node string, ch *gomavlib.Channel) (string, string) {
+	ep := "unknown"
+	if e := ch.Endpoint(); e != nil {
+		ep = fmt.Sprintf("%T%+v", e.Conf(), e.Conf())
+	}
+	return node + "|" + ep, ep
+}
+
+// getLocked returns the stats for a channel's endpoint, creating them if needed (caller holds lock)
+func (t *Tracker) getLocked(node string, ch *gomavlib.Channel) *EndpointStats {
+	key, ep := endpointKey(node, ch)
+	s, ok := t.endpoints[key]
+	if !ok {
+		s = &EndpointStats{
+			Node:     node,
+			Endpoint: ep,
+			open:     make(map[*gomavlib.Channel]time.Time),
+		}
+		t.endpoints[key] = s
+	}
+	return s
+}
+
+// Opened records an EventChannelOpen
+func (t *Tracker) Opened(node string, ch *gomavlib.Channel) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.getLocked(node, ch)
+	s.open[ch] = now
+	s.Opens++
+	s.LastOpen = &now
+
+	addr := ch.String()
+	for i := range s.Remotes {
+		if s.Remotes[i].Address == addr {
+			s.Remotes[i].LastSeen = now
+			s.Remotes[i].Opens++
+			return
+		}
+	}
+	if len(s.Remotes) >= maxRemotes {
+		s.Remotes = s.Remotes[1:]
+	}
+	s.Remotes = append(s.Remotes, RemoteEntry{Address: addr, FirstSeen: now, LastSeen: now, Opens: 1})
+}
+
+// Closed records an EventChannelClose and raises an alert when the endpoint starts flapping
+func (t *Tracker) Closed(node string, ch *gomavlib.Channel) {
+	now := time.Now()
+
+	t.mu.Lock()
+	s := t.getLocked(node, ch)
+	if opened, ok := s.open[ch]; ok {
+		s.closedTotal += now.Sub(opened)
+		delete(s.open, ch)
+	}
+	s.Closes++
+	s.LastClose = &now
+
+	s.recentClose = append(s.recentClose, now)
+	s.pruneLocked(now)
+
+	wasFlapping := s.Flapping
+	s.Flapping = len(s.recentClose) >= flapThreshold
+	flapping := s.Flapping
+	flaps := len(s.recentClose)
+	endpoint := s.Endpoint
+	t.mu.Unlock()
+
+	if flapping && !wasFlapping {
+		alerts.Raise("channels", alerts.SeverityWarning, fmt.Sprintf(
+			"%s channel flapping: %d closes in %v (%s, last remote %s)",
+			node, flaps, flapWindow, endpoint, ch.String()))
+	}
+}
+
+// pruneLocked drops close events outside the flap window (caller holds lock)
+func (s *EndpointStats) pruneLocked(now time.Time) {
+	i := 0
+	for i < len(s.recentClose) && now.Sub(s.recentClose[i]) > flapWindow {
+		i++
+	}
+	s.recentClose = s.recentClose[i:]
+}
+
+// Snapshot returns per-endpoint statistics sorted by node and endpoint
+func (t *Tracker) Snapshot() []EndpointStats {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]EndpointStats, 0, len(t.endpoints))
+	for _, s := range t.endpoints {
+		s.pruneLocked(now)
+		if s.Flapping && len(s.recentClose) < flapThreshold {
+			s.Flapping = false
+		}
+
+		total := s.closedTotal
+		for _, opened := range s.open {
+			total += now.Sub(opened)
+		}
+
+		c := *s
+		c.OpenCount = len(s.open)
+		c.Flaps = len(s.recentClose)
+		c.OpenSeconds = total.Seconds()
+		c.Remotes = append([]RemoteEntry(nil), s.Remotes...)
+		c.open = nil
+		c.recentClose = nil
+		out = append(out, c)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Node != out[j].Node {
+			return out[i].Node < out[j].Node
+		}
+		return out[i].Endpoint < out[j].Endpoint
+	})
+	return out
+}
@@ -0,0 +1,220 @@
+package chaos
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"DroneBridge/internal/dialer"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// fakeIPs are reported alternately by ForceIPChange (RFC 2544 benchmark range)
+var fakeIPs = [2]string{"198.18.0.1", "198.18.0.2"}
+
+// Settings are the impairments applied to the server-facing path
+type Settings struct {
+	LatencyMs   int     `json:"latencyMs"`   // Added to every frame and auth write
+	JitterMs    int     `json:"jitterMs"`    // Random extra delay 0..jitter (frames may reorder, like UDP)
+	LossPercent float64 `json:"lossPercent"` // Dropped MAVLink frames, both directions (not applied to the auth TCP stream)
+}
+
+// Status is a snapshot of the injector
+type Status struct {
+	Enabled   bool     `json:"enabled"`
+	Settings  Settings `json:"settings"`
+	FakeIP    string   `json:"fakeIP,omitempty"` // Local IP reported to the IP monitor instead of the real one
+	IPChanges int      `json:"ipChanges"`
+	Dropped   uint64   `json:"dropped"`
+	Delayed   uint64   `json:"delayed"`
+}
+
+// Injector impairs the link to the server for bench testing of reconnects and
+// degraded-link behaviour. It does nothing unless enabled with the --chaos flag.
+type Injector struct {
+	mu        sync.Mutex
+	enabled   bool
+	settings  Settings
+	fakeIP    string
+	ipChanges int
+	rng       *rand.Rand
+
+	active  atomic.Bool // Any impairment set (fast path for every frame)
+	dropped atomic.Uint64
+	delayed atomic.Uint64
+}
+
+// Global is the process-wide injector
+var Global = &Injector{}
+
+// Enable allows impairments to be configured
+func (i *Injector) Enable() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.enabled = true
+	i.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	logger.Warn("[CHAOS] ⚠️ Link simulation enabled - DEBUG ONLY, never fly with --chaos")
+}
+
+// Enabled reports whether the debug flag was given
+func (i *Injector) Enabled() bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.enabled
+}
+
+// Set replaces the impairments (all zero = pass-through)
+func (i *Injector) Set(s Settings) error {
+	if s.LatencyMs < 0 || s.LatencyMs > 10000 || s.JitterMs < 0 || s.JitterMs > 10000 {
+		return fmt.Errorf("latencyMs and jitterMs must be 0-10000")
+	}
+	if s.LossPercent < 0 || s.LossPercent > 100 {
+		return fmt.Errorf("lossPercent must be 0-100")
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.enabled {
+		return fmt.Errorf("link simulation is disabled (start with --chaos)")
+	}
+	i.settings = s
+	i.active.Store(s.LatencyMs > 0 || s.JitterMs > 0 || s.LossPercent > 0)
+
+	msg := fmt.Sprintf("Link simulation: latency=%dms jitter=%dms loss=%.1f%%", s.LatencyMs, s.JitterMs, s.LossPercent)
+	logger.Warn("[CHAOS] %s", msg)
+	metrics.Global.AddLog("WARN", msg)
+	return nil
+}
+
+// ForceIPChange makes the IP monitor see a different local IP on its next
+// check, which runs the real reconnect sequence. Returns the reported IP.
+func (i *Injector) ForceIPChange() (string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.enabled {
+		return "", fmt.Errorf("link simulation is disabled (start with --chaos)")
+	}
+	if i.fakeIP == fakeIPs[0] {
+		i.fakeIP = fakeIPs[1]
+	} else {
+		i.fakeIP = fakeIPs[0]
+	}
+	i.ipChanges++
+	logger.Warn("[CHAOS] Simulating IP change to %s", i.fakeIP)
+	metrics.Global.AddLog("WARN", "Link simulation: IP change to "+i.fakeIP)
+	return i.fakeIP, nil
+}
+
+// ClearIP reports the real local IP again (the monitor sees one more change)
+func (i *Injector) ClearIP() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.fakeIP = ""
+}
+
+// Active reports whether frames are being impaired
+func (i *Injector) Active() bool {
+	return i.active.Load()
+}
+
+// Deliver runs fn now, later or never according to the impairments
+func (i *Injector) Deliver(fn func()) {
+	i.mu.Lock()
+	s := i.settings
+	drop := s.LossPercent > 0 && i.rng.Float64()*100 < s.LossPercent
+	delay := i.delayLocked()
+	i.mu.Unlock()
+
+	if drop {
+		i.dropped.Add(1)
+		return
+	}
+	if delay <= 0 {
+		fn()
+		return
+	}
+	i.delayed.Add(1)
+	time.AfterFunc(delay, fn)
+}
+
+// delayLocked draws latency plus jitter (caller holds i.mu)
+func (i *Injector) delayLocked() time.Duration {
+	d := time.Duration(i.settings.LatencyMs) * time.Millisecond
+	if i.settings.JitterMs > 0 {
+		d += time.Duration(i.rng.Int63n(int64(i.settings.JitterMs)+1)) * time.Millisecond
+	}
+	return d
+}
+
+// Snapshot returns the injector status
+func (i *Injector) Snapshot() Status {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return Status{
+		Enabled:   i.enabled,
+		Settings:  i.settings,
+		FakeIP:    i.fakeIP,
+		IPChanges: i.ipChanges,
+		Dropped:   i.dropped.Load(),
+		Delayed:   i.delayed.Load(),
+	}
+}
+
+// Dialer wraps d so connections are delayed on write and UDP connections
+// report the simulated local IP
+func (i *Injector) Dialer(d dialer.Dialer) dialer.Dialer {
+	return &chaosDialer{inner: d, inj: i}
+}
+
+type chaosDialer struct {
+	inner dialer.Dialer
+	inj   *Injector
+}
+
+func (d *chaosDialer) Dial(network, address string) (net.Conn, error) {
+	return d.wrap(d.inner.Dial(network, address))
+}
+
+func (d *chaosDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	return d.wrap(d.inner.DialTimeout(network, address, timeout))
+}
+
+func (d *chaosDialer) wrap(conn net.Conn, err error) (net.Conn, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &chaosConn{Conn: conn, inj: d.inj}, nil
+}
+
+// chaosConn delays writes in order (a stream cannot reorder or lose bytes)
+type chaosConn struct {
+	net.Conn
+	inj *Injector
+}
+
+func (c *chaosConn) Write(p []byte) (int, error) {
+	if c.inj.Active() {
+		c.inj.mu.Lock()
+		delay := c.inj.delayLocked()
+		c.inj.mu.Unlock()
+		if delay > 0 {
+			c.inj.delayed.Add(1)
+			time.Sleep(delay)
+		}
+	}
+	return c.Conn.Write(p)
+}
+
+func (c *chaosConn) LocalAddr() net.Addr {
+	addr := c.Conn.LocalAddr()
+	c.inj.mu.Lock()
+	fakeIP := c.inj.fakeIP
+	c.inj.mu.Unlock()
+	if udp, ok := addr.(*net.UDPAddr); ok && fakeIP != "" {
+		return &net.UDPAddr{IP: net.ParseIP(fakeIP), Port: udp.Port}
+	}
+	return addr
+}
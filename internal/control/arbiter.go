@@ -0,0 +1,216 @@
+package control
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/clock"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// Control sources
+const (
+	SourceLocal = "local" // GCS on the drone's local network (e.g. QGC next to the pilot)
+	SourceCloud = "cloud" // Commands relayed by the router
+)
+
+// Arbitration policies
+const (
+	PolicyLocalPriority = "local_priority" // Cloud control is held off while a local GCS is controlling
+	PolicyCloudPriority = "cloud_priority" // Cloud control always passes; local activity is only reported
+	PolicyTakeover      = "takeover"       // Only the owner set via the API may control
+	PolicyLastWriter    = "last_writer"    // Whoever sent control last wins; switches are notified
+)
+
+// IsControlMessage reports whether a message acts on the vehicle (mode, sticks, commands, setpoints)
+func IsControlMessage(msgID uint32) bool {
+	switch msgID {
+	case 11, // SET_MODE
+		69, // MANUAL_CONTROL
+		70, // RC_CHANNELS_OVERRIDE
+		75, // COMMAND_INT
+		76, // COMMAND_LONG
+		82, // SET_ATTITUDE_TARGET
+		84, // SET_POSITION_TARGET_LOCAL_NED
+		86: // SET_POSITION_TARGET_GLOBAL_INT
+		return true
+	}
+	return false
+}
+
+// Status is a snapshot of the arbiter
+type Status struct {
+	Policy      string               `json:"policy"`
+	Owner       string               `json:"owner"`      // Explicit owner (takeover policy)
+	Controller  string               `json:"controller"` // Source currently in control ("" = nobody active)
+	HoldSeconds float64              `json:"holdSeconds"`
+	LastActive  map[string]time.Time `json:"lastActive"`
+	Blocked     int64                `json:"blocked"`   // Cloud control messages held back
+	Conflicts   int64                `json:"conflicts"` // Times both sources were controlling at once
+}
+
+// Arbiter decides whether cloud control may reach the FC while a local GCS is also active
+type Arbiter struct {
+	clock clock.Clock
+
+	mu         sync.Mutex
+	policy     string
+	hold       time.Duration // How long a source stays "active" after its last control message
+	owner      string
+	lastActive map[string]time.Time
+	controller string
+	conflict   bool
+	blocked    int64
+	conflicts  int64
+
+	// RC override guard (see guard.go)
+	guard GuardStatus
+}
+
+// Global is the process-wide arbiter
+var Global = New(PolicyLocalPriority, 3*time.Second)
+
+// New creates an arbiter
+func New(policy string, hold time.Duration) *Arbiter {
+	return &Arbiter{
+		clock:      clock.Real,
+		policy:     policy,
+		hold:       hold,
+		owner:      SourceCloud,
+		lastActive: make(map[string]time.Time),
+	}
+}
+
+// Configure sets the policy and activity hold time
+func (a *Arbiter) Configure(policy string, hold time.Duration) error {
+	switch policy {
+	case PolicyLocalPriority, PolicyCloudPriority, PolicyTakeover, PolicyLastWriter:
+	default:
+		return fmt.Errorf("unknown control arbitration policy %q", policy)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.policy = policy
+	a.hold = hold
+	return nil
+}
+
+// Takeover makes source the explicit owner (used by the takeover policy)
+func (a *Arbiter) Takeover(source string) error {
+	if source != SourceLocal && source != SourceCloud {
+		return fmt.Errorf("owner must be %q or %q", SourceLocal, SourceCloud)
+	}
+	a.mu.Lock()
+	prev := a.owner
+	a.owner = source
+	a.mu.Unlock()
+
+	if prev != source {
+		alerts.Raise("control", alerts.SeverityInfo, fmt.Sprintf("Control taken over by %s (was %s)", source, prev))
+	}
+	return nil
+}
+
+// ObserveLocal records control from a local GCS
+func (a *Arbiter) ObserveLocal(msgID uint32) {
+	if !IsControlMessage(msgID) {
+		return
+	}
+	a.mu.Lock()
+	a.lastActive[SourceLocal] = a.clock.Now()
+	notify := a.updateLocked(SourceLocal)
+	a.mu.Unlock()
+	notify()
+}
+
+// AllowCloud reports whether a control message from the cloud may be forwarded to the FC
+func (a *Arbiter) AllowCloud(msgID uint32) bool {
+	if !IsControlMessage(msgID) {
+		return true
+	}
+	now := a.clock.Now()
+
+	a.mu.Lock()
+	localActive := now.Sub(a.lastActive[SourceLocal]) < a.hold
+	var allow bool
+	switch a.policy {
+	case PolicyLocalPriority:
+		allow = !localActive
+	case PolicyTakeover:
+		allow = a.owner == SourceCloud
+	default: // cloud_priority, last_writer
+		allow = true
+	}
+	if !allow {
+		a.blocked++
+		policy := a.policy
+		a.mu.Unlock()
+		logger.Debug("[CONTROL] Cloud control message %d held back (%s)", msgID, policy)
+		return false
+	}
+	a.lastActive[SourceCloud] = now
+	notify := a.updateLocked(SourceCloud)
+	a.mu.Unlock()
+	notify()
+	return true
+}
+
+// updateLocked tracks the controlling source and returns the notification to send
+// after the lock is released (caller holds lock)
+func (a *Arbiter) updateLocked(source string) func() {
+	now := a.clock.Now()
+	other := SourceCloud
+	if source == SourceCloud {
+		other = SourceLocal
+	}
+	bothActive := now.Sub(a.lastActive[other]) < a.hold
+
+	var msgs []string
+	if bothActive && !a.conflict {
+		a.conflicts++
+		msgs = append(msgs, fmt.Sprintf("Conflicting control: local GCS and cloud are both commanding (policy %s)", a.policy))
+	}
+	a.conflict = bothActive
+
+	if a.controller != source {
+		if a.controller != "" && a.policy == PolicyLastWriter {
+			msgs = append(msgs, fmt.Sprintf("Control switched from %s to %s (last writer wins)", a.controller, source))
+		}
+		a.controller = source
+	}
+
+	return func() {
+		for _, m := range msgs {
+			alerts.Raise("control", alerts.SeverityWarning, m)
+			metrics.Global.AddLog("WARN", m)
+		}
+	}
+}
+
+// Snapshot returns the arbiter state
+func (a *Arbiter) Snapshot() Status {
+	now := a.clock.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	controller := a.controller
+	if now.Sub(a.lastActive[controller]) >= a.hold {
+		controller = ""
+	}
+	last := make(map[string]time.Time, len(a.lastActive))
+	for k, v := range a.lastActive {
+		last[k] = v
+	}
+	return Status{
+		Policy:      a.policy,
+		Owner:       a.owner,
+		Controller:  controller,
+		HoldSeconds: a.hold.Seconds(),
+		LastActive:  last,
+		Blocked:     a.blocked,
+		Conflicts:   a.conflicts,
+	}
+}
@@ -0,0 +1,209 @@
+package control
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/testutil"
+)
+
+const (
+	msgCommandLong  = 76
+	msgManualCtrl   = 69
+	msgRCOverride   = 70
+	msgHeartbeat    = 0
+	msgGlobalPosInt = 33
+)
+
+var testStart = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestArbiter builds an arbiter on a fake clock with a 3 s hold
+func newTestArbiter(t *testing.T, policy string) (*Arbiter, *testutil.FakeClock) {
+	t.Helper()
+	clk := testutil.NewFakeClock(testStart)
+	a := New(PolicyLocalPriority, 3*time.Second)
+	a.clock = clk
+	if err := a.Configure(policy, 3*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	return a, clk
+}
+
+// recordAlerts collects control alerts raised until the test ends
+func recordAlerts(t *testing.T) func() []string {
+	t.Helper()
+	var mu sync.Mutex
+	var got []string
+	alerts.Global.SetObserver(func(a alerts.Alert) {
+		if a.Source != "control" {
+			return
+		}
+		mu.Lock()
+		got = append(got, a.Message)
+		mu.Unlock()
+	})
+	t.Cleanup(func() { alerts.Global.SetObserver(nil) })
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		out := got
+		got = nil
+		return out
+	}
+}
+
+func TestLocalPriorityHold(t *testing.T) {
+	a, clk := newTestArbiter(t, PolicyLocalPriority)
+	steps := []struct {
+		name    string
+		advance time.Duration
+		local   bool // A local GCS sends a command first
+		msgID   uint32
+		want    bool
+	}{
+		{"nobody local", 0, false, msgCommandLong, true},
+		{"local commanding", 0, true, msgCommandLong, false},
+		{"non-control message passes", 0, false, msgGlobalPosInt, true},
+		{"heartbeat passes", time.Second, false, msgHeartbeat, true},
+		{"still within the hold", 1999 * time.Millisecond, false, msgCommandLong, false},
+		{"hold expired", time.Millisecond, false, msgCommandLong, true},
+		{"local telemetry doesn't restart the hold", 0, false, msgCommandLong, true},
+	}
+	for _, st := range steps {
+		clk.Advance(st.advance)
+		if st.local {
+			a.ObserveLocal(msgCommandLong)
+		}
+		a.ObserveLocal(msgGlobalPosInt)
+		if got := a.AllowCloud(st.msgID); got != st.want {
+			t.Errorf("%s: AllowCloud(%d) = %v, want %v", st.name, st.msgID, got, st.want)
+		}
+	}
+	if s := a.Snapshot(); s.Blocked != 2 {
+		t.Errorf("blocked = %d, want 2", s.Blocked)
+	}
+}
+
+func TestTakeoverOwner(t *testing.T) {
+	a, clk := newTestArbiter(t, PolicyTakeover)
+	steps := []struct {
+		name  string
+		owner string // Takeover before the check ("" = none)
+		local bool
+		want  bool
+	}{
+		{"cloud owns by default", "", false, true},
+		{"local activity doesn't matter to the owner", "", true, true},
+		{"local takes over", SourceLocal, false, false},
+		{"local owns with nobody active", "", false, false},
+		{"cloud takes back", SourceCloud, true, true},
+	}
+	for _, st := range steps {
+		clk.Advance(10 * time.Second)
+		if st.owner != "" {
+			if err := a.Takeover(st.owner); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if st.local {
+			a.ObserveLocal(msgCommandLong)
+		}
+		if got := a.AllowCloud(msgCommandLong); got != st.want {
+			t.Errorf("%s: AllowCloud() = %v, want %v", st.name, got, st.want)
+		}
+		if owner := a.Snapshot().Owner; st.owner != "" && owner != st.owner {
+			t.Errorf("%s: owner = %q, want %q", st.name, owner, st.owner)
+		}
+	}
+	if err := a.Takeover("router"); err == nil {
+		t.Error("Takeover(\"router\") = nil, want an error")
+	}
+}
+
+func TestLastWriterSwitches(t *testing.T) {
+	a, clk := newTestArbiter(t, PolicyLastWriter)
+	raised := recordAlerts(t)
+
+	steps := []struct {
+		name         string
+		advance      time.Duration
+		source       string
+		controller   string
+		wantSwitch   string // Expected switch notification ("" = none)
+		wantConflict bool   // A conflict notification is expected
+	}{
+		{"cloud starts", 0, SourceCloud, SourceCloud, "", false},
+		{"cloud again", time.Second, SourceCloud, SourceCloud, "", false},
+		{"local takes over while cloud is active", time.Second, SourceLocal, SourceLocal, "from cloud to local", true},
+		{"cloud writes back during the same conflict", time.Second, SourceCloud, SourceCloud, "from local to cloud", false},
+		{"local alone after the hold", 10 * time.Second, SourceLocal, SourceLocal, "from cloud to local", false},
+	}
+	for _, st := range steps {
+		clk.Advance(st.advance)
+		if st.source == SourceLocal {
+			a.ObserveLocal(msgCommandLong)
+		} else if !a.AllowCloud(msgCommandLong) {
+			t.Fatalf("%s: last_writer held back cloud control", st.name)
+		}
+		if got := a.Snapshot().Controller; got != st.controller {
+			t.Errorf("%s: controller = %q, want %q", st.name, got, st.controller)
+		}
+
+		msgs := raised()
+		var switched, conflict bool
+		for _, m := range msgs {
+			switch {
+			case strings.Contains(m, "Control switched"):
+				switched = st.wantSwitch != "" && strings.Contains(m, st.wantSwitch)
+				if !switched {
+					t.Errorf("%s: unexpected %q", st.name, m)
+				}
+			case strings.Contains(m, "Conflicting control"):
+				conflict = true
+			}
+		}
+		if st.wantSwitch != "" && !switched {
+			t.Errorf("%s: no switch notification %q in %q", st.name, st.wantSwitch, msgs)
+		}
+		if conflict != st.wantConflict {
+			t.Errorf("%s: conflict notified = %v, want %v", st.name, conflict, st.wantConflict)
+		}
+	}
+
+	// The controller lapses once its hold is over
+	clk.Advance(3 * time.Second)
+	if got := a.Snapshot().Controller; got != "" {
+		t.Errorf("after the hold: controller = %q, want none", got)
+	}
+}
+
+func TestConflictCounting(t *testing.T) {
+	a, clk := newTestArbiter(t, PolicyCloudPriority)
+	steps := []struct {
+		advance time.Duration
+		source  string
+		want    int64
+	}{
+		{0, SourceCloud, 0},
+		{time.Second, SourceLocal, 1},     // Both active: a new conflict
+		{time.Second, SourceCloud, 1},     // Same conflict continues
+		{time.Second, SourceLocal, 1},     // Still the same conflict
+		{5 * time.Second, SourceCloud, 1}, // Local went quiet: conflict over
+		{time.Second, SourceCloud, 1},
+		{time.Second, SourceLocal, 2}, // Both active again: a second conflict
+	}
+	for i, st := range steps {
+		clk.Advance(st.advance)
+		if st.source == SourceLocal {
+			a.ObserveLocal(msgRCOverride)
+		} else {
+			a.AllowCloud(msgRCOverride)
+		}
+		if got := a.Snapshot().Conflicts; got != st.want {
+			t.Errorf("step %d (%s): conflicts = %d, want %d", i, st.source, got, st.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package control
+
+import (
+	"fmt"
+	"time"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/logger"
+)
+
+// IsStickInput reports whether a message carries pilot stick/RC input
+func IsStickInput(msgID uint32) bool {
+	return msgID == 69 || // MANUAL_CONTROL
+		msgID == 70 // RC_CHANNELS_OVERRIDE
+}
+
+// GuardStatus is a snapshot of the RC override guard
+type GuardStatus struct {
+	Enabled        bool       `json:"enabled"`        // Guard active (config)
+	RemotePiloting bool       `json:"remotePiloting"` // Cloud stick input allowed
+	ChangedAt      *time.Time `json:"changedAt,omitempty"`
+	ChangedBy      string     `json:"changedBy,omitempty"`
+	Blocked        int64      `json:"blocked"` // Cloud stick messages dropped
+}
+
+// ConfigureGuard enables the RC override guard. Remote piloting always starts
+// disabled; changing it needs an admin token (see web tokenGuard).
+func (a *Arbiter) ConfigureGuard(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.guard.Enabled = enabled
+	a.guard.RemotePiloting = false
+}
+
+// SetRemotePiloting allows or blocks cloud stick input (caller must have authorized the request)
+func (a *Arbiter) SetRemotePiloting(enabled bool, by string) {
+	now := a.clock.Now()
+	a.mu.Lock()
+	changed := a.guard.RemotePiloting != enabled
+	a.guard.RemotePiloting = enabled
+	a.guard.ChangedAt = &now
+	a.guard.ChangedBy = by
+	a.mu.Unlock()
+
+	if changed {
+		state := "disabled"
+		if enabled {
+			state = "enabled"
+		}
+		alerts.Raise("control", alerts.SeverityWarning, fmt.Sprintf("Remote piloting %s by %s", state, by))
+	}
+}
+
+// AllowStickInput reports whether stick/RC input from the cloud may reach the FC
+func (a *Arbiter) AllowStickInput(msgID uint32) bool {
+	if !IsStickInput(msgID) {
+		return true
+	}
+	a.mu.Lock()
+	allow := !a.guard.Enabled || a.guard.RemotePiloting
+	if !allow {
+		a.guard.Blocked++
+	}
+	blocked := a.guard.Blocked
+	a.mu.Unlock()
+
+	if !allow && blocked%100 == 1 {
+		logger.Warn("[CONTROL] Blocked cloud stick input (msg %d) - remote piloting is disabled (%d blocked)", msgID, blocked)
+	}
+	return allow
+}
+
+// GuardSnapshot returns the RC override guard state
+func (a *Arbiter) GuardSnapshot() GuardStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.guard
+}
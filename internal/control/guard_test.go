@@ -0,0 +1,51 @@
+package control
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllowStickInput(t *testing.T) {
+	tests := []struct {
+		name        string
+		guard       bool
+		remote      bool
+		msgID       uint32
+		want        bool
+		wantBlocked int64
+	}{
+		{"guard off", false, false, msgManualCtrl, true, 0},
+		{"guard on, remote piloting off: MANUAL_CONTROL", true, false, msgManualCtrl, false, 1},
+		{"guard on, remote piloting off: RC_CHANNELS_OVERRIDE", true, false, msgRCOverride, false, 1},
+		{"guard on, remote piloting off: commands pass", true, false, msgCommandLong, true, 0},
+		{"guard on, remote piloting on", true, true, msgManualCtrl, true, 0},
+	}
+	for _, tt := range tests {
+		a, clk := newTestArbiter(t, PolicyCloudPriority)
+		a.ConfigureGuard(tt.guard)
+		clk.Advance(time.Minute)
+		if tt.remote {
+			a.SetRemotePiloting(true, "tok-a")
+		}
+		if got := a.AllowStickInput(tt.msgID); got != tt.want {
+			t.Errorf("%s: AllowStickInput(%d) = %v, want %v", tt.name, tt.msgID, got, tt.want)
+		}
+		g := a.GuardSnapshot()
+		if g.Blocked != tt.wantBlocked {
+			t.Errorf("%s: blocked = %d, want %d", tt.name, g.Blocked, tt.wantBlocked)
+		}
+		if tt.remote && (g.ChangedBy != "tok-a" || g.ChangedAt == nil || !g.ChangedAt.Equal(clk.Now())) {
+			t.Errorf("%s: changed by %q at %v, want tok-a at %v", tt.name, g.ChangedBy, g.ChangedAt, clk.Now())
+		}
+	}
+}
+
+func TestConfigureGuardResetsRemotePiloting(t *testing.T) {
+	a, _ := newTestArbiter(t, PolicyCloudPriority)
+	a.ConfigureGuard(true)
+	a.SetRemotePiloting(true, "tok-a")
+	a.ConfigureGuard(true)
+	if a.AllowStickInput(msgManualCtrl) {
+		t.Error("remote piloting survived ConfigureGuard, want it to start disabled")
+	}
+}
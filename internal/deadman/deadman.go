@@ -0,0 +1,237 @@
+package deadman
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/clock"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// Action is one step run when the uplink is declared dead
+type Action struct {
+	Run     func() error
+	Restore func() // Undoes the action when the uplink returns or the vehicle disarms (may be nil)
+}
+
+// Watchdog runs a configured action list once when neither a session refresh nor
+// any server traffic has succeeded for the timeout while the vehicle is armed.
+// Unlike the forwarder's health flag it does not gate forwarding; it is the
+// bridge's last resort for a flight that has lost its ground link.
+type Watchdog struct {
+	lastUplink atomic.Int64 // Unix nanoseconds of the last uplink evidence
+	clock      clock.Clock
+
+	mu        sync.Mutex
+	enabled   bool
+	timeout   time.Duration
+	names     []string
+	actions   map[string]Action
+	armed     bool
+	armedAt   time.Time
+	suspended bool // Maintenance mode: no actions until resumed
+	fired     bool
+	firedAt   time.Time
+	executed  []string // Actions that ran for the current trip
+	failures  map[string]string
+}
+
+// Global is the process-wide dead-man watchdog
+var Global = New()
+
+// New creates a disabled watchdog
+func New() *Watchdog {
+	w := &Watchdog{clock: clock.Real, timeout: 3 * time.Minute, actions: make(map[string]Action), failures: make(map[string]string)}
+	w.Touch()
+	return w
+}
+
+// RegisterAction makes an action available to the configured list
+func (w *Watchdog) RegisterAction(name string, a Action) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.actions[name] = a
+}
+
+// Configure enables the watchdog with the actions to run, in order
+func (w *Watchdog) Configure(enabled bool, timeout time.Duration, actions []string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, name := range actions {
+		if _, ok := w.actions[name]; !ok {
+			return fmt.Errorf("unknown dead-man action %q", name)
+		}
+	}
+	w.enabled = enabled
+	w.timeout = timeout
+	w.names = actions
+	return nil
+}
+
+// Suspend holds the actions back (e.g. for bench work in maintenance mode) and
+// stands down a trip in progress; resuming restarts the silence count
+func (w *Watchdog) Suspend(suspended bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if suspended == w.suspended {
+		return
+	}
+	w.suspended = suspended
+	if suspended {
+		if w.fired {
+			logger.Info("[DEADMAN] Suspended, standing down")
+			w.restoreLocked()
+		}
+		return
+	}
+	w.Touch() // Forwarding was paused, the silence says nothing about the link
+}
+
+// Touch records uplink evidence: a successful session refresh or a frame from the server
+func (w *Watchdog) Touch() {
+	w.lastUplink.Store(w.clock.Now().UnixNano())
+}
+
+// ObserveArmed follows the armed state from heartbeats; disarming ends a trip
+func (w *Watchdog) ObserveArmed(armed bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if armed == w.armed {
+		return
+	}
+	w.armed = armed
+	if armed {
+		w.armedAt = w.clock.Now()
+		return
+	}
+	if w.fired {
+		logger.Info("[DEADMAN] Vehicle disarmed, standing down")
+		w.restoreLocked()
+	}
+}
+
+// silenceLocked returns how long the uplink has been quiet, counted from arming at the earliest
+func (w *Watchdog) silenceLocked(now time.Time) time.Duration {
+	last := time.Unix(0, w.lastUplink.Load())
+	if w.armedAt.After(last) {
+		last = w.armedAt
+	}
+	return now.Sub(last)
+}
+
+// Check fires the action list when the uplink has been silent too long, and stands
+// down once it is back
+func (w *Watchdog) Check() {
+	// Actions wait on the FC and cameras, so they run without w.mu; heartbeats must not block
+	for _, name := range w.trip() {
+		w.mu.Lock()
+		action, fired := w.actions[name], w.fired
+		w.mu.Unlock()
+		if !fired {
+			return // Disarmed or uplink back meanwhile
+		}
+
+		err := action.Run()
+		w.mu.Lock()
+		if err != nil {
+			logger.Error("[DEADMAN] Action %s failed: %v", name, err)
+			w.failures[name] = err.Error()
+		} else {
+			logger.Info("[DEADMAN] Action %s done", name)
+			w.executed = append(w.executed, name)
+		}
+		w.mu.Unlock()
+	}
+}
+
+// trip decides whether the watchdog fires now and returns the actions to run
+func (w *Watchdog) trip() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.enabled || !w.armed || w.suspended {
+		return nil
+	}
+	silence := w.silenceLocked(w.clock.Now())
+	if w.fired {
+		if silence < w.timeout {
+			msg := "Uplink restored, dead-man actions stood down"
+			logger.Info("[DEADMAN] %s", msg)
+			metrics.Global.AddLog("INFO", msg)
+			w.restoreLocked()
+		}
+		return nil
+	}
+	if silence < w.timeout {
+		return nil
+	}
+
+	w.fired = true
+	w.firedAt = w.clock.Now()
+	w.executed = nil
+	w.failures = make(map[string]string)
+	msg := fmt.Sprintf("No uplink for %v while armed - running %s", silence.Round(time.Second), strings.Join(w.names, ", "))
+	logger.Error("[DEADMAN] %s", msg)
+	metrics.Global.AddLog("ERROR", msg)
+	alerts.Raise("deadman", alerts.SeverityCritical, msg)
+	return w.names
+}
+
+// restoreLocked undoes the executed actions in reverse order, in the background (caller holds w.mu)
+func (w *Watchdog) restoreLocked() {
+	var restores []func()
+	for i := len(w.executed) - 1; i >= 0; i-- {
+		if restore := w.actions[w.executed[i]].Restore; restore != nil {
+			restores = append(restores, restore)
+		}
+	}
+	w.fired = false
+	w.executed = nil
+	go func() {
+		for _, restore := range restores {
+			restore()
+		}
+	}()
+}
+
+// Run checks the uplink every interval until stopCh is closed
+func (w *Watchdog) Run(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := w.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C():
+			w.Check()
+		}
+	}
+}
+
+// Snapshot returns the watchdog state for the web API
+func (w *Watchdog) Snapshot() map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s := map[string]interface{}{
+		"enabled":        w.enabled,
+		"timeoutSeconds": w.timeout.Seconds(),
+		"actions":        w.names,
+		"silenceSeconds": w.clock.Since(time.Unix(0, w.lastUplink.Load())).Seconds(),
+		"suspended":      w.suspended,
+		"fired":          w.fired,
+	}
+	if w.fired {
+		s["firedAt"] = w.firedAt
+		failures := make(map[string]string, len(w.failures))
+		for k, v := range w.failures {
+			failures[k] = v
+		}
+		s["executed"] = append([]string(nil), w.executed...)
+		s["failures"] = failures
+	}
+	return s
+}
@@ -0,0 +1,131 @@
+package deadman
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"DroneBridge/internal/testutil"
+)
+
+var testStart = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestWatchdog builds a watchdog on a fake clock with a "hold" action that
+// counts its runs and a "photo" action that always fails
+func newTestWatchdog(t *testing.T, timeout time.Duration) (*Watchdog, *testutil.FakeClock, *int, chan struct{}) {
+	t.Helper()
+	clk := testutil.NewFakeClock(testStart)
+	w := New()
+	w.clock = clk
+	w.Touch()
+
+	runs := 0
+	restored := make(chan struct{}, 4)
+	w.RegisterAction("hold", Action{
+		Run:     func() error { runs++; return nil },
+		Restore: func() { restored <- struct{}{} },
+	})
+	w.RegisterAction("photo", Action{Run: func() error { return errors.New("no camera") }})
+	if err := w.Configure(true, timeout, []string{"photo", "hold"}); err != nil {
+		t.Fatal(err)
+	}
+	return w, clk, &runs, restored
+}
+
+func TestWatchdogTiming(t *testing.T) {
+	w, clk, runs, restored := newTestWatchdog(t, time.Minute)
+
+	steps := []struct {
+		name     string
+		do       func()
+		wantRuns int
+		wantFire bool
+	}{
+		{"silent while disarmed", func() { clk.Advance(5 * time.Minute) }, 0, false},
+		{"armed after a long silence", func() { w.ObserveArmed(true) }, 0, false},
+		{"just under the timeout after arming", func() { clk.Advance(59 * time.Second) }, 0, false},
+		{"timeout reached", func() { clk.Advance(time.Second) }, 1, true},
+		{"fires once per trip", func() { clk.Advance(time.Minute) }, 1, true},
+		{"uplink back", func() { w.Touch() }, 1, false},
+		{"silent again", func() { clk.Advance(time.Minute) }, 2, true},
+		{"disarmed", func() { w.ObserveArmed(false) }, 2, false},
+		{"silent after disarming", func() { clk.Advance(10 * time.Minute) }, 2, false},
+	}
+	for _, step := range steps {
+		step.do()
+		w.Check()
+		if *runs != step.wantRuns {
+			t.Errorf("%s: action ran %d times, want %d", step.name, *runs, step.wantRuns)
+		}
+		if fired := w.Snapshot()["fired"].(bool); fired != step.wantFire {
+			t.Errorf("%s: fired = %v, want %v", step.name, fired, step.wantFire)
+		}
+	}
+
+	// Both stand-downs restored the executed action
+	for i := 0; i < 2; i++ {
+		select {
+		case <-restored:
+		case <-time.After(time.Second):
+			t.Fatalf("restore %d not run", i+1)
+		}
+	}
+}
+
+func TestWatchdogFailedActionDoesNotStopTheList(t *testing.T) {
+	w, clk, runs, _ := newTestWatchdog(t, time.Minute)
+	w.ObserveArmed(true)
+	clk.Advance(time.Minute)
+	w.Check()
+
+	if *runs != 1 {
+		t.Errorf("hold ran %d times after photo failed, want 1", *runs)
+	}
+	snap := w.Snapshot()
+	if got := snap["executed"].([]string); len(got) != 1 || got[0] != "hold" {
+		t.Errorf("executed = %v, want [hold]", got)
+	}
+	if got := snap["failures"].(map[string]string)["photo"]; got != "no camera" {
+		t.Errorf("photo failure = %q, want %q", got, "no camera")
+	}
+}
+
+func TestWatchdogSuspended(t *testing.T) {
+	w, clk, runs, restored := newTestWatchdog(t, time.Minute)
+	w.ObserveArmed(true)
+	clk.Advance(time.Minute)
+	w.Check()
+
+	// Suspending stands the trip down; while suspended nothing fires
+	w.Suspend(true)
+	select {
+	case <-restored:
+	case <-time.After(time.Second):
+		t.Fatal("suspend did not restore the action")
+	}
+	clk.Advance(10 * time.Minute)
+	w.Check()
+	if *runs != 1 {
+		t.Errorf("action ran %d times while suspended, want 1", *runs)
+	}
+
+	// Resuming restarts the silence count
+	w.Suspend(false)
+	clk.Advance(59 * time.Second)
+	w.Check()
+	if *runs != 1 {
+		t.Errorf("action ran %d times before the timeout after resuming, want 1", *runs)
+	}
+	clk.Advance(time.Second)
+	w.Check()
+	if *runs != 2 {
+		t.Errorf("action ran %d times after the timeout, want 2", *runs)
+	}
+}
+
+func TestConfigureUnknownAction(t *testing.T) {
+	w := New()
+	if err := w.Configure(true, time.Minute, []string{"parachute"}); err == nil {
+		t.Error("Configure() accepted an unknown action")
+	}
+}
@@ -0,0 +1,266 @@
+package diagnose
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"DroneBridge/config"
+	"DroneBridge/internal/auth"
+	"DroneBridge/internal/forwarder"
+)
+
+// Check results
+const (
+	StatusPass = "PASS"
+	StatusWarn = "WARN" // Works, but probably not as intended in the field
+	StatusFail = "FAIL"
+	StatusSkip = "SKIP" // Not applicable with this configuration
+)
+
+// dialTimeout bounds each reachability check
+const dialTimeout = 5 * time.Second
+
+// minClockYear is the earliest plausible wall-clock year; boards without an RTC
+// boot in 1970 (or at the last fake-hwclock save) until NTP syncs
+const minClockYear = 2025
+
+// Options select the configuration to check
+type Options struct {
+	ConfigFile    string
+	ContainerMode bool
+	SecretDir     string // Overrides paths.secret_dir (test mode)
+}
+
+// Check is the outcome of one commissioning check
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// Report is the result of all checks; it passes when nothing failed
+type Report struct {
+	Generated time.Time `json:"generated"`
+	Checks    []Check   `json:"checks"`
+	Passed    bool      `json:"passed"`
+}
+
+func (r *Report) add(name, status, format string, v ...interface{}) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: status, Detail: fmt.Sprintf(format, v...)})
+}
+
+// Run performs the field commissioning checks. Pixhawk discovery binds the
+// MAVLink listen port, so the bridge service must be stopped first.
+func Run(opts Options) *Report {
+	r := &Report{Generated: time.Now()}
+	defer func() {
+		r.Passed = true
+		for _, c := range r.Checks {
+			r.Passed = r.Passed && c.Status != StatusFail
+		}
+	}()
+
+	cfg, ok := checkConfig(r, opts)
+	if !ok {
+		for _, name := range []string{"secret", "hmac", "auth_host", "mediamtx", "pixhawk", "camera", "gstreamer"} {
+			r.add(name, StatusSkip, "configuration did not load")
+		}
+		checkClock(r)
+		return r
+	}
+
+	checkSecret(r, cfg, opts.SecretDir)
+	if err := auth.SelfTestHMAC(); err != nil {
+		r.add("hmac", StatusFail, "%v", err)
+	} else {
+		r.add("hmac", StatusPass, "key derivation and HMAC-SHA256 known-answer test")
+	}
+	checkAuthHost(r, cfg)
+	checkMediaMTX(r, cfg)
+	checkPixhawk(r, cfg)
+	checkCamera(r, cfg)
+	checkGStreamer(r, cfg)
+	checkClock(r)
+	return r
+}
+
+func checkConfig(r *Report, opts Options) (*config.Config, bool) {
+	var cfg *config.Config
+	var err error
+	if opts.ContainerMode {
+		cfg, err = config.LoadContainer(opts.ConfigFile)
+	} else {
+		cfg, err = config.Load(opts.ConfigFile)
+	}
+	if err != nil {
+		r.add("config", StatusFail, "%v", err)
+		return nil, false
+	}
+	if cfg.Auth.UUID == "" {
+		id, err := auth.HardwareUUID()
+		if err != nil {
+			r.add("config", StatusFail, "auth.uuid is empty and no hardware identity to derive it from: %v", err)
+			return nil, false
+		}
+		cfg.Auth.UUID = id
+		r.add("config", StatusPass, "auth.uuid empty - hardware UUID is %s", id)
+	}
+	if !auth.ValidUUID(cfg.Auth.UUID) {
+		r.add("config", StatusFail, "auth.uuid %q is not a UUID", cfg.Auth.UUID)
+		return nil, false
+	}
+	r.add("config", StatusPass, "%s valid (drone %s)", opts.ConfigFile, cfg.Auth.UUID)
+	return cfg, true
+}
+
+func checkSecret(r *Report, cfg *config.Config, secretDir string) {
+	if !cfg.Features.Auth || !cfg.Auth.Enabled {
+		r.add("secret", StatusSkip, "authentication disabled")
+		return
+	}
+	if secretDir == "" {
+		secretDir = cfg.Paths.SecretDir
+	}
+	if secretDir != "" {
+		auth.SetSecretDir(secretDir)
+	}
+	if cfg.Auth.Mode == "mtls" {
+		if _, err := auth.LoadTLSConfig(cfg.Auth.TLS.CertFile, cfg.Auth.TLS.KeyFile, cfg.Auth.TLS.CAFile, cfg.Auth.TLS.ServerName); err != nil {
+			r.add("secret", StatusFail, "mTLS identity: %v", err)
+			return
+		}
+		r.add("secret", StatusPass, "mTLS client certificate %s loads", cfg.Auth.TLS.CertFile)
+		return
+	}
+	if _, err := auth.LoadSecret(cfg.Auth.UUID); err != nil {
+		r.add("secret", StatusFail, "%v - run with --register once", err)
+		return
+	}
+	if cfg.Auth.SharedSecret == "" {
+		r.add("secret", StatusWarn, "drone secret present, auth.shared_secret empty (raw key is used)")
+		return
+	}
+	r.add("secret", StatusPass, "drone secret and auth.shared_secret present")
+}
+
+func checkAuthHost(r *Report, cfg *config.Config) {
+	if !cfg.Features.Auth || !cfg.Auth.Enabled {
+		r.add("auth_host", StatusSkip, "authentication disabled")
+		return
+	}
+	addr := net.JoinHostPort(cfg.Auth.Host, fmt.Sprint(cfg.Auth.Port))
+	status, detail := probe(addr)
+	r.add("auth_host", status, "%s", detail)
+}
+
+func checkMediaMTX(r *Report, cfg *config.Config) {
+	if !cfg.Features.Video || !cfg.Camera.Enabled {
+		r.add("mediamtx", StatusSkip, "video disabled")
+		return
+	}
+	if cfg.Camera.Output == "srt" {
+		// SRT is UDP: nothing answers a probe without a handshake
+		r.add("mediamtx", StatusSkip, "SRT output is not probed")
+		return
+	}
+	addr := net.JoinHostPort(cfg.Camera.MediaMTX.Host, fmt.Sprint(cfg.Camera.MediaMTX.Port))
+	status, detail := probe(addr)
+	r.add("mediamtx", status, "RTSP %s", detail)
+}
+
+// probe connects to a TCP address and reports the connect time
+func probe(addr string) (string, string) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("%s unreachable: %v", addr, err)
+	}
+	conn.Close()
+	return StatusPass, fmt.Sprintf("%s reachable (%v)", addr, time.Since(start).Round(time.Millisecond))
+}
+
+func checkPixhawk(r *Report, cfg *config.Config) {
+	timeout := time.Duration(cfg.Ethernet.PixhawkConnectionTimeout) * time.Second
+	ip, port, sysID, err := forwarder.DiscoverPixhawk(cfg, timeout)
+	if err != nil {
+		status := StatusFail
+		if cfg.Ethernet.AllowMissingPixhawk {
+			status = StatusWarn
+		}
+		r.add("pixhawk", status, "not discovered within %v: %v", timeout, err)
+		return
+	}
+	r.add("pixhawk", StatusPass, "%s:%d (System ID %d)", ip, port, sysID)
+}
+
+func checkCamera(r *Report, cfg *config.Config) {
+	if !cfg.Features.Camera || !cfg.Camera.Enabled {
+		r.add("camera", StatusSkip, "camera disabled")
+		return
+	}
+	device := fmt.Sprintf("/dev/video%d", cfg.Camera.CameraID)
+	if _, err := os.Stat(device); err != nil {
+		r.add("camera", StatusFail, "%s not present", device)
+		return
+	}
+	r.add("camera", StatusPass, "%s present", device)
+}
+
+func checkGStreamer(r *Report, cfg *config.Config) {
+	if !cfg.Features.Camera || !cfg.Camera.Enabled {
+		r.add("gstreamer", StatusSkip, "camera disabled")
+		return
+	}
+	path, err := exec.LookPath("gst-launch-1.0")
+	if err != nil {
+		r.add("gstreamer", StatusFail, "gst-launch-1.0 not installed")
+		return
+	}
+	if err := exec.Command("gst-inspect-1.0", "--exists", "v4l2src").Run(); err != nil {
+		r.add("gstreamer", StatusFail, "%s found, but the v4l2src element is missing (gstreamer1.0-plugins-good)", path)
+		return
+	}
+	r.add("gstreamer", StatusPass, "%s with v4l2src", path)
+}
+
+// checkClock fails on a clock that was never set (HMAC timestamps would be
+// rejected) and warns when the system reports NTP as not synchronised
+func checkClock(r *Report) {
+	now := time.Now()
+	if now.Year() < minClockYear {
+		r.add("clock", StatusFail, "system time %s is not set (no RTC / NTP)", now.UTC().Format(time.RFC3339))
+		return
+	}
+	if runtime.GOOS == "linux" {
+		out, err := exec.Command("timedatectl", "show", "-p", "NTPSynchronized", "--value").Output()
+		if err == nil && strings.TrimSpace(string(out)) == "no" {
+			r.add("clock", StatusWarn, "%s, NTP not synchronised", now.UTC().Format(time.RFC3339))
+			return
+		}
+	}
+	r.add("clock", StatusPass, "%s", now.UTC().Format(time.RFC3339))
+}
+
+// Print writes the report as a table with a final verdict
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintln(w, "DroneBridge commissioning check")
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	counts := map[string]int{}
+	for _, c := range r.Checks {
+		counts[c.Status]++
+		fmt.Fprintf(w, "[%s] %-10s %s\n", c.Status, c.Name, c.Detail)
+	}
+	fmt.Fprintln(w, strings.Repeat("=", 60))
+	verdict := "PASS"
+	if !r.Passed {
+		verdict = "FAIL"
+	}
+	fmt.Fprintf(w, "Result: %s (%d passed, %d warnings, %d failed, %d skipped)\n",
+		verdict, counts[StatusPass], counts[StatusWarn], counts[StatusFail], counts[StatusSkip])
+}
@@ -0,0 +1,25 @@
+package dialer
+
+import (
+	"net"
+	"time"
+)
+
+// Dialer abstracts outbound connections so reconnection logic can run against fake networks
+type Dialer interface {
+	Dial(network, address string) (net.Conn, error)
+	DialTimeout(network, address string, timeout time.Duration) (net.Conn, error)
+}
+
+// Real dials using the net package
+var Real Dialer = realDialer{}
+
+type realDialer struct{}
+
+func (realDialer) Dial(network, address string) (net.Conn, error) {
+	return net.Dial(network, address)
+}
+
+func (realDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout(network, address, timeout)
+}
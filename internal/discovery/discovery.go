@@ -0,0 +1,193 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/logger"
+)
+
+// Config controls the discovery beacon
+type Config struct {
+	Enabled     bool          // Initial state; can be toggled at runtime
+	Port        int           // UDP port ground tools listen on
+	BroadcastIP string        // Destination address (default: 255.255.255.255)
+	Interval    time.Duration // Beacon interval
+	WebPort     int           // Advertised dashboard/API port
+	MAVLinkPort int           // Advertised MAVLink UDP port
+}
+
+// Beacon is the JSON announcement ground tools use to enumerate drones
+type Beacon struct {
+	UUID        string `json:"uuid"`
+	Name        string `json:"name"`
+	WebPort     int    `json:"webPort"`
+	MAVLinkPort int    `json:"mavlinkPort"`
+	Version     string `json:"version"`
+}
+
+// Announcer periodically broadcasts a Beacon on the local network
+type Announcer struct {
+	mu       sync.Mutex
+	cfg      Config
+	uuid     string
+	name     func() string
+	enabled  bool
+	running  bool
+	sent     int
+	lastSent time.Time
+	lastErr  string
+}
+
+// Global is the process-wide announcer
+var Global = &Announcer{}
+
+// Configure sets the beacon contents and schedule. name is called per beacon
+// so renames through the identity API are picked up.
+func (a *Announcer) Configure(cfg Config, uuid string, name func() string) {
+	if cfg.BroadcastIP == "" {
+		cfg.BroadcastIP = "255.255.255.255"
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cfg = cfg
+	a.uuid = uuid
+	a.name = name
+	a.enabled = cfg.Enabled
+}
+
+// SetEnabled starts or pauses the beacon without restarting the bridge
+func (a *Announcer) SetEnabled(enabled bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.enabled == enabled {
+		return
+	}
+	a.enabled = enabled
+	if enabled {
+		logger.Info("[DISCOVERY] Beacon enabled")
+	} else {
+		logger.Info("[DISCOVERY] Beacon disabled")
+	}
+}
+
+// Run sends beacons while enabled until stopCh is closed
+func (a *Announcer) Run(stopCh <-chan struct{}) error {
+	a.mu.Lock()
+	cfg := a.cfg
+	a.mu.Unlock()
+
+	dest := &net.UDPAddr{IP: net.ParseIP(cfg.BroadcastIP), Port: cfg.Port}
+	if dest.IP == nil {
+		return fmt.Errorf("invalid discovery broadcast address %q", cfg.BroadcastIP)
+	}
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return fmt.Errorf("failed to open discovery socket: %w", err)
+	}
+	defer conn.Close()
+
+	a.mu.Lock()
+	a.running = true
+	a.mu.Unlock()
+	logger.Info("[DISCOVERY] Beacon to %s every %v", dest, cfg.Interval)
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		a.announce(conn, dest)
+		select {
+		case <-stopCh:
+			a.mu.Lock()
+			a.running = false
+			a.mu.Unlock()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// announce sends one beacon if enabled
+func (a *Announcer) announce(conn *net.UDPConn, dest *net.UDPAddr) {
+	a.mu.Lock()
+	if !a.enabled {
+		a.mu.Unlock()
+		return
+	}
+	b := Beacon{
+		UUID:        a.uuid,
+		WebPort:     a.cfg.WebPort,
+		MAVLinkPort: a.cfg.MAVLinkPort,
+		Version:     Version(),
+	}
+	nameFn := a.name
+	a.mu.Unlock()
+
+	if nameFn != nil {
+		b.Name = nameFn()
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return
+	}
+	_, err = conn.WriteToUDP(data, dest)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err != nil {
+		if a.lastErr != err.Error() {
+			logger.Warn("[DISCOVERY] Beacon failed: %v", err)
+		}
+		a.lastErr = err.Error()
+		return
+	}
+	a.lastErr = ""
+	a.sent++
+	a.lastSent = time.Now()
+}
+
+// Snapshot returns the beacon state for /api/discovery
+func (a *Announcer) Snapshot() map[string]interface{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	snap := map[string]interface{}{
+		"enabled":     a.enabled,
+		"running":     a.running,
+		"port":        a.cfg.Port,
+		"broadcastIp": a.cfg.BroadcastIP,
+		"intervalMs":  a.cfg.Interval.Milliseconds(),
+		"sent":        a.sent,
+	}
+	if !a.lastSent.IsZero() {
+		snap["lastSent"] = a.lastSent
+	}
+	if a.lastErr != "" {
+		snap["error"] = a.lastErr
+	}
+	return snap
+}
+
+// Version identifies the running build: the module version, or the VCS
+// revision for binaries built from a checkout
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" && len(s.Value) >= 12 {
+			return s.Value[:12]
+		}
+	}
+	return "(devel)"
+}
@@ -0,0 +1,186 @@
+package dronecan
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/logger"
+)
+
+const (
+	// offlineAfter marks a node offline when its UAVCAN_NODE_STATUS stops (sent at 1 Hz)
+	offlineAfter = 5 * time.Second
+	// infoRetryInterval limits MAV_CMD_UAVCAN_GET_NODE_INFO requests for nodes without info
+	infoRetryInterval = 30 * time.Second
+)
+
+// Node is one CAN node reported by the flight controller
+type Node struct {
+	SystemID     uint8      `json:"systemId"`
+	NodeID       uint8      `json:"nodeId"` // Component ID of the forwarded messages
+	Health       string     `json:"health"` // ok, warning, error, critical
+	Mode         string     `json:"mode"`   // operational, initialization, maintenance, software_update, offline
+	Online       bool       `json:"online"`
+	UptimeSec    uint32     `json:"uptimeSec"`
+	VendorStatus uint16     `json:"vendorStatus"`
+	Restarts     int        `json:"restarts"` // Uptime went backwards while monitored
+	LastStatus   time.Time  `json:"lastStatus"`
+	Name         string     `json:"name,omitempty"`
+	HwVersion    string     `json:"hwVersion,omitempty"`
+	SwVersion    string     `json:"swVersion,omitempty"`
+	SwVcsCommit  string     `json:"swVcsCommit,omitempty"`
+	HwUniqueID   string     `json:"hwUniqueId,omitempty"`
+	LastInfo     *time.Time `json:"lastInfo,omitempty"`
+}
+
+type nodeKey struct {
+	sysID  uint8
+	nodeID uint8
+}
+
+// Registry collects UAVCAN_NODE_STATUS and UAVCAN_NODE_INFO forwarded by the FC
+type Registry struct {
+	mu          sync.Mutex
+	nodes       map[nodeKey]*Node
+	requestInfo func() error
+	lastInfoReq time.Time
+}
+
+// Global is the process-wide CAN node registry
+var Global = New()
+
+// New creates an empty registry
+func New() *Registry {
+	return &Registry{nodes: make(map[nodeKey]*Node)}
+}
+
+// SetInfoRequester sets how node info is requested from the FC (MAV_CMD_UAVCAN_GET_NODE_INFO)
+func (r *Registry) SetInfoRequester(fn func() error) {
+	r.mu.Lock()
+	r.requestInfo = fn
+	r.mu.Unlock()
+}
+
+// label names a node for logs and alerts
+func (n *Node) label() string {
+	if n.Name != "" {
+		return fmt.Sprintf("CAN node %d (%s)", n.NodeID, n.Name)
+	}
+	return fmt.Sprintf("CAN node %d", n.NodeID)
+}
+
+// UpdateStatus records a UAVCAN_NODE_STATUS
+func (r *Registry) UpdateStatus(sysID, compID uint8, m *common.MessageUavcanNodeStatus) {
+	key := nodeKey{sysID, compID}
+	health := strings.ToLower(strings.TrimPrefix(m.Health.String(), "UAVCAN_NODE_HEALTH_"))
+	mode := strings.ToLower(strings.TrimPrefix(m.Mode.String(), "UAVCAN_NODE_MODE_"))
+
+	r.mu.Lock()
+	n, ok := r.nodes[key]
+	if !ok {
+		n = &Node{SystemID: sysID, NodeID: compID}
+		r.nodes[key] = n
+		logger.Info("[DRONECAN] Discovered node %d (health %s, mode %s)", compID, health, mode)
+	} else if m.UptimeSec < n.UptimeSec {
+		n.Restarts++
+		logger.Warn("[DRONECAN] %s restarted (uptime %ds -> %ds)", n.label(), n.UptimeSec, m.UptimeSec)
+	}
+	prevHealth := n.Health
+	n.Health = health
+	n.Mode = mode
+	n.UptimeSec = m.UptimeSec
+	n.VendorStatus = m.VendorSpecificStatusCode
+	n.LastStatus = time.Now()
+	label := n.label()
+
+	var request func() error
+	if n.LastInfo == nil && r.requestInfo != nil && time.Since(r.lastInfoReq) > infoRetryInterval {
+		r.lastInfoReq = time.Now()
+		request = r.requestInfo
+	}
+	r.mu.Unlock()
+
+	// Alert on health transitions, and on nodes that appear already unhealthy
+	if (ok && health != prevHealth) || (!ok && health != "ok") {
+		switch health {
+		case "error", "critical":
+			alerts.Raise("dronecan", alerts.SeverityCritical, fmt.Sprintf("%s health %s", label, health))
+		case "warning":
+			alerts.Raise("dronecan", alerts.SeverityWarning, fmt.Sprintf("%s health warning", label))
+		default:
+			if ok {
+				alerts.Raise("dronecan", alerts.SeverityInfo, fmt.Sprintf("%s health %s", label, health))
+			}
+		}
+	}
+	if request != nil {
+		go func() {
+			if err := request(); err != nil {
+				logger.Debug("[DRONECAN] Node info request failed: %v", err)
+			}
+		}()
+	}
+}
+
+// UpdateInfo records a UAVCAN_NODE_INFO (name and versions)
+func (r *Registry) UpdateInfo(sysID, compID uint8, m *common.MessageUavcanNodeInfo) {
+	key := nodeKey{sysID, compID}
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.nodes[key]
+	if !ok {
+		n = &Node{SystemID: sysID, NodeID: compID, LastStatus: now}
+		r.nodes[key] = n
+	}
+	n.Name = strings.TrimRight(m.Name, "\x00")
+	n.HwVersion = fmt.Sprintf("%d.%d", m.HwVersionMajor, m.HwVersionMinor)
+	n.SwVersion = fmt.Sprintf("%d.%d", m.SwVersionMajor, m.SwVersionMinor)
+	n.SwVcsCommit = ""
+	if m.SwVcsCommit != 0 {
+		n.SwVcsCommit = fmt.Sprintf("%08x", m.SwVcsCommit)
+	}
+	n.HwUniqueID = hex.EncodeToString(m.HwUniqueId[:])
+	n.LastInfo = &now
+	logger.Info("[DRONECAN] Node %d is %s (sw %s, hw %s)", compID, n.Name, n.SwVersion, n.HwVersion)
+}
+
+// Snapshot returns all known nodes sorted by node ID
+func (r *Registry) Snapshot() map[string]interface{} {
+	r.mu.Lock()
+	nodes := make([]Node, 0, len(r.nodes))
+	online, unhealthy := 0, 0
+	for _, n := range r.nodes {
+		node := *n
+		node.Online = time.Since(n.LastStatus) <= offlineAfter
+		if node.Online {
+			online++
+			if node.Health != "ok" {
+				unhealthy++
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].SystemID != nodes[j].SystemID {
+			return nodes[i].SystemID < nodes[j].SystemID
+		}
+		return nodes[i].NodeID < nodes[j].NodeID
+	})
+	return map[string]interface{}{
+		"nodes":     nodes,
+		"count":     len(nodes),
+		"online":    online,
+		"unhealthy": unhealthy,
+	}
+}
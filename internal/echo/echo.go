@@ -0,0 +1,141 @@
+package echo
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialect"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/mavlink_custom"
+)
+
+const (
+	ttl        = 2 * time.Second // An echo arrives within milliseconds; anything later is real traffic
+	maxEntries = 1024            // Bound on outstanding writes (a broadcast storm must not grow the map)
+)
+
+type key struct {
+	msgID uint32
+	sum   uint64 // FNV-1a of the encoded payload
+}
+
+type entry struct {
+	pending int
+	expires time.Time
+}
+
+// Registry remembers messages the bridge wrote to the FC link so their echoes
+// (the listener's UDP broadcast is received by its own server endpoint) are not
+// mistaken for FC or local GCS traffic and forwarded again. Messages are matched
+// by ID and payload, since the node assigns the header when writing.
+type Registry struct {
+	mu      sync.Mutex
+	rw      *dialect.ReadWriter
+	entries map[key]*entry
+	ids     map[uint32]int // Entries per message ID, to skip encoding unrelated frames
+}
+
+// Global is the process-wide registry of bridge-originated writes
+var Global = New()
+
+// New creates an empty registry
+func New() *Registry {
+	rw, _ := dialect.NewReadWriter(mavlink_custom.GetCombinedDialect())
+	return &Registry{rw: rw, entries: make(map[key]*entry), ids: make(map[uint32]int)}
+}
+
+// Record notes a message about to be written to the FC link
+func (r *Registry) Record(msg message.Message) {
+	k, ok := r.keyOf(msg)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) >= maxEntries {
+		r.pruneLocked(now)
+		if len(r.entries) >= maxEntries {
+			return
+		}
+	}
+	e := r.entries[k]
+	if e == nil {
+		e = &entry{}
+		r.entries[k] = e
+		r.ids[k.msgID]++
+	} else if now.After(e.expires) {
+		e.pending = 0
+	}
+	e.pending++
+	e.expires = now.Add(ttl)
+}
+
+// IsEcho reports whether a received frame is the echo of a recorded write,
+// consuming the record so the same message sent again by a GCS passes
+func (r *Registry) IsEcho(fr frame.Frame) bool {
+	r.mu.Lock()
+	recorded := r.ids[fr.GetMessage().GetID()] > 0
+	r.mu.Unlock()
+	if !recorded {
+		return false // Cheap path: no recent write of this message type
+	}
+
+	k, ok := r.keyOf(fr.GetMessage())
+	if !ok {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := r.entries[k]
+	if e == nil {
+		return false
+	}
+	if time.Now().After(e.expires) {
+		r.deleteLocked(k)
+		return false
+	}
+	e.pending--
+	if e.pending <= 0 {
+		r.deleteLocked(k)
+	}
+	return true
+}
+
+// keyOf encodes the message as a v2 payload (trailing zeros truncated, like on the wire)
+func (r *Registry) keyOf(msg message.Message) (key, bool) {
+	var payload []byte
+	if raw, ok := msg.(*message.MessageRaw); ok {
+		payload = raw.Payload
+	} else {
+		if r.rw == nil {
+			return key{}, false
+		}
+		mp := r.rw.GetMessage(msg.GetID())
+		if mp == nil {
+			return key{}, false
+		}
+		payload = mp.Write(msg, true).Payload
+	}
+	h := fnv.New64a()
+	h.Write(payload)
+	return key{msgID: msg.GetID(), sum: h.Sum64()}, true
+}
+
+func (r *Registry) pruneLocked(now time.Time) {
+	for k, e := range r.entries {
+		if now.After(e.expires) {
+			r.deleteLocked(k)
+		}
+	}
+}
+
+func (r *Registry) deleteLocked(k key) {
+	delete(r.entries, k)
+	if r.ids[k.msgID]--; r.ids[k.msgID] <= 0 {
+		delete(r.ids, k.msgID)
+	}
+}
@@ -0,0 +1,223 @@
+// Package entitlements gates premium subsystems on the feature list the fleet
+// server signs for this drone (ENTITLEMENTS), so one binary serves every tier.
+// The last verified list is cached on disk and keeps working offline until it
+// expires.
+package entitlements
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/auth"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// Premium features
+const (
+	FeatureVideo            = "video"             // Video streaming
+	FeaturePrecisionLanding = "precision_landing" // Landing-pad detection in the video pipeline
+	FeatureRelay            = "relay"             // MAVLink relay to browser GCS clients (/ws/mavlink)
+)
+
+// Premium lists the features that need an entitlement
+var Premium = []string{FeatureVideo, FeaturePrecisionLanding, FeatureRelay}
+
+// Status is the entitlement state for GET /api/entitlements
+type Status struct {
+	Serial    uint64          `json:"serial,omitempty"`
+	IssuedAt  *time.Time      `json:"issuedAt,omitempty"`
+	ExpiresAt *time.Time      `json:"expiresAt,omitempty"`
+	Expired   bool            `json:"expired"`
+	Features  []string        `json:"features"` // Signed list, including features this build does not gate
+	Allowed   map[string]bool `json:"allowed"`  // Premium feature -> usable now
+}
+
+// Store holds the current list and tells an observer when a feature is granted or revoked
+type Store struct {
+	mu       sync.RWMutex
+	path     string
+	current  *auth.Entitlements
+	allowed  map[string]bool // Last state reported to the observer
+	observer func(feature string, allowed bool)
+}
+
+// Global is the process-wide entitlement store. Premium features stay locked
+// until a verified list grants them.
+var Global = &Store{}
+
+// Configure loads the cached list from path, keeping it only if verify accepts
+// its signature. A missing file is not an error.
+func (s *Store) Configure(path string, verify func(*auth.Entitlements) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path = path
+	s.allowed = s.allowedLocked(time.Now())
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read entitlements: %w", err)
+	}
+	var ent auth.Entitlements
+	if err := json.Unmarshal(data, &ent); err != nil {
+		return fmt.Errorf("failed to parse entitlements: %w", err)
+	}
+	if err := verify(&ent); err != nil {
+		return fmt.Errorf("cached entitlements rejected: %w", err)
+	}
+	// Only the signed list counts, not the features stored next to it
+	ent.Features = nil
+	for _, f := range strings.Split(string(ent.List), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			ent.Features = append(ent.Features, f)
+		}
+	}
+	s.current = &ent
+	s.allowed = s.allowedLocked(time.Now())
+	return nil
+}
+
+// SetObserver sets a function called when a premium feature becomes usable or locked
+func (s *Store) SetObserver(fn func(feature string, allowed bool)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observer = fn
+}
+
+// Allowed reports whether a premium feature may be used now
+func (s *Store) Allowed(feature string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.grantsLocked(feature, time.Now())
+}
+
+// Apply installs a verified list from the router and caches it. Lists older
+// than the current one are rejected as replays.
+func (s *Store) Apply(ent *auth.Entitlements) error {
+	s.mu.Lock()
+	if s.current != nil && ent.Serial < s.current.Serial {
+		s.mu.Unlock()
+		return fmt.Errorf("serial %d is older than %d", ent.Serial, s.current.Serial)
+	}
+	renewed := s.current == nil || ent.Serial != s.current.Serial
+	s.current = ent
+	path := s.path
+	s.mu.Unlock()
+
+	if renewed {
+		logger.Info("[ENTITLEMENTS] List %d: %v, expires %s", ent.Serial, ent.Features,
+			time.Unix(int64(ent.ExpiresAt), 0).Format("2006-01-02 15:04"))
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Entitlements %d received", ent.Serial))
+	}
+	if path != "" {
+		if err := save(path, ent); err != nil {
+			logger.Warn("[ENTITLEMENTS] Failed to cache list: %v", err)
+		}
+	}
+	s.notify()
+	return nil
+}
+
+// Run re-checks expiry once a minute until stop is closed
+func (s *Store) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.notify()
+		}
+	}
+}
+
+// Status returns the current list and which premium features are usable
+func (s *Store) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+	st := Status{Features: []string{}, Allowed: make(map[string]bool)}
+	for _, f := range Premium {
+		st.Allowed[f] = s.grantsLocked(f, now)
+	}
+	if ent := s.current; ent != nil {
+		issued := time.Unix(int64(ent.IssuedAt), 0)
+		expires := time.Unix(int64(ent.ExpiresAt), 0)
+		st.Serial = ent.Serial
+		st.IssuedAt, st.ExpiresAt = &issued, &expires
+		st.Expired = !now.Before(expires)
+		st.Features = append(st.Features, ent.Features...)
+		sort.Strings(st.Features)
+	}
+	return st
+}
+
+// notify reports premium features whose state changed since the last call
+func (s *Store) notify() {
+	s.mu.Lock()
+	now := s.allowedLocked(time.Now())
+	var changed []string
+	for _, f := range Premium {
+		if now[f] != s.allowed[f] {
+			changed = append(changed, f)
+		}
+	}
+	s.allowed = now
+	observer := s.observer
+	s.mu.Unlock()
+
+	for _, f := range changed {
+		if now[f] {
+			logger.Info("[ENTITLEMENTS] ✅ %s enabled", f)
+		} else {
+			logger.Warn("[ENTITLEMENTS] %s locked (not entitled or list expired)", f)
+			metrics.Global.AddLog("WARN", "Feature locked: "+f)
+		}
+		if observer != nil {
+			observer(f, now[f])
+		}
+	}
+}
+
+func (s *Store) allowedLocked(now time.Time) map[string]bool {
+	allowed := make(map[string]bool, len(Premium))
+	for _, f := range Premium {
+		allowed[f] = s.grantsLocked(f, now)
+	}
+	return allowed
+}
+
+// grantsLocked reports whether the current list grants feature at now (caller holds s.mu)
+func (s *Store) grantsLocked(feature string, now time.Time) bool {
+	if s.current == nil || !now.Before(time.Unix(int64(s.current.ExpiresAt), 0)) {
+		return false
+	}
+	for _, f := range s.current.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// save writes the list atomically
+func save(path string, ent *auth.Entitlements) error {
+	data, err := json.MarshalIndent(ent, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,87 @@
+package entitlements
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"DroneBridge/internal/auth"
+)
+
+func list(serial uint64, expires time.Time, features ...string) *auth.Entitlements {
+	return &auth.Entitlements{Serial: serial, IssuedAt: uint64(time.Now().Unix()), ExpiresAt: uint64(expires.Unix()), Features: features}
+}
+
+func TestAllowed(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name    string
+		ent     *auth.Entitlements
+		feature string
+		want    bool
+	}{
+		{"no list", nil, FeatureVideo, false},
+		{"granted", list(1, future, FeatureVideo), FeatureVideo, true},
+		{"not in the list", list(1, future, FeatureVideo), FeatureRelay, false},
+		{"expired", list(1, past, FeatureVideo), FeatureVideo, false},
+	}
+	for _, tt := range tests {
+		s := &Store{}
+		if tt.ent != nil {
+			if err := s.Apply(tt.ent); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := s.Allowed(tt.feature); got != tt.want {
+			t.Errorf("%s: Allowed(%s) = %v, want %v", tt.name, tt.feature, got, tt.want)
+		}
+	}
+}
+
+func TestApplyRejectsOlderSerial(t *testing.T) {
+	s := &Store{}
+	future := time.Now().Add(time.Hour)
+	if err := s.Apply(list(5, future, FeatureVideo)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Apply(list(4, future, FeatureVideo, FeatureRelay)); err == nil {
+		t.Fatal("Apply() accepted an older serial")
+	}
+	if s.Allowed(FeatureRelay) {
+		t.Error("replayed list granted relay")
+	}
+}
+
+func TestConfigureVerifiesCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "entitlements.json")
+	writer := &Store{path: path}
+	if err := writer.Apply(list(2, time.Now().Add(time.Hour), FeatureVideo)); err != nil {
+		t.Fatal(err)
+	}
+	// The stored features are ignored: only the signed list counts
+	writer.current.List = []byte(FeatureVideo)
+
+	tests := []struct {
+		name   string
+		verify func(*auth.Entitlements) error
+		want   bool
+	}{
+		{"valid signature", func(*auth.Entitlements) error { return nil }, true},
+		{"invalid signature", func(*auth.Entitlements) error { return errors.New("invalid signature") }, false},
+	}
+	if err := save(path, writer.current); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		s := &Store{}
+		err := s.Configure(path, tt.verify)
+		if (err == nil) != tt.want {
+			t.Errorf("%s: Configure() = %v", tt.name, err)
+		}
+		if got := s.Allowed(FeatureVideo); got != tt.want {
+			t.Errorf("%s: Allowed(video) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
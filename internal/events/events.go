@@ -0,0 +1,66 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// maxEvents is how many events are kept for /api/events
+const maxEvents = 200
+
+// Event is a structured session/API key lifecycle event shown by the dashboard
+type Event struct {
+	Type     string                 `json:"type"` // Always "event", tells it apart from other WebSocket text messages
+	Time     time.Time              `json:"time"`
+	Kind     string                 `json:"kind"`     // e.g. "session_refresh_failed", "apikey_revoked"
+	Severity string                 `json:"severity"` // INFO, WARN, ERROR
+	Message  string                 `json:"message"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Store keeps the most recent events and hands new ones to an observer
+type Store struct {
+	mu       sync.RWMutex
+	recent   []Event
+	observer func(Event)
+}
+
+// Global is the process-wide event store
+var Global = &Store{recent: make([]Event, 0, maxEvents)}
+
+// SetObserver sets a function called with every new event (e.g. the WebSocket push)
+func (s *Store) SetObserver(fn func(Event)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observer = fn
+}
+
+// Record stores an event and passes it to the observer
+func (s *Store) Record(kind, severity, message string, fields map[string]interface{}) {
+	ev := Event{Type: "event", Time: time.Now(), Kind: kind, Severity: severity, Message: message, Fields: fields}
+
+	s.mu.Lock()
+	if len(s.recent) >= maxEvents {
+		s.recent = s.recent[1:]
+	}
+	s.recent = append(s.recent, ev)
+	observer := s.observer
+	s.mu.Unlock()
+
+	if observer != nil {
+		observer(ev)
+	}
+}
+
+// Recent returns up to limit of the most recent events, oldest first (limit <= 0 = all)
+func (s *Store) Recent(limit int) []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	src := s.recent
+	if limit > 0 && len(src) > limit {
+		src = src[len(src)-limit:]
+	}
+	out := make([]Event, len(src))
+	copy(out, src)
+	return out
+}
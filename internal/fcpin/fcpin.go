@@ -0,0 +1,267 @@
+package fcpin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/logger"
+)
+
+const (
+	versionRetry    = 10 * time.Second // AUTOPILOT_VERSION request interval until the UID is known
+	versionRecheck  = time.Minute      // ...and afterwards, to spot another FC with the same system ID
+	foreignLogEvery = 30 * time.Second // Rate limit of the "refusing traffic" warning per source
+)
+
+// Pin is the flight controller this drone's UUID is bound to
+type Pin struct {
+	SysID    uint8     `json:"sysId"`
+	UID      string    `json:"uid,omitempty"` // AUTOPILOT_VERSION uid (or uid2) in hex; empty until the FC reports it
+	PinnedAt time.Time `json:"pinnedAt"`
+}
+
+// Foreign is another FC seen on the network whose traffic is refused
+type Foreign struct {
+	Source   string    `json:"source"` // MAVLink channel it arrived on
+	SysID    uint8     `json:"sysId"`
+	UID      string    `json:"uid,omitempty"`
+	Frames   uint64    `json:"frames"`
+	LastSeen time.Time `json:"lastSeen"`
+
+	lastLog time.Time
+}
+
+// Status is the pinning state shown by the API
+type Status struct {
+	Enabled  bool       `json:"enabled"`
+	Pin      *Pin       `json:"pin"` // null until the first FC heartbeat
+	Refused  uint64     `json:"refused"`
+	Foreign  []Foreign  `json:"foreign"`
+	Repaired *time.Time `json:"repaired,omitempty"` // Last re-pairing via the API
+}
+
+// Guard pins the bridge to one FC by system ID and autopilot UID. Traffic of
+// any other FC on the same network is refused, so it is never forwarded under
+// this drone's UUID, until the drone is explicitly re-paired.
+type Guard struct {
+	mu          sync.Mutex
+	enabled     bool
+	path        string
+	pin         *Pin
+	foreign     map[string]*Foreign // By source channel
+	refused     uint64
+	lastRequest time.Time
+	repaired    time.Time
+}
+
+// Global is the process-wide FC pin
+var Global = &Guard{foreign: make(map[string]*Foreign)}
+
+// Configure enables pinning and loads the pin saved at path, if any
+func (g *Guard) Configure(enabled bool, path string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.enabled = enabled
+	g.path = path
+	if !enabled {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read FC pin: %w", err)
+	}
+	var pin Pin
+	if err := json.Unmarshal(data, &pin); err != nil {
+		return fmt.Errorf("failed to parse FC pin: %w", err)
+	}
+	g.pin = &pin
+	logger.Info("[FCPIN] Pinned to FC System ID %d (UID %s)", pin.SysID, orUnknown(pin.UID))
+	return nil
+}
+
+// Matches reports whether a heartbeat of sysID may come from the pinned FC.
+// Discovery uses it to skip other vehicles on a shared bench network.
+func (g *Guard) Matches(sysID uint8) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return !g.enabled || g.pin == nil || g.pin.SysID == sysID
+}
+
+// Pinned reports whether pinning is on and the pin is sysID
+func (g *Guard) Pinned(sysID uint8) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.enabled && g.pin != nil && g.pin.SysID == sysID
+}
+
+// Allow reports whether a frame from the FC side may be used and forwarded.
+// The first FC heard after (re-)pairing is pinned by its system ID.
+func (g *Guard) Allow(sysID uint8, source string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.enabled {
+		return true
+	}
+	if g.pin == nil {
+		g.pin = &Pin{SysID: sysID, PinnedAt: time.Now()}
+		g.lastRequest = time.Time{}
+		logger.Info("[FCPIN] Pinned to FC System ID %d (%s)", sysID, source)
+		g.saveLocked()
+		return true
+	}
+
+	f, flagged := g.foreign[source]
+	if !flagged && sysID == g.pin.SysID {
+		return true
+	}
+	if !flagged {
+		f = &Foreign{Source: source, SysID: sysID}
+		g.foreign[source] = f
+	}
+	f.Frames++
+	f.LastSeen = time.Now()
+	g.refused++
+	if time.Since(f.lastLog) >= foreignLogEvery {
+		f.lastLog = f.LastSeen
+		logger.Warn("[FCPIN] ⚠️ Refusing traffic of FC System ID %d (UID %s) from %s - this drone is pinned to System ID %d (re-pair via POST /api/fc/pin)",
+			f.SysID, orUnknown(f.UID), source, g.pin.SysID)
+	}
+	return false
+}
+
+// ObserveVersion checks an AUTOPILOT_VERSION against the pin: the pinned FC's
+// first report pins its UID, a different UID with the pinned system ID marks
+// its source as another FC, and the pinned UID clears such a mark.
+func (g *Guard) ObserveVersion(sysID uint8, source string, uid uint64, uid2 [18]uint8) {
+	id := uidString(uid, uid2)
+	if id == "" {
+		return // FC does not report a UID
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.enabled || g.pin == nil || sysID != g.pin.SysID {
+		return
+	}
+	switch {
+	case g.pin.UID == "":
+		if _, flagged := g.foreign[source]; flagged {
+			return
+		}
+		g.pin.UID = id
+		logger.Info("[FCPIN] Pinned FC System ID %d to autopilot UID %s", sysID, id)
+		g.saveLocked()
+	case g.pin.UID == id:
+		delete(g.foreign, source)
+	default:
+		if f, flagged := g.foreign[source]; flagged {
+			f.UID = id
+			return
+		}
+		g.foreign[source] = &Foreign{Source: source, SysID: sysID, UID: id, LastSeen: time.Now()}
+		logger.Warn("[FCPIN] ⚠️ Another FC (UID %s) uses the pinned System ID %d on %s - refusing its traffic", id, sysID, source)
+	}
+}
+
+// VersionRequestDue reports whether AUTOPILOT_VERSION should be requested now
+func (g *Guard) VersionRequestDue(now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.enabled || g.pin == nil {
+		return false
+	}
+	interval := versionRecheck
+	if g.pin.UID == "" {
+		interval = versionRetry
+	}
+	if now.Sub(g.lastRequest) < interval {
+		return false
+	}
+	g.lastRequest = now
+	return true
+}
+
+// Repair forgets the pin and the refused FCs; the next FC heard is pinned
+func (g *Guard) Repair() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.enabled {
+		return fmt.Errorf("FC pinning is disabled (ethernet.pin_fc)")
+	}
+	g.pin = nil
+	g.foreign = make(map[string]*Foreign)
+	g.refused = 0
+	g.repaired = time.Now()
+	if g.path != "" {
+		if err := os.Remove(g.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove FC pin: %w", err)
+		}
+	}
+	logger.Info("[FCPIN] Pin cleared - the next FC heard will be pinned")
+	return nil
+}
+
+// Status returns the pin and the refused FCs
+func (g *Guard) Status() Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	st := Status{Enabled: g.enabled, Refused: g.refused, Foreign: []Foreign{}}
+	if !g.repaired.IsZero() {
+		repaired := g.repaired
+		st.Repaired = &repaired
+	}
+	if g.pin != nil {
+		pin := *g.pin
+		st.Pin = &pin
+	}
+	for _, f := range g.foreign {
+		st.Foreign = append(st.Foreign, *f)
+	}
+	return st
+}
+
+// saveLocked writes the pin atomically (caller holds lock)
+func (g *Guard) saveLocked() {
+	if g.path == "" || g.pin == nil {
+		return
+	}
+	data, err := json.MarshalIndent(g.pin, "", "  ")
+	if err != nil {
+		return
+	}
+	tmp := g.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		logger.Warn("[FCPIN] Failed to save FC pin: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, g.path); err != nil {
+		logger.Warn("[FCPIN] Failed to save FC pin: %v", err)
+	}
+}
+
+// uidString formats the autopilot UID: uid when set, else uid2 ("" if neither)
+func uidString(uid uint64, uid2 [18]uint8) string {
+	if uid != 0 {
+		return fmt.Sprintf("%016x", uid)
+	}
+	for _, b := range uid2 {
+		if b != 0 {
+			return fmt.Sprintf("%x", uid2[:])
+		}
+	}
+	return ""
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,218 @@
+package forwarder
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3"
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/config"
+	"DroneBridge/internal/fcpin"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+)
+
+// discoveryProbe is one network DiscoverPixhawk looks for the FC on
+type discoveryProbe struct {
+	name      string
+	endpoints []gomavlib.EndpointConf
+	announce  time.Duration // GCS heartbeat period (0 = gomavlib default)
+}
+
+// discoveryResult is the outcome of a probe: the FC found, or why not
+type discoveryResult struct {
+	probe string
+	ip    string
+	port  int
+	sysID uint8
+	err   error
+}
+
+// discoveryProbes builds the probes: the ethernet broadcast network (with the
+// UDP server FCs stream to) and one per ethernet.discovery_candidates entry
+func discoveryProbes(cfg *config.Config) ([]discoveryProbe, error) {
+	server := gomavlib.EndpointUDPServer{Address: fmt.Sprintf("0.0.0.0:%d", cfg.Network.LocalListenPort)}
+
+	var probes []discoveryProbe
+	localEthIP, broadcastEthIP, ifaceName, ethErr := getEthernetIP(cfg)
+	switch {
+	case ethErr == nil && localEthIP != "" && broadcastEthIP != "":
+		probes = append(probes, discoveryProbe{
+			name: "ethernet " + ifaceName,
+			endpoints: []gomavlib.EndpointConf{
+				server,
+				gomavlib.EndpointUDPBroadcast{
+					BroadcastAddress: fmt.Sprintf("%s:%d", broadcastEthIP, cfg.Network.LocalListenPort),
+					LocalAddress:     fmt.Sprintf("%s:%d", localEthIP, cfg.Network.BroadcastPort),
+				},
+			},
+		})
+		logger.Info("[DISCOVERY] UDP Broadcast enabled on %s: Local=%s:%d, Broadcast=%s:%d",
+			ifaceName, localEthIP, cfg.Network.BroadcastPort, broadcastEthIP, cfg.Network.LocalListenPort)
+	case len(cfg.Ethernet.DiscoveryCandidates) == 0:
+		return nil, fmt.Errorf("network discovery unavailable: %v", ethErr)
+	default:
+		// Candidates are still probed; FCs streaming to our port are heard by the server
+		logger.Warn("[DISCOVERY] Ethernet broadcast unavailable (%v), probing candidates only", ethErr)
+		probes = append(probes, discoveryProbe{name: "udp server", endpoints: []gomavlib.EndpointConf{server}})
+	}
+
+	usedLocal := map[string]bool{localEthIP: true}
+	for i, cand := range cfg.Ethernet.DiscoveryCandidates {
+		if cand.Host != "" {
+			addr := net.JoinHostPort(cand.Host, strconv.Itoa(cand.Port))
+			probes = append(probes, discoveryProbe{
+				name:      "candidate " + addr,
+				endpoints: []gomavlib.EndpointConf{gomavlib.EndpointUDPClient{Address: addr}},
+				announce:  time.Second, // Routers only forward to clients they have heard from
+			})
+			continue
+		}
+		localIP := localIPForBroadcast(cand.BroadcastIP)
+		if localIP == "" {
+			logger.Warn("[DISCOVERY] Skipping discovery_candidates[%d]: no local interface on the %s subnet", i, cand.BroadcastIP)
+			continue
+		}
+		if usedLocal[localIP] {
+			logger.Warn("[DISCOVERY] Skipping discovery_candidates[%d]: %s is already probed", i, localIP)
+			continue
+		}
+		usedLocal[localIP] = true
+		probes = append(probes, discoveryProbe{
+			name: fmt.Sprintf("candidate %s:%d", cand.BroadcastIP, cand.Port),
+			endpoints: []gomavlib.EndpointConf{
+				gomavlib.EndpointUDPBroadcast{
+					BroadcastAddress: fmt.Sprintf("%s:%d", cand.BroadcastIP, cand.Port),
+					LocalAddress:     fmt.Sprintf("%s:%d", localIP, cfg.Network.BroadcastPort),
+				},
+			},
+			announce: time.Second,
+		})
+	}
+	return probes, nil
+}
+
+// localIPForBroadcast returns the IPv4 address of the interface whose subnet
+// has the given broadcast address, or "" if there is none
+func localIPForBroadcast(broadcastIP string) string {
+	target := net.ParseIP(broadcastIP).To4()
+	ifaces, err := net.Interfaces()
+	if target == nil || err != nil {
+		return ""
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() == nil || len(ipNet.Mask) != net.IPv4len {
+				continue
+			}
+			ip := ipNet.IP.To4()
+			broadcast := make(net.IP, net.IPv4len)
+			for i := range ip {
+				broadcast[i] = ip[i] | ^ipNet.Mask[i]
+			}
+			if broadcast.Equal(target) {
+				return ip.String()
+			}
+		}
+	}
+	return ""
+}
+
+// run waits on the probe's node for the first FC heartbeat, until stop is closed
+func (p discoveryProbe) run(serverIP string, stop <-chan struct{}) discoveryResult {
+	res := discoveryResult{probe: p.name}
+	node, err := gomavlib.NewNode(gomavlib.NodeConf{
+		Endpoints:       p.endpoints,
+		Dialect:         mavlink_custom.GetCombinedDialect(),
+		OutVersion:      gomavlib.V2,
+		OutSystemID:     255, // Identify as a GCS during discovery
+		HeartbeatPeriod: p.announce,
+	})
+	if err != nil {
+		res.err = fmt.Errorf("failed to create discovery node: %w", err)
+		return res
+	}
+	defer node.Close()
+
+	eventCh := node.Events()
+	for {
+		select {
+		case <-stop:
+			res.err = fmt.Errorf("stopped")
+			return res
+		case event := <-eventCh:
+			if frame, ok := event.(*gomavlib.EventFrame); ok {
+				if ip, port, ok := pixhawkHeartbeat(frame, serverIP); ok {
+					res.ip, res.port, res.sysID = ip, port, frame.SystemID()
+					return res
+				}
+			}
+		}
+	}
+}
+
+// pixhawkHeartbeat reports whether frame is a heartbeat of a flight controller
+// (not a GCS or the server) and returns the address it came from
+func pixhawkHeartbeat(frame *gomavlib.EventFrame, serverIP string) (string, int, bool) {
+	hb, ok := frame.Message().(*common.MessageHeartbeat)
+	if !ok {
+		return "", 0, false
+	}
+	sysID := frame.SystemID()
+
+	// 1. Skip GCS heartbeats (from server or other GCS)
+	if sysID == 255 || hb.Type == common.MAV_TYPE_GCS {
+		logger.Debug("[DISCOVERY] Skipping GCS heartbeat (SysID: %d, Type: %d)", sysID, hb.Type)
+		return "", 0, false
+	}
+
+	// 2. Skip invalid autopilots (strictly Pixhawk-like devices)
+	if hb.Autopilot == common.MAV_AUTOPILOT_INVALID {
+		logger.Debug("[DISCOVERY] Skipping invalid autopilot (SysID: %d)", sysID)
+		return "", 0, false
+	}
+
+	// In gomavlib v3, the Channel string usually contains the remote address
+	chanStr := frame.Channel.String()
+	remoteAddr := chanStr
+
+	// Extraction of remote IP from channel string
+	parts := strings.Split(chanStr, ":")
+	if len(parts) >= 3 && parts[0] == "udp" {
+		remoteAddr = strings.Join(parts[1:3], ":")
+		if idx := strings.Index(remoteAddr, " "); idx != -1 {
+			remoteAddr = remoteAddr[:idx]
+		}
+	}
+
+	// Extract IP from host:port
+	ip, portStr, _ := net.SplitHostPort(remoteAddr)
+	if ip == "" {
+		ip = remoteAddr
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	// 3. Skip Server IP (explicit loop prevention)
+	if serverIP != "" && ip == serverIP {
+		logger.Debug("[DISCOVERY] Skipping heartbeat from Server IP: %s", ip)
+		return "", 0, false
+	}
+
+	// 4. Skip other vehicles when pinned to an FC
+	if !fcpin.Global.Matches(sysID) {
+		logger.Debug("[DISCOVERY] Skipping FC System ID %d at %s: not the pinned FC", sysID, ip)
+		return "", 0, false
+	}
+
+	logger.Info("[DISCOVERY] Pixhawk heartbeat (System ID: %d, Autopilot: %d) from channel: %s", sysID, hb.Autopilot, chanStr)
+	return ip, port, true
+}
@@ -0,0 +1,178 @@
+package forwarder
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+const (
+	eventBuffer     = 512                   // Events relayed ahead of a consumer loop
+	eventStallAfter = 50 * time.Millisecond // Handling one event for longer counts as a stall
+	eventWarnEvery  = 10 * time.Second      // At most one backlog warning per stage in this period
+)
+
+// seqKey identifies a MAVLink sender for sequence tracking
+type seqKey struct {
+	sysID  uint8
+	compID uint8
+}
+
+// eventStage instruments one node's events between the relay that reads them
+// and the consumer loop that handles them. gomavlib blocks its reader while the
+// events channel is not drained, so a slow consumer makes the kernel drop
+// datagrams (or the serial buffer overflow) without any error. The relay
+// records when the buffer is full, the consumer records how long each event
+// took, and sequence gaps show the frames that were lost.
+type eventStage struct {
+	source   string // Metrics label: "fc" or "server"
+	consumer string // Consumer loop named in warnings
+
+	mu       sync.Mutex
+	events   uint64
+	waits    uint64        // Relay found the buffer full
+	waited   time.Duration // Total time the relay was blocked
+	stalls   uint64        // Events handled slower than eventStallAfter
+	lost     uint64        // Frames missing from the sequence numbers
+	maxDepth int
+	lastSeq  map[seqKey]uint8
+
+	current *stageEvent // Latest event a consumer started on (nil between events)
+
+	// Since the last warning
+	slowest     time.Duration
+	slowestMsg  string
+	pendingWait time.Duration
+	pendingLost uint64
+	lastWarn    time.Time
+}
+
+// stageEvent is one event being handled; the consumer loop keeps it between begin and end
+type stageEvent struct {
+	msg     message.Message
+	started time.Time
+}
+
+func newEventStage(source, consumer string) *eventStage {
+	return &eventStage{source: source, consumer: consumer, lastSeq: make(map[seqKey]uint8)}
+}
+
+// relay hands evt to the consumer through ch, timing the wait when the buffer
+// is full. Returns false when stop closed first.
+func (s *eventStage) relay(ch chan<- gomavlib.Event, evt gomavlib.Event, stop <-chan struct{}) bool {
+	select {
+	case ch <- evt:
+		return true
+	default:
+	}
+
+	start := time.Now()
+	select {
+	case ch <- evt:
+	case <-stop:
+		return false
+	}
+	s.blocked(time.Since(start), cap(ch))
+	return true
+}
+
+// blocked records a relay wait on a full buffer and warns with the consumer that held it up
+func (s *eventStage) blocked(d time.Duration, capacity int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waits++
+	s.waited += d
+	s.pendingWait += d
+	s.maxDepth = capacity // It was full
+
+	now := time.Now()
+	if now.Sub(s.lastWarn) < eventWarnEvery {
+		return
+	}
+	slowest := "no single slow event"
+	if s.current != nil && time.Since(s.current.started) > s.slowest {
+		slowest = fmt.Sprintf("still handling %s after %v", getMessageTypeName(s.current.msg), time.Since(s.current.started).Round(time.Millisecond))
+	} else if s.slowestMsg != "" {
+		slowest = fmt.Sprintf("slowest %s took %v", s.slowestMsg, s.slowest.Round(time.Millisecond))
+	}
+	msg := fmt.Sprintf("%s events backed up (buffer of %d full for %v): %s is too slow, %s; %d frames lost",
+		s.source, capacity, s.pendingWait.Round(time.Millisecond), s.consumer, slowest, s.pendingLost)
+	logger.Warn("[EVENTS] %s", msg)
+	metrics.Global.AddLog("WARN", msg)
+	s.lastWarn = now
+	s.slowest, s.slowestMsg = 0, ""
+	s.pendingWait, s.pendingLost = 0, 0
+}
+
+// begin marks the consumer starting on an event and checks its sequence number.
+// The returned handle (nil for non-frame events) is passed back to end.
+func (s *eventStage) begin(evt gomavlib.Event) *stageEvent {
+	e, ok := evt.(*gomavlib.EventFrame)
+	if !ok {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events++
+	s.current = &stageEvent{msg: e.Message(), started: time.Now()}
+
+	// Gaps of more than half the range are reordering or a sender restart, not loss
+	key := seqKey{sysID: e.SystemID(), compID: e.ComponentID()}
+	seq := e.Frame.GetSequenceNumber()
+	if last, seen := s.lastSeq[key]; seen {
+		if gap := seq - last - 1; gap > 0 && gap < 128 {
+			s.lost += uint64(gap)
+			s.pendingLost += uint64(gap)
+		}
+	}
+	s.lastSeq[key] = seq
+	return s.current
+}
+
+// end marks the consumer done with ev (no-op for nil)
+func (s *eventStage) end(ev *stageEvent) {
+	if ev == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := time.Since(ev.started)
+	if d >= eventStallAfter {
+		s.stalls++
+		logger.Debug("[EVENTS] %s spent %v on %s", s.consumer, d.Round(time.Millisecond), getMessageTypeName(ev.msg))
+	}
+	if d > s.slowest {
+		s.slowest = d
+		s.slowestMsg = getMessageTypeName(ev.msg)
+	}
+	if s.current == ev {
+		s.current = nil
+	}
+}
+
+// publish exports the counters with the current buffer depth
+func (s *eventStage) publish(depth, capacity int) {
+	s.mu.Lock()
+	if depth > s.maxDepth {
+		s.maxDepth = depth
+	}
+	st := metrics.EventStage{
+		Consumer:     s.consumer,
+		Events:       s.events,
+		Depth:        depth,
+		MaxDepth:     s.maxDepth,
+		Capacity:     capacity,
+		Backpressure: s.waits,
+		BlockedMs:    float64(s.waited) / float64(time.Millisecond),
+		Stalls:       s.stalls,
+		Lost:         s.lost,
+	}
+	s.mu.Unlock()
+	metrics.Global.SetEventStage(s.source, st)
+}
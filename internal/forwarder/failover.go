@@ -0,0 +1,223 @@
+package forwarder
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3"
+
+	"DroneBridge/config"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/signing"
+)
+
+const (
+	// failoverCheckInterval is how often the upstream server is checked for silence
+	failoverCheckInterval = time.Second
+	// probeTimeout is how long a preferred server has to acknowledge a probe
+	probeTimeout = 5 * time.Second
+)
+
+// upstreamFailover moves the uplink along network.servers when the current
+// server goes quiet. Any verified frame from the server counts as a sign of
+// life; routers ACK every SESSION_HEARTBEAT, so a live one is never silent for
+// long. After a switch the new server gets a full timeout before the next one.
+// While on a backup, the servers preferred over it are probed every failback
+// interval and the uplink returns to the best one that answers.
+type upstreamFailover struct {
+	servers  []config.ServerConfig // Priority order, see config parse
+	timeout  time.Duration
+	failback time.Duration
+
+	mu        sync.Mutex
+	current   int       // Index into servers
+	lastRx    time.Time // Last frame from the server
+	since     time.Time // When the current server was selected
+	lastProbe time.Time // Start of the last probe round
+	probing   bool      // A probe round is running
+}
+
+func newUpstreamFailover(servers []config.ServerConfig, timeout, failback time.Duration, now time.Time) *upstreamFailover {
+	return &upstreamFailover{servers: servers, timeout: timeout, failback: failback, since: now}
+}
+
+// Heard records traffic from the current server
+func (u *upstreamFailover) Heard(now time.Time) {
+	u.mu.Lock()
+	u.lastRx = now
+	u.mu.Unlock()
+}
+
+// Check returns the next server when the current one has been silent for the timeout
+func (u *upstreamFailover) Check(now time.Time) (config.ServerConfig, time.Duration, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	last := u.since
+	if u.lastRx.After(last) {
+		last = u.lastRx
+	}
+	silent := now.Sub(last)
+	if silent < u.timeout {
+		return config.ServerConfig{}, 0, false
+	}
+	u.current = (u.current + 1) % len(u.servers)
+	u.since = now
+	return u.servers[u.current], silent, true
+}
+
+// Probe starts a probe round when the uplink has been on a backup for the
+// failback interval since it switched or last probed. It returns the servers
+// preferred over the current one, best first (nil = nothing to probe).
+func (u *upstreamFailover) Probe(now time.Time) []config.ServerConfig {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.current == 0 || u.probing || now.Sub(u.since) < u.failback || now.Sub(u.lastProbe) < u.failback {
+		return nil
+	}
+	u.probing = true
+	u.lastProbe = now
+	return append([]config.ServerConfig(nil), u.servers[:u.current]...)
+}
+
+// FailBack ends a probe round. Server i of the probed list (-1 = none answered)
+// becomes the current one if it is still preferred over it.
+func (u *upstreamFailover) FailBack(i int, now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.probing = false
+	if i < 0 || i >= u.current {
+		return false
+	}
+	u.current = i
+	u.since = now
+	return true
+}
+
+// watchUpstream fails the uplink over to the next configured server (network.servers)
+// and back to a preferred one once it answers again
+func (f *Forwarder) watchUpstream() {
+	ticker := f.clock.NewTicker(failoverCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stopCh:
+			return
+		case <-ticker.C():
+			now := f.clock.Now()
+			next, silent, ok := f.failover.Check(now)
+			if !ok {
+				if preferred := f.failover.Probe(now); preferred != nil {
+					go f.failBack(preferred)
+				}
+				continue
+			}
+			f.mu.RLock()
+			prev := f.cfg.GetAddress()
+			f.mu.RUnlock()
+			addr := fmt.Sprintf("%s:%d", next.Host, next.Port)
+			logger.Warn("[FAILOVER] No traffic from %s for %v - failing over to %s", prev, silent.Round(time.Second), addr)
+			metrics.Global.AddLog("WARN", fmt.Sprintf("Upstream failover: %s -> %s", prev, addr))
+			metrics.Global.RecordFailover(addr)
+			f.retarget(next.Host, next.Port, "failover to "+addr)
+		}
+	}
+}
+
+// failBack probes the preferred servers, best first, and returns the uplink to
+// the first one that registers the session
+func (f *Forwarder) failBack(preferred []config.ServerConfig) {
+	for i, s := range preferred {
+		addr := fmt.Sprintf("%s:%d", s.Host, s.Port)
+		if err := f.probe(addr); err != nil {
+			logger.Debug("[FAILOVER] Probe of %s failed: %v", addr, err)
+			continue
+		}
+		if !f.failover.FailBack(i, f.clock.Now()) {
+			return // Failed over past it meanwhile
+		}
+		f.mu.RLock()
+		prev := f.cfg.GetAddress()
+		f.mu.RUnlock()
+		logger.Info("[FAILOVER] %s answers again - failing back from %s", addr, prev)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Upstream failback: %s -> %s", prev, addr))
+		metrics.Global.RecordFailover(addr)
+		f.retarget(s.Host, s.Port, "failback to "+addr)
+		return
+	}
+	f.failover.FailBack(-1, f.clock.Now())
+}
+
+// probeServer checks that the router at addr would take the session: SESSION_HEARTBEAT
+// from a separate uplink, repeated each second, must come back with a registered
+// SESSION_HEARTBEAT_ACK within probeTimeout
+func (f *Forwarder) probeServer(addr string) error {
+	if f.authClient == nil {
+		return fmt.Errorf("no auth client")
+	}
+	tokenHex, expiresAt := f.authClient.GetSessionInfo()
+	token, ok := sessionTokenBytes(tokenHex)
+	if !ok {
+		return fmt.Errorf("no session")
+	}
+
+	var link *quicLink
+	if f.quic != nil {
+		tlsConf, err := quicTLSConfig(f.cfg.Network.QUIC)
+		if err != nil {
+			return err
+		}
+		link = newQUICLink(addr, tlsConf)
+		defer link.Close()
+	}
+	node, err := newSenderNode(addr, f.senderSysID, link)
+	if err != nil {
+		return err
+	}
+	up := newUplink(node, newEventStage("probe", "failBack"))
+	defer up.Close()
+
+	sent := make(map[uint16]bool)
+	send := func() error {
+		seq, err := f.authClient.NextHeartbeatSequence()
+		if err != nil {
+			return err
+		}
+		sent[seq] = true
+		return up.WriteMessageAll(mavlink_custom.NewSessionHeartbeat(token, expiresAt, seq))
+	}
+	if err := send(); err != nil {
+		return err
+	}
+	resend := f.clock.NewTicker(time.Second)
+	defer resend.Stop()
+	deadline := f.clock.After(probeTimeout)
+	for {
+		select {
+		case <-f.stopCh:
+			return fmt.Errorf("stopped")
+		case <-deadline:
+			return fmt.Errorf("no SESSION_HEARTBEAT_ACK within %v", probeTimeout)
+		case <-resend.C():
+			if err := send(); err != nil {
+				return err
+			}
+		case evt := <-up.Events():
+			e, ok := evt.(*gomavlib.EventFrame)
+			if !ok || signing.Global.Verify(e.Frame) != nil {
+				continue
+			}
+			ack, ok := e.Message().(*mavlink_custom.MessageSessionHeartbeatAck)
+			if !ok || !sent[ack.Sequence] {
+				continue
+			}
+			if ack.Status != mavlink_custom.SessionAckRegistered {
+				return fmt.Errorf("router rejected the session (status %d)", ack.Status)
+			}
+			return nil
+		}
+	}
+}
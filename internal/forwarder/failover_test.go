@@ -0,0 +1,113 @@
+package forwarder
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"DroneBridge/config"
+	"DroneBridge/internal/testutil"
+)
+
+var testServers = []config.ServerConfig{
+	{Host: "primary", Port: 14550, Priority: 0},
+	{Host: "secondary", Port: 14550, Priority: 1},
+	{Host: "tertiary", Port: 14550, Priority: 2},
+}
+
+func TestFailoverProbeSchedule(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	u := newUpstreamFailover(testServers, 10*time.Second, time.Minute, start)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	if got := u.Probe(at(2 * time.Minute)); got != nil {
+		t.Errorf("on the primary: Probe() = %v, want nil", got)
+	}
+	// Primary silent: fail over to the secondary, then the tertiary
+	if next, _, ok := u.Check(at(10 * time.Second)); !ok || next.Host != "secondary" {
+		t.Fatalf("Check() = %v, %v, want secondary", next.Host, ok)
+	}
+	if next, _, ok := u.Check(at(20 * time.Second)); !ok || next.Host != "tertiary" {
+		t.Fatalf("Check() = %v, %v, want tertiary", next.Host, ok)
+	}
+	u.Heard(at(25 * time.Second))
+
+	if got := u.Probe(at(70 * time.Second)); got != nil {
+		t.Errorf("before the failback interval: Probe() = %v, want nil", got)
+	}
+	got := u.Probe(at(80 * time.Second))
+	if len(got) != 2 || got[0].Host != "primary" || got[1].Host != "secondary" {
+		t.Fatalf("Probe() = %v, want primary, secondary", got)
+	}
+	if again := u.Probe(at(200 * time.Second)); again != nil {
+		t.Errorf("during a probe round: Probe() = %v, want nil", again)
+	}
+
+	// Nobody answered: wait another interval
+	if u.FailBack(-1, at(85*time.Second)) {
+		t.Error("FailBack(-1) = true, want false")
+	}
+	if again := u.Probe(at(120 * time.Second)); again != nil {
+		t.Errorf("after a failed round: Probe() = %v, want nil", again)
+	}
+	if again := u.Probe(at(140 * time.Second)); len(again) != 2 {
+		t.Fatalf("next round: Probe() = %v, want 2 servers", again)
+	}
+
+	// The secondary answered: back to it, the primary stays to be probed
+	if !u.FailBack(1, at(145*time.Second)) {
+		t.Fatal("FailBack(1) = false, want true")
+	}
+	if again := u.Probe(at(205 * time.Second)); len(again) != 1 || again[0].Host != "primary" {
+		t.Errorf("on the secondary: Probe() = %v, want primary", again)
+	}
+	if u.FailBack(1, at(206*time.Second)) {
+		t.Error("FailBack to the current server = true, want false")
+	}
+}
+
+func TestFailBackProbesBestFirst(t *testing.T) {
+	tests := []struct {
+		name     string
+		answers  map[string]bool
+		wantAddr string // "" = stays on the tertiary
+	}{
+		{"primary back", map[string]bool{"primary:14550": true, "secondary:14550": true}, "primary:14550"},
+		{"only secondary back", map[string]bool{"secondary:14550": true}, "secondary:14550"},
+		{"none back", nil, ""},
+	}
+	for _, tt := range tests {
+		clk := testutil.NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+		f := newTestForwarder(t, clk, testutil.NewFakeDialer("10.0.0.2"))
+		f.cfg.Network.TargetHost = "tertiary"
+		f.failover = newUpstreamFailover(testServers, 10*time.Second, time.Minute, clk.Now())
+		f.failover.current = 2
+
+		var probed []string
+		f.probe = func(addr string) error {
+			probed = append(probed, addr)
+			if tt.answers[addr] {
+				return nil
+			}
+			return errors.New("no answer")
+		}
+		clk.Advance(time.Minute)
+		preferred := f.failover.Probe(clk.Now())
+		f.failBack(preferred)
+
+		if probed[0] != "primary:14550" {
+			t.Errorf("%s: probed %v first, want the primary", tt.name, probed)
+		}
+		got := f.cfg.GetAddress()
+		want := tt.wantAddr
+		if want == "" {
+			want = "tertiary:14550"
+		}
+		if got != want {
+			t.Errorf("%s: uplink at %s, want %s", tt.name, got, want)
+		}
+		if f.failover.probing {
+			t.Errorf("%s: probe round still open", tt.name)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package forwarder
+
+import (
+	"sync"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/fcpin"
+	"DroneBridge/internal/logger"
+)
+
+// fcSource remembers which system/component is the flight controller itself.
+// Gimbals, cameras and companion computers share the FC's system ID and send
+// heartbeats with the armed bit clear, so vehicle state (armed, flight mode)
+// is only taken from the FC's own heartbeat.
+type fcSource struct {
+	mu     sync.Mutex
+	known  bool
+	sysID  uint8
+	compID uint8
+}
+
+// observe reports whether hb from sysID/compID is the flight controller's
+// heartbeat. The first autopilot heartbeat pins the source; it moves only when
+// the FC pin (ethernet.pin_fc) was re-paired to another system ID.
+func (s *fcSource) observe(sysID, compID uint8, hb *common.MessageHeartbeat) bool {
+	if hb.Autopilot == common.MAV_AUTOPILOT_INVALID || hb.Type == common.MAV_TYPE_GCS {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.known && s.sysID == sysID && s.compID == compID {
+		return true
+	}
+	if s.known && !(s.sysID != sysID && fcpin.Global.Pinned(sysID)) {
+		return false
+	}
+	s.known, s.sysID, s.compID = true, sysID, compID
+	logger.Info("[PIXHAWK] Taking vehicle state from System ID %d, Component ID %d", sysID, compID)
+	return true
+}
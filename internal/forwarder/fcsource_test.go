@@ -0,0 +1,36 @@
+package forwarder
+
+import (
+	"testing"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+)
+
+func TestFCSourceObserve(t *testing.T) {
+	fc := &common.MessageHeartbeat{Type: common.MAV_TYPE_QUADROTOR, Autopilot: common.MAV_AUTOPILOT_ARDUPILOTMEGA}
+	gimbal := &common.MessageHeartbeat{Type: common.MAV_TYPE_GIMBAL, Autopilot: common.MAV_AUTOPILOT_INVALID}
+	companion := &common.MessageHeartbeat{Type: common.MAV_TYPE_ONBOARD_CONTROLLER, Autopilot: common.MAV_AUTOPILOT_GENERIC}
+	gcs := &common.MessageHeartbeat{Type: common.MAV_TYPE_GCS, Autopilot: common.MAV_AUTOPILOT_GENERIC}
+
+	var s fcSource
+	steps := []struct {
+		name   string
+		sysID  uint8
+		compID uint8
+		hb     *common.MessageHeartbeat
+		want   bool
+	}{
+		{"gimbal before FC", 1, 154, gimbal, false},
+		{"gcs", 1, 190, gcs, false},
+		{"first FC heartbeat pins", 1, 1, fc, true},
+		{"FC again", 1, 1, fc, true},
+		{"gimbal after FC", 1, 154, gimbal, false},
+		{"companion with an autopilot type", 1, 191, companion, false},
+		{"another vehicle", 2, 1, fc, false},
+	}
+	for _, st := range steps {
+		if got := s.observe(st.sysID, st.compID, st.hb); got != st.want {
+			t.Errorf("%s: observe() = %v, want %v", st.name, got, st.want)
+		}
+	}
+}
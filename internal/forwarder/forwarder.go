@@ -3,7 +3,6 @@ package forwarder
 import (
 	"fmt"
 	"net"
-	"os/exec"
 	"strconv"
 	"strings"
 	"sync"
@@ -11,19 +10,49 @@ import (
 	"time"
 
 	"github.com/bluenviron/gomavlib/v3"
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/ardupilotmega"
 	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
 
 	"DroneBridge/config"
 	"DroneBridge/internal/auth"
+	"DroneBridge/internal/batch"
+	"DroneBridge/internal/battery"
+	"DroneBridge/internal/channels"
+	"DroneBridge/internal/clock"
+	"DroneBridge/internal/control"
+	"DroneBridge/internal/deadman"
+	"DroneBridge/internal/dialer"
+	"DroneBridge/internal/dronecan"
+	"DroneBridge/internal/echo"
+	"DroneBridge/internal/fcpin"
+	"DroneBridge/internal/health"
+	"DroneBridge/internal/journal"
 	"DroneBridge/internal/logger"
+	"DroneBridge/internal/maintenance"
 	"DroneBridge/internal/mavlink_custom"
+	"DroneBridge/internal/mesh"
 	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/parseerrors"
+	"DroneBridge/internal/policy"
+	"DroneBridge/internal/power"
+	"DroneBridge/internal/rollup"
+	"DroneBridge/internal/safety"
+	"DroneBridge/internal/signing"
+	"DroneBridge/internal/telemetry"
+	"DroneBridge/internal/traffic"
+	"DroneBridge/internal/transform"
+	"DroneBridge/internal/watchdog"
+	"DroneBridge/internal/wsproxy"
 	"DroneBridge/web"
 )
 
 // getMessageTypeName extracts clean message type name from message
 // e.g., *common.MessageHeartbeat -> HEARTBEAT
 func getMessageTypeName(msg interface{}) string {
+	if raw, ok := msg.(*message.MessageRaw); ok {
+		return fmt.Sprintf("UNKNOWN_%d", raw.ID)
+	}
 	fullType := fmt.Sprintf("%T", msg)
 
 	// Remove *common. prefix if exists
@@ -55,7 +84,9 @@ func getPixhawkSystemID() uint8 {
 type Forwarder struct {
 	cfg          *config.Config
 	listenerNode *gomavlib.Node // Listens for messages from Pixhawk and sends heartbeats
-	senderNode   *gomavlib.Node // Sends messages to server
+	sender       *uplink        // Sends messages to server (survives IP-change migrations)
+	quic         *quicLink      // QUIC transport under the sender node (nil with network.protocol udp)
+	senderSysID  uint8          // OutSystemID used for sender nodes
 	authClient   *auth.Client
 	stopCh       chan struct{}
 	previousIP   string // Track previous local IP for change detection
@@ -65,14 +96,9 @@ type Forwarder struct {
 	pixhawkOnce      sync.Once     // Ensure pixhawkConnected is closed only once
 
 	// Network health
-	isHealthy    bool
-	forceCheckCh chan struct{}
-	mu           sync.RWMutex
-
-	// Logging control
-	lastHeartbeatLog time.Time
-	lastGPSLog       time.Time
-	lastAttitudeLog  time.Time
+	isHealthy bool
+	reconnect *reconnectCoordinator // Sole owner of reconnect sequencing
+	mu        sync.RWMutex
 
 	// UDP heartbeat status
 	udpHeartbeatSent chan struct{} // Signal when first UDP heartbeat sent
@@ -82,21 +108,70 @@ type Forwarder struct {
 	seqMu      sync.RWMutex
 
 	// Verbose mode for detailed message parsing
-	verboseMode bool
+	verboseMode     bool
+	verboseMessages map[string]bool // Normalised types from log.verbose_messages (nil = not selective)
 
 	// Server IP for loop prevention
 	serverIP string
 
+	// Replay detection for SESSION_HEARTBEAT received from the server side
+	heartbeatGuard *auth.SequenceGuard
+
+	// Router confirmation of our uplink (SESSION_HEARTBEAT_ACK)
+	registration *uplinkRegistration
+
+	// Upstream server failover (nil unless network.servers lists more than one)
+	failover *upstreamFailover
+	probe    func(addr string) error // Asks a preferred server to take the session (probeServer)
+
+	// Injectable time and network (defaults to the real ones)
+	clock  clock.Clock
+	dialer dialer.Dialer
+
+	// Compressed batching of low-priority messages (nil when disabled)
+	batcher *batch.Batcher
+
+	// Non-blocking per-direction write queues
+	toServer *writeQueue // Pixhawk -> server (uplink)
+	toFC     *writeQueue // Server -> Pixhawk (listener)
+
+	// Ethernet hotplug (ethernet.hotplug): broadcast endpoint added after startup
+	hotplugMu sync.RWMutex
+	hotplug   *fcBroadcast        // nil while no link came up after startup
+	fcEvents  chan gomavlib.Event // Listener and hotplug node events, read by receiveAndForward
+
+	// Event stream instrumentation (see eventstats.go)
+	fcStage     *eventStage
+	serverStage *eventStage
+
+	fcSource fcSource // System/component whose heartbeat carries vehicle state
+
+	fcSignedSeq atomic.Uint32 // Sequence of our own messages to the FC sent as signed frames (signing.sign_to_fc)
+
+	// Consumer loops, restarted by the watchdog when stuck in a handler
+	fcLoop     *loopRestarter
+	serverLoop *loopRestarter
+
 	// Stats
 	statsManager *logger.StatsManager
 	rxCount      *atomic.Uint64
 	txCount      *atomic.Uint64
 	dedupCount   *atomic.Uint64
+
+	filteredCount     *atomic.Uint64
+	shapedCount       *atomic.Uint64
+	batchedCount      *atomic.Uint64
+	overflowCount     *atomic.Uint64
+	heldCount         *atomic.Uint64
+	maintenanceCount  *atomic.Uint64
+	echoCount         *atomic.Uint64
+	foreignCount      *atomic.Uint64
+	badSignatureCount *atomic.Uint64
 }
 
 // getLocalIP returns the current local IP address used for outbound connections
-func getLocalIP() (string, error) {
-	conn, err := net.Dial("udp", "8.8.8.8:80")
+func getLocalIP(d dialer.Dialer) (string, error) {
+	conn, err := d.Dial("udp", "8.8.8.8:80")
 	if err != nil {
 		return "", err
 	}
@@ -106,7 +181,7 @@ func getLocalIP() (string, error) {
 }
 
 // getEthernetIP automatically detects the IP address of an ethernet interface
-// It searches for interfaces matching common ethernet naming patterns: eth*, end*, enp*, eno*
+// It searches for interfaces matching the platform's ethernet naming patterns (defaultEthPatterns)
 // Returns the IP address and broadcast address for the found interface
 func getEthernetIP(cfg *config.Config) (localIP string, broadcastIP string, ifaceName string, err error) {
 	// If local IP is configured, check if it exists on an interface
@@ -166,7 +241,7 @@ func getEthernetIP(cfg *config.Config) (localIP string, broadcastIP string, ifac
 			return localIP, broadcastIP, ifaceName, nil
 		} else if cfg.Ethernet.AutoSetup {
 			// IP not found, try to auto-setup on detected interface
-			ethPatterns := []string{"eth", "end", "enp", "eno"}
+			ethPatterns := defaultEthPatterns
 			if cfg.Ethernet.Interface != "" {
 				ethPatterns = []string{cfg.Ethernet.Interface}
 			}
@@ -210,7 +285,7 @@ func getEthernetIP(cfg *config.Config) (localIP string, broadcastIP string, ifac
 	}
 
 	// Auto-detect from interface
-	ethPatterns := []string{"eth", "end", "enp", "eno"}
+	ethPatterns := defaultEthPatterns
 
 	// If specific interface is configured, only look for that
 	if cfg.Ethernet.Interface != "" {
@@ -314,26 +389,18 @@ func getEthernetIP(cfg *config.Config) (localIP string, broadcastIP string, ifac
 	return "", "", "", fmt.Errorf("no ethernet interface found (patterns: %v)", ethPatterns)
 }
 
-// setupInterfaceIP configures an IP address on an interface using ip command
-func setupInterfaceIP(ifaceName, ipAddr, subnet string) error {
-	if subnet == "" {
-		subnet = "24"
+// prefixToMask converts a prefix length ("24") to a dotted netmask ("255.255.255.0")
+func prefixToMask(subnet string) (string, error) {
+	bits, err := strconv.Atoi(subnet)
+	if err != nil || bits < 0 || bits > 32 {
+		return "", fmt.Errorf("invalid subnet prefix %q", subnet)
 	}
-	cmd := exec.Command("sudo", "ip", "addr", "add", fmt.Sprintf("%s/%s", ipAddr, subnet), "dev", ifaceName)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		// Check if IP already exists
-		if strings.Contains(string(output), "File exists") {
-			logger.Debug("[NETWORK] IP %s already exists on %s", ipAddr, ifaceName)
-			return nil
-		}
-		return fmt.Errorf("failed to add IP: %s - %v", string(output), err)
-	}
-	return nil
+	return net.IP(net.CIDRMask(bits, 32)).String(), nil
 }
 
 // New creates a new forwarder instance
-// DiscoverPixhawk opens a transient MAVLink node to discover Pixhawk's IP via broadcast.
+// DiscoverPixhawk looks for the Pixhawk on the ethernet broadcast network and on every
+// ethernet.discovery_candidates entry in parallel; the first FC heartbeat wins.
 // Returns the discovered IP (string), Port (int), and its System ID (uint8).
 func DiscoverPixhawk(cfg *config.Config, timeout time.Duration) (string, int, uint8, error) {
 	logger.Info("[DISCOVERY] 🔎 Starting Pixhawk discovery via Broadcast...")
@@ -346,99 +413,57 @@ func DiscoverPixhawk(cfg *config.Config, timeout time.Duration) (string, int, ui
 		logger.Info("[DISCOVERY] Server IP resolved to %s (will be explicitly skipped)", serverIP)
 	}
 
-	// Get ethernet IP for UDP broadcast
-	localEthIP, broadcastEthIP, ifaceName, ethErr := getEthernetIP(cfg)
-	if ethErr != nil || localEthIP == "" || broadcastEthIP == "" {
-		return "", 0, 0, fmt.Errorf("network discovery unavailable: %v", ethErr)
+	probes, err := discoveryProbes(cfg)
+	if err != nil {
+		return "", 0, 0, err
 	}
-
-	// Use temporary endpoints for discovery
-	// We use the same listen port, but we'll close this node immediately after discovery
-	endpoints := []gomavlib.EndpointConf{
-		gomavlib.EndpointUDPServer{Address: fmt.Sprintf("0.0.0.0:%d", cfg.Network.LocalListenPort)},
-		gomavlib.EndpointUDPBroadcast{
-			BroadcastAddress: fmt.Sprintf("%s:%d", broadcastEthIP, cfg.Network.LocalListenPort),
-			LocalAddress:     fmt.Sprintf("%s:%d", localEthIP, cfg.Network.BroadcastPort),
-		},
+	if len(probes) > 1 {
+		logger.Info("[DISCOVERY] Probing %d networks in parallel", len(probes))
 	}
 
-	logger.Info("[DISCOVERY] UDP Broadcast enabled on %s: Local=%s:%d, Broadcast=%s:%d",
-		ifaceName, localEthIP, cfg.Network.BroadcastPort, broadcastEthIP, cfg.Network.LocalListenPort)
-
-	discoveryNode, err := gomavlib.NewNode(gomavlib.NodeConf{
-		Endpoints:   endpoints,
-		Dialect:     mavlink_custom.GetCombinedDialect(),
-		OutVersion:  gomavlib.V2,
-		OutSystemID: 255, // Identify as a GCS during discovery
-	})
-	if err != nil {
-		return "", 0, 0, fmt.Errorf("failed to create discovery node: %w", err)
+	// Every probe node is closed before returning, so the listener can take over the ports
+	stop := make(chan struct{})
+	results := make(chan discoveryResult, len(probes))
+	var wg sync.WaitGroup
+	for _, p := range probes {
+		wg.Add(1)
+		go func(p discoveryProbe) {
+			defer wg.Done()
+			results <- p.run(serverIP, stop)
+		}(p)
 	}
-	defer discoveryNode.Close()
+	defer wg.Wait()
+	defer close(stop)
 
-	// Wait for heartbeat
 	timeoutTimer := time.NewTimer(timeout)
 	defer timeoutTimer.Stop()
 
-	eventCh := discoveryNode.Events()
-	for {
+	var failures []string
+	for range probes {
 		select {
 		case <-timeoutTimer.C:
 			return "", 0, 0, fmt.Errorf("discovery timed out after %v", timeout)
-		case event := <-eventCh:
-			if frame, ok := event.(*gomavlib.EventFrame); ok {
-				if hb, ok := frame.Message().(*common.MessageHeartbeat); ok {
-					sysID := frame.SystemID()
-
-					// 1. Skip GCS heartbeats (from server or other GCS)
-					if sysID == 255 || hb.Type == common.MAV_TYPE_GCS {
-						logger.Debug("[DISCOVERY] Skipping GCS heartbeat (SysID: %d, Type: %d)", sysID, hb.Type)
-						continue
-					}
-
-					// 2. Skip invalid autopilots (strictly Pixhawk-like devices)
-					if hb.Autopilot == common.MAV_AUTOPILOT_INVALID {
-						logger.Debug("[DISCOVERY] Skipping invalid autopilot (SysID: %d)", sysID)
-						continue
-					}
-
-					// In gomavlib v3, the Channel string usually contains the remote address
-					chanStr := frame.Channel.String()
-					remoteAddr := chanStr
-
-					// Extraction of remote IP from channel string
-					parts := strings.Split(chanStr, ":")
-					if len(parts) >= 3 && parts[0] == "udp" {
-						remoteAddr = strings.Join(parts[1:3], ":")
-						if idx := strings.Index(remoteAddr, " "); idx != -1 {
-							remoteAddr = remoteAddr[:idx]
-						}
-					}
-
-					// Extract IP from host:port
-					ip, portStr, _ := net.SplitHostPort(remoteAddr)
-					if ip == "" {
-						ip = remoteAddr
-					}
-					port, _ := strconv.Atoi(portStr)
-
-					// 3. Skip Server IP (explicit loop prevention)
-					if serverIP != "" && ip == serverIP {
-						logger.Debug("[DISCOVERY] Skipping heartbeat from Server IP: %s", ip)
-						continue
-					}
-
-					logger.Info("[DISCOVERY] ✅ Found Pixhawk at %s:%d (System ID: %d, Autopilot: %d) from channel: %s", ip, port, sysID, hb.Autopilot, chanStr)
-					return ip, port, sysID, nil
-				}
+		case res := <-results:
+			if res.err != nil {
+				logger.Warn("[DISCOVERY] %s: %v", res.probe, res.err)
+				failures = append(failures, fmt.Sprintf("%s: %v", res.probe, res.err))
+				continue
 			}
+			logger.Info("[DISCOVERY] ✅ Found Pixhawk at %s:%d (System ID: %d) via %s", res.ip, res.port, res.sysID, res.probe)
+			return res.ip, res.port, res.sysID, nil
 		}
 	}
+	return "", 0, 0, fmt.Errorf("network discovery unavailable: %s", strings.Join(failures, "; "))
 }
 
 // NewListener creates only the listener node to receive from Pixhawk
 // If pixhawkIP is provided, it uses direct Unicast instead of Broadcast.
+// With serial.enabled the node uses the UART instead and the address is ignored.
 func NewListener(cfg *config.Config, pixhawkIP string, pixhawkPort int) (*gomavlib.Node, error) {
+	if cfg.Serial.Enabled {
+		return newSerialListener(cfg)
+	}
+
 	// Build endpoints list
 	endpoints := []gomavlib.EndpointConf{
 		gomavlib.EndpointUDPServer{Address: fmt.Sprintf("0.0.0.0:%d", cfg.Network.LocalListenPort)},
@@ -526,23 +551,30 @@ func New(cfg *config.Config, authClient *auth.Client, listenerNode *gomavlib.Nod
 	}
 	logger.Info("[FORWARDER] Using Pixhawk System ID: %d for OutSystemID", pixhawkSysID)
 
+	// QUIC uplink (network.protocol: quic) - the link outlives sender nodes
+	var quicUplink *quicLink
+	if cfg.Network.Protocol == "quic" {
+		tlsConf, err := quicTLSConfig(cfg.Network.QUIC)
+		if err != nil {
+			listenerNode.Close()
+			return nil, err
+		}
+		quicUplink = newQUICLink(cfg.GetAddress(), tlsConf)
+	}
+
 	// Create sender node to forward to server WITH correct system ID
-	senderNode, err := gomavlib.NewNode(gomavlib.NodeConf{
-		Endpoints: []gomavlib.EndpointConf{
-			gomavlib.EndpointUDPClient{Address: cfg.GetAddress()},
-		},
-		Dialect:     mavlink_custom.GetCombinedDialect(),
-		OutVersion:  gomavlib.V2,
-		OutSystemID: pixhawkSysID, // Use actual Pixhawk sys_id instead of hardcoded 1
-	})
+	senderNode, err := newSenderNode(cfg.GetAddress(), pixhawkSysID, quicUplink)
 	if err != nil {
 		listenerNode.Close()
+		if quicUplink != nil {
+			quicUplink.Close()
+		}
 		return nil, fmt.Errorf("failed to create sender MAVLink node: %w", err)
 	}
-	logger.Info("MAVLink sender created, forwarding to %s", cfg.GetAddress())
+	logger.Info("MAVLink sender created, forwarding to %s over %s", cfg.GetAddress(), cfg.Network.Protocol)
 
 	// Get initial local IP
-	localIP, err := getLocalIP()
+	localIP, err := getLocalIP(dialer.Real)
 	if err != nil {
 		logger.Warn("Failed to get local IP: %v", err)
 		localIP = ""
@@ -555,48 +587,115 @@ func New(cfg *config.Config, authClient *auth.Client, listenerNode *gomavlib.Nod
 		sIP = sIPs[0].String()
 	}
 
+	serverStage := newEventStage("server", "receiveFromServer")
 	fwd := &Forwarder{
 		cfg:              cfg,
 		listenerNode:     listenerNode,
-		senderNode:       senderNode,
+		sender:           newUplink(senderNode, serverStage),
+		quic:             quicUplink,
+		fcStage:          newEventStage("fc", "receiveAndForward"),
+		serverStage:      serverStage,
+		senderSysID:      pixhawkSysID,
 		authClient:       authClient,
 		stopCh:           make(chan struct{}),
 		previousIP:       localIP,
 		pixhawkConnected: make(chan struct{}),
 		isHealthy:        true,
-		forceCheckCh:     make(chan struct{}, 1),
 		udpHeartbeatSent: make(chan struct{}, 1),
 		lastSeqNum:       make(map[uint8]uint8),
 		verboseMode:      cfg.Log.Verbose,
+		verboseMessages:  newVerboseFilter(cfg.Log.VerboseMessages),
 		serverIP:         sIP,
 		statsManager:     logger.NewStatsManager(cfg.Log.StatsInterval),
+		heartbeatGuard:   auth.NewSequenceGuard(),
+		clock:            clock.Real,
+		dialer:           dialer.Real,
+	}
+
+	fwd.reconnect = newReconnectCoordinator(fwd)
+	fwd.fcLoop = newLoopRestarter("receiveAndForward", fwd.receiveAndForward)
+	fwd.serverLoop = newLoopRestarter("receiveFromServer", fwd.receiveFromServer)
+	fwd.registration = newUplinkRegistration(time.Duration(cfg.Auth.SessionAckWait)*time.Second, sessionHeartbeatInterval(cfg))
+	if len(cfg.Network.Servers) > 1 {
+		fwd.failover = newUpstreamFailover(cfg.Network.Servers, time.Duration(cfg.Network.FailoverTimeout)*time.Second,
+			time.Duration(cfg.Network.FailbackInterval)*time.Second, fwd.clock.Now())
+		fwd.probe = fwd.probeServer
 	}
 
 	// Register counters
 	fwd.rxCount = fwd.statsManager.RegisterCounter("Received")
 	fwd.txCount = fwd.statsManager.RegisterCounter("Forwarded")
 	fwd.dedupCount = fwd.statsManager.RegisterCounter("Dedup")
+	fwd.filteredCount = fwd.statsManager.RegisterCounter("Filtered")
+	fwd.shapedCount = fwd.statsManager.RegisterCounter("RateLimited")
+	fwd.batchedCount = fwd.statsManager.RegisterCounter("Batched")
+	fwd.overflowCount = fwd.statsManager.RegisterCounter("QueueOverflow")
+	fwd.heldCount = fwd.statsManager.RegisterCounter("HeldUnregistered")
+	fwd.maintenanceCount = fwd.statsManager.RegisterCounter("Maintenance")
+	fwd.echoCount = fwd.statsManager.RegisterCounter("Echo")
+	fwd.foreignCount = fwd.statsManager.RegisterCounter("ForeignFC")
+	fwd.badSignatureCount = fwd.statsManager.RegisterCounter("BadSignature")
+
+	fwd.toServer = newWriteQueue("to_server", cfg.Forwarding.WriteQueueSize, fwd.overflowCount)
+	fwd.toFC = newWriteQueue("to_fc", cfg.Forwarding.WriteQueueSize, fwd.overflowCount)
+	fwd.toFC.skip = fwd.staleCommandCheck()
+
+	if cfg.Forwarding.Batching.Enabled && fwd.quic != nil {
+		logger.Info("[BATCH] Batching is not used over QUIC (batches are plain UDP datagrams)")
+	} else if cfg.Forwarding.Batching.Enabled {
+		fwd.batcher, err = batch.New(batch.Config{
+			Address:   cfg.GetAddress(),
+			MaxFrames: cfg.Forwarding.Batching.MaxFrames,
+			MaxDelay:  time.Duration(cfg.Forwarding.Batching.MaxDelay) * time.Millisecond,
+			Messages:  cfg.Forwarding.Batching.Messages,
+		}, fwd.sessionToken)
+		if err != nil {
+			logger.Warn("[BATCH] Batching disabled: %v", err)
+			fwd.batcher = nil
+		}
+	}
 
 	// Wire up network error callback
 	if authClient != nil {
 		authClient.OnNetworkError = func() {
-			fwd.mu.Lock()
-			if fwd.isHealthy {
-				logger.Warn("[NETWORK] Network error detected via Auth Client - Marking unhealthy")
-				fwd.isHealthy = false
-				// Trigger immediate IP check
-				select {
-				case fwd.forceCheckCh <- struct{}{}:
-				default:
-				}
-			}
-			fwd.mu.Unlock()
+			fwd.reconnect.Trigger("auth network error")
 		}
+		authClient.OnRatePolicy = fwd.applyRatePolicy
 	}
 
 	return fwd, nil
 }
 
+// newSenderNode creates a node forwarding to the server with the custom dialect
+// (including SESSION_HEARTBEAT), over link when the uplink uses QUIC
+func newSenderNode(addr string, sysID uint8, link *quicLink) (*gomavlib.Node, error) {
+	var endpoint gomavlib.EndpointConf = gomavlib.EndpointUDPClient{Address: addr}
+	if link != nil {
+		endpoint = gomavlib.EndpointCustom{ReadWriteCloser: link.endpoint()}
+	}
+	return gomavlib.NewNode(gomavlib.NodeConf{
+		Endpoints:   []gomavlib.EndpointConf{endpoint},
+		Dialect:     mavlink_custom.GetCombinedDialect(),
+		OutVersion:  gomavlib.V2,
+		OutSystemID: sysID,
+	})
+}
+
+// SetClock replaces the time source used for monitoring and backoff. Must be called before Start().
+func (f *Forwarder) SetClock(clk clock.Clock) {
+	f.clock = clk
+	f.sender.clock = clk
+}
+
+// SetDialer replaces the dialer used for local IP detection and the batch uplink.
+// Must be called before Start().
+func (f *Forwarder) SetDialer(d dialer.Dialer) {
+	f.dialer = d
+	if f.batcher != nil {
+		f.batcher.SetDialer(d)
+	}
+}
+
 // GetListenerNode returns the listener MAVLink node for external use
 func (f *Forwarder) GetListenerNode() *gomavlib.Node {
 	return f.listenerNode
@@ -608,7 +707,7 @@ func (f *Forwarder) WaitForPixhawkConnection(timeout time.Duration) bool {
 	select {
 	case <-f.pixhawkConnected:
 		return true
-	case <-time.After(timeout):
+	case <-f.clock.After(timeout):
 		return false
 	}
 }
@@ -621,19 +720,74 @@ func (f *Forwarder) SetAuthClient(authClient *auth.Client) {
 	if f.authClient != nil {
 		// Wire up network error callback
 		f.authClient.OnNetworkError = func() {
-			f.mu.Lock()
-			if f.isHealthy {
-				logger.Warn("[NETWORK] Network error detected via Auth Client - Marking unhealthy")
-				f.isHealthy = false
-				// Trigger immediate IP check
-				select {
-				case f.forceCheckCh <- struct{}{}:
-				default:
-				}
-			}
-			f.mu.Unlock()
+			f.reconnect.Trigger("auth network error")
+		}
+		f.authClient.OnRatePolicy = f.applyRatePolicy
+
+		// Negotiation may already have happened during the initial authentication
+		if rp := f.authClient.GetRatePolicy(); rp != nil {
+			go f.applyRatePolicy(rp)
+		}
+	}
+}
+
+// applyRatePolicy hands a router-advertised rate policy to the uplink shaper and batcher
+func (f *Forwarder) applyRatePolicy(rp *auth.RatePolicy) {
+	rates := make([]policy.MessageRate, 0, len(rp.Rates))
+	for _, r := range rp.Rates {
+		rates = append(rates, policy.MessageRate{MsgID: r.MsgID, MaxHz: float64(r.MaxHz)})
+	}
+	policy.Shaper.Apply(rp.MaxBytesPerSec, rates, "router")
+	logger.Info("[RATES] Applied router rate policy: max %d B/s, %d message caps", rp.MaxBytesPerSec, len(rates))
+
+	if f.batcher != nil {
+		if err := f.batcher.SetAlgorithm(rp.Compression); err != nil {
+			logger.Warn("[BATCH] %v - batching disabled", err)
+			f.batcher.SetAlgorithm(batch.AlgoNone)
+		} else if rp.Compression != batch.AlgoNone {
+			logger.Info("[BATCH] Router accepted compressed batching (algorithm 0x%02x)", rp.Compression)
 		}
 	}
+
+	if rp.HeartbeatID != 0 {
+		f.applyHeartbeatIdentity(mavlink_custom.Identity{
+			HeartbeatID:    rp.HeartbeatID,
+			HeartbeatAckID: rp.HeartbeatAckID,
+			Layout:         rp.HeartbeatLayout,
+		})
+	}
+}
+
+// applyHeartbeatIdentity switches SESSION_HEARTBEAT to the IDs and layout the
+// router assigned. The sender node decodes with the dialect it was built with,
+// so a change rebuilds it through the reconnect coordinator.
+func (f *Forwarder) applyHeartbeatIdentity(id mavlink_custom.Identity) {
+	changed, err := mavlink_custom.SetIdentity(id)
+	if err != nil {
+		logger.Warn("[MAVLINK_HB] Ignoring session heartbeat identity from router: %v - keeping %s", err, mavlink_custom.CurrentIdentity())
+		return
+	}
+	if !changed {
+		return
+	}
+	logger.Info("[MAVLINK_HB] Router assigned %s", id)
+	metrics.Global.AddLog("INFO", "Session heartbeat identity: "+id.String())
+	if err := signing.Global.ReloadDialect(); err != nil {
+		logger.Warn("[SIGNING] %v", err)
+	}
+	f.reconnect.TriggerRebind("session heartbeat identity changed")
+}
+
+// sessionToken returns the current session token (empty when auth is disabled)
+func (f *Forwarder) sessionToken() string {
+	f.mu.RLock()
+	client := f.authClient
+	f.mu.RUnlock()
+	if client == nil {
+		return ""
+	}
+	token, _ := client.GetSessionInfo()
+	return token
 }
 
 // Start begins the forwarder
@@ -644,7 +798,8 @@ func (f *Forwarder) Start() error {
 	// Do NOT call authClient.Start() here to avoid duplicate TCP connections
 	// The auth client is set via SetAuthClient() after forwarder creation
 
-	// Start IP change monitor
+	// Start reconnect coordinator and IP change monitor (which only reports triggers)
+	go f.reconnect.run(f.stopCh)
 	go f.monitorIPChange()
 
 	// Wait for first UDP heartbeat before starting to forward
@@ -654,14 +809,23 @@ func (f *Forwarder) Start() error {
 		select {
 		case <-f.udpHeartbeatSent:
 			logger.Info("First UDP heartbeat sent - now starting MAVLink forwarding")
-		case <-time.After(5 * time.Second):
+		case <-f.clock.After(5 * time.Second):
 			logger.Warn("Timeout waiting for UDP heartbeat, starting anyway...")
 		}
 	}
 
-	// Start receiving and forwarding messages
-	go f.receiveAndForward()
-	go f.receiveFromServer()
+	// Start write queues, then receiving and forwarding messages
+	go f.toServer.run(f.stopCh)
+	go f.toFC.run(f.stopCh)
+	// FC events go through a buffer, so a slow receiveAndForward shows up as backpressure
+	f.fcEvents = make(chan gomavlib.Event, eventBuffer)
+	go f.pumpEvents(f.listenerNode.Events(), nil)
+	if f.cfg.Ethernet.Hotplug && !f.cfg.Serial.Enabled {
+		go f.watchEthernet()
+	}
+	f.fcLoop.start()
+	f.serverLoop.start()
+	wsproxy.Global.SetInjector(f.relayFromWebSocket)
 	// DISABLED: GCS heartbeat causes MAV ID confusion (SystemID=1 conflicts with drone)
 	// DroneBridge should only forward messages, not generate its own heartbeat
 	// go f.sendHeartbeat()
@@ -669,6 +833,10 @@ func (f *Forwarder) Start() error {
 	f.statsManager.Start()
 
 	go f.sendMavlinkSessionHeartbeat() // MAVLink-wrapped session heartbeat for IP:Port sync
+	if f.failover != nil {
+		metrics.Global.SetUpstreamServer(f.cfg.GetAddress())
+		go f.watchUpstream()
+	}
 
 	logger.Info("Forwarder started - listening on port %d, forwarding to %s",
 		f.cfg.Network.LocalListenPort, f.cfg.GetAddress())
@@ -687,7 +855,13 @@ func (f *Forwarder) Stop() {
 	}
 
 	f.listenerNode.Close()
-	f.senderNode.Close()
+	f.sender.Close()
+	if f.quic != nil {
+		f.quic.Close()
+	}
+	if f.batcher != nil {
+		f.batcher.Close()
+	}
 
 	if f.statsManager != nil {
 		f.statsManager.Stop()
@@ -696,15 +870,34 @@ func (f *Forwarder) Stop() {
 }
 
 // receiveAndForward listens for incoming MAVLink messages from Pixhawk and forwards them to server
-func (f *Forwarder) receiveAndForward() {
-	eventCh := f.listenerNode.Events()
+func (f *Forwarder) receiveAndForward(gen uint64) {
+	eventCh := f.fcEvents
+
+	// A stuck event only takes its own loop down: the watchdog starts a fresh one
+	wd := watchdog.Global.Register("receiveAndForward", f.fcLoop.restart)
+	defer watchdog.Global.Unregister(wd)
+	beat := f.clock.NewTicker(watchdog.BeatInterval)
+	defer beat.Stop()
+
+	// Per-loop state: a retired generation still finishing its event must not share it
+	var handling *stageEvent
+	var lastHeartbeatLog, lastGPSLog, lastSysStatusLog time.Time
 
 	for {
+		if f.fcLoop.retired(gen) {
+			return
+		}
+		wd.Beat()
+		f.fcStage.end(handling)
+		handling = nil
 		select {
 		case <-f.stopCh:
 			return
+		case <-beat.C():
+			f.fcStage.publish(len(eventCh), cap(eventCh))
 		case event := <-eventCh:
-			now := time.Now()
+			handling = f.fcStage.begin(event)
+			now := f.clock.Now()
 			switch e := event.(type) {
 			case *gomavlib.EventFrame:
 				// Received a MAVLink message from Pixhawk
@@ -715,9 +908,19 @@ func (f *Forwarder) receiveAndForward() {
 
 				f.rxCount.Add(1)
 
+				// Our own writes come back when the listener broadcasts; never treat them as FC or GCS traffic
+				if echo.Global.IsEcho(e.Frame) {
+					f.echoCount.Add(1)
+					metrics.Global.IncEchoSuppressed(msgTypeName)
+					logger.Debug("[ECHO] Suppressed echo of %s (SysID: %d)", msgTypeName, sysID)
+					continue
+				}
+
 				// Skip messages not from Pixhawk (filter by SystemID 255, GCS type, or Server IP)
 				// Only forward messages from flight controller (typically SystemID 1)
 				if sysID == 255 {
+					// Local GCS traffic - not forwarded, but its control messages take part in arbitration
+					control.Global.ObserveLocal(msg.GetID())
 					logger.Debug("[SKIP] GCS message %s (SysID: 255)", msgTypeName)
 					continue
 				}
@@ -738,6 +941,15 @@ func (f *Forwarder) receiveAndForward() {
 					}
 				}
 
+				// Another FC on the same network is never forwarded under this drone's UUID
+				if v, ok := msg.(*common.MessageAutopilotVersion); ok {
+					fcpin.Global.ObserveVersion(sysID, e.Channel.String(), v.Uid, v.Uid2)
+				}
+				if !fcpin.Global.Allow(sysID, e.Channel.String()) {
+					f.foreignCount.Add(1)
+					continue
+				}
+
 				// Deduplicate messages by checking sequence number
 				f.seqMu.Lock()
 				lastSeq, exists := f.lastSeqNum[sysID]
@@ -751,42 +963,171 @@ func (f *Forwarder) receiveAndForward() {
 				f.lastSeqNum[sysID] = seqNum
 				f.seqMu.Unlock()
 
+				// Vendor extensions the dialect does not know are forwarded as raw frames
+				if isUnknownMessage(msg) && !f.passUnknown(metrics.BandFCToServer, msg.GetID()) {
+					continue
+				}
+
 				// Debug: Log all received messages
 				logger.Debug("[RX] %s (SysID: %d, Seq: %d)", msgTypeName, sysID, seqNum)
+				if f.verboseFor(msg, "Pixhawk") {
+					f.parseMessageVerbose(msg, sysID, "Pixhawk")
+				}
 
 				// Log specific message types at INFO level (reduced frequency)
 				switch m := msg.(type) {
 				case *common.MessageHeartbeat:
+					// Gimbals, cameras and companions share the FC's system ID; only its own heartbeat carries vehicle state
+					if !f.fcSource.observe(sysID, e.ComponentID(), m) {
+						break
+					}
 					// Signal on first heartbeat from Pixhawk
 					f.pixhawkOnce.Do(func() {
 						close(f.pixhawkConnected)
 						logger.Info("[PIXHAWK_CONNECTED] ✅ First heartbeat received from Pixhawk (SysID: %d)", sysID)
 					})
 
-					if now.Sub(f.lastHeartbeatLog) > 30*time.Second {
+					if fcpin.Global.VersionRequestDue(now) {
+						f.requestAutopilotVersion(sysID)
+					}
+
+					if now.Sub(lastHeartbeatLog) > 30*time.Second {
 						logger.Info("[PIXHAWK] Heartbeat: Type=%d, Mode=%d, Status=%d", m.Type, m.BaseMode, m.SystemStatus)
-						f.lastHeartbeatLog = now
+						lastHeartbeatLog = now
 					}
 					// Notify web server of connected Pixhawk - this captures the actual system ID
 					web.HandleHeartbeat(sysID)
+					armed := m.BaseMode&common.MAV_MODE_FLAG_SAFETY_ARMED != 0
+					web.HandleArmedState(armed)
+					rollup.Global.ObserveArmed(armed)
+					battery.Global.ObserveArmed(armed)
+					deadman.Global.ObserveArmed(armed)
+					safety.Global.ObserveArmed(armed)
+					power.Global.ObserveArmed(armed)
+					web.HandleFlightMode(m.Type, m.Autopilot, m.CustomMode)
+					journal.Global.ObserveHeartbeat(m)
 					actualSysID := web.GetPixhawkSystemID()
 					logger.Debug("[SYSID] Detected Pixhawk System ID: %d (using for MAVLink operations)", actualSysID)
 				case *common.MessageGpsRawInt:
-					if now.Sub(f.lastGPSLog) > 30*time.Second {
+					journal.Global.ObserveGPSRaw(m)
+					if now.Sub(lastGPSLog) > 30*time.Second {
 						logger.Info("[PIXHAWK] GPS: Fix=%d, Lat=%.6f, Lon=%.6f, Sats=%d",
 							m.FixType, float64(m.Lat)/1e7, float64(m.Lon)/1e7, m.SatellitesVisible)
-						f.lastGPSLog = now
+						lastGPSLog = now
 					}
 				case *common.MessageSysStatus:
-					if now.Sub(f.lastAttitudeLog) > 30*time.Second {
-						logger.Info("[PIXHAWK] Status: Voltage=%.2fV, Battery=%d%%",
-							float64(m.VoltageBattery)/1000, m.BatteryRemaining)
-						f.lastAttitudeLog = now
+					unhealthy := health.Global.UpdateSysStatus(m)
+					journal.Global.ObserveSysStatus(m)
+					if now.Sub(lastSysStatusLog) > 30*time.Second {
+						sensorState := "all sensors healthy"
+						if len(unhealthy) > 0 {
+							sensorState = "unhealthy: " + strings.Join(unhealthy, ", ")
+						}
+						logger.Info("[PIXHAWK] Status: Voltage=%.2fV, Battery=%d%%, %s",
+							float64(m.VoltageBattery)/1000, m.BatteryRemaining, sensorState)
+						lastSysStatusLog = now
 					}
+				case *ardupilotmega.MessageEkfStatusReport:
+					health.Global.UpdateEKFStatus(m)
+				case *common.MessageVibration:
+					health.Global.UpdateVibration(m)
+				case *common.MessageUavcanNodeStatus:
+					dronecan.Global.UpdateStatus(sysID, e.ComponentID(), m)
+				case *common.MessageUavcanNodeInfo:
+					dronecan.Global.UpdateInfo(sysID, e.ComponentID(), m)
+				case *common.MessageGlobalPositionInt:
+					traffic.Global.UpdateOwnship(m)
+					mesh.Global.UpdateOwnship(m)
+					web.HandlePosition(m)
+					journal.Global.ObservePosition(m)
+				case *common.MessageHomePosition:
+					web.HandleHomePosition(m)
+				case *common.MessageBatteryStatus:
+					battery.Global.UpdateStatus(m)
+				case *common.MessageSmartBatteryInfo:
+					battery.Global.UpdateInfo(m)
+				case *common.MessageVfrHud:
+					web.HandleVFRHud(m)
+				case *common.MessageTerrainReport:
+					web.HandleTerrainReport(m)
+				case *common.MessageAdsbVehicle:
+					traffic.Global.UpdateADSB(m)
 				case *common.MessageParamValue:
 					// Forward to web server for parameter caching
 					web.HandleParamValue(m)
 					logger.Debug("[PARAM] %s = %v (%d/%d)", m.ParamId, m.ParamValue, m.ParamIndex, m.ParamCount)
+				case *common.MessageStatustext, *ardupilotmega.MessageMagCalProgress, *common.MessageMagCalReport,
+					*common.MessageCommandAck, *common.MessageCommandLong:
+					// Calibration workflow progress, command ACKs and pre-arm reports
+					web.HandleCalibrationMessage(msg)
+					web.HandleCommandMessage(msg)
+				}
+				telemetry.Global.Observe(sysID, e.ComponentID(), msg) // Latest of every type for /api/telemetry/latest
+
+				// Apply forwarding policy (deployment tier allow-list)
+				if !policy.Global.Allowed(msg.GetID()) {
+					f.filteredCount.Add(1)
+					logger.Debug("[POLICY] %s blocked by policy '%s'", msgTypeName, policy.Global.Active())
+					continue
+				}
+
+				// Browser GCS clients on /ws/mavlink see what the policy lets through, at their own rates
+				if policy.Profiles.Allow(policy.ConsumerWebSocket, msg.GetID(), e.ComponentID()) {
+					if wsFrame, ok := transform.Global.Frame(policy.ConsumerWebSocket, e.Frame); ok {
+						wsproxy.Global.Publish(wsFrame)
+					}
+				}
+
+				// Replies to the dashboard's own parameter requests stay local
+				if !policy.LocalParams.AllowUplink(msg.GetID()) {
+					f.filteredCount.Add(1)
+					continue
+				}
+
+				// Maintenance mode keeps bench work off the fleet dashboard; the session heartbeat still goes out
+				if maintenance.Mode.Active() {
+					f.maintenanceCount.Add(1)
+					continue
+				}
+
+				// Filter table (forwarding.filters): deny rules and per-rule rate caps, first match wins
+				switch policy.Filters.Check(sysID, e.ComponentID(), msg.GetID()) {
+				case policy.FilterDenied:
+					f.filteredCount.Add(1)
+					continue
+				case policy.FilterRateLimited:
+					f.shapedCount.Add(1)
+					continue
+				}
+
+				// Apply the cloud rate profile, then router-negotiated rate caps and bandwidth budget
+				if !policy.Profiles.Allow(policy.ConsumerCloud, msg.GetID(), e.ComponentID()) {
+					f.shapedCount.Add(1)
+					continue
+				}
+				if !policy.Shaper.Allow(msg.GetID(), func() int { return mavlink_custom.FrameSize(e.Frame) }) {
+					f.shapedCount.Add(1)
+					continue
+				}
+
+				// Until the router confirms our endpoint, only priority messages go out
+				if !isPriorityMessage(msg.GetID()) && f.registration.Holding(now) {
+					f.heldCount.Add(1)
+					continue
+				}
+
+				// Configured transformations (datum correction, home redaction) for the cloud
+				upFrame, ok := transform.Global.Frame(policy.ConsumerCloud, e.Frame)
+				if !ok {
+					f.filteredCount.Add(1)
+					continue
+				}
+				// MAVLink 2 signing (signing.enabled): the router authenticates what we forward
+				upFrame, err := signing.Global.Sign(upFrame)
+				if err != nil {
+					logger.Debug("[SIGNING] Not forwarding %s: %v", msgTypeName, err)
+					f.filteredCount.Add(1)
+					continue
 				}
 
 				// Forward message to server
@@ -796,111 +1137,159 @@ func (f *Forwarder) receiveAndForward() {
 
 				if !healthy {
 					metrics.Global.IncFailedUnhealthy(msgTypeName)
-				} else {
-					// Forward the raw frame directly to preserve original message
-					if err := f.senderNode.WriteFrameAll(e.Frame); err != nil {
-						logger.Error("[FORWARD] Failed to forward frame %s: %v", msgTypeName, err)
+				} else if f.batcher != nil && f.batcher.Wants(msg.GetID()) {
+					if err := f.batcher.Add(upFrame); err != nil {
+						logger.Error("[BATCH] Failed to batch frame %s: %v", msgTypeName, err)
 						metrics.Global.IncFailedSend(msgTypeName)
 					} else {
-						f.txCount.Add(1)
-						logger.Debug("[FORWARD] %s", msgTypeName)
+						f.batchedCount.Add(1)
 						metrics.Global.IncSent(msgTypeName)
+						metrics.Global.AddBand(metrics.BandFCToServer, mavlink_custom.FrameSize(upFrame))
+					}
+				} else {
+					// Forward the raw frame to preserve original message (queued so a stalled uplink can't block the listener)
+					fr := upFrame
+					queued := f.toServer.Enqueue(queuedWrite{
+						name:     msgTypeName,
+						msgID:    msg.GetID(),
+						source:   sysID,
+						received: now,
+						write:    func() error { return f.sender.WriteFrameAll(fr) },
+						done: func(err error) {
+							if err != nil {
+								logger.Error("[FORWARD] Failed to forward frame %s: %v", msgTypeName, err)
+								metrics.Global.IncFailedSend(msgTypeName)
+							} else {
+								f.txCount.Add(1)
+								logger.Debug("[FORWARD] %s", msgTypeName)
+								metrics.Global.IncSent(msgTypeName)
+								metrics.Global.AddBand(metrics.BandFCToServer, mavlink_custom.FrameSize(fr))
+							}
+						},
+					}, isPriorityMessage(msg.GetID()))
+					if !queued {
+						metrics.Global.IncFailedSend(msgTypeName)
 					}
 				}
 
 			case *gomavlib.EventChannelOpen:
 				logger.Info("[LISTENER] Channel opened: %v", e.Channel)
+				channels.Global.Opened("listener", e.Channel)
 			case *gomavlib.EventChannelClose:
 				logger.Warn("[LISTENER] Channel closed: %v", e.Channel)
+				channels.Global.Closed("listener", e.Channel)
 			case *gomavlib.EventParseError:
 				logger.Debug("[LISTENER] Parse error: %v", e.Error)
+				parseerrors.Global.Record("listener", e.Channel, e.Error)
 			}
 		}
 	}
 }
 
-// parseMessageVerbose provides detailed field-by-field parsing of MAVLink messages from server
-func (f *Forwarder) parseMessageVerbose(msg interface{}, sysID uint8) {
+// parseMessageVerbose provides detailed field-by-field parsing of MAVLink messages from
+// the server or the Pixhawk (source)
+func (f *Forwarder) parseMessageVerbose(msg interface{}, sysID uint8, source string) {
 	switch m := msg.(type) {
 	case *common.MessageHeartbeat:
-		logger.Info("[VERBOSE] HEARTBEAT from server (SysID: %d) - Type=%d, Autopilot=%d, BaseMode=%d, CustomMode=%d, SystemStatus=%d",
-			sysID, m.Type, m.Autopilot, m.BaseMode, m.CustomMode, m.SystemStatus)
+		logger.Info("[VERBOSE] HEARTBEAT from %s (SysID: %d) - Type=%d, Autopilot=%d, BaseMode=%d, CustomMode=%d, SystemStatus=%d",
+			source, sysID, m.Type, m.Autopilot, m.BaseMode, m.CustomMode, m.SystemStatus)
 
 	case *common.MessageSysStatus:
-		logger.Info("[VERBOSE] SYS_STATUS from server - Load=%d%%, Battery=%dmV (%d%%), CommDrop=%d, CommErrors=%d, ErrorsCount1=%d",
-			m.Load/10, m.VoltageBattery, m.BatteryRemaining,
+		logger.Info("[VERBOSE] SYS_STATUS from %s - Load=%d%%, Battery=%dmV (%d%%), CommDrop=%d, CommErrors=%d, ErrorsCount1=%d",
+			source, m.Load/10, m.VoltageBattery, m.BatteryRemaining,
 			m.DropRateComm, m.ErrorsComm, m.ErrorsCount1)
 
 	case *common.MessageGpsRawInt:
-		logger.Info("[VERBOSE] GPS_RAW_INT from server - Fix=%d, Lat=%.7f, Lon=%.7f, Alt=%d cm, Sats=%d, HDOP=%d, VDOP=%d, Vel=%d cm/s, Cog=%d°",
-			m.FixType, float64(m.Lat)/1e7, float64(m.Lon)/1e7, m.Alt, m.SatellitesVisible,
+		logger.Info("[VERBOSE] GPS_RAW_INT from %s - Fix=%d, Lat=%.7f, Lon=%.7f, Alt=%d cm, Sats=%d, HDOP=%d, VDOP=%d, Vel=%d cm/s, Cog=%d°",
+			source, m.FixType, float64(m.Lat)/1e7, float64(m.Lon)/1e7, m.Alt, m.SatellitesVisible,
 			m.Eph, m.Epv, m.Vel, m.Cog)
 
 	case *common.MessageAttitude:
-		logger.Info("[VERBOSE] ATTITUDE from server - Roll=%.2f rad, Pitch=%.2f rad, Yaw=%.2f rad, RollSpeed=%.2f rad/s, PitchSpeed=%.2f rad/s, YawSpeed=%.2f rad/s, TimeBootMs=%d ms",
-			m.Roll, m.Pitch, m.Yaw, m.Rollspeed, m.Pitchspeed, m.Yawspeed, m.TimeBootMs)
+		logger.Info("[VERBOSE] ATTITUDE from %s - Roll=%.2f rad, Pitch=%.2f rad, Yaw=%.2f rad, RollSpeed=%.2f rad/s, PitchSpeed=%.2f rad/s, YawSpeed=%.2f rad/s, TimeBootMs=%d ms",
+			source, m.Roll, m.Pitch, m.Yaw, m.Rollspeed, m.Pitchspeed, m.Yawspeed, m.TimeBootMs)
 
 	case *common.MessageLocalPositionNed:
-		logger.Info("[VERBOSE] LOCAL_POSITION_NED from server - X=%.2f m, Y=%.2f m, Z=%.2f m, Vx=%.2f m/s, Vy=%.2f m/s, Vz=%.2f m/s, TimeBootMs=%d ms",
-			m.X, m.Y, m.Z, m.Vx, m.Vy, m.Vz, m.TimeBootMs)
+		logger.Info("[VERBOSE] LOCAL_POSITION_NED from %s - X=%.2f m, Y=%.2f m, Z=%.2f m, Vx=%.2f m/s, Vy=%.2f m/s, Vz=%.2f m/s, TimeBootMs=%d ms",
+			source, m.X, m.Y, m.Z, m.Vx, m.Vy, m.Vz, m.TimeBootMs)
 
 	case *common.MessageGlobalPositionInt:
-		logger.Info("[VERBOSE] GLOBAL_POSITION_INT from server - Lat=%.7f°, Lon=%.7f°, Alt=%d mm, RelAlt=%d mm, Vx=%d cm/s, Vy=%d cm/s, Vz=%d cm/s, Hdg=%d cdeg, TimeBootMs=%d ms",
-			float64(m.Lat)/1e7, float64(m.Lon)/1e7, m.Alt, m.RelativeAlt, m.Vx, m.Vy, m.Vz, m.Hdg, m.TimeBootMs)
+		logger.Info("[VERBOSE] GLOBAL_POSITION_INT from %s - Lat=%.7f°, Lon=%.7f°, Alt=%d mm, RelAlt=%d mm, Vx=%d cm/s, Vy=%d cm/s, Vz=%d cm/s, Hdg=%d cdeg, TimeBootMs=%d ms",
+			source, float64(m.Lat)/1e7, float64(m.Lon)/1e7, m.Alt, m.RelativeAlt, m.Vx, m.Vy, m.Vz, m.Hdg, m.TimeBootMs)
 
 	case *common.MessageVfrHud:
-		logger.Info("[VERBOSE] VFR_HUD from server - Airspeed=%.2f m/s, Groundspeed=%.2f m/s, Heading=%d°, Throttle=%d%%, Altitude=%.2f m, ClimbRate=%.2f m/s",
-			m.Airspeed, m.Groundspeed, m.Heading, m.Throttle, m.Alt, m.Climb)
+		logger.Info("[VERBOSE] VFR_HUD from %s - Airspeed=%.2f m/s, Groundspeed=%.2f m/s, Heading=%d°, Throttle=%d%%, Altitude=%.2f m, ClimbRate=%.2f m/s",
+			source, m.Airspeed, m.Groundspeed, m.Heading, m.Throttle, m.Alt, m.Climb)
 
 	case *common.MessageBatteryStatus:
-		logger.Info("[VERBOSE] BATTERY_STATUS from server - BatType=%d, ID=%d, BatFunction=%d, Temperature=%d°C, Voltage=%d mV, CurrentBattery=%d mA, ChargeState=%d, Cells=[%d, %d, %d, %d, %d, %d] mV",
-			m.Type, m.Id, m.BatteryFunction, m.Temperature, m.Voltages[0], m.CurrentBattery, m.ChargeState,
+		logger.Info("[VERBOSE] BATTERY_STATUS from %s - BatType=%d, ID=%d, BatFunction=%d, Temperature=%d°C, Voltage=%d mV, CurrentBattery=%d mA, ChargeState=%d, Cells=[%d, %d, %d, %d, %d, %d] mV",
+			source, m.Type, m.Id, m.BatteryFunction, m.Temperature, m.Voltages[0], m.CurrentBattery, m.ChargeState,
 			m.Voltages[0], m.Voltages[1], m.Voltages[2], m.Voltages[3], m.Voltages[4], m.Voltages[5])
 
 	case *common.MessageServoOutputRaw:
-		logger.Info("[VERBOSE] SERVO_OUTPUT_RAW from server - ServoPort=%d, TimeUsec=%d us, Outputs=[%d, %d, %d, %d, %d, %d, %d, %d]",
-			m.Port, m.TimeUsec, m.Servo1Raw, m.Servo2Raw, m.Servo3Raw, m.Servo4Raw, m.Servo5Raw, m.Servo6Raw, m.Servo7Raw, m.Servo8Raw)
+		logger.Info("[VERBOSE] SERVO_OUTPUT_RAW from %s - ServoPort=%d, TimeUsec=%d us, Outputs=[%d, %d, %d, %d, %d, %d, %d, %d]",
+			source, m.Port, m.TimeUsec, m.Servo1Raw, m.Servo2Raw, m.Servo3Raw, m.Servo4Raw, m.Servo5Raw, m.Servo6Raw, m.Servo7Raw, m.Servo8Raw)
 
 	case *common.MessageMissionItem:
-		logger.Info("[VERBOSE] MISSION_ITEM from server - Seq=%d, Frame=%d, Command=%d, Current=%d, Autocontinue=%d, Params=[%.2f, %.2f, %.2f, %.2f], X=%.7f, Y=%.7f, Z=%.2f",
-			m.Seq, m.Frame, m.Command, m.Current, m.Autocontinue,
+		logger.Info("[VERBOSE] MISSION_ITEM from %s - Seq=%d, Frame=%d, Command=%d, Current=%d, Autocontinue=%d, Params=[%.2f, %.2f, %.2f, %.2f], X=%.7f, Y=%.7f, Z=%.2f",
+			source, m.Seq, m.Frame, m.Command, m.Current, m.Autocontinue,
 			m.Param1, m.Param2, m.Param3, m.Param4, m.X, m.Y, m.Z)
 
 	case *common.MessageParamValue:
-		logger.Info("[VERBOSE] PARAM_VALUE from server - ParamId=%s, ParamValue=%.2f, ParamType=%d, ParamCount=%d, ParamIndex=%d",
-			m.ParamId, m.ParamValue, m.ParamType, m.ParamCount, m.ParamIndex)
+		logger.Info("[VERBOSE] PARAM_VALUE from %s - ParamId=%s, ParamValue=%.2f, ParamType=%d, ParamCount=%d, ParamIndex=%d",
+			source, m.ParamId, m.ParamValue, m.ParamType, m.ParamCount, m.ParamIndex)
 
 	case *common.MessageCommandAck:
-		logger.Info("[VERBOSE] COMMAND_ACK from server - Command=%d, Result=%d, Progress=%d, ResultParam2=%d",
-			m.Command, m.Result, m.Progress, m.ResultParam2)
+		logger.Info("[VERBOSE] COMMAND_ACK from %s - Command=%d, Result=%d, Progress=%d, ResultParam2=%d",
+			source, m.Command, m.Result, m.Progress, m.ResultParam2)
 
 	case *common.MessageSetMode:
-		logger.Info("[VERBOSE] SET_MODE from server - TargetSystem=%d, BaseMode=%d, CustomMode=%d",
-			m.TargetSystem, m.BaseMode, m.CustomMode)
+		logger.Info("[VERBOSE] SET_MODE from %s - TargetSystem=%d, BaseMode=%d, CustomMode=%d",
+			source, m.TargetSystem, m.BaseMode, m.CustomMode)
 
 	case *common.MessageManualControl:
-		logger.Info("[VERBOSE] MANUAL_CONTROL from server - Target=%d, Pitch=%d, Roll=%d, Throttle=%d, Yaw=%d, Buttons=%d",
-			m.Target, m.X, m.Y, m.Z, m.R, m.Buttons)
+		logger.Info("[VERBOSE] MANUAL_CONTROL from %s - Target=%d, Pitch=%d, Roll=%d, Throttle=%d, Yaw=%d, Buttons=%d",
+			source, m.Target, m.X, m.Y, m.Z, m.R, m.Buttons)
 
 	default:
-		// Generic message - just log the type name
 		msgTypeName := getMessageTypeName(msg)
-		logger.Debug("[VERBOSE] %s from server (SysID: %d) - message type not specifically parsed",
-			msgTypeName, sysID)
+		if f.verboseMessages != nil {
+			// Explicitly listed - dump all fields
+			logger.Info("[VERBOSE] %s from %s (SysID: %d) - %+v", msgTypeName, source, sysID, msg)
+			return
+		}
+		// Generic message - just log the type name
+		logger.Debug("[VERBOSE] %s from %s (SysID: %d) - message type not specifically parsed",
+			msgTypeName, source, sysID)
 	}
 }
 
 // receiveFromServer listens for incoming MAVLink messages from server and logs them
-func (f *Forwarder) receiveFromServer() {
-	eventCh := f.senderNode.Events()
+func (f *Forwarder) receiveFromServer(gen uint64) {
+	eventCh := f.sender.Events()
 	receivedCount := 0
-	lastLogTime := time.Now()
+	lastLogTime := f.clock.Now()
 
+	// A handler stuck on one event isn't helped by a new socket - retire the loop instead
+	wd := watchdog.Global.Register("receiveFromServer", f.serverLoop.restart)
+	defer watchdog.Global.Unregister(wd)
+	beat := f.clock.NewTicker(watchdog.BeatInterval)
+	defer beat.Stop()
+
+	var handling *stageEvent
 	for {
+		if f.serverLoop.retired(gen) {
+			return
+		}
+		wd.Beat()
+		f.serverStage.end(handling)
+		handling = nil
 		select {
 		case <-f.stopCh:
 			return
+		case <-beat.C():
+			f.serverStage.publish(f.sender.Depth())
 		case event := <-eventCh:
+			handling = f.serverStage.begin(event)
 			switch e := event.(type) {
 			case *gomavlib.EventFrame:
 				// Received a MAVLink message from server
@@ -909,46 +1298,128 @@ func (f *Forwarder) receiveFromServer() {
 				sysID := e.SystemID()
 				receivedCount++
 
+				// With signing, only authenticated frames count as server traffic (see signing.require_incoming)
+				if err := signing.Global.Verify(e.Frame); err != nil {
+					f.badSignatureCount.Add(1)
+					logger.Debug("[SIGNING] Dropped %s from server (SysID: %d): %v", msgTypeName, sysID, err)
+					continue
+				}
+				if f.serverLinkFrame(msg, sysID) {
+					continue
+				}
+
+				if isUnknownMessage(msg) && !f.passUnknown(metrics.BandServerToFC, msg.GetID()) {
+					continue
+				}
+
 				// Log statistics every 1000 messages or every 10 seconds
-				now := time.Now()
+				now := f.clock.Now()
 				if receivedCount%1000 == 0 || now.Sub(lastLogTime) > 10*time.Second {
 					logger.Info("[SERVER->PIXHAWK] Received %d messages from server", receivedCount)
 					lastLogTime = now
 				}
 
 				// Verbose mode: parse and log detailed message fields
-				if f.verboseMode {
-					f.parseMessageVerbose(msg, sysID)
+				if f.verboseFor(msg, "server") {
+					f.parseMessageVerbose(msg, sysID, "server")
 				}
 
 				logger.Debug("[SERVER->PIXHAWK] %s (SysID: %d)", msgTypeName, sysID)
 
-				// Forward message to Pixhawk
-				if err := f.listenerNode.WriteMessageAll(msg); err != nil {
-					logger.Error("[SERVER->PIXHAWK] Failed to forward %s: %v", msgTypeName, err)
-				} else {
-					logger.Debug("[SERVER->PIXHAWK] Forwarded %s", msgTypeName)
+				// Parameters are frozen in flight; tell the cloud GCS why its write had no effect
+				if err := checkParamFreeze(msg, safety.SourceServer); err != nil {
+					f.Inject(&common.MessageStatustext{Severity: common.MAV_SEVERITY_WARNING, Text: "Bridge: params frozen while armed"}, false, true)
+					continue
+				}
+
+				// Stick input from the cloud only passes while remote piloting is enabled
+				if !control.Global.AllowStickInput(msg.GetID()) {
+					continue
 				}
 
+				// Arbitrate against a local GCS controlling the same vehicle
+				if !control.Global.AllowCloud(msg.GetID()) {
+					logger.Debug("[CONTROL] %s from server held back - local GCS has control", msgTypeName)
+					continue
+				}
+
+				// Forward message to Pixhawk (queued so a stalled FC link can't delay later commands)
+				policy.LocalParams.CloudRequest(msg.GetID())
+				write := func() error {
+					echo.Global.Record(msg)
+					return f.writeFC(msg)
+				}
+				if isUnknownMessage(msg) {
+					// Re-encoding needs the definition; send the frame as received instead
+					fr := e.Frame
+					write = func() error {
+						echo.Global.Record(msg)
+						return f.writeFCFrame(fr)
+					}
+				}
+				f.toFC.Enqueue(queuedWrite{
+					name:     msgTypeName,
+					msgID:    msg.GetID(),
+					source:   sysID,
+					received: now,
+					write:    write,
+					done: func(err error) {
+						if err != nil {
+							logger.Error("[SERVER->PIXHAWK] Failed to forward %s: %v", msgTypeName, err)
+						} else {
+							logger.Debug("[SERVER->PIXHAWK] Forwarded %s", msgTypeName)
+							metrics.Global.AddBand(metrics.BandServerToFC, mavlink_custom.MessageSize(msg))
+						}
+					},
+				}, isPriorityMessage(msg.GetID()))
+
 			case *gomavlib.EventChannelOpen:
 				logger.Info("[SENDER] Channel opened: %v", e.Channel)
+				channels.Global.Opened("sender", e.Channel)
 			case *gomavlib.EventChannelClose:
 				logger.Warn("[SENDER] Channel closed: %v", e.Channel)
+				channels.Global.Closed("sender", e.Channel)
 			case *gomavlib.EventParseError:
 				logger.Debug("[SENDER] Parse error: %v", e.Error)
+				parseerrors.Global.Record("sender", e.Channel, e.Error)
 			}
 		}
 	}
 }
+
+// requestAutopilotVersion asks the FC for AUTOPILOT_VERSION, whose UID pins it (see fcpin)
+func (f *Forwarder) requestAutopilotVersion(sysID uint8) {
+	msg := &common.MessageCommandLong{
+		TargetSystem:    sysID,
+		TargetComponent: 1,
+		Command:         common.MAV_CMD_REQUEST_MESSAGE,
+		Param1:          float32((&common.MessageAutopilotVersion{}).GetID()),
+	}
+	f.toFC.Enqueue(queuedWrite{
+		name:     "COMMAND_LONG",
+		msgID:    msg.GetID(),
+		received: f.clock.Now(),
+		write: func() error {
+			echo.Global.Record(msg)
+			return f.writeFC(msg)
+		},
+		done: func(err error) {
+			if err != nil {
+				logger.Warn("[FCPIN] Failed to request AUTOPILOT_VERSION: %v", err)
+			}
+		},
+	}, false)
+}
+
 func (f *Forwarder) sendHeartbeat() {
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := f.clock.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-f.stopCh:
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			msg := &common.MessageHeartbeat{
 				Type:         6, // MAV_TYPE_GCS
 				Autopilot:    0, // MAV_AUTOPILOT_INVALID
@@ -956,7 +1427,7 @@ func (f *Forwarder) sendHeartbeat() {
 				CustomMode:   0,
 				SystemStatus: 4, // MAV_STATE_ACTIVE
 			}
-			if err := f.listenerNode.WriteMessageAll(msg); err != nil {
+			if err := f.writeFC(msg); err != nil {
 				logger.Error("[HEARTBEAT] Failed to send GCS heartbeat: %v", err)
 			} else {
 				logger.Debug("[HEARTBEAT] Sent GCS heartbeat")
@@ -965,6 +1436,48 @@ func (f *Forwarder) sendHeartbeat() {
 	}
 }
 
+// serverLinkFrame takes the link-local frames from the router (SESSION_HEARTBEAT_ACK,
+// SESSION_HEARTBEAT), which are never forwarded to Pixhawk, and reports whether msg
+// was one. Any frame that passes its checks proves the link alive (dead-man switch,
+// failover); a replayed heartbeat or a stale ACK does not.
+func (f *Forwarder) serverLinkFrame(msg message.Message, sysID uint8) bool {
+	handled := true
+	if ack, ok := msg.(*mavlink_custom.MessageSessionHeartbeatAck); ok {
+		// The router confirms our endpoint
+		if err := f.registration.Ack(ack, f.clock.Now()); err != nil {
+			logger.Warn("[MAVLINK_HB] Ignored SESSION_HEARTBEAT_ACK from SysID %d: %v", sysID, err)
+			return true
+		}
+	} else if seq, ok := mavlink_custom.SessionHeartbeatSequence(msg); ok {
+		if !f.heartbeatGuard.Accept(sysID, seq) {
+			logger.Warn("[REPLAY] Rejected replayed SESSION_HEARTBEAT seq=%d from SysID %d", seq, sysID)
+			metrics.Global.AddLog("WARN", fmt.Sprintf("Rejected replayed SESSION_HEARTBEAT seq=%d", seq))
+			return true
+		}
+	} else {
+		handled = false
+	}
+
+	deadman.Global.Touch()
+	if f.failover != nil {
+		f.failover.Heard(f.clock.Now())
+	}
+	return handled
+}
+
+// sessionTokenBytes converts the hex session token to the 32 bytes SESSION_HEARTBEAT carries
+func sessionTokenBytes(tokenHex string) ([32]byte, bool) {
+	var token [32]byte
+	if len(tokenHex) < 64 {
+		return token, false
+	}
+	// Decode first 64 hex chars to 32 bytes
+	for i := 0; i < 32; i++ {
+		fmt.Sscanf(tokenHex[i*2:i*2+2], "%02x", &token[i])
+	}
+	return token, true
+}
+
 // sendMavlinkSessionHeartbeat sends SESSION_HEARTBEAT messages with session token to sync IP:Port
 // This ensures the UDP source port matches between heartbeat and MAVLink data
 func (f *Forwarder) sendMavlinkSessionHeartbeat() {
@@ -973,55 +1486,44 @@ func (f *Forwarder) sendMavlinkSessionHeartbeat() {
 		return
 	}
 
-	// Get frequency from config (Hz)
-	frequency := f.cfg.Auth.SessionHeartbeatFrequency
-	if frequency <= 0 {
-		frequency = 1.0 // Default 1 Hz
-	}
-	interval := time.Duration(1.0 / frequency * float64(time.Second))
-	ticker := time.NewTicker(interval)
+	ticker := f.clock.NewTicker(sessionHeartbeatInterval(f.cfg))
 	defer ticker.Stop()
 
-	logger.Info("[MAVLINK_HB] Starting MAVLink session heartbeat at %.1f Hz", frequency)
+	logger.Info("[MAVLINK_HB] Starting MAVLink session heartbeat at %.1f Hz", 1/sessionHeartbeatInterval(f.cfg).Seconds())
 	firstSent := false
-	sequence := uint16(0)
 
 	for {
 		select {
 		case <-f.stopCh:
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			tokenHex, expiresAt := f.authClient.GetSessionInfo()
 			if tokenHex == "" {
 				continue // No session yet
 			}
 
-			// Convert hex token to binary (32 bytes)
-			var tokenBinary [32]byte
-			if len(tokenHex) >= 64 {
-				// Decode first 64 hex chars to 32 bytes
-				for i := 0; i < 32; i++ {
-					fmt.Sscanf(tokenHex[i*2:i*2+2], "%02x", &tokenBinary[i])
-				}
-			} else {
+			tokenBinary, ok := sessionTokenBytes(tokenHex)
+			if !ok {
 				logger.Warn("[MAVLINK_HB] Token too short: %d chars", len(tokenHex))
 				continue
 			}
 
-			// Create custom SESSION_HEARTBEAT message
-			msg := &mavlink_custom.MessageSessionHeartbeat{
-				Token:     tokenBinary,
-				ExpiresAt: uint32(expiresAt.Unix()),
-				Sequence:  sequence,
+			// Create custom SESSION_HEARTBEAT message (sequence is monotonic across restarts)
+			sequence, err := f.authClient.NextHeartbeatSequence()
+			if err != nil {
+				logger.Error("[MAVLINK_HB] Session heartbeat not sent: %v", err)
+				continue
 			}
-			sequence++
+			msg := mavlink_custom.NewSessionHeartbeat(tokenBinary, expiresAt, sequence)
 
-			// Send via senderNode (to server) - this ensures same source port as MAVLink data
-			if err := f.senderNode.WriteMessageAll(msg); err != nil {
+			// Send via sender uplink (to server) - this ensures same source port as MAVLink data
+			if err := f.sender.WriteMessageAll(msg); err != nil {
 				logger.Error("[MAVLINK_HB] Failed to send session heartbeat: %v", err)
 			} else {
+				metrics.Global.AddBand(metrics.BandBridge, mavlink_custom.MessageSize(msg))
+				f.registration.Sent(sequence, f.clock.Now())
 				if !firstSent {
-					logger.Info("[MAVLINK_HB] ✓ First MAVLink session heartbeat sent (ID 42000)")
+					logger.Info("[MAVLINK_HB] ✓ First MAVLink session heartbeat sent (ID %d)", msg.GetID())
 					firstSent = true
 					// Signal that heartbeat is ready
 					select {
@@ -1029,18 +1531,27 @@ func (f *Forwarder) sendMavlinkSessionHeartbeat() {
 					default:
 					}
 				}
-				logger.Debug("[MAVLINK_HB] Sent session heartbeat #%d", sequence-1)
+				logger.Debug("[MAVLINK_HB] Sent session heartbeat #%d", sequence)
 			}
 		}
 	}
 }
 
+// sessionHeartbeatInterval is the SESSION_HEARTBEAT period from auth.session_heartbeat_frequency
+func sessionHeartbeatInterval(cfg *config.Config) time.Duration {
+	frequency := cfg.Auth.SessionHeartbeatFrequency
+	if frequency <= 0 {
+		frequency = 1.0 // Default 1 Hz
+	}
+	return time.Duration(1.0 / frequency * float64(time.Second))
+}
+
 func (f *Forwarder) monitorIPChange() {
-	ticker := time.NewTicker(5 * time.Second)
+	ticker := f.clock.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
 	checkIP := func() {
-		currentIP, err := getLocalIP()
+		currentIP, err := getLocalIP(f.dialer)
 		if err != nil {
 			logger.Debug("[IP_MONITOR] Failed to get IP: %v", err)
 			return
@@ -1061,34 +1572,7 @@ func (f *Forwarder) monitorIPChange() {
 			metrics.Global.SetIP(currentIP)
 			f.previousIP = currentIP
 
-			// Close current sender node
-			f.senderNode.Close()
-
-			// Create new sender node with custom dialect (including SESSION_HEARTBEAT)
-			node, err := gomavlib.NewNode(gomavlib.NodeConf{
-				Endpoints: []gomavlib.EndpointConf{
-					gomavlib.EndpointUDPClient{Address: f.cfg.GetAddress()},
-				},
-				Dialect:     mavlink_custom.GetCombinedDialect(),
-				OutVersion:  gomavlib.V2,
-				OutSystemID: 1, // Placeholder: will use actual Pixhawk sys_id from web.GetPixhawkSystemID() when available
-			})
-			if err != nil {
-				logger.Error("[IP_MONITOR] Error recreating sender node: %v", err)
-				return
-			}
-
-			f.senderNode = node
-			logger.Info("[IP_MONITOR] Sender reconnected on IP: %s", currentIP)
-
-			// Also force TCP auth client to reconnect immediately
-			if f.authClient != nil {
-				f.authClient.ForceReconnect()
-			}
-
-			f.mu.Lock()
-			f.isHealthy = true
-			f.mu.Unlock()
+			f.reconnect.Trigger("IP changed to " + currentIP)
 		}
 	}
 
@@ -1096,9 +1580,7 @@ func (f *Forwarder) monitorIPChange() {
 		select {
 		case <-f.stopCh:
 			return
-		case <-ticker.C:
-			checkIP()
-		case <-f.forceCheckCh:
+		case <-ticker.C():
 			checkIP()
 		}
 	}
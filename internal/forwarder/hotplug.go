@@ -0,0 +1,300 @@
+package forwarder
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3"
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/config"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+	"DroneBridge/internal/signing"
+)
+
+const (
+	hotplugDebounce     = 2 * time.Second // Let a link settle (DHCP, auto_setup) before re-checking
+	hotplugPollInterval = 5 * time.Second // Re-check interval where link events are unavailable
+)
+
+// fcBroadcast is a broadcast endpoint towards the FC added after startup, when
+// the ethernet link came up later than the bridge (e.g. switch power sequencing)
+type fcBroadcast struct {
+	node        *gomavlib.Node
+	iface       string
+	localIP     string
+	broadcastIP string
+}
+
+// ethernetWatch is the state of the hotplug watcher goroutine
+type ethernetWatch struct {
+	f          *Forwarder
+	baseline   string          // Ethernet IP at startup, already served by the listener node
+	setupTried map[string]bool // Interfaces auto_setup was attempted on since they came up
+}
+
+// watchEthernet adds the broadcast endpoint when the FC ethernet link comes up
+// after startup and removes it when the link goes away, so the FC is found
+// without restarting the process
+func (f *Forwarder) watchEthernet() {
+	w := &ethernetWatch{f: f, setupTried: make(map[string]bool)}
+	w.baseline, _, _, _ = findEthernet(f.cfg)
+	logger.Info("[HOTPLUG] Watching ethernet links (startup IP: %q)", w.baseline)
+
+	changed := make(chan struct{}, 1)
+	var poll <-chan time.Time
+	if err := watchLinks(changed, f.stopCh); err != nil {
+		logger.Info("[HOTPLUG] %v - polling every %v", err, hotplugPollInterval)
+		ticker := f.clock.NewTicker(hotplugPollInterval)
+		defer ticker.Stop()
+		poll = ticker.C()
+	}
+
+	var settle <-chan time.Time
+	for {
+		select {
+		case <-f.stopCh:
+			f.removeHotplug("stopping")
+			return
+		case <-changed:
+			settle = f.clock.After(hotplugDebounce)
+		case <-settle:
+			settle = nil
+			w.check()
+		case <-poll:
+			w.check()
+		}
+	}
+}
+
+// check compares the ethernet link with the current broadcast endpoints
+func (w *ethernetWatch) check() {
+	f := w.f
+	localIP, broadcastIP, ifaceName, bare := findEthernet(f.cfg)
+
+	// A matching interface came up without an address: let auto_setup configure it once
+	if bare == "" {
+		w.setupTried = make(map[string]bool)
+	} else if localIP == "" && f.cfg.Ethernet.AutoSetup && !w.setupTried[bare] {
+		w.setupTried[bare] = true
+		logger.Info("[HOTPLUG] Ethernet %s is up without an address, running auto_setup", bare)
+		if ip, bcast, name, err := getEthernetIP(f.cfg); err == nil {
+			localIP, broadcastIP, ifaceName = ip, bcast, name
+		}
+	}
+
+	f.hotplugMu.RLock()
+	current := f.hotplug
+	f.hotplugMu.RUnlock()
+
+	switch {
+	case localIP == "" || localIP == w.baseline:
+		if current != nil {
+			f.removeHotplug(fmt.Sprintf("ethernet %s went down", current.iface))
+		}
+	case current != nil && current.localIP == localIP && current.broadcastIP == broadcastIP:
+		// Unchanged
+	default:
+		if current != nil {
+			f.removeHotplug(fmt.Sprintf("ethernet %s changed address", current.iface))
+		}
+		f.addHotplug(localIP, broadcastIP, ifaceName)
+	}
+}
+
+// addHotplug creates a broadcast-only node on the interface. Its GCS
+// heartbeats announce the bridge, so the FC answers as during startup discovery.
+func (f *Forwarder) addHotplug(localIP, broadcastIP, ifaceName string) {
+	node, err := gomavlib.NewNode(gomavlib.NodeConf{
+		Endpoints: []gomavlib.EndpointConf{
+			gomavlib.EndpointUDPBroadcast{
+				BroadcastAddress: fmt.Sprintf("%s:%d", broadcastIP, f.cfg.Network.LocalListenPort),
+				LocalAddress:     fmt.Sprintf("%s:%d", localIP, f.cfg.Network.BroadcastPort),
+			},
+		},
+		Dialect:     mavlink_custom.GetCombinedDialect(),
+		OutVersion:  gomavlib.V2,
+		OutSystemID: 255, // Ground station ID, like the listener
+	})
+	if err != nil {
+		logger.Warn("[HOTPLUG] Failed to add UDP Broadcast on %s: %v", ifaceName, err)
+		return
+	}
+
+	hp := &fcBroadcast{node: node, iface: ifaceName, localIP: localIP, broadcastIP: broadcastIP}
+	f.hotplugMu.Lock()
+	f.hotplug = hp
+	f.hotplugMu.Unlock()
+	go f.pumpEvents(hp.node.Events(), hp)
+
+	logger.Info("[HOTPLUG] Ethernet %s up: UDP Broadcast enabled, Local=%s:%d, Broadcast=%s:%d",
+		ifaceName, localIP, f.cfg.Network.BroadcastPort, broadcastIP, f.cfg.Network.LocalListenPort)
+	select {
+	case <-f.pixhawkConnected:
+	default:
+		logger.Info("[HOTPLUG] 🔎 Pixhawk not connected yet, rediscovering via Broadcast on %s...", ifaceName)
+	}
+}
+
+// removeHotplug closes the hotplugged broadcast endpoint, if any
+func (f *Forwarder) removeHotplug(reason string) {
+	f.hotplugMu.Lock()
+	hp := f.hotplug
+	f.hotplug = nil
+	f.hotplugMu.Unlock()
+	if hp == nil {
+		return
+	}
+	hp.node.Close()
+	logger.Info("[HOTPLUG] UDP Broadcast on %s removed (%s)", hp.iface, reason)
+}
+
+// pumpEvents feeds a node's events into fcEvents until the node is closed.
+// For a hotplugged node it also reports the first FC heartbeat.
+func (f *Forwarder) pumpEvents(events chan gomavlib.Event, hp *fcBroadcast) {
+	found := hp == nil
+	for event := range events {
+		if e, ok := event.(*gomavlib.EventFrame); ok && !found {
+			if hb, ok := e.Message().(*common.MessageHeartbeat); ok && e.SystemID() != 255 &&
+				hb.Type != common.MAV_TYPE_GCS && hb.Autopilot != common.MAV_AUTOPILOT_INVALID {
+				found = true
+				logger.Info("[HOTPLUG] ✅ Found Pixhawk on %s (System ID: %d) from channel: %s", hp.iface, e.SystemID(), e.Channel)
+			}
+		}
+		if !f.fcStage.relay(f.fcEvents, event, f.stopCh) {
+			return
+		}
+	}
+}
+
+// writeFC writes a message to every endpoint towards the FC
+func (f *Forwarder) writeFC(msg message.Message) error {
+	if signing.Global.SignsFC() {
+		fr, err := signing.Global.SignMessage(msg, f.listenerNode.OutSystemID, f.listenerNode.OutComponentID, uint8(f.fcSignedSeq.Add(1)))
+		if err != nil {
+			return err
+		}
+		return f.writeFCFrameAll(fr)
+	}
+	if err := f.listenerNode.WriteMessageAll(msg); err != nil {
+		return err
+	}
+	f.hotplugMu.RLock()
+	defer f.hotplugMu.RUnlock()
+	if f.hotplug != nil {
+		return f.hotplug.node.WriteMessageAll(msg)
+	}
+	return nil
+}
+
+// writeFCFrame writes a frame as received to every endpoint towards the FC,
+// re-signed with our key when signing.sign_to_fc is set
+func (f *Forwarder) writeFCFrame(fr frame.Frame) error {
+	if signing.Global.SignsFC() {
+		var err error
+		if fr, err = signing.Global.Sign(fr); err != nil {
+			return err
+		}
+	}
+	return f.writeFCFrameAll(fr)
+}
+
+func (f *Forwarder) writeFCFrameAll(fr frame.Frame) error {
+	if err := f.listenerNode.WriteFrameAll(fr); err != nil {
+		return err
+	}
+	f.hotplugMu.RLock()
+	defer f.hotplugMu.RUnlock()
+	if f.hotplug != nil {
+		return f.hotplug.node.WriteFrameAll(fr)
+	}
+	return nil
+}
+
+// WriteToFC sends a message to the FC over the listener and any hotplugged
+// broadcast endpoint. Used by the web API so its commands follow hotplug too.
+func (f *Forwarder) WriteToFC(msg message.Message) error {
+	return f.writeFC(msg)
+}
+
+// findEthernet looks up the ethernet link the same way getEthernetIP does,
+// without logging or auto_setup. bare is a matching interface that is up but
+// lacks the address (a candidate for auto_setup).
+func findEthernet(cfg *config.Config) (localIP, broadcastIP, ifaceName, bare string) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", "", "", ""
+	}
+
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		addrs, _ := iface.Addrs()
+		var ipv4 *net.IPNet
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() == nil {
+				continue
+			}
+			if cfg.Ethernet.LocalIP != "" {
+				if ipNet.IP.String() == cfg.Ethernet.LocalIP {
+					return cfg.Ethernet.LocalIP, configuredBroadcast(cfg), iface.Name, ""
+				}
+				continue
+			}
+			if ipv4 == nil {
+				ipv4 = ipNet
+			}
+		}
+
+		if !ethernetNameMatches(cfg, iface.Name) {
+			continue
+		}
+		if ipv4 == nil || cfg.Ethernet.LocalIP != "" {
+			if bare == "" {
+				bare = iface.Name
+			}
+			continue
+		}
+		ip := ipv4.IP.To4()
+		broadcast := make(net.IP, len(ip))
+		for i := range ip {
+			broadcast[i] = ip[i] | ^ipv4.Mask[len(ipv4.Mask)-len(ip)+i]
+		}
+		return ip.String(), broadcast.String(), iface.Name, ""
+	}
+	return "", "", "", bare
+}
+
+// ethernetNameMatches applies the interface selection of getEthernetIP: the
+// configured interface exactly, else the platform's ethernet name prefixes
+func ethernetNameMatches(cfg *config.Config, name string) bool {
+	if cfg.Ethernet.Interface != "" {
+		return name == cfg.Ethernet.Interface
+	}
+	for _, pattern := range defaultEthPatterns {
+		if strings.HasPrefix(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// configuredBroadcast is the broadcast address for ethernet.local_ip
+func configuredBroadcast(cfg *config.Config) string {
+	if cfg.Ethernet.BroadcastIP != "" {
+		return cfg.Ethernet.BroadcastIP
+	}
+	// Same /24 assumption as getEthernetIP
+	ipParts := strings.Split(cfg.Ethernet.LocalIP, ".")
+	if len(ipParts) == 4 {
+		return fmt.Sprintf("%s.%s.%s.255", ipParts[0], ipParts[1], ipParts[2])
+	}
+	return ""
+}
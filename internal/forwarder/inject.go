@@ -0,0 +1,77 @@
+package forwarder
+
+import (
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/echo"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/maintenance"
+	"DroneBridge/internal/mavlink_custom"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/policy"
+)
+
+// Inject sends a message generated on the companion (e.g. an external sensor
+// reading) to the flight controller and/or the router. Server-bound messages
+// go through the same forwarding policy and write queue as Pixhawk telemetry.
+func (f *Forwarder) Inject(msg message.Message, toFC, toServer bool) {
+	name := getMessageTypeName(msg)
+	now := f.clock.Now()
+
+	if toFC {
+		f.toFC.Enqueue(queuedWrite{
+			name:     name,
+			msgID:    msg.GetID(),
+			received: now,
+			write: func() error {
+				echo.Global.Record(msg)
+				return f.writeFC(msg)
+			},
+			done: func(err error) {
+				if err != nil {
+					logger.Error("[INJECT->PIXHAWK] Failed to send %s: %v", name, err)
+				} else {
+					metrics.Global.AddBand(metrics.BandBridge, mavlink_custom.MessageSize(msg))
+				}
+			},
+		}, false)
+	}
+
+	if !toServer {
+		return
+	}
+	if !policy.Global.Allowed(msg.GetID()) {
+		f.filteredCount.Add(1)
+		return
+	}
+	if maintenance.Mode.Active() {
+		f.maintenanceCount.Add(1)
+		return
+	}
+	f.mu.RLock()
+	healthy := f.isHealthy
+	f.mu.RUnlock()
+	if !healthy {
+		metrics.Global.IncFailedUnhealthy(name)
+		return
+	}
+	queued := f.toServer.Enqueue(queuedWrite{
+		name:     name,
+		msgID:    msg.GetID(),
+		received: now,
+		write:    func() error { return f.sender.WriteMessageAll(msg) },
+		done: func(err error) {
+			if err != nil {
+				logger.Error("[INJECT] Failed to send %s: %v", name, err)
+				metrics.Global.IncFailedSend(name)
+			} else {
+				f.txCount.Add(1)
+				metrics.Global.IncSent(name)
+				metrics.Global.AddBand(metrics.BandBridge, mavlink_custom.MessageSize(msg))
+			}
+		},
+	}, false)
+	if !queued {
+		metrics.Global.IncFailedSend(name)
+	}
+}
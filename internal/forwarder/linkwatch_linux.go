@@ -0,0 +1,62 @@
+package forwarder
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// Netlink multicast groups (linux/rtnetlink.h), not exported by syscall
+const (
+	rtmgrpLink       = 0x1
+	rtmgrpIPv4IfAddr = 0x10
+)
+
+// watchLinks signals changed on every netlink link or IPv4 address event, so
+// an interface coming up is noticed immediately. The socket read times out
+// every second to notice stop. Returns an error if netlink is unavailable.
+func watchLinks(changed chan<- struct{}, stop <-chan struct{}) error {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return fmt.Errorf("netlink socket: %w", err)
+	}
+	addr := &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: rtmgrpLink | rtmgrpIPv4IfAddr,
+	}
+	if err := syscall.Bind(fd, addr); err != nil {
+		syscall.Close(fd)
+		return fmt.Errorf("netlink bind: %w", err)
+	}
+	timeout := syscall.NsecToTimeval(time.Second.Nanoseconds())
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &timeout); err != nil {
+		syscall.Close(fd)
+		return fmt.Errorf("netlink timeout: %w", err)
+	}
+
+	go func() {
+		defer syscall.Close(fd)
+		buf := make([]byte, 8192)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			// The content does not matter: any event triggers a full re-check
+			_, _, err := syscall.Recvfrom(fd, buf, 0)
+			if err == syscall.EAGAIN || err == syscall.EINTR {
+				continue
+			}
+			if err != nil {
+				// ENOBUFS means events were dropped; re-check, but don't spin on a broken socket
+				time.Sleep(time.Second)
+			}
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package forwarder
+
+import "fmt"
+
+// watchLinks has no link events on this platform; the caller polls instead
+func watchLinks(changed chan<- struct{}, stop <-chan struct{}) error {
+	return fmt.Errorf("link events are not supported on this platform")
+}
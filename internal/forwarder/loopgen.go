@@ -0,0 +1,57 @@
+package forwarder
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// loopRestarter runs a consumer loop in generations. When the watchdog finds the
+// loop stuck in a handler it starts the next generation; the stuck one notices
+// it was retired and exits as soon as its handler returns. Only one retired
+// generation may be outstanding: further restarts are refused until it has
+// exited, so a handler that never returns can't pile up loops.
+type loopRestarter struct {
+	name    string
+	run     func(gen uint64)
+	gen     atomic.Uint64 // Generation that should be running
+	running atomic.Int32  // Generations that have not exited yet
+}
+
+func newLoopRestarter(name string, run func(gen uint64)) *loopRestarter {
+	return &loopRestarter{name: name, run: run}
+}
+
+// start runs the first generation
+func (r *loopRestarter) start() {
+	r.running.Add(1)
+	go r.loop(r.gen.Load())
+}
+
+// restart retires the running generation and starts a new one (watchdog callback)
+func (r *loopRestarter) restart() {
+	if !r.running.CompareAndSwap(1, 2) {
+		logger.Warn("[FORWARDER] %s stalled again while a retired generation is still stuck - not restarting", r.name)
+		return
+	}
+	gen := r.gen.Add(1)
+	logger.Warn("[FORWARDER] Restarting %s (generation %d)", r.name, gen)
+	metrics.Global.AddLog("WARN", fmt.Sprintf("Restarting stalled %s loop", r.name))
+	go r.loop(gen)
+}
+
+// retired reports whether gen has been replaced by a newer generation
+func (r *loopRestarter) retired(gen uint64) bool {
+	if r.gen.Load() == gen {
+		return false
+	}
+	logger.Info("[FORWARDER] Stalled %s (generation %d) exited", r.name, gen)
+	return true
+}
+
+func (r *loopRestarter) loop(gen uint64) {
+	defer r.running.Add(-1)
+	r.run(gen)
+}
@@ -0,0 +1,58 @@
+package forwarder
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoopRestarterKeepsOneRetiredGeneration(t *testing.T) {
+	started := make(chan uint64, 4)
+	release := make(chan struct{})
+	var r *loopRestarter
+	r = newLoopRestarter("test", func(gen uint64) {
+		started <- gen
+		<-release // Stuck in a handler until released
+		r.retired(gen)
+	})
+
+	waitStarted := func(want uint64) {
+		t.Helper()
+		select {
+		case gen := <-started:
+			if gen != want {
+				t.Fatalf("started generation %d, want %d", gen, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("generation %d never started", want)
+		}
+	}
+
+	r.start()
+	waitStarted(0)
+
+	r.restart()
+	waitStarted(1)
+	if !r.retired(0) || r.retired(1) {
+		t.Fatal("generation 0 should be retired and 1 current")
+	}
+
+	// Generation 0 is still stuck: another restart must not add a third loop
+	r.restart()
+	select {
+	case gen := <-started:
+		t.Fatalf("generation %d started while a retired one was still running", gen)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if n := r.running.Load(); n != 2 {
+		t.Fatalf("running = %d, want 2", n)
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for r.running.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("loops did not exit (running = %d)", r.running.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,27 @@
+package forwarder
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// defaultEthPatterns are the ethernet interface name prefixes tried during auto-detect
+// (macOS names wired and USB adapters en0, en1, ...)
+var defaultEthPatterns = []string{"en"}
+
+// setupInterfaceIP adds an IP alias to an interface using ifconfig
+func setupInterfaceIP(ifaceName, ipAddr, subnet string) error {
+	if subnet == "" {
+		subnet = "24"
+	}
+	mask, err := prefixToMask(subnet)
+	if err != nil {
+		return err
+	}
+	// Adding an existing alias succeeds silently, so no "already exists" check is needed
+	output, err := exec.Command("sudo", "-n", "ifconfig", ifaceName, "alias", ipAddr, "netmask", mask).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to add IP: %s - %v", string(output), err)
+	}
+	return nil
+}
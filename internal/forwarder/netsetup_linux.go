@@ -0,0 +1,30 @@
+package forwarder
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"DroneBridge/internal/logger"
+)
+
+// defaultEthPatterns are the ethernet interface name prefixes tried during auto-detect
+var defaultEthPatterns = []string{"eth", "end", "enp", "eno"}
+
+// setupInterfaceIP configures an IP address on an interface using ip command
+func setupInterfaceIP(ifaceName, ipAddr, subnet string) error {
+	if subnet == "" {
+		subnet = "24"
+	}
+	cmd := exec.Command("sudo", "ip", "addr", "add", fmt.Sprintf("%s/%s", ipAddr, subnet), "dev", ifaceName)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// Check if IP already exists
+		if strings.Contains(string(output), "File exists") {
+			logger.Debug("[NETWORK] IP %s already exists on %s", ipAddr, ifaceName)
+			return nil
+		}
+		return fmt.Errorf("failed to add IP: %s - %v", string(output), err)
+	}
+	return nil
+}
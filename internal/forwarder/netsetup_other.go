@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package forwarder
+
+import "fmt"
+
+// defaultEthPatterns are the ethernet interface name prefixes tried during auto-detect
+var defaultEthPatterns = []string{"eth", "em", "igb", "re"}
+
+// setupInterfaceIP is not implemented on this platform; configure the address manually
+func setupInterfaceIP(ifaceName, ipAddr, subnet string) error {
+	return fmt.Errorf("automatic IP setup is not supported on this platform, configure %s on %s manually", ipAddr, ifaceName)
+}
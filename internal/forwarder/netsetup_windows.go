@@ -0,0 +1,35 @@
+package forwarder
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"DroneBridge/internal/logger"
+)
+
+// defaultEthPatterns are the ethernet interface name prefixes tried during auto-detect
+// (Windows names adapters "Ethernet", "Ethernet 2", ...)
+var defaultEthPatterns = []string{"Ethernet"}
+
+// setupInterfaceIP adds an IP address to an adapter using netsh (requires an elevated prompt)
+func setupInterfaceIP(ifaceName, ipAddr, subnet string) error {
+	if subnet == "" {
+		subnet = "24"
+	}
+	mask, err := prefixToMask(subnet)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("netsh", "interface", "ipv4", "add", "address",
+		fmt.Sprintf("name=%s", ifaceName), ipAddr, mask)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "already exists") {
+			logger.Debug("[NETWORK] IP %s already exists on %s", ipAddr, ifaceName)
+			return nil
+		}
+		return fmt.Errorf("failed to add IP: %s - %v", strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
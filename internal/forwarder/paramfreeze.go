@@ -0,0 +1,19 @@
+package forwarder
+
+import (
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/safety"
+)
+
+// checkParamFreeze applies the in-flight parameter freeze to PARAM_SET and PARAM_EXT_SET
+func checkParamFreeze(msg message.Message, source string) error {
+	switch m := msg.(type) {
+	case *common.MessageParamSet:
+		return safety.Global.CheckParamSet(m.ParamId, source)
+	case *common.MessageParamExtSet:
+		return safety.Global.CheckParamSet(m.ParamId, source)
+	}
+	return nil
+}
@@ -0,0 +1,376 @@
+package forwarder
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/quic-go/quic-go"
+
+	"DroneBridge/config"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// quicALPN is the ALPN protocol the router's QUIC listener accepts for MAVLink
+const quicALPN = "dronebridge-mavlink"
+
+const (
+	quicDialTimeout  = 10 * time.Second
+	quicProbeTimeout = 5 * time.Second // Validation of a new path after a route change
+	quicMaxBackoff   = 30 * time.Second
+	quicRxBuffer     = 256 // Received datagrams waiting for the sender node
+)
+
+// quicTLSConfig builds the TLS config of the QUIC uplink from network.quic
+func quicTLSConfig(q config.QUICConfig) (*tls.Config, error) {
+	tlsConf := &tls.Config{
+		ServerName: q.ServerName,
+		NextProtos: []string{quicALPN},
+		MinVersion: tls.VersionTLS13,
+	}
+	if q.CAFile != "" {
+		pem, err := os.ReadFile(q.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read network.quic.ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", q.CAFile)
+		}
+		tlsConf.RootCAs = pool
+	}
+	if q.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(q.CertFile, q.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load network.quic client certificate: %w", err)
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConf, nil
+}
+
+// quicSocket is one local UDP socket a QUIC connection uses as a path
+type quicSocket struct {
+	udp *net.UDPConn
+	tr  *quic.Transport
+}
+
+func newQUICSocket() (*quicSocket, error) {
+	udp, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, err
+	}
+	return &quicSocket{udp: udp, tr: &quic.Transport{Conn: udp}}, nil
+}
+
+func (s *quicSocket) close() {
+	s.tr.Close()
+	s.udp.Close()
+}
+
+// quicLink carries the uplink over QUIC: every MAVLink frame is one QUIC
+// datagram (unreliable, so a lost frame never holds up the next one) protected
+// by TLS 1.3. The link outlives sender nodes: it dials in the background,
+// redials when the connection dies and moves the connection to a fresh socket
+// when the route changes, so an IP change needs no new node or handshake.
+type quicLink struct {
+	tlsConf *tls.Config
+	conf    *quic.Config
+
+	ctx    context.Context // Cancelled by Close
+	cancel context.CancelFunc
+	wake   chan struct{} // Redial without waiting for the backoff
+
+	mu      sync.Mutex
+	addr    string
+	conn    *quic.Conn
+	sockets []*quicSocket // Paths of conn; replaced ones stay open until it ends
+
+	rx      chan []byte // Received datagrams for the active endpoint
+	dropped atomic.Uint64
+}
+
+// newQUICLink starts connecting to addr in the background
+func newQUICLink(addr string, tlsConf *tls.Config) *quicLink {
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &quicLink{
+		tlsConf: tlsConf,
+		conf: &quic.Config{
+			EnableDatagrams:      true,
+			HandshakeIdleTimeout: quicDialTimeout,
+			MaxIdleTimeout:       30 * time.Second,
+			KeepAlivePeriod:      10 * time.Second,
+		},
+		ctx:    ctx,
+		cancel: cancel,
+		wake:   make(chan struct{}, 1),
+		addr:   addr,
+		rx:     make(chan []byte, quicRxBuffer),
+	}
+	go l.run()
+	return l
+}
+
+// run keeps a connection up until Close
+func (l *quicLink) run() {
+	var backoff time.Duration
+	for {
+		conn, err := l.dial()
+		if err != nil {
+			if l.ctx.Err() != nil {
+				return
+			}
+			if backoff = 2 * backoff; backoff == 0 {
+				backoff = time.Second
+			} else if backoff > quicMaxBackoff {
+				backoff = quicMaxBackoff
+			}
+			logger.Warn("[QUIC] Failed to connect to %s: %v - retrying in %v", l.address(), err, backoff)
+			select {
+			case <-l.ctx.Done():
+				return
+			case <-l.wake:
+			case <-time.After(backoff):
+			}
+			continue
+		}
+		backoff = 0
+		logger.Info("[QUIC] Connected to %s from %s", conn.RemoteAddr(), conn.LocalAddr())
+		metrics.Global.AddLog("INFO", fmt.Sprintf("QUIC uplink connected to %s", conn.RemoteAddr()))
+
+		l.receive(conn)
+		l.release(conn)
+		if l.ctx.Err() != nil {
+			return
+		}
+		logger.Warn("[QUIC] Connection to %s lost: %v", conn.RemoteAddr(), context.Cause(conn.Context()))
+		metrics.Global.AddLog("WARN", fmt.Sprintf("QUIC uplink lost: %v", context.Cause(conn.Context())))
+	}
+}
+
+// dial opens a connection on a new socket
+func (l *quicLink) dial() (*quic.Conn, error) {
+	addr := l.address()
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	tlsConf := l.tlsConf.Clone()
+	if tlsConf.ServerName == "" {
+		tlsConf.ServerName, _, _ = net.SplitHostPort(addr)
+	}
+
+	sock, err := newQUICSocket()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(l.ctx, quicDialTimeout)
+	defer cancel()
+	conn, err := sock.tr.Dial(ctx, raddr, tlsConf, l.conf)
+	if err != nil {
+		sock.close()
+		return nil, err
+	}
+	if !conn.ConnectionState().SupportsDatagrams {
+		conn.CloseWithError(0, "datagrams required")
+		sock.close()
+		return nil, errors.New("router does not accept QUIC datagrams")
+	}
+
+	l.mu.Lock()
+	l.conn = conn
+	l.sockets = []*quicSocket{sock}
+	l.mu.Unlock()
+	return conn, nil
+}
+
+// receive hands datagrams to the endpoint until the connection ends
+func (l *quicLink) receive(conn *quic.Conn) {
+	for {
+		data, err := conn.ReceiveDatagram(l.ctx)
+		if err != nil {
+			return
+		}
+		select {
+		case l.rx <- data:
+		case <-l.ctx.Done():
+			return
+		}
+	}
+}
+
+// release forgets a dead connection and closes its sockets
+func (l *quicLink) release(conn *quic.Conn) {
+	l.mu.Lock()
+	var sockets []*quicSocket
+	if l.conn == conn {
+		sockets = l.sockets
+		l.conn = nil
+		l.sockets = nil
+	}
+	l.mu.Unlock()
+	conn.CloseWithError(0, "")
+	for _, s := range sockets {
+		s.close()
+	}
+}
+
+func (l *quicLink) address() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.addr
+}
+
+// redial drops the current connection (if any) and connects again right away
+func (l *quicLink) redial(conn *quic.Conn, why string) {
+	if conn != nil {
+		conn.CloseWithError(0, why)
+	}
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// follow moves the link to addr over the current route. A new router address
+// means a new connection; otherwise the connection is migrated to a fresh
+// socket (a new path from the new local address) and only redialed when the
+// router does not validate the path.
+func (l *quicLink) follow(addr string) {
+	l.mu.Lock()
+	conn := l.conn
+	if addr != l.addr {
+		l.addr = addr
+		l.mu.Unlock()
+		logger.Info("[QUIC] Router address changed to %s - reconnecting", addr)
+		l.redial(conn, "router address changed")
+		return
+	}
+	l.mu.Unlock()
+	if conn == nil {
+		l.redial(nil, "")
+		return
+	}
+
+	if err := l.migrate(conn); err != nil {
+		logger.Warn("[QUIC] Connection migration failed: %v - reconnecting", err)
+		l.redial(conn, "migration failed")
+		return
+	}
+	logger.Info("[QUIC] Connection migrated to a new path")
+	metrics.Global.AddLog("INFO", "QUIC uplink migrated to the new network path")
+}
+
+// migrate probes a path from a new socket and switches conn to it
+func (l *quicLink) migrate(conn *quic.Conn) error {
+	sock, err := newQUICSocket()
+	if err != nil {
+		return err
+	}
+	path, err := conn.AddPath(sock.tr)
+	if err != nil {
+		sock.close()
+		return err
+	}
+	ctx, cancel := context.WithTimeout(l.ctx, quicProbeTimeout)
+	defer cancel()
+	if err := path.Probe(ctx); err != nil {
+		path.Close()
+		sock.close()
+		return fmt.Errorf("path not validated: %w", err)
+	}
+	if err := path.Switch(); err != nil {
+		path.Close()
+		sock.close()
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn != conn {
+		sock.close() // Connection ended meanwhile
+		return nil
+	}
+	l.sockets = append(l.sockets, sock)
+	return nil
+}
+
+// send writes one frame as a datagram; frames are dropped while disconnected,
+// like plain UDP
+func (l *quicLink) send(p []byte) {
+	l.mu.Lock()
+	conn := l.conn
+	l.mu.Unlock()
+	if conn == nil {
+		l.dropped.Add(1)
+		return
+	}
+	if err := conn.SendDatagram(p); err != nil {
+		if n := l.dropped.Add(1); n%100 == 1 {
+			logger.Debug("[QUIC] Dropped frame: %v (%d dropped)", err, n)
+		}
+	}
+}
+
+// endpoint returns a stream for one sender node
+func (l *quicLink) endpoint() *quicEndpoint {
+	return &quicEndpoint{link: l, closed: make(chan struct{})}
+}
+
+// Close ends the connection and stops redialing
+func (l *quicLink) Close() {
+	l.cancel()
+	l.mu.Lock()
+	conn := l.conn
+	l.mu.Unlock()
+	if conn != nil {
+		conn.CloseWithError(0, "closing")
+	}
+}
+
+// quicEndpoint is a sender node's view of the link (a gomavlib custom
+// endpoint). Closing it when the node is replaced leaves the link up.
+type quicEndpoint struct {
+	link    *quicLink
+	pending []byte // Rest of a datagram larger than the last Read buffer
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func (e *quicEndpoint) Read(p []byte) (int, error) {
+	if len(e.pending) == 0 {
+		select {
+		case data := <-e.link.rx:
+			e.pending = data
+		case <-e.closed:
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, e.pending)
+	e.pending = e.pending[n:]
+	return n, nil
+}
+
+func (e *quicEndpoint) Write(p []byte) (int, error) {
+	select {
+	case <-e.closed:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+	e.link.send(p)
+	return len(p), nil
+}
+
+func (e *quicEndpoint) Close() error {
+	e.once.Do(func() { close(e.closed) })
+	return nil
+}
@@ -0,0 +1,242 @@
+package forwarder
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// Reconnect coordinator states (exposed in metrics as reconnect_state)
+const (
+	reconnectIdle      = "connected"
+	reconnectDetected  = "detected"
+	reconnectPaused    = "paused"
+	reconnectRebinding = "rebinding"
+	reconnectReauth    = "reauth"
+	reconnectResuming  = "resuming"
+	reconnectBackoff   = "backoff"
+)
+
+// reconnectCoordinator is the single owner of reconnection. IP monitoring and the
+// auth client only report triggers; the coordinator runs one sequence at a time:
+// detect → pause forwarding → rebind uplink → re-auth/refresh → resume.
+// The uplink is only rebound when the local address or route to the router
+// changed (or the sender node must be rebuilt); otherwise only the session is
+// refreshed and forwarding never pauses. Over QUIC a route change migrates the
+// connection instead.
+type reconnectCoordinator struct {
+	f         *Forwarder
+	triggerCh chan string
+
+	mu      sync.Mutex
+	pending string // Reasons coalesced while a sequence is running
+	rebind  bool   // A pending trigger needs a new sender node regardless of the route
+	backoff time.Duration
+
+	boundAddr string // Router address the sender node was built for
+	boundIP   string // Local address of the route to it at that time
+}
+
+func newReconnectCoordinator(f *Forwarder) *reconnectCoordinator {
+	metrics.Global.SetReconnectState(reconnectIdle, "")
+	return &reconnectCoordinator{
+		f:         f,
+		triggerCh: make(chan string, 1),
+	}
+}
+
+// Trigger requests a reconnect sequence. Triggers arriving while one is
+// already queued are coalesced into it.
+func (c *reconnectCoordinator) Trigger(reason string) {
+	c.trigger(reason, false)
+}
+
+// TriggerRebind requests a reconnect sequence that rebuilds the sender node even
+// if the route is unchanged (new dialect, stuck node)
+func (c *reconnectCoordinator) TriggerRebind(reason string) {
+	c.trigger(reason, true)
+}
+
+func (c *reconnectCoordinator) trigger(reason string, rebind bool) {
+	c.mu.Lock()
+	c.rebind = c.rebind || rebind
+	if c.pending != "" {
+		c.pending += "; " + reason
+		c.mu.Unlock()
+		return
+	}
+	c.pending = reason
+	c.mu.Unlock()
+
+	select {
+	case c.triggerCh <- reason:
+	default:
+	}
+}
+
+// TriggerReconnect requests a full reconnect sequence, rebuilding the sender node
+func (f *Forwarder) TriggerReconnect(reason string) {
+	f.reconnect.TriggerRebind(reason)
+}
+
+// Retarget points the uplink at a new router address and reconnects
+func (f *Forwarder) Retarget(host string, port int) {
+	f.retarget(host, port, "router address changed")
+}
+
+func (f *Forwarder) retarget(host string, port int, reason string) {
+	f.mu.Lock()
+	f.cfg.Network.TargetHost = host
+	f.cfg.Network.TargetPort = port
+	f.mu.Unlock()
+	if f.batcher != nil {
+		f.batcher.SetAddress(fmt.Sprintf("%s:%d", host, port))
+	}
+	f.reconnect.Trigger(reason)
+}
+
+// run processes triggers until stopCh is closed
+func (c *reconnectCoordinator) run(stopCh <-chan struct{}) {
+	c.boundAddr, c.boundIP = c.route()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-c.triggerCh:
+			c.mu.Lock()
+			reason := c.pending
+			rebind := c.rebind
+			c.pending = ""
+			c.rebind = false
+			c.mu.Unlock()
+
+			if err := c.sequence(reason, rebind); err != nil {
+				c.retryLater(stopCh, reason, rebind, err)
+			} else {
+				c.backoff = 0
+			}
+		}
+	}
+}
+
+// route returns the router address and the local address the OS currently
+// routes to it from (empty when there is no route)
+func (c *reconnectCoordinator) route() (addr, localIP string) {
+	c.f.mu.RLock()
+	addr = c.f.cfg.GetAddress()
+	c.f.mu.RUnlock()
+	conn, err := c.f.dialer.Dial("udp", addr)
+	if err != nil {
+		return addr, ""
+	}
+	defer conn.Close()
+	if udp, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		localIP = udp.IP.String()
+	}
+	return addr, localIP
+}
+
+// sequence runs one reconnect cycle; the uplink is rebound when forced or when
+// the router address or the local address routed to it changed
+func (c *reconnectCoordinator) sequence(reason string, rebind bool) error {
+	f := c.f
+	start := f.clock.Now()
+
+	c.setState(reconnectDetected, reason)
+	logger.Warn("[RECONNECT] Reconnect triggered: %s", reason)
+	metrics.Global.AddLog("WARN", "Reconnect triggered: "+reason)
+
+	addr, localIP := c.route()
+	routeChanged := addr != c.boundAddr || localIP != c.boundIP
+	if routeChanged && f.quic != nil {
+		// QUIC moves the connection to the new path itself; the node stays
+		c.setState(reconnectRebinding, reason)
+		f.quic.follow(addr)
+		c.boundAddr, c.boundIP = addr, localIP
+		routeChanged = false
+		f.registration.Reset() // New path or new router: the router has to confirm it again
+	}
+	if rebind || routeChanged {
+		if !rebind {
+			logger.Info("[RECONNECT] Route to %s changed (%s -> %s) - rebinding uplink", addr, c.boundIP, localIP)
+		}
+		c.setState(reconnectPaused, reason)
+		f.mu.Lock()
+		f.isHealthy = false
+		f.mu.Unlock()
+
+		c.setState(reconnectRebinding, reason)
+		err := f.sender.Migrate(func() (*gomavlib.Node, error) {
+			return newSenderNode(f.cfg.GetAddress(), f.senderSysID, f.quic)
+		})
+		if err != nil {
+			return fmt.Errorf("rebind failed: %w", err)
+		}
+		c.boundAddr, c.boundIP = addr, localIP
+		if f.batcher != nil {
+			f.batcher.Reconnect()
+		}
+		f.registration.Reset() // New source port: the router has to confirm it again
+	} else {
+		logger.Info("[RECONNECT] Route to %s unchanged (via %s) - keeping the uplink socket", addr, localIP)
+	}
+
+	f.mu.RLock()
+	authClient := f.authClient
+	f.mu.RUnlock()
+	if authClient != nil {
+		c.setState(reconnectReauth, reason)
+		authClient.ForceReconnect()
+		if err := authClient.TriggerSessionRecovery(); err != nil {
+			return fmt.Errorf("session recovery failed: %w", err)
+		}
+	}
+
+	c.setState(reconnectResuming, reason)
+	f.mu.Lock()
+	f.isHealthy = true
+	f.mu.Unlock()
+
+	c.setState(reconnectIdle, "")
+	logger.Info("[RECONNECT] ✅ Reconnected in %v", f.clock.Since(start).Round(time.Millisecond))
+	metrics.Global.AddLog("INFO", fmt.Sprintf("Reconnected in %v", f.clock.Since(start).Round(time.Millisecond)))
+	return nil
+}
+
+// retryLater re-runs the sequence with exponential backoff. Forwarding resumes on
+// the current socket meanwhile: frames may still get through, and a failed
+// rebind leaves the previous node in place.
+func (c *reconnectCoordinator) retryLater(stopCh <-chan struct{}, reason string, rebind bool, err error) {
+	c.f.mu.Lock()
+	c.f.isHealthy = true
+	c.f.mu.Unlock()
+	if c.backoff == 0 {
+		c.backoff = 2 * time.Second
+	} else if c.backoff < 30*time.Second {
+		c.backoff *= 2
+	}
+	c.setState(reconnectBackoff, reason)
+	logger.Error("[RECONNECT] ❌ %v - retrying in %v", err, c.backoff)
+	metrics.Global.AddLog("ERROR", fmt.Sprintf("Reconnect failed: %v", err))
+
+	delay := c.backoff
+	go func() {
+		select {
+		case <-stopCh:
+		case <-c.f.clock.After(delay):
+			c.trigger("retry: "+strings.TrimPrefix(reason, "retry: "), rebind)
+		}
+	}()
+}
+
+func (c *reconnectCoordinator) setState(state, reason string) {
+	metrics.Global.SetReconnectState(state, reason)
+	logger.Debug("[RECONNECT] State -> %s", state)
+}
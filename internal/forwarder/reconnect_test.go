@@ -0,0 +1,173 @@
+package forwarder
+
+import (
+	"crypto/tls"
+	"errors"
+	"testing"
+	"time"
+
+	"DroneBridge/config"
+	"DroneBridge/internal/mavlink_custom"
+	"DroneBridge/internal/testutil"
+)
+
+// newTestForwarder builds the parts of a forwarder the reconnect coordinator uses.
+// The sender node targets a local UDP port; nothing has to listen on it.
+func newTestForwarder(t *testing.T, clk *testutil.FakeClock, d *testutil.FakeDialer) *Forwarder {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Network.TargetHost = "127.0.0.1"
+	cfg.Network.TargetPort = 14550
+
+	node, err := newSenderNode(cfg.GetAddress(), 1, nil)
+	if err != nil {
+		t.Fatalf("newSenderNode: %v", err)
+	}
+	f := &Forwarder{
+		cfg:          cfg,
+		clock:        clk,
+		dialer:       d,
+		senderSysID:  1,
+		registration: newUplinkRegistration(time.Second, time.Second),
+		sender:       newUplink(node, newEventStage("sender", "receiveFromServer")),
+	}
+	f.sender.clock = clk
+	f.reconnect = newReconnectCoordinator(f)
+	t.Cleanup(f.sender.Close)
+
+	// route() dials the router each time - close the server ends so the fake never fills up
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		for {
+			select {
+			case conn := <-d.Accepted():
+				conn.Close()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return f
+}
+
+func TestReconnectRebindsOnlyWhenNeeded(t *testing.T) {
+	tests := []struct {
+		name       string
+		newLocalIP string // Local address of the route after the trigger ("" = unchanged)
+		rebind     bool
+		wantRebind bool
+	}{
+		{"route unchanged", "", false, false},
+		{"local address changed", "10.0.0.3", false, true},
+		{"forced rebind", "", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := testutil.NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+			d := testutil.NewFakeDialer("10.0.0.2")
+			f := newTestForwarder(t, clk, d)
+			c := f.reconnect
+			c.boundAddr, c.boundIP = c.route()
+
+			if tt.newLocalIP != "" {
+				d.SetLocalIP(tt.newLocalIP)
+			}
+			before := f.sender.node
+			if err := c.sequence("test", tt.rebind); err != nil {
+				t.Fatalf("sequence: %v", err)
+			}
+
+			if rebound := f.sender.node != before; rebound != tt.wantRebind {
+				t.Errorf("rebound = %v, want %v", rebound, tt.wantRebind)
+			}
+			wantIP := "10.0.0.2"
+			if tt.newLocalIP != "" {
+				wantIP = tt.newLocalIP
+			}
+			if c.boundIP != wantIP {
+				t.Errorf("boundIP = %q, want %q", c.boundIP, wantIP)
+			}
+			if !f.isHealthy {
+				t.Error("forwarding not resumed after the sequence")
+			}
+		})
+	}
+}
+
+func TestReconnectQUICResetsRegistration(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	d := testutil.NewFakeDialer("10.0.0.2")
+	f := newTestForwarder(t, clk, d)
+	f.quic = newQUICLink(f.cfg.GetAddress(), &tls.Config{InsecureSkipVerify: true, NextProtos: []string{quicALPN}})
+	t.Cleanup(f.quic.Close)
+	c := f.reconnect
+	c.boundAddr, c.boundIP = c.route()
+
+	f.registration.Sent(7, clk.Now())
+	if err := f.registration.Ack(&mavlink_custom.MessageSessionHeartbeatAck{Sequence: 7}, clk.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	d.SetLocalIP("10.0.0.3")
+	before := f.sender.node
+	if err := c.sequence("test", false); err != nil {
+		t.Fatalf("sequence: %v", err)
+	}
+	if f.sender.node != before {
+		t.Error("QUIC uplink rebound the sender node, want the connection to follow the route")
+	}
+	f.registration.mu.Lock()
+	state := f.registration.state
+	f.registration.mu.Unlock()
+	if state != "" {
+		t.Errorf("registration = %q after the path changed, want it reset", state)
+	}
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	f := newTestForwarder(t, clk, testutil.NewFakeDialer("10.0.0.2"))
+	c := f.reconnect
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	for _, want := range []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 32 * time.Second, 32 * time.Second} {
+		c.retryLater(stopCh, "link lost", true, errors.New("rebind failed"))
+		if c.backoff != want {
+			t.Fatalf("backoff = %v, want %v", c.backoff, want)
+		}
+		if !f.isHealthy {
+			t.Fatal("forwarding paused during backoff")
+		}
+
+		// Let the retry goroutine register its timer before moving the clock
+		deadline := time.Now().Add(time.Second)
+		for clk.Waiters() == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("retry timer never registered")
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		clk.Advance(want - time.Millisecond)
+		select {
+		case <-c.triggerCh:
+			t.Fatalf("retry fired before %v", want)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		clk.Advance(time.Millisecond)
+		select {
+		case <-c.triggerCh:
+		case <-time.After(time.Second):
+			t.Fatalf("retry did not fire after %v", want)
+		}
+		c.mu.Lock()
+		if c.pending != "retry: link lost" || !c.rebind {
+			t.Fatalf("retry queued (%q, rebind=%v), want (\"retry: link lost\", rebind=true)", c.pending, c.rebind)
+		}
+		c.pending, c.rebind = "", false
+		c.mu.Unlock()
+	}
+}
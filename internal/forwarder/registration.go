@@ -0,0 +1,115 @@
+package forwarder
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+	"DroneBridge/internal/metrics"
+)
+
+// Uplink registration states (exposed in metrics as uplink_state)
+const (
+	uplinkBlind      = "blind"      // Sending without confirmation from the router
+	uplinkRegistered = "registered" // Router acknowledged our SESSION_HEARTBEAT
+	uplinkRejected   = "rejected"   // Router refused our session token
+)
+
+// ackWindow is how far behind the latest sent sequence an ACK may be
+const ackWindow = 32
+
+// uplinkRegistration tracks whether the router has registered the UDP endpoint
+// our telemetry comes from. After startup or a rebind, only priority messages
+// are forwarded until it does (for up to wait), so a full-rate stream isn't sent
+// into an unregistered path. Routers that never acknowledge get full rate once
+// wait has passed.
+type uplinkRegistration struct {
+	mu        sync.Mutex
+	wait      time.Duration // How long to hold back full-rate forwarding
+	lapse     time.Duration // Registration expires without an ACK for this long
+	state     string        // Empty until the first heartbeat after startup or a rebind
+	since     time.Time     // Last state change
+	holdUntil time.Time     // Zero once registered
+	lastAck   time.Time
+	lastSent  uint16
+}
+
+func newUplinkRegistration(wait, heartbeatInterval time.Duration) *uplinkRegistration {
+	lapse := 5 * heartbeatInterval
+	if lapse < 3*time.Second {
+		lapse = 3 * time.Second
+	}
+	return &uplinkRegistration{wait: wait, lapse: lapse}
+}
+
+// Sent records a SESSION_HEARTBEAT and expires a registration whose ACKs stopped
+func (u *uplinkRegistration) Sent(seq uint16, now time.Time) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.lastSent = seq
+	switch {
+	case u.state == "":
+		u.holdUntil = now.Add(u.wait)
+		u.setState(uplinkBlind, now)
+	case u.state == uplinkRegistered && now.Sub(u.lastAck) > u.lapse:
+		logger.Warn("[MAVLINK_HB] No SESSION_HEARTBEAT_ACK for %v - uplink registration lapsed", now.Sub(u.lastAck).Round(time.Second))
+		metrics.Global.AddLog("WARN", "Uplink registration lapsed - sending blind")
+		u.setState(uplinkBlind, now)
+	}
+}
+
+// Ack handles a SESSION_HEARTBEAT_ACK from the router
+func (u *uplinkRegistration) Ack(ack *mavlink_custom.MessageSessionHeartbeatAck, now time.Time) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.state == "" || u.lastSent-ack.Sequence > ackWindow {
+		return fmt.Errorf("ACK for sequence %d does not match a recent heartbeat (last sent %d)", ack.Sequence, u.lastSent)
+	}
+	metrics.Global.RecordUplinkAck()
+
+	if ack.Status == mavlink_custom.SessionAckRejected {
+		if u.state != uplinkRejected {
+			logger.Warn("[MAVLINK_HB] Router rejected the session token (seq %d) - telemetry is not accepted", ack.Sequence)
+			metrics.Global.AddLog("WARN", "Router rejected the session token")
+		}
+		u.setState(uplinkRejected, now)
+		return nil
+	}
+
+	u.lastAck = now
+	u.holdUntil = time.Time{}
+	if u.state != uplinkRegistered {
+		logger.Info("[MAVLINK_HB] ✓ Uplink registered by router (%s for %v)", u.state, now.Sub(u.since).Round(time.Millisecond))
+		metrics.Global.AddLog("INFO", "Uplink registered by router")
+	}
+	u.setState(uplinkRegistered, now)
+	return nil
+}
+
+// Reset forgets the registration after the uplink was rebound (new source port)
+func (u *uplinkRegistration) Reset() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.state = ""
+	u.holdUntil = time.Time{}
+	metrics.Global.SetUplinkState(uplinkBlind)
+}
+
+// Holding reports whether non-priority messages must wait for the router's ACK
+func (u *uplinkRegistration) Holding(now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return now.Before(u.holdUntil)
+}
+
+// setState records a state change (caller holds lock)
+func (u *uplinkRegistration) setState(state string, now time.Time) {
+	if state == u.state {
+		return
+	}
+	u.state = state
+	u.since = now
+	metrics.Global.SetUplinkState(state)
+}
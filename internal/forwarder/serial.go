@@ -0,0 +1,158 @@
+package forwarder
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3"
+
+	"DroneBridge/config"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+)
+
+// serialReopenInterval is how often a lost serial port is reopened
+const serialReopenInterval = time.Second
+
+// newSerialListener creates the listener node on the FC's UART (serial section)
+func newSerialListener(cfg *config.Config) (*gomavlib.Node, error) {
+	var endpoint gomavlib.EndpointConf = gomavlib.EndpointSerial{Device: cfg.Serial.Device, Baud: cfg.Serial.Baud}
+	if cfg.Serial.FlowControl {
+		// gomavlib's serial endpoint always turns RTS/CTS off - open the port ourselves
+		port, err := newSerialPort(cfg.Serial.Device, cfg.Serial.Baud)
+		if err != nil {
+			return nil, err
+		}
+		endpoint = gomavlib.EndpointCustom{ReadWriteCloser: port}
+	}
+
+	listenerNode, err := gomavlib.NewNode(gomavlib.NodeConf{
+		Endpoints:   []gomavlib.EndpointConf{endpoint},
+		Dialect:     mavlink_custom.GetCombinedDialect(),
+		OutVersion:  gomavlib.V2,
+		OutSystemID: 255, // Ground station ID
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open serial port %s: %w", cfg.Serial.Device, err)
+	}
+	logger.Info("[LISTENER] MAVLink listener on serial %s at %d baud (flow control %v)",
+		cfg.Serial.Device, cfg.Serial.Baud, cfg.Serial.FlowControl)
+	return listenerNode, nil
+}
+
+// serialPort is a UART with RTS/CTS flow control that is reopened after an
+// error (USB adapter unplugged, FC rebooting), like gomavlib's serial endpoint.
+// Reads block until the port is back; writes while it is down are dropped.
+type serialPort struct {
+	device string
+	baud   int
+
+	mu     sync.Mutex
+	port   io.ReadWriteCloser
+	closed chan struct{}
+	once   sync.Once
+}
+
+// newSerialPort opens the port once to report configuration errors at startup
+func newSerialPort(device string, baud int) (*serialPort, error) {
+	port, err := openFlowControl(device, baud)
+	if err != nil {
+		return nil, err
+	}
+	return &serialPort{device: device, baud: baud, port: port, closed: make(chan struct{})}, nil
+}
+
+func (s *serialPort) Read(p []byte) (int, error) {
+	for {
+		port, err := s.current()
+		if err != nil {
+			return 0, err
+		}
+		n, err := port.Read(p)
+		if err == nil || n > 0 {
+			return n, nil
+		}
+		s.drop(port, err)
+	}
+}
+
+func (s *serialPort) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	port := s.port
+	s.mu.Unlock()
+	if port == nil {
+		return len(p), nil
+	}
+	if _, err := port.Write(p); err != nil {
+		s.drop(port, err)
+	}
+	return len(p), nil
+}
+
+func (s *serialPort) Close() error {
+	s.once.Do(func() { close(s.closed) })
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.port != nil {
+		s.port.Close()
+		s.port = nil
+	}
+	return nil
+}
+
+// current returns the open port, reopening it until it succeeds or the port is closed
+func (s *serialPort) current() (io.ReadWriteCloser, error) {
+	for {
+		select {
+		case <-s.closed:
+			return nil, errors.New("serial port closed")
+		default:
+		}
+		s.mu.Lock()
+		port := s.port
+		s.mu.Unlock()
+		if port != nil {
+			return port, nil
+		}
+
+		port, err := openFlowControl(s.device, s.baud)
+		if err == nil {
+			s.mu.Lock()
+			select {
+			case <-s.closed:
+				s.mu.Unlock()
+				port.Close()
+				return nil, errors.New("serial port closed")
+			default:
+			}
+			s.port = port
+			s.mu.Unlock()
+			logger.Info("[SERIAL] ✅ %s reopened", s.device)
+			return port, nil
+		}
+		select {
+		case <-s.closed:
+			return nil, errors.New("serial port closed")
+		case <-time.After(serialReopenInterval):
+		}
+	}
+}
+
+// drop closes a failed port so the next read reopens it
+func (s *serialPort) drop(port io.ReadWriteCloser, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.port != port {
+		return // Already replaced
+	}
+	port.Close()
+	s.port = nil
+	select {
+	case <-s.closed:
+	default:
+		logger.Warn("[SERIAL] ⚠️ %s lost: %v - reopening every %v", s.device, err, serialReopenInterval)
+	}
+}
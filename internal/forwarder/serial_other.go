@@ -0,0 +1,13 @@
+//go:build !linux
+
+package forwarder
+
+import (
+	"fmt"
+	"io"
+)
+
+// openFlowControl is not implemented on this platform; use the port without flow control
+func openFlowControl(device string, baud int) (io.ReadWriteCloser, error) {
+	return nil, fmt.Errorf("serial flow control is only supported on Linux, set serial.flow_control: false")
+}
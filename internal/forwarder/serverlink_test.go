@@ -0,0 +1,46 @@
+package forwarder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/auth"
+	"DroneBridge/internal/mavlink_custom"
+	"DroneBridge/internal/testutil"
+)
+
+func TestServerLinkFrameLiveness(t *testing.T) {
+	clk := testutil.NewFakeClock(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	f := &Forwarder{
+		clock:          clk,
+		heartbeatGuard: auth.NewSequenceGuard(),
+		failover:       newUpstreamFailover(nil, 10*time.Second, time.Minute, clk.Now()),
+	}
+	tests := []struct {
+		name        string
+		msg         message.Message
+		wantHandled bool
+		wantHeard   bool
+	}{
+		{"heartbeat", &mavlink_custom.MessageSessionHeartbeat{Sequence: 10}, true, true},
+		{"newer heartbeat", &mavlink_custom.MessageSessionHeartbeat{Sequence: 11}, true, true},
+		{"replayed heartbeat", &mavlink_custom.MessageSessionHeartbeat{Sequence: 10}, true, false},
+		{"repeated heartbeat", &mavlink_custom.MessageSessionHeartbeat{Sequence: 11}, true, false},
+		{"command", &common.MessageCommandLong{Command: common.MAV_CMD_NAV_RETURN_TO_LAUNCH}, false, true},
+	}
+	for _, tt := range tests {
+		clk.Advance(time.Second)
+		if handled := f.serverLinkFrame(tt.msg, 255); handled != tt.wantHandled {
+			t.Errorf("%s: handled = %v, want %v", tt.name, handled, tt.wantHandled)
+		}
+		f.failover.mu.Lock()
+		heard := f.failover.lastRx.Equal(clk.Now())
+		f.failover.mu.Unlock()
+		if heard != tt.wantHeard {
+			t.Errorf("%s: counted as liveness = %v, want %v", tt.name, heard, tt.wantHeard)
+		}
+	}
+}
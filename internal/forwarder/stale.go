@@ -0,0 +1,40 @@
+package forwarder
+
+import (
+	"fmt"
+	"time"
+
+	"DroneBridge/internal/control"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// staleCommandCheck returns the pre-write check for the FC queue that drops or flags
+// command-class messages older than forwarding.stale_command.max_age (nil when off)
+func (f *Forwarder) staleCommandCheck() func(queuedWrite) bool {
+	cfg := f.cfg.Forwarding.StaleCommand
+	if cfg.Action == "off" {
+		return nil
+	}
+	maxAge := time.Duration(cfg.MaxAge) * time.Millisecond
+	drop := cfg.Action == "drop"
+
+	return func(w queuedWrite) bool {
+		if !control.IsControlMessage(w.msgID) || w.received.IsZero() {
+			return false
+		}
+		age := f.clock.Since(w.received)
+		if age <= maxAge {
+			return false
+		}
+
+		metrics.Global.IncStaleCommand(w.name, drop)
+		if drop {
+			logger.Warn("[STALE] Dropped %s to FC: %v old (max %v)", w.name, age.Round(time.Millisecond), maxAge)
+			metrics.Global.AddLog("WARN", fmt.Sprintf("Dropped stale %s (%v old)", w.name, age.Round(time.Millisecond)))
+			return true
+		}
+		logger.Warn("[STALE] Forwarding %s to FC although %v old (max %v)", w.name, age.Round(time.Millisecond), maxAge)
+		return false
+	}
+}
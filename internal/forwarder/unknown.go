@@ -0,0 +1,27 @@
+package forwarder
+
+import (
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// isUnknownMessage reports whether a message ID is outside the dialect.
+// gomavlib delivers such frames undecoded as *message.MessageRaw; they are
+// re-sent byte for byte, so vendor extensions survive the bridge unchanged.
+func isUnknownMessage(msg message.Message) bool {
+	_, ok := msg.(*message.MessageRaw)
+	return ok
+}
+
+// passUnknown counts a frame with an unknown message ID on a band and reports
+// whether forwarding.pass_unknown lets it through
+func (f *Forwarder) passUnknown(band string, msgID uint32) bool {
+	pass := f.cfg.Forwarding.PassUnknown
+	metrics.Global.IncUnknown(band, msgID, !pass)
+	if !pass {
+		logger.Debug("[UNKNOWN] Dropped message ID %d (%s, pass_unknown off)", msgID, band)
+	}
+	return pass
+}
@@ -0,0 +1,165 @@
+package forwarder
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/chaos"
+	"DroneBridge/internal/clock"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/signing"
+)
+
+// uplink is a stable writer in front of the sender node.
+// On an IP change a new node is built first, swapped in atomically, and the old
+// node is closed only after in-flight writes have drained - writers never see a
+// closed node and the events stream survives the swap.
+type uplink struct {
+	mu   sync.RWMutex // Writers hold RLock; Migrate takes Lock to drain them
+	node *gomavlib.Node
+
+	events chan gomavlib.Event // Stable event stream across migrations
+	closed chan struct{}
+	stage  *eventStage // Instruments events (buffer occupancy, backpressure)
+	clock  clock.Clock // Times migrations
+
+	migrating  atomic.Bool
+	failedSend atomic.Int64 // Write failures during the current migration
+
+	signedSeq atomic.Uint32 // Sequence of our own messages sent as signed frames
+}
+
+// newUplink wraps an initial sender node
+func newUplink(node *gomavlib.Node, stage *eventStage) *uplink {
+	u := &uplink{
+		node:   node,
+		events: make(chan gomavlib.Event, eventBuffer),
+		closed: make(chan struct{}),
+		stage:  stage,
+		clock:  clock.Real,
+	}
+	go u.pump(node)
+	return u
+}
+
+// pump relays a node's events into the stable stream until the node closes
+func (u *uplink) pump(node *gomavlib.Node) {
+	for evt := range node.Events() {
+		if _, ok := evt.(*gomavlib.EventFrame); ok && chaos.Global.Active() {
+			chaos.Global.Deliver(func() { u.relay(evt) })
+			continue
+		}
+		if !u.relay(evt) {
+			return
+		}
+	}
+}
+
+// relay hands one event to the stable stream; false once the uplink is closed
+func (u *uplink) relay(evt gomavlib.Event) bool {
+	return u.stage.relay(u.events, evt, u.closed)
+}
+
+// Depth returns the events waiting in the stream and its capacity
+func (u *uplink) Depth() (int, int) {
+	return len(u.events), cap(u.events)
+}
+
+// Events returns the events of the current sender node (survives migrations)
+func (u *uplink) Events() <-chan gomavlib.Event {
+	return u.events
+}
+
+// WriteFrameAll writes a frame through the current sender node
+func (u *uplink) WriteFrameAll(fr frame.Frame) error {
+	if chaos.Global.Active() {
+		chaos.Global.Deliver(func() { u.writeFrame(fr) })
+		return nil
+	}
+	return u.writeFrame(fr)
+}
+
+func (u *uplink) writeFrame(fr frame.Frame) error {
+	u.mu.RLock()
+	err := u.node.WriteFrameAll(fr)
+	u.mu.RUnlock()
+	u.countFailure(err)
+	return err
+}
+
+// WriteMessageAll writes a message through the current sender node
+func (u *uplink) WriteMessageAll(msg message.Message) error {
+	if chaos.Global.Active() {
+		chaos.Global.Deliver(func() { u.writeMessage(msg) })
+		return nil
+	}
+	return u.writeMessage(msg)
+}
+
+func (u *uplink) writeMessage(msg message.Message) error {
+	u.mu.RLock()
+	var err error
+	if signing.Global.Active() {
+		// The node can't sign with a key that rotates at runtime - frame and sign it ourselves
+		var fr frame.Frame
+		fr, err = signing.Global.SignMessage(msg, u.node.OutSystemID, u.node.OutComponentID, uint8(u.signedSeq.Add(1)))
+		if err == nil {
+			err = u.node.WriteFrameAll(fr)
+		}
+	} else {
+		err = u.node.WriteMessageAll(msg)
+	}
+	u.mu.RUnlock()
+	u.countFailure(err)
+	return err
+}
+
+func (u *uplink) countFailure(err error) {
+	if err != nil && u.migrating.Load() {
+		u.failedSend.Add(1)
+	}
+}
+
+// Migrate builds a replacement node with newNode, swaps it in and closes the old one.
+// The old node keeps serving writers until the new one is ready (make-before-break).
+func (u *uplink) Migrate(newNode func() (*gomavlib.Node, error)) error {
+	start := u.clock.Now()
+	u.failedSend.Store(0)
+	u.migrating.Store(true)
+	defer u.migrating.Store(false)
+
+	node, err := newNode()
+	if err != nil {
+		return fmt.Errorf("failed to create replacement sender node: %w", err)
+	}
+	go u.pump(node)
+
+	// Swap - Lock waits for in-flight writes on the old node to drain
+	u.mu.Lock()
+	old := u.node
+	u.node = node
+	u.mu.Unlock()
+
+	old.Close()
+
+	duration := u.clock.Since(start)
+	lost := u.failedSend.Load()
+	metrics.Global.RecordMigration(duration, lost)
+	logger.Info("[UPLINK] Migrated sender in %v (%d frames lost)", duration.Round(time.Millisecond), lost)
+	return nil
+}
+
+// Close closes the current sender node and stops the event stream
+func (u *uplink) Close() {
+	close(u.closed)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.node.Close()
+}
@@ -0,0 +1,51 @@
+package forwarder
+
+import (
+	"fmt"
+	"strings"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+)
+
+// verboseKey normalises a MAVLink name ("COMMAND_LONG") or Go message type
+// (*common.MessageCommandLong) to the same key ("COMMANDLONG")
+func verboseKey(name string) string {
+	if i := strings.LastIndex(name, ".Message"); i >= 0 {
+		name = name[i+len(".Message"):]
+	}
+	return strings.ToUpper(strings.ReplaceAll(name, "_", ""))
+}
+
+// newVerboseFilter resolves log.verbose_messages against the dialect. Unknown names
+// are reported once so a typo doesn't silently disable logging.
+func newVerboseFilter(names []string) map[string]bool {
+	if len(names) == 0 {
+		return nil
+	}
+	known := make(map[string]bool)
+	for _, msg := range mavlink_custom.GetCombinedDialect().Messages {
+		known[verboseKey(fmt.Sprintf("%T", msg))] = true
+	}
+	filter := make(map[string]bool, len(names))
+	for _, name := range names {
+		key := verboseKey(name)
+		if !known[key] {
+			logger.Warn("[VERBOSE] Unknown message type %q in log.verbose_messages", name)
+			continue
+		}
+		filter[key] = true
+	}
+	logger.Info("[VERBOSE] Field-level logging for %s in both directions", strings.Join(names, ", "))
+	return filter
+}
+
+// verboseFor reports whether a message from source ("server" or "Pixhawk") gets
+// field-level logging. With log.verbose_messages only the listed types are parsed,
+// in both directions; otherwise log.verbose parses every server message.
+func (f *Forwarder) verboseFor(msg interface{}, source string) bool {
+	if f.verboseMessages != nil {
+		return f.verboseMessages[verboseKey(fmt.Sprintf("%T", msg))]
+	}
+	return f.verboseMode && source == "server"
+}
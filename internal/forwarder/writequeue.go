@@ -0,0 +1,146 @@
+package forwarder
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// queuedWrite is one pending write; done (optional) receives the write result
+type queuedWrite struct {
+	name     string    // Message type name for logging/metrics
+	msgID    uint32    // MAVLink message ID
+	source   uint8     // Source system ID; writes from one source keep their order
+	received time.Time // When the bridge received the message (age stamp)
+	write    func() error
+	done     func(error)
+}
+
+// writeQueue decouples a receive loop from a possibly stalled link. Writes are
+// bounded per direction; when full, new writes are dropped and counted. Priority
+// writes (HEARTBEAT/COMMAND_*) use their own lane and are always drained first,
+// so they only overtake other sources: a command whose source still has writes
+// in the normal lane queues behind them (e.g. COMMAND_LONG after SET_MODE).
+type writeQueue struct {
+	direction string
+	priority  chan queuedWrite
+	normal    chan queuedWrite
+	overflow  *atomic.Uint64
+	skip      func(queuedWrite) bool // Optional pre-write check; true drops the write
+
+	mu      sync.Mutex
+	pending map[uint8]int // Source system ID -> writes in the normal lane
+}
+
+// newWriteQueue creates a queue holding up to size normal writes
+func newWriteQueue(direction string, size int, overflow *atomic.Uint64) *writeQueue {
+	prioSize := size / 4
+	if prioSize < 16 {
+		prioSize = 16
+	}
+	return &writeQueue{
+		direction: direction,
+		priority:  make(chan queuedWrite, prioSize),
+		normal:    make(chan queuedWrite, size),
+		overflow:  overflow,
+		pending:   make(map[uint8]int),
+	}
+}
+
+// isPriorityMessage reports whether a message bypasses the normal lane
+func isPriorityMessage(msgID uint32) bool {
+	switch msgID {
+	case 0, // HEARTBEAT
+		75, // COMMAND_INT
+		76, // COMMAND_LONG
+		77, // COMMAND_ACK
+		80: // COMMAND_CANCEL
+		return true
+	}
+	return false
+}
+
+// Enqueue queues a write without blocking. Returns false if the lane was full and the write dropped.
+// A priority write goes to the normal lane while its source has writes there,
+// except HEARTBEAT, which carries no order.
+func (q *writeQueue) Enqueue(w queuedWrite, priority bool) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if priority && w.msgID != 0 && q.pending[w.source] > 0 {
+		priority = false
+	}
+	lane := q.normal
+	if priority {
+		lane = q.priority
+	}
+	select {
+	case lane <- w:
+		if !priority {
+			q.pending[w.source]++
+		}
+		return true
+	default:
+		q.overflow.Add(1)
+		metrics.Global.IncQueueOverflow(q.direction)
+		logger.Debug("[QUEUE] %s queue full, dropped %s", q.direction, w.name)
+		return false
+	}
+}
+
+// Depth returns the number of pending writes
+func (q *writeQueue) Depth() int {
+	return len(q.priority) + len(q.normal)
+}
+
+// run performs queued writes until stopCh is closed
+func (q *writeQueue) run(stopCh <-chan struct{}) {
+	for {
+		// Drain the priority lane first
+		select {
+		case w := <-q.priority:
+			q.exec(w)
+			continue
+		default:
+		}
+
+		select {
+		case <-stopCh:
+			return
+		case w := <-q.priority:
+			q.exec(w)
+		case w := <-q.normal:
+			q.dequeued(w)
+			q.exec(w)
+		}
+	}
+}
+
+// dequeued releases a write taken from the normal lane
+func (q *writeQueue) dequeued(w queuedWrite) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending[w.source]--; q.pending[w.source] <= 0 {
+		delete(q.pending, w.source)
+	}
+}
+
+func (q *writeQueue) exec(w queuedWrite) {
+	if q.skip != nil && q.skip(w) {
+		return
+	}
+	err := w.write()
+	if w.done != nil {
+		w.done(err)
+	}
+}
+
+// QueueDepths returns the pending writes per direction
+func (f *Forwarder) QueueDepths() map[string]int {
+	return map[string]int{
+		f.toServer.direction: f.toServer.Depth(),
+		f.toFC.direction:     f.toFC.Depth(),
+	}
+}
@@ -0,0 +1,76 @@
+package forwarder
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// queued is a write for the queue tests: message ID, source system, lane asked for
+type queued struct {
+	msgID    uint32
+	source   uint8
+	priority bool
+}
+
+func TestWriteQueueOrder(t *testing.T) {
+	const (
+		heartbeat   = 0
+		setMode     = 11
+		position    = 33
+		commandLong = 76
+	)
+	tests := []struct {
+		name   string
+		writes []queued
+		want   []uint32
+	}{
+		{"command after mode change from the same GCS",
+			[]queued{{setMode, 255, false}, {commandLong, 255, true}},
+			[]uint32{setMode, commandLong}},
+		{"command ahead of another source's traffic",
+			[]queued{{position, 1, false}, {position, 1, false}, {commandLong, 255, true}},
+			[]uint32{commandLong, position, position}},
+		{"commands of one source stay in order",
+			[]queued{{setMode, 255, false}, {commandLong, 255, true}, {commandLong + 1, 255, true}, {position, 1, false}},
+			[]uint32{setMode, commandLong, commandLong + 1, position}},
+		{"heartbeat ahead of its own source",
+			[]queued{{position, 1, false}, {heartbeat, 1, true}},
+			[]uint32{heartbeat, position}},
+	}
+	for _, tt := range tests {
+		q := newWriteQueue("test", 16, &atomic.Uint64{})
+		var (
+			mu  sync.Mutex
+			got []uint32
+			wg  sync.WaitGroup
+		)
+		for _, w := range tt.writes {
+			id := w.msgID
+			wg.Add(1)
+			q.Enqueue(queuedWrite{
+				msgID:  id,
+				source: w.source,
+				write: func() error {
+					mu.Lock()
+					got = append(got, id)
+					mu.Unlock()
+					return nil
+				},
+				done: func(error) { wg.Done() },
+			}, w.priority)
+		}
+		stop := make(chan struct{})
+		go q.run(stop)
+		wg.Wait()
+		close(stop)
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: written %v, want %v", tt.name, got, tt.want)
+		}
+		if len(q.pending) != 0 {
+			t.Errorf("%s: pending = %v after the queue drained", tt.name, q.pending)
+		}
+	}
+}
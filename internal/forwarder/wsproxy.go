@@ -0,0 +1,74 @@
+package forwarder
+
+import (
+	"fmt"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+
+	"DroneBridge/internal/control"
+	"DroneBridge/internal/echo"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/policy"
+	"DroneBridge/internal/safety"
+)
+
+// relayFromWebSocket sends a frame from a /ws/mavlink client to the Pixhawk.
+// It applies the same checks as commands arriving from the router, plus the
+// role rules of the matching REST routes for the client's token.
+func (f *Forwarder) relayFromWebSocket(fr frame.Frame, role string) error {
+	msg := fr.GetMessage()
+	name := getMessageTypeName(msg)
+
+	switch msg.(type) {
+	case *mavlink_custom.MessageSessionHeartbeat, *mavlink_custom.MessageSessionHeartbeatV2, *mavlink_custom.MessageSessionHeartbeatAck:
+		return fmt.Errorf("%s is link-local", name)
+	}
+	if mavlink_custom.IsSessionMessage(msg.GetID()) { // Raw when the router assigned IDs after the hub's dialect was built
+		return fmt.Errorf("message %d is link-local", msg.GetID())
+	}
+	if isUnknownMessage(msg) && !f.passUnknown(metrics.BandWebToFC, msg.GetID()) {
+		return fmt.Errorf("unknown message %d not forwarded", msg.GetID())
+	}
+	if err := checkWebSocketRole(msg, role); err != nil {
+		return err
+	}
+	if err := checkParamFreeze(msg, safety.SourceWeb); err != nil {
+		return err
+	}
+	if !control.Global.AllowStickInput(msg.GetID()) {
+		return fmt.Errorf("%s needs remote piloting", name)
+	}
+	if !control.Global.AllowCloud(msg.GetID()) {
+		return fmt.Errorf("%s held back - local GCS has control", name)
+	}
+
+	policy.LocalParams.LocalRequest(msg.GetID())
+	write := func() error {
+		echo.Global.Record(msg)
+		return f.writeFC(msg)
+	}
+	if isUnknownMessage(msg) {
+		write = func() error {
+			echo.Global.Record(msg)
+			return f.writeFCFrame(fr)
+		}
+	}
+	f.toFC.Enqueue(queuedWrite{
+		name:     name,
+		msgID:    msg.GetID(),
+		source:   fr.GetSystemID(),
+		received: f.clock.Now(),
+		write:    write,
+		done: func(err error) {
+			if err != nil {
+				logger.Error("[WS->PIXHAWK] Failed to forward %s: %v", name, err)
+			} else {
+				logger.Debug("[WS->PIXHAWK] Forwarded %s", name)
+				metrics.Global.AddBand(metrics.BandWebToFC, mavlink_custom.MessageSize(msg))
+			}
+		},
+	}, isPriorityMessage(msg.GetID()))
+	return nil
+}
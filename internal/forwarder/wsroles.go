@@ -0,0 +1,64 @@
+package forwarder
+
+import (
+	"fmt"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/control"
+	"DroneBridge/internal/tokens"
+)
+
+// forceDisarmMagic is MAV_CMD_COMPONENT_ARM_DISARM param2 for disarming in flight
+const forceDisarmMagic = 21196
+
+// isEmergencyCommand reports whether msg is a flight termination or force-disarm.
+// Those need a second operator's confirmation at /api/emergency.
+func isEmergencyCommand(msg message.Message) bool {
+	var cmd common.MAV_CMD
+	var param1, param2 float32
+	switch m := msg.(type) {
+	case *common.MessageCommandLong:
+		cmd, param1, param2 = m.Command, m.Param1, m.Param2
+	case *common.MessageCommandInt:
+		cmd, param1, param2 = m.Command, m.Param1, m.Param2
+	default:
+		return false
+	}
+	switch cmd {
+	case common.MAV_CMD_DO_FLIGHTTERMINATION:
+		return param1 >= 0.5
+	case common.MAV_CMD_COMPONENT_ARM_DISARM:
+		return param1 == 0 && param2 == forceDisarmMagic
+	}
+	return false
+}
+
+// wsRequiredRole returns the token role a /ws/mavlink client needs to send msg,
+// matching the REST routes that send the same messages ("" = any sender)
+func wsRequiredRole(msg message.Message) string {
+	switch msg.(type) {
+	case *common.MessageSetupSigning:
+		return tokens.RoleAdmin // /api/signing
+	case *common.MessageParamSet, *common.MessageParamExtSet,
+		*common.MessageMissionCount, *common.MessageMissionItem, *common.MessageMissionItemInt,
+		*common.MessageMissionClearAll, *common.MessageMissionSetCurrent:
+		return tokens.RoleOperator // /api/param/set, /api/flight
+	}
+	if control.IsControlMessage(msg.GetID()) {
+		return tokens.RoleOperator // /api/flight, /api/guided
+	}
+	return ""
+}
+
+// checkWebSocketRole refuses command-class messages the client's role may not send
+func checkWebSocketRole(msg message.Message, role string) error {
+	if isEmergencyCommand(msg) {
+		return fmt.Errorf("emergency stop needs two-operator confirmation - use /api/emergency")
+	}
+	if need := wsRequiredRole(msg); need != "" && !tokens.Allows(role, need) {
+		return fmt.Errorf("message %d needs the %s role", msg.GetID(), need)
+	}
+	return nil
+}
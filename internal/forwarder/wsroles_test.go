@@ -0,0 +1,42 @@
+package forwarder
+
+import (
+	"testing"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/tokens"
+)
+
+func TestCheckWebSocketRole(t *testing.T) {
+	arm := &common.MessageCommandLong{Command: common.MAV_CMD_COMPONENT_ARM_DISARM, Param1: 1}
+	forceDisarm := &common.MessageCommandLong{Command: common.MAV_CMD_COMPONENT_ARM_DISARM, Param2: forceDisarmMagic}
+	terminate := &common.MessageCommandInt{Command: common.MAV_CMD_DO_FLIGHTTERMINATION, Param1: 1}
+	paramSet := &common.MessageParamSet{ParamId: "WPNAV_SPEED", ParamValue: 500}
+	signing := &common.MessageSetupSigning{}
+	heartbeat := &common.MessageHeartbeat{}
+
+	tests := []struct {
+		name    string
+		msg     message.Message
+		role    string
+		wantErr bool
+	}{
+		{"telemetry heartbeat", heartbeat, tokens.RoleTelemetry, false},
+		{"camera command", arm, tokens.RoleCamera, true},
+		{"operator command", arm, tokens.RoleOperator, false},
+		{"operator param set", paramSet, tokens.RoleOperator, false},
+		{"camera param set", paramSet, tokens.RoleCamera, true},
+		{"operator signing", signing, tokens.RoleOperator, true},
+		{"admin signing", signing, tokens.RoleAdmin, false},
+		{"admin force-disarm", forceDisarm, tokens.RoleAdmin, true},
+		{"admin termination", terminate, tokens.RoleAdmin, true},
+		{"anonymous command", arm, "", true},
+	}
+	for _, tt := range tests {
+		if err := checkWebSocketRole(tt.msg, tt.role); (err != nil) != tt.wantErr {
+			t.Errorf("%s: checkWebSocketRole() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
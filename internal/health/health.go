@@ -0,0 +1,275 @@
+package health
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/ardupilotmega"
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/alerts"
+)
+
+// Thresholds controls when decoded values are considered unhealthy
+type Thresholds struct {
+	VibrationWarn     float64 // m/s/s on any axis
+	VibrationCritical float64 // m/s/s on any axis
+	EKFVarianceWarn   float64 // Normalised EKF variance (1.0 = failsafe level)
+}
+
+// SensorState is the decoded state of a single SYS_STATUS sensor bit
+type SensorState struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Healthy bool   `json:"healthy"`
+}
+
+// EKFState is the decoded EKF_STATUS_REPORT
+type EKFState struct {
+	Flags              []string  `json:"flags"`
+	Healthy            bool      `json:"healthy"`
+	VelocityVariance   float32   `json:"velocityVariance"`
+	PosHorizVariance   float32   `json:"posHorizVariance"`
+	PosVertVariance    float32   `json:"posVertVariance"`
+	CompassVariance    float32   `json:"compassVariance"`
+	TerrainAltVariance float32   `json:"terrainAltVariance"`
+	LastUpdated        time.Time `json:"lastUpdated"`
+}
+
+// VibrationState is the decoded VIBRATION message
+type VibrationState struct {
+	X           float32   `json:"x"`
+	Y           float32   `json:"y"`
+	Z           float32   `json:"z"`
+	Clipping    [3]uint32 `json:"clipping"`
+	Level       string    `json:"level"` // ok, warning, critical
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+// FCHealth aggregates flight controller health decoded from telemetry
+type FCHealth struct {
+	mu         sync.RWMutex
+	thresholds Thresholds
+
+	sensors        []SensorState
+	sensorsUpdated time.Time
+	ekf            *EKFState
+	vibration      *VibrationState
+
+	// Active warnings keyed by identifier, used to alert only on transitions
+	active map[string]string
+}
+
+// Global is the process-wide FC health tracker
+var Global = New(Thresholds{VibrationWarn: 30, VibrationCritical: 60, EKFVarianceWarn: 0.8})
+
+// New creates a health tracker with the given thresholds
+func New(t Thresholds) *FCHealth {
+	return &FCHealth{
+		thresholds: t,
+		active:     make(map[string]string),
+	}
+}
+
+// SetThresholds updates warning thresholds
+func (h *FCHealth) SetThresholds(t Thresholds) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.thresholds = t
+}
+
+// sensorName returns a short name for a single MAV_SYS_STATUS_SENSOR bit
+func sensorName(bit common.MAV_SYS_STATUS_SENSOR) string {
+	name := bit.String()
+	if name == "" || strings.Contains(name, "|") {
+		return fmt.Sprintf("SENSOR_%d", bits.TrailingZeros32(uint32(bit)))
+	}
+	return strings.TrimPrefix(name, "MAV_SYS_STATUS_SENSOR_")
+}
+
+// UpdateSysStatus decodes the SYS_STATUS sensor bitmasks
+// Returns the names of enabled sensors currently reporting unhealthy
+func (h *FCHealth) UpdateSysStatus(m *common.MessageSysStatus) []string {
+	present := uint32(m.OnboardControlSensorsPresent)
+	enabled := uint32(m.OnboardControlSensorsEnabled)
+	healthy := uint32(m.OnboardControlSensorsHealth)
+
+	var sensors []SensorState
+	var unhealthy []string
+	for present != 0 {
+		bit := present & -present
+		present &^= bit
+
+		state := SensorState{
+			Name:    sensorName(common.MAV_SYS_STATUS_SENSOR(bit)),
+			Enabled: enabled&bit != 0,
+			Healthy: healthy&bit != 0,
+		}
+		sensors = append(sensors, state)
+		if state.Enabled && !state.Healthy {
+			unhealthy = append(unhealthy, state.Name)
+		}
+	}
+
+	h.mu.Lock()
+	h.sensors = sensors
+	h.sensorsUpdated = time.Now()
+	h.mu.Unlock()
+
+	h.setWarning("sensors", len(unhealthy) > 0, alerts.SeverityWarning,
+		fmt.Sprintf("Unhealthy sensors: %s", strings.Join(unhealthy, ", ")))
+
+	return unhealthy
+}
+
+// UpdateEKFStatus decodes EKF_STATUS_REPORT flags and checks variances
+func (h *FCHealth) UpdateEKFStatus(m *ardupilotmega.MessageEkfStatusReport) {
+	var flags []string
+	for v := uint64(m.Flags); v != 0; {
+		bit := v & -v
+		v &^= bit
+		flags = append(flags, strings.TrimPrefix(ardupilotmega.EKF_STATUS_FLAGS(bit).String(), "EKF_"))
+	}
+
+	h.mu.RLock()
+	limit := float32(h.thresholds.EKFVarianceWarn)
+	h.mu.RUnlock()
+
+	var high []string
+	variances := []struct {
+		name  string
+		value float32
+	}{
+		{"velocity", m.VelocityVariance},
+		{"pos_horiz", m.PosHorizVariance},
+		{"pos_vert", m.PosVertVariance},
+		{"compass", m.CompassVariance},
+		{"terrain_alt", m.TerrainAltVariance},
+	}
+	for _, v := range variances {
+		if limit > 0 && v.value >= limit {
+			high = append(high, fmt.Sprintf("%s=%.2f", v.name, v.value))
+		}
+	}
+
+	glitching := m.Flags&ardupilotmega.EKF_GPS_GLITCHING != 0
+	uninitialized := m.Flags&ardupilotmega.EKF_UNINITIALIZED != 0
+	attitudeOK := m.Flags&ardupilotmega.EKF_ATTITUDE != 0
+
+	h.mu.Lock()
+	h.ekf = &EKFState{
+		Flags:              flags,
+		Healthy:            attitudeOK && !glitching && !uninitialized && len(high) == 0,
+		VelocityVariance:   m.VelocityVariance,
+		PosHorizVariance:   m.PosHorizVariance,
+		PosVertVariance:    m.PosVertVariance,
+		CompassVariance:    m.CompassVariance,
+		TerrainAltVariance: m.TerrainAltVariance,
+		LastUpdated:        time.Now(),
+	}
+	h.mu.Unlock()
+
+	h.setWarning("ekf_variance", len(high) > 0, alerts.SeverityWarning,
+		fmt.Sprintf("EKF variance above %.2f: %s", limit, strings.Join(high, ", ")))
+	h.setWarning("ekf_gps_glitch", glitching, alerts.SeverityWarning, "EKF reports GPS glitching")
+}
+
+// UpdateVibration records vibration levels and classifies them against thresholds
+func (h *FCHealth) UpdateVibration(m *common.MessageVibration) {
+	h.mu.RLock()
+	t := h.thresholds
+	var prevClipping [3]uint32
+	if h.vibration != nil {
+		prevClipping = h.vibration.Clipping
+	}
+	h.mu.RUnlock()
+
+	peak := float64(m.VibrationX)
+	if float64(m.VibrationY) > peak {
+		peak = float64(m.VibrationY)
+	}
+	if float64(m.VibrationZ) > peak {
+		peak = float64(m.VibrationZ)
+	}
+
+	level := "ok"
+	if t.VibrationCritical > 0 && peak >= t.VibrationCritical {
+		level = "critical"
+	} else if t.VibrationWarn > 0 && peak >= t.VibrationWarn {
+		level = "warning"
+	}
+
+	clipping := [3]uint32{m.Clipping_0, m.Clipping_1, m.Clipping_2}
+	clippingIncreased := false
+	for i := range clipping {
+		if clipping[i] > prevClipping[i] && prevClipping[i] != 0 {
+			clippingIncreased = true
+		}
+	}
+
+	h.mu.Lock()
+	h.vibration = &VibrationState{
+		X:           m.VibrationX,
+		Y:           m.VibrationY,
+		Z:           m.VibrationZ,
+		Clipping:    clipping,
+		Level:       level,
+		LastUpdated: time.Now(),
+	}
+	h.mu.Unlock()
+
+	severity := alerts.SeverityWarning
+	if level == "critical" {
+		severity = alerts.SeverityCritical
+	}
+	h.setWarning("vibration", level != "ok", severity,
+		fmt.Sprintf("Vibration %s: X=%.1f Y=%.1f Z=%.1f m/s/s", level, m.VibrationX, m.VibrationY, m.VibrationZ))
+	h.setWarning("clipping", clippingIncreased, alerts.SeverityWarning,
+		fmt.Sprintf("Accelerometer clipping increasing: %v", clipping))
+}
+
+// setWarning raises an alert when a condition becomes active and logs when it clears
+func (h *FCHealth) setWarning(key string, active bool, severity, message string) {
+	h.mu.Lock()
+	_, wasActive := h.active[key]
+	if active {
+		h.active[key] = message
+	} else {
+		delete(h.active, key)
+	}
+	h.mu.Unlock()
+
+	if active && !wasActive {
+		alerts.Raise("fc_health", severity, message)
+	} else if !active && wasActive {
+		alerts.Raise("fc_health", alerts.SeverityInfo, fmt.Sprintf("Cleared: %s", key))
+	}
+}
+
+// Snapshot returns the decoded health state for the web API
+func (h *FCHealth) Snapshot() map[string]interface{} {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	warnings := make([]string, 0, len(h.active))
+	for _, msg := range h.active {
+		warnings = append(warnings, msg)
+	}
+
+	var sensorsUpdated interface{}
+	if !h.sensorsUpdated.IsZero() {
+		sensorsUpdated = h.sensorsUpdated
+	}
+
+	return map[string]interface{}{
+		"healthy":        len(h.active) == 0,
+		"warnings":       warnings,
+		"sensors":        h.sensors,
+		"sensorsUpdated": sensorsUpdated,
+		"ekf":            h.ekf,
+		"vibration":      h.vibration,
+	}
+}
@@ -0,0 +1,94 @@
+package health
+
+import "fmt"
+
+// Vehicle classes the preflight check sets are defined for
+const (
+	ClassCopter = "copter"
+	ClassPlane  = "plane"
+	ClassRover  = "rover" // Ground rovers and surface boats
+	ClassSub    = "sub"
+)
+
+// PreflightCheck is the result of a single bridge-side preflight check
+type PreflightCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// sensorCheck is a SYS_STATUS sensor a vehicle class depends on
+type sensorCheck struct {
+	sensor   string // Name as decoded by sensorName
+	required bool   // Optional sensors are only checked when the FC reports them
+}
+
+// preflightSensors lists the sensors checked per vehicle class
+var preflightSensors = map[string][]sensorCheck{
+	ClassCopter: {
+		{"3D_GYRO", true}, {"3D_ACCEL", true}, {"3D_MAG", true}, {"ABSOLUTE_PRESSURE", true},
+		{"GPS", true}, {"AHRS", true}, {"TERRAIN", false}, {"LASER_POSITION", false},
+	},
+	ClassPlane: {
+		{"3D_GYRO", true}, {"3D_ACCEL", true}, {"3D_MAG", true}, {"ABSOLUTE_PRESSURE", true},
+		{"GPS", true}, {"AHRS", true}, {"DIFFERENTIAL_PRESSURE", false},
+	},
+	ClassRover: {
+		{"3D_GYRO", true}, {"3D_ACCEL", true}, {"3D_MAG", true}, {"GPS", true}, {"AHRS", true},
+	},
+	ClassSub: {
+		{"3D_GYRO", true}, {"3D_ACCEL", true}, {"3D_MAG", true}, {"ABSOLUTE_PRESSURE", true}, {"AHRS", true},
+	},
+}
+
+// Preflight evaluates the checks for a vehicle class against the decoded telemetry.
+// Unknown classes get the copter set, the strictest one.
+func (h *FCHealth) Preflight(class string) []PreflightCheck {
+	sensors, ok := preflightSensors[class]
+	if !ok {
+		sensors = preflightSensors[ClassCopter]
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	reported := make(map[string]SensorState, len(h.sensors))
+	for _, s := range h.sensors {
+		reported[s.Name] = s
+	}
+
+	var checks []PreflightCheck
+	for _, c := range sensors {
+		s, present := reported[c.sensor]
+		switch {
+		case !present && !c.required:
+			continue
+		case !present:
+			checks = append(checks, PreflightCheck{Name: c.sensor, Detail: "not reported by FC"})
+		case !s.Enabled:
+			checks = append(checks, PreflightCheck{Name: c.sensor, Passed: !c.required, Detail: "disabled"})
+		case !s.Healthy:
+			checks = append(checks, PreflightCheck{Name: c.sensor, Detail: "unhealthy"})
+		default:
+			checks = append(checks, PreflightCheck{Name: c.sensor, Passed: true})
+		}
+	}
+
+	// EKF and vibration matter to everything but the vibration limits are tuned for multirotors
+	switch {
+	case h.ekf == nil:
+		checks = append(checks, PreflightCheck{Name: "EKF", Detail: "no EKF_STATUS_REPORT received"})
+	case !h.ekf.Healthy:
+		checks = append(checks, PreflightCheck{Name: "EKF", Detail: fmt.Sprintf("flags %v", h.ekf.Flags)})
+	default:
+		checks = append(checks, PreflightCheck{Name: "EKF", Passed: true})
+	}
+	if h.vibration != nil && (class == ClassCopter || !ok) {
+		checks = append(checks, PreflightCheck{
+			Name:   "VIBRATION",
+			Passed: h.vibration.Level != "critical",
+			Detail: h.vibration.Level,
+		})
+	}
+	return checks
+}
@@ -0,0 +1,97 @@
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Identity is the human-readable metadata of this drone
+type Identity struct {
+	UUID       string `json:"uuid"`
+	Name       string `json:"name"`
+	Airframe   string `json:"airframe"`
+	Operator   string `json:"operator"`
+	TailNumber string `json:"tailNumber"`
+}
+
+// Store holds the drone identity. Values set through the API are persisted to a
+// local file and take precedence over the config defaults on the next start.
+type Store struct {
+	mu   sync.RWMutex
+	path string
+	id   Identity
+}
+
+// Global is the process-wide identity store
+var Global = &Store{}
+
+// Load initializes the store from config defaults and the local metadata file
+func (s *Store) Load(path string, defaults Identity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path = path
+	s.id = defaults
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read identity: %w", err)
+	}
+	var saved Identity
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse identity: %w", err)
+	}
+	uuid := s.id.UUID
+	s.id = saved
+	s.id.UUID = uuid // The UUID always comes from registration, never from the metadata file
+	return nil
+}
+
+// Get returns the current identity
+func (s *Store) Get() Identity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.id
+}
+
+// DisplayName returns the friendly name, falling back to the UUID
+func (s *Store) DisplayName() string {
+	id := s.Get()
+	if id.Name != "" {
+		return id.Name
+	}
+	return id.UUID
+}
+
+// Update replaces the editable fields and persists them
+func (s *Store) Update(id Identity) error {
+	if len(id.Name) > 64 || len(id.Airframe) > 64 || len(id.Operator) > 64 || len(id.TailNumber) > 32 {
+		return fmt.Errorf("identity fields are too long")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id.UUID = s.id.UUID
+	s.id = id
+	return s.saveLocked()
+}
+
+// saveLocked writes the identity atomically (caller holds lock)
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil // In-memory only
+	}
+	data, err := json.MarshalIndent(s.id, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write identity: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,252 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/storage"
+)
+
+// Entry types
+const (
+	TypeGPS     = "gps"
+	TypeBattery = "battery"
+	TypeEvent   = "events"
+	TypeAuth    = "auth" // Handshake steps when auth.trace is on
+)
+
+// dayLayout names the daily journal files (UTC)
+const dayLayout = "2006-01-02"
+
+// Entry is one journaled sample or event
+type Entry struct {
+	Time time.Time              `json:"time"`
+	Type string                 `json:"type"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// Recorder appends flight data to daily JSON-lines files (<dir>/YYYY-MM-DD.jsonl)
+// so it can be exported by time window without parsing tlogs
+type Recorder struct {
+	mu       sync.Mutex
+	dir      string
+	interval time.Duration // Minimum time between gps/battery samples
+	file     *os.File
+	day      string
+	refused  bool // Storage refused the current day's file (logged once)
+
+	lastSample map[string]time.Time
+
+	// Flight state used to journal changes and enrich samples
+	haveHeartbeat bool
+	armed         bool
+	customMode    uint32
+	fixType       common.GPS_FIX_TYPE
+	satellites    uint8
+}
+
+// Global is the process-wide journal (disabled until Configure is called)
+var Global = &Recorder{}
+
+// Configure sets the journal directory (empty = disabled) and the sample interval
+func (r *Recorder) Configure(dir string, interval time.Duration) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create journal directory: %w", err)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closeLocked()
+	r.dir = dir
+	r.interval = interval
+	r.lastSample = make(map[string]time.Time)
+	return nil
+}
+
+// Close closes the current file, e.g. on shutdown
+func (r *Recorder) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closeLocked()
+}
+
+func (r *Recorder) closeLocked() {
+	if r.file != nil {
+		r.file.Close()
+		r.file = nil
+	}
+	r.day = ""
+}
+
+// Record appends an entry now
+func (r *Recorder) Record(typ string, data map[string]interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writeLocked(Entry{Time: time.Now().UTC(), Type: typ, Data: data})
+}
+
+// sampleLocked appends a periodic sample unless one of the type was written
+// within the sample interval (caller holds r.mu)
+func (r *Recorder) sampleLocked(typ string, data map[string]interface{}) {
+	now := time.Now().UTC()
+	if now.Sub(r.lastSample[typ]) < r.interval {
+		return
+	}
+	r.lastSample[typ] = now
+	r.writeLocked(Entry{Time: now, Type: typ, Data: data})
+}
+
+// writeLocked appends an entry to the file of its day (caller holds r.mu)
+func (r *Recorder) writeLocked(e Entry) {
+	if r.dir == "" {
+		return
+	}
+	day := e.Time.Format(dayLayout)
+	if r.file == nil || day != r.day {
+		r.closeLocked()
+		// A new file is a new recording - respect the disk-space guard
+		if err := storage.Global.AllowRecording(); err != nil {
+			if !r.refused {
+				logger.Warn("[JOURNAL] %v", err)
+				r.refused = true
+			}
+			return
+		}
+		f, err := os.OpenFile(filepath.Join(r.dir, day+".jsonl"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			logger.Warn("[JOURNAL] Failed to open journal: %v", err)
+			return
+		}
+		r.file, r.day, r.refused = f, day, false
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if _, err := r.file.Write(append(data, '\n')); err != nil {
+		logger.Warn("[JOURNAL] Failed to write %s entry: %v", e.Type, err)
+		r.closeLocked()
+	}
+}
+
+// ObserveHeartbeat journals arming and flight mode changes
+func (r *Recorder) ObserveHeartbeat(m *common.MessageHeartbeat) {
+	armed := m.BaseMode&common.MAV_MODE_FLAG_SAFETY_ARMED != 0
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.haveHeartbeat {
+		r.haveHeartbeat, r.armed, r.customMode = true, armed, m.CustomMode
+		return
+	}
+	if armed != r.armed {
+		event := "disarmed"
+		if armed {
+			event = "armed"
+		}
+		r.writeLocked(Entry{Time: time.Now().UTC(), Type: TypeEvent, Data: map[string]interface{}{"event": event, "mode": m.CustomMode}})
+		r.armed = armed
+	}
+	if m.CustomMode != r.customMode {
+		r.writeLocked(Entry{Time: time.Now().UTC(), Type: TypeEvent, Data: map[string]interface{}{"event": "mode_change", "from": r.customMode, "mode": m.CustomMode}})
+		r.customMode = m.CustomMode
+	}
+}
+
+// ObserveGPSRaw keeps fix type and satellite count for the next gps sample
+func (r *Recorder) ObserveGPSRaw(m *common.MessageGpsRawInt) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fixType = m.FixType
+	r.satellites = m.SatellitesVisible
+}
+
+// ObservePosition samples GLOBAL_POSITION_INT
+func (r *Recorder) ObservePosition(m *common.MessageGlobalPositionInt) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sampleLocked(TypeGPS, map[string]interface{}{
+		"lat":        float64(m.Lat) / 1e7,
+		"lon":        float64(m.Lon) / 1e7,
+		"alt_msl":    float64(m.Alt) / 1000,
+		"alt_rel":    float64(m.RelativeAlt) / 1000,
+		"vx":         float64(m.Vx) / 100,
+		"vy":         float64(m.Vy) / 100,
+		"vz":         float64(m.Vz) / 100,
+		"heading":    float64(m.Hdg) / 100,
+		"fix_type":   int(r.fixType),
+		"satellites": int(r.satellites),
+		"armed":      r.armed,
+	})
+}
+
+// ObserveSysStatus samples the battery from SYS_STATUS
+func (r *Recorder) ObserveSysStatus(m *common.MessageSysStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sampleLocked(TypeBattery, map[string]interface{}{
+		"voltage":   float64(m.VoltageBattery) / 1000,
+		"current":   float64(m.CurrentBattery) / 100, // -0.01 = not measured
+		"remaining": int(m.BatteryRemaining),         // -1 = not estimated
+		"armed":     r.armed,
+	})
+}
+
+// Query returns the entries of the given types (all if none are given) with
+// from <= time < to, oldest first, at most limit (0 = no limit)
+func (r *Recorder) Query(from, to time.Time, types []string, limit int) ([]Entry, error) {
+	r.mu.Lock()
+	dir := r.dir
+	r.mu.Unlock()
+
+	out := []Entry{}
+	if dir == "" {
+		return out, nil
+	}
+	wanted := make(map[string]bool, len(types))
+	for _, t := range types {
+		wanted[t] = true
+	}
+
+	from, to = from.UTC(), to.UTC()
+	for day := from.Truncate(24 * time.Hour); day.Before(to); day = day.Add(24 * time.Hour) {
+		f, err := os.Open(filepath.Join(dir, day.Format(dayLayout)+".jsonl"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open journal: %w", err)
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var e Entry
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				continue // Torn write from a power loss
+			}
+			if e.Time.Before(from) || !e.Time.Before(to) || (len(wanted) > 0 && !wanted[e.Type]) {
+				continue
+			}
+			out = append(out, e)
+			if limit > 0 && len(out) >= limit {
+				f.Close()
+				return out, nil
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read journal: %w", err)
+		}
+	}
+	return out, nil
+}
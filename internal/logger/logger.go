@@ -2,8 +2,10 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -39,6 +41,14 @@ type Logger struct {
 	level       Level
 	logger      *log.Logger
 	useUnixTime bool
+
+	// Per-subsystem overrides keyed by the message tag (e.g. "MESH" for "[MESH] ...")
+	subsystems map[string]Level
+
+	// Temporary level that reverts to revertTo when the timer fires
+	revertTo    Level
+	revertAt    time.Time
+	revertTimer *time.Timer
 }
 
 var defaultLogger = &Logger{
@@ -47,13 +57,104 @@ var defaultLogger = &Logger{
 	useUnixTime: false,
 }
 
-// SetLevel sets the global log level
+// SetLevel sets the global log level, cancelling a pending temporary level
 func SetLevel(level Level) {
 	defaultLogger.mu.Lock()
 	defer defaultLogger.mu.Unlock()
+	defaultLogger.cancelRevertLocked()
 	defaultLogger.level = level
 }
 
+// ParseLevel converts a level name (debug, info, warn, error) to a Level
+func ParseLevel(levelStr string) (Level, error) {
+	level, ok := levelFromString[strings.ToLower(levelStr)]
+	if !ok {
+		return INFO, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", levelStr)
+	}
+	return level, nil
+}
+
+// String returns the level name
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// SetLevelFor sets the global log level for d, then reverts to the level that was
+// active before. Setting a level again (temporary or not) replaces the pending revert.
+func SetLevelFor(level Level, d time.Duration) {
+	defaultLogger.mu.Lock()
+	defer defaultLogger.mu.Unlock()
+
+	revertTo := defaultLogger.level
+	if defaultLogger.revertTimer != nil {
+		revertTo = defaultLogger.revertTo // Extending keeps the original level
+	}
+	defaultLogger.cancelRevertLocked()
+	defaultLogger.level = level
+	defaultLogger.revertTo = revertTo
+	defaultLogger.revertAt = time.Now().Add(d)
+
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		defaultLogger.mu.Lock()
+		if defaultLogger.revertTimer != timer {
+			defaultLogger.mu.Unlock()
+			return // Replaced in the meantime
+		}
+		defaultLogger.revertTimer = nil
+		defaultLogger.level = defaultLogger.revertTo
+		defaultLogger.mu.Unlock()
+		defaultLogger.logger.Printf("[LOGGER] Temporary log level expired, back to %s", levelNames[revertTo])
+	})
+	defaultLogger.revertTimer = timer
+}
+
+// TemporaryLevel reports a pending revert: the level restored and when
+func TemporaryLevel() (revertTo Level, at time.Time, ok bool) {
+	defaultLogger.mu.RLock()
+	defer defaultLogger.mu.RUnlock()
+	if defaultLogger.revertTimer == nil {
+		return INFO, time.Time{}, false
+	}
+	return defaultLogger.revertTo, defaultLogger.revertAt, true
+}
+
+// cancelRevertLocked drops a pending temporary level revert (caller holds mu)
+func (l *Logger) cancelRevertLocked() {
+	if l.revertTimer != nil {
+		l.revertTimer.Stop()
+		l.revertTimer = nil
+	}
+}
+
+// SetSubsystemLevel overrides the level for messages tagged "[TAG] ..."
+func SetSubsystemLevel(tag string, level Level) {
+	defaultLogger.mu.Lock()
+	defer defaultLogger.mu.Unlock()
+	if defaultLogger.subsystems == nil {
+		defaultLogger.subsystems = make(map[string]Level)
+	}
+	defaultLogger.subsystems[strings.ToUpper(tag)] = level
+}
+
+// ClearSubsystemLevel removes a subsystem override so the global level applies again
+func ClearSubsystemLevel(tag string) {
+	defaultLogger.mu.Lock()
+	defer defaultLogger.mu.Unlock()
+	delete(defaultLogger.subsystems, strings.ToUpper(tag))
+}
+
+// SubsystemLevels returns the per-subsystem overrides
+func SubsystemLevels() map[string]string {
+	defaultLogger.mu.RLock()
+	defer defaultLogger.mu.RUnlock()
+	out := make(map[string]string, len(defaultLogger.subsystems))
+	for tag, level := range defaultLogger.subsystems {
+		out[tag] = levelNames[level]
+	}
+	return out
+}
+
 // SetLevelFromString sets log level from string (debug, info, warn, error)
 func SetLevelFromString(levelStr string) {
 	if level, ok := levelFromString[strings.ToLower(levelStr)]; ok {
@@ -78,6 +179,44 @@ func SetTimestampFormat(format string) {
 	}
 }
 
+// console is where log lines are printed; outputFile is the optional log file
+var (
+	console    io.Writer = os.Stdout
+	outputFile io.Writer
+)
+
+// SetOutputFile additionally appends all output to a file. Packages that use the
+// standard log package are redirected as well so the file holds the full log.
+func SetOutputFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	outputFile = f
+	applyOutput()
+	return nil
+}
+
+// UseStderr prints log lines to stderr, keeping stdout free for machine-readable
+// output (e.g. --register --json)
+func UseStderr() {
+	console = os.Stderr
+	applyOutput()
+}
+
+func applyOutput() {
+	if outputFile == nil {
+		defaultLogger.logger.SetOutput(console)
+		log.SetOutput(os.Stderr)
+		return
+	}
+	defaultLogger.logger.SetOutput(io.MultiWriter(console, outputFile))
+	log.SetOutput(io.MultiWriter(os.Stderr, outputFile))
+}
+
 // GetLevel returns current log level
 func GetLevel() Level {
 	defaultLogger.mu.RLock()
@@ -90,9 +229,18 @@ func GetLevelString() string {
 	return levelNames[GetLevel()]
 }
 
-func shouldLog(level Level) bool {
+// shouldLog checks a message against its subsystem override (from the leading
+// "[TAG]" of the format) or the global level
+func shouldLog(level Level, format string) bool {
 	defaultLogger.mu.RLock()
 	defer defaultLogger.mu.RUnlock()
+	if len(defaultLogger.subsystems) > 0 && strings.HasPrefix(format, "[") {
+		if end := strings.IndexByte(format, ']'); end > 1 {
+			if sub, ok := defaultLogger.subsystems[format[1:end]]; ok {
+				return level >= sub
+			}
+		}
+	}
 	return level >= defaultLogger.level
 }
 
@@ -110,28 +258,28 @@ func formatMessage(prefix, format string, v ...interface{}) string {
 
 // Debug logs at DEBUG level
 func Debug(format string, v ...interface{}) {
-	if shouldLog(DEBUG) {
+	if shouldLog(DEBUG, format) {
 		defaultLogger.logger.Print(formatMessage("[DEBUG] ", format, v...))
 	}
 }
 
 // Info logs at INFO level
 func Info(format string, v ...interface{}) {
-	if shouldLog(INFO) {
+	if shouldLog(INFO, format) {
 		defaultLogger.logger.Print(formatMessage("[INFO] ", format, v...))
 	}
 }
 
 // Warn logs at WARN level
 func Warn(format string, v ...interface{}) {
-	if shouldLog(WARN) {
+	if shouldLog(WARN, format) {
 		defaultLogger.logger.Print(formatMessage("[WARN] ", format, v...))
 	}
 }
 
 // Error logs at ERROR level
 func Error(format string, v ...interface{}) {
-	if shouldLog(ERROR) {
+	if shouldLog(ERROR, format) {
 		defaultLogger.logger.Print(formatMessage("[ERROR] ", format, v...))
 	}
 }
@@ -0,0 +1,261 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/rollup"
+)
+
+// maxEvents bounds the service log kept on disk
+const maxEvents = 500
+
+// Reminder is the service interval of one item; whichever limit is reached first is due
+type Reminder struct {
+	Item    string
+	Hours   float64 // 0 = no limit
+	Flights int     // 0 = no limit
+}
+
+// Event is a logged maintenance action
+type Event struct {
+	Time        time.Time `json:"time"`
+	Item        string    `json:"item"`
+	Action      string    `json:"action"` // e.g. replaced, serviced, inspected
+	Note        string    `json:"note,omitempty"`
+	FlightHours float64   `json:"flightHours"` // Airframe hours when it was logged
+}
+
+// itemState is where an item's counters restarted
+type itemState struct {
+	HoursAt    float64   `json:"hours_at"`
+	FlightsAt  int       `json:"flights_at"`
+	ServicedOn time.Time `json:"serviced_on"`
+	Reminded   bool      `json:"reminded"` // Alert raised for the current interval
+}
+
+// ItemStatus is the counter state of one item for the web API
+type ItemStatus struct {
+	Item         string     `json:"item"`
+	Hours        float64    `json:"hours"`   // Flight hours since last service
+	Flights      int        `json:"flights"` // Flights since last service
+	HoursLimit   float64    `json:"hoursLimit,omitempty"`
+	FlightsLimit int        `json:"flightsLimit,omitempty"`
+	Due          bool       `json:"due"`
+	LastServiced *time.Time `json:"lastServiced,omitempty"`
+}
+
+// state is the persisted form of the tracker
+type state struct {
+	Items  map[string]*itemState `json:"items"`
+	Events []Event               `json:"events"`
+}
+
+// Tracker counts flight hours and flights per item since its last logged service,
+// from the armed time in the statistics rollups, and raises reminders when an
+// item's configured interval is exceeded
+type Tracker struct {
+	mu        sync.Mutex
+	path      string
+	reminders []Reminder
+	st        state
+}
+
+// Global is the process-wide maintenance tracker
+var Global = &Tracker{st: state{Items: make(map[string]*itemState)}}
+
+// Configure sets the reminders and loads the service log from path. A missing file is not an error.
+func (t *Tracker) Configure(path string, reminders []Reminder) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.path = path
+	t.reminders = reminders
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read maintenance log: %w", err)
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("failed to parse maintenance log: %w", err)
+	}
+	if st.Items == nil {
+		st.Items = make(map[string]*itemState)
+	}
+	t.st = st
+	return nil
+}
+
+// Log records a maintenance action and restarts the item's counters
+func (t *Tracker) Log(item, action, note string) (Event, error) {
+	item = strings.TrimSpace(item)
+	if item == "" {
+		return Event{}, fmt.Errorf("item is required")
+	}
+	if action == "" {
+		action = "serviced"
+	}
+	armed, flights := rollup.Global.FlightTotals()
+	hours := armed.Hours()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ev := Event{Time: time.Now(), Item: item, Action: action, Note: note, FlightHours: hours}
+	t.st.Items[item] = &itemState{HoursAt: hours, FlightsAt: flights, ServicedOn: ev.Time}
+	t.st.Events = append(t.st.Events, ev)
+	if len(t.st.Events) > maxEvents {
+		t.st.Events = t.st.Events[len(t.st.Events)-maxEvents:]
+	}
+	t.saveLocked()
+	logger.Info("[MAINT] %s %s at %.1f flight hours", item, action, hours)
+	return ev, nil
+}
+
+// statusLocked returns the counters of every configured or logged item (caller holds t.mu)
+func (t *Tracker) statusLocked(hours float64, flights int) []ItemStatus {
+	limits := make(map[string]Reminder, len(t.reminders))
+	for _, r := range t.reminders {
+		limits[r.Item] = r
+	}
+	names := make([]string, 0, len(limits)+len(t.st.Items))
+	for name := range limits {
+		names = append(names, name)
+	}
+	for name := range t.st.Items {
+		if _, ok := limits[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	items := make([]ItemStatus, 0, len(names))
+	for _, name := range names {
+		s := ItemStatus{Item: name, Hours: hours, Flights: flights}
+		if st := t.st.Items[name]; st != nil {
+			s.Hours -= st.HoursAt
+			s.Flights -= st.FlightsAt
+			on := st.ServicedOn
+			s.LastServiced = &on
+		}
+		if r, ok := limits[name]; ok {
+			s.HoursLimit = r.Hours
+			s.FlightsLimit = r.Flights
+			s.Due = (r.Hours > 0 && s.Hours >= r.Hours) || (r.Flights > 0 && s.Flights >= r.Flights)
+		}
+		items = append(items, s)
+	}
+	return items
+}
+
+// Status returns the item counters and the most recent service events, newest first
+func (t *Tracker) Status() map[string]interface{} {
+	armed, flights := rollup.Global.FlightTotals()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := t.st.Events
+	if len(events) > 50 {
+		events = events[len(events)-50:]
+	}
+	recent := make([]Event, len(events))
+	for i, ev := range events {
+		recent[len(events)-1-i] = ev
+	}
+	return map[string]interface{}{
+		"flightHours": armed.Hours(),
+		"flights":     flights,
+		"items":       t.statusLocked(armed.Hours(), flights),
+		"events":      recent,
+	}
+}
+
+// Due returns the items whose service interval is exceeded
+func (t *Tracker) Due() []string {
+	armed, flights := rollup.Global.FlightTotals()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	due := []string{}
+	for _, s := range t.statusLocked(armed.Hours(), flights) {
+		if s.Due {
+			due = append(due, s.Item)
+		}
+	}
+	return due
+}
+
+// Check raises a reminder once per interval for each item that became due
+func (t *Tracker) Check() {
+	armed, flights := rollup.Global.FlightTotals()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changed := false
+	for _, s := range t.statusLocked(armed.Hours(), flights) {
+		if !s.Due {
+			continue
+		}
+		st := t.st.Items[s.Item]
+		if st == nil {
+			st = &itemState{}
+			t.st.Items[s.Item] = st
+		}
+		if st.Reminded {
+			continue
+		}
+		st.Reminded = true
+		changed = true
+		msg := fmt.Sprintf("Maintenance due: %s (%.1f h, %d flights since last service)", s.Item, s.Hours, s.Flights)
+		logger.Warn("[MAINT] %s", msg)
+		metrics.Global.AddLog("WARN", msg)
+		alerts.Raise("maintenance", alerts.SeverityWarning, msg)
+	}
+	if changed {
+		t.saveLocked()
+	}
+}
+
+// Run checks the reminders every interval until stopCh is closed
+func (t *Tracker) Run(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			t.Check()
+		}
+	}
+}
+
+// saveLocked persists the service log atomically (caller holds t.mu)
+func (t *Tracker) saveLocked() {
+	if t.path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(t.st, "", "  ")
+	if err != nil {
+		logger.Warn("[MAINT] Failed to encode maintenance log: %v", err)
+		return
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		logger.Warn("[MAINT] Failed to write maintenance log: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		logger.Warn("[MAINT] Failed to write maintenance log: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package maintenance
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// ModeStatus is the state of maintenance mode for the web API
+type ModeStatus struct {
+	Active           bool       `json:"active"`
+	Reason           string     `json:"reason,omitempty"`
+	By               string     `json:"by,omitempty"`
+	Since            *time.Time `json:"since,omitempty"`
+	Until            *time.Time `json:"until,omitempty"`
+	RemainingSeconds float64    `json:"remainingSeconds,omitempty"`
+	DefaultMinutes   float64    `json:"defaultMinutes"`
+	MaxMinutes       float64    `json:"maxMinutes"`
+	LastExit         string     `json:"lastExit,omitempty"` // Why the last window ended
+}
+
+// Window is the time-limited maintenance mode for bench work: while it is
+// active the forwarder keeps telemetry off the cloud, the session stays up with
+// a maintenance flag for the router and the dead-man actions are suspended.
+// It always ends on its own when the timeout runs out.
+type Window struct {
+	active atomic.Bool // Read by the forwarder for every frame
+
+	mu         sync.Mutex
+	defaultDur time.Duration
+	maxDur     time.Duration
+	reason     string
+	by         string
+	since      time.Time
+	until      time.Time
+	timer      *time.Timer
+	lastExit   string
+	observer   func(ModeStatus)
+}
+
+// Mode is the process-wide maintenance mode
+var Mode = &Window{defaultDur: 30 * time.Minute, maxDur: 4 * time.Hour}
+
+// SetLimits sets the duration used when none is requested and the longest allowed
+func (w *Window) SetLimits(defaultDur, maxDur time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.defaultDur = defaultDur
+	w.maxDur = maxDur
+}
+
+// SetObserver sets a function called with the new status whenever the mode is
+// entered, extended or left (e.g. to tell the router)
+func (w *Window) SetObserver(fn func(ModeStatus)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.observer = fn
+}
+
+// Active reports whether maintenance mode is on
+func (w *Window) Active() bool {
+	return w.active.Load()
+}
+
+// Enter starts maintenance mode for d (0 = the default duration). Entering again
+// while active restarts the timeout from now.
+func (w *Window) Enter(d time.Duration, reason, by string) (ModeStatus, error) {
+	w.mu.Lock()
+	if d == 0 {
+		d = w.defaultDur
+	}
+	if d < 0 || d > w.maxDur {
+		max := w.maxDur
+		w.mu.Unlock()
+		return ModeStatus{}, fmt.Errorf("duration must be positive and at most %v", max)
+	}
+
+	now := time.Now()
+	extended := w.active.Load()
+	if !extended {
+		w.since = now
+	}
+	w.reason = reason
+	w.by = by
+	w.until = now.Add(d)
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(d, w.expire)
+	w.active.Store(true)
+	status := w.statusLocked(now)
+	observer := w.observer
+	w.mu.Unlock()
+
+	msg := fmt.Sprintf("Maintenance mode on until %s (%s)", status.Until.Format("15:04:05"), reason)
+	if extended {
+		msg = fmt.Sprintf("Maintenance mode extended until %s (%s)", status.Until.Format("15:04:05"), reason)
+	}
+	logger.Info("[MAINT] %s", msg)
+	metrics.Global.AddLog("INFO", msg)
+	if observer != nil {
+		observer(status)
+	}
+	return status, nil
+}
+
+// Exit ends maintenance mode; why is kept as the last exit reason. Returns false when it was not active.
+func (w *Window) Exit(why string) bool {
+	w.mu.Lock()
+	if !w.active.Load() {
+		w.mu.Unlock()
+		return false
+	}
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.exitLocked(why)
+	status := w.statusLocked(time.Now())
+	observer := w.observer
+	w.mu.Unlock()
+
+	if observer != nil {
+		observer(status)
+	}
+	return true
+}
+
+// expire ends maintenance mode when its timeout runs out
+func (w *Window) expire() {
+	w.mu.Lock()
+	now := time.Now()
+	if !w.active.Load() || now.Before(w.until) {
+		w.mu.Unlock()
+		return // Left or extended meanwhile
+	}
+	w.timer = nil
+	w.exitLocked("timeout")
+	status := w.statusLocked(now)
+	observer := w.observer
+	w.mu.Unlock()
+
+	if observer != nil {
+		observer(status)
+	}
+}
+
+// exitLocked clears the window and logs how long it lasted (caller holds w.mu)
+func (w *Window) exitLocked(why string) {
+	w.active.Store(false)
+	w.lastExit = why
+	msg := fmt.Sprintf("Maintenance mode off after %v (%s)", time.Since(w.since).Round(time.Second), why)
+	logger.Info("[MAINT] %s", msg)
+	metrics.Global.AddLog("INFO", msg)
+}
+
+// Status returns the current state of maintenance mode
+func (w *Window) Status() ModeStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.statusLocked(time.Now())
+}
+
+// statusLocked builds the status (caller holds w.mu)
+func (w *Window) statusLocked(now time.Time) ModeStatus {
+	s := ModeStatus{
+		Active:         w.active.Load(),
+		DefaultMinutes: w.defaultDur.Minutes(),
+		MaxMinutes:     w.maxDur.Minutes(),
+		LastExit:       w.lastExit,
+	}
+	if s.Active {
+		since, until := w.since, w.until
+		s.Reason = w.reason
+		s.By = w.by
+		s.Since = &since
+		s.Until = &until
+		s.RemainingSeconds = until.Sub(now).Seconds()
+	}
+	return s
+}
@@ -1,13 +1,95 @@
 package mavlink_custom
 
 import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
 	"github.com/bluenviron/gomavlib/v3/pkg/dialect"
 	"github.com/bluenviron/gomavlib/v3/pkg/dialects/all"
 	"github.com/bluenviron/gomavlib/v3/pkg/message"
 )
 
+// SESSION_HEARTBEAT field layouts
+const (
+	LayoutV1 = 1 // SESSION_HEARTBEAT: 32-bit expires_at
+	LayoutV2 = 2 // SESSION_HEARTBEAT_V2: 64-bit expires_at
+
+	// SupportedLayouts is the bitmask of layouts this build can send (bit N = layout N)
+	SupportedLayouts byte = 1<<LayoutV1 | 1<<LayoutV2
+)
+
+// Identity is the message IDs and field layout of the session messages. The
+// defaults can be changed in the config and the router may assign others
+// during auth (RATE_POLICY), e.g. when 42999 collides with a vendor extension.
+type Identity struct {
+	HeartbeatID    uint32 // SESSION_HEARTBEAT message ID
+	HeartbeatAckID uint32 // SESSION_HEARTBEAT_ACK message ID
+	Layout         uint8  // SESSION_HEARTBEAT field layout (LayoutV1, LayoutV2)
+}
+
+// DefaultIdentity is the identity routers assume when none is negotiated
+var DefaultIdentity = Identity{HeartbeatID: 42999, HeartbeatAckID: 42998, Layout: LayoutV1}
+
+var identity atomic.Pointer[Identity]
+
+func init() {
+	id := DefaultIdentity
+	identity.Store(&id)
+}
+
+// CurrentIdentity returns the identity the session messages use now
+func CurrentIdentity() Identity {
+	return *identity.Load()
+}
+
+// SetIdentity switches the session messages to a new identity. Dialects built
+// afterwards (GetCombinedDialect) use it; nodes and read/writers built before
+// must be rebuilt. Reports whether anything changed.
+func SetIdentity(id Identity) (bool, error) {
+	if err := id.Validate(); err != nil {
+		return false, err
+	}
+	if CurrentIdentity() == id {
+		return false, nil
+	}
+	identity.Store(&id)
+	return true, nil
+}
+
+// Validate checks that the IDs fit MAVLink 2, don't clash with each other or a
+// standard message and that the layout is supported
+func (id Identity) Validate() error {
+	if id.HeartbeatID == 0 || id.HeartbeatID > 0xFFFFFF || id.HeartbeatAckID == 0 || id.HeartbeatAckID > 0xFFFFFF {
+		return fmt.Errorf("message IDs must be between 1 and 16777215")
+	}
+	if id.HeartbeatID == id.HeartbeatAckID {
+		return fmt.Errorf("SESSION_HEARTBEAT and SESSION_HEARTBEAT_ACK need different IDs (both %d)", id.HeartbeatID)
+	}
+	if id.Layout > 7 || SupportedLayouts&(1<<id.Layout) == 0 {
+		return fmt.Errorf("unsupported SESSION_HEARTBEAT layout %d", id.Layout)
+	}
+	for _, msg := range all.Dialect.Messages {
+		if msg.GetID() == id.HeartbeatID || msg.GetID() == id.HeartbeatAckID {
+			return fmt.Errorf("message ID %d is already used by the standard dialect", msg.GetID())
+		}
+	}
+	return nil
+}
+
+// IsSessionMessage reports whether a message ID is one of the current session messages
+func IsSessionMessage(id uint32) bool {
+	cur := identity.Load()
+	return id == cur.HeartbeatID || id == cur.HeartbeatAckID
+}
+
+// String formats the identity for logs
+func (id Identity) String() string {
+	return fmt.Sprintf("SESSION_HEARTBEAT %d (layout %d), ACK %d", id.HeartbeatID, id.Layout, id.HeartbeatAckID)
+}
+
 // MessageSessionHeartbeat is a custom MAVLink message for session token synchronization
-// Message ID: 42999 (Changed from 42000 to avoid conflicts)
+// Message ID: Identity.HeartbeatID (default 42999), layout 1
 type MessageSessionHeartbeat struct {
 	Token     [32]byte // Session token (32 bytes binary)
 	ExpiresAt uint32   // Session expiration timestamp (Unix time)
@@ -16,22 +98,72 @@ type MessageSessionHeartbeat struct {
 
 // GetID implements the Message interface
 func (*MessageSessionHeartbeat) GetID() uint32 {
-	return 42999
+	return identity.Load().HeartbeatID
+}
+
+// MessageSessionHeartbeatV2 is SESSION_HEARTBEAT in layout 2, with a 64-bit expiry
+// Message ID: Identity.HeartbeatID
+type MessageSessionHeartbeatV2 struct {
+	Token     [32]byte // Session token (32 bytes binary)
+	ExpiresAt uint64   // Session expiration timestamp (Unix time)
+	Sequence  uint16   // Sequence number for tracking
+}
+
+// GetID implements the Message interface
+func (*MessageSessionHeartbeatV2) GetID() uint32 {
+	return identity.Load().HeartbeatID
+}
+
+// NewSessionHeartbeat builds a SESSION_HEARTBEAT in the current layout
+func NewSessionHeartbeat(token [32]byte, expiresAt time.Time, sequence uint16) message.Message {
+	if identity.Load().Layout == LayoutV2 {
+		return &MessageSessionHeartbeatV2{Token: token, ExpiresAt: uint64(expiresAt.Unix()), Sequence: sequence}
+	}
+	return &MessageSessionHeartbeat{Token: token, ExpiresAt: uint32(expiresAt.Unix()), Sequence: sequence}
 }
 
-// GetCombinedDialect creates a dialect that includes both all standard and custom messages
+// SessionHeartbeatSequence returns the sequence number of a SESSION_HEARTBEAT in any layout
+func SessionHeartbeatSequence(msg message.Message) (uint16, bool) {
+	switch hb := msg.(type) {
+	case *MessageSessionHeartbeat:
+		return hb.Sequence, true
+	case *MessageSessionHeartbeatV2:
+		return hb.Sequence, true
+	}
+	return 0, false
+}
+
+// MessageSessionHeartbeatAck is the router's reply to SESSION_HEARTBEAT, confirming
+// that the UDP endpoint the heartbeat arrived from is registered for the session
+// Message ID: Identity.HeartbeatAckID (default 42998)
+type MessageSessionHeartbeatAck struct {
+	Sequence uint16 // Sequence number of the acknowledged SESSION_HEARTBEAT
+	Status   uint8  // SessionAckRegistered or SessionAckRejected
+}
+
+// GetID implements the Message interface
+func (*MessageSessionHeartbeatAck) GetID() uint32 {
+	return identity.Load().HeartbeatAckID
+}
+
+// SESSION_HEARTBEAT_ACK status values
+const (
+	SessionAckRegistered = 0 // Endpoint registered, telemetry is accepted
+	SessionAckRejected   = 1 // Token unknown or expired, telemetry is dropped
+)
+
+// GetCombinedDialect creates a dialect that includes both all standard and the
+// custom messages, with the IDs and heartbeat layout of the current identity
 func GetCombinedDialect() *dialect.Dialect {
-	// First, check if our ID is already in all.Dialect (extremely unlikely for 42999)
-	for _, msg := range all.Dialect.Messages {
-		if msg.GetID() == 42999 {
-			return all.Dialect // Already exists, just return all
-		}
+	var heartbeat message.Message = &MessageSessionHeartbeat{}
+	if identity.Load().Layout == LayoutV2 {
+		heartbeat = &MessageSessionHeartbeatV2{}
 	}
 
 	// Create a NEW slice to avoid modifying the original all.Dialect global slice
-	allMsgs := make([]message.Message, len(all.Dialect.Messages))
+	allMsgs := make([]message.Message, len(all.Dialect.Messages), len(all.Dialect.Messages)+2)
 	copy(allMsgs, all.Dialect.Messages)
-	allMsgs = append(allMsgs, &MessageSessionHeartbeat{})
+	allMsgs = append(allMsgs, heartbeat, &MessageSessionHeartbeatAck{})
 
 	customDialect := &dialect.Dialect{
 		Version:  all.Dialect.Version,
@@ -0,0 +1,54 @@
+package mavlink_custom
+
+import (
+	"sync"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialect"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+)
+
+var (
+	sizeMu       sync.Mutex
+	sizeRW       *dialect.ReadWriter
+	sizeIdentity Identity // Identity sizeRW was built for
+)
+
+// sizeReadWriter returns a read/writer for the current identity, rebuilt when it changes
+func sizeReadWriter() *dialect.ReadWriter {
+	sizeMu.Lock()
+	defer sizeMu.Unlock()
+	if id := CurrentIdentity(); sizeRW == nil || id != sizeIdentity {
+		sizeRW, _ = dialect.NewReadWriter(GetCombinedDialect())
+		sizeIdentity = id
+	}
+	return sizeRW
+}
+
+// FrameSize returns the on-wire size of a frame in bytes (header + payload + checksum + signature)
+func FrameSize(fr frame.Frame) int {
+	v2, isV2 := fr.(*frame.V2Frame)
+
+	payload := 0
+	if raw, ok := fr.GetMessage().(*message.MessageRaw); ok {
+		payload = len(raw.Payload)
+	} else if rw := sizeReadWriter(); rw != nil {
+		if mrw := rw.GetMessage(fr.GetMessage().GetID()); mrw != nil {
+			payload = len(mrw.Write(fr.GetMessage(), isV2).Payload)
+		}
+	}
+
+	if !isV2 {
+		return 6 + payload + 2
+	}
+	size := 10 + payload + 2
+	if v2.Signature != nil {
+		size += 13
+	}
+	return size
+}
+
+// MessageSize returns the on-wire size of a message sent as an unsigned MAVLink v2 frame
+func MessageSize(msg message.Message) int {
+	return FrameSize(&frame.V2Frame{Message: msg})
+}
@@ -0,0 +1,317 @@
+package mesh
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/traffic"
+)
+
+const (
+	maxClockSkew   = 30 * time.Second // Reject reports this far from our clock (replays)
+	ownshipMaxAge  = 5 * time.Second  // Stop broadcasting when our position is older than this
+	maxPacketBytes = 2048
+)
+
+// Config controls peer position exchange
+type Config struct {
+	Port        int           // UDP port shared by all bridges
+	BroadcastIP string        // Destination address (default: 255.255.255.255)
+	Key         string        // Shared key; reports with a wrong HMAC are ignored
+	Interval    time.Duration // Broadcast interval
+	StaleAfter  time.Duration // Peers not heard for this long are dropped
+}
+
+// Report is the position one bridge broadcasts to the others
+type Report struct {
+	ID        string  `json:"id"` // Drone UUID
+	Name      string  `json:"name"`
+	Timestamp int64   `json:"ts"` // Unix milliseconds
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	Alt       float64 `json:"alt"`    // meters AMSL
+	AltRel    float64 `json:"altRel"` // meters above home
+	VN        float64 `json:"vn"`     // m/s north
+	VE        float64 `json:"ve"`     // m/s east
+	VU        float64 `json:"vu"`     // m/s up
+	Heading   float64 `json:"hdg"`    // degrees
+}
+
+// packet is the signed wire format
+type packet struct {
+	Report json.RawMessage `json:"report"`
+	MAC    string          `json:"mac"` // hex HMAC-SHA256 of Report
+}
+
+// Peer is a nearby fleet member
+type Peer struct {
+	Report
+	Address  string    `json:"address"`
+	LastSeen time.Time `json:"lastSeen"`
+	Reports  int       `json:"reports"`
+}
+
+// Mesh exchanges GLOBAL_POSITION_INT between bridges on the local network
+type Mesh struct {
+	mu        sync.Mutex
+	cfg       Config
+	selfID    string
+	selfName  func() string
+	ownship   *Report
+	ownUpdate time.Time
+	peers     map[string]*Peer
+	rejected  int
+	sent      int
+	running   bool
+}
+
+// Global is the process-wide mesh
+var Global = New()
+
+// New creates an idle mesh
+func New() *Mesh {
+	return &Mesh{peers: make(map[string]*Peer)}
+}
+
+// Configure sets our identity and exchange settings. name is called per broadcast
+// so renames through the identity API are picked up.
+func (m *Mesh) Configure(cfg Config, selfID string, name func() string) {
+	if cfg.BroadcastIP == "" {
+		cfg.BroadcastIP = "255.255.255.255"
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = cfg
+	m.selfID = selfID
+	m.selfName = name
+}
+
+// UpdateOwnship records our position from GLOBAL_POSITION_INT
+func (m *Mesh) UpdateOwnship(msg *common.MessageGlobalPositionInt) {
+	r := &Report{
+		Lat:    float64(msg.Lat) / 1e7,
+		Lon:    float64(msg.Lon) / 1e7,
+		Alt:    float64(msg.Alt) / 1000,
+		AltRel: float64(msg.RelativeAlt) / 1000,
+		VN:     float64(msg.Vx) / 100,
+		VE:     float64(msg.Vy) / 100,
+		VU:     -float64(msg.Vz) / 100,
+	}
+	if msg.Hdg != math.MaxUint16 {
+		r.Heading = float64(msg.Hdg) / 100
+	}
+	m.mu.Lock()
+	m.ownship = r
+	m.ownUpdate = time.Now()
+	m.mu.Unlock()
+}
+
+// Run broadcasts our position and listens for peers until stopCh is closed
+func (m *Mesh) Run(stopCh <-chan struct{}) error {
+	m.mu.Lock()
+	cfg := m.cfg
+	m.mu.Unlock()
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: cfg.Port})
+	if err != nil {
+		return fmt.Errorf("failed to listen on mesh port %d: %w", cfg.Port, err)
+	}
+	dest := &net.UDPAddr{IP: net.ParseIP(cfg.BroadcastIP), Port: cfg.Port}
+	if dest.IP == nil {
+		conn.Close()
+		return fmt.Errorf("invalid mesh broadcast address %q", cfg.BroadcastIP)
+	}
+
+	m.mu.Lock()
+	m.running = true
+	m.mu.Unlock()
+	logger.Info("[MESH] Exchanging positions on UDP %d (broadcast %s)", cfg.Port, cfg.BroadcastIP)
+
+	go m.receive(conn)
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			conn.Close()
+			m.mu.Lock()
+			m.running = false
+			m.mu.Unlock()
+			return nil
+		case <-ticker.C:
+			if data := m.encode(); data != nil {
+				if _, err := conn.WriteToUDP(data, dest); err != nil {
+					logger.Debug("[MESH] Broadcast failed: %v", err)
+				} else {
+					m.mu.Lock()
+					m.sent++
+					m.mu.Unlock()
+				}
+			}
+			m.prune()
+		}
+	}
+}
+
+// encode signs our current position, or returns nil if it is stale
+func (m *Mesh) encode() []byte {
+	m.mu.Lock()
+	if m.ownship == nil || time.Since(m.ownUpdate) > ownshipMaxAge {
+		m.mu.Unlock()
+		return nil
+	}
+	r := *m.ownship
+	r.ID = m.selfID
+	nameFn := m.selfName
+	key := m.cfg.Key
+	m.mu.Unlock()
+
+	if nameFn != nil {
+		r.Name = nameFn()
+	}
+	r.Timestamp = time.Now().UnixMilli()
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil
+	}
+	data, _ := json.Marshal(packet{Report: body, MAC: sign(key, body)})
+	return data
+}
+
+func sign(key string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (m *Mesh) receive(conn *net.UDPConn) {
+	buf := make([]byte, maxPacketBytes)
+	for {
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return // Closed on stop
+		}
+		if err := m.handle(buf[:n], addr); err != nil {
+			logger.Debug("[MESH] Ignored report from %s: %v", addr, err)
+			m.mu.Lock()
+			m.rejected++
+			m.mu.Unlock()
+		}
+	}
+}
+
+// handle verifies a received report and records the peer
+func (m *Mesh) handle(data []byte, addr *net.UDPAddr) error {
+	var p packet
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("malformed packet: %w", err)
+	}
+
+	m.mu.Lock()
+	key, selfID := m.cfg.Key, m.selfID
+	m.mu.Unlock()
+
+	if !hmac.Equal([]byte(p.MAC), []byte(sign(key, p.Report))) {
+		return fmt.Errorf("bad signature")
+	}
+	var r Report
+	if err := json.Unmarshal(p.Report, &r); err != nil {
+		return fmt.Errorf("malformed report: %w", err)
+	}
+	if r.ID == "" || r.ID == selfID {
+		return nil // Our own broadcast looped back
+	}
+	if skew := time.Since(time.UnixMilli(r.Timestamp)); skew > maxClockSkew || skew < -maxClockSkew {
+		return fmt.Errorf("timestamp %v off", skew.Round(time.Second))
+	}
+
+	m.mu.Lock()
+	peer, ok := m.peers[r.ID]
+	if !ok {
+		peer = &Peer{}
+		m.peers[r.ID] = peer
+		logger.Info("[MESH] Discovered peer %s (%s) at %s", r.Name, r.ID, addr.IP)
+	} else if r.Timestamp <= peer.Timestamp {
+		m.mu.Unlock()
+		return nil // Out of order
+	}
+	peer.Report = r
+	peer.Address = addr.IP.String()
+	peer.LastSeen = time.Now()
+	peer.Reports++
+	m.mu.Unlock()
+
+	callsign := r.Name
+	if callsign == "" {
+		callsign = r.ID
+	}
+	traffic.Global.Update(&traffic.Contact{
+		ID:        "peer:" + r.ID,
+		Source:    "peer",
+		Callsign:  callsign,
+		Lat:       r.Lat,
+		Lon:       r.Lon,
+		Alt:       r.Alt,
+		Heading:   math.Mod(math.Atan2(r.VE, r.VN)*180/math.Pi+360, 360),
+		GroundSpd: math.Hypot(r.VN, r.VE),
+		ClimbRate: r.VU,
+	})
+	return nil
+}
+
+// prune drops peers that stopped reporting
+func (m *Mesh) prune() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cfg.StaleAfter <= 0 {
+		return
+	}
+	for id, p := range m.peers {
+		if time.Since(p.LastSeen) > m.cfg.StaleAfter {
+			logger.Info("[MESH] Lost peer %s (%s)", p.Name, id)
+			delete(m.peers, id)
+		}
+	}
+}
+
+// Snapshot returns nearby peers sorted by name
+func (m *Mesh) Snapshot() map[string]interface{} {
+	m.prune()
+	m.mu.Lock()
+	peers := make([]Peer, 0, len(m.peers))
+	for _, p := range m.peers {
+		peers = append(peers, *p)
+	}
+	running, port, sent, rejected := m.running, m.cfg.Port, m.sent, m.rejected
+	m.mu.Unlock()
+
+	sort.Slice(peers, func(i, j int) bool {
+		if peers[i].Name != peers[j].Name {
+			return peers[i].Name < peers[j].Name
+		}
+		return peers[i].ID < peers[j].ID
+	})
+	return map[string]interface{}{
+		"enabled":  running,
+		"port":     port,
+		"peers":    peers,
+		"count":    len(peers),
+		"sent":     sent,
+		"rejected": rejected,
+	}
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// checkpoint is the on-disk form of the cumulative counters
+type checkpoint struct {
+	SentPackets         map[string]int64 `json:"sent_packets"`
+	FailedPackets       map[string]int64 `json:"failed_packets"`
+	FailedUnhealthy     map[string]int64 `json:"failed_unhealthy"`
+	FailedSend          map[string]int64 `json:"failed_send"`
+	QueueOverflows      map[string]int64 `json:"queue_overflows"`
+	BatchDropped        map[string]int64 `json:"batch_dropped"`
+	StaleDropped        map[string]int64 `json:"stale_dropped"`
+	StaleFlagged        map[string]int64 `json:"stale_flagged"`
+	BandPackets         map[string]int64 `json:"band_packets"`
+	BandBytes           map[string]int64 `json:"band_bytes"`
+	Migrations          int64            `json:"migrations"`
+	MigrationFramesLost int64            `json:"migration_lost"`
+	Reconnects          int64            `json:"reconnects"`
+	Restarts            int64            `json:"restarts"`
+	CountersSince       time.Time        `json:"counters_since"`
+	SavedAt             time.Time        `json:"saved_at"`
+}
+
+// Restore loads counters from a previous checkpoint and counts this start as a restart.
+// A missing file is not an error - counters simply start from zero.
+func (m *Metrics) Restore(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read metrics checkpoint: %w", err)
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return fmt.Errorf("failed to parse metrics checkpoint: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	addCounts(m.SentPackets, cp.SentPackets)
+	addCounts(m.FailedPackets, cp.FailedPackets)
+	addCounts(m.FailedUnhealthy, cp.FailedUnhealthy)
+	addCounts(m.FailedSend, cp.FailedSend)
+	addCounts(m.QueueOverflows, cp.QueueOverflows)
+	addCounts(m.BatchDropped, cp.BatchDropped)
+	addCounts(m.StaleDropped, cp.StaleDropped)
+	addCounts(m.StaleFlagged, cp.StaleFlagged)
+	addCounts(m.BandPackets, cp.BandPackets)
+	addCounts(m.BandBytes, cp.BandBytes)
+	m.Migrations += cp.Migrations
+	m.MigrationFramesLost += cp.MigrationFramesLost
+	m.Reconnects += cp.Reconnects
+	m.Restarts = cp.Restarts + 1
+	if !cp.CountersSince.IsZero() {
+		m.CountersSince = cp.CountersSince
+	}
+	return nil
+}
+
+func addCounts(dst, src map[string]int64) {
+	for k, v := range src {
+		dst[k] += v
+	}
+}
+
+func copyCounts(src map[string]int64) map[string]int64 {
+	out := make(map[string]int64, len(src))
+	for k, v := range src {
+		out[k] = v
+	}
+	return out
+}
+
+// Checkpoint writes the cumulative counters to path (atomically via rename)
+func (m *Metrics) Checkpoint(path string) error {
+	m.mu.RLock()
+	cp := checkpoint{
+		SentPackets:         copyCounts(m.SentPackets),
+		FailedPackets:       copyCounts(m.FailedPackets),
+		FailedUnhealthy:     copyCounts(m.FailedUnhealthy),
+		FailedSend:          copyCounts(m.FailedSend),
+		QueueOverflows:      copyCounts(m.QueueOverflows),
+		BatchDropped:        copyCounts(m.BatchDropped),
+		StaleDropped:        copyCounts(m.StaleDropped),
+		StaleFlagged:        copyCounts(m.StaleFlagged),
+		BandPackets:         copyCounts(m.BandPackets),
+		BandBytes:           copyCounts(m.BandBytes),
+		Migrations:          m.Migrations,
+		MigrationFramesLost: m.MigrationFramesLost,
+		Reconnects:          m.Reconnects,
+		Restarts:            m.Restarts,
+		CountersSince:       m.CountersSince,
+		SavedAt:             time.Now(),
+	}
+	m.mu.RUnlock()
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write metrics checkpoint: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// RunCheckpoints checkpoints every interval until stopCh is closed
+func (m *Metrics) RunCheckpoints(path string, interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if err := m.Checkpoint(path); err != nil {
+				m.AddLog("WARN", err.Error())
+			}
+		}
+	}
+}
@@ -14,6 +14,17 @@ type Metrics struct {
 	FailedPackets    map[string]int64
 	FailedUnhealthy  map[string]int64 // Failed due to unhealthy state
 	FailedSend       map[string]int64 // Failed due to send error
+	QueueOverflows   map[string]int64 // Writes dropped because a direction's write queue was full
+	BatchDropped     map[string]int64 // Batched frames lost per reason ("compress", "send", "no_socket")
+	StaleDropped     map[string]int64 // Command-class messages to the FC dropped as too old
+	StaleFlagged     map[string]int64 // Command-class messages to the FC forwarded although too old
+	BandPackets      map[string]int64 // Messages sent per band (origin and destination, see Band*)
+	BandBytes        map[string]int64 // On-wire bytes sent per band
+	EchoSuppressed   map[string]int64 // Bridge-originated writes received back on the listener and dropped
+
+	// Frames with message IDs outside the dialect (vendor extensions), per band and ID
+	UnknownReceived map[string]map[uint32]int64
+	UnknownDropped  map[string]map[uint32]int64 // Dropped because forwarding.pass_unknown is off
 
 	// System status
 	CurrentIP  string
@@ -25,10 +36,54 @@ type Metrics struct {
 	SessionExpiresAt time.Time
 	RefreshInterval  time.Duration
 
+	// Uplink migration (IP change handling)
+	Migrations          int64
+	LastMigrationAt     time.Time
+	LastMigrationTime   time.Duration
+	MigrationFramesLost int64 // Frames that failed to send while the uplink was migrating
+
+	// Reconnect coordinator state machine
+	ReconnectState  string
+	ReconnectReason string
+	ReconnectSince  time.Time
+	Reconnects      int64
+
+	// Uplink registration confirmed by the router (SESSION_HEARTBEAT_ACK)
+	UplinkState   string    // "blind", "registered" or "rejected" (empty until the first session heartbeat)
+	UplinkSince   time.Time // Last state change
+	UplinkLastAck time.Time
+	UplinkAcks    int64
+
+	// Upstream server failover (network.servers)
+	UpstreamServer string // Router address in use
+	Failovers      int64
+	LastFailover   time.Time
+
+	// Node event streams per source ("fc", "server"), see forwarder eventstats.go
+	EventStreams map[string]EventStage
+
+	// Restart persistence (see checkpoint.go)
+	Restarts      int64     // Process restarts since counters were first recorded
+	CountersSince time.Time // When the persisted counters started accumulating
+
 	// Logs
 	RecentLogs []LogEntry
 }
 
+
+// EventStage is the state of one node's event stream and the loop consuming it
+type EventStage struct {
+	Consumer     string  `json:"consumer"`     // Loop handling the events
+	Events       uint64  `json:"events"`       // Frames handled
+	Depth        int     `json:"depth"`        // Events waiting in the buffer
+	MaxDepth     int     `json:"max_depth"`    // Highest depth seen
+	Capacity     int     `json:"capacity"`     // Buffer size
+	Backpressure uint64  `json:"backpressure"` // Times the buffer was full and the node's reader blocked
+	BlockedMs    float64 `json:"blocked_ms"`   // Total time the reader was blocked
+	Stalls       uint64  `json:"stalls"`       // Events the consumer took too long to handle
+	Lost         uint64  `json:"lost"`         // Frames missing from the sequence numbers
+}
+
 type LogEntry struct {
 	Time    time.Time `json:"time"`
 	Level   string    `json:"level"`
@@ -47,7 +102,18 @@ func New() *Metrics {
 		FailedPackets:   make(map[string]int64),
 		FailedUnhealthy: make(map[string]int64),
 		FailedSend:      make(map[string]int64),
+		QueueOverflows:  make(map[string]int64),
+		BatchDropped:    make(map[string]int64),
+		StaleDropped:    make(map[string]int64),
+		StaleFlagged:    make(map[string]int64),
+		BandPackets:     make(map[string]int64),
+		BandBytes:       make(map[string]int64),
+		EchoSuppressed:  make(map[string]int64),
+		UnknownReceived: make(map[string]map[uint32]int64),
+		UnknownDropped:  make(map[string]map[uint32]int64),
+		EventStreams:    make(map[string]EventStage),
 		StartTime:       time.Now(),
+		CountersSince:   time.Now(),
 		RecentLogs:      make([]LogEntry, 0, 100),
 		AuthStatus:      "Initializing",
 	}
@@ -118,6 +184,185 @@ func (m *Metrics) SetSessionInfo(expiresAt time.Time, interval time.Duration) {
 	m.RefreshInterval = interval
 }
 
+// Bands split traffic by origin and destination
+const (
+	BandFCToServer = "fc_to_server" // Pixhawk telemetry forwarded to the router
+	BandServerToFC = "server_to_fc" // Cloud commands forwarded to the Pixhawk
+	BandWebToFC    = "web_to_fc"    // Local dashboard/API requests to the Pixhawk
+	BandBridge     = "bridge"       // Generated by the bridge itself (session heartbeats, sensors)
+)
+
+// AddBand counts a message of the given on-wire size sent in a band
+func (m *Metrics) AddBand(band string, bytes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.BandPackets[band]++
+	m.BandBytes[band] += int64(bytes)
+}
+
+// Totals returns the cumulative messages and bytes forwarded between the FC and
+// the router, and the uplink reconnect count (used by the statistics rollups)
+func (m *Metrics) Totals() (messages, bytes, reconnects int64) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, band := range []string{BandFCToServer, BandServerToFC} {
+		messages += m.BandPackets[band]
+	}
+	for _, band := range []string{BandFCToServer, BandServerToFC, BandBridge} {
+		bytes += m.BandBytes[band]
+	}
+	return messages, bytes, m.Reconnects
+}
+
+// IncUnknown counts a frame whose message ID is not in the dialect
+func (m *Metrics) IncUnknown(band string, msgID uint32, dropped bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	countID(m.UnknownReceived, band, msgID)
+	if dropped {
+		countID(m.UnknownDropped, band, msgID)
+	}
+}
+
+func countID(counts map[string]map[uint32]int64, band string, msgID uint32) {
+	if counts[band] == nil {
+		counts[band] = make(map[uint32]int64)
+	}
+	counts[band][msgID]++
+}
+
+// IncEchoSuppressed counts an echo of a bridge-originated write
+func (m *Metrics) IncEchoSuppressed(msgType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.EchoSuppressed[msgType]++
+}
+
+// IncQueueOverflow counts a write dropped by a full write queue ("to_fc" or "to_server")
+func (m *Metrics) IncQueueOverflow(direction string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.QueueOverflows[direction]++
+}
+
+// IncBatchDropped counts frames lost with a batch that could not be compressed or sent
+func (m *Metrics) IncBatchDropped(reason string, frames int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.BatchDropped[reason] += int64(frames)
+}
+
+// IncStaleCommand counts a command-class message that exceeded the maximum age
+func (m *Metrics) IncStaleCommand(msgType string, dropped bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if dropped {
+		m.StaleDropped[msgType]++
+	} else {
+		m.StaleFlagged[msgType]++
+	}
+}
+
+// RecordMigration records a completed uplink migration
+func (m *Metrics) RecordMigration(duration time.Duration, framesLost int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Migrations++
+	m.LastMigrationAt = time.Now()
+	m.LastMigrationTime = duration
+	m.MigrationFramesLost += framesLost
+}
+
+// SetReconnectState records a reconnect coordinator state transition
+func (m *Metrics) SetReconnectState(state, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state == m.ReconnectState {
+		return
+	}
+	if m.ReconnectState == "connected" {
+		m.Reconnects++
+	}
+	m.ReconnectState = state
+	m.ReconnectReason = reason
+	m.ReconnectSince = time.Now()
+}
+
+// SetUplinkState records whether the router confirmed the uplink ("blind", "registered", "rejected")
+func (m *Metrics) SetUplinkState(state string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state == m.UplinkState {
+		return
+	}
+	m.UplinkState = state
+	m.UplinkSince = time.Now()
+}
+
+// RecordUplinkAck counts a SESSION_HEARTBEAT_ACK from the router
+func (m *Metrics) RecordUplinkAck() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.UplinkAcks++
+	m.UplinkLastAck = time.Now()
+}
+
+// SetUpstreamServer records the router address the uplink starts on
+func (m *Metrics) SetUpstreamServer(addr string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.UpstreamServer = addr
+}
+
+// RecordFailover counts a switch to another upstream server
+func (m *Metrics) RecordFailover(addr string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.UpstreamServer = addr
+	m.Failovers++
+	m.LastFailover = time.Now()
+}
+
+// PacketCounts returns copies of the sent and failed counters per message type
+func (m *Metrics) PacketCounts() (sent, failed map[string]int64) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sent = make(map[string]int64, len(m.SentPackets))
+	for k, v := range m.SentPackets {
+		sent[k] = v
+	}
+	failed = make(map[string]int64, len(m.FailedPackets))
+	for k, v := range m.FailedPackets {
+		failed[k] = v
+	}
+	return sent, failed
+}
+
+// SessionExpiry returns when the current cloud session expires (zero before the first auth)
+func (m *Metrics) SessionExpiry() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.SessionExpiresAt
+}
+
+// SetEventStage updates the event stream state of a source
+func (m *Metrics) SetEventStage(source string, st EventStage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.EventStreams[source] = st
+}
+
+// EventStages returns a copy of the event stream state per source
+func (m *Metrics) EventStages() map[string]EventStage {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[string]EventStage, len(m.EventStreams))
+	for k, v := range m.EventStreams {
+		out[k] = v
+	}
+	return out
+}
+
 func (m *Metrics) GetSnapshot() map[string]interface{} {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -127,12 +372,39 @@ func (m *Metrics) GetSnapshot() map[string]interface{} {
 		"failed_packets":    m.FailedPackets,
 		"failed_unhealthy":  m.FailedUnhealthy,
 		"failed_send":       m.FailedSend,
+		"queue_overflows":   m.QueueOverflows,
+		"batch_dropped":     m.BatchDropped,
+		"stale_dropped":     m.StaleDropped,
+		"stale_flagged":     m.StaleFlagged,
+		"band_packets":      m.BandPackets,
+		"band_bytes":        m.BandBytes,
+		"unknown_received":  m.UnknownReceived,
+		"unknown_dropped":   m.UnknownDropped,
+		"echo_suppressed":   m.EchoSuppressed,
 		"current_ip":        m.CurrentIP,
 		"auth_status":       m.AuthStatus,
 		"last_auth":         m.LastAuth,
 		"uptime":            time.Since(m.StartTime).String(),
 		"session_expires":   m.SessionExpiresAt,
 		"refresh_interval":  m.RefreshInterval.Seconds(),
+		"migrations":        m.Migrations,
+		"last_migration":    m.LastMigrationAt,
+		"last_migration_ms": m.LastMigrationTime.Milliseconds(),
+		"migration_lost":    m.MigrationFramesLost,
+		"reconnect_state":   m.ReconnectState,
+		"reconnect_reason":  m.ReconnectReason,
+		"reconnect_since":   m.ReconnectSince,
+		"reconnects":        m.Reconnects,
+		"uplink_state":      m.UplinkState,
+		"uplink_since":      m.UplinkSince,
+		"uplink_last_ack":   m.UplinkLastAck,
+		"uplink_acks":       m.UplinkAcks,
+		"upstream_server":   m.UpstreamServer,
+		"failovers":         m.Failovers,
+		"last_failover":     m.LastFailover,
+		"event_streams":     m.EventStreams,
+		"restarts":          m.Restarts,
+		"counters_since":    m.CountersSince,
 		"logs":              m.RecentLogs,
 	}
 }
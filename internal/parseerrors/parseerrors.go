@@ -0,0 +1,219 @@
+package parseerrors
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3"
+)
+
+// Error kinds, from the gomavlib frame reader messages
+const (
+	KindMagic     = "invalid_magic" // Byte that does not start a frame: line noise or misframing
+	KindChecksum  = "checksum"      // CRC mismatch on a known message: noise, or CRC_EXTRA of a changed definition
+	KindDecode    = "decode"        // Known ID whose payload does not fit the definition: dialect mismatch
+	KindSignature = "signature"
+	KindOther     = "other" // Truncated frames and anything unrecognised
+)
+
+// Diagnoses derived from the error mix of a channel
+const (
+	DiagnosisNone     = ""
+	DiagnosisNoise    = "line_noise"       // Spread over random IDs or mostly framing errors
+	DiagnosisDialect  = "dialect_mismatch" // Concentrated on a few message IDs
+	diagnoseMinErrors = 10
+)
+
+// ringSize is the number of recent samples kept across all channels
+const ringSize = 64
+
+var (
+	magicRe    = regexp.MustCompile(`invalid magic byte: ([0-9a-f]+)`)
+	checksumRe = regexp.MustCompile(`wrong checksum, expected ([0-9a-f]+), got ([0-9a-f]+), message id is (\d+)`)
+)
+
+// Sample is one recent parse error. gomavlib discards the frame, so Bytes holds
+// the offending bytes the parser reports: the stray magic byte, or the expected
+// and received CRC (little-endian as on the wire).
+type Sample struct {
+	Time    time.Time `json:"time"`
+	Node    string    `json:"node"`
+	Channel string    `json:"channel"`
+	Kind    string    `json:"kind"`
+	MsgID   *uint32   `json:"msgId,omitempty"`
+	Bytes   string    `json:"bytes,omitempty"` // Hexdump
+	Error   string    `json:"error"`
+}
+
+// ChannelStats are the parse errors of one channel
+type ChannelStats struct {
+	Node      string           `json:"node"`
+	Channel   string           `json:"channel"`
+	Total     int64            `json:"total"`
+	ByKind    map[string]int64 `json:"byKind"`
+	ByMsgID   map[uint32]int64 `json:"byMsgId,omitempty"` // Checksum errors per message ID
+	First     time.Time        `json:"first"`
+	Last      time.Time        `json:"last"`
+	Diagnosis string           `json:"diagnosis,omitempty"`
+	TopMsgIDs []uint32         `json:"topMsgIds,omitempty"`
+}
+
+// Tracker counts parse errors per channel and keeps a ring of recent samples
+type Tracker struct {
+	mu       sync.Mutex
+	channels map[string]*ChannelStats
+	ring     [ringSize]Sample
+	next     int
+	count    int
+}
+
+// Global is the process-wide parse error tracker
+var Global = New()
+
+// New creates an empty tracker
+func New() *Tracker {
+	return &Tracker{channels: make(map[string]*ChannelStats)}
+}
+
+// Record counts an EventParseError of a node ("listener" or "sender")
+func (t *Tracker) Record(node string, ch *gomavlib.Channel, err error) {
+	channel := "unknown"
+	if ch != nil {
+		channel = ch.String()
+	}
+	s := classify(err)
+	s.Time = time.Now()
+	s.Node = node
+	s.Channel = channel
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := node + "|" + channel
+	cs, ok := t.channels[key]
+	if !ok {
+		cs = &ChannelStats{Node: node, Channel: channel, ByKind: make(map[string]int64), First: s.Time}
+		t.channels[key] = cs
+	}
+	cs.Total++
+	cs.ByKind[s.Kind]++
+	cs.Last = s.Time
+	if s.Kind == KindChecksum && s.MsgID != nil {
+		if cs.ByMsgID == nil {
+			cs.ByMsgID = make(map[uint32]int64)
+		}
+		cs.ByMsgID[*s.MsgID]++
+	}
+
+	t.ring[t.next] = s
+	t.next = (t.next + 1) % ringSize
+	if t.count < ringSize {
+		t.count++
+	}
+}
+
+// classify extracts the kind, message ID and reported bytes from a reader error
+func classify(err error) Sample {
+	msg := err.Error()
+	s := Sample{Kind: KindOther, Error: msg}
+	switch {
+	case strings.Contains(msg, "invalid magic byte"):
+		s.Kind = KindMagic
+		if m := magicRe.FindStringSubmatch(msg); m != nil {
+			if b, err := strconv.ParseUint(m[1], 16, 8); err == nil {
+				s.Bytes = fmt.Sprintf("%02x", b)
+			}
+		}
+	case strings.Contains(msg, "wrong checksum"):
+		s.Kind = KindChecksum
+		if m := checksumRe.FindStringSubmatch(msg); m != nil {
+			s.Bytes = crcBytes(m[1]) + " / " + crcBytes(m[2])
+			if id, err := strconv.ParseUint(m[3], 10, 32); err == nil {
+				id32 := uint32(id)
+				s.MsgID = &id32
+			}
+		}
+	case strings.Contains(msg, "unable to decode message"):
+		s.Kind = KindDecode
+	case strings.Contains(msg, "signature"):
+		s.Kind = KindSignature
+	}
+	return s
+}
+
+// crcBytes renders a 16-bit CRC as its two wire bytes ("a1b2" -> "b2 a1")
+func crcBytes(hex string) string {
+	v, err := strconv.ParseUint(hex, 16, 16)
+	if err != nil {
+		return hex
+	}
+	return fmt.Sprintf("%02x %02x", byte(v), byte(v>>8))
+}
+
+// diagnose guesses the cause of a channel's errors: checksum errors that hit a
+// handful of message IDs point to a definition mismatch, everything else to noise
+func diagnose(cs *ChannelStats) (string, []uint32) {
+	if cs.Total < diagnoseMinErrors {
+		return DiagnosisNone, nil
+	}
+	ids := make([]uint32, 0, len(cs.ByMsgID))
+	for id := range cs.ByMsgID {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return cs.ByMsgID[ids[i]] > cs.ByMsgID[ids[j]] })
+	if len(ids) > 3 {
+		ids = ids[:3]
+	}
+
+	var top int64
+	for _, id := range ids {
+		top += cs.ByMsgID[id]
+	}
+	if cs.ByKind[KindDecode] > 0 || (len(ids) > 0 && top*10 >= cs.Total*8) {
+		return DiagnosisDialect, ids
+	}
+	return DiagnosisNoise, ids
+}
+
+// Snapshot returns per-channel counts (most errors first) and the recent samples (newest first)
+func (t *Tracker) Snapshot() ([]ChannelStats, []Sample) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make([]ChannelStats, 0, len(t.channels))
+	for _, cs := range t.channels {
+		c := *cs
+		c.ByKind = make(map[string]int64, len(cs.ByKind))
+		for k, v := range cs.ByKind {
+			c.ByKind[k] = v
+		}
+		if cs.ByMsgID != nil {
+			c.ByMsgID = make(map[uint32]int64, len(cs.ByMsgID))
+			for k, v := range cs.ByMsgID {
+				c.ByMsgID[k] = v
+			}
+		}
+		c.Diagnosis, c.TopMsgIDs = diagnose(cs)
+		stats = append(stats, c)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Total > stats[j].Total })
+
+	samples := make([]Sample, 0, t.count)
+	for i := 1; i <= t.count; i++ {
+		samples = append(samples, t.ring[(t.next-i+ringSize)%ringSize])
+	}
+	return stats, samples
+}
+
+// Reset clears all counts and samples, e.g. after a firmware update
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.channels = make(map[string]*ChannelStats)
+	t.next, t.count = 0, 0
+}
@@ -0,0 +1,147 @@
+package payload
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Output drives one physical payload line
+type Output interface {
+	Set(on bool) error
+	Describe() string
+}
+
+// writeSysfs writes a value to a sysfs attribute
+func writeSysfs(path, value string) error {
+	if err := os.WriteFile(path, []byte(value), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// sysfsGPIO is a pin driven through /sys/class/gpio
+type sysfsGPIO struct {
+	dir       string
+	pin       int
+	activeLow bool
+}
+
+// NewSysfsGPIO exports a GPIO pin and configures it as an output in the off state.
+// On kernels 6.6+ the sysfs number is the chip base plus the line (e.g. 512+17 on a Pi).
+func NewSysfsGPIO(pin int, activeLow bool) (Output, error) {
+	g := &sysfsGPIO{dir: fmt.Sprintf("/sys/class/gpio/gpio%d", pin), pin: pin, activeLow: activeLow}
+	if _, err := os.Stat(g.dir); os.IsNotExist(err) {
+		if err := writeSysfs("/sys/class/gpio/export", strconv.Itoa(pin)); err != nil {
+			return nil, err
+		}
+		time.Sleep(100 * time.Millisecond) // udev applies permissions asynchronously
+	}
+	// "low"/"high" set the direction and the initial level in one step without a glitch
+	initial := "low"
+	if activeLow {
+		initial = "high"
+	}
+	if err := writeSysfs(filepath.Join(g.dir, "direction"), initial); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *sysfsGPIO) Set(on bool) error {
+	value := "0"
+	if on != g.activeLow {
+		value = "1"
+	}
+	return writeSysfs(filepath.Join(g.dir, "value"), value)
+}
+
+func (g *sysfsGPIO) Describe() string {
+	return fmt.Sprintf("sysfs gpio%d", g.pin)
+}
+
+// gpiochipGPIO is a line on a GPIO character device, driven with libgpiod's gpioset
+type gpiochipGPIO struct {
+	chip      string
+	line      int
+	activeLow bool
+}
+
+// NewGpiochipGPIO uses a line on /dev/<chip> and drives it to the off state
+func NewGpiochipGPIO(chip string, line int, activeLow bool) (Output, error) {
+	if _, err := exec.LookPath("gpioset"); err != nil {
+		return nil, fmt.Errorf("gpioset not found (install gpiod): %w", err)
+	}
+	g := &gpiochipGPIO{chip: chip, line: line, activeLow: activeLow}
+	if err := g.Set(false); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *gpiochipGPIO) Set(on bool) error {
+	value := 0
+	if on != g.activeLow {
+		value = 1
+	}
+	output, err := exec.Command("gpioset", g.chip, fmt.Sprintf("%d=%d", g.line, value)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("gpioset failed: %s - %v", string(output), err)
+	}
+	return nil
+}
+
+func (g *gpiochipGPIO) Describe() string {
+	return fmt.Sprintf("%s line %d", g.chip, g.line)
+}
+
+// sysfsPWM is a PWM channel (servo or dimmer) driven through /sys/class/pwm
+type sysfsPWM struct {
+	dir     string
+	onDuty  time.Duration
+	offDuty time.Duration
+}
+
+// NewSysfsPWM exports a PWM channel, sets its period and enables it at the off duty cycle
+func NewSysfsPWM(chip string, channel int, period, onDuty, offDuty time.Duration) (Output, error) {
+	if onDuty > period || offDuty > period {
+		return nil, fmt.Errorf("duty cycle longer than the %v period", period)
+	}
+	chipDir := filepath.Join("/sys/class/pwm", chip)
+	p := &sysfsPWM{dir: filepath.Join(chipDir, fmt.Sprintf("pwm%d", channel)), onDuty: onDuty, offDuty: offDuty}
+	if _, err := os.Stat(p.dir); os.IsNotExist(err) {
+		if err := writeSysfs(filepath.Join(chipDir, "export"), strconv.Itoa(channel)); err != nil {
+			return nil, err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	// The duty cycle must never exceed the period, so shrink it before changing the period
+	if err := writeSysfs(filepath.Join(p.dir, "duty_cycle"), "0"); err != nil {
+		return nil, err
+	}
+	if err := writeSysfs(filepath.Join(p.dir, "period"), strconv.FormatInt(period.Nanoseconds(), 10)); err != nil {
+		return nil, err
+	}
+	if err := p.Set(false); err != nil {
+		return nil, err
+	}
+	if err := writeSysfs(filepath.Join(p.dir, "enable"), "1"); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *sysfsPWM) Set(on bool) error {
+	duty := p.offDuty
+	if on {
+		duty = p.onDuty
+	}
+	return writeSysfs(filepath.Join(p.dir, "duty_cycle"), strconv.FormatInt(duty.Nanoseconds(), 10))
+}
+
+func (p *sysfsPWM) Describe() string {
+	return "sysfs " + filepath.Base(filepath.Dir(p.dir)) + "/" + filepath.Base(p.dir)
+}
@@ -0,0 +1,240 @@
+package payload
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/audit"
+	"DroneBridge/internal/logger"
+)
+
+// Commands accepted by Controller.Command
+const (
+	CommandOn      = "on"
+	CommandOff     = "off"
+	CommandTrigger = "trigger" // On for the action's pulse time, then off
+)
+
+// Interlock restricts when an action may be switched on. Switching off is always allowed.
+// An action without restrictions must say so with None.
+type Interlock struct {
+	None            bool    `json:"none,omitempty"`  // Declared unrestricted
+	RequireArmed    bool    `json:"requireArmed"`    // Vehicle must be armed (e.g. drop mechanism)
+	RequireDisarmed bool    `json:"requireDisarmed"` // Vehicle must be disarmed (e.g. servicing a release)
+	MinAltitude     float64 `json:"minAltitude"`     // Minimum altitude above home in meters (0 = no limit)
+	MaxAltitude     float64 `json:"maxAltitude"`     // Maximum altitude above home in meters (0 = no limit)
+}
+
+// VehicleState is the flight state interlocks are checked against
+type VehicleState struct {
+	Connected   bool
+	Armed       bool
+	HasPosition bool    // A fresh GLOBAL_POSITION_INT is available
+	AltRel      float64 // Meters above home
+}
+
+// declared reports whether the interlock restricts anything or is explicitly None
+func (il Interlock) declared() bool {
+	return il.None || il.RequireArmed || il.RequireDisarmed || il.MinAltitude > 0 || il.MaxAltitude > 0
+}
+
+// check returns why the interlock blocks switching on, or nil
+func (il Interlock) check(st VehicleState) error {
+	needsFC := il.RequireArmed || il.RequireDisarmed || il.MinAltitude > 0 || il.MaxAltitude > 0
+	if needsFC && !st.Connected {
+		return fmt.Errorf("not connected to the flight controller")
+	}
+	if il.RequireArmed && !st.Armed {
+		return fmt.Errorf("vehicle must be armed")
+	}
+	if il.RequireDisarmed && st.Armed {
+		return fmt.Errorf("vehicle must be disarmed")
+	}
+	if (il.MinAltitude > 0 || il.MaxAltitude > 0) && !st.HasPosition {
+		return fmt.Errorf("no current position for the altitude interlock")
+	}
+	if il.MinAltitude > 0 && st.AltRel < il.MinAltitude {
+		return fmt.Errorf("altitude %.1f m is below the %.1f m minimum", st.AltRel, il.MinAltitude)
+	}
+	if il.MaxAltitude > 0 && st.AltRel > il.MaxAltitude {
+		return fmt.Errorf("altitude %.1f m is above the %.1f m maximum", st.AltRel, il.MaxAltitude)
+	}
+	return nil
+}
+
+// Status is a snapshot of one payload action
+type Status struct {
+	Name       string     `json:"name"`
+	Output     string     `json:"output"`
+	On         bool       `json:"on"`
+	PulseMs    int64      `json:"pulseMs,omitempty"`
+	Interlock  Interlock  `json:"interlock"`
+	LastChange *time.Time `json:"lastChange,omitempty"`
+	LastError  string     `json:"lastError,omitempty"`
+}
+
+type action struct {
+	name      string
+	output    Output
+	pulse     time.Duration
+	interlock Interlock
+
+	mu         sync.Mutex
+	on         bool
+	lastChange time.Time
+	lastError  string
+	pulseTimer *time.Timer
+}
+
+// Controller holds the configured payload actions
+type Controller struct {
+	mu      sync.RWMutex
+	actions map[string]*action
+}
+
+// Global is the process-wide payload controller
+var Global = New()
+
+// New creates a controller without actions
+func New() *Controller {
+	return &Controller{actions: make(map[string]*action)}
+}
+
+// Add registers a named action. pulse is the on time of a trigger command.
+func (c *Controller) Add(name string, out Output, pulse time.Duration, il Interlock) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.actions[name]; exists {
+		return fmt.Errorf("payload action %q already defined", name)
+	}
+	if !il.declared() {
+		return fmt.Errorf("payload action %q has no interlock (set one, or none to allow switching at any time)", name)
+	}
+	c.actions[name] = &action{name: name, output: out, pulse: pulse, interlock: il}
+	logger.Info("[PAYLOAD] Action %s on %s", name, out.Describe())
+	return nil
+}
+
+// Command switches an action on, off or triggers a pulse after checking its
+// interlock. source identifies the requester in the audit log.
+func (c *Controller) Command(name, command string, st VehicleState, source string) error {
+	c.mu.RLock()
+	a, ok := c.actions[name]
+	c.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown payload action %q", name)
+	}
+
+	err := a.command(command, st)
+	fields := map[string]interface{}{
+		"action":  name,
+		"command": command,
+		"source":  source,
+		"armed":   st.Armed,
+		"altRel":  st.AltRel,
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		logger.Warn("[PAYLOAD] %s %s refused: %v", name, command, err)
+	} else {
+		logger.Info("[PAYLOAD] %s %s (by %s)", name, command, source)
+	}
+	audit.Global.Record("payload", command, fields)
+	return err
+}
+
+func (a *action) command(command string, st VehicleState) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch command {
+	case CommandOff:
+		return a.setLocked(false)
+	case CommandOn, CommandTrigger:
+		if err := a.interlock.check(st); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("command must be %s, %s or %s", CommandOn, CommandOff, CommandTrigger)
+	}
+
+	if command == CommandTrigger && a.pulse <= 0 {
+		return fmt.Errorf("action %s has no pulse time configured", a.name)
+	}
+	if err := a.setLocked(true); err != nil {
+		return err
+	}
+	if command == CommandTrigger {
+		var timer *time.Timer
+		timer = time.AfterFunc(a.pulse, func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			if a.pulseTimer != timer {
+				return // Superseded by a later command
+			}
+			if err := a.setLocked(false); err != nil {
+				logger.Error("[PAYLOAD] %s failed to end pulse: %v", a.name, err)
+			}
+		})
+		a.pulseTimer = timer
+	}
+	return nil
+}
+
+// setLocked drives the output (caller holds a.mu). Any pending pulse end is cancelled.
+func (a *action) setLocked(on bool) error {
+	if a.pulseTimer != nil {
+		a.pulseTimer.Stop()
+		a.pulseTimer = nil
+	}
+	if err := a.output.Set(on); err != nil {
+		a.lastError = err.Error()
+		return err
+	}
+	a.on = on
+	a.lastChange = time.Now()
+	a.lastError = ""
+	return nil
+}
+
+// AllOff switches every action off, e.g. on shutdown
+func (c *Controller) AllOff() {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, a := range c.actions {
+		a.mu.Lock()
+		if err := a.setLocked(false); err != nil {
+			logger.Warn("[PAYLOAD] %s failed to switch off: %v", a.name, err)
+		}
+		a.mu.Unlock()
+	}
+}
+
+// Snapshot returns all actions sorted by name
+func (c *Controller) Snapshot() []Status {
+	c.mu.RLock()
+	out := make([]Status, 0, len(c.actions))
+	for _, a := range c.actions {
+		a.mu.Lock()
+		st := Status{
+			Name:      a.name,
+			Output:    a.output.Describe(),
+			On:        a.on,
+			PulseMs:   a.pulse.Milliseconds(),
+			Interlock: a.interlock,
+			LastError: a.lastError,
+		}
+		if !a.lastChange.IsZero() {
+			t := a.lastChange
+			st.LastChange = &t
+		}
+		a.mu.Unlock()
+		out = append(out, st)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
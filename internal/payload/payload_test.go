@@ -0,0 +1,121 @@
+package payload
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeOutput records the last level it was driven to
+type fakeOutput struct {
+	on  bool
+	err error
+}
+
+func (o *fakeOutput) Set(on bool) error {
+	if o.err != nil {
+		return o.err
+	}
+	o.on = on
+	return nil
+}
+
+func (o *fakeOutput) Describe() string { return "fake" }
+
+func TestInterlockCheck(t *testing.T) {
+	flying := VehicleState{Connected: true, Armed: true, HasPosition: true, AltRel: 20}
+	tests := []struct {
+		name    string
+		il      Interlock
+		st      VehicleState
+		wantErr string
+	}{
+		{"none, no FC", Interlock{None: true}, VehicleState{}, ""},
+		{"armed, no FC", Interlock{RequireArmed: true}, VehicleState{}, "not connected"},
+		{"armed, disarmed", Interlock{RequireArmed: true}, VehicleState{Connected: true}, "must be armed"},
+		{"armed, armed", Interlock{RequireArmed: true}, flying, ""},
+		{"disarmed, armed", Interlock{RequireDisarmed: true}, flying, "must be disarmed"},
+		{"disarmed, on the ground", Interlock{RequireDisarmed: true}, VehicleState{Connected: true}, ""},
+		{"altitude, no position", Interlock{MinAltitude: 5}, VehicleState{Connected: true, Armed: true}, "no current position"},
+		{"below minimum", Interlock{MinAltitude: 30}, flying, "below"},
+		{"above maximum", Interlock{MaxAltitude: 10}, flying, "above"},
+		{"inside the band", Interlock{RequireArmed: true, MinAltitude: 5, MaxAltitude: 50}, flying, ""},
+		{"at the minimum", Interlock{MinAltitude: 20}, flying, ""},
+	}
+	for _, tt := range tests {
+		err := tt.il.check(tt.st)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: check() = %v, want nil", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: check() = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestAddRequiresInterlock(t *testing.T) {
+	c := New()
+	tests := []struct {
+		name    string
+		il      Interlock
+		wantErr bool
+	}{
+		{"undeclared", Interlock{}, true},
+		{"explicitly none", Interlock{None: true}, false},
+		{"require armed", Interlock{RequireArmed: true}, false},
+		{"altitude only", Interlock{MaxAltitude: 3}, false},
+	}
+	for _, tt := range tests {
+		err := c.Add(strings.ReplaceAll(tt.name, " ", "_"), &fakeOutput{}, 0, tt.il)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Add() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if len(c.Snapshot()) != 3 {
+		t.Errorf("Snapshot() has %d actions, want 3", len(c.Snapshot()))
+	}
+}
+
+func TestCommand(t *testing.T) {
+	out := &fakeOutput{}
+	c := New()
+	if err := c.Add("drop", out, 20*time.Millisecond, Interlock{RequireArmed: true, MinAltitude: 5}); err != nil {
+		t.Fatal(err)
+	}
+	ground := VehicleState{Connected: true, HasPosition: true}
+	flying := VehicleState{Connected: true, Armed: true, HasPosition: true, AltRel: 10}
+
+	if err := c.Command("drop", CommandOn, ground, "test"); err == nil || out.on {
+		t.Fatalf("on while disarmed: err %v, output on %v", err, out.on)
+	}
+	if err := c.Command("drop", CommandOn, flying, "test"); err != nil || !out.on {
+		t.Fatalf("on in flight: err %v, output on %v", err, out.on)
+	}
+	// Switching off ignores the interlock
+	if err := c.Command("drop", CommandOff, ground, "test"); err != nil || out.on {
+		t.Fatalf("off on the ground: err %v, output on %v", err, out.on)
+	}
+	if err := c.Command("drop", "open", flying, "test"); err == nil {
+		t.Error("unknown command accepted")
+	}
+	if err := c.Command("release", CommandOn, flying, "test"); err == nil {
+		t.Error("unknown action accepted")
+	}
+
+	if err := c.Command("drop", CommandTrigger, flying, "test"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		c.actions["drop"].mu.Lock()
+		on := out.on
+		c.actions["drop"].mu.Unlock()
+		if !on {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("trigger pulse did not end")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"sort"
+	"time"
+)
+
+// ProfileConfig is one consumer's rate profile as configured
+type ProfileConfig struct {
+	DefaultHz float64       `json:"defaultHz"`
+	Rates     []MessageRate `json:"rates"`
+}
+
+// ShaperConfig is the router-negotiated uplink limits in effect
+type ShaperConfig struct {
+	Source         string        `json:"source"`
+	MaxBytesPerSec float64       `json:"maxBytesPerSec"`
+	Rates          []MessageRate `json:"rates"`
+}
+
+// LocalParamsConfig is the local parameter exchange setting
+type LocalParamsConfig struct {
+	Action     string  `json:"action"`
+	RatePerSec float64 `json:"ratePerSec,omitempty"` // For LocalParamsLimit
+}
+
+// Config is the forwarding configuration in effect, without counters, so two
+// drones forwarding the same way export the same Config
+type Config struct {
+	Policy      Policy                   `json:"policy"`   // Active tier with its allow-list
+	Filters     []FilterRule             `json:"filters"`  // forwarding.filters in order
+	Profiles    map[string]ProfileConfig `json:"profiles"` // Consumer rate profiles
+	Shaper      ShaperConfig             `json:"shaper"`
+	LocalParams LocalParamsConfig        `json:"localParams"`
+}
+
+// Current returns the configuration of the process-wide policy, filter table,
+// rate profiles, shaper and local parameter filter
+func Current() Config {
+	return Config{
+		Policy:      Global.activeConfig(),
+		Filters:     Filters.config(),
+		Profiles:    Profiles.config(),
+		Shaper:      Shaper.config(),
+		LocalParams: LocalParams.config(),
+	}
+}
+
+// activeConfig returns a copy of the active policy
+func (m *Manager) activeConfig() Policy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return Policy{Name: m.active.Name, AllowAll: m.active.AllowAll, Allow: append([]uint32(nil), m.active.Allow...)}
+}
+
+// config returns the configured rules without their counters
+func (t *FilterTable) config() []FilterRule {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]FilterRule, len(t.rules))
+	for i, r := range t.rules {
+		out[i] = r.FilterRule
+		out[i].Messages = append([]uint32(nil), r.Messages...)
+	}
+	return out
+}
+
+// config returns each consumer's profile
+func (s *ProfileSet) config() map[string]ProfileConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]ProfileConfig, len(s.consumers))
+	for name, d := range s.consumers {
+		out[name] = ProfileConfig{DefaultHz: d.profile.DefaultHz, Rates: sortedRates(d.profile.Rates)}
+	}
+	return out
+}
+
+// config returns the limits without the token bucket state
+func (s *RateShaper) config() ShaperConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return ShaperConfig{Source: s.source, MaxBytesPerSec: s.maxBytesPerSec, Rates: sortedRates(s.rates)}
+}
+
+// config returns the action and forwarded response rate
+func (l *LocalParamFilter) config() LocalParamsConfig {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	c := LocalParamsConfig{Action: l.action}
+	if l.action == LocalParamsLimit && l.interval > 0 {
+		c.RatePerSec = float64(time.Second) / float64(l.interval)
+	}
+	return c
+}
+
+// sortedRates lists per-message caps by message ID
+func sortedRates(rates map[uint32]float64) []MessageRate {
+	out := make([]MessageRate, 0, len(rates))
+	for id, hz := range rates {
+		out = append(out, MessageRate{MsgID: id, MaxHz: hz})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].MsgID < out[j].MsgID })
+	return out
+}
@@ -0,0 +1,158 @@
+package policy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Filter rule actions (forwarding.filters[].action)
+const (
+	FilterAllow = "allow" // Forward, at most MaxHz when set
+	FilterDeny  = "deny"  // Keep off the uplink
+)
+
+// FilterVerdict is the outcome of the filter table for one frame
+type FilterVerdict int
+
+const (
+	FilterPass        FilterVerdict = iota // No rule matched, or an allow rule let it through
+	FilterDenied                           // A deny rule matched
+	FilterRateLimited                      // An allow rule matched but its max Hz was reached
+)
+
+// FilterRule matches frames by source system and message ID. Empty fields match anything.
+type FilterRule struct {
+	SystemID uint8    `json:"sysid,omitempty"`    // Source system ID (0 = any)
+	Messages []uint32 `json:"messages,omitempty"` // Message IDs (empty = any)
+	Action   string   `json:"action"`             // FilterAllow or FilterDeny
+	MaxHz    float64  `json:"maxHz,omitempty"`    // Rate cap for allow rules (0 = unlimited)
+}
+
+func (r *FilterRule) matches(sysID uint8, msgID uint32) bool {
+	if r.SystemID != 0 && r.SystemID != sysID {
+		return false
+	}
+	if len(r.Messages) == 0 {
+		return true
+	}
+	for _, id := range r.Messages {
+		if id == msgID {
+			return true
+		}
+	}
+	return false
+}
+
+// filterKey separates sources under one rule, so a rule for several messages
+// or vehicles caps each of them on its own
+type filterKey struct {
+	rule   int
+	sysID  uint8
+	compID uint8
+	msgID  uint32
+}
+
+// FilterStats counts what one rule did
+type FilterStats struct {
+	FilterRule
+	Matched     uint64 `json:"matched"`
+	Denied      uint64 `json:"denied"`
+	RateLimited uint64 `json:"rateLimited"`
+}
+
+// FilterTable applies forwarding.filters to the uplink. Rules are checked in
+// order and the first match decides; frames no rule matches pass.
+type FilterTable struct {
+	mu       sync.Mutex
+	rules    []FilterStats
+	lastSent map[filterKey]time.Time
+}
+
+// Filters is the process-wide uplink filter table
+var Filters = &FilterTable{lastSent: make(map[filterKey]time.Time)}
+
+// ValidateFilterRules checks rules as Configure does, without installing them
+func ValidateFilterRules(rules []FilterRule) error {
+	_, err := filterStats(rules)
+	return err
+}
+
+// Configure replaces the rules
+func (t *FilterTable) Configure(rules []FilterRule) error {
+	stats, err := filterStats(rules)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rules = stats
+	t.lastSent = make(map[filterKey]time.Time)
+	return nil
+}
+
+// filterStats validates rules and returns them with zeroed counters
+func filterStats(rules []FilterRule) ([]FilterStats, error) {
+	stats := make([]FilterStats, 0, len(rules))
+	for i, r := range rules {
+		if r.Action == "" {
+			r.Action = FilterAllow
+		}
+		switch r.Action {
+		case FilterAllow:
+		case FilterDeny:
+			if r.MaxHz != 0 {
+				return nil, fmt.Errorf("rule %d: max_hz only applies to allow rules", i)
+			}
+		default:
+			return nil, fmt.Errorf("rule %d: unknown action %q (use %q or %q)", i, r.Action, FilterAllow, FilterDeny)
+		}
+		if r.MaxHz < 0 {
+			return nil, fmt.Errorf("rule %d: max_hz must not be negative", i)
+		}
+		stats = append(stats, FilterStats{FilterRule: r})
+	}
+	return stats, nil
+}
+
+// Check runs a frame from sysID/compID through the rules
+func (t *FilterTable) Check(sysID, compID uint8, msgID uint32) FilterVerdict {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.rules {
+		r := &t.rules[i]
+		if !r.matches(sysID, msgID) {
+			continue
+		}
+		r.Matched++
+		if r.Action == FilterDeny {
+			r.Denied++
+			return FilterDenied
+		}
+		if r.MaxHz <= 0 {
+			return FilterPass
+		}
+
+		now := time.Now()
+		key := filterKey{rule: i, sysID: sysID, compID: compID, msgID: msgID}
+		minInterval := time.Duration(float64(time.Second) / r.MaxHz)
+		if last, seen := t.lastSent[key]; seen && now.Sub(last) < minInterval {
+			r.RateLimited++
+			return FilterRateLimited
+		}
+		t.lastSent[key] = now
+		return FilterPass
+	}
+	return FilterPass
+}
+
+// Snapshot returns the rules in order with their counters
+func (t *FilterTable) Snapshot() []FilterStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]FilterStats, len(t.rules))
+	copy(out, t.rules)
+	return out
+}
@@ -0,0 +1,144 @@
+package policy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MAVLink parameter protocol message IDs
+const (
+	msgParamRequestRead = 20
+	msgParamRequestList = 21
+	msgParamValue       = 22
+	msgParamSet         = 23
+)
+
+// Local parameter exchange actions (forwarding.local_params.action)
+const (
+	LocalParamsForward = "forward" // Responses go to the cloud like any telemetry
+	LocalParamsDrop    = "drop"    // Responses are kept off the uplink
+	LocalParamsLimit   = "limit"   // Responses reach the cloud at a capped rate
+)
+
+// paramExchangeIdle ends an exchange when no PARAM_VALUE arrived for this long
+const paramExchangeIdle = 3 * time.Second
+
+// paramExchange is an open request/response exchange with the FC
+type paramExchange struct {
+	until time.Time
+}
+
+func (e *paramExchange) open(now time.Time) { e.until = now.Add(paramExchangeIdle) }
+
+// active reports whether the exchange is open, extending it on each response
+func (e *paramExchange) active(now time.Time, response bool) bool {
+	if !now.Before(e.until) {
+		return false
+	}
+	if response {
+		e.open(now)
+	}
+	return true
+}
+
+// LocalParamFilter keeps PARAM_VALUE replies to dashboard requests (a full
+// PARAM_REQUEST_LIST is ~1000 messages) off the uplink. While the cloud has its
+// own exchange open, responses are forwarded so its GCS gets its answers.
+type LocalParamFilter struct {
+	mu       sync.Mutex
+	action   string
+	interval time.Duration // Minimum spacing of forwarded responses when limiting
+	lastSent time.Time
+
+	local, cloud paramExchange
+
+	suppressed int64 // Responses dropped from the uplink
+	limited    int64 // Responses dropped by the rate cap
+}
+
+// LocalParams is the process-wide local parameter filter
+var LocalParams = &LocalParamFilter{action: LocalParamsForward}
+
+// Configure sets the action and, for LocalParamsLimit, the forwarded PARAM_VALUE rate
+func (l *LocalParamFilter) Configure(action string, ratePerSec int) error {
+	switch action {
+	case LocalParamsForward, LocalParamsDrop, LocalParamsLimit:
+	default:
+		return fmt.Errorf("unknown local parameter action %q", action)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.action = action
+	l.interval = 0
+	if ratePerSec > 0 {
+		l.interval = time.Second / time.Duration(ratePerSec)
+	}
+	return nil
+}
+
+// LocalRequest records a parameter request sent to the FC by the bridge itself
+func (l *LocalParamFilter) LocalRequest(msgID uint32) {
+	if !isParamRequest(msgID) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.local.open(time.Now())
+}
+
+// CloudRequest records a parameter request relayed from the server to the FC
+func (l *LocalParamFilter) CloudRequest(msgID uint32) {
+	if !isParamRequest(msgID) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cloud.open(time.Now())
+}
+
+// AllowUplink reports whether a message from the FC may be forwarded to the server
+func (l *LocalParamFilter) AllowUplink(msgID uint32) bool {
+	if msgID != msgParamValue {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	cloud := l.cloud.active(now, true)
+	local := l.local.active(now, true)
+	if cloud || !local {
+		return true
+	}
+	switch l.action {
+	case LocalParamsDrop:
+		l.suppressed++
+		return false
+	case LocalParamsLimit:
+		if now.Sub(l.lastSent) < l.interval {
+			l.limited++
+			return false
+		}
+		l.lastSent = now
+	}
+	return true
+}
+
+// Snapshot returns the filter state for the web API
+func (l *LocalParamFilter) Snapshot() map[string]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	return map[string]interface{}{
+		"action":      l.action,
+		"localActive": now.Before(l.local.until),
+		"cloudActive": now.Before(l.cloud.until),
+		"suppressed":  l.suppressed,
+		"limited":     l.limited,
+	}
+}
+
+func isParamRequest(msgID uint32) bool {
+	return msgID == msgParamRequestRead || msgID == msgParamRequestList || msgID == msgParamSet
+}
@@ -0,0 +1,188 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Policy defines which message IDs may be forwarded to the server
+type Policy struct {
+	Name     string   `json:"name"`
+	AllowAll bool     `json:"allowAll"`
+	Allow    []uint32 `json:"allow,omitempty"`
+
+	allowSet map[uint32]struct{}
+}
+
+// Allowed reports whether a message ID passes this policy
+func (p *Policy) Allowed(msgID uint32) bool {
+	if p.AllowAll {
+		return true
+	}
+	_, ok := p.allowSet[msgID]
+	return ok
+}
+
+// Manager holds the named forwarding policies and the currently active one
+type Manager struct {
+	mu       sync.RWMutex
+	policies map[string]*Policy
+	active   *Policy
+}
+
+// Built-in tiers used when the config does not define them
+var (
+	minimalIDs = []uint32{
+		0,   // HEARTBEAT
+		1,   // SYS_STATUS
+		24,  // GPS_RAW_INT
+		33,  // GLOBAL_POSITION_INT
+		77,  // COMMAND_ACK
+		147, // BATTERY_STATUS
+		253, // STATUSTEXT
+	}
+	standardIDs = append(append([]uint32{}, minimalIDs...),
+		22,  // PARAM_VALUE
+		30,  // ATTITUDE
+		42,  // MISSION_CURRENT
+		44,  // MISSION_COUNT
+		47,  // MISSION_ACK
+		62,  // NAV_CONTROLLER_OUTPUT
+		73,  // MISSION_ITEM_INT
+		74,  // VFR_HUD
+		111, // TIMESYNC
+		193, // EKF_STATUS_REPORT
+		241, // VIBRATION
+		242, // HOME_POSITION
+		245, // EXTENDED_SYS_STATE
+		246, // ADSB_VEHICLE
+	)
+)
+
+// Global is the process-wide policy manager (defaults to the "full" tier)
+var Global = New(nil, "full")
+
+// New creates a policy manager from named allow-lists merged over the built-in tiers.
+// A configured list is taken literally (an empty one forwards nothing); only the
+// built-in "full" tier allows everything.
+func New(configured map[string][]uint32, active string) *Manager {
+	m := &Manager{policies: make(map[string]*Policy)}
+	m.add("minimal", minimalIDs, false)
+	m.add("standard", standardIDs, false)
+	m.add("full", nil, true)
+
+	for name, ids := range configured {
+		if name == "full" {
+			continue
+		}
+		m.add(name, ids, false)
+	}
+
+	if p, ok := m.policies[active]; ok {
+		m.active = p
+	} else {
+		m.active = m.policies["full"]
+	}
+	return m
+}
+
+func (m *Manager) add(name string, ids []uint32, allowAll bool) {
+	p := &Policy{Name: name, AllowAll: allowAll, allowSet: make(map[uint32]struct{}, len(ids))}
+	for _, id := range ids {
+		if _, dup := p.allowSet[id]; dup {
+			continue
+		}
+		p.allowSet[id] = struct{}{}
+		p.Allow = append(p.Allow, id)
+	}
+	sort.Slice(p.Allow, func(i, j int) bool { return p.Allow[i] < p.Allow[j] })
+	m.policies[name] = p
+}
+
+// Allowed reports whether a message ID passes the active policy
+func (m *Manager) Allowed(msgID uint32) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.active.Allowed(msgID)
+}
+
+// Active returns the active policy name
+func (m *Manager) Active() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.active.Name
+}
+
+// Has reports whether a policy of that name is known
+func (m *Manager) Has(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.policies[name]
+	return ok
+}
+
+// SetActive switches the active policy at runtime
+func (m *Manager) SetActive(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.policies[name]
+	if !ok {
+		return fmt.Errorf("unknown forwarding policy %q", name)
+	}
+	m.active = p
+	return nil
+}
+
+// covers reports whether p forwards everything q forwards
+func (p *Policy) covers(q *Policy) bool {
+	if p.AllowAll {
+		return true
+	}
+	if q.AllowAll {
+		return false
+	}
+	for _, id := range q.Allow {
+		if !p.Allowed(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckNarrower returns an error unless next forwards nothing prev did not: its
+// active policy and every policy it shares a name with allow no more than before,
+// and new policies no more than prev's active one. The router may only tighten
+// what reaches it.
+func CheckNarrower(prev, next *Manager) error {
+	prev.mu.RLock()
+	defer prev.mu.RUnlock()
+	next.mu.RLock()
+	defer next.mu.RUnlock()
+
+	if !prev.active.covers(next.active) {
+		return fmt.Errorf("forwarding policy %q allows messages %q did not", next.active.Name, prev.active.Name)
+	}
+	for name, p := range next.policies {
+		before, ok := prev.policies[name]
+		if !ok {
+			before = prev.active
+		}
+		if !before.covers(p) {
+			return fmt.Errorf("forwarding policy %q allows messages %q did not", name, before.Name)
+		}
+	}
+	return nil
+}
+
+// List returns all known policies sorted by name
+func (m *Manager) List() []Policy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]Policy, 0, len(m.policies))
+	for _, p := range m.policies {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,27 @@
+package policy
+
+import "testing"
+
+func TestConfiguredPolicies(t *testing.T) {
+	m := New(map[string][]uint32{"blackout": nil, "heartbeat": {0}, "full": {0}}, "blackout")
+	tests := []struct {
+		policy string
+		msgID  uint32
+		want   bool
+	}{
+		{"blackout", 0, false},
+		{"blackout", 33, false},
+		{"heartbeat", 0, true},
+		{"heartbeat", 33, false},
+		{"full", 33, true}, // The built-in tier cannot be narrowed by name
+		{"full", 50123, true},
+	}
+	for _, tt := range tests {
+		if err := m.SetActive(tt.policy); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.Allowed(tt.msgID); got != tt.want {
+			t.Errorf("%s: Allowed(%d) = %v, want %v", tt.policy, tt.msgID, got, tt.want)
+		}
+	}
+}
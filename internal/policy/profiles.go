@@ -0,0 +1,142 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Consumers of FC telemetry that can have their own rate profile (forwarding.profiles)
+const (
+	ConsumerCloud     = "cloud"     // Uplink to the router
+	ConsumerWebSocket = "websocket" // Browser GCS clients on /ws/mavlink
+)
+
+// Consumers lists the valid profile names
+var Consumers = []string{ConsumerCloud, ConsumerWebSocket}
+
+// RateProfile caps message rates for one consumer
+type RateProfile struct {
+	DefaultHz float64            // Cap for messages not in Rates (0 = unlimited)
+	Rates     map[uint32]float64 // Message ID -> max Hz (0 = unlimited)
+}
+
+// isExchangeMessage reports whether a message belongs to commands or a
+// request/response protocol, where a skipped frame breaks the exchange;
+// default_hz never applies to them
+func isExchangeMessage(msgID uint32) bool {
+	switch msgID {
+	case 0, // HEARTBEAT
+		22,                                 // PARAM_VALUE
+		39, 40, 42, 44, 45, 46, 47, 51, 73, // Mission protocol
+		75, 76, 77, 80, // COMMAND_INT/LONG/ACK/CANCEL
+		110, // FILE_TRANSFER_PROTOCOL
+		253: // STATUSTEXT
+		return true
+	}
+	return false
+}
+
+// rateFor returns the cap of a message (0 = unlimited)
+func (p RateProfile) rateFor(msgID uint32) float64 {
+	if hz, ok := p.Rates[msgID]; ok {
+		return hz
+	}
+	if isExchangeMessage(msgID) {
+		return 0
+	}
+	return p.DefaultHz
+}
+
+// downsamplerKey separates components, so e.g. two GPS units are capped independently
+type downsamplerKey struct {
+	msgID  uint32
+	compID uint8
+}
+
+// downsampler applies a profile to the frames of one consumer
+type downsampler struct {
+	profile  RateProfile
+	lastSent map[downsamplerKey]time.Time
+	dropped  uint64
+}
+
+// ProfileSet holds a downsampler per consumer. Each consumer sees the FC
+// stream at its own rates, unlike Shaper which only limits the uplink.
+type ProfileSet struct {
+	mu        sync.Mutex
+	consumers map[string]*downsampler
+}
+
+// Profiles is the process-wide set of consumer rate profiles
+var Profiles = &ProfileSet{consumers: make(map[string]*downsampler)}
+
+// Configure replaces the profiles. Consumers without one get every frame.
+func (s *ProfileSet) Configure(profiles map[string]RateProfile) error {
+	consumers := make(map[string]*downsampler, len(profiles))
+	for name, p := range profiles {
+		if !validConsumer(name) {
+			return fmt.Errorf("unknown telemetry consumer %q (valid: %v)", name, Consumers)
+		}
+		consumers[name] = &downsampler{profile: p, lastSent: make(map[downsamplerKey]time.Time)}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.consumers = consumers
+	return nil
+}
+
+// Allow reports whether a frame may be delivered to consumer now
+func (s *ProfileSet) Allow(consumer string, msgID uint32, compID uint8) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.consumers[consumer]
+	if !ok {
+		return true
+	}
+	maxHz := d.profile.rateFor(msgID)
+	if maxHz <= 0 {
+		return true
+	}
+
+	now := time.Now()
+	key := downsamplerKey{msgID: msgID, compID: compID}
+	minInterval := time.Duration(float64(time.Second) / maxHz)
+	if last, seen := d.lastSent[key]; seen && now.Sub(last) < minInterval {
+		d.dropped++
+		return false
+	}
+	d.lastSent[key] = now
+	return true
+}
+
+// Snapshot returns each consumer's profile and how many frames it skipped
+func (s *ProfileSet) Snapshot() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]interface{}, len(s.consumers))
+	for name, d := range s.consumers {
+		rates := make([]MessageRate, 0, len(d.profile.Rates))
+		for id, hz := range d.profile.Rates {
+			rates = append(rates, MessageRate{MsgID: id, MaxHz: hz})
+		}
+		sort.Slice(rates, func(i, j int) bool { return rates[i].MsgID < rates[j].MsgID })
+		out[name] = map[string]interface{}{
+			"defaultHz":   d.profile.DefaultHz,
+			"rates":       rates,
+			"downsampled": d.dropped,
+		}
+	}
+	return out
+}
+
+func validConsumer(name string) bool {
+	for _, c := range Consumers {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package policy
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// MessageRate is a per-message rate cap
+type MessageRate struct {
+	MsgID uint32  `json:"msgId"`
+	MaxHz float64 `json:"maxHz"`
+}
+
+// RateShaper enforces per-message rate caps and an overall bandwidth budget on the uplink
+type RateShaper struct {
+	mu sync.Mutex
+
+	maxBytesPerSec float64
+	tokens         float64
+	lastRefill     time.Time
+
+	rates    map[uint32]float64   // msgID -> max Hz
+	lastSent map[uint32]time.Time // msgID -> last time a frame was let through
+
+	source    string // "none" or "router"
+	appliedAt time.Time
+}
+
+// Shaper is the process-wide uplink rate shaper
+var Shaper = NewRateShaper()
+
+// NewRateShaper creates a shaper without limits
+func NewRateShaper() *RateShaper {
+	return &RateShaper{
+		rates:    make(map[uint32]float64),
+		lastSent: make(map[uint32]time.Time),
+		source:   "none",
+	}
+}
+
+// Apply replaces the active limits. maxBytesPerSec of 0 disables the bandwidth budget.
+func (s *RateShaper) Apply(maxBytesPerSec uint32, rates []MessageRate, source string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maxBytesPerSec = float64(maxBytesPerSec)
+	s.tokens = s.maxBytesPerSec
+	s.lastRefill = time.Now()
+	s.rates = make(map[uint32]float64, len(rates))
+	for _, r := range rates {
+		if r.MaxHz > 0 {
+			s.rates[r.MsgID] = r.MaxHz
+		}
+	}
+	s.source = source
+	s.appliedAt = time.Now()
+}
+
+// Allow reports whether a frame may be sent now. size is only evaluated
+// when a bandwidth budget is active.
+func (s *RateShaper) Allow(msgID uint32, size func() int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	if maxHz, ok := s.rates[msgID]; ok {
+		minInterval := time.Duration(float64(time.Second) / maxHz)
+		if last, seen := s.lastSent[msgID]; seen && now.Sub(last) < minInterval {
+			return false
+		}
+	}
+
+	if s.maxBytesPerSec > 0 {
+		// Refill token bucket (burst capacity = one second of budget)
+		s.tokens += now.Sub(s.lastRefill).Seconds() * s.maxBytesPerSec
+		if s.tokens > s.maxBytesPerSec {
+			s.tokens = s.maxBytesPerSec
+		}
+		s.lastRefill = now
+
+		n := float64(size())
+		if s.tokens < n {
+			return false
+		}
+		s.tokens -= n
+	}
+
+	if _, ok := s.rates[msgID]; ok {
+		s.lastSent[msgID] = now
+	}
+	return true
+}
+
+// Snapshot returns the active limits for the web API
+func (s *RateShaper) Snapshot() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rates := make([]MessageRate, 0, len(s.rates))
+	for id, hz := range s.rates {
+		rates = append(rates, MessageRate{MsgID: id, MaxHz: hz})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].MsgID < rates[j].MsgID })
+
+	var appliedAt interface{}
+	if !s.appliedAt.IsZero() {
+		appliedAt = s.appliedAt
+	}
+
+	return map[string]interface{}{
+		"source":         s.source,
+		"maxBytesPerSec": s.maxBytesPerSec,
+		"rates":          rates,
+		"appliedAt":      appliedAt,
+	}
+}
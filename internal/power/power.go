@@ -0,0 +1,150 @@
+// Package power watches the companion computer's supply voltage (UPS HAT or
+// ADC) and requests a clean shutdown on undervoltage, so the SD card and flight
+// records survive the supply collapsing.
+package power
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/sensors"
+)
+
+// Config is the supply voltage input and shutdown policy
+type Config struct {
+	Source     sensors.Source
+	Scale      float64 // volts = raw*Scale + Offset
+	Offset     float64
+	Interval   time.Duration
+	MinVoltage float64 // Undervoltage threshold (V)
+	Samples    int     // Consecutive low readings before shutting down
+	WhenArmed  bool    // Shut down even while the vehicle is armed
+}
+
+// Monitor polls the supply voltage and closes Undervoltage() once a shutdown is due
+type Monitor struct {
+	mu      sync.Mutex
+	cfg     Config
+	lastErr string
+	low     int // Consecutive readings below MinVoltage
+	armed   bool
+	held    bool // Undervoltage while armed, waiting for disarm
+
+	undervoltage chan struct{}
+	once         sync.Once
+}
+
+// Global is the process-wide supply monitor
+var Global = &Monitor{undervoltage: make(chan struct{})}
+
+// Configure sets the voltage input and thresholds
+func (m *Monitor) Configure(cfg Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = cfg
+}
+
+// ObserveArmed follows the armed state from heartbeats
+func (m *Monitor) ObserveArmed(armed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.armed = armed
+}
+
+// Undervoltage is closed when the supply stayed below the threshold and the bridge should shut down
+func (m *Monitor) Undervoltage() <-chan struct{} {
+	return m.undervoltage
+}
+
+// Run polls the supply until stop is closed
+func (m *Monitor) Run(stop <-chan struct{}) {
+	m.mu.Lock()
+	cfg := m.cfg
+	m.mu.Unlock()
+	if cfg.Source == nil {
+		return
+	}
+	defer cfg.Source.Close()
+	logger.Info("[POWER] Watching supply on %s (shutdown below %.2fV for %d readings)",
+		cfg.Source.Describe(), cfg.MinVoltage, cfg.Samples)
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			raw, err := cfg.Source.Read()
+			if err != nil {
+				m.readFailed(err)
+				continue
+			}
+			m.observe(raw*cfg.Scale + cfg.Offset)
+		}
+	}
+}
+
+// readFailed logs a read error once until it changes. Failed reads neither count
+// as low nor reset the count: a sagging supply can take the ADC down with it.
+func (m *Monitor) readFailed(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err.Error() != m.lastErr {
+		logger.Warn("[POWER] Failed to read supply voltage: %v", err)
+		m.lastErr = err.Error()
+	}
+}
+
+// observe counts a supply reading towards the undervoltage shutdown
+func (m *Monitor) observe(v float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastErr = ""
+
+	if v >= m.cfg.MinVoltage {
+		if m.low >= m.cfg.Samples {
+			logger.Info("[POWER] Supply recovered to %.2fV", v)
+			metrics.Global.AddLog("INFO", fmt.Sprintf("Companion supply recovered to %.2fV", v))
+		}
+		m.low, m.held = 0, false
+		return
+	}
+	m.low++
+	if m.low < m.cfg.Samples {
+		return
+	}
+	if m.armed && !m.cfg.WhenArmed {
+		if !m.held {
+			msg := fmt.Sprintf("Companion supply %.2fV below %.2fV - shutdown held while armed", v, m.cfg.MinVoltage)
+			logger.Error("[POWER] %s", msg)
+			metrics.Global.AddLog("ERROR", msg)
+			alerts.Raise("power", alerts.SeverityCritical, msg)
+			m.held = true
+		}
+		return
+	}
+	m.once.Do(func() {
+		msg := fmt.Sprintf("Companion supply %.2fV below %.2fV - shutting down", v, m.cfg.MinVoltage)
+		logger.Error("[POWER] %s", msg)
+		metrics.Global.AddLog("ERROR", msg)
+		alerts.Raise("power", alerts.SeverityCritical, msg)
+		close(m.undervoltage)
+	})
+}
+
+// PowerOff flushes file system buffers and runs the OS shutdown command
+func PowerOff(command []string) error {
+	syncDisks()
+	logger.Info("[POWER] Running %s", strings.Join(command, " "))
+	if out, err := exec.Command(command[0], command[1:]...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %v (%s)", command[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
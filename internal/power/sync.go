@@ -0,0 +1,10 @@
+//go:build !windows
+
+package power
+
+import "syscall"
+
+// syncDisks commits buffered writes (journals, counters) before the power goes
+func syncDisks() {
+	syscall.Sync()
+}
@@ -0,0 +1,4 @@
+package power
+
+// syncDisks is a no-op on Windows; the OS shutdown flushes the file system
+func syncDisks() {}
@@ -0,0 +1,332 @@
+package rollup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// dayLayout keys the daily rollups (local time, so a day matches the operator's day)
+const dayLayout = "2006-01-02"
+
+// maxFlights bounds the per-flight history kept on disk
+const maxFlights = 500
+
+// Stats are the counters accumulated per day, week and flight
+type Stats struct {
+	FlightSeconds float64 `json:"flightSeconds"`
+	Flights       int     `json:"flights"`
+	Messages      int64   `json:"messages"`    // Forwarded between the FC and the router
+	Bytes         int64   `json:"bytes"`       // Uplink data used
+	Disconnects   int64   `json:"disconnects"` // Uplink reconnects
+}
+
+func (s *Stats) add(o Stats) {
+	s.FlightSeconds += o.FlightSeconds
+	s.Flights += o.Flights
+	s.Messages += o.Messages
+	s.Bytes += o.Bytes
+	s.Disconnects += o.Disconnects
+}
+
+// Day is the rollup of one calendar day
+type Day struct {
+	Date string `json:"date"`
+	Stats
+}
+
+// Week is the rollup of one ISO week, built from the daily rollups
+type Week struct {
+	Week string `json:"week"` // e.g. 2026-W42
+	Days int    `json:"days"` // Days with data
+	Stats
+}
+
+// Flight is one armed period
+type Flight struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Stats
+}
+
+// state is the persisted form of the rollups
+type state struct {
+	Days    map[string]*Stats `json:"days"`
+	Flights []Flight          `json:"flights"`
+	Total   Stats             `json:"total"`
+	SavedAt time.Time         `json:"saved_at"`
+}
+
+// totals is a sample of the cumulative metrics counters
+type totals struct {
+	messages, bytes, reconnects int64
+}
+
+func sampleTotals() totals {
+	m, b, r := metrics.Global.Totals()
+	return totals{m, b, r}
+}
+
+func (t totals) since(o totals) Stats {
+	return Stats{Messages: t.messages - o.messages, Bytes: t.bytes - o.bytes, Disconnects: t.reconnects - o.reconnects}
+}
+
+// Recorder rolls the cumulative counters and armed time into per-day and
+// per-flight statistics that survive restarts
+type Recorder struct {
+	mu            sync.Mutex
+	path          string
+	retentionDays int
+	st            state
+
+	last        totals // Counters at the previous sample
+	haveLast    bool   // last is valid (taken after metrics were restored)
+	armed       bool
+	accountedAt time.Time // Armed time before this is already in the rollups
+	flight      *Flight   // Current flight
+	flightStart totals
+}
+
+// Global is the process-wide rollup recorder (in memory until Configure is called)
+var Global = New()
+
+// New creates an empty recorder
+func New() *Recorder {
+	return &Recorder{retentionDays: 400, st: state{Days: make(map[string]*Stats)}}
+}
+
+// Configure loads previous rollups from path. A missing file is not an error.
+func (r *Recorder) Configure(path string, retentionDays int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.path = path
+	r.retentionDays = retentionDays
+	r.last = sampleTotals()
+	r.haveLast = true
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read statistics rollups: %w", err)
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("failed to parse statistics rollups: %w", err)
+	}
+	if st.Days == nil {
+		st.Days = make(map[string]*Stats)
+	}
+	r.st = st
+	return nil
+}
+
+// ObserveArmed follows the armed state from heartbeats; transitions start and end flights
+func (r *Recorder) ObserveArmed(armed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if armed == r.armed {
+		return
+	}
+	now := time.Now()
+	if armed {
+		r.accountLocked(now)
+		r.armed = true
+		r.accountedAt = now
+		r.flight = &Flight{Start: now}
+		r.flightStart = sampleTotals()
+		r.dayLocked(now).Flights++
+		r.st.Total.Flights++
+		return
+	}
+
+	r.accountLocked(now)
+	r.armed = false
+	if r.flight != nil {
+		f := *r.flight
+		f.End = now
+		f.Stats = sampleTotals().since(r.flightStart)
+		f.Flights = 1
+		f.FlightSeconds = now.Sub(f.Start).Seconds()
+		r.st.Flights = append(r.st.Flights, f)
+		if len(r.st.Flights) > maxFlights {
+			r.st.Flights = r.st.Flights[len(r.st.Flights)-maxFlights:]
+		}
+		r.flight = nil
+		logger.Info("[ROLLUP] Flight ended after %v (%.1f h total)", now.Sub(f.Start).Round(time.Second), r.st.Total.FlightSeconds/3600)
+	}
+	r.saveLocked()
+}
+
+// accountLocked adds counter deltas and armed time since the last call to today's rollup
+func (r *Recorder) accountLocked(now time.Time) {
+	day := r.dayLocked(now)
+	cur := sampleTotals()
+	if r.haveLast {
+		delta := cur.since(r.last)
+		day.add(delta)
+		r.st.Total.add(delta)
+	}
+	r.last = cur
+	r.haveLast = true
+
+	if r.armed {
+		secs := now.Sub(r.accountedAt).Seconds()
+		day.FlightSeconds += secs
+		r.st.Total.FlightSeconds += secs
+		r.accountedAt = now
+	}
+}
+
+func (r *Recorder) dayLocked(now time.Time) *Stats {
+	key := now.Format(dayLayout)
+	d := r.st.Days[key]
+	if d == nil {
+		d = &Stats{}
+		r.st.Days[key] = d
+	}
+	return d
+}
+
+// pruneLocked drops daily rollups older than the retention
+func (r *Recorder) pruneLocked(now time.Time) {
+	if r.retentionDays <= 0 {
+		return
+	}
+	cutoff := now.AddDate(0, 0, -r.retentionDays).Format(dayLayout)
+	for key := range r.st.Days {
+		if key < cutoff {
+			delete(r.st.Days, key)
+		}
+	}
+}
+
+// saveLocked persists the rollups atomically (caller holds r.mu)
+func (r *Recorder) saveLocked() {
+	if r.path == "" {
+		return
+	}
+	r.st.SavedAt = time.Now()
+	data, err := json.MarshalIndent(r.st, "", "  ")
+	if err != nil {
+		logger.Warn("[ROLLUP] Failed to encode statistics: %v", err)
+		return
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		logger.Warn("[ROLLUP] Failed to write statistics: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		logger.Warn("[ROLLUP] Failed to write statistics: %v", err)
+	}
+}
+
+// Flush rolls up everything counted so far and persists it, e.g. on shutdown
+func (r *Recorder) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.accountLocked(now)
+	r.pruneLocked(now)
+	r.saveLocked()
+}
+
+// Run rolls up every interval until stopCh is closed
+func (r *Recorder) Run(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			r.Flush()
+		}
+	}
+}
+
+// FlightTotals returns the accumulated armed time and flight count, including the current flight
+func (r *Recorder) FlightTotals() (time.Duration, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accountLocked(time.Now())
+	return time.Duration(r.st.Total.FlightSeconds * float64(time.Second)), r.st.Total.Flights
+}
+
+// Snapshot returns the last days of daily rollups (newest first), the weeks they
+// fall in and recent flights for the web API
+func (r *Recorder) Snapshot(days int) map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.accountLocked(now)
+
+	keys := make([]string, 0, len(r.st.Days))
+	for key := range r.st.Days {
+		keys = append(keys, key)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+
+	// Weeks are summed over all retained days so the oldest listed week is complete
+	daily := make([]Day, 0, len(keys))
+	var weekly []Week
+	weekIndex := make(map[string]int)
+	for n, key := range keys {
+		s := *r.st.Days[key]
+		t, err := time.Parse(dayLayout, key)
+		if err != nil {
+			continue
+		}
+		year, week := t.ISOWeek()
+		wk := fmt.Sprintf("%d-W%02d", year, week)
+		i, ok := weekIndex[wk]
+		if !ok {
+			if days > 0 && n >= days {
+				break // Week starts outside the requested window
+			}
+			i = len(weekly)
+			weekIndex[wk] = i
+			weekly = append(weekly, Week{Week: wk})
+		}
+		weekly[i].Days++
+		weekly[i].add(s)
+		if days <= 0 || n < days {
+			daily = append(daily, Day{Date: key, Stats: s})
+		}
+	}
+
+	flights := r.st.Flights
+	if len(flights) > 20 {
+		flights = flights[len(flights)-20:]
+	}
+	recent := make([]Flight, len(flights))
+	for i, f := range flights {
+		recent[len(flights)-1-i] = f
+	}
+
+	var current interface{}
+	if r.flight != nil {
+		f := *r.flight
+		f.Stats = sampleTotals().since(r.flightStart)
+		f.Flights = 1
+		f.FlightSeconds = now.Sub(f.Start).Seconds()
+		current = f
+	}
+
+	return map[string]interface{}{
+		"total":       r.st.Total,
+		"flightHours": r.st.Total.FlightSeconds / 3600,
+		"daily":       daily,
+		"weekly":      weekly,
+		"flights":     recent,
+		"current":     current,
+	}
+}
@@ -0,0 +1,104 @@
+package safety
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// Parameter write sources
+const (
+	SourceWeb    = "web"    // Dashboard / REST API / web GCS over /ws/mavlink
+	SourceServer = "server" // Relayed by the router
+)
+
+// Rejection is a parameter write refused by the in-flight freeze
+type Rejection struct {
+	Time   time.Time `json:"time"`
+	Param  string    `json:"param"`
+	Source string    `json:"source"`
+}
+
+// Status is a snapshot of the parameter freeze
+type Status struct {
+	FreezeInFlight bool       `json:"freezeInFlight"`
+	Allowlist      []string   `json:"allowlist"`
+	Frozen         bool       `json:"frozen"` // Freeze enabled and vehicle armed
+	Rejected       int64      `json:"rejected"`
+	Last           *Rejection `json:"last,omitempty"`
+}
+
+// Guard rejects parameter writes from the web and the server while the vehicle
+// is armed. Parameters on the allowlist can still be changed in flight.
+type Guard struct {
+	mu       sync.Mutex
+	freeze   bool
+	allow    map[string]bool
+	armed    bool
+	rejected int64
+	last     *Rejection
+}
+
+// Global is the process-wide safety guard
+var Global = &Guard{allow: make(map[string]bool)}
+
+// Configure enables the in-flight parameter freeze with the parameters still allowed
+func (g *Guard) Configure(freeze bool, allowlist []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.freeze = freeze
+	g.allow = make(map[string]bool, len(allowlist))
+	for _, name := range allowlist {
+		g.allow[strings.ToUpper(name)] = true
+	}
+}
+
+// ObserveArmed follows the armed state from heartbeats
+func (g *Guard) ObserveArmed(armed bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.armed = armed
+}
+
+// CheckParamSet returns an error when a PARAM_SET from source must not reach the FC
+func (g *Guard) CheckParamSet(param, source string) error {
+	param = strings.TrimRight(param, "\x00")
+	g.mu.Lock()
+	if !g.freeze || !g.armed || g.allow[strings.ToUpper(param)] {
+		g.mu.Unlock()
+		return nil
+	}
+	g.rejected++
+	g.last = &Rejection{Time: time.Now(), Param: param, Source: source}
+	g.mu.Unlock()
+
+	msg := fmt.Sprintf("Rejected %s parameter change %s - parameters are frozen while armed", source, param)
+	logger.Warn("[SAFETY] %s", msg)
+	metrics.Global.AddLog("WARN", msg)
+	alerts.Raise("safety", alerts.SeverityWarning, msg)
+	return fmt.Errorf("parameter %s is frozen while armed", param)
+}
+
+// Snapshot returns the freeze state for the web API
+func (g *Guard) Snapshot() Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s := Status{
+		FreezeInFlight: g.freeze,
+		Allowlist:      make([]string, 0, len(g.allow)),
+		Frozen:         g.freeze && g.armed,
+		Rejected:       g.rejected,
+		Last:           g.last,
+	}
+	for name := range g.allow {
+		s.Allowlist = append(s.Allowlist, name)
+	}
+	sort.Strings(s.Allowlist)
+	return s
+}
@@ -0,0 +1,266 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/logger"
+)
+
+// maxHistory is the number of task runs kept for the web API
+const maxHistory = 100
+
+// Action is the work a task performs
+type Action func() error
+
+// Task is one configured scheduled task. Exactly one of Every or At is set.
+type Task struct {
+	Name   string
+	Action string        // Registered action name
+	Every  time.Duration // Run at a fixed interval
+	At     string        // Run daily at this local time ("HH:MM")
+}
+
+// RunRecord is one completed task run
+type RunRecord struct {
+	Task     string    `json:"task"`
+	Action   string    `json:"action"`
+	Trigger  string    `json:"trigger"` // "schedule" or "manual"
+	Started  time.Time `json:"started"`
+	Duration float64   `json:"durationSeconds"`
+	Success  bool      `json:"success"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// TaskStatus is a snapshot of one task
+type TaskStatus struct {
+	Name      string     `json:"name"`
+	Action    string     `json:"action"`
+	Schedule  string     `json:"schedule"`
+	NextRun   time.Time  `json:"nextRun"`
+	LastRun   *time.Time `json:"lastRun,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+	Running   bool       `json:"running"`
+	Runs      int        `json:"runs"`
+	Failures  int        `json:"failures"`
+}
+
+type task struct {
+	Task
+	atHour, atMin int
+
+	next      time.Time
+	lastRun   time.Time
+	lastError string
+	running   bool
+	runs      int
+	failures  int
+}
+
+// Scheduler runs registered actions at intervals or at fixed times of day
+type Scheduler struct {
+	mu      sync.Mutex
+	actions map[string]Action
+	tasks   map[string]*task
+	history []RunRecord
+}
+
+// Global is the process-wide scheduler
+var Global = New()
+
+// New creates an empty scheduler
+func New() *Scheduler {
+	return &Scheduler{actions: make(map[string]Action), tasks: make(map[string]*task)}
+}
+
+// RegisterAction makes an action available to configured tasks
+func (s *Scheduler) RegisterAction(name string, fn Action) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions[name] = fn
+}
+
+// Add schedules a task. The action must already be registered.
+func (s *Scheduler) Add(t Task) error {
+	if t.Name == "" {
+		t.Name = t.Action
+	}
+	tk := &task{Task: t}
+	switch {
+	case t.Every > 0 && t.At != "":
+		return fmt.Errorf("task %q: set either an interval or a time of day, not both", t.Name)
+	case t.Every > 0:
+	case t.At != "":
+		at, err := time.Parse("15:04", t.At)
+		if err != nil {
+			return fmt.Errorf("task %q: invalid time %q (want HH:MM)", t.Name, t.At)
+		}
+		tk.atHour, tk.atMin = at.Hour(), at.Minute()
+	default:
+		return fmt.Errorf("task %q: no interval or time of day", t.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.actions[t.Action]; !ok {
+		return fmt.Errorf("task %q: unknown action %q", t.Name, t.Action)
+	}
+	if _, exists := s.tasks[t.Name]; exists {
+		return fmt.Errorf("task %q is defined twice", t.Name)
+	}
+	tk.next = tk.nextAfter(time.Now())
+	s.tasks[t.Name] = tk
+	return nil
+}
+
+// nextAfter returns the first run time after now
+func (t *task) nextAfter(now time.Time) time.Time {
+	if t.Every > 0 {
+		return now.Add(t.Every)
+	}
+	next := time.Date(now.Year(), now.Month(), now.Day(), t.atHour, t.atMin, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func (t *task) schedule() string {
+	if t.Every > 0 {
+		return "every " + t.Every.String()
+	}
+	return "daily at " + t.At
+}
+
+// Run starts due tasks until stopCh is closed
+func (s *Scheduler) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for _, t := range s.tasks {
+				if now.Before(t.next) {
+					continue
+				}
+				t.next = t.nextAfter(now)
+				s.startLocked(t, "schedule")
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// RunNow starts a task immediately, outside its schedule
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks[name]
+	if !ok {
+		return fmt.Errorf("unknown task %q", name)
+	}
+	if t.running {
+		return fmt.Errorf("task %q is already running", name)
+	}
+	s.startLocked(t, "manual")
+	return nil
+}
+
+// startLocked runs a task in the background unless it is still running (caller holds lock)
+func (s *Scheduler) startLocked(t *task, trigger string) {
+	if t.running {
+		logger.Warn("[SCHEDULE] Skipping %s: previous run still in progress", t.Name)
+		return
+	}
+	t.running = true
+	fn := s.actions[t.Action]
+	go s.execute(t, fn, trigger)
+}
+
+func (s *Scheduler) execute(t *task, fn Action, trigger string) {
+	start := time.Now()
+	logger.Info("[SCHEDULE] Running %s (%s)", t.Name, t.Action)
+	err := fn()
+
+	rec := RunRecord{
+		Task:     t.Name,
+		Action:   t.Action,
+		Trigger:  trigger,
+		Started:  start,
+		Duration: time.Since(start).Seconds(),
+		Success:  err == nil,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	t.running = false
+	t.lastRun = start
+	t.lastError = rec.Error
+	t.runs++
+	if err != nil {
+		t.failures++
+	}
+	s.history = append(s.history, rec)
+	if len(s.history) > maxHistory {
+		s.history = s.history[len(s.history)-maxHistory:]
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		logger.Warn("[SCHEDULE] %s failed: %v", t.Name, err)
+		alerts.Raise("scheduler", alerts.SeverityWarning, fmt.Sprintf("Scheduled task %s failed: %v", t.Name, err))
+	} else {
+		logger.Info("[SCHEDULE] ✅ %s completed in %v", t.Name, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// Snapshot returns task status sorted by next run and the run history (newest first)
+func (s *Scheduler) Snapshot() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]TaskStatus, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		st := TaskStatus{
+			Name:      t.Name,
+			Action:    t.Action,
+			Schedule:  t.schedule(),
+			NextRun:   t.next,
+			LastError: t.lastError,
+			Running:   t.running,
+			Runs:      t.runs,
+			Failures:  t.failures,
+		}
+		if !t.lastRun.IsZero() {
+			last := t.lastRun
+			st.LastRun = &last
+		}
+		tasks = append(tasks, st)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].NextRun.Before(tasks[j].NextRun) })
+
+	history := make([]RunRecord, len(s.history))
+	for i, rec := range s.history {
+		history[len(s.history)-1-i] = rec
+	}
+
+	actions := make([]string, 0, len(s.actions))
+	for name := range s.actions {
+		actions = append(actions, name)
+	}
+	sort.Strings(actions)
+
+	return map[string]interface{}{
+		"tasks":   tasks,
+		"history": history,
+		"actions": actions,
+	}
+}
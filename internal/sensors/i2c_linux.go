@@ -0,0 +1,31 @@
+package sensors
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// i2cSlave is the I2C_SLAVE ioctl from linux/i2c-dev.h
+const i2cSlave = 0x0703
+
+// i2cReadRegister selects a register on an I2C device and reads length bytes
+func i2cReadRegister(device string, address uint16, register byte, length int) ([]byte, error) {
+	f, err := os.OpenFile(device, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", device, err)
+	}
+	defer f.Close()
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), i2cSlave, uintptr(address)); errno != 0 {
+		return nil, fmt.Errorf("failed to select address 0x%02x on %s: %v", address, device, errno)
+	}
+	if _, err := f.Write([]byte{register}); err != nil {
+		return nil, fmt.Errorf("failed to select register 0x%02x: %w", register, err)
+	}
+	buf := make([]byte, length)
+	if _, err := f.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to read register 0x%02x: %w", register, err)
+	}
+	return buf, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package sensors
+
+import "fmt"
+
+// i2cReadRegister is only implemented through Linux i2c-dev
+func i2cReadRegister(device string, address uint16, register byte, length int) ([]byte, error) {
+	return nil, fmt.Errorf("i2c sensors are only supported on Linux")
+}
@@ -0,0 +1,229 @@
+package sensors
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/logger"
+)
+
+// Kinds of MAVLink message a sensor is published as
+const (
+	KindDistance = "distance" // DISTANCE_SENSOR (value in meters)
+	KindValue    = "value"    // NAMED_VALUE_FLOAT
+)
+
+// Input is one configured external sensor
+type Input struct {
+	Name     string
+	Kind     string
+	Source   Source
+	Interval time.Duration
+	Scale    float64 // value = raw*Scale + Offset
+	Offset   float64
+	ToFC     bool // Inject into the flight controller link
+	ToServer bool // Send with the telemetry stream to the router
+
+	// DISTANCE_SENSOR only
+	ID          uint8
+	Orientation common.MAV_SENSOR_ORIENTATION
+	SensorType  common.MAV_DISTANCE_SENSOR
+	MinDistance float64 // meters
+	MaxDistance float64 // meters
+}
+
+// Reading is the latest state of one sensor
+type Reading struct {
+	Name     string     `json:"name"`
+	Kind     string     `json:"kind"`
+	Source   string     `json:"source"`
+	Value    float64    `json:"value"`
+	Raw      float64    `json:"raw"`
+	Updated  *time.Time `json:"updated,omitempty"`
+	Reads    uint64     `json:"reads"`
+	Errors   uint64     `json:"errors"`
+	LastErr  string     `json:"lastError,omitempty"`
+	ToFC     bool       `json:"toFc"`
+	ToServer bool       `json:"toServer"`
+}
+
+// Publisher sends a sensor message towards the flight controller and/or the router
+type Publisher func(msg message.Message, toFC, toServer bool)
+
+type input struct {
+	Input
+
+	mu      sync.Mutex
+	value   float64
+	raw     float64
+	updated time.Time
+	reads   uint64
+	errors  uint64
+	lastErr string
+}
+
+// Hub polls external sensors and publishes them as MAVLink
+type Hub struct {
+	mu      sync.RWMutex
+	inputs  []*input
+	publish Publisher
+	started time.Time
+}
+
+// Global is the process-wide sensor hub
+var Global = New()
+
+// New creates a hub without sensors
+func New() *Hub {
+	return &Hub{started: time.Now()}
+}
+
+// Add registers a sensor. Must be called before Run.
+func (h *Hub) Add(in Input) {
+	if in.Scale == 0 {
+		in.Scale = 1
+	}
+	h.mu.Lock()
+	h.inputs = append(h.inputs, &input{Input: in})
+	h.mu.Unlock()
+	logger.Info("[SENSORS] %s (%s) on %s every %v", in.Name, in.Kind, in.Source.Describe(), in.Interval)
+}
+
+// SetPublisher sets where readings are sent (the forwarder once it is running)
+func (h *Hub) SetPublisher(p Publisher) {
+	h.mu.Lock()
+	h.publish = p
+	h.mu.Unlock()
+}
+
+// Run polls every sensor at its interval until stopCh is closed
+func (h *Hub) Run(stopCh <-chan struct{}) {
+	h.mu.RLock()
+	inputs := h.inputs
+	h.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, in := range inputs {
+		wg.Add(1)
+		go func(in *input) {
+			defer wg.Done()
+			h.poll(in, stopCh)
+		}(in)
+	}
+	wg.Wait()
+}
+
+func (h *Hub) poll(in *input, stopCh <-chan struct{}) {
+	defer in.Source.Close()
+	ticker := time.NewTicker(in.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		raw, err := in.Source.Read()
+		in.mu.Lock()
+		if err != nil {
+			// Log only when the error changes so a disconnected sensor doesn't flood the log
+			if in.lastErr != err.Error() {
+				logger.Warn("[SENSORS] %s: %v", in.Name, err)
+			}
+			in.errors++
+			in.lastErr = err.Error()
+			in.mu.Unlock()
+			continue
+		}
+		if in.lastErr != "" {
+			logger.Info("[SENSORS] %s recovered", in.Name)
+		}
+		in.raw = raw
+		in.value = raw*in.Scale + in.Offset
+		in.updated = time.Now()
+		in.reads++
+		in.lastErr = ""
+		value := in.value
+		in.mu.Unlock()
+
+		h.mu.RLock()
+		publish := h.publish
+		h.mu.RUnlock()
+		if publish != nil && (in.ToFC || in.ToServer) {
+			publish(h.message(in, value), in.ToFC, in.ToServer)
+		}
+	}
+}
+
+// message builds the MAVLink message for a reading
+func (h *Hub) message(in *input, value float64) message.Message {
+	bootMs := uint32(time.Since(h.started).Milliseconds())
+	if in.Kind == KindDistance {
+		return &common.MessageDistanceSensor{
+			TimeBootMs:      bootMs,
+			MinDistance:     centimeters(in.MinDistance),
+			MaxDistance:     centimeters(in.MaxDistance),
+			CurrentDistance: centimeters(value),
+			Type:            in.SensorType,
+			Id:              in.ID,
+			Orientation:     in.Orientation,
+			Covariance:      math.MaxUint8, // Unknown
+		}
+	}
+	name := in.Name
+	if len(name) > 10 {
+		name = name[:10] // NAMED_VALUE_FLOAT names are 10 characters
+	}
+	return &common.MessageNamedValueFloat{
+		TimeBootMs: bootMs,
+		Name:       name,
+		Value:      float32(value),
+	}
+}
+
+// centimeters converts meters to the uint16 centimeters DISTANCE_SENSOR uses
+func centimeters(m float64) uint16 {
+	cm := math.Round(m * 100)
+	if cm < 0 {
+		return 0
+	}
+	if cm > math.MaxUint16 {
+		return math.MaxUint16
+	}
+	return uint16(cm)
+}
+
+// Snapshot returns the latest reading of every sensor in configuration order
+func (h *Hub) Snapshot() []Reading {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	out := make([]Reading, 0, len(h.inputs))
+	for _, in := range h.inputs {
+		in.mu.Lock()
+		r := Reading{
+			Name:     in.Name,
+			Kind:     in.Kind,
+			Source:   in.Source.Describe(),
+			Value:    in.value,
+			Raw:      in.raw,
+			Reads:    in.reads,
+			Errors:   in.errors,
+			LastErr:  in.lastErr,
+			ToFC:     in.ToFC,
+			ToServer: in.ToServer,
+		}
+		if !in.updated.IsZero() {
+			t := in.updated
+			r.Updated = &t
+		}
+		in.mu.Unlock()
+		out = append(out, r)
+	}
+	return out
+}
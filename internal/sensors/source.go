@@ -0,0 +1,172 @@
+package sensors
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Source reads one raw value from an external sensor
+type Source interface {
+	Read() (float64, error)
+	Describe() string
+	Close() error
+}
+
+// numberPattern matches the first number on a serial line ("R0123", "dist=1.52m", "412 ppm")
+var numberPattern = regexp.MustCompile(`[-+]?[0-9]*\.?[0-9]+`)
+
+// serialSource reads newline-terminated ASCII readings from a UART sensor.
+// A background reader keeps the latest value so Read never blocks on the port.
+type serialSource struct {
+	device string
+	maxAge time.Duration
+	file   *os.File
+
+	mu      sync.Mutex
+	value   float64
+	updated time.Time
+	err     error
+}
+
+// NewSerial opens a UART sensor that prints one reading per line. baud > 0
+// configures the port with stty; 0 leaves the current port settings.
+// Readings older than maxAge are reported as stale.
+func NewSerial(device string, baud int, maxAge time.Duration) (Source, error) {
+	if baud > 0 {
+		if out, err := exec.Command("stty", "-F", device, strconv.Itoa(baud), "raw", "-echo").CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("failed to configure %s: %v (%s)", device, err, string(out))
+		}
+	}
+	f, err := os.Open(device)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", device, err)
+	}
+	s := &serialSource{device: device, maxAge: maxAge, file: f}
+	go s.readLines()
+	return s, nil
+}
+
+func (s *serialSource) readLines() {
+	scanner := bufio.NewScanner(s.file)
+	for scanner.Scan() {
+		match := numberPattern.FindString(scanner.Text())
+		if match == "" {
+			continue // Banner or status line
+		}
+		v, err := strconv.ParseFloat(match, 64)
+		if err != nil {
+			continue
+		}
+		s.mu.Lock()
+		s.value, s.updated, s.err = v, time.Now(), nil
+		s.mu.Unlock()
+	}
+	s.mu.Lock()
+	s.err = fmt.Errorf("%s closed: %v", s.device, scanner.Err())
+	s.mu.Unlock()
+}
+
+func (s *serialSource) Read() (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.updated.IsZero() {
+		return 0, fmt.Errorf("no reading from %s yet", s.device)
+	}
+	if age := time.Since(s.updated); age > s.maxAge {
+		return 0, fmt.Errorf("last reading from %s is %v old", s.device, age.Round(time.Millisecond))
+	}
+	return s.value, nil
+}
+
+func (s *serialSource) Describe() string {
+	return "serial " + s.device
+}
+
+func (s *serialSource) Close() error {
+	return s.file.Close()
+}
+
+// i2cSource reads a big-endian register from an I2C device
+type i2cSource struct {
+	device   string
+	address  uint16
+	register byte
+	length   int
+}
+
+// NewI2C opens an I2C sensor register of 1, 2 or 4 bytes (big-endian, unsigned)
+func NewI2C(device string, address uint16, register byte, length int) (Source, error) {
+	if length != 1 && length != 2 && length != 4 {
+		return nil, fmt.Errorf("i2c register length must be 1, 2 or 4 bytes, got %d", length)
+	}
+	s := &i2cSource{device: device, address: address, register: register, length: length}
+	// Probe once so a wrong bus or address is reported at startup
+	if _, err := s.Read(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *i2cSource) Read() (float64, error) {
+	buf, err := i2cReadRegister(s.device, s.address, s.register, s.length)
+	if err != nil {
+		return 0, err
+	}
+	var raw uint32
+	for _, b := range buf {
+		raw = raw<<8 | uint32(b)
+	}
+	return float64(raw), nil
+}
+
+func (s *i2cSource) Describe() string {
+	return fmt.Sprintf("i2c %s 0x%02x reg 0x%02x", s.device, s.address, s.register)
+}
+
+func (s *i2cSource) Close() error {
+	return nil
+}
+
+// fileSource reads a number from a file on every poll, e.g. a sysfs hwmon or IIO value
+type fileSource struct {
+	path string
+}
+
+// NewFile opens a sensor exposed by a kernel driver as a file
+func NewFile(path string) (Source, error) {
+	s := &fileSource{path: path}
+	// Probe once so a wrong path is reported at startup
+	if _, err := s.Read(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSource) Read() (float64, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return 0, err
+	}
+	match := numberPattern.FindString(string(data))
+	if match == "" {
+		return 0, fmt.Errorf("no reading in %s", s.path)
+	}
+	return strconv.ParseFloat(match, 64)
+}
+
+func (s *fileSource) Describe() string {
+	return "file " + s.path
+}
+
+func (s *fileSource) Close() error {
+	return nil
+}
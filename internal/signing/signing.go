@@ -0,0 +1,316 @@
+package signing
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialect"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+)
+
+// rotationGrace is how long frames signed with the previous key are still
+// accepted after a rotation, so in-flight traffic and a router switching over
+// a moment later are not rejected
+const rotationGrace = 2 * time.Minute
+
+// epoch is the reference of MAVLink 2 signature timestamps (10 µs units)
+var epoch = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Config configures MAVLink 2 signing (signing section)
+type Config struct {
+	Enabled         bool
+	KeyFile         string        // Hex-encoded 32-byte secret key
+	LinkID          uint8         // Signature link ID of our frames
+	Window          time.Duration // Max age of an incoming timestamp vs. our clock (0 = only replay check)
+	RequireIncoming bool          // Reject unsigned frames from the server
+	SignToFC        bool          // Sign frames sent to the FC (it must have the same key, see SETUP_SIGNING)
+}
+
+// stream identifies a signed stream; timestamps must increase per stream
+type stream struct {
+	sysID, compID, linkID uint8
+}
+
+// Signer signs and verifies MAVLink 2 frames with the shared secret key
+type Signer struct {
+	mu        sync.Mutex
+	cfg       Config
+	key       *frame.V2Key
+	prev      *frame.V2Key // Accepted until prevUntil after a rotation
+	prevUntil time.Time
+	lastTS    uint64            // Last timestamp we signed with
+	streams   map[stream]uint64 // Last accepted timestamp per incoming stream
+	rw        *dialect.ReadWriter
+
+	signed, verified, unsigned, rejected atomic.Uint64
+}
+
+// Global is the process-wide signer
+var Global = &Signer{streams: make(map[stream]uint64)}
+
+// Configure sets up signing and loads the key file, if present. Without a key
+// signing stays inactive until one is provisioned (SetKey).
+func (s *Signer) Configure(cfg Config) error {
+	rw, err := dialect.NewReadWriter(mavlink_custom.GetCombinedDialect())
+	if err != nil {
+		return fmt.Errorf("failed to init dialect: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfg
+	s.rw = rw
+	if !cfg.Enabled {
+		return nil
+	}
+
+	data, err := os.ReadFile(cfg.KeyFile)
+	if os.IsNotExist(err) {
+		logger.Warn("[SIGNING] No signing key at %s yet - frames stay unsigned until the router provisions one", cfg.KeyFile)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read signing key: %w", err)
+	}
+	key, err := parseKey(strings.TrimSpace(string(data)))
+	if err != nil {
+		return fmt.Errorf("invalid signing key in %s: %w", cfg.KeyFile, err)
+	}
+	s.key = key
+	logger.Info("[SIGNING] MAVLink 2 signing enabled (key %s, link ID %d)", fingerprint(key), cfg.LinkID)
+	return nil
+}
+
+// SetKey installs a new secret key (rotation). The previous key stays valid
+// for incoming frames during rotationGrace. The key is saved to the key file.
+func (s *Signer) SetKey(raw []byte, source string) error {
+	if len(raw) != len(frame.V2Key{}) {
+		return fmt.Errorf("signing key must be %d bytes, got %d", len(frame.V2Key{}), len(raw))
+	}
+	key := new(frame.V2Key)
+	copy(key[:], raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.cfg.Enabled {
+		return fmt.Errorf("MAVLink signing is disabled (signing.enabled)")
+	}
+	if s.key != nil && *s.key == *key {
+		return nil // Already in use
+	}
+	if err := saveKey(s.cfg.KeyFile, key); err != nil {
+		return err
+	}
+	if s.key != nil {
+		s.prev = s.key
+		s.prevUntil = time.Now().Add(rotationGrace)
+	}
+	s.key = key
+	logger.Info("[SIGNING] 🔑 Signing key %s installed from %s", fingerprint(key), source)
+	return nil
+}
+
+// Active reports whether frames are signed (enabled and a key is present)
+func (s *Signer) Active() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.key != nil && s.cfg.Enabled
+}
+
+// SignsFC reports whether frames to the FC are signed as well
+func (s *Signer) SignsFC() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.key != nil && s.cfg.Enabled && s.cfg.SignToFC
+}
+
+// ReloadDialect rebuilds the dialect after the session message identity changed
+// (mavlink_custom.SetIdentity), so SESSION_HEARTBEAT is signed with its new ID
+func (s *Signer) ReloadDialect() error {
+	rw, err := dialect.NewReadWriter(mavlink_custom.GetCombinedDialect())
+	if err != nil {
+		return fmt.Errorf("failed to init dialect: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rw = rw
+	return nil
+}
+
+// Sign returns a signed copy of fr, or fr itself when signing is inactive.
+// MAVLink 1 frames are re-framed as MAVLink 2, which alone can carry a signature.
+func (s *Signer) Sign(fr frame.Frame) (frame.Frame, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key == nil || !s.cfg.Enabled {
+		return fr, nil
+	}
+
+	var c frame.V2Frame
+	switch ff := fr.(type) {
+	case *frame.V2Frame:
+		c = *ff
+	case *frame.V1Frame:
+		c = frame.V2Frame{SequenceNumber: ff.SequenceNumber, SystemID: ff.SystemID, ComponentID: ff.ComponentID, Message: ff.Message}
+	default:
+		return nil, fmt.Errorf("unsupported frame type %T", fr)
+	}
+	mp := s.rw.GetMessage(c.Message.GetID())
+	if mp == nil {
+		return nil, fmt.Errorf("message %d is not in the dialect", c.Message.GetID())
+	}
+	if _, ok := c.Message.(*message.MessageRaw); !ok {
+		c.Message = mp.Write(c.Message, true)
+	}
+
+	// Timestamps must strictly increase, even if the clock steps back or two frames share a tick
+	ts := uint64(time.Since(epoch) / (10 * time.Microsecond))
+	if ts <= s.lastTS {
+		ts = s.lastTS + 1
+	}
+	s.lastTS = ts
+
+	c.IncompatibilityFlag |= frame.V2FlagSigned // Covered by the checksum
+	c.Checksum = c.GenerateChecksum(mp.CRCExtra())
+	c.SignatureLinkID = s.cfg.LinkID
+	c.SignatureTimestamp = ts
+	c.Signature = c.GenerateSignature(s.key)
+	s.signed.Add(1)
+	return &c, nil
+}
+
+// SignMessage frames msg as sysID/compID with the given sequence number and signs it
+func (s *Signer) SignMessage(msg message.Message, sysID, compID, seq uint8) (frame.Frame, error) {
+	return s.Sign(&frame.V2Frame{SequenceNumber: seq, SystemID: sysID, ComponentID: compID, Message: msg})
+}
+
+// Verify checks the signature of an incoming frame: the key (or the previous
+// one during a rotation), a timestamp newer than the stream's last and within
+// the window. Unsigned frames pass unless RequireIncoming is set.
+func (s *Signer) Verify(fr frame.Frame) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key == nil || !s.cfg.Enabled {
+		return nil
+	}
+
+	ff, ok := fr.(*frame.V2Frame)
+	if !ok || !ff.IsSigned() || ff.Signature == nil {
+		s.unsigned.Add(1)
+		if s.cfg.RequireIncoming {
+			s.rejected.Add(1)
+			return fmt.Errorf("frame is not signed")
+		}
+		return nil
+	}
+
+	// The signature covers the payload as sent; decoded messages are re-encoded (MAVLink 2 truncation is canonical)
+	c := *ff
+	if _, isRaw := c.Message.(*message.MessageRaw); !isRaw {
+		mp := s.rw.GetMessage(c.Message.GetID())
+		if mp == nil {
+			s.rejected.Add(1)
+			return fmt.Errorf("message %d is not in the dialect", c.Message.GetID())
+		}
+		c.Message = mp.Write(c.Message, true)
+	}
+	valid := subtle.ConstantTimeCompare(c.GenerateSignature(s.key)[:], ff.Signature[:]) == 1
+	if !valid && s.prev != nil && time.Now().Before(s.prevUntil) {
+		valid = subtle.ConstantTimeCompare(c.GenerateSignature(s.prev)[:], ff.Signature[:]) == 1
+	}
+	if !valid {
+		s.rejected.Add(1)
+		return fmt.Errorf("wrong signature")
+	}
+
+	st := stream{sysID: ff.SystemID, compID: ff.ComponentID, linkID: ff.SignatureLinkID}
+	if last, seen := s.streams[st]; seen && ff.SignatureTimestamp <= last {
+		s.rejected.Add(1)
+		return fmt.Errorf("replayed signature timestamp %d (last %d)", ff.SignatureTimestamp, last)
+	}
+	if s.cfg.Window > 0 {
+		sent := epoch.Add(time.Duration(ff.SignatureTimestamp) * 10 * time.Microsecond)
+		if skew := time.Since(sent); skew > s.cfg.Window || skew < -s.cfg.Window {
+			s.rejected.Add(1)
+			return fmt.Errorf("signature timestamp %v off our clock (window %v)", skew.Round(time.Millisecond), s.cfg.Window)
+		}
+	}
+	s.streams[st] = ff.SignatureTimestamp
+	s.verified.Add(1)
+	return nil
+}
+
+// Status is the signing state shown by the API
+type Status struct {
+	Enabled  bool   `json:"enabled"`
+	Active   bool   `json:"active"`        // A key is present
+	Key      string `json:"key,omitempty"` // Fingerprint, compare with the router's
+	LinkID   uint8  `json:"linkId"`
+	SignToFC bool   `json:"signToFC"`
+	Signed   uint64 `json:"signed"`
+	Verified uint64 `json:"verified"`
+	Unsigned uint64 `json:"unsigned"` // Unsigned frames from the server
+	Rejected uint64 `json:"rejected"`
+}
+
+// Status returns the configuration, key fingerprint and counters
+func (s *Signer) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := Status{
+		Enabled:  s.cfg.Enabled,
+		Active:   s.cfg.Enabled && s.key != nil,
+		LinkID:   s.cfg.LinkID,
+		SignToFC: s.cfg.SignToFC,
+		Signed:   s.signed.Load(),
+		Verified: s.verified.Load(),
+		Unsigned: s.unsigned.Load(),
+		Rejected: s.rejected.Load(),
+	}
+	if s.key != nil {
+		st.Key = fingerprint(s.key)
+	}
+	return st
+}
+
+// saveKey writes the key hex-encoded and atomically, readable by the owner only
+func saveKey(path string, key *frame.V2Key) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(hex.EncodeToString(key[:])+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to save signing key: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save signing key: %w", err)
+	}
+	return nil
+}
+
+func parseKey(s string) (*frame.V2Key, error) {
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != len(frame.V2Key{}) {
+		return nil, fmt.Errorf("expected %d bytes, got %d", len(frame.V2Key{}), len(raw))
+	}
+	key := new(frame.V2Key)
+	copy(key[:], raw)
+	return key, nil
+}
+
+// fingerprint identifies a key in logs without revealing it
+func fingerprint(key *frame.V2Key) string {
+	sum := sha256.Sum256(key[:])
+	return hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,149 @@
+package soak
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3"
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+)
+
+// simSystemID is the system ID of the simulated flight controller
+const simSystemID = 1
+
+// udpPeer is a datagram socket bound to a fixed local port that sends to one
+// target. Unlike a connected socket it also accepts replies from the bridge's
+// unicast listener, which sends from its own ephemeral port.
+type udpPeer struct {
+	conn   *net.UDPConn
+	target *net.UDPAddr
+}
+
+func (p *udpPeer) Read(b []byte) (int, error) {
+	n, _, err := p.conn.ReadFromUDP(b)
+	return n, err
+}
+
+func (p *udpPeer) Write(b []byte) (int, error) {
+	return p.conn.WriteToUDP(b, p.target)
+}
+
+func (p *udpPeer) Close() error {
+	return p.conn.Close()
+}
+
+// simulator is a flight controller stand-in that streams telemetry at a fixed rate
+type simulator struct {
+	node *gomavlib.Node
+	port int
+	rate int
+	sent atomic.Uint64
+}
+
+// newSimulator binds a loopback socket and streams to the bridge's listen port
+func newSimulator(listenPort, rate int) (*simulator, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, fmt.Errorf("simulator socket: %w", err)
+	}
+	node, err := gomavlib.NewNode(gomavlib.NodeConf{
+		Endpoints: []gomavlib.EndpointConf{
+			gomavlib.EndpointCustom{ReadWriteCloser: &udpPeer{
+				conn:   conn,
+				target: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: listenPort},
+			}},
+		},
+		Dialect:     mavlink_custom.GetCombinedDialect(),
+		OutVersion:  gomavlib.V2,
+		OutSystemID: simSystemID,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("simulator node: %w", err)
+	}
+	return &simulator{node: node, port: conn.LocalAddr().(*net.UDPAddr).Port, rate: rate}, nil
+}
+
+// run streams messages until stopCh is closed. Messages go out in 10 ms bursts
+// so rates above the timer resolution are reached.
+func (s *simulator) run(stopCh <-chan struct{}) {
+	defer s.node.Close()
+	go func() {
+		// Drain commands and acks the bridge forwards to the "FC"
+		for range s.node.Events() {
+		}
+	}()
+
+	const tick = 10 * time.Millisecond
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	start := time.Now()
+	logger.Info("[SOAK] Simulator streaming %d msg/s from 127.0.0.1:%d", s.rate, s.port)
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+		due := uint64(time.Since(start).Seconds() * float64(s.rate))
+		for s.sent.Load() < due {
+			n := s.sent.Add(1)
+			s.node.WriteMessageAll(simMessage(n, time.Since(start)))
+		}
+	}
+}
+
+// simMessage returns the n-th message of a telemetry mix: one heartbeat per ten
+// messages, the rest attitude, position, GPS, VFR_HUD and SYS_STATUS
+func simMessage(n uint64, elapsed time.Duration) message.Message {
+	t := elapsed.Seconds()
+	switch n % 10 {
+	case 0:
+		return &common.MessageHeartbeat{
+			Type:           common.MAV_TYPE_QUADROTOR,
+			Autopilot:      common.MAV_AUTOPILOT_ARDUPILOTMEGA,
+			BaseMode:       common.MAV_MODE_FLAG_CUSTOM_MODE_ENABLED,
+			SystemStatus:   common.MAV_STATE_STANDBY,
+			MavlinkVersion: 3,
+		}
+	case 1, 2, 3:
+		return &common.MessageAttitude{
+			TimeBootMs: uint32(elapsed.Milliseconds()),
+			Roll:       float32(0.1 * math.Sin(t)),
+			Pitch:      float32(0.1 * math.Cos(t)),
+			Yaw:        float32(math.Mod(t/10, 2*math.Pi)),
+		}
+	case 4, 5:
+		return &common.MessageGlobalPositionInt{
+			TimeBootMs:  uint32(elapsed.Milliseconds()),
+			Lat:         int32((21.0285 + 0.0005*math.Sin(t/60)) * 1e7),
+			Lon:         int32((105.8542 + 0.0005*math.Cos(t/60)) * 1e7),
+			Alt:         20000,
+			RelativeAlt: 10000,
+		}
+	case 6:
+		return &common.MessageGpsRawInt{
+			FixType:           common.GPS_FIX_TYPE_3D_FIX,
+			Lat:               int32(21.0285 * 1e7),
+			Lon:               int32(105.8542 * 1e7),
+			SatellitesVisible: 14,
+			Eph:               80,
+			Epv:               120,
+		}
+	case 7, 8:
+		return &common.MessageVfrHud{Airspeed: 5, Groundspeed: 5, Heading: int16(int(t) % 360), Alt: 20}
+	default:
+		return &common.MessageSysStatus{
+			VoltageBattery:   15800,
+			CurrentBattery:   1200,
+			BatteryRemaining: 80,
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package soak
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// maxSamples bounds the samples kept for the report (older ones are thinned)
+const maxSamples = 720
+
+// Config controls a soak run
+type Config struct {
+	Duration           time.Duration
+	Rate               int           // Simulated FC messages per second
+	ListenPort         int           // Bridge port the simulator streams to
+	ReconnectInterval  time.Duration // Forced sender node recreation (0 = never)
+	SampleInterval     time.Duration
+	Warmup             time.Duration // Baseline is taken after this
+	MaxGoroutineGrowth int
+	MaxHeapGrowthMB    float64
+	MaxFDGrowth        int
+	MaxQueueDepth      int    // Pending writes in any direction (0 = not checked)
+	ReportFile         string // JSON report; the goroutine dump goes next to it
+}
+
+// Hooks connect the runner to the forwarder under test
+type Hooks struct {
+	QueueDepths func() map[string]int
+	Reconnect   func(reason string)
+}
+
+// Sample is one measurement of the process
+type Sample struct {
+	Time        time.Time      `json:"time"`
+	Goroutines  int            `json:"goroutines"`
+	HeapMB      float64        `json:"heapMB"` // Live heap after GC
+	FDs         int            `json:"fds"`    // Open file descriptors (-1 = not available)
+	QueueDepths map[string]int `json:"queueDepths,omitempty"`
+	Sent        uint64         `json:"sent"` // Simulated messages so far
+	Reconnects  int            `json:"reconnects"`
+}
+
+// Report is the outcome of a soak run
+type Report struct {
+	Running       bool       `json:"running"`
+	Passed        bool       `json:"passed"`
+	Failure       string     `json:"failure,omitempty"`
+	Started       time.Time  `json:"started"`
+	Finished      *time.Time `json:"finished,omitempty"`
+	Duration      string     `json:"duration"`
+	Rate          int        `json:"rate"`
+	Baseline      *Sample    `json:"baseline,omitempty"`
+	Latest        *Sample    `json:"latest,omitempty"`
+	Peak          Sample     `json:"peak"` // Per-field maxima
+	Samples       []Sample   `json:"samples"`
+	GoroutineDump string     `json:"goroutineDump,omitempty"` // File with the stacks at failure
+	cfg           Config
+}
+
+// Runner drives the simulator, forces node recreation and checks for leaks
+type Runner struct {
+	mu     sync.Mutex
+	sim    *simulator
+	report *Report
+}
+
+// Global is the process-wide soak runner (idle unless --soak is given)
+var Global = &Runner{}
+
+// StartSimulator starts the simulated FC so it can be discovered; returns the
+// loopback port it streams from
+func (r *Runner) StartSimulator(cfg Config, stopCh <-chan struct{}) (int, error) {
+	sim, err := newSimulator(cfg.ListenPort, cfg.Rate)
+	if err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	r.sim = sim
+	r.report = &Report{Running: true, Started: time.Now(), Duration: cfg.Duration.String(), Rate: cfg.Rate, cfg: cfg}
+	r.mu.Unlock()
+	go sim.run(stopCh)
+	return sim.port, nil
+}
+
+// Run samples the process until the duration has passed or a threshold is
+// exceeded, writes the report and sends it on the returned channel
+func (r *Runner) Run(hooks Hooks, stopCh <-chan struct{}) <-chan Report {
+	done := make(chan Report, 1)
+	go func() {
+		done <- r.run(hooks, stopCh)
+	}()
+	return done
+}
+
+func (r *Runner) run(hooks Hooks, stopCh <-chan struct{}) Report {
+	r.mu.Lock()
+	cfg := r.report.cfg
+	start := r.report.Started
+	r.mu.Unlock()
+
+	end := time.After(cfg.Duration - time.Since(start))
+	sampleTicker := time.NewTicker(cfg.SampleInterval)
+	defer sampleTicker.Stop()
+	var reconnectC <-chan time.Time
+	if cfg.ReconnectInterval > 0 && hooks.Reconnect != nil {
+		t := time.NewTicker(cfg.ReconnectInterval)
+		defer t.Stop()
+		reconnectC = t.C
+	}
+
+	reconnects := 0
+	failure := ""
+	for failure == "" {
+		select {
+		case <-stopCh:
+			failure = "interrupted"
+		case <-end:
+			r.finish("")
+			return r.Snapshot()
+		case <-reconnectC:
+			reconnects++
+			hooks.Reconnect(fmt.Sprintf("soak: forced node recreation #%d", reconnects))
+		case <-sampleTicker.C:
+			s := r.sample(hooks, reconnects)
+			failure = r.record(s, time.Since(start) >= cfg.Warmup)
+		}
+	}
+	r.finish(failure)
+	return r.Snapshot()
+}
+
+// sample measures the process now
+func (r *Runner) sample(hooks Hooks, reconnects int) Sample {
+	runtime.GC()
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	s := Sample{
+		Time:       time.Now(),
+		Goroutines: runtime.NumGoroutine(),
+		HeapMB:     float64(ms.HeapAlloc) / (1024 * 1024),
+		FDs:        countFDs(),
+		Reconnects: reconnects,
+	}
+	if hooks.QueueDepths != nil {
+		s.QueueDepths = hooks.QueueDepths()
+	}
+	r.mu.Lock()
+	if r.sim != nil {
+		s.Sent = r.sim.sent.Load()
+	}
+	r.mu.Unlock()
+	return s
+}
+
+// countFDs counts open file descriptors where /proc is available
+func countFDs() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
+
+// record stores a sample and returns why the run failed ("" = within limits).
+// The first sample after warmup becomes the baseline.
+func (r *Runner) record(s Sample, warm bool) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rep := r.report
+	cfg := rep.cfg
+
+	rep.Latest = &s
+	if len(rep.Samples) >= maxSamples {
+		// Keep every other sample so the whole run stays covered
+		thinned := rep.Samples[:0]
+		for i := 0; i < len(rep.Samples); i += 2 {
+			thinned = append(thinned, rep.Samples[i])
+		}
+		rep.Samples = thinned
+	}
+	rep.Samples = append(rep.Samples, s)
+	rep.Peak.Goroutines = max(rep.Peak.Goroutines, s.Goroutines)
+	rep.Peak.HeapMB = max(rep.Peak.HeapMB, s.HeapMB)
+	rep.Peak.FDs = max(rep.Peak.FDs, s.FDs)
+	if rep.Peak.QueueDepths == nil {
+		rep.Peak.QueueDepths = make(map[string]int)
+	}
+	for dir, d := range s.QueueDepths {
+		rep.Peak.QueueDepths[dir] = max(rep.Peak.QueueDepths[dir], d)
+		if cfg.MaxQueueDepth > 0 && d > cfg.MaxQueueDepth {
+			return fmt.Sprintf("%s queue depth %d exceeds %d", dir, d, cfg.MaxQueueDepth)
+		}
+	}
+
+	if !warm {
+		return ""
+	}
+	if rep.Baseline == nil {
+		base := s
+		rep.Baseline = &base
+		logger.Info("[SOAK] Baseline: %d goroutines, %.1f MB heap, %d fds", s.Goroutines, s.HeapMB, s.FDs)
+		return ""
+	}
+	base := rep.Baseline
+	logger.Debug("[SOAK] %d goroutines (%+d), %.1f MB heap (%+.1f), %d fds (%+d), %d sent",
+		s.Goroutines, s.Goroutines-base.Goroutines, s.HeapMB, s.HeapMB-base.HeapMB, s.FDs, s.FDs-base.FDs, s.Sent)
+
+	if cfg.MaxGoroutineGrowth > 0 && s.Goroutines-base.Goroutines > cfg.MaxGoroutineGrowth {
+		return fmt.Sprintf("goroutines grew by %d (%d -> %d), limit %d", s.Goroutines-base.Goroutines, base.Goroutines, s.Goroutines, cfg.MaxGoroutineGrowth)
+	}
+	if cfg.MaxHeapGrowthMB > 0 && s.HeapMB-base.HeapMB > cfg.MaxHeapGrowthMB {
+		return fmt.Sprintf("heap grew by %.1f MB (%.1f -> %.1f), limit %.0f MB", s.HeapMB-base.HeapMB, base.HeapMB, s.HeapMB, cfg.MaxHeapGrowthMB)
+	}
+	if cfg.MaxFDGrowth > 0 && s.FDs >= 0 && base.FDs >= 0 && s.FDs-base.FDs > cfg.MaxFDGrowth {
+		return fmt.Sprintf("file descriptors grew by %d (%d -> %d), limit %d", s.FDs-base.FDs, base.FDs, s.FDs, cfg.MaxFDGrowth)
+	}
+	return ""
+}
+
+// finish marks the run complete and writes the report (and a goroutine dump on failure)
+func (r *Runner) finish(failure string) {
+	now := time.Now()
+	r.mu.Lock()
+	rep := r.report
+	rep.Running = false
+	rep.Finished = &now
+	rep.Passed = failure == ""
+	rep.Failure = failure
+	cfg := rep.cfg
+	r.mu.Unlock()
+
+	if failure != "" && cfg.ReportFile != "" {
+		dumpFile := strings.TrimSuffix(cfg.ReportFile, ".json") + ".goroutines.txt"
+		if err := writeGoroutineDump(dumpFile); err != nil {
+			logger.Warn("[SOAK] Failed to write goroutine dump: %v", err)
+		} else {
+			r.mu.Lock()
+			rep.GoroutineDump = dumpFile
+			r.mu.Unlock()
+		}
+	}
+	if cfg.ReportFile != "" {
+		data, _ := json.MarshalIndent(r.Snapshot(), "", "  ")
+		if err := os.WriteFile(cfg.ReportFile, data, 0644); err != nil {
+			logger.Warn("[SOAK] Failed to write report: %v", err)
+		}
+	}
+
+	if failure == "" {
+		logger.Info("[SOAK] ✅ Passed after %v (report: %s)", now.Sub(rep.Started).Round(time.Second), cfg.ReportFile)
+		metrics.Global.AddLog("INFO", "Soak test passed")
+	} else {
+		logger.Error("[SOAK] ❌ Failed after %v: %s (report: %s)", now.Sub(rep.Started).Round(time.Second), failure, cfg.ReportFile)
+		metrics.Global.AddLog("ERROR", "Soak test failed: "+failure)
+	}
+}
+
+// writeGoroutineDump writes all goroutine stacks grouped by identical stacks,
+// the quickest way to spot what is leaking
+func writeGoroutineDump(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return pprof.Lookup("goroutine").WriteTo(f, 1)
+}
+
+// Active reports whether a soak run was started
+func (r *Runner) Active() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.report != nil
+}
+
+// Snapshot returns the current report
+func (r *Runner) Snapshot() Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.report == nil {
+		return Report{}
+	}
+	rep := *r.report
+	rep.Samples = append([]Sample(nil), rep.Samples...)
+	peak := make(map[string]int, len(rep.Peak.QueueDepths))
+	for dir, d := range rep.Peak.QueueDepths {
+		peak[dir] = d
+	}
+	rep.Peak.QueueDepths = peak
+	return rep
+}
@@ -0,0 +1,339 @@
+package startup
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"DroneBridge/config"
+	"DroneBridge/internal/auth"
+	"DroneBridge/internal/camera"
+	"DroneBridge/internal/identity"
+	"DroneBridge/internal/logger"
+)
+
+// redactedKeys are config keys whose values are replaced in the report
+var redactedKeys = map[string]bool{
+	"shared_secret": true,
+	"password":      true,
+	"key":           true,
+	"api_token":     true,
+	"admin_token":   true,
+	"secret_key":    true,
+}
+
+// Facts are startup results that are not part of the configuration
+type Facts struct {
+	ConfigFile     string
+	ContainerMode  bool
+	PixhawkAddress string // Empty when discovery failed
+	PixhawkSysID   uint8
+}
+
+// Interface is a network interface seen at startup
+type Interface struct {
+	Name       string   `json:"name"`
+	Up         bool     `json:"up"`
+	Addresses  []string `json:"addresses"`
+	Configured bool     `json:"configured"` // Matches ethernet.interface
+}
+
+// Endpoint is an address the bridge listens on or connects to
+type Endpoint struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+// Secret reports whether a credential is configured, never its value
+type Secret struct {
+	Name    string `json:"name"`
+	Present bool   `json:"present"`
+}
+
+// Report is the structured startup summary served at /api/startup-report
+type Report struct {
+	Generated     time.Time              `json:"generated"`
+	ConfigFile    string                 `json:"configFile"`
+	ContainerMode bool                   `json:"containerMode"`
+	Drone         string                 `json:"drone"`
+	UUID          string                 `json:"uuid"`
+	LogLevel      string                 `json:"logLevel"`
+	Features      map[string]bool        `json:"features"`
+	Endpoints     []Endpoint             `json:"endpoints"`
+	Interfaces    []Interface            `json:"interfaces"`
+	Secrets       []Secret               `json:"secrets"`
+	VideoDevices  []string               `json:"videoDevices"`
+	Cameras       []camera.CameraStatus  `json:"cameras"`
+	Warnings      []string               `json:"warnings"`
+	Config        map[string]interface{} `json:"config"` // Effective configuration, secrets redacted
+}
+
+var (
+	mu      sync.RWMutex
+	current *Report
+)
+
+// Current returns the last published report (nil before startup completes)
+func Current() *Report {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Build collects the startup report from the effective configuration
+func Build(cfg *config.Config, facts Facts) *Report {
+	r := &Report{
+		Generated:     time.Now(),
+		ConfigFile:    facts.ConfigFile,
+		ContainerMode: facts.ContainerMode,
+		Drone:         identity.Global.DisplayName(),
+		UUID:          cfg.Auth.UUID,
+		LogLevel:      logger.GetLevelString(),
+		Features: map[string]bool{
+			"camera":  cfg.Features.Camera,
+			"video":   cfg.Features.Video,
+			"web":     cfg.Features.Web,
+			"auth":    cfg.Features.Auth,
+			"landing": cfg.Features.Landing,
+		},
+		Cameras:  camera.StatusAll(),
+		Warnings: []string{},
+	}
+	r.Config = redactedConfig(cfg)
+	r.Interfaces = interfaces(cfg.Ethernet.Interface)
+	r.VideoDevices, _ = filepath.Glob("/dev/video*")
+	if r.VideoDevices == nil {
+		r.VideoDevices = []string{}
+	}
+
+	pixhawk := facts.PixhawkAddress
+	if pixhawk == "" && cfg.Serial.Enabled {
+		pixhawk = "no heartbeat on the serial link"
+	} else if pixhawk == "" {
+		pixhawk = "not discovered (broadcast fallback)"
+	} else {
+		pixhawk = fmt.Sprintf("%s (System ID %d)", pixhawk, facts.PixhawkSysID)
+	}
+	fcLink := Endpoint{"mavlink_listen", fmt.Sprintf("0.0.0.0:%d", cfg.Network.LocalListenPort)}
+	if cfg.Serial.Enabled {
+		fcLink = Endpoint{"fc_serial", fmt.Sprintf("%s @ %d baud (flow control %v)", cfg.Serial.Device, cfg.Serial.Baud, cfg.Serial.FlowControl)}
+	}
+	r.Endpoints = []Endpoint{
+		fcLink,
+		{"pixhawk", pixhawk},
+		{"router_mavlink", cfg.GetAddress()},
+	}
+	if cfg.Auth.Enabled {
+		r.Endpoints = append(r.Endpoints, Endpoint{"router_auth", fmt.Sprintf("%s:%d (%s)", cfg.Auth.Host, cfg.Auth.Port, cfg.Auth.Mode)})
+	}
+	if cfg.Features.Web {
+		r.Endpoints = append(r.Endpoints, Endpoint{"web", fmt.Sprintf("0.0.0.0:%d", cfg.Web.Port)})
+	}
+	if cfg.Features.Video {
+		r.Endpoints = append(r.Endpoints, Endpoint{"mediamtx", fmt.Sprintf("%s:%d", cfg.Camera.MediaMTX.Host, cfg.Camera.MediaMTX.Port)})
+	}
+	if cfg.Mesh.Enabled {
+		broadcast := cfg.Mesh.BroadcastIP
+		if broadcast == "" {
+			broadcast = "255.255.255.255"
+		}
+		r.Endpoints = append(r.Endpoints, Endpoint{"mesh", fmt.Sprintf("%s:%d", broadcast, cfg.Mesh.Port)})
+	}
+	if cfg.Upload.Enabled {
+		r.Endpoints = append(r.Endpoints, Endpoint{"upload", fmt.Sprintf("%s/%s", cfg.Upload.Endpoint, cfg.Upload.Bucket)})
+	}
+
+	secretFile := auth.SecretExists(cfg.Auth.UUID)
+	r.Secrets = []Secret{
+		{"drone_secret_file", secretFile},
+		{"auth.shared_secret", cfg.Auth.SharedSecret != ""},
+		{"control.api_token", cfg.Control.APIToken != ""},
+		{"camera.mediamtx.password", cfg.Camera.MediaMTX.Password != ""},
+	}
+	if cfg.Auth.Mode == "mtls" {
+		r.Secrets = append(r.Secrets,
+			Secret{"auth.tls.cert_file", fileExists(cfg.Auth.TLS.CertFile)},
+			Secret{"auth.tls.key_file", fileExists(cfg.Auth.TLS.KeyFile)})
+	}
+	if cfg.Mesh.Enabled {
+		r.Secrets = append(r.Secrets, Secret{"mesh.key", cfg.Mesh.Key != ""})
+	}
+	if cfg.Upload.Enabled {
+		r.Secrets = append(r.Secrets, Secret{"upload.secret_key", cfg.Upload.SecretKey != ""})
+	}
+
+	// Sanity checks: valid but probably unintended settings
+	warn := func(format string, v ...interface{}) { r.Warnings = append(r.Warnings, fmt.Sprintf(format, v...)) }
+	if !cfg.Auth.Enabled {
+		warn("Authentication is disabled - telemetry is forwarded without a session")
+	} else if cfg.Auth.Mode != "mtls" && !secretFile && cfg.Auth.SharedSecret == "" {
+		warn("No drone secret found - run with --register once")
+	}
+	if facts.PixhawkAddress == "" {
+		warn("Pixhawk was not discovered at startup (allow_missing_pixhawk)")
+	}
+	if cfg.Ethernet.Interface != "" {
+		found := false
+		for _, iface := range r.Interfaces {
+			found = found || iface.Configured
+		}
+		if !found {
+			warn("Configured ethernet.interface %q does not exist", cfg.Ethernet.Interface)
+		}
+	}
+	if cfg.Features.Camera && cfg.Camera.Enabled && len(r.VideoDevices) == 0 && len(r.Cameras) == 0 {
+		warn("Camera is enabled but no video devices were found")
+	}
+	if cfg.Features.Video && cfg.Camera.MediaMTX.Host == "" {
+		warn("Video is enabled but camera.mediamtx.host is empty")
+	}
+	if _, err := net.LookupHost(cfg.Network.TargetHost); err != nil {
+		warn("Router host %q does not resolve: %v", cfg.Network.TargetHost, err)
+	}
+	if logger.GetLevel() == logger.DEBUG {
+		warn("Debug logging is enabled")
+	}
+	for _, s := range r.Secrets {
+		if strings.HasPrefix(s.Name, "auth.tls.") && !s.Present {
+			warn("%s does not exist", s.Name)
+		}
+	}
+	return r
+}
+
+// Publish stores the report for the web API and prints it as the startup banner
+func Publish(r *Report) {
+	mu.Lock()
+	current = r
+	mu.Unlock()
+
+	logger.Info("[STARTUP] ================ Startup report ================")
+	logger.Info("[STARTUP] Drone:      %s (%s)", r.Drone, r.UUID)
+	mode := ""
+	if r.ContainerMode {
+		mode = " (container mode)"
+	}
+	logger.Info("[STARTUP] Config:     %s%s, log level %s", r.ConfigFile, mode, r.LogLevel)
+
+	names := make([]string, 0, len(r.Features))
+	for name := range r.Features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	features := make([]string, 0, len(names))
+	for _, name := range names {
+		state := "off"
+		if r.Features[name] {
+			state = "on"
+		}
+		features = append(features, name+"="+state)
+	}
+	logger.Info("[STARTUP] Features:   %s", strings.Join(features, " "))
+
+	for _, e := range r.Endpoints {
+		logger.Info("[STARTUP] Endpoint:   %-15s %s", e.Name, e.Address)
+	}
+	for _, iface := range r.Interfaces {
+		state := "down"
+		if iface.Up {
+			state = "up"
+		}
+		marker := ""
+		if iface.Configured {
+			marker = " (ethernet.interface)"
+		}
+		logger.Info("[STARTUP] Interface:  %-15s %s %s%s", iface.Name, state, strings.Join(iface.Addresses, ", "), marker)
+	}
+	for _, s := range r.Secrets {
+		state := "missing"
+		if s.Present {
+			state = "present"
+		}
+		logger.Info("[STARTUP] Secret:     %-24s %s", s.Name, state)
+	}
+	devices := strings.Join(r.VideoDevices, ", ")
+	if devices == "" {
+		devices = "none"
+	}
+	logger.Info("[STARTUP] Video:      %s, %d camera(s) loaded", devices, len(r.Cameras))
+	for _, w := range r.Warnings {
+		logger.Warn("[STARTUP] ⚠️  %s", w)
+	}
+	if len(r.Warnings) == 0 {
+		logger.Info("[STARTUP] No warnings")
+	}
+	logger.Info("[STARTUP] ================================================")
+}
+
+// interfaces lists network interfaces with their addresses (loopback excluded)
+func interfaces(configured string) []Interface {
+	out := []Interface{}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return out
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		entry := Interface{
+			Name:       iface.Name,
+			Up:         iface.Flags&net.FlagUp != 0,
+			Addresses:  []string{},
+			Configured: iface.Name == configured,
+		}
+		if addrs, err := iface.Addrs(); err == nil {
+			for _, a := range addrs {
+				entry.Addresses = append(entry.Addresses, a.String())
+			}
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// redactedConfig converts the config to a generic map and hides secret values
+func redactedConfig(cfg *config.Config) map[string]interface{} {
+	out := map[string]interface{}{}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return out
+	}
+	if err := yaml.Unmarshal(data, &out); err != nil {
+		return map[string]interface{}{}
+	}
+	redact(out)
+	return out
+}
+
+func redact(v interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if s, ok := val.(string); ok && redactedKeys[k] && s != "" {
+				t[k] = "***"
+				continue
+			}
+			redact(val)
+		}
+	case []interface{}:
+		for _, val := range t {
+			redact(val)
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// Class is a category of prunable files (tlogs, video segments, journals, ...)
+type Class struct {
+	Name     string        // Display name
+	Dir      string        // Directory holding the files (relative to DataDir unless absolute)
+	Pattern  string        // Glob matched against file names (e.g. "*.tlog")
+	Priority int           // Lower priority classes are pruned first when space is low
+	MaxAge   time.Duration // Files older than this are always removed (0 = keep until space is needed)
+}
+
+// Config controls the storage manager
+type Config struct {
+	DataDir    string        // Data partition to monitor
+	MinFree    uint64        // Below this many free bytes new recordings are refused
+	TargetFree uint64        // Pruning continues until this many bytes are free
+	Interval   time.Duration // Check interval
+	Classes    []Class
+
+	UploadStateFile string // Per-artifact upload state (default: <DataDir>/.upload_state.json)
+}
+
+// ClassStatus is the current usage of one class
+type ClassStatus struct {
+	Name     string `json:"name"`
+	Dir      string `json:"dir"`
+	Priority int    `json:"priority"`
+	Files    int    `json:"files"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// Status is a snapshot of the storage manager
+type Status struct {
+	DataDir      string         `json:"dataDir"`
+	FreeBytes    uint64         `json:"freeBytes"`
+	MinFreeBytes uint64         `json:"minFreeBytes"`
+	LowSpace     bool           `json:"lowSpace"`
+	LastCheck    time.Time      `json:"lastCheck"`
+	PrunedFiles  int64          `json:"prunedFiles"`
+	PrunedBytes  int64          `json:"prunedBytes"`
+	Classes      []ClassStatus  `json:"classes"`
+	Uploads      map[string]int `json:"uploads,omitempty"` // Tracked artifacts per upload state
+	LastError    string         `json:"lastError,omitempty"`
+}
+
+// Manager monitors free space on the data partition and prunes old files
+type Manager struct {
+	mu      sync.RWMutex
+	cfg     Config
+	status  Status
+	uploads map[string]*UploadState // Keyed by file path
+}
+
+type candidate struct {
+	path    string
+	size    int64
+	modTime time.Time
+	class   int // Index into Config.Classes
+}
+
+// Global is the process-wide storage manager
+var Global = New(Config{DataDir: ".", Interval: time.Minute})
+
+// New creates a storage manager
+func New(cfg Config) *Manager {
+	m := &Manager{}
+	m.Configure(cfg)
+	return m
+}
+
+// Configure replaces the storage configuration
+func (m *Manager) Configure(cfg Config) {
+	if cfg.DataDir == "" {
+		cfg.DataDir = "."
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.TargetFree < cfg.MinFree {
+		cfg.TargetFree = cfg.MinFree
+	}
+	if cfg.UploadStateFile == "" {
+		cfg.UploadStateFile = defaultUploadStateFile(cfg.DataDir)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.uploads == nil || cfg.UploadStateFile != m.cfg.UploadStateFile {
+		m.uploads = loadUploadStates(cfg.UploadStateFile)
+	}
+	m.cfg = cfg
+	m.status.DataDir = cfg.DataDir
+	m.status.MinFreeBytes = cfg.MinFree
+}
+
+// Run checks storage every interval until stopCh is closed
+func (m *Manager) Run(stopCh <-chan struct{}) {
+	m.Check()
+
+	m.mu.RLock()
+	interval := m.cfg.Interval
+	m.mu.RUnlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// Check applies retention, prunes by priority while free space is below target
+// and raises an alert when free space drops below the recording threshold
+func (m *Manager) Check() {
+	m.mu.RLock()
+	cfg := m.cfg
+	m.mu.RUnlock()
+
+	now := time.Now()
+	files, classes := scan(cfg)
+
+	var prunedFiles, prunedBytes int64
+	remove := func(c candidate) {
+		if err := os.Remove(c.path); err != nil {
+			logger.Warn("[STORAGE] Failed to remove %s: %v", c.path, err)
+			return
+		}
+		m.forgetUpload(c.path)
+		prunedFiles++
+		prunedBytes += c.size
+		logger.Info("[STORAGE] 🗑️ Pruned %s (%d bytes)", c.path, c.size)
+	}
+
+	// 1. Retention - always drop expired files
+	kept := files[:0]
+	for _, c := range files {
+		if maxAge := cfg.Classes[c.class].MaxAge; maxAge > 0 && now.Sub(c.modTime) > maxAge {
+			remove(c)
+			continue
+		}
+		kept = append(kept, c)
+	}
+	files = kept
+
+	// 2. Free space - prune lowest priority, then already uploaded, then oldest,
+	// until the target is reached
+	free, err := freeBytes(cfg.DataDir)
+	if err == nil && free < cfg.TargetFree {
+		uploaded := make(map[string]bool, len(files))
+		for _, c := range files {
+			uploaded[c.path] = m.uploaded(c.path)
+		}
+		sort.Slice(files, func(i, j int) bool {
+			pi, pj := cfg.Classes[files[i].class].Priority, cfg.Classes[files[j].class].Priority
+			if pi != pj {
+				return pi < pj
+			}
+			if ui, uj := uploaded[files[i].path], uploaded[files[j].path]; ui != uj {
+				return ui
+			}
+			return files[i].modTime.Before(files[j].modTime)
+		})
+		for _, c := range files {
+			if free >= cfg.TargetFree {
+				break
+			}
+			remove(c)
+			if free, err = freeBytes(cfg.DataDir); err != nil {
+				break
+			}
+		}
+		// Rescan so class usage reflects what's left
+		_, classes = scan(cfg)
+	}
+
+	m.mu.Lock()
+	wasLow := m.status.LowSpace
+	m.status.LastCheck = now
+	m.status.Classes = classes
+	m.status.PrunedFiles += prunedFiles
+	m.status.PrunedBytes += prunedBytes
+	if err != nil {
+		m.status.LastError = err.Error()
+	} else {
+		m.status.LastError = ""
+		m.status.FreeBytes = free
+		m.status.LowSpace = cfg.MinFree > 0 && free < cfg.MinFree
+	}
+	low := m.status.LowSpace
+	m.mu.Unlock()
+
+	if prunedFiles > 0 {
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Storage: pruned %d files (%d bytes)", prunedFiles, prunedBytes))
+	}
+	if low && !wasLow {
+		alerts.Raise("storage", alerts.SeverityCritical, fmt.Sprintf(
+			"Low disk space on %s: %d MB free (minimum %d MB) - new recordings refused",
+			cfg.DataDir, free>>20, cfg.MinFree>>20))
+	} else if !low && wasLow {
+		alerts.Raise("storage", alerts.SeverityInfo, fmt.Sprintf("Disk space recovered on %s: %d MB free", cfg.DataDir, free>>20))
+	}
+}
+
+// scan lists the files of every class
+func scan(cfg Config) ([]candidate, []ClassStatus) {
+	var files []candidate
+	classes := make([]ClassStatus, 0, len(cfg.Classes))
+	for i, cl := range cfg.Classes {
+		dir := cl.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(cfg.DataDir, dir)
+		}
+		pattern := cl.Pattern
+		if pattern == "" {
+			pattern = "*"
+		}
+		cs := ClassStatus{Name: cl.Name, Dir: dir, Priority: cl.Priority}
+
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, p := range matches {
+			info, err := os.Stat(p)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			cs.Files++
+			cs.Bytes += info.Size()
+			files = append(files, candidate{path: p, size: info.Size(), modTime: info.ModTime(), class: i})
+		}
+		classes = append(classes, cs)
+	}
+	return files, classes
+}
+
+// AllowRecording returns an error when free space is below the minimum.
+// Recorders must call this before opening a new file.
+func (m *Manager) AllowRecording() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.status.LowSpace {
+		return fmt.Errorf("recording refused: %d MB free on %s (minimum %d MB)",
+			m.status.FreeBytes>>20, m.cfg.DataDir, m.cfg.MinFree>>20)
+	}
+	return nil
+}
+
+// Snapshot returns the current storage status
+func (m *Manager) Snapshot() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s := m.status
+	s.Classes = append([]ClassStatus(nil), m.status.Classes...)
+	s.Uploads = m.uploadCountsLocked()
+	return s
+}
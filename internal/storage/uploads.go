@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"DroneBridge/internal/logger"
+)
+
+// Upload states of an artifact
+const (
+	UploadPending  = "pending"  // Not uploaded yet (or will be retried)
+	UploadDone     = "uploaded" // Stored on the remote endpoint
+	UploadFailed   = "failed"   // Gave up after repeated errors
+	uploadStateTmp = ".tmp"
+)
+
+// UploadState is the upload progress of one artifact
+type UploadState struct {
+	State       string     `json:"state"`
+	Attempts    int        `json:"attempts,omitempty"`
+	Key         string     `json:"key,omitempty"` // Remote object key once uploaded
+	LastAttempt *time.Time `json:"lastAttempt,omitempty"`
+	UploadedAt  *time.Time `json:"uploadedAt,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+}
+
+// Artifact is a completed file of a storage class
+type Artifact struct {
+	Path    string      `json:"path"`
+	Class   string      `json:"class"`
+	Size    int64       `json:"size"`
+	ModTime time.Time   `json:"modTime"`
+	Upload  UploadState `json:"upload"`
+}
+
+// loadUploadStates reads the persisted upload states (missing file = none)
+func loadUploadStates(path string) map[string]*UploadState {
+	states := make(map[string]*UploadState)
+	if path == "" {
+		return states
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("[STORAGE] Failed to read upload state %s: %v", path, err)
+		}
+		return states
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		logger.Warn("[STORAGE] Ignoring corrupt upload state %s: %v", path, err)
+		return make(map[string]*UploadState)
+	}
+	return states
+}
+
+// saveUploadsLocked persists the upload states atomically (caller holds m.mu)
+func (m *Manager) saveUploadsLocked() {
+	path := m.cfg.UploadStateFile
+	if path == "" {
+		return
+	}
+	data, err := json.MarshalIndent(m.uploads, "", "  ")
+	if err != nil {
+		logger.Warn("[STORAGE] Failed to encode upload state: %v", err)
+		return
+	}
+	if err := os.WriteFile(path+uploadStateTmp, data, 0644); err != nil {
+		logger.Warn("[STORAGE] Failed to write upload state: %v", err)
+		return
+	}
+	if err := os.Rename(path+uploadStateTmp, path); err != nil {
+		logger.Warn("[STORAGE] Failed to write upload state: %v", err)
+	}
+}
+
+// forgetUpload drops the state of a removed file
+func (m *Manager) forgetUpload(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.uploads[path]; ok {
+		delete(m.uploads, path)
+		m.saveUploadsLocked()
+	}
+}
+
+// uploaded reports whether a file has been uploaded
+func (m *Manager) uploaded(path string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	st, ok := m.uploads[path]
+	return ok && st.State == UploadDone
+}
+
+// Artifacts returns the files of the named classes (all classes if none are
+// given) that have not been modified for settle, oldest first, with their
+// upload state. Files still being written are left out.
+func (m *Manager) Artifacts(classes []string, settle time.Duration) []Artifact {
+	m.mu.RLock()
+	cfg := m.cfg
+	m.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(classes))
+	for _, c := range classes {
+		wanted[c] = true
+	}
+
+	files, _ := scan(cfg)
+	now := time.Now()
+	out := make([]Artifact, 0, len(files))
+	m.mu.RLock()
+	for _, f := range files {
+		class := cfg.Classes[f.class].Name
+		if len(wanted) > 0 && !wanted[class] {
+			continue
+		}
+		if now.Sub(f.modTime) < settle {
+			continue
+		}
+		a := Artifact{Path: f.path, Class: class, Size: f.size, ModTime: f.modTime, Upload: UploadState{State: UploadPending}}
+		if st, ok := m.uploads[f.path]; ok {
+			a.Upload = *st
+		}
+		out = append(out, a)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ModTime.Before(out[j].ModTime) })
+	return out
+}
+
+// SetUploadState records the upload progress of a file and persists it
+func (m *Manager) SetUploadState(path string, st UploadState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploads[path] = &st
+	m.saveUploadsLocked()
+}
+
+// uploadCountsLocked counts tracked artifacts per upload state (caller holds m.mu)
+func (m *Manager) uploadCountsLocked() map[string]int {
+	if len(m.uploads) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, st := range m.uploads {
+		counts[st.State]++
+	}
+	return counts
+}
+
+// defaultUploadStateFile places the upload state next to the data it describes
+func defaultUploadStateFile(dataDir string) string {
+	return filepath.Join(dataDir, ".upload_state.json")
+}
@@ -0,0 +1,177 @@
+// Package telemetry keeps the most recent decoded instance of every message
+// type received from the FC, so dashboard widgets can read any value through
+// /api/telemetry/latest without a dedicated handler.
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+)
+
+// TypeInfo describes one cached message type
+type TypeInfo struct {
+	Message  string    `json:"message"` // MAVLink name, e.g. GLOBAL_POSITION_INT
+	ID       uint32    `json:"id"`
+	Count    uint64    `json:"count"` // Instances received since startup
+	Received time.Time `json:"received"`
+}
+
+// Snapshot is the latest instance of a message type as a field map
+type Snapshot struct {
+	TypeInfo
+	SystemID    uint8                  `json:"system_id"`
+	ComponentID uint8                  `json:"component_id"`
+	Fields      map[string]interface{} `json:"fields"` // MAVLink field names
+}
+
+type entry struct {
+	msg         message.Message
+	name        string
+	systemID    uint8
+	componentID uint8
+	received    time.Time
+	count       uint64
+}
+
+// Hub caches the latest message of each type, whichever component sent it
+type Hub struct {
+	mu     sync.RWMutex
+	latest map[uint32]*entry
+}
+
+// Global is the process-wide telemetry hub
+var Global = &Hub{latest: make(map[uint32]*entry)}
+
+// Observe caches a message from the FC. Unknown (raw) messages are skipped.
+func (h *Hub) Observe(systemID, componentID uint8, msg message.Message) {
+	if _, ok := msg.(*message.MessageRaw); ok {
+		return
+	}
+	// Copy, so later in-place changes by the forwarding path don't leak into the cache
+	cp := reflect.New(reflect.TypeOf(msg).Elem())
+	cp.Elem().Set(reflect.ValueOf(msg).Elem())
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e := h.latest[msg.GetID()]
+	if e == nil {
+		e = &entry{name: messageName(msg)}
+		h.latest[msg.GetID()] = e
+	}
+	e.msg = cp.Interface().(message.Message)
+	e.systemID = systemID
+	e.componentID = componentID
+	e.received = time.Now()
+	e.count++
+}
+
+// Types lists the cached message types by name
+func (h *Hub) Types() []TypeInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	out := make([]TypeInfo, 0, len(h.latest))
+	for id, e := range h.latest {
+		out = append(out, TypeInfo{Message: e.name, ID: id, Count: e.count, Received: e.received})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Message < out[j].Message })
+	return out
+}
+
+// Latest returns the most recent instance of a message type, looked up by
+// MAVLink name (case and underscores ignored) or numeric ID
+func (h *Hub) Latest(name string) (Snapshot, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var e *entry
+	var id uint32
+	if n, err := strconv.ParseUint(name, 10, 32); err == nil {
+		id, e = uint32(n), h.latest[uint32(n)]
+	} else {
+		key := nameKey(name)
+		for i, c := range h.latest {
+			if nameKey(c.name) == key {
+				id, e = i, c
+				break
+			}
+		}
+	}
+	if e == nil {
+		return Snapshot{}, false
+	}
+	return Snapshot{
+		TypeInfo:    TypeInfo{Message: e.name, ID: id, Count: e.count, Received: e.received},
+		SystemID:    e.systemID,
+		ComponentID: e.componentID,
+		Fields:      fields(e.msg),
+	}, true
+}
+
+var upper = regexp.MustCompile("([A-Z])")
+
+// messageName converts a dialect type (*common.MessageGlobalPositionInt) to its
+// MAVLink name (GLOBAL_POSITION_INT), the same way gomavlib does
+func messageName(msg message.Message) string {
+	name := strings.TrimPrefix(reflect.TypeOf(msg).Elem().Name(), "Message")
+	return strings.ToUpper(upper.ReplaceAllString(name, "_${1}")[1:])
+}
+
+// nameKey normalises a message name for lookups
+func nameKey(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "_", ""))
+}
+
+// fields maps a message to MAVLink field names. NaN and infinite floats
+// (common for "unknown" values) become null so the map encodes as JSON.
+func fields(msg message.Message) map[string]interface{} {
+	v := reflect.ValueOf(msg).Elem()
+	t := v.Type()
+	out := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("mavname")
+		if name == "" {
+			name = strings.ToLower(upper.ReplaceAllString(f.Name, "_${1}")[1:])
+		}
+		out[name] = value(v.Field(i))
+	}
+	return out
+}
+
+func value(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil
+		}
+		if v.Kind() == reflect.Float32 {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 32)) // Shortest float32 form, not 0.10000000149
+		}
+		return f
+	case reflect.Array:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = value(v.Index(i))
+		}
+		return out
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() // Enum types encode as their number
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.String:
+		return v.String()
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,94 @@
+package testutil
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/dialer"
+)
+
+// FakeDialer is an in-memory dialer.Dialer. Each successful dial creates a net.Pipe;
+// the client end is returned to the caller and the server end is delivered on Accepted.
+type FakeDialer struct {
+	mu     sync.Mutex
+	err    error
+	dials  []string
+	accept chan net.Conn
+	local  net.Addr
+}
+
+// NewFakeDialer creates a fake dialer whose connections report localIP as their local address
+func NewFakeDialer(localIP string) *FakeDialer {
+	return &FakeDialer{
+		accept: make(chan net.Conn, 16),
+		local:  &net.UDPAddr{IP: net.ParseIP(localIP)},
+	}
+}
+
+var _ dialer.Dialer = (*FakeDialer)(nil)
+
+// Dial implements dialer.Dialer
+func (d *FakeDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialTimeout(network, address, 0)
+}
+
+// DialTimeout implements dialer.Dialer
+func (d *FakeDialer) DialTimeout(network, address string, _ time.Duration) (net.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.dials = append(d.dials, network+"://"+address)
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	client, server := net.Pipe()
+	local := d.local
+	if network == "tcp" {
+		local = &net.TCPAddr{IP: d.local.(*net.UDPAddr).IP}
+	}
+	select {
+	case d.accept <- server:
+	default:
+		client.Close()
+		server.Close()
+		return nil, fmt.Errorf("fake dialer: too many unaccepted connections")
+	}
+	return &fakeConn{Conn: client, local: local}, nil
+}
+
+// Accepted returns the server ends of dialed connections
+func (d *FakeDialer) Accepted() <-chan net.Conn {
+	return d.accept
+}
+
+// FailWith makes subsequent dials fail with err (nil restores success)
+func (d *FakeDialer) FailWith(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.err = err
+}
+
+// SetLocalIP changes the local address reported by new connections (simulates an IP change)
+func (d *FakeDialer) SetLocalIP(ip string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.local = &net.UDPAddr{IP: net.ParseIP(ip)}
+}
+
+// Dials returns the addresses dialed so far as "network://address"
+func (d *FakeDialer) Dials() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.dials...)
+}
+
+// fakeConn overrides LocalAddr so callers that inspect the local IP see the fake one
+type fakeConn struct {
+	net.Conn
+	local net.Addr
+}
+
+func (c *fakeConn) LocalAddr() net.Addr { return c.local }
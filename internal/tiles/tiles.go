@@ -0,0 +1,326 @@
+package tiles
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/logger"
+)
+
+// Tile sources reported with a served tile
+const (
+	SourceCache    = "cache"    // Fresh tile from disk
+	SourceUpstream = "upstream" // Fetched now
+	SourceStale    = "stale"    // Expired tile served because upstream is unreachable
+)
+
+const (
+	maxTileZoom = 19
+	// seedDelay keeps pre-seeding within tile server usage policies (OSM: no bulk downloads)
+	seedDelay = 500 * time.Millisecond
+	// maxTileBytes bounds one upstream response
+	maxTileBytes = 1 << 20
+)
+
+// Area is a lat/lon bounding box
+type Area struct {
+	MinLat float64 `json:"minLat"`
+	MinLon float64 `json:"minLon"`
+	MaxLat float64 `json:"maxLat"`
+	MaxLon float64 `json:"maxLon"`
+}
+
+// Config controls the tile cache
+type Config struct {
+	Dir       string        // Cache directory (<dir>/<z>/<x>/<y>.png)
+	Upstream  string        // URL template with {z}, {x}, {y}
+	MaxAge    time.Duration // Tiles older than this are refreshed when upstream is reachable (0 = never)
+	MaxSeed   int           // Maximum tiles one seed run may fetch
+	UserAgent string
+}
+
+// SeedStatus is the progress of the current or last pre-seed
+type SeedStatus struct {
+	Running  bool       `json:"running"`
+	Area     *Area      `json:"area,omitempty"`
+	MinZoom  int        `json:"minZoom"`
+	MaxZoom  int        `json:"maxZoom"`
+	Total    int        `json:"total"`
+	Done     int        `json:"done"`
+	Fetched  int        `json:"fetched"`
+	Failed   int        `json:"failed"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// Status is a snapshot of the tile cache
+type Status struct {
+	Enabled  bool       `json:"enabled"`
+	Upstream string     `json:"upstream,omitempty"`
+	Hits     int64      `json:"hits"`
+	Fetches  int64      `json:"fetches"`
+	Stale    int64      `json:"stale"`
+	Misses   int64      `json:"misses"` // Not cached and upstream unreachable
+	Seed     SeedStatus `json:"seed"`
+}
+
+// Cache serves map tiles from disk and fills it from an upstream tile server
+type Cache struct {
+	mu      sync.Mutex
+	cfg     Config
+	client  *http.Client
+	status  Status
+	seedCxl context.CancelFunc
+}
+
+// Global is the process-wide tile cache (disabled until Configure is called)
+var Global = &Cache{}
+
+// Configure enables the cache
+func (c *Cache) Configure(cfg Config) error {
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create tile directory: %w", err)
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = "DroneBridge-tile-cache/1.0"
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cfg = cfg
+	c.client = &http.Client{Timeout: 10 * time.Second}
+	c.status.Enabled = true
+	c.status.Upstream = cfg.Upstream
+	return nil
+}
+
+// Enabled reports whether the cache is configured
+func (c *Cache) Enabled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status.Enabled
+}
+
+// ValidTile checks tile coordinates
+func ValidTile(z, x, y int) error {
+	if z < 0 || z > maxTileZoom {
+		return fmt.Errorf("zoom must be 0-%d", maxTileZoom)
+	}
+	n := 1 << uint(z)
+	if x < 0 || x >= n || y < 0 || y >= n {
+		return fmt.Errorf("tile %d/%d/%d out of range", z, x, y)
+	}
+	return nil
+}
+
+func (c *Cache) path(z, x, y int) string {
+	return filepath.Join(c.cfg.Dir, strconv.Itoa(z), strconv.Itoa(x), strconv.Itoa(y)+".png")
+}
+
+// Get returns a tile, from disk when fresh, else from upstream (stored for
+// later), else the expired copy
+func (c *Cache) Get(z, x, y int) ([]byte, string, error) {
+	if err := ValidTile(z, x, y); err != nil {
+		return nil, "", err
+	}
+	c.mu.Lock()
+	cfg := c.cfg
+	c.mu.Unlock()
+
+	path := c.path(z, x, y)
+	info, statErr := os.Stat(path)
+	if statErr == nil && (cfg.MaxAge <= 0 || time.Since(info.ModTime()) < cfg.MaxAge) {
+		if data, err := os.ReadFile(path); err == nil {
+			c.count(func(s *Status) { s.Hits++ })
+			return data, SourceCache, nil
+		}
+	}
+
+	data, err := c.fetch(z, x, y)
+	if err == nil {
+		c.count(func(s *Status) { s.Fetches++ })
+		return data, SourceUpstream, nil
+	}
+	if statErr == nil {
+		if stale, rerr := os.ReadFile(path); rerr == nil {
+			c.count(func(s *Status) { s.Stale++ })
+			return stale, SourceStale, nil
+		}
+	}
+	c.count(func(s *Status) { s.Misses++ })
+	return nil, "", err
+}
+
+func (c *Cache) count(fn func(*Status)) {
+	c.mu.Lock()
+	fn(&c.status)
+	c.mu.Unlock()
+}
+
+// fetch downloads a tile from upstream and stores it
+func (c *Cache) fetch(z, x, y int) ([]byte, error) {
+	c.mu.Lock()
+	cfg, client := c.cfg, c.client
+	c.mu.Unlock()
+	if cfg.Upstream == "" {
+		return nil, fmt.Errorf("tile not cached and no upstream configured")
+	}
+
+	url := strings.NewReplacer("{z}", strconv.Itoa(z), "{x}", strconv.Itoa(x), "{y}", strconv.Itoa(y)).Replace(cfg.Upstream)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", cfg.UserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("upstream unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("upstream returned HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTileBytes))
+	if err != nil {
+		return nil, fmt.Errorf("upstream read failed: %w", err)
+	}
+
+	path := c.path(z, x, y)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0644); err == nil {
+			os.Rename(tmp, path)
+		}
+	}
+	return data, nil
+}
+
+// TileXY converts a position to the tile containing it at zoom z
+func TileXY(lat, lon float64, z int) (int, int) {
+	n := float64(int(1) << uint(z))
+	lat = math.Max(-85.05112878, math.Min(85.05112878, lat))
+	x := int((lon + 180) / 360 * n)
+	rad := lat * math.Pi / 180
+	y := int((1 - math.Log(math.Tan(rad)+1/math.Cos(rad))/math.Pi) / 2 * n)
+	clamp := func(v int) int { return int(math.Max(0, math.Min(n-1, float64(v)))) }
+	return clamp(x), clamp(y)
+}
+
+// CountTiles returns how many tiles cover an area over a zoom range
+func CountTiles(a Area, minZoom, maxZoom int) int {
+	total := 0
+	for z := minZoom; z <= maxZoom; z++ {
+		x0, y0 := TileXY(a.MaxLat, a.MinLon, z)
+		x1, y1 := TileXY(a.MinLat, a.MaxLon, z)
+		total += (x1 - x0 + 1) * (y1 - y0 + 1)
+	}
+	return total
+}
+
+// StartSeed pre-fetches every missing tile of an area over a zoom range in the
+// background. A running seed is cancelled first.
+func (c *Cache) StartSeed(a Area, minZoom, maxZoom int) error {
+	if a.MinLat >= a.MaxLat || a.MinLon >= a.MaxLon {
+		return fmt.Errorf("area must have minLat < maxLat and minLon < maxLon")
+	}
+	if minZoom < 0 || maxZoom > maxTileZoom || minZoom > maxZoom {
+		return fmt.Errorf("zoom range must be within 0-%d", maxTileZoom)
+	}
+	total := CountTiles(a, minZoom, maxZoom)
+
+	c.mu.Lock()
+	if !c.status.Enabled {
+		c.mu.Unlock()
+		return fmt.Errorf("tile cache is disabled")
+	}
+	if c.cfg.MaxSeed > 0 && total > c.cfg.MaxSeed {
+		c.mu.Unlock()
+		return fmt.Errorf("area needs %d tiles, more than the %d allowed - reduce the area or zoom", total, c.cfg.MaxSeed)
+	}
+	if c.seedCxl != nil {
+		c.seedCxl()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.seedCxl = cancel
+	now := time.Now()
+	area := a
+	c.status.Seed = SeedStatus{Running: true, Area: &area, MinZoom: minZoom, MaxZoom: maxZoom, Total: total, Started: &now}
+	c.mu.Unlock()
+
+	logger.Info("[TILES] Seeding %d tiles (zoom %d-%d)", total, minZoom, maxZoom)
+	go c.seed(ctx, a, minZoom, maxZoom)
+	return nil
+}
+
+func (c *Cache) seed(ctx context.Context, a Area, minZoom, maxZoom int) {
+	var seedErr error
+	for z := minZoom; z <= maxZoom && seedErr == nil; z++ {
+		x0, y0 := TileXY(a.MaxLat, a.MinLon, z)
+		x1, y1 := TileXY(a.MinLat, a.MaxLon, z)
+		for x := x0; x <= x1 && seedErr == nil; x++ {
+			for y := y0; y <= y1; y++ {
+				if ctx.Err() != nil {
+					seedErr = fmt.Errorf("cancelled")
+					break
+				}
+				fetched, failed := false, false
+				if _, err := os.Stat(c.path(z, x, y)); err != nil {
+					if _, err := c.fetch(z, x, y); err != nil {
+						failed = true
+						logger.Debug("[TILES] Seed %d/%d/%d failed: %v", z, x, y, err)
+					} else {
+						fetched = true
+					}
+				}
+				c.mu.Lock()
+				c.status.Seed.Done++
+				if fetched {
+					c.status.Seed.Fetched++
+				}
+				if failed {
+					c.status.Seed.Failed++
+				}
+				c.mu.Unlock()
+
+				if fetched || failed {
+					select {
+					case <-ctx.Done():
+					case <-time.After(seedDelay):
+					}
+				}
+			}
+		}
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	c.status.Seed.Running = false
+	c.status.Seed.Finished = &now
+	if seedErr != nil {
+		c.status.Seed.Error = seedErr.Error()
+	}
+	st := c.status.Seed
+	c.mu.Unlock()
+	logger.Info("[TILES] Seed finished: %d fetched, %d failed, %d already cached", st.Fetched, st.Failed, st.Done-st.Fetched-st.Failed)
+}
+
+// Snapshot returns the cache status
+func (c *Cache) Snapshot() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.status
+	if s.Seed.Area != nil {
+		area := *s.Seed.Area
+		s.Seed.Area = &area
+	}
+	return s
+}
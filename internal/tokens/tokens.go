@@ -0,0 +1,259 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Roles, from least to most privileged; each includes the ones before it
+const (
+	RoleTelemetry = "telemetry" // Read-only access to status and telemetry
+	RoleCamera    = "camera"    // Telemetry plus camera control
+	RoleOperator  = "operator"  // Camera plus flight commands, parameters, payloads and link settings
+	RoleAdmin     = "admin"     // Everything, including token management
+)
+
+var roleRank = map[string]int{RoleTelemetry: 1, RoleCamera: 2, RoleOperator: 3, RoleAdmin: 4}
+
+// secretPrefix marks bearer values issued by this store
+const secretPrefix = "dbt_"
+
+// maxTokens bounds the store so a leaked admin token can't fill the disk
+const maxTokens = 100
+
+// Token is an issued API token. Only the SHA-256 of the secret is kept.
+type Token struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Role     string     `json:"role"`
+	Hint     string     `json:"hint"`           // First characters of the secret, to recognise it
+	Hash     string     `json:"hash,omitempty"` // Never returned by List
+	Created  time.Time  `json:"created"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	LastUsed *time.Time `json:"lastUsed,omitempty"`
+}
+
+// ValidRole reports whether role is one of the Role* constants
+func ValidRole(role string) bool {
+	return roleRank[role] > 0
+}
+
+// Allows reports whether a token with role may act as need
+func Allows(role, need string) bool {
+	return roleRank[role] > 0 && roleRank[role] >= roleRank[need]
+}
+
+// IsToken reports whether a bearer value looks like an issued token
+func IsToken(secret string) bool {
+	return strings.HasPrefix(secret, secretPrefix)
+}
+
+// Identity is who a bearer value belongs to
+type Identity struct {
+	ID   string // Token ID, "admin_token" or the config key of a scoped credential
+	Name string
+	Role string
+}
+
+// Scoped is a per-feature credential from the config (files.api_token,
+// control.api_token): it acts as Role, but only on paths under Prefix
+type Scoped struct {
+	Key    string // Config key, used as the identity
+	Secret string
+	Prefix string
+	Role   string
+}
+
+// Store holds the issued tokens and persists them to a local file
+type Store struct {
+	mu     sync.Mutex
+	path   string
+	admin  string // Bootstrap admin credential from the config (plaintext)
+	scoped []Scoped
+	tokens []Token
+}
+
+// Global is the process-wide token store
+var Global = &Store{}
+
+// Load reads the token file (a missing file is an empty store). adminToken is
+// accepted as an admin credential so the first tokens can be created.
+func (s *Store) Load(path, adminToken string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.path = path
+	s.admin = adminToken
+	s.tokens = nil
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read tokens: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.tokens); err != nil {
+		return fmt.Errorf("failed to parse tokens: %w", err)
+	}
+	return nil
+}
+
+// Create issues a token and returns it with its secret, which is not stored
+// and cannot be shown again. ttl 0 means the token does not expire.
+func (s *Store) Create(name, role string, ttl time.Duration) (Token, string, error) {
+	if !ValidRole(role) {
+		return Token{}, "", fmt.Errorf("role must be %s, %s, %s or %s", RoleTelemetry, RoleCamera, RoleOperator, RoleAdmin)
+	}
+	if name == "" || len(name) > 64 {
+		return Token{}, "", fmt.Errorf("name must be 1-64 characters")
+	}
+	if ttl < 0 {
+		return Token{}, "", fmt.Errorf("expiry must not be negative")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return Token{}, "", err
+	}
+	secret := secretPrefix + hex.EncodeToString(raw)
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Token{}, "", err
+	}
+	t := Token{
+		ID:      hex.EncodeToString(id),
+		Name:    name,
+		Role:    role,
+		Hint:    secret[:len(secretPrefix)+6],
+		Hash:    hashSecret(secret),
+		Created: time.Now(),
+	}
+	if ttl > 0 {
+		exp := t.Created.Add(ttl)
+		t.Expires = &exp
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.tokens) >= maxTokens {
+		return Token{}, "", fmt.Errorf("token limit of %d reached - revoke unused tokens", maxTokens)
+	}
+	s.tokens = append(s.tokens, t)
+	if err := s.saveLocked(); err != nil {
+		s.tokens = s.tokens[:len(s.tokens)-1]
+		return Token{}, "", err
+	}
+	t.Hash = ""
+	return t, secret, nil
+}
+
+// Revoke deletes a token
+func (s *Store) Revoke(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.tokens {
+		if t.ID == id {
+			s.tokens = append(s.tokens[:i], s.tokens[i+1:]...)
+			return s.saveLocked()
+		}
+	}
+	return fmt.Errorf("token %s not found", id)
+}
+
+// List returns the tokens without their hashes, newest first
+func (s *Store) List() []Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Token, len(s.tokens))
+	copy(out, s.tokens)
+	for i := range out {
+		out[i].Hash = ""
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.After(out[j].Created) })
+	return out
+}
+
+// SetScoped replaces the per-feature credentials; empty secrets are ignored
+func (s *Store) SetScoped(creds []Scoped) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scoped = s.scoped[:0]
+	for _, c := range creds {
+		if c.Secret != "" {
+			s.scoped = append(s.scoped, c)
+		}
+	}
+}
+
+// Authenticate returns the role of a bearer value: an issued, unexpired token
+// or the bootstrap admin token
+func (s *Store) Authenticate(secret string) (string, bool) {
+	id, ok := s.Identify(secret, "")
+	return id.Role, ok
+}
+
+// Identify returns who a bearer value belongs to: an issued, unexpired token,
+// the bootstrap admin token or a scoped credential for path. LastUsed is
+// tracked in memory and persisted with the next change.
+func (s *Store) Identify(secret, path string) (Identity, bool) {
+	if secret == "" {
+		return Identity{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.admin != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(s.admin)) == 1 {
+		return Identity{ID: "admin_token", Name: "admin_token", Role: RoleAdmin}, true
+	}
+	for _, c := range s.scoped {
+		if path != "" && strings.HasPrefix(path, c.Prefix) && subtle.ConstantTimeCompare([]byte(secret), []byte(c.Secret)) == 1 {
+			return Identity{ID: c.Key, Name: c.Key, Role: c.Role}, true
+		}
+	}
+	if !IsToken(secret) {
+		return Identity{}, false
+	}
+	hash := hashSecret(secret)
+	now := time.Now()
+	for i := range s.tokens {
+		t := &s.tokens[i]
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(t.Hash)) != 1 {
+			continue
+		}
+		if t.Expires != nil && now.After(*t.Expires) {
+			return Identity{}, false
+		}
+		t.LastUsed = &now
+		return Identity{ID: t.ID, Name: t.Name, Role: t.Role}, true
+	}
+	return Identity{}, false
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// saveLocked writes the tokens atomically with owner-only permissions (caller holds lock)
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil // In-memory only
+	}
+	data, err := json.MarshalIndent(s.tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write tokens: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
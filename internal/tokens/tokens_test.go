@@ -0,0 +1,82 @@
+package tokens
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		role, need string
+		want       bool
+	}{
+		{RoleAdmin, RoleAdmin, true},
+		{RoleAdmin, RoleTelemetry, true},
+		{RoleOperator, RoleAdmin, false},
+		{RoleOperator, RoleOperator, true},
+		{RoleOperator, RoleCamera, true},
+		{RoleCamera, RoleOperator, false},
+		{RoleTelemetry, RoleCamera, false},
+		{RoleTelemetry, RoleTelemetry, true},
+		{"", RoleTelemetry, false},
+		{"root", RoleTelemetry, false},
+	}
+	for _, tt := range tests {
+		if got := Allows(tt.role, tt.need); got != tt.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tt.role, tt.need, got, tt.want)
+		}
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	s := &Store{}
+	if err := s.Load(filepath.Join(t.TempDir(), "tokens.json"), "bootstrap-secret"); err != nil {
+		t.Fatal(err)
+	}
+	s.SetScoped([]Scoped{{Key: "files.api_token", Secret: "files-secret", Prefix: "/api/files", Role: RoleAdmin}})
+	_, operator, err := s.Create("ops", RoleOperator, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, expiredSecret, err := s.Create("old", RoleAdmin, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Minute)
+	for i := range s.tokens {
+		if s.tokens[i].ID == expired.ID {
+			s.tokens[i].Expires = &past
+		}
+	}
+	_, revokedSecret, err := s.Create("gone", RoleAdmin, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Revoke(s.tokens[len(s.tokens)-1].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		path     string
+		wantRole string
+		wantOK   bool
+	}{
+		{"bootstrap admin", "bootstrap-secret", "/api/flight/land", RoleAdmin, true},
+		{"issued operator", operator, "/api/flight/land", RoleOperator, true},
+		{"scoped on its prefix", "files-secret", "/api/files/upload", RoleAdmin, true},
+		{"scoped elsewhere", "files-secret", "/api/emergency/stop", "", false},
+		{"expired", expiredSecret, "/api/status", "", false},
+		{"revoked", revokedSecret, "/api/status", "", false},
+		{"unknown token", "dbt_0000", "/api/status", "", false},
+		{"empty", "", "/api/status", "", false},
+	}
+	for _, tt := range tests {
+		id, ok := s.Identify(tt.secret, tt.path)
+		if ok != tt.wantOK || id.Role != tt.wantRole {
+			t.Errorf("%s: Identify() = (%q, %v), want (%q, %v)", tt.name, id.Role, ok, tt.wantRole, tt.wantOK)
+		}
+	}
+}
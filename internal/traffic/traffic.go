@@ -0,0 +1,240 @@
+package traffic
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/alerts"
+)
+
+const earthRadius = 6371000.0 // meters
+
+// Limits controls when traffic is considered a separation conflict
+type Limits struct {
+	HorizontalMeters float64       // Minimum horizontal separation at CPA
+	VerticalMeters   float64       // Minimum vertical separation at CPA
+	LookaheadSec     float64       // Only conflicts within this horizon are alerted
+	StaleAfter       time.Duration // Contacts not updated for this long are dropped
+}
+
+// Position is a geodetic position with NED velocity
+type Position struct {
+	Lat float64 // degrees
+	Lon float64 // degrees
+	Alt float64 // meters AMSL
+	VN  float64 // m/s north
+	VE  float64 // m/s east
+	VU  float64 // m/s up
+}
+
+// Contact is a single tracked traffic target
+type Contact struct {
+	ID        string    `json:"id"`     // ICAO address (hex) or peer identifier
+	Source    string    `json:"source"` // "adsb" or "peer"
+	Callsign  string    `json:"callsign"`
+	Lat       float64   `json:"lat"`
+	Lon       float64   `json:"lon"`
+	Alt       float64   `json:"alt"`
+	Heading   float64   `json:"heading"`
+	GroundSpd float64   `json:"groundSpeed"`
+	ClimbRate float64   `json:"climbRate"`
+	LastSeen  time.Time `json:"lastSeen"`
+
+	// Derived relative to ownship
+	DistanceM      float64 `json:"distanceM"`
+	VerticalM      float64 `json:"verticalM"`
+	CPATimeSec     float64 `json:"cpaTimeSec"`
+	CPAHorizontalM float64 `json:"cpaHorizontalM"`
+	CPAVerticalM   float64 `json:"cpaVerticalM"`
+	Conflict       bool    `json:"conflict"`
+
+	vel Position
+}
+
+// Tracker collects nearby traffic and computes closest point of approach
+type Tracker struct {
+	mu        sync.RWMutex
+	limits    Limits
+	ownship   *Position
+	ownUpdate time.Time
+	contacts  map[string]*Contact
+}
+
+// Global is the process-wide traffic tracker
+var Global = New(Limits{HorizontalMeters: 500, VerticalMeters: 100, LookaheadSec: 60, StaleAfter: 30 * time.Second})
+
+// New creates a traffic tracker
+func New(l Limits) *Tracker {
+	return &Tracker{
+		limits:   l,
+		contacts: make(map[string]*Contact),
+	}
+}
+
+// SetLimits updates separation limits
+func (t *Tracker) SetLimits(l Limits) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limits = l
+}
+
+// UpdateOwnship records our own position from GLOBAL_POSITION_INT
+func (t *Tracker) UpdateOwnship(m *common.MessageGlobalPositionInt) {
+	pos := &Position{
+		Lat: float64(m.Lat) / 1e7,
+		Lon: float64(m.Lon) / 1e7,
+		Alt: float64(m.Alt) / 1000,
+		VN:  float64(m.Vx) / 100,
+		VE:  float64(m.Vy) / 100,
+		VU:  -float64(m.Vz) / 100,
+	}
+
+	t.mu.Lock()
+	t.ownship = pos
+	t.ownUpdate = time.Now()
+	t.mu.Unlock()
+}
+
+// UpdateADSB records an ADSB_VEHICLE report
+func (t *Tracker) UpdateADSB(m *common.MessageAdsbVehicle) {
+	heading := float64(m.Heading) / 100
+	speed := float64(m.HorVelocity) / 100
+	rad := heading * math.Pi / 180
+
+	t.Update(&Contact{
+		ID:        fmt.Sprintf("%06X", m.IcaoAddress),
+		Source:    "adsb",
+		Callsign:  strings.TrimSpace(strings.TrimRight(m.Callsign, "\x00")),
+		Lat:       float64(m.Lat) / 1e7,
+		Lon:       float64(m.Lon) / 1e7,
+		Alt:       float64(m.Altitude) / 1000,
+		Heading:   heading,
+		GroundSpd: speed,
+		ClimbRate: float64(m.VerVelocity) / 100,
+		vel:       Position{VN: speed * math.Cos(rad), VE: speed * math.Sin(rad), VU: float64(m.VerVelocity) / 100},
+	})
+}
+
+// Update records a contact from any source and evaluates separation against ownship
+func (t *Tracker) Update(c *Contact) {
+	if c.vel == (Position{}) && c.GroundSpd != 0 {
+		rad := c.Heading * math.Pi / 180
+		c.vel = Position{VN: c.GroundSpd * math.Cos(rad), VE: c.GroundSpd * math.Sin(rad), VU: c.ClimbRate}
+	}
+	c.LastSeen = time.Now()
+
+	t.mu.Lock()
+	prev, existed := t.contacts[c.ID]
+	wasConflict := existed && prev.Conflict
+	if t.ownship != nil {
+		t.evaluate(c)
+	}
+	t.contacts[c.ID] = c
+	t.pruneLocked()
+	limits := t.limits
+	t.mu.Unlock()
+
+	if c.Conflict && !wasConflict {
+		alerts.Raise("traffic", alerts.SeverityCritical, fmt.Sprintf(
+			"Traffic %s (%s) CPA in %.0fs: %.0fm horizontal, %.0fm vertical (limits %.0fm/%.0fm)",
+			c.ID, c.Callsign, c.CPATimeSec, c.CPAHorizontalM, c.CPAVerticalM,
+			limits.HorizontalMeters, limits.VerticalMeters))
+	} else if !c.Conflict && wasConflict {
+		alerts.Raise("traffic", alerts.SeverityInfo, fmt.Sprintf("Traffic %s (%s) conflict cleared", c.ID, c.Callsign))
+	}
+}
+
+// evaluate computes relative geometry and closest point of approach (caller holds lock)
+func (t *Tracker) evaluate(c *Contact) {
+	own := t.ownship
+
+	// Flat-earth local tangent plane around ownship - accurate enough for traffic ranges
+	latRad := own.Lat * math.Pi / 180
+	north := (c.Lat - own.Lat) * math.Pi / 180 * earthRadius
+	east := (c.Lon - own.Lon) * math.Pi / 180 * earthRadius * math.Cos(latRad)
+	up := c.Alt - own.Alt
+
+	c.DistanceM = math.Hypot(north, east)
+	c.VerticalM = up
+
+	// Relative velocity (contact minus ownship)
+	rvN := c.vel.VN - own.VN
+	rvE := c.vel.VE - own.VE
+	rvU := c.vel.VU - own.VU
+
+	// Time of horizontal closest approach, clamped to [0, lookahead]
+	tcpa := 0.0
+	if v2 := rvN*rvN + rvE*rvE; v2 > 1e-6 {
+		tcpa = -(north*rvN + east*rvE) / v2
+	}
+	if tcpa < 0 {
+		tcpa = 0
+	}
+	if t.limits.LookaheadSec > 0 && tcpa > t.limits.LookaheadSec {
+		tcpa = t.limits.LookaheadSec
+	}
+
+	c.CPATimeSec = tcpa
+	c.CPAHorizontalM = math.Hypot(north+rvN*tcpa, east+rvE*tcpa)
+	c.CPAVerticalM = math.Abs(up + rvU*tcpa)
+	c.Conflict = c.CPAHorizontalM < t.limits.HorizontalMeters && c.CPAVerticalM < t.limits.VerticalMeters
+}
+
+// pruneLocked drops stale contacts (caller holds lock)
+func (t *Tracker) pruneLocked() {
+	if t.limits.StaleAfter <= 0 {
+		return
+	}
+	for id, c := range t.contacts {
+		if time.Since(c.LastSeen) > t.limits.StaleAfter {
+			delete(t.contacts, id)
+		}
+	}
+}
+
+// Snapshot returns current traffic sorted by CPA distance
+func (t *Tracker) Snapshot() map[string]interface{} {
+	t.mu.Lock()
+	t.pruneLocked()
+	contacts := make([]Contact, 0, len(t.contacts))
+	conflicts := 0
+	for _, c := range t.contacts {
+		contacts = append(contacts, *c)
+		if c.Conflict {
+			conflicts++
+		}
+	}
+	var ownship interface{}
+	if t.ownship != nil {
+		ownship = map[string]interface{}{
+			"lat":       t.ownship.Lat,
+			"lon":       t.ownship.Lon,
+			"alt":       t.ownship.Alt,
+			"updatedAt": t.ownUpdate,
+		}
+	}
+	limits := t.limits
+	t.mu.Unlock()
+
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].CPAHorizontalM < contacts[j].CPAHorizontalM
+	})
+
+	return map[string]interface{}{
+		"ownship":   ownship,
+		"contacts":  contacts,
+		"count":     len(contacts),
+		"conflicts": conflicts,
+		"limits": map[string]interface{}{
+			"horizontalM":  limits.HorizontalMeters,
+			"verticalM":    limits.VerticalMeters,
+			"lookaheadSec": limits.LookaheadSec,
+		},
+	}
+}
@@ -0,0 +1,199 @@
+package transform
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+)
+
+// PrivacyMode degrades the position the cloud receives: coordinates are
+// rounded to a grid, home/EKF origin and positions it cannot round are withheld. It runs after the
+// configured transforms and only for the cloud; local consumers keep full precision.
+type PrivacyMode struct {
+	mu              sync.RWMutex
+	enabled         bool
+	precisionMeters float64
+
+	rounded, suppressed atomic.Uint64
+}
+
+// PrivacyStatus is the privacy mode state shown by the API
+type PrivacyStatus struct {
+	Enabled         bool    `json:"enabled"`
+	PrecisionMeters float64 `json:"precisionMeters"`
+	Rounded         uint64  `json:"rounded"`    // Position messages sent with rounded coordinates
+	Suppressed      uint64  `json:"suppressed"` // Withheld: home/origin, other position-bearing types and unknown IDs
+}
+
+// Privacy is the process-wide privacy mode
+var Privacy = &PrivacyMode{precisionMeters: 100}
+
+// Set switches privacy mode and sets the rounding precision (meters)
+func (p *PrivacyMode) Set(enabled bool, precisionMeters float64) error {
+	if precisionMeters <= 0 || math.IsNaN(precisionMeters) || math.IsInf(precisionMeters, 0) {
+		return fmt.Errorf("precision must be a positive number of meters, got %g", precisionMeters)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.enabled = enabled
+	p.precisionMeters = precisionMeters
+	return nil
+}
+
+// Status returns the current mode and counters
+func (p *PrivacyMode) Status() PrivacyStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return PrivacyStatus{
+		Enabled:         p.enabled,
+		PrecisionMeters: p.precisionMeters,
+		Rounded:         p.rounded.Load(),
+		Suppressed:      p.suppressed.Load(),
+	}
+}
+
+// active returns the rounding precision when privacy mode applies to msg
+func (p *PrivacyMode) active(msg message.Message) (float64, bool) {
+	if classifyPrivacy(msg) == privacyPass {
+		return 0, false
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.precisionMeters, p.enabled
+}
+
+// apply rounds the coordinates of msg; false means it must be withheld
+func (p *PrivacyMode) apply(msg message.Message, precisionMeters float64) bool {
+	if classifyPrivacy(msg) != privacyRound {
+		p.suppressed.Add(1)
+		return false
+	}
+	v := reflect.ValueOf(msg).Elem()
+	pairs := positionFields[msg.GetID()]
+	if framedPositions[msg.GetID()] {
+		pairs = nil
+		if globalFrame(common.MAV_FRAME(v.FieldByName("Frame").Uint())) {
+			pairs = [][2]string{{"X", "Y"}}
+		}
+	}
+	for _, pair := range pairs {
+		lat, lon := v.FieldByName(pair[0]), v.FieldByName(pair[1])
+		if lat.Kind() == reflect.Float32 { // Degrees
+			la, lo := snapToGrid(int32(lat.Float()*1e7), int32(lon.Float()*1e7), precisionMeters)
+			lat.SetFloat(float64(la) / 1e7)
+			lon.SetFloat(float64(lo) / 1e7)
+			continue
+		}
+		la, lo := snapToGrid(int32(lat.Int()), int32(lon.Int()), precisionMeters)
+		lat.SetInt(int64(la))
+		lon.SetInt(int64(lo))
+	}
+	p.rounded.Add(1)
+	return true
+}
+
+// privacyKind is how privacy mode treats a message type
+type privacyKind int
+
+const (
+	privacyPass     privacyKind = iota // Carries no position
+	privacyRound                       // Coordinates rounded to the grid
+	privacyWithhold                    // Not sent to the cloud
+)
+
+// positionFields are the latitude/longitude field pairs rounded per message ID
+// (degE7 integers, or degrees for float fields)
+var positionFields = map[uint32][][2]string{
+	24:    {{"Lat", "Lon"}},                                       // GPS_RAW_INT
+	33:    {{"Lat", "Lon"}},                                       // GLOBAL_POSITION_INT
+	63:    {{"Lat", "Lon"}},                                       // GLOBAL_POSITION_INT_COV
+	86:    {{"LatInt", "LonInt"}},                                 // SET_POSITION_TARGET_GLOBAL_INT
+	87:    {{"LatInt", "LonInt"}},                                 // POSITION_TARGET_GLOBAL_INT
+	90:    {{"Lat", "Lon"}},                                       // HIL_STATE
+	108:   {{"Lat", "Lon"}, {"LatInt", "LonInt"}},                 // SIM_STATE
+	113:   {{"Lat", "Lon"}},                                       // HIL_GPS
+	115:   {{"Lat", "Lon"}},                                       // HIL_STATE_QUATERNION
+	124:   {{"Lat", "Lon"}},                                       // GPS2_RAW
+	133:   {{"Lat", "Lon"}},                                       // TERRAIN_REQUEST
+	134:   {{"Lat", "Lon"}},                                       // TERRAIN_DATA
+	135:   {{"Lat", "Lon"}},                                       // TERRAIN_CHECK
+	136:   {{"Lat", "Lon"}},                                       // TERRAIN_REPORT
+	144:   {{"Lat", "Lon"}},                                       // FOLLOW_TARGET
+	160:   {{"Lat", "Lng"}},                                       // FENCE_POINT
+	164:   {{"Lat", "Lng"}},                                       // SIMSTATE
+	175:   {{"Lat", "Lng"}},                                       // RALLY_POINT
+	178:   {{"Lat", "Lng"}},                                       // AHRS2
+	180:   {{"Lat", "Lng"}},                                       // CAMERA_FEEDBACK
+	182:   {{"Lat", "Lng"}},                                       // AHRS3
+	232:   {{"Lat", "Lon"}},                                       // GPS_INPUT
+	234:   {{"Latitude", "Longitude"}},                            // HIGH_LATENCY
+	235:   {{"Latitude", "Longitude"}},                            // HIGH_LATENCY2
+	246:   {{"Lat", "Lon"}},                                       // ADSB_VEHICLE
+	263:   {{"Lat", "Lon"}},                                       // CAMERA_IMAGE_CAPTURED
+	271:   {{"LatCamera", "LonCamera"}, {"LatImage", "LonImage"}}, // CAMERA_FOV_STATUS
+	276:   {{"Lat", "Lon"}},                                       // CAMERA_TRACKING_GEO_STATUS
+	301:   {{"Lat", "Lon"}},                                       // AIS_VESSEL
+	340:   {{"Lat", "Lon"}, {"NextLat", "NextLon"}},               // UTM_GLOBAL_POSITION
+	510:   {{"Lat", "Lon"}},                                       // TARGET_ABSOLUTE
+	11038: {{"Lat", "Lng"}},                                       // WATER_DEPTH
+	12901: {{"Latitude", "Longitude"}},                            // OPEN_DRONE_ID_LOCATION
+}
+
+// framedPositions carry X/Y as a position when their Frame is global
+// (MISSION_ITEM, MISSION_ITEM_INT, COMMAND_INT, COMMAND_INT_STAMPED,
+// ORBIT_EXECUTION_STATUS, FIGURE_EIGHT_EXECUTION_STATUS)
+var framedPositions = map[uint32]bool{39: true, 73: true, 75: true, 223: true, 360: true, 361: true}
+
+// globalFrame reports whether X/Y in this frame are latitude/longitude
+func globalFrame(f common.MAV_FRAME) bool {
+	switch f {
+	case common.MAV_FRAME_GLOBAL, common.MAV_FRAME_GLOBAL_RELATIVE_ALT, common.MAV_FRAME_GLOBAL_INT,
+		common.MAV_FRAME_GLOBAL_RELATIVE_ALT_INT, common.MAV_FRAME_GLOBAL_TERRAIN_ALT, common.MAV_FRAME_GLOBAL_TERRAIN_ALT_INT:
+		return true
+	}
+	return false
+}
+
+// positionFieldName matches field names that hold a latitude or longitude
+var positionFieldName = regexp.MustCompile(`(?i)lat|lon|lng`)
+
+// privacyKinds caches classifyPrivacy per message type
+var privacyKinds sync.Map // reflect.Type -> privacyKind
+
+// classifyPrivacy decides how privacy mode treats msg. Home and EKF origin are
+// withheld, listed positions rounded, and anything else that may carry a
+// position is withheld: other types with a lat/lon field and IDs outside the
+// dialect, whose payload cannot be inspected.
+func classifyPrivacy(msg message.Message) privacyKind {
+	typ := reflect.TypeOf(msg)
+	if k, ok := privacyKinds.Load(typ); ok {
+		return k.(privacyKind)
+	}
+	var kind privacyKind
+	switch id := msg.GetID(); {
+	case typ == reflect.TypeOf(&message.MessageRaw{}):
+		kind = privacyWithhold
+	case id == msgHomePosition, id == msgGPSGlobalOrigin, id == 48, id == 243: // Also SET_GPS_GLOBAL_ORIGIN, SET_HOME_POSITION
+		kind = privacyWithhold
+	case positionFields[id] != nil, framedPositions[id]:
+		kind = privacyRound
+	default:
+		kind = privacyPass
+		for i, elem := 0, typ.Elem(); i < elem.NumField(); i++ {
+			name := strings.ReplaceAll(strings.ToLower(elem.Field(i).Name), "relative", "")
+			if positionFieldName.MatchString(name) {
+				kind = privacyWithhold
+				break
+			}
+		}
+	}
+	privacyKinds.Store(typ, kind)
+	return kind
+}
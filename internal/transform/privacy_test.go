@@ -0,0 +1,177 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/all"
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/ardupilotmega"
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/policy"
+)
+
+const (
+	privLat, privLon = 473977420, 85455940 // 47.397742, 8.545594
+	privPrecision    = 100.0
+)
+
+func TestPrivacyMessages(t *testing.T) {
+	wantLat, wantLon := snapToGrid(privLat, privLon, privPrecision)
+	fLat, fLon := float32(privLat)/1e7, float32(privLon)/1e7
+
+	tests := []struct {
+		name     string
+		msg      message.Message
+		want     privacyKind
+		lat, lon func(message.Message) int32 // Rounded coordinates to check (nil = none)
+		tol      int32                       // float32 degrees hold ~1e-5° at these latitudes
+	}{
+		{"GPS_RAW_INT", &common.MessageGpsRawInt{Lat: privLat, Lon: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*common.MessageGpsRawInt).Lat },
+			func(m message.Message) int32 { return m.(*common.MessageGpsRawInt).Lon }, 0},
+		{"GLOBAL_POSITION_INT", &common.MessageGlobalPositionInt{Lat: privLat, Lon: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*common.MessageGlobalPositionInt).Lat },
+			func(m message.Message) int32 { return m.(*common.MessageGlobalPositionInt).Lon }, 0},
+		{"GLOBAL_POSITION_INT_COV", &common.MessageGlobalPositionIntCov{Lat: privLat, Lon: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*common.MessageGlobalPositionIntCov).Lat },
+			func(m message.Message) int32 { return m.(*common.MessageGlobalPositionIntCov).Lon }, 0},
+		{"GPS2_RAW", &common.MessageGps2Raw{Lat: privLat, Lon: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*common.MessageGps2Raw).Lat },
+			func(m message.Message) int32 { return m.(*common.MessageGps2Raw).Lon }, 0},
+		{"POSITION_TARGET_GLOBAL_INT", &common.MessagePositionTargetGlobalInt{LatInt: privLat, LonInt: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*common.MessagePositionTargetGlobalInt).LatInt },
+			func(m message.Message) int32 { return m.(*common.MessagePositionTargetGlobalInt).LonInt }, 0},
+		{"HIGH_LATENCY2", &common.MessageHighLatency2{Latitude: privLat, Longitude: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*common.MessageHighLatency2).Latitude },
+			func(m message.Message) int32 { return m.(*common.MessageHighLatency2).Longitude }, 0},
+		{"TERRAIN_REQUEST", &common.MessageTerrainRequest{Lat: privLat, Lon: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*common.MessageTerrainRequest).Lat },
+			func(m message.Message) int32 { return m.(*common.MessageTerrainRequest).Lon }, 0},
+		{"TERRAIN_REPORT", &common.MessageTerrainReport{Lat: privLat, Lon: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*common.MessageTerrainReport).Lat },
+			func(m message.Message) int32 { return m.(*common.MessageTerrainReport).Lon }, 0},
+		{"ADSB_VEHICLE", &common.MessageAdsbVehicle{Lat: privLat, Lon: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*common.MessageAdsbVehicle).Lat },
+			func(m message.Message) int32 { return m.(*common.MessageAdsbVehicle).Lon }, 0},
+		{"CAMERA_IMAGE_CAPTURED", &common.MessageCameraImageCaptured{Lat: privLat, Lon: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*common.MessageCameraImageCaptured).Lat },
+			func(m message.Message) int32 { return m.(*common.MessageCameraImageCaptured).Lon }, 0},
+		{"MISSION_ITEM_INT global", &common.MessageMissionItemInt{Frame: common.MAV_FRAME_GLOBAL_RELATIVE_ALT_INT, X: privLat, Y: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*common.MessageMissionItemInt).X },
+			func(m message.Message) int32 { return m.(*common.MessageMissionItemInt).Y }, 0},
+		{"MISSION_ITEM global", &common.MessageMissionItem{Frame: common.MAV_FRAME_GLOBAL, X: fLat, Y: fLon}, privacyRound,
+			func(m message.Message) int32 { return int32(m.(*common.MessageMissionItem).X*1e7 + 0.5) },
+			func(m message.Message) int32 { return int32(m.(*common.MessageMissionItem).Y*1e7 + 0.5) }, 64},
+		{"AHRS2", &ardupilotmega.MessageAhrs2{Lat: privLat, Lng: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*ardupilotmega.MessageAhrs2).Lat },
+			func(m message.Message) int32 { return m.(*ardupilotmega.MessageAhrs2).Lng }, 0},
+		{"AHRS3", &ardupilotmega.MessageAhrs3{Lat: privLat, Lng: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*ardupilotmega.MessageAhrs3).Lat },
+			func(m message.Message) int32 { return m.(*ardupilotmega.MessageAhrs3).Lng }, 0},
+		{"CAMERA_FEEDBACK", &ardupilotmega.MessageCameraFeedback{Lat: privLat, Lng: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*ardupilotmega.MessageCameraFeedback).Lat },
+			func(m message.Message) int32 { return m.(*ardupilotmega.MessageCameraFeedback).Lng }, 0},
+		{"RALLY_POINT", &ardupilotmega.MessageRallyPoint{Lat: privLat, Lng: privLon}, privacyRound,
+			func(m message.Message) int32 { return m.(*ardupilotmega.MessageRallyPoint).Lat },
+			func(m message.Message) int32 { return m.(*ardupilotmega.MessageRallyPoint).Lng }, 0},
+		{"FENCE_POINT", &ardupilotmega.MessageFencePoint{Lat: fLat, Lng: fLon}, privacyRound,
+			func(m message.Message) int32 { return int32(m.(*ardupilotmega.MessageFencePoint).Lat*1e7 + 0.5) },
+			func(m message.Message) int32 { return int32(m.(*ardupilotmega.MessageFencePoint).Lng*1e7 + 0.5) }, 64},
+		{"MISSION_ITEM_INT local", &common.MessageMissionItemInt{Frame: common.MAV_FRAME_LOCAL_NED, X: 1500, Y: -300}, privacyRound, nil, nil, 0},
+		{"HOME_POSITION", &common.MessageHomePosition{Latitude: privLat, Longitude: privLon}, privacyWithhold, nil, nil, 0},
+		{"GPS_GLOBAL_ORIGIN", &common.MessageGpsGlobalOrigin{Latitude: privLat, Longitude: privLon}, privacyWithhold, nil, nil, 0},
+		{"SET_HOME_POSITION", &common.MessageSetHomePosition{Latitude: privLat, Longitude: privLon}, privacyWithhold, nil, nil, 0},
+		{"not in the table (DEEPSTALL)", &ardupilotmega.MessageDeepstall{LandingLat: privLat, LandingLon: privLon}, privacyWithhold, nil, nil, 0},
+		{"unknown ID", &message.MessageRaw{ID: 50123, Payload: []byte{1, 2, 3}}, privacyWithhold, nil, nil, 0},
+		{"ATTITUDE", &common.MessageAttitude{Roll: 0.1}, privacyPass, nil, nil, 0},
+		{"ALTITUDE", &common.MessageAltitude{AltitudeRelative: 20}, privacyPass, nil, nil, 0},
+	}
+	for _, tt := range tests {
+		p := &PrivacyMode{}
+		if err := p.Set(true, privPrecision); err != nil {
+			t.Fatal(err)
+		}
+		precision, on := p.active(tt.msg)
+		if on != (tt.want != privacyPass) {
+			t.Errorf("%s: active = %v, want %v", tt.name, on, tt.want != privacyPass)
+			continue
+		}
+		if !on {
+			continue
+		}
+		before := reflect.ValueOf(tt.msg).Elem().Interface()
+		kept := p.apply(tt.msg, precision)
+		if kept != (tt.want == privacyRound) {
+			t.Errorf("%s: kept = %v, want %v", tt.name, kept, tt.want == privacyRound)
+			continue
+		}
+		if tt.lat != nil {
+			if lat, lon := tt.lat(tt.msg), tt.lon(tt.msg); abs32(lat-wantLat) > tt.tol+1 || abs32(lon-wantLon) > tt.tol+1 {
+				t.Errorf("%s: position %d, %d, want %d, %d", tt.name, lat, lon, wantLat, wantLon)
+			}
+		} else if kept && reflect.ValueOf(tt.msg).Elem().Interface() != before {
+			t.Errorf("%s: local coordinates changed", tt.name)
+		}
+	}
+}
+
+// Every message type in the dialect with a latitude or longitude field is
+// rounded or withheld, never passed through
+func TestPrivacyCoversTheDialect(t *testing.T) {
+	for _, msg := range all.Dialect.Messages {
+		typ := reflect.TypeOf(msg).Elem()
+		for i := 0; i < typ.NumField(); i++ {
+			name := typ.Field(i).Name
+			if !positionFieldName.MatchString(name) || name == "RelativeAlt" || name == "AltitudeRelative" {
+				continue
+			}
+			if classifyPrivacy(msg) == privacyPass {
+				t.Errorf("%s (%d): field %s passes privacy mode", typ.Name(), msg.GetID(), name)
+			}
+			break
+		}
+	}
+	// Every listed field exists with a coordinate type
+	for id, pairs := range positionFields {
+		var msg message.Message
+		for _, m := range all.Dialect.Messages {
+			if m.GetID() == id {
+				msg = m
+			}
+		}
+		if msg == nil {
+			t.Errorf("message %d is not in the dialect", id)
+			continue
+		}
+		typ := reflect.TypeOf(msg).Elem()
+		for _, pair := range pairs {
+			for _, name := range pair {
+				f, ok := typ.FieldByName(name)
+				if !ok || (f.Type.Kind() != reflect.Int32 && f.Type.Kind() != reflect.Float32) {
+					t.Errorf("%s (%d): no int32/float32 field %s", typ.Name(), id, name)
+				}
+			}
+		}
+	}
+}
+
+func TestPrivacyWithholdsUnknownFrames(t *testing.T) {
+	defer func(enabled bool, precision float64) { Privacy.Set(enabled, precision) }(Privacy.Status().Enabled, Privacy.Status().PrecisionMeters)
+	if err := Privacy.Set(true, privPrecision); err != nil {
+		t.Fatal(err)
+	}
+	p := &Pipeline{}
+	if err := p.Configure(nil); err != nil {
+		t.Fatal(err)
+	}
+	fr := &frame.V2Frame{Message: &message.MessageRaw{ID: 50123, Payload: []byte{1, 2, 3}}}
+	if _, kept := p.Frame(policy.ConsumerCloud, fr); kept {
+		t.Error("unknown message sent to the cloud in privacy mode")
+	}
+	if _, kept := p.Frame(policy.ConsumerWebSocket, fr); !kept {
+		t.Error("unknown message withheld from a local consumer")
+	}
+}
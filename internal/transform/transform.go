@@ -0,0 +1,198 @@
+package transform
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialect"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+	"DroneBridge/internal/policy"
+)
+
+// Transformer modifies FC messages in flight. Apply gets a private copy of a
+// message whose ID is in Messages and returns false to drop it.
+type Transformer interface {
+	Name() string
+	Messages() []uint32
+	Apply(msg message.Message) bool
+}
+
+// Spec configures one transformer (forwarding.transforms entry)
+type Spec struct {
+	Type      string   `json:"type"`
+	Value     float64  `json:"value"`
+	Consumers []string `json:"consumers,omitempty"` // Default: cloud
+}
+
+// stage is a configured transformer with its counters
+type stage struct {
+	t                Transformer
+	applied, dropped atomic.Uint64
+}
+
+// Pipeline runs, per consumer, a chain of transformers over FC frames. Frames
+// no transformer handles pass unchanged; modified ones are re-encoded.
+type Pipeline struct {
+	mu     sync.RWMutex
+	specs  []Spec
+	stages []*stage
+	chains map[string]map[uint32][]*stage // consumer -> msgID -> stages in config order
+	rw     *dialect.ReadWriter
+}
+
+// Global is the process-wide transformation pipeline
+var Global = &Pipeline{}
+
+// New builds a transformer from its spec
+func New(spec Spec) (Transformer, error) {
+	switch spec.Type {
+	case TypeAltitudeOffset:
+		return altitudeOffset{meters: spec.Value}, nil
+	case TypeHeadingOffset:
+		return headingOffset{degrees: spec.Value}, nil
+	case TypeRedactHome:
+		if spec.Value < 0 {
+			return nil, fmt.Errorf("redact_home value is a grid size in meters and must not be negative")
+		}
+		return redactHome{gridMeters: spec.Value}, nil
+	}
+	return nil, fmt.Errorf("unknown transform type %q", spec.Type)
+}
+
+// Configure replaces the transformers. They run in the given order.
+func (p *Pipeline) Configure(specs []Spec) error {
+	var stages []*stage
+	chains := make(map[string]map[uint32][]*stage)
+	for i, spec := range specs {
+		t, err := New(spec)
+		if err != nil {
+			return fmt.Errorf("transform %d: %w", i, err)
+		}
+		consumers := spec.Consumers
+		if len(consumers) == 0 {
+			consumers = []string{policy.ConsumerCloud}
+		}
+		st := &stage{t: t}
+		stages = append(stages, st)
+		for _, c := range consumers {
+			if !validConsumer(c) {
+				return fmt.Errorf("transform %d: unknown consumer %q (valid: %v)", i, c, policy.Consumers)
+			}
+			if chains[c] == nil {
+				chains[c] = make(map[uint32][]*stage)
+			}
+			for _, id := range t.Messages() {
+				chains[c][id] = append(chains[c][id], st)
+			}
+		}
+	}
+
+	// Needed even without transforms: privacy mode can be switched on at runtime
+	rw, err := dialect.NewReadWriter(mavlink_custom.GetCombinedDialect())
+	if err != nil {
+		return fmt.Errorf("failed to init dialect: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.specs = append([]Spec(nil), specs...)
+	p.stages = stages
+	p.chains = chains
+	p.rw = rw
+	return nil
+}
+
+// Frame returns the frame to deliver to consumer: fr itself when no
+// transformer (or, for the cloud, privacy mode) applies, else a re-encoded
+// copy. ok is false if it was dropped.
+func (p *Pipeline) Frame(consumer string, fr frame.Frame) (out frame.Frame, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	msg := fr.GetMessage()
+	chain := p.chains[consumer][msg.GetID()]
+	var precision float64
+	private := false
+	if consumer == policy.ConsumerCloud {
+		precision, private = Privacy.active(msg)
+	}
+	if len(chain) == 0 && !private {
+		return fr, true
+	}
+	if p.rw == nil {
+		return fr, !private // Not configured yet: never leak precise positions
+	}
+	mp := p.rw.GetMessage(msg.GetID())
+	if mp == nil {
+		return fr, !private // Unknown to the dialect: nothing to transform, and nothing to vouch for in privacy mode
+	}
+
+	// Transformers work on a copy: the decoded message is shared with the other consumers
+	cp := reflect.New(reflect.TypeOf(msg).Elem())
+	cp.Elem().Set(reflect.ValueOf(msg).Elem())
+	msg = cp.Interface().(message.Message)
+	for _, st := range chain {
+		if !st.t.Apply(msg) {
+			st.dropped.Add(1)
+			return nil, false
+		}
+		st.applied.Add(1)
+	}
+	if private && !Privacy.apply(msg, precision) {
+		return nil, false
+	}
+
+	// The checksum covers the payload and an altered frame can no longer carry the FC's signature
+	switch ff := fr.(type) {
+	case *frame.V2Frame:
+		c := *ff
+		c.Message = mp.Write(msg, true)
+		c.IncompatibilityFlag &^= frame.V2FlagSigned
+		c.Signature = nil
+		c.Checksum = c.GenerateChecksum(mp.CRCExtra())
+		return &c, true
+	case *frame.V1Frame:
+		c := *ff
+		c.Message = mp.Write(msg, false)
+		c.Checksum = c.GenerateChecksum(mp.CRCExtra())
+		return &c, true
+	}
+	logger.Debug("[TRANSFORM] Unsupported frame type %T", fr)
+	return fr, true
+}
+
+// Specs returns the configured transformers in order
+func (p *Pipeline) Specs() []Spec {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]Spec{}, p.specs...)
+}
+
+// Snapshot returns each transformer with how often it ran and dropped a message
+func (p *Pipeline) Snapshot() []map[string]interface{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]map[string]interface{}, 0, len(p.stages))
+	for _, st := range p.stages {
+		out = append(out, map[string]interface{}{
+			"name":    st.t.Name(),
+			"applied": st.applied.Load(),
+			"dropped": st.dropped.Load(),
+		})
+	}
+	return out
+}
+
+func validConsumer(name string) bool {
+	for _, c := range policy.Consumers {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package transform
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+)
+
+// Transformer types (forwarding.transforms[].type)
+const (
+	TypeAltitudeOffset = "altitude_offset" // Add value meters to AMSL altitudes (datum correction)
+	TypeHeadingOffset  = "heading_offset"  // Add value degrees to headings (e.g. magnetic declination)
+	TypeRedactHome     = "redact_home"     // Snap home/origin to a value-meter grid (0 = drop them)
+)
+
+// MAVLink message IDs handled by the transformers
+const (
+	msgAttitude          = 30
+	msgGlobalPositionInt = 33
+	msgGPSRawInt         = 24
+	msgGPSGlobalOrigin   = 49
+	msgVFRHud            = 74
+	msgHomePosition      = 242
+)
+
+// altitudeOffset shifts AMSL altitudes, e.g. from the WGS84 ellipsoid to a local datum.
+// Altitudes relative to home are left alone.
+type altitudeOffset struct {
+	meters float64
+}
+
+func (t altitudeOffset) Name() string { return fmt.Sprintf("%s(%+gm)", TypeAltitudeOffset, t.meters) }
+
+func (t altitudeOffset) Messages() []uint32 {
+	return []uint32{msgGlobalPositionInt, msgGPSRawInt, msgVFRHud, msgHomePosition, msgGPSGlobalOrigin}
+}
+
+func (t altitudeOffset) Apply(msg message.Message) bool {
+	mm := int32(math.Round(t.meters * 1000))
+	switch m := msg.(type) {
+	case *common.MessageGlobalPositionInt:
+		m.Alt += mm
+	case *common.MessageGpsRawInt:
+		m.Alt += mm
+	case *common.MessageVfrHud:
+		m.Alt += float32(t.meters)
+	case *common.MessageHomePosition:
+		m.Altitude += mm
+	case *common.MessageGpsGlobalOrigin:
+		m.Altitude += mm
+	}
+	return true
+}
+
+// headingOffset rotates headings, e.g. magnetic to true north by the declination
+type headingOffset struct {
+	degrees float64
+}
+
+func (t headingOffset) Name() string { return fmt.Sprintf("%s(%+g°)", TypeHeadingOffset, t.degrees) }
+
+func (t headingOffset) Messages() []uint32 {
+	return []uint32{msgGlobalPositionInt, msgVFRHud, msgAttitude}
+}
+
+func (t headingOffset) Apply(msg message.Message) bool {
+	switch m := msg.(type) {
+	case *common.MessageGlobalPositionInt:
+		if m.Hdg != math.MaxUint16 { // UINT16_MAX = unknown
+			m.Hdg = uint16(math.Mod(math.Mod(float64(m.Hdg)+t.degrees*100, 36000)+36000, 36000))
+		}
+	case *common.MessageVfrHud:
+		m.Heading = int16(math.Mod(math.Mod(float64(m.Heading)+t.degrees, 360)+360, 360))
+	case *common.MessageAttitude:
+		yaw := float64(m.Yaw) + t.degrees*math.Pi/180
+		m.Yaw = float32(math.Remainder(yaw, 2*math.Pi)) // Keep within -pi..pi
+	}
+	return true
+}
+
+// redactHome hides the precise take-off location: home and EKF origin are
+// snapped to the centre of a grid cell, or dropped when the grid is 0
+type redactHome struct {
+	gridMeters float64
+}
+
+func (t redactHome) Name() string {
+	if t.gridMeters == 0 {
+		return TypeRedactHome + "(drop)"
+	}
+	return fmt.Sprintf("%s(%gm)", TypeRedactHome, t.gridMeters)
+}
+
+func (t redactHome) Messages() []uint32 {
+	return []uint32{msgHomePosition, msgGPSGlobalOrigin}
+}
+
+func (t redactHome) Apply(msg message.Message) bool {
+	if t.gridMeters == 0 {
+		return false
+	}
+	switch m := msg.(type) {
+	case *common.MessageHomePosition:
+		m.Latitude, m.Longitude = snapToGrid(m.Latitude, m.Longitude, t.gridMeters)
+	case *common.MessageGpsGlobalOrigin:
+		m.Latitude, m.Longitude = snapToGrid(m.Latitude, m.Longitude, t.gridMeters)
+	}
+	return true
+}
+
+// snapToGrid moves a position (degE7) to the centre of its grid cell
+func snapToGrid(lat, lon int32, gridMeters float64) (int32, int32) {
+	const metersPerDegree = 111320.0
+	latDeg := float64(lat) / 1e7
+	latStep := gridMeters / metersPerDegree
+	lonStep := gridMeters / (metersPerDegree * math.Max(math.Cos(latDeg*math.Pi/180), 0.01))
+
+	snap := func(v, step float64) int32 {
+		return int32(math.Round((math.Floor(v/step)*step + step/2) * 1e7))
+	}
+	return snap(latDeg, latStep), snap(float64(lon)/1e7, lonStep)
+}
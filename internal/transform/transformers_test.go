@@ -0,0 +1,249 @@
+package transform
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/policy"
+)
+
+func TestAltitudeOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		meters float64
+		msg    message.Message
+		want   message.Message
+	}{
+		{"global position", 12.5,
+			&common.MessageGlobalPositionInt{Alt: 100000, RelativeAlt: 20000},
+			&common.MessageGlobalPositionInt{Alt: 112500, RelativeAlt: 20000}},
+		{"gps raw negative", -30,
+			&common.MessageGpsRawInt{Alt: 50000},
+			&common.MessageGpsRawInt{Alt: 20000}},
+		{"vfr hud", 2.5,
+			&common.MessageVfrHud{Alt: 100},
+			&common.MessageVfrHud{Alt: 102.5}},
+		{"home position", 10,
+			&common.MessageHomePosition{Altitude: 5000},
+			&common.MessageHomePosition{Altitude: 15000}},
+		{"gps global origin", 0.0004, // Rounds to the nearest millimetre
+			&common.MessageGpsGlobalOrigin{Altitude: 1000},
+			&common.MessageGpsGlobalOrigin{Altitude: 1000}},
+	}
+	for _, tt := range tests {
+		if !(altitudeOffset{meters: tt.meters}).Apply(tt.msg) {
+			t.Errorf("%s: Apply() dropped the message", tt.name)
+			continue
+		}
+		if !messagesEqual(tt.msg, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, tt.msg, tt.want)
+		}
+	}
+}
+
+func TestHeadingOffset(t *testing.T) {
+	tests := []struct {
+		name    string
+		degrees float64
+		msg     message.Message
+		want    message.Message
+	}{
+		{"east declination", 3.5,
+			&common.MessageGlobalPositionInt{Hdg: 9000},
+			&common.MessageGlobalPositionInt{Hdg: 9350}},
+		{"wraps past north", 10,
+			&common.MessageGlobalPositionInt{Hdg: 35500},
+			&common.MessageGlobalPositionInt{Hdg: 500}},
+		{"west declination wraps below zero", -10,
+			&common.MessageGlobalPositionInt{Hdg: 500},
+			&common.MessageGlobalPositionInt{Hdg: 35500}},
+		{"unknown heading kept", 10,
+			&common.MessageGlobalPositionInt{Hdg: math.MaxUint16},
+			&common.MessageGlobalPositionInt{Hdg: math.MaxUint16}},
+		{"vfr hud", -20,
+			&common.MessageVfrHud{Heading: 5},
+			&common.MessageVfrHud{Heading: 345}},
+	}
+	for _, tt := range tests {
+		if !(headingOffset{degrees: tt.degrees}).Apply(tt.msg) {
+			t.Errorf("%s: Apply() dropped the message", tt.name)
+			continue
+		}
+		if !messagesEqual(tt.msg, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, tt.msg, tt.want)
+		}
+	}
+
+	// ATTITUDE yaw stays within -pi..pi
+	att := &common.MessageAttitude{Yaw: float32(170 * math.Pi / 180)}
+	headingOffset{degrees: 20}.Apply(att)
+	if want := -170 * math.Pi / 180; math.Abs(float64(att.Yaw)-want) > 1e-5 {
+		t.Errorf("attitude: yaw = %v, want %v", att.Yaw, want)
+	}
+}
+
+func TestRedactHome(t *testing.T) {
+	const lat, lon = 473977420, 85455940 // 47.397742, 8.545594
+
+	tests := []struct {
+		name     string
+		grid     float64
+		msg      message.Message
+		wantDrop bool
+	}{
+		{"home dropped", 0, &common.MessageHomePosition{Latitude: lat, Longitude: lon}, true},
+		{"origin dropped", 0, &common.MessageGpsGlobalOrigin{Latitude: lat, Longitude: lon}, true},
+		{"home snapped", 500, &common.MessageHomePosition{Latitude: lat, Longitude: lon}, false},
+		{"origin snapped", 500, &common.MessageGpsGlobalOrigin{Latitude: lat, Longitude: lon}, false},
+	}
+	wantLat, wantLon := snapToGrid(lat, lon, 500)
+	for _, tt := range tests {
+		kept := (redactHome{gridMeters: tt.grid}).Apply(tt.msg)
+		if kept == tt.wantDrop {
+			t.Errorf("%s: Apply() = %v, want %v", tt.name, kept, !tt.wantDrop)
+			continue
+		}
+		if tt.wantDrop {
+			continue
+		}
+		var gotLat, gotLon int32
+		switch m := tt.msg.(type) {
+		case *common.MessageHomePosition:
+			gotLat, gotLon = m.Latitude, m.Longitude
+		case *common.MessageGpsGlobalOrigin:
+			gotLat, gotLon = m.Latitude, m.Longitude
+		}
+		if gotLat != wantLat || gotLon != wantLon {
+			t.Errorf("%s: position = (%d, %d), want (%d, %d)", tt.name, gotLat, gotLon, wantLat, wantLon)
+		}
+	}
+
+	// Every point of a cell lands on the same centre (give or take float rounding,
+	// a few cm), within half a cell of the original
+	aLat, aLon := snapToGrid(lat, lon, 500)
+	bLat, bLon := snapToGrid(lat+10, lon+10, 500)
+	if abs32(aLat-bLat) > 5 || abs32(aLon-bLon) > 5 {
+		t.Errorf("neighbouring points snapped to different cells: (%d, %d) vs (%d, %d)", aLat, aLon, bLat, bLon)
+	}
+	if d := math.Abs(float64(aLat-lat)) / 1e7 * 111320; d > 250 {
+		t.Errorf("snapped latitude moved %.0fm, want at most half the grid", d)
+	}
+}
+
+func TestPipelineChainOrder(t *testing.T) {
+	home := &common.MessageHomePosition{Latitude: 473977420, Longitude: 85455940, Altitude: 5000}
+
+	tests := []struct {
+		name        string
+		specs       []Spec
+		consumer    string
+		wantKept    bool
+		wantAlt     int32
+		wantApplied []uint64 // Per stage, in config order
+		wantDropped []uint64
+	}{
+		{
+			name:        "offsets accumulate in order",
+			specs:       []Spec{{Type: TypeAltitudeOffset, Value: 10}, {Type: TypeAltitudeOffset, Value: -2.5}},
+			consumer:    policy.ConsumerCloud,
+			wantKept:    true,
+			wantAlt:     12500,
+			wantApplied: []uint64{1, 1},
+			wantDropped: []uint64{0, 0},
+		},
+		{
+			name:        "offset runs before a later drop",
+			specs:       []Spec{{Type: TypeAltitudeOffset, Value: 10}, {Type: TypeRedactHome, Value: 0}},
+			consumer:    policy.ConsumerCloud,
+			wantApplied: []uint64{1, 0},
+			wantDropped: []uint64{0, 1},
+		},
+		{
+			name:        "drop stops the chain",
+			specs:       []Spec{{Type: TypeRedactHome, Value: 0}, {Type: TypeAltitudeOffset, Value: 10}},
+			consumer:    policy.ConsumerCloud,
+			wantApplied: []uint64{0, 0},
+			wantDropped: []uint64{1, 0},
+		},
+		{
+			name:        "other consumers untouched",
+			specs:       []Spec{{Type: TypeAltitudeOffset, Value: 10}},
+			consumer:    policy.ConsumerWebSocket,
+			wantKept:    true,
+			wantAlt:     5000,
+			wantApplied: []uint64{0},
+			wantDropped: []uint64{0},
+		},
+	}
+	for _, tt := range tests {
+		p := &Pipeline{}
+		if err := p.Configure(tt.specs); err != nil {
+			t.Fatalf("%s: Configure() = %v", tt.name, err)
+		}
+		in := *home
+		out, kept := p.Frame(tt.consumer, &frame.V2Frame{Message: &in})
+		if kept != tt.wantKept {
+			t.Errorf("%s: kept = %v, want %v", tt.name, kept, tt.wantKept)
+			continue
+		}
+		if in != *home {
+			t.Errorf("%s: the shared input message was modified", tt.name)
+		}
+		if kept {
+			got := decodeHome(t, p, out)
+			if got.Altitude != tt.wantAlt {
+				t.Errorf("%s: altitude = %d, want %d", tt.name, got.Altitude, tt.wantAlt)
+			}
+		}
+		for i, st := range p.stages {
+			if st.applied.Load() != tt.wantApplied[i] || st.dropped.Load() != tt.wantDropped[i] {
+				t.Errorf("%s: stage %d (%s) applied/dropped = %d/%d, want %d/%d", tt.name, i, st.t.Name(),
+					st.applied.Load(), st.dropped.Load(), tt.wantApplied[i], tt.wantDropped[i])
+			}
+		}
+	}
+}
+
+// decodeHome returns the HOME_POSITION carried by a frame from Pipeline.Frame
+func decodeHome(t *testing.T, p *Pipeline, fr frame.Frame) *common.MessageHomePosition {
+	t.Helper()
+	msg := fr.GetMessage()
+	if raw, ok := msg.(*message.MessageRaw); ok {
+		var err error
+		if msg, err = p.rw.GetMessage(msgHomePosition).Read(raw, true); err != nil {
+			t.Fatalf("decoding transformed frame: %v", err)
+		}
+	}
+	home, ok := msg.(*common.MessageHomePosition)
+	if !ok {
+		t.Fatalf("transformed frame carries %T, want HOME_POSITION", msg)
+	}
+	return home
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func messagesEqual(a, b message.Message) bool {
+	switch x := a.(type) {
+	case *common.MessageGlobalPositionInt:
+		return *x == *b.(*common.MessageGlobalPositionInt)
+	case *common.MessageGpsRawInt:
+		return *x == *b.(*common.MessageGpsRawInt)
+	case *common.MessageVfrHud:
+		return *x == *b.(*common.MessageVfrHud)
+	case *common.MessageHomePosition:
+		return *x == *b.(*common.MessageHomePosition)
+	case *common.MessageGpsGlobalOrigin:
+		return *x == *b.(*common.MessageGpsGlobalOrigin)
+	}
+	return false
+}
@@ -0,0 +1,331 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/storage"
+)
+
+// maxAttempts is how often an artifact is tried before it is marked failed
+const maxAttempts = 5
+
+// errPaused aborts an upload whose conditions stopped holding (armed, WiFi lost)
+var errPaused = errors.New("upload paused")
+
+// Backend stores uploaded artifacts
+type Backend interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64) error
+	Describe() string
+}
+
+// Config controls which artifacts are shipped and when
+type Config struct {
+	Classes         []string      // Storage classes to upload (empty = all)
+	Prefix          string        // Object key prefix
+	DroneUUID       string        // Keys are <prefix><uuid>/<class>/<file>
+	BytesPerSecond  int64         // Bandwidth limit (0 = unlimited)
+	WiFiInterface   string        // Upload only while this interface is up with an address (empty = any network)
+	RequireDisarmed bool          // Upload only while the FC reports disarmed
+	Settle          time.Duration // A file is complete once unmodified for this long
+	Interval        time.Duration // Time between scans for new artifacts
+}
+
+// Status is a snapshot of the uploader
+type Status struct {
+	Enabled       bool       `json:"enabled"`
+	Destination   string     `json:"destination,omitempty"`
+	Blocked       string     `json:"blocked,omitempty"` // Why uploads are on hold
+	Current       string     `json:"current,omitempty"` // File being uploaded
+	UploadedFiles int64      `json:"uploadedFiles"`
+	UploadedBytes int64      `json:"uploadedBytes"`
+	LastUpload    *time.Time `json:"lastUpload,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+}
+
+// Uploader ships completed storage artifacts to a backend
+type Uploader struct {
+	mu      sync.Mutex
+	cfg     Config
+	backend Backend
+	vehicle func() (connected, armed bool)
+	status  Status
+}
+
+// Global is the process-wide uploader
+var Global = &Uploader{}
+
+// Configure sets the backend and policy; a nil backend disables uploads
+func (u *Uploader) Configure(cfg Config, backend Backend) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.cfg = cfg
+	u.backend = backend
+	u.status.Enabled = backend != nil
+	if backend != nil {
+		u.status.Destination = backend.Describe()
+	}
+}
+
+// SetVehicleState sets the source of the FC link and arming state
+func (u *Uploader) SetVehicleState(fn func() (connected, armed bool)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.vehicle = fn
+}
+
+// Run uploads pending artifacts every interval until stopCh is closed
+func (u *Uploader) Run(stopCh <-chan struct{}) {
+	u.mu.Lock()
+	interval := u.cfg.Interval
+	enabled := u.backend != nil
+	u.mu.Unlock()
+	if !enabled {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		u.pass(ctx)
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pass uploads every pending artifact while the conditions allow it
+func (u *Uploader) pass(ctx context.Context) {
+	u.mu.Lock()
+	cfg := u.cfg
+	u.mu.Unlock()
+
+	for _, a := range storage.Global.Artifacts(cfg.Classes, cfg.Settle) {
+		if a.Upload.State == storage.UploadDone || a.Upload.State == storage.UploadFailed {
+			continue
+		}
+		if err := u.blocked(); err != nil {
+			u.setBlocked(err.Error())
+			return
+		}
+		u.setBlocked("")
+		if ctx.Err() != nil {
+			return
+		}
+		u.uploadArtifact(ctx, cfg, a)
+	}
+}
+
+// blocked returns why uploads may not run now, or nil
+func (u *Uploader) blocked() error {
+	u.mu.Lock()
+	cfg := u.cfg
+	vehicle := u.vehicle
+	u.mu.Unlock()
+
+	if cfg.RequireDisarmed {
+		if vehicle == nil {
+			return fmt.Errorf("vehicle state unknown")
+		}
+		connected, armed := vehicle()
+		if !connected {
+			return fmt.Errorf("flight controller not connected")
+		}
+		if armed {
+			return fmt.Errorf("vehicle armed")
+		}
+	}
+	if cfg.WiFiInterface != "" {
+		if err := interfaceUp(cfg.WiFiInterface); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// interfaceUp checks that a network interface is up and has a usable address
+func interfaceUp(name string) error {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return fmt.Errorf("%s not present", name)
+	}
+	if iface.Flags&net.FlagUp == 0 {
+		return fmt.Errorf("%s is down", name)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && ipnet.IP.IsGlobalUnicast() {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s has no address", name)
+}
+
+// uploadArtifact uploads one file and records the outcome in the store
+func (u *Uploader) uploadArtifact(ctx context.Context, cfg Config, a storage.Artifact) {
+	u.mu.Lock()
+	backend := u.backend
+	u.status.Current = a.Path
+	u.mu.Unlock()
+	defer func() {
+		u.mu.Lock()
+		u.status.Current = ""
+		u.mu.Unlock()
+	}()
+
+	key := cfg.Prefix + cfg.DroneUUID + "/" + a.Class + "/" + filepath.Base(a.Path)
+	st := a.Upload
+	now := time.Now()
+	st.LastAttempt = &now
+
+	err := u.put(ctx, backend, cfg.BytesPerSecond, key, a)
+	if errors.Is(err, errPaused) || ctx.Err() != nil {
+		// Not the artifact's fault - retried without counting an attempt
+		logger.Info("[UPLOAD] ⏸️ %s interrupted: %v", filepath.Base(a.Path), err)
+		return
+	}
+
+	st.Attempts++
+	if err != nil {
+		st.LastError = err.Error()
+		st.State = storage.UploadPending
+		if st.Attempts >= maxAttempts {
+			st.State = storage.UploadFailed
+		}
+		storage.Global.SetUploadState(a.Path, st)
+		logger.Warn("[UPLOAD] ❌ %s (attempt %d/%d): %v", filepath.Base(a.Path), st.Attempts, maxAttempts, err)
+
+		u.mu.Lock()
+		u.status.LastError = err.Error()
+		u.mu.Unlock()
+		return
+	}
+
+	done := time.Now()
+	st.State = storage.UploadDone
+	st.Key = key
+	st.UploadedAt = &done
+	st.LastError = ""
+	storage.Global.SetUploadState(a.Path, st)
+	logger.Info("[UPLOAD] ✅ %s → %s (%d bytes in %v)", filepath.Base(a.Path), key, a.Size, done.Sub(now).Round(time.Second))
+	metrics.Global.AddLog("INFO", fmt.Sprintf("Uploaded %s (%d bytes)", filepath.Base(a.Path), a.Size))
+
+	u.mu.Lock()
+	u.status.UploadedFiles++
+	u.status.UploadedBytes += a.Size
+	u.status.LastUpload = &done
+	u.status.LastError = ""
+	u.mu.Unlock()
+}
+
+// put streams a file to the backend through the bandwidth limiter
+func (u *Uploader) put(ctx context.Context, backend Backend, bps int64, key string, a storage.Artifact) error {
+	f, err := os.Open(a.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	body := &throttledReader{r: f, bps: bps, start: time.Now(), check: u.blocked, cancel: cancel}
+	err = backend.Put(ctx, key, body, a.Size)
+	if paused := body.pausedErr(); paused != nil {
+		return fmt.Errorf("%w: %v", errPaused, paused)
+	}
+	return err
+}
+
+// throttledReader limits the read rate to bps and aborts the upload once the
+// upload conditions stop holding
+type throttledReader struct {
+	r      io.Reader
+	bps    int64
+	start  time.Time
+	read   int64
+	check  func() error
+	cancel func()
+	last   time.Time
+
+	mu     sync.Mutex // The transport may still be reading when Put returns
+	paused error
+}
+
+func (t *throttledReader) pausedErr() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.paused
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if time.Since(t.last) >= time.Second {
+		t.last = time.Now()
+		if err := t.check(); err != nil {
+			t.mu.Lock()
+			t.paused = err
+			t.mu.Unlock()
+			t.cancel()
+			return 0, err
+		}
+	}
+	if t.bps > 0 {
+		if max := t.bps / 4; max > 0 && int64(len(p)) > max {
+			p = p[:max]
+		}
+		if ahead := time.Duration(t.read*int64(time.Second)/t.bps) - time.Since(t.start); ahead > 0 {
+			time.Sleep(ahead)
+		}
+	}
+	n, err := t.r.Read(p)
+	t.read += int64(n)
+	return n, err
+}
+
+// setBlocked records why uploads are on hold ("" = not blocked)
+func (u *Uploader) setBlocked(reason string) {
+	u.mu.Lock()
+	changed := u.status.Blocked != reason
+	u.status.Blocked = reason
+	u.mu.Unlock()
+	if changed && reason != "" {
+		logger.Debug("[UPLOAD] On hold: %s", reason)
+	}
+}
+
+// Classes returns the storage classes being uploaded (empty = all)
+func (u *Uploader) Classes() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]string(nil), u.cfg.Classes...)
+}
+
+// Snapshot returns the uploader status
+func (u *Uploader) Snapshot() Status {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.status
+}
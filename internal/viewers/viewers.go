@@ -0,0 +1,124 @@
+package viewers
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/audit"
+	"DroneBridge/internal/logger"
+)
+
+// maxHistory is the number of ended sessions kept for the web API
+const maxHistory = 50
+
+// Session is one user watching this drone through the router
+type Session struct {
+	UserUUID string     `json:"userUuid"`
+	Started  time.Time  `json:"started"`
+	Ended    *time.Time `json:"ended,omitempty"`
+	Duration float64    `json:"durationSeconds"`
+	EndedBy  string     `json:"endedBy,omitempty"` // "disconnect", "reconnect" or "shutdown"
+}
+
+// Tracker follows viewer sessions from router USER_CONNECTED / USER_DISCONNECTED
+type Tracker struct {
+	mu            sync.Mutex
+	active        map[string]time.Time
+	history       []Session
+	totalSessions int
+	totalSeconds  float64
+}
+
+// Global is the process-wide viewer tracker
+var Global = New()
+
+// New creates an empty tracker
+func New() *Tracker {
+	return &Tracker{active: make(map[string]time.Time)}
+}
+
+// Connect starts a session for a user. A repeated connect without a disconnect
+// closes the previous session first so no time is counted twice.
+func (t *Tracker) Connect(userUUID string) {
+	now := time.Now()
+	t.mu.Lock()
+	if _, ok := t.active[userUUID]; ok {
+		t.endLocked(userUUID, now, "reconnect")
+	}
+	t.active[userUUID] = now
+	count := len(t.active)
+	t.mu.Unlock()
+
+	logger.Info("[VIEWERS] User %s connected (%d watching)", userUUID, count)
+	audit.Global.Record("viewer", "connect", map[string]interface{}{"user": userUUID})
+}
+
+// Disconnect ends a user's session
+func (t *Tracker) Disconnect(userUUID string) {
+	t.mu.Lock()
+	if _, ok := t.active[userUUID]; !ok {
+		t.mu.Unlock()
+		logger.Debug("[VIEWERS] Disconnect for unknown user %s", userUUID)
+		return
+	}
+	t.endLocked(userUUID, time.Now(), "disconnect")
+	count := len(t.active)
+	t.mu.Unlock()
+
+	logger.Info("[VIEWERS] User %s disconnected (%d watching)", userUUID, count)
+}
+
+// CloseAll ends every active session, e.g. on shutdown
+func (t *Tracker) CloseAll(reason string) {
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for user := range t.active {
+		t.endLocked(user, now, reason)
+	}
+}
+
+// endLocked closes a session and records it for accounting (caller holds lock)
+func (t *Tracker) endLocked(userUUID string, now time.Time, reason string) {
+	started := t.active[userUUID]
+	delete(t.active, userUUID)
+
+	s := Session{UserUUID: userUUID, Started: started, Ended: &now, Duration: now.Sub(started).Seconds(), EndedBy: reason}
+	t.history = append([]Session{s}, t.history...)
+	if len(t.history) > maxHistory {
+		t.history = t.history[:maxHistory]
+	}
+	t.totalSessions++
+	t.totalSeconds += s.Duration
+
+	audit.Global.Record("viewer", "session", map[string]interface{}{
+		"user":            userUUID,
+		"started":         started.UTC(),
+		"ended":           now.UTC(),
+		"durationSeconds": s.Duration,
+		"endedBy":         reason,
+	})
+}
+
+// Snapshot returns active sessions (longest first), recently ended sessions and totals
+func (t *Tracker) Snapshot() map[string]interface{} {
+	now := time.Now()
+	t.mu.Lock()
+	active := make([]Session, 0, len(t.active))
+	for user, started := range t.active {
+		active = append(active, Session{UserUUID: user, Started: started, Duration: now.Sub(started).Seconds()})
+	}
+	history := append([]Session{}, t.history...)
+	totalSessions, totalSeconds := t.totalSessions, t.totalSeconds
+	t.mu.Unlock()
+
+	sort.Slice(active, func(i, j int) bool { return active[i].Started.Before(active[j].Started) })
+	return map[string]interface{}{
+		"active":        active,
+		"count":         len(active),
+		"recent":        history,
+		"totalSessions": totalSessions,
+		"totalSeconds":  totalSeconds,
+	}
+}
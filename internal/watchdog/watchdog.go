@@ -0,0 +1,188 @@
+package watchdog
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// BeatInterval is how often idle loops should beat so a quiet link isn't mistaken for a stall
+const BeatInterval = time.Second
+
+// Loop is a registered event loop. The loop calls Beat every time it returns to its select.
+type Loop struct {
+	name    string
+	onStall func() // Restarts the affected subsystem (may be nil)
+
+	mu       sync.Mutex
+	lastBeat time.Time
+	stalled  bool
+	lastKick time.Time
+	stalls   int
+}
+
+// LoopStatus is a snapshot of one registered loop
+type LoopStatus struct {
+	Name       string    `json:"name"`
+	LastBeat   time.Time `json:"lastBeat"`
+	AgeSeconds float64   `json:"ageSeconds"`
+	Timeout    float64   `json:"timeoutSeconds"`
+	Stalled    bool      `json:"stalled"`
+	Stalls     int       `json:"stalls"`
+}
+
+// Watchdog detects loops that stop making progress
+type Watchdog struct {
+	mu      sync.Mutex
+	timeout time.Duration
+	loops   map[string]*Loop
+}
+
+// Global is the process-wide watchdog
+var Global = New()
+
+// New creates an empty watchdog
+func New() *Watchdog {
+	return &Watchdog{timeout: 30 * time.Second, loops: make(map[string]*Loop)}
+}
+
+// SetTimeout sets how long a loop may go without beating before it is considered stuck
+func (w *Watchdog) SetTimeout(timeout time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timeout = timeout
+}
+
+// Register adds a loop that must beat at least every timeout. onStall is called
+// (once per timeout while the loop stays stuck) to restart the affected subsystem.
+// Registering an existing name replaces it.
+func (w *Watchdog) Register(name string, onStall func()) *Loop {
+	l := &Loop{name: name, onStall: onStall, lastBeat: time.Now()}
+	w.mu.Lock()
+	w.loops[name] = l
+	w.mu.Unlock()
+	return l
+}
+
+// Unregister removes a loop (call when the loop exits normally)
+func (w *Watchdog) Unregister(l *Loop) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.loops[l.name] == l {
+		delete(w.loops, l.name)
+	}
+}
+
+// Beat records progress
+func (l *Loop) Beat() {
+	l.mu.Lock()
+	l.lastBeat = time.Now()
+	recovered := l.stalled
+	l.stalled = false
+	l.mu.Unlock()
+
+	if recovered {
+		logger.Info("[WATCHDOG] ✅ %s is making progress again", l.name)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Watchdog: %s recovered", l.name))
+	}
+}
+
+// Run checks all loops until stopCh is closed
+func (w *Watchdog) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(BeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+func (w *Watchdog) check() {
+	now := time.Now()
+	w.mu.Lock()
+	timeout := w.timeout
+	loops := make([]*Loop, 0, len(w.loops))
+	for _, l := range w.loops {
+		loops = append(loops, l)
+	}
+	w.mu.Unlock()
+
+	for _, l := range loops {
+		l.mu.Lock()
+		age := now.Sub(l.lastBeat)
+		kick := age > timeout && now.Sub(l.lastKick) > timeout
+		first := kick && !l.stalled
+		if kick {
+			l.stalled = true
+			l.lastKick = now
+			if first {
+				l.stalls++
+			}
+		}
+		l.mu.Unlock()
+
+		if !kick {
+			continue
+		}
+		if first {
+			alerts.Raise("watchdog", alerts.SeverityCritical, fmt.Sprintf(
+				"%s has not made progress for %v - restarting", l.name, age.Round(time.Second)))
+			logStacks(l.name)
+		} else {
+			logger.Warn("[WATCHDOG] %s still stuck after %v - restarting again", l.name, age.Round(time.Second))
+		}
+		if l.onStall != nil {
+			go l.onStall()
+		}
+	}
+}
+
+// logStacks logs the stacks of goroutines running the stalled loop
+func logStacks(name string) {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	found := false
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		if strings.Contains(g, name) {
+			logger.Error("[WATCHDOG] Stack of stalled %s:\n%s", name, g)
+			found = true
+		}
+	}
+	if !found {
+		logger.Error("[WATCHDOG] No goroutine found running %s", name)
+	}
+}
+
+// Snapshot returns the status of all registered loops sorted by name
+func (w *Watchdog) Snapshot() []LoopStatus {
+	now := time.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	out := make([]LoopStatus, 0, len(w.loops))
+	for _, l := range w.loops {
+		l.mu.Lock()
+		out = append(out, LoopStatus{
+			Name:       l.name,
+			LastBeat:   l.lastBeat,
+			AgeSeconds: now.Sub(l.lastBeat).Seconds(),
+			Timeout:    w.timeout.Seconds(),
+			Stalled:    l.stalled,
+			Stalls:     l.stalls,
+		})
+		l.mu.Unlock()
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,232 @@
+package wsproxy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialect"
+	"github.com/bluenviron/gomavlib/v3/pkg/frame"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/mavlink_custom"
+)
+
+const (
+	maxClients  = 8   // Concurrent WebSocket clients
+	clientQueue = 512 // Frames buffered per client before new ones are dropped
+)
+
+// TextWriter is implemented by connections that can also send text messages,
+// which carry JSON events next to the binary MAVLink frames
+type TextWriter interface {
+	WriteText(data []byte) error
+}
+
+// outMsg is one queued message to a client
+type outMsg struct {
+	data []byte
+	text bool // JSON event (text message) instead of MAVLink frames
+}
+
+type client struct {
+	remote    string
+	role      string // Token role, handed to the injector with each frame
+	canSend   bool
+	events    bool // Also receives JSON events (see PublishEvent)
+	connected time.Time
+	out       chan outMsg
+	conn      io.Closer
+
+	sent, received, dropped, rejected atomic.Uint64
+}
+
+// Hub fans FC frames out to WebSocket clients (browser GCS components) and
+// relays their frames to the FC through the forwarder's injector
+type Hub struct {
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	rw      *dialect.ReadWriter
+	writer  *frame.Writer
+	buf     bytes.Buffer
+	inject  func(fr frame.Frame, role string) error
+}
+
+// Global is the process-wide hub
+var Global = New()
+
+// New creates a hub without clients
+func New() *Hub {
+	h := &Hub{clients: make(map[*client]struct{})}
+	rw, err := dialect.NewReadWriter(mavlink_custom.GetCombinedDialect())
+	if err != nil {
+		logger.Error("[WS] Failed to init dialect: %v", err)
+		return h
+	}
+	h.rw = rw
+	h.writer = &frame.Writer{ByteWriter: &h.buf, DialectRW: rw}
+	if err := h.writer.Initialize(); err != nil {
+		logger.Error("[WS] Failed to init frame writer: %v", err)
+		h.writer = nil
+	}
+	return h
+}
+
+// SetInjector sets the function that relays client frames to the FC; it gets
+// the sending client's token role and returns an error when a frame is refused
+func (h *Hub) SetInjector(fn func(fr frame.Frame, role string) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.inject = fn
+}
+
+// Publish sends a frame from the FC to every client. The frame keeps its
+// original header and checksum, so clients see exactly what the FC sent.
+func (h *Hub) Publish(fr frame.Frame) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.clients) == 0 || h.writer == nil {
+		return
+	}
+
+	// Encoding replaces the frame's message with its raw form - work on a copy
+	switch ff := fr.(type) {
+	case *frame.V2Frame:
+		c := *ff
+		fr = &c
+	case *frame.V1Frame:
+		c := *ff
+		fr = &c
+	}
+	h.buf.Reset()
+	if err := h.writer.Write(fr); err != nil {
+		logger.Debug("[WS] Failed to encode frame %d: %v", fr.GetMessage().GetID(), err)
+		return
+	}
+	data := bytes.Clone(h.buf.Bytes())
+	for c := range h.clients {
+		select {
+		case c.out <- outMsg{data: data}:
+		default:
+			c.dropped.Add(1)
+		}
+	}
+}
+
+// PublishEvent sends v as a JSON text message to the clients that asked for events
+func (h *Hub) PublishEvent(v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		logger.Debug("[WS] Failed to encode event: %v", err)
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		if !c.events {
+			continue
+		}
+		select {
+		case c.out <- outMsg{data: data, text: true}:
+		default:
+			c.dropped.Add(1)
+		}
+	}
+}
+
+// CloseAll disconnects every client, e.g. when the relay is no longer entitled
+func (h *Hub) CloseAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		c.conn.Close() // Serve cleans up when its read fails
+	}
+}
+
+// Serve runs a client until its connection closes. Each Write on conn must
+// send one binary message. Frames from the client are relayed only if canSend,
+// and the injector may still refuse them based on role.
+// With events, JSON events are sent too if conn implements TextWriter.
+func (h *Hub) Serve(conn io.ReadWriteCloser, remote, role string, canSend, events bool) error {
+	if h.rw == nil {
+		return fmt.Errorf("MAVLink dialect unavailable")
+	}
+	tw, _ := conn.(TextWriter)
+	c := &client{remote: remote, role: role, canSend: canSend, events: events && tw != nil, connected: time.Now(), out: make(chan outMsg, clientQueue), conn: conn}
+	h.mu.Lock()
+	if len(h.clients) >= maxClients {
+		h.mu.Unlock()
+		return fmt.Errorf("too many WebSocket clients (max %d)", maxClients)
+	}
+	h.clients[c] = struct{}{}
+	h.mu.Unlock()
+	logger.Info("[WS] MAVLink client %s connected (send %v, events %v)", remote, canSend, c.events)
+
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		for msg := range c.out {
+			var err error
+			if msg.text {
+				err = tw.WriteText(msg.data)
+			} else {
+				_, err = conn.Write(msg.data)
+			}
+			if err != nil {
+				conn.Close() // Unblocks the reader
+				for range c.out {
+				}
+				return
+			}
+			c.sent.Add(1)
+		}
+	}()
+
+	reader := &frame.Reader{BufByteReader: bufio.NewReader(conn), DialectRW: h.rw}
+	err := reader.Initialize()
+	for err == nil {
+		var fr frame.Frame
+		fr, err = reader.Read()
+		if err != nil {
+			var readErr frame.ReadError
+			if errors.As(err, &readErr) {
+				c.rejected.Add(1)
+				err = nil
+			}
+			continue
+		}
+		if !canSend {
+			c.rejected.Add(1)
+			continue
+		}
+		h.mu.Lock()
+		inject := h.inject
+		h.mu.Unlock()
+		if inject == nil {
+			c.rejected.Add(1)
+			continue
+		}
+		if ierr := inject(fr, c.role); ierr != nil {
+			c.rejected.Add(1)
+			logger.Debug("[WS] Frame %d from %s refused: %v", fr.GetMessage().GetID(), remote, ierr)
+			continue
+		}
+		c.received.Add(1)
+	}
+
+	h.mu.Lock()
+	delete(h.clients, c)
+	close(c.out)
+	h.mu.Unlock()
+	conn.Close()
+	<-writerDone
+	logger.Info("[WS] MAVLink client %s disconnected (%d sent, %d received, %d dropped, %d rejected)",
+		remote, c.sent.Load(), c.received.Load(), c.dropped.Load(), c.rejected.Load())
+	return nil
+}
@@ -1,30 +1,88 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
-	"regexp"
+	"reflect"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/bluenviron/gomavlib/v3"
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
 
 	"DroneBridge/config"
+	"DroneBridge/internal/alerts"
+	"DroneBridge/internal/apikeys"
+	"DroneBridge/internal/audit"
 	"DroneBridge/internal/auth"
+	"DroneBridge/internal/batch"
+	"DroneBridge/internal/battery"
 	"DroneBridge/internal/camera"
+	"DroneBridge/internal/chaos"
+	"DroneBridge/internal/control"
+	"DroneBridge/internal/deadman"
+	"DroneBridge/internal/diagnose"
+	"DroneBridge/internal/dialer"
+	"DroneBridge/internal/discovery"
+	"DroneBridge/internal/dronecan"
+	"DroneBridge/internal/entitlements"
+	"DroneBridge/internal/events"
+	"DroneBridge/internal/fcpin"
 	"DroneBridge/internal/forwarder"
+	"DroneBridge/internal/health"
+	"DroneBridge/internal/identity"
+	"DroneBridge/internal/journal"
 	"DroneBridge/internal/logger"
+	"DroneBridge/internal/maintenance"
+	"DroneBridge/internal/mavlink_custom"
+	"DroneBridge/internal/mesh"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/payload"
+	"DroneBridge/internal/policy"
+	"DroneBridge/internal/power"
+	"DroneBridge/internal/rollup"
+	"DroneBridge/internal/safety"
+	"DroneBridge/internal/scheduler"
+	"DroneBridge/internal/sensors"
+	"DroneBridge/internal/signing"
+	"DroneBridge/internal/soak"
+	"DroneBridge/internal/startup"
+	"DroneBridge/internal/storage"
+	"DroneBridge/internal/tiles"
+	"DroneBridge/internal/tokens"
+	"DroneBridge/internal/traffic"
+	"DroneBridge/internal/transform"
+	"DroneBridge/internal/upload"
+	"DroneBridge/internal/viewers"
+	"DroneBridge/internal/watchdog"
+	"DroneBridge/internal/wsproxy"
 	"DroneBridge/web"
 )
 
 func main() {
+	// Subcommand: "dronebridge diagnose [flags]" runs the commissioning checks and exits
+	diagnoseMode := len(os.Args) > 1 && os.Args[1] == "diagnose"
+	if diagnoseMode {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	// Parse command-line flags
 	configFile := flag.String("config", "config/config.yaml", "Path to configuration file")
 	logLevel := flag.String("log", "", "Log level: debug, info, warn, error (overrides config)")
 	register := flag.Bool("register", false, "Register this drone with the fleet server")
+	reRegister := flag.Bool("re-register", false, "Register again, replacing the secret the fleet server holds for this drone")
+	registerOnly := flag.Bool("register-only", false, "Exit after --register/--re-register instead of starting the bridge; the exit code reports the result")
+	registerBatch := flag.String("register-batch", "", "Register every drone UUID listed in this file (one per line) and exit")
+	jsonOutput := flag.Bool("json", false, "Print registration results as JSON on stdout (one line per drone); logs go to stderr")
 
 	// Debug overrides
 	overrideListenPort := flag.Int("listen-port", 0, "Override local UDP listen port")
@@ -33,23 +91,66 @@ func main() {
 	overrideServer := flag.String("server", "", "Override Server Host")
 	overrideServerPort := flag.Int("server-port", 0, "Override Server Port")
 	overrideBroadcastPort := flag.Int("broadcast-port", -1, "Override UDP broadcast bind port (0=random, -1=disabled/auto)")
+	soakDuration := flag.Duration("soak", 0, "DEBUG: run a soak test of this duration against a built-in FC simulator (e.g. 6h), then exit")
+	chaosMode := flag.Bool("chaos", false, "DEBUG: enable link simulation (latency/jitter/loss/IP change via /api/debug/chaos)")
 
 	// Test Mode
 	testMode := flag.Bool("test-mode", false, "Enable test mode (uses test_mode/ folder for secrets)")
 
+	// Container Mode
+	containerFlag := flag.Bool("container", false, "Container mode: DRONEBRIDGE_* env config, no interface setup, state under /data (auto-detected in Docker/Kubernetes)")
+
 	flag.Parse()
 
-	// Create logs directory if it doesn't exist
-	if err := os.MkdirAll("logs", 0755); err != nil {
-		logger.Warn("Failed to create logs directory: %v", err)
+	containerMode := *containerFlag || runningInContainer()
+	if *jsonOutput {
+		logger.UseStderr()
+	}
+
+	if diagnoseMode {
+		opts := diagnose.Options{ConfigFile: *configFile, ContainerMode: containerMode}
+		if *testMode {
+			opts.SecretDir = "test_mode"
+		}
+		report := diagnose.Run(opts)
+		report.Print(os.Stdout)
+		if !report.Passed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Create logs directory if it doesn't exist (container images may have a read-only root)
+	if !containerMode {
+		if err := os.MkdirAll("logs", 0755); err != nil {
+			logger.Warn("Failed to create logs directory: %v", err)
+		}
 	}
 
 	// Load configuration
-	logger.Info("Loading configuration from %s", *configFile)
-	cfg, err := config.Load(*configFile)
+	var cfg *config.Config
+	var err error
+	if containerMode {
+		logger.Info("📦 Container mode: loading %s (optional) with %s* environment overrides", *configFile, config.EnvPrefix)
+		cfg, err = config.LoadContainer(*configFile)
+	} else {
+		logger.Info("Loading configuration from %s", *configFile)
+		cfg, err = config.Load(*configFile)
+	}
 	if err != nil {
 		logger.Fatal("Failed to load configuration: %v", err)
 	}
+	if cfg.Paths.LogFile != "" {
+		if err := logger.SetOutputFile(cfg.Paths.LogFile); err != nil {
+			logger.Warn("Log file disabled: %v", err)
+		}
+	}
+	if cfg.Paths.SecretDir != "" {
+		if err := os.MkdirAll(cfg.Paths.SecretDir, 0700); err != nil {
+			logger.Fatal("Failed to create secret directory: %v", err)
+		}
+		auth.SetSecretDir(cfg.Paths.SecretDir)
+	}
 
 	// Apply Command Line Overrides
 	if *overrideListenPort > 0 {
@@ -75,11 +176,15 @@ func main() {
 			logger.Warn("Failed to create test_mode directory: %v", err)
 		}
 
-		// Use secret file in test_mode folder
+		// Use the secret store in test_mode folder
 		// e.g. test_mode/.drone_secret_<uuid>
-		customSecretFile := filepath.Join(testDir, fmt.Sprintf(".drone_secret_%s", cfg.Auth.UUID))
-		auth.SetSecretFileName(customSecretFile)
-		logger.Info("🧪 [TEST MODE] Using isolated secret file: %s", customSecretFile)
+		auth.SetSecretDir(testDir)
+		logger.Info("🧪 [TEST MODE] Using isolated secret store: %s", testDir)
+	}
+
+	// Move a single-drone .drone_secret into the per-UUID secret store
+	if err := auth.MigrateLegacySecret(); err != nil {
+		logger.Warn("Legacy secret not migrated: %v", err)
 	}
 	if *overrideServer != "" {
 		logger.Info("🔧 [OVERRIDE] Auth Host: %s -> %s", cfg.Auth.Host, *overrideServer)
@@ -107,13 +212,253 @@ func main() {
 		logger.SetTimestampFormat(cfg.Log.TimestampFormat)
 	}
 
+	// No UUID configured: derive one from the hardware (stable across reflashes)
+	if cfg.Auth.UUID == "" {
+		cfg.Auth.UUID = auth.ProvisionUUID()
+		logger.Info("🆔 Auto-provisioned Drone UUID from hardware: %s", cfg.Auth.UUID)
+	}
+
 	// VALIDATE UUID FORMAT
-	if !isValidUUID(cfg.Auth.UUID) {
+	if !auth.ValidUUID(cfg.Auth.UUID) {
 		logger.Fatal("❌ Invalid Drone UUID format: '%s'. strictly UUID (8-4-4-4-12 hex) required.", cfg.Auth.UUID)
 	}
 
 	logger.Info("Configuration loaded successfully (Log level: %s)", logger.GetLevelString())
 
+	// Provisioning: register and exit before any subsystem starts
+	if *registerBatch != "" || (*registerOnly && (*register || *reRegister)) {
+		os.Exit(runRegistration(cfg, *registerBatch, *reRegister, *jsonOutput))
+	}
+
+	// Drone name metadata (values edited via /api/identity override the config)
+	err = identity.Global.Load(cfg.Drone.MetadataFile, identity.Identity{
+		UUID:       cfg.Auth.UUID,
+		Name:       cfg.Drone.Name,
+		Airframe:   cfg.Drone.Airframe,
+		Operator:   cfg.Drone.Operator,
+		TailNumber: cfg.Drone.TailNumber,
+	})
+	if err != nil {
+		logger.Warn("Drone identity metadata not loaded: %v", err)
+	}
+
+	// FC pinning: other vehicles on the bench network are skipped by discovery and refused
+	if err := fcpin.Global.Configure(cfg.Ethernet.PinFC, cfg.Ethernet.PinFile); err != nil {
+		logger.Warn("FC pin not loaded: %v", err)
+	}
+
+	// Apply health thresholds and alert delivery settings
+	health.Global.SetThresholds(health.Thresholds{
+		VibrationWarn:     cfg.Health.VibrationWarn,
+		VibrationCritical: cfg.Health.VibrationCritical,
+		EKFVarianceWarn:   cfg.Health.EKFVarianceWarn,
+	})
+	traffic.Global.SetLimits(traffic.Limits{
+		HorizontalMeters: cfg.Traffic.HorizontalLimit,
+		VerticalMeters:   cfg.Traffic.VerticalLimit,
+		LookaheadSec:     cfg.Traffic.LookaheadSec,
+		StaleAfter:       time.Duration(cfg.Traffic.StaleTimeout) * time.Second,
+	})
+	alerts.Global.SetWebhook(cfg.Alerts.WebhookURL)
+	policy.Global = policy.New(cfg.Forwarding.Policies, cfg.Forwarding.Policy)
+	logger.Info("Forwarding policy: %s", policy.Global.Active())
+	if err := policy.LocalParams.Configure(cfg.Forwarding.LocalParams.Action, cfg.Forwarding.LocalParams.Rate); err != nil {
+		logger.Fatal("Invalid forwarding.local_params: %v", err)
+	}
+	profiles := make(map[string]policy.RateProfile, len(cfg.Forwarding.Profiles))
+	for name, p := range cfg.Forwarding.Profiles {
+		profiles[name] = policy.RateProfile{DefaultHz: p.DefaultHz, Rates: p.Rates}
+	}
+	if err := policy.Profiles.Configure(profiles); err != nil {
+		logger.Fatal("Invalid forwarding.profiles: %v", err)
+	}
+	if err := policy.Filters.Configure(filterRules(cfg.Forwarding.Filters)); err != nil {
+		logger.Fatal("Invalid forwarding.filters: %v", err)
+	}
+	transforms := make([]transform.Spec, 0, len(cfg.Forwarding.Transforms))
+	for _, t := range cfg.Forwarding.Transforms {
+		transforms = append(transforms, transform.Spec{Type: t.Type, Value: t.Value, Consumers: t.Consumers})
+	}
+	if err := transform.Global.Configure(transforms); err != nil {
+		logger.Fatal("Invalid forwarding.transforms: %v", err)
+	}
+	if err := transform.Privacy.Set(cfg.Forwarding.Privacy.Enabled, cfg.Forwarding.Privacy.PrecisionM); err != nil {
+		logger.Fatal("Invalid forwarding.privacy: %v", err)
+	}
+	if cfg.Forwarding.Privacy.Enabled {
+		logger.Info("Privacy mode enabled: cloud positions rounded to %gm", cfg.Forwarding.Privacy.PrecisionM)
+	}
+	if err := control.Global.Configure(cfg.Control.Arbitration, time.Duration(cfg.Control.HoldTime)*time.Second); err != nil {
+		logger.Fatal("Invalid control arbitration: %v", err)
+	}
+	control.Global.ConfigureGuard(cfg.Control.RCOverrideGuard)
+	safety.Global.Configure(cfg.Safety.FreezeParamsInFlight, cfg.Safety.ParamAllowlist)
+
+	if err := audit.Global.Open(cfg.Audit.File); err != nil {
+		logger.Warn("Audit log disabled: %v", err)
+	}
+
+	// Companion payload outputs, switched off until commanded
+	registerPayloadActions(cfg)
+
+	// Restore counters from the last checkpoint so totals survive restarts
+	if err := metrics.Global.Restore(cfg.Metrics.CheckpointFile); err != nil {
+		logger.Warn("Metrics checkpoint not restored: %v", err)
+	}
+	servicesStop := make(chan struct{})
+	go metrics.Global.RunCheckpoints(cfg.Metrics.CheckpointFile, time.Duration(cfg.Metrics.CheckpointInterval)*time.Second, servicesStop)
+
+	// Daily and per-flight statistics rollups (baseline taken after the restore above)
+	if err := rollup.Global.Configure(cfg.Metrics.RollupFile, cfg.Metrics.RollupDays); err != nil {
+		logger.Warn("Statistics rollups not restored: %v", err)
+	}
+	go rollup.Global.Run(time.Minute, servicesStop)
+
+	// Service reminders from the accumulated armed time
+	reminders := make([]maintenance.Reminder, 0, len(cfg.Maintenance.Reminders))
+	for _, rem := range cfg.Maintenance.Reminders {
+		reminders = append(reminders, maintenance.Reminder{Item: rem.Item, Hours: rem.Hours, Flights: rem.Flights})
+	}
+	if err := maintenance.Global.Configure(cfg.Maintenance.File, reminders); err != nil {
+		logger.Warn("Maintenance log not restored: %v", err)
+	}
+	maintenance.Global.Check()
+	go maintenance.Global.Run(time.Minute, servicesStop)
+	maintenance.Mode.SetLimits(time.Duration(cfg.Maintenance.ModeMinutes)*time.Minute, time.Duration(cfg.Maintenance.ModeMaxMinutes)*time.Minute)
+
+	// Battery packs across flights
+	if err := battery.Global.Configure(cfg.Battery.File, cfg.Battery.SagFactor); err != nil {
+		logger.Warn("Battery history not restored: %v", err)
+	}
+
+	// Dead-man uplink watchdog (actions that need the FC or cameras check for them when run)
+	recordDir := cfg.Deadman.RecordDir
+	if !filepath.IsAbs(recordDir) {
+		recordDir = filepath.Join(cfg.Storage.DataDir, recordDir)
+	}
+	deadman.Global.RegisterAction("statustext", deadman.Action{
+		Run: func() error {
+			return web.SendStatusText(common.MAV_SEVERITY_CRITICAL, "Bridge: ground link lost")
+		},
+		Restore: func() {
+			if err := web.SendStatusText(common.MAV_SEVERITY_NOTICE, "Bridge: ground link restored"); err != nil {
+				logger.Warn("[DEADMAN] %v", err)
+			}
+		},
+	})
+	deadman.Global.RegisterAction("rtl", deadman.Action{Run: web.ReturnToLaunch})
+	deadman.Global.RegisterAction("record", deadman.Action{
+		Run: func() error {
+			if !cfg.Features.Camera {
+				return fmt.Errorf("camera feature disabled")
+			}
+			return camera.StartRecording(recordDir)
+		},
+		Restore: func() {
+			if err := camera.StopRecording(); err != nil {
+				logger.Warn("[DEADMAN] %v", err)
+			}
+		},
+	})
+	if err := deadman.Global.Configure(cfg.Deadman.Enabled, time.Duration(cfg.Deadman.Timeout)*time.Second, cfg.Deadman.Actions); err != nil {
+		logger.Fatal("Invalid deadman config: %v", err)
+	}
+	go deadman.Global.Run(5*time.Second, servicesStop)
+
+	// Disk-space guard and pruning
+	storageCfg := storage.Config{
+		DataDir:    cfg.Storage.DataDir,
+		MinFree:    uint64(cfg.Storage.MinFreeMB) << 20,
+		TargetFree: uint64(cfg.Storage.TargetFreeMB) << 20,
+		Interval:   time.Duration(cfg.Storage.CheckInterval) * time.Second,
+	}
+	for _, cl := range cfg.Storage.Classes {
+		storageCfg.Classes = append(storageCfg.Classes, storage.Class{
+			Name:     cl.Name,
+			Dir:      cl.Dir,
+			Pattern:  cl.Pattern,
+			Priority: cl.Priority,
+			MaxAge:   time.Duration(cl.RetentionDays) * 24 * time.Hour,
+		})
+	}
+	storage.Global.Configure(storageCfg)
+	go storage.Global.Run(servicesStop)
+
+	// Flight data journal for time-windowed exports
+	if cfg.Journal.Enabled {
+		dir := cfg.Journal.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(cfg.Storage.DataDir, dir)
+		}
+		if err := journal.Global.Configure(dir, time.Duration(cfg.Journal.SampleInterval)*time.Second); err != nil {
+			logger.Warn("Flight journal disabled: %v", err)
+		} else {
+			alerts.Global.SetObserver(func(a alerts.Alert) {
+				journal.Global.Record(journal.TypeEvent, map[string]interface{}{
+					"event": "alert", "source": a.Source, "severity": a.Severity, "message": a.Message,
+				})
+			})
+		}
+	}
+
+	// Map tile cache so dashboard maps work without internet in the field
+	if cfg.Tiles.Enabled {
+		dir := cfg.Tiles.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(cfg.Storage.DataDir, dir)
+		}
+		if err := tiles.Global.Configure(tiles.Config{
+			Dir:       dir,
+			Upstream:  cfg.Tiles.Upstream,
+			MaxAge:    time.Duration(cfg.Tiles.MaxAgeDays) * 24 * time.Hour,
+			MaxSeed:   cfg.Tiles.MaxSeed,
+			UserAgent: cfg.Tiles.UserAgent,
+		}); err != nil {
+			logger.Warn("Tile cache disabled: %v", err)
+		} else if seed := cfg.Tiles.Seed; seed.MaxZoom > 0 {
+			area := tiles.Area{MinLat: seed.MinLat, MinLon: seed.MinLon, MaxLat: seed.MaxLat, MaxLon: seed.MaxLon}
+			if err := tiles.Global.StartSeed(area, seed.MinZoom, seed.MaxZoom); err != nil {
+				logger.Warn("Tile pre-seed skipped: %v", err)
+			}
+		}
+	}
+
+	// Upload of completed tlogs, video segments and diagnostics bundles
+	if cfg.Upload.Enabled {
+		upload.Global.Configure(upload.Config{
+			Classes:         cfg.Upload.Classes,
+			Prefix:          cfg.Upload.Prefix,
+			DroneUUID:       cfg.Auth.UUID,
+			BytesPerSecond:  int64(cfg.Upload.MaxKbps) * 1000 / 8,
+			WiFiInterface:   cfg.Upload.WiFiInterface,
+			RequireDisarmed: cfg.Upload.RequireDisarmed,
+			Settle:          time.Duration(cfg.Upload.SettleTime) * time.Second,
+			Interval:        time.Duration(cfg.Upload.Interval) * time.Second,
+		}, &upload.S3{
+			Endpoint:  cfg.Upload.Endpoint,
+			Region:    cfg.Upload.Region,
+			Bucket:    cfg.Upload.Bucket,
+			AccessKey: cfg.Upload.AccessKey,
+			SecretKey: cfg.Upload.SecretKey,
+		})
+		upload.Global.SetVehicleState(web.VehicleArmed)
+		go upload.Global.Run(servicesStop)
+	}
+
+	// Watchdog for stuck event loops (loops register themselves when they start)
+	watchdog.Global.SetTimeout(time.Duration(cfg.Watchdog.StallTimeout) * time.Second)
+	go watchdog.Global.Run(servicesStop)
+
+	// SESSION_HEARTBEAT IDs and layout; set before any uplink dialect is built
+	heartbeatIdentity := mavlink_custom.Identity{
+		HeartbeatID:    cfg.Auth.SessionHeartbeatID,
+		HeartbeatAckID: cfg.Auth.SessionHeartbeatAckID,
+		Layout:         uint8(cfg.Auth.SessionHeartbeatLayout),
+	}
+	if _, err := mavlink_custom.SetIdentity(heartbeatIdentity); err != nil {
+		logger.Fatal("Invalid session heartbeat identity: %v", err)
+	}
+
 	// Create single auth client instance - will be reused for both registration and normal operation
 	authClient := auth.NewClient(
 		cfg.Auth.Host,
@@ -122,18 +467,103 @@ func main() {
 		cfg.Auth.SharedSecret,
 		cfg.Auth.KeepaliveInterval,
 	)
+	authClient.SetReauthLimits(time.Duration(cfg.Auth.StartupJitter)*time.Second, cfg.Auth.MaxReauthPerMinute)
+	authClient.SetAPIKeyStatusTTL(time.Duration(cfg.Auth.APIKeyCacheTTL) * time.Second)
+	if cfg.Auth.PolicyReportInterval > 0 {
+		authClient.SetPolicyReporter(time.Duration(cfg.Auth.PolicyReportInterval)*time.Second, policyReport)
+	}
+	authClient.SetHeartbeatOffer(heartbeatIdentity.HeartbeatID, heartbeatIdentity.HeartbeatAckID, mavlink_custom.SupportedLayouts)
+	if cfg.Auth.Trace {
+		authClient.SetTrace(true, func(fields map[string]interface{}) {
+			journal.Global.Record(journal.TypeAuth, fields)
+		})
+	}
+	// Session and API key events go to /api/events and the dashboard WebSocket
+	events.Global.SetObserver(func(ev events.Event) { wsproxy.Global.PublishEvent(ev) })
+	authClient.SetEventSink(events.Global.Record)
+	if cfg.Features.Video {
+		authClient.OnVideoControl = handleVideoControl
+	}
+	authClient.OnUserConnect = viewers.Global.Connect
+	authClient.OnUserLeave = viewers.Global.Disconnect
+	// Maintenance mode: the forwarder holds telemetry back itself; the router is told and the dead-man waits
+	maintenance.Mode.SetObserver(func(st maintenance.ModeStatus) {
+		deadman.Global.Suspend(st.Active)
+		if st.Active {
+			authClient.SetStatus(auth.StatusMaintenance, *st.Until)
+		} else {
+			authClient.SetStatus(0, time.Time{})
+		}
+	})
+	if cfg.Forwarding.Batching.Enabled && cfg.Network.Protocol != "quic" {
+		authClient.SetCompressionCaps(batch.SupportedAlgorithms)
+	}
+	if *chaosMode {
+		chaos.Global.Enable()
+		authClient.SetDialer(chaos.Global.Dialer(dialer.Real))
+	}
+	// MAVLink 2 signing key: loaded from disk, provisioned and rotated by the router
+	err = signing.Global.Configure(signing.Config{
+		Enabled:         cfg.Signing.Enabled,
+		KeyFile:         cfg.Signing.KeyFile,
+		LinkID:          uint8(cfg.Signing.LinkID),
+		Window:          time.Duration(cfg.Signing.TimestampWindow) * time.Second,
+		RequireIncoming: cfg.Signing.RequireIncoming,
+		SignToFC:        cfg.Signing.SignToFC,
+	})
+	if err != nil {
+		logger.Fatal("Failed to set up MAVLink signing: %v", err)
+	}
+	if cfg.Signing.Enabled {
+		authClient.OnSigningKey = func(key []byte) error {
+			return signing.Global.SetKey(key, "router")
+		}
+	}
+	// Premium subsystems follow the feature list the fleet server signs for this drone
+	if err := entitlements.Global.Configure(cfg.Entitlements.File, authClient.VerifyEntitlements); err != nil {
+		logger.Warn("[STARTUP] Ignoring cached entitlements: %v", err)
+	}
+	authClient.OnEntitlements = entitlements.Global.Apply
+	camera.SetStartGate(func() error {
+		if !entitlements.Global.Allowed(entitlements.FeatureVideo) {
+			return fmt.Errorf("video is not entitled")
+		}
+		return nil
+	})
+	entitlements.Global.SetObserver(func(feature string, allowed bool) {
+		applyEntitlement(cfg, feature, allowed)
+	})
+	go entitlements.Global.Run(servicesStop)
+	if cfg.Auth.Mode == "mtls" {
+		tlsCfg, err := auth.LoadTLSConfig(cfg.Auth.TLS.CertFile, cfg.Auth.TLS.KeyFile, cfg.Auth.TLS.CAFile, cfg.Auth.TLS.ServerName)
+		if err != nil {
+			logger.Fatal("Failed to load mTLS identity: %v", err)
+		}
+		authClient.SetTLS(tlsCfg)
+		logger.Info("Auth mode: mtls (client certificate %s)", cfg.Auth.TLS.CertFile)
+	}
 
 	// Handle registration mode - SEPARATE from auth
+	if *reRegister {
+		*register = true
+	}
+	if *register && !cfg.Features.Auth {
+		logger.Fatal("❌ Registration requires the auth feature (features.auth: false)")
+	}
 	if *register {
 		logger.Info("🚀 STARTING REGISTRATION PROCESS")
 		logger.Info("Connecting to %s:%d", cfg.Auth.Host, cfg.Auth.Port)
 
-		if err := authClient.Register(); err != nil {
-			logger.Fatal("❌ Registration failed: %v", err)
+		res, err := authClient.Register(*reRegister)
+		if *jsonOutput {
+			json.NewEncoder(os.Stdout).Encode(newRegistrationReport(cfg.Auth.UUID, res, err))
+		}
+		if err != nil {
+			logger.Error("❌ Registration failed: %v", err)
+			os.Exit(registerExitCode(err))
 		}
 
 		logger.Info("✅ Registration completed successfully!")
-		logger.Info("Secret key has been saved to .drone_secret")
 		logger.Info("Registration connection will be closed, then proceeding with authentication...")
 
 		// IMPORTANT: Registration creates its own TCP connection and closes it automatically
@@ -146,10 +576,43 @@ func main() {
 
 	// STEP 0: Discover Pixhawk (Transient Phase)
 	logger.Info("[STARTUP] ⏳ Entering Discovery Phase...")
-	discoveredIP, discoveredPort, discoveredSysID, discErr := forwarder.DiscoverPixhawk(cfg, time.Duration(cfg.Ethernet.PixhawkConnectionTimeout)*time.Second)
+	var discoveredIP string
+	var discoveredPort int
+	var discoveredSysID uint8
+	var discErr error
+	serialLink := cfg.Serial.Enabled && *soakDuration == 0
+	if *soakDuration > 0 {
+		// The simulator is the flight controller - no discovery needed
+		soakCfg := soak.Config{
+			Duration:           *soakDuration,
+			Rate:               cfg.Soak.Rate,
+			ListenPort:         cfg.Network.LocalListenPort,
+			ReconnectInterval:  time.Duration(cfg.Soak.ReconnectInterval) * time.Second,
+			SampleInterval:     time.Duration(cfg.Soak.SampleInterval) * time.Second,
+			Warmup:             time.Duration(cfg.Soak.Warmup) * time.Second,
+			MaxGoroutineGrowth: cfg.Soak.MaxGoroutineGrowth,
+			MaxHeapGrowthMB:    cfg.Soak.MaxHeapGrowthMB,
+			MaxFDGrowth:        cfg.Soak.MaxFDGrowth,
+			MaxQueueDepth:      cfg.Soak.MaxQueueDepth,
+			ReportFile:         cfg.Soak.ReportFile,
+		}
+		logger.Warn("[SOAK] ⚠️ Soak test for %v - DEBUG ONLY, do not run with a flight controller attached", *soakDuration)
+		discoveredIP, discoveredSysID = "127.0.0.1", 1
+		discoveredPort, discErr = soak.Global.StartSimulator(soakCfg, servicesStop)
+		if discErr != nil {
+			logger.Fatal("[SOAK] Failed to start simulator: %v", discErr)
+		}
+	} else if serialLink {
+		// UART link: nothing to discover, the FC's first heartbeat gives its system ID
+		discErr = fmt.Errorf("serial link on %s, waiting for heartbeat", cfg.Serial.Device)
+	} else {
+		discoveredIP, discoveredPort, discoveredSysID, discErr = forwarder.DiscoverPixhawk(cfg, time.Duration(cfg.Ethernet.PixhawkConnectionTimeout)*time.Second)
+	}
 
 	var listenerNode *gomavlib.Node
-	if discErr == nil {
+	if serialLink {
+		listenerNode, err = forwarder.NewListener(cfg, "", 0)
+	} else if discErr == nil {
 		logger.Info("[STARTUP] ✅ Pixhawk discovered at %s:%d (System ID: %d)", discoveredIP, discoveredPort, discoveredSysID)
 		// Register found SysID with web bridge early
 		web.HandleHeartbeat(discoveredSysID)
@@ -178,14 +641,20 @@ func main() {
 	pixhawkConnected := (discErr == nil)
 	pixhawkSysID := discoveredSysID
 
-	if !pixhawkConnected && cfg.Ethernet.AllowMissingPixhawk {
-		// We need to wait for a heartbeat if we fell back to broadcast
-		logger.Info("[STARTUP] ⏳ Waiting for Pixhawk heartbeat via Broadcast fallback...")
+	if !pixhawkConnected && (cfg.Ethernet.AllowMissingPixhawk || serialLink) {
+		// We need to wait for a heartbeat if we fell back to broadcast or use the UART
+		heartbeatWait := 10 * time.Second // Small additional timeout
+		if serialLink {
+			heartbeatWait = time.Duration(cfg.Ethernet.PixhawkConnectionTimeout) * time.Second
+			logger.Info("[STARTUP] ⏳ Waiting for Pixhawk heartbeat on %s...", cfg.Serial.Device)
+		} else {
+			logger.Info("[STARTUP] ⏳ Waiting for Pixhawk heartbeat via Broadcast fallback...")
+		}
 		pixhawkReadyCh := make(chan struct{})
 
 		go func() {
 			eventCh := listenerNode.Events()
-			timeout := time.NewTimer(10 * time.Second) // Small additional timeout
+			timeout := time.NewTimer(heartbeatWait)
 			defer timeout.Stop()
 
 			for {
@@ -206,6 +675,14 @@ func main() {
 			}
 		}()
 		<-pixhawkReadyCh
+		if !pixhawkConnected && serialLink && !cfg.Ethernet.AllowMissingPixhawk {
+			logger.Fatal("[STARTUP] ❌ No Pixhawk heartbeat on %s at %d baud. Check wiring and SERIALx_PROTOCOL/SERIALx_BAUD, or set 'allow_missing_pixhawk: true' to skip.",
+				cfg.Serial.Device, cfg.Serial.Baud)
+		}
+		if !pixhawkConnected {
+			logger.Warn("[STARTUP] ⚠️  No Pixhawk heartbeat, running in bench mode (FC data unavailable)")
+			web.SetBenchMode(true)
+		}
 	}
 
 	// STEP 2: Now create full forwarder (with sender node using correct SysID)
@@ -214,6 +691,10 @@ func main() {
 	if err != nil {
 		logger.Fatal("Failed to create forwarder: %v", err)
 	}
+	web.SetFCWriter(fwd.WriteToFC)
+	if *chaosMode {
+		fwd.SetDialer(chaos.Global.Dialer(dialer.Real))
+	}
 
 	// STEP 3: Start forwarder
 	logger.Info("[STARTUP] Starting forwarder...")
@@ -221,31 +702,61 @@ func main() {
 		logger.Fatal("Failed to start forwarder: %v", err)
 	}
 
-	// STEP 4: Authenticate with server
-	logger.Info("[STARTUP] ✈️  Now proceeding with server authentication...")
+	// External sensors are injected into the FC link and telemetry stream by the forwarder
+	if len(cfg.Sensors.Inputs) > 0 {
+		registerSensors(cfg)
+		sensors.Global.SetPublisher(fwd.Inject)
+		go sensors.Global.Run(servicesStop)
+	}
 
-	// Start auth client
-	if err := authClient.Start(); err != nil {
-		logger.Fatal("Failed to start auth client: %v", err)
+	// Companion supply voltage: undervoltage ends the run below with an OS shutdown
+	if cfg.Power.Enabled {
+		if src, err := openPowerSource(cfg.Power); err != nil {
+			logger.Warn("[STARTUP] Power monitor disabled: %v", err)
+		} else {
+			power.Global.Configure(power.Config{
+				Source:     src,
+				Scale:      cfg.Power.Scale,
+				Offset:     cfg.Power.Offset,
+				Interval:   time.Duration(cfg.Power.Interval) * time.Millisecond,
+				MinVoltage: cfg.Power.MinVoltage,
+				Samples:    cfg.Power.Samples,
+				WhenArmed:  cfg.Power.ShutdownWhenArmed,
+			})
+			go power.Global.Run(servicesStop)
+		}
 	}
 
-	// Wait for auth client to be authenticated (max 10 seconds)
-	logger.Info("Waiting for auth client to authenticate with router...")
-	for i := 0; i < 100; i++ {
-		if authClient.IsAuthenticated() {
-			logger.Info("✅ Auth client authenticated with router")
-			break
+	// STEP 4: Authenticate with server
+	logger.Info("[STARTUP] ✈️  Now proceeding with server authentication...")
+
+	if cfg.Features.Auth {
+		// Start auth client
+		if err := authClient.Start(); err != nil {
+			logger.Fatal("Failed to start auth client: %v", err)
 		}
-		time.Sleep(100 * time.Millisecond)
-		if i == 99 {
-			logger.Warn("⚠️ Auth client authentication timeout (10s), continuing anyway")
+
+		// Wait for auth client to be authenticated (max 10 seconds)
+		logger.Info("Waiting for auth client to authenticate with router...")
+		for i := 0; i < 100; i++ {
+			if authClient.IsAuthenticated() {
+				logger.Info("✅ Auth client authenticated with router")
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+			if i == 99 {
+				logger.Warn("⚠️ Auth client authentication timeout (10s), continuing anyway")
+			}
 		}
+	} else {
+		logger.Info("[STARTUP] Auth disabled by features, running as local telemetry relay")
 	}
 
 	// STEP 5: Initialize video streaming
-	logger.Info("[STARTUP] 📹 Initializing video streaming...")
-
-	if cfg.Camera.Enabled {
+	if !cfg.Features.Camera {
+		logger.Info("[STARTUP] Camera disabled by features")
+	} else if cfg.Camera.Enabled {
+		logger.Info("[STARTUP] 📹 Initializing video streaming...")
 		// Convert YAML config to streaming config
 		streamingCfg := &camera.StreamingConfig{
 			CameraID:         cfg.Camera.CameraID,
@@ -257,15 +768,48 @@ func main() {
 			DroneID:          cfg.Auth.UUID, // Use auth UUID automatically
 			Bitrate:          cfg.Camera.Encoder.Bitrate,
 			OverlayEnabled:   cfg.Camera.Features.Overlay,
-			DetectionEnabled: cfg.Camera.Features.Detection,
+			DetectionEnabled: cfg.Camera.Features.Detection && cfg.Features.Landing && entitlements.Global.Allowed(entitlements.FeaturePrecisionLanding),
 			KeyframeInterval: cfg.Camera.Encoder.KeyframeInterval,
 			Preset:           cfg.Camera.Encoder.Preset,
 			Tune:             cfg.Camera.Encoder.Tune,
+			Encoder:          cfg.Camera.Encoder.Type,
 			Enabled:          cfg.Camera.Enabled,
+			PathTemplate:     cfg.Camera.MediaMTX.PathTemplate,
+			OrgID:            cfg.Camera.MediaMTX.OrgID,
+			PublishAuth:      cfg.Camera.MediaMTX.PublishAuth,
+			PublishUser:      cfg.Camera.MediaMTX.Username,
+			PublishPass:      cfg.Camera.MediaMTX.Password,
+			Output:           cfg.Camera.Output,
+			SRTHost:          cfg.Camera.SRT.Host,
+			SRTPort:          cfg.Camera.SRT.Port,
+			SRTLatency:       cfg.Camera.SRT.Latency,
+			SRTPassphrase:    cfg.Camera.SRT.Passphrase,
+			SRTKeyLength:     cfg.Camera.SRT.KeyLength,
+			AudioEnabled:     cfg.Camera.Audio.Enabled,
+			AudioSource:      cfg.Camera.Audio.Source,
+			AudioDevice:      cfg.Camera.Audio.Device,
+			AudioSampleRate:  cfg.Camera.Audio.SampleRate,
+			AudioChannels:    cfg.Camera.Audio.Channels,
+			AudioCodec:       cfg.Camera.Audio.Codec,
+			AudioBitrate:     cfg.Camera.Audio.Bitrate,
 		}
+		camera.SetPublishTokenSource(func() (string, error) {
+			status, err := authClient.GetAPIKeyStatus()
+			if err != nil {
+				return "", err
+			}
+			if status.HasActiveKey != 0x01 || status.APIKey == "" {
+				return "", fmt.Errorf("no active API key")
+			}
+			return status.APIKey, nil
+		})
 
 		if err := camera.InitializeFromConfig(streamingCfg, cfg.Auth.Host, cfg.Auth.UUID); err != nil {
 			logger.Warn("[STARTUP] Failed to initialize camera: %v", err)
+		} else if !cfg.Features.Video {
+			logger.Info("[STARTUP] Camera loaded, video streaming disabled by features")
+		} else if cfg.Camera.OnDemand {
+			logger.Info("[STARTUP] Camera loaded, streaming starts when the router requests it")
 		} else {
 			if err := camera.StartAllCameras(); err != nil {
 				logger.Warn("[STARTUP] Failed to start cameras: %v", err)
@@ -278,35 +822,742 @@ func main() {
 	}
 
 	// Start web server with auth client and drone UUID
-	web.StartServer(cfg.Web.Port, authClient, cfg.Auth.UUID)
+	web.SetProfileDir(cfg.Params.ProfileDir)
+	web.SetFirmwareSettings(web.FirmwareSettings{
+		UploadDir:  cfg.Firmware.UploadDir,
+		Uploader:   cfg.Firmware.Uploader,
+		SerialPort: cfg.Firmware.SerialPort,
+		MaxSize:    int64(cfg.Firmware.MaxImageMB) << 20,
+	})
+	if err := tokens.Global.Load(cfg.Tokens.File, cfg.Tokens.AdminToken); err != nil {
+		logger.Warn("API tokens not loaded: %v", err)
+	}
+	// The per-feature tokens are admin credentials for their own routes only
+	tokens.Global.SetScoped([]tokens.Scoped{
+		{Key: "files.api_token", Secret: cfg.Files.APIToken, Prefix: "/api/files", Role: tokens.RoleAdmin},
+		{Key: "control.api_token", Secret: cfg.Control.APIToken, Prefix: "/api/control", Role: tokens.RoleAdmin},
+	})
+	web.SetTokensRequired(cfg.Tokens.Required)
+	web.SetWSAllowedOrigins(cfg.Web.WSAllowedOrigins)
+	web.SetFileSettings(web.FileSettings{
+		Root:        cfg.Files.Root,
+		MaxFileSize: int64(cfg.Files.MaxFileMB) << 20,
+		Quota:       int64(cfg.Files.QuotaMB) << 20,
+	})
+	web.SetGuidedSettings(web.GuidedSettings{
+		MaxDistance: cfg.Guided.MaxDistance,
+		MaxAltitude: cfg.Guided.MaxAltitude,
+		MaxSpeed:    cfg.Guided.MaxSpeed,
+	})
+	webAuth := authClient
+	if !cfg.Features.Auth {
+		webAuth = nil
+	}
+	if cfg.Features.Web {
+		if webAuth != nil {
+			apikeys.Global.SetClient(webAuth)
+			go apikeys.Global.Run(servicesStop)
+		}
+		web.StartServer(cfg.Web.Port, webAuth, cfg.Auth.UUID)
+		dronecan.Global.SetInfoRequester(web.RequestCANNodeInfo)
+	} else if containerMode {
+		logger.Info("[STARTUP] Web server disabled by features, serving probes only")
+		web.StartProbeServer(cfg.Web.Port, webAuth)
+	} else {
+		logger.Info("[STARTUP] Web server disabled by features")
+	}
 
 	// Now set auth client on forwarder and re-wire callbacks
-	fwd.SetAuthClient(authClient)
+	if cfg.Features.Auth {
+		fwd.SetAuthClient(authClient)
+		authClient.OnConfigPush = func(overlay []byte) (string, error) {
+			return applyRemoteConfig(cfg, fwd, *configFile, containerMode, overlay)
+		}
+	}
+
+	// Scheduled maintenance tasks
+	registerScheduledActions(cfg, authClient)
+	for _, t := range cfg.Schedule.Tasks {
+		err := scheduler.Global.Add(scheduler.Task{
+			Name:   t.Name,
+			Action: t.Action,
+			Every:  time.Duration(t.Every) * time.Second,
+			At:     t.At,
+		})
+		if err != nil {
+			logger.Fatal("Invalid scheduled task: %v", err)
+		}
+	}
+	go scheduler.Global.Run(servicesStop)
+
+	// Position exchange with other bridges on the local network
+	if cfg.Mesh.Enabled {
+		mesh.Global.Configure(mesh.Config{
+			Port:        cfg.Mesh.Port,
+			BroadcastIP: cfg.Mesh.BroadcastIP,
+			Key:         cfg.Mesh.Key,
+			Interval:    time.Duration(cfg.Mesh.Interval) * time.Millisecond,
+			StaleAfter:  time.Duration(cfg.Mesh.StaleTimeout) * time.Second,
+		}, cfg.Auth.UUID, identity.Global.DisplayName)
+		go func() {
+			if err := mesh.Global.Run(servicesStop); err != nil {
+				logger.Warn("[STARTUP] Peer mesh disabled: %v", err)
+			}
+		}()
+	}
+
+	// Discovery beacon for ground tools; runs even when disabled so /api/discovery can switch it on
+	webPort := 0
+	if cfg.Features.Web {
+		webPort = cfg.Web.Port
+	}
+	discovery.Global.Configure(discovery.Config{
+		Enabled:     cfg.Discovery.Enabled,
+		Port:        cfg.Discovery.Port,
+		BroadcastIP: cfg.Discovery.BroadcastIP,
+		Interval:    time.Duration(cfg.Discovery.Interval) * time.Second,
+		WebPort:     webPort,
+		MAVLinkPort: cfg.Network.LocalListenPort,
+	}, cfg.Auth.UUID, identity.Global.DisplayName)
+	go func() {
+		if err := discovery.Global.Run(servicesStop); err != nil {
+			logger.Warn("[STARTUP] Discovery beacon disabled: %v", err)
+		}
+	}()
+	pixhawkAddress := ""
+	if discErr == nil {
+		pixhawkAddress = fmt.Sprintf("%s:%d", discoveredIP, discoveredPort)
+	} else if serialLink && pixhawkConnected {
+		pixhawkAddress = fmt.Sprintf("serial %s @ %d baud", cfg.Serial.Device, cfg.Serial.Baud)
+	}
+	startup.Publish(startup.Build(cfg, startup.Facts{
+		ConfigFile:     *configFile,
+		ContainerMode:  containerMode,
+		PixhawkAddress: pixhawkAddress,
+		PixhawkSysID:   pixhawkSysID,
+	}))
+	web.SetReady()
 
 	// Wait for interrupt signal
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 
+	// A soak run ends the process by itself once it passes or fails
+	var soakDone <-chan soak.Report
+	if soak.Global.Active() {
+		soakDone = soak.Global.Run(soak.Hooks{
+			QueueDepths: fwd.QueueDepths,
+			Reconnect:   fwd.TriggerReconnect,
+		}, servicesStop)
+	}
+	soakFailed := false
+	powerOff := false
+
 	logger.Info("MAVLink forwarder running. Press Ctrl+C to stop.")
-	<-sigCh
+	select {
+	case <-sigCh:
+	case report := <-soakDone:
+		soakFailed = !report.Passed
+	case <-power.Global.Undervoltage():
+		powerOff = true
+	}
 
 	// Graceful shutdown
 	logger.Info("[SHUTDOWN] Initiating graceful shutdown...")
+	if powerOff {
+		// Tell the pilot while the FC link is still up
+		if err := web.SendStatusText(common.MAV_SEVERITY_CRITICAL, "Bridge: low supply voltage, shutting down"); err != nil {
+			logger.Warn("[SHUTDOWN] Failed to notify FC: %v", err)
+		}
+	}
 
 	// Stop cameras first
-	camera.GracefulShutdown()
+	if cfg.Features.Camera {
+		camera.GracefulShutdown()
+	}
 
 	// Stop forwarder
 	fwd.Stop()
 
+	// Close open viewer sessions so their time is accounted
+	viewers.Global.CloseAll("shutdown")
+
+	// Leave no payload output energised
+	payload.Global.AllOff()
+	journal.Global.Close()
+
+	// Persist final counters
+	close(servicesStop)
+	if err := metrics.Global.Checkpoint(cfg.Metrics.CheckpointFile); err != nil {
+		logger.Warn("[SHUTDOWN] Failed to checkpoint metrics: %v", err)
+	}
+	rollup.Global.Flush()
+
 	// Cleanup resources
-	camera.Cleanup()
+	if cfg.Features.Camera {
+		camera.Cleanup()
+	}
 
 	logger.Info("[SHUTDOWN] ✅ Complete")
+	if powerOff {
+		if err := power.PowerOff(cfg.Power.ShutdownCommand); err != nil {
+			logger.Error("[SHUTDOWN] OS shutdown failed: %v", err)
+			os.Exit(1)
+		}
+	}
+	if soakFailed {
+		os.Exit(1)
+	}
+}
+
+// handleVideoControl applies a router VIDEO_CONTROL message to the cameras
+func handleVideoControl(vc *auth.VideoControl) error {
+	action := map[byte]string{auth.VideoStart: "start", auth.VideoStop: "stop", auth.VideoReconfigure: "reconfigure"}[vc.Action]
+	cameraID := int(vc.CameraID)
+	if vc.CameraID == auth.VideoAllCameras {
+		cameraID = -1
+	}
+	logger.Info("[VIDEO] Router requested %s (camera %d)", action, cameraID)
+	err := camera.ControlCameras(action, cameraID, camera.StreamSettings{
+		Width:       int(vc.Width),
+		Height:      int(vc.Height),
+		Framerate:   int(vc.Framerate),
+		BitrateKbps: int(vc.BitrateKbps),
+	})
+	if err != nil {
+		logger.Warn("[VIDEO] Router %s failed: %v", action, err)
+	}
+	return err
 }
 
-// isValidUUID checks if the string is a valid UUID
-func isValidUUID(u string) bool {
-	r := regexp.MustCompile("^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}$")
-	return r.MatchString(u)
+// applyEntitlement starts or stops a premium subsystem when its entitlement is
+// granted or revoked (new list from the router, or the current one expired)
+func applyEntitlement(cfg *config.Config, feature string, allowed bool) {
+	cameras := cfg.Features.Camera && cfg.Camera.Enabled
+	switch feature {
+	case entitlements.FeatureVideo:
+		if !cameras || !cfg.Features.Video {
+			return
+		}
+		if !allowed {
+			if err := camera.ControlCameras("stop", -1, camera.StreamSettings{}); err != nil {
+				logger.Warn("[ENTITLEMENTS] Failed to stop video: %v", err)
+			}
+		} else if !cfg.Camera.OnDemand {
+			if err := camera.StartAllCameras(); err != nil {
+				logger.Warn("[ENTITLEMENTS] Failed to start video: %v", err)
+			}
+		}
+	case entitlements.FeaturePrecisionLanding:
+		if !cameras {
+			return
+		}
+		if err := camera.SetDetection(cfg.Camera.Features.Detection && cfg.Features.Landing && allowed); err != nil {
+			logger.Warn("[ENTITLEMENTS] Failed to apply landing detection: %v", err)
+		}
+	case entitlements.FeatureRelay:
+		if !allowed {
+			wsproxy.Global.CloseAll()
+		}
+	}
+}
+
+// filterRules converts forwarding.filters to the policy filter table
+func filterRules(filters []config.FilterRuleConfig) []policy.FilterRule {
+	rules := make([]policy.FilterRule, 0, len(filters))
+	for _, f := range filters {
+		rules = append(rules, policy.FilterRule{SystemID: uint8(f.SysID), Messages: f.Messages, Action: f.Action, MaxHz: f.MaxHz})
+	}
+	return rules
+}
+
+// policyReport is the forwarding configuration in effect, as reported to the router in POLICY_REPORT
+func policyReport() ([]byte, error) {
+	privacy := transform.Privacy.Status()
+	return json.Marshal(struct {
+		policy.Config
+		Transforms      []transform.Spec `json:"transforms"`
+		Privacy         bool             `json:"privacy"`
+		PrivacyMeters   float64          `json:"privacyMeters,omitempty"`
+		MaintenanceMode bool             `json:"maintenanceMode"`
+	}{
+		Config:          policy.Current(),
+		Transforms:      transform.Global.Specs(),
+		Privacy:         privacy.Enabled,
+		PrivacyMeters:   privacy.PrecisionMeters,
+		MaintenanceMode: maintenance.Mode.Active(),
+	})
+}
+
+// configPushMu serialises router config pushes
+var configPushMu sync.Mutex
+
+// applyRemoteConfig merges a router CONFIG_PUSH overlay into the config file,
+// validates and persists the result, then applies what can change at runtime.
+// Settings that are only read at startup take effect after a restart; the
+// returned note lists them for the ACK.
+func applyRemoteConfig(cfg *config.Config, fwd *forwarder.Forwarder, filename string, container bool, overlay []byte) (string, error) {
+	configPushMu.Lock()
+	defer configPushMu.Unlock()
+
+	load := config.Load
+	if container {
+		load = config.LoadContainer
+	}
+	prev, err := load(filename)
+	if err != nil {
+		return "", err
+	}
+	next, merged, err := config.ApplyOverlay(filename, overlay, container)
+	if err != nil {
+		return "", err
+	}
+
+	// Refuse what could not be applied before anything changes, so a push is
+	// applied completely or not at all
+	// Policies exist to hold the cloud to less than everything: it may tighten them, not widen them
+	if next.Forwarding.Policy != prev.Forwarding.Policy || !reflect.DeepEqual(next.Forwarding.Policies, prev.Forwarding.Policies) {
+		if err := policy.CheckNarrower(policy.Global, policy.New(next.Forwarding.Policies, next.Forwarding.Policy)); err != nil {
+			return "", err
+		}
+	}
+	policyChanged := next.Forwarding.Policy != prev.Forwarding.Policy && reflect.DeepEqual(next.Forwarding.Policies, prev.Forwarding.Policies)
+	if policyChanged && !policy.Global.Has(next.Forwarding.Policy) {
+		return "", fmt.Errorf("unknown forwarding policy %q", next.Forwarding.Policy)
+	}
+	filtersChanged := !reflect.DeepEqual(next.Forwarding.Filters, prev.Forwarding.Filters)
+	if filtersChanged {
+		if err := policy.ValidateFilterRules(filterRules(next.Forwarding.Filters)); err != nil {
+			return "", err
+		}
+	}
+
+	// Persist before applying: a failed write leaves the file and the running
+	// config untouched. The file keeps its mode (it may hold tokens).
+	mode := os.FileMode(0600)
+	if st, err := os.Stat(filename); err == nil {
+		mode = st.Mode().Perm()
+	}
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, merged, mode); err != nil {
+		return "", fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Chmod(tmp, mode); err != nil { // WriteFile keeps the mode of a leftover tmp file
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	// Hot-apply; pending keeps the settings applied here at their old values so
+	// whatever still differs from prev needs a restart
+	pending := *next
+	if next.Log.Level != prev.Log.Level {
+		logger.SetLevelFromString(next.Log.Level)
+		cfg.Log.Level = next.Log.Level
+		pending.Log.Level = prev.Log.Level
+	}
+	if next.Health != prev.Health {
+		health.Global.SetThresholds(health.Thresholds{
+			VibrationWarn:     next.Health.VibrationWarn,
+			VibrationCritical: next.Health.VibrationCritical,
+			EKFVarianceWarn:   next.Health.EKFVarianceWarn,
+		})
+		cfg.Health = next.Health
+		pending.Health = prev.Health
+	}
+	if next.Traffic != prev.Traffic {
+		traffic.Global.SetLimits(traffic.Limits{
+			HorizontalMeters: next.Traffic.HorizontalLimit,
+			VerticalMeters:   next.Traffic.VerticalLimit,
+			LookaheadSec:     next.Traffic.LookaheadSec,
+			StaleAfter:       time.Duration(next.Traffic.StaleTimeout) * time.Second,
+		})
+		cfg.Traffic = next.Traffic
+		pending.Traffic = prev.Traffic
+	}
+	if next.Alerts.WebhookURL != prev.Alerts.WebhookURL {
+		alerts.Global.SetWebhook(next.Alerts.WebhookURL)
+		cfg.Alerts.WebhookURL = next.Alerts.WebhookURL
+		pending.Alerts.WebhookURL = prev.Alerts.WebhookURL
+	}
+	if policyChanged {
+		if err := policy.Global.SetActive(next.Forwarding.Policy); err != nil {
+			logger.Warn("[CONFIG_PUSH] Forwarding policy not switched: %v", err) // Checked above
+		}
+		cfg.Forwarding.Policy = next.Forwarding.Policy
+		pending.Forwarding.Policy = prev.Forwarding.Policy
+	}
+	if filtersChanged {
+		if err := policy.Filters.Configure(filterRules(next.Forwarding.Filters)); err != nil {
+			logger.Warn("[CONFIG_PUSH] Forwarding filters not replaced: %v", err) // Checked above
+		}
+		cfg.Forwarding.Filters = next.Forwarding.Filters
+		pending.Forwarding.Filters = prev.Forwarding.Filters
+	}
+	if next.Maintenance.ModeMinutes != prev.Maintenance.ModeMinutes || next.Maintenance.ModeMaxMinutes != prev.Maintenance.ModeMaxMinutes {
+		maintenance.Mode.SetLimits(time.Duration(next.Maintenance.ModeMinutes)*time.Minute, time.Duration(next.Maintenance.ModeMaxMinutes)*time.Minute)
+		cfg.Maintenance.ModeMinutes, cfg.Maintenance.ModeMaxMinutes = next.Maintenance.ModeMinutes, next.Maintenance.ModeMaxMinutes
+		pending.Maintenance.ModeMinutes, pending.Maintenance.ModeMaxMinutes = prev.Maintenance.ModeMinutes, prev.Maintenance.ModeMaxMinutes
+	}
+	if !reflect.DeepEqual(next.Maintenance.Reminders, prev.Maintenance.Reminders) && next.Maintenance.File == prev.Maintenance.File {
+		reminders := make([]maintenance.Reminder, 0, len(next.Maintenance.Reminders))
+		for _, rem := range next.Maintenance.Reminders {
+			reminders = append(reminders, maintenance.Reminder{Item: rem.Item, Hours: rem.Hours, Flights: rem.Flights})
+		}
+		if err := maintenance.Global.Configure(next.Maintenance.File, reminders); err != nil {
+			logger.Warn("[CONFIG_PUSH] Maintenance log not reloaded: %v", err)
+		}
+		cfg.Maintenance = next.Maintenance
+		pending.Maintenance = prev.Maintenance
+	}
+	if next.Battery != prev.Battery && next.Battery.File == prev.Battery.File {
+		if err := battery.Global.Configure(next.Battery.File, next.Battery.SagFactor); err != nil {
+			logger.Warn("[CONFIG_PUSH] Battery history not reloaded: %v", err)
+		}
+		cfg.Battery = next.Battery
+		pending.Battery = prev.Battery
+	}
+	if !reflect.DeepEqual(next.Safety, prev.Safety) {
+		safety.Global.Configure(next.Safety.FreezeParamsInFlight, next.Safety.ParamAllowlist)
+		cfg.Safety = next.Safety
+		pending.Safety = prev.Safety
+	}
+	targetChanged := next.Network.TargetHost != prev.Network.TargetHost || next.Network.TargetPort != prev.Network.TargetPort
+	if targetChanged {
+		logger.Info("[CONFIG_PUSH] Router address %s:%d -> %s:%d", prev.Network.TargetHost, prev.Network.TargetPort, next.Network.TargetHost, next.Network.TargetPort)
+		fwd.Retarget(next.Network.TargetHost, next.Network.TargetPort)
+		pending.Network.TargetHost, pending.Network.TargetPort = prev.Network.TargetHost, prev.Network.TargetPort
+	}
+
+	var restart []string
+	pv, nv := reflect.ValueOf(*prev), reflect.ValueOf(pending)
+	for i := 0; i < pv.NumField(); i++ {
+		if !reflect.DeepEqual(pv.Field(i).Interface(), nv.Field(i).Interface()) {
+			restart = append(restart, pv.Type().Field(i).Tag.Get("yaml"))
+		}
+	}
+
+	note := "applied"
+	if len(restart) > 0 {
+		note = "applied, restart required for: " + strings.Join(restart, ", ")
+	}
+	logger.Info("[CONFIG_PUSH] Router config %s", note)
+	metrics.Global.AddLog("INFO", "Router config push "+note)
+	audit.Global.Record("config", "push", map[string]interface{}{"overlay": string(overlay), "restart": restart})
+	return note, nil
+}
+
+// registerPayloadActions initialises the configured payload outputs. An output that
+// can't be initialised (missing pin, no permission) is skipped so telemetry still runs.
+func registerPayloadActions(cfg *config.Config) {
+	for _, p := range cfg.Payload.Actions {
+		var out payload.Output
+		var err error
+		switch {
+		case p.Type == "pwm":
+			out, err = payload.NewSysfsPWM(p.Chip, p.Pin,
+				time.Duration(p.PeriodUs)*time.Microsecond,
+				time.Duration(p.OnDutyUs)*time.Microsecond,
+				time.Duration(p.OffDutyUs)*time.Microsecond)
+		case p.Driver == "gpiochip":
+			out, err = payload.NewGpiochipGPIO(p.Chip, p.Pin, p.ActiveLow)
+		default:
+			out, err = payload.NewSysfsGPIO(p.Pin, p.ActiveLow)
+		}
+		if err != nil {
+			logger.Warn("[STARTUP] Payload action %s disabled: %v", p.Name, err)
+			continue
+		}
+		il := payload.Interlock{
+			None:            p.Interlock.None,
+			RequireArmed:    p.Interlock.RequireArmed,
+			RequireDisarmed: p.Interlock.RequireDisarmed,
+			MinAltitude:     p.Interlock.MinAltitude,
+			MaxAltitude:     p.Interlock.MaxAltitude,
+		}
+		if err := payload.Global.Add(p.Name, out, time.Duration(p.PulseMs)*time.Millisecond, il); err != nil {
+			logger.Warn("[STARTUP] Payload action %s disabled: %v", p.Name, err)
+		}
+	}
+}
+
+// openPowerSource opens the supply voltage input (power section)
+func openPowerSource(p config.PowerConfig) (sensors.Source, error) {
+	switch p.Bus {
+	case "i2c":
+		return sensors.NewI2C(p.Device, uint16(p.Address), byte(p.Register), p.Length)
+	case "serial":
+		return sensors.NewSerial(p.Device, p.Baud, 3*time.Duration(p.Interval)*time.Millisecond)
+	}
+	return sensors.NewFile(p.Device)
+}
+
+// registerSensors opens the configured external sensors. A sensor that can't be
+// opened is skipped with a warning.
+func registerSensors(cfg *config.Config) {
+	orientations := map[string]common.MAV_SENSOR_ORIENTATION{
+		"forward": common.MAV_SENSOR_ROTATION_NONE,
+		"right":   common.MAV_SENSOR_ROTATION_YAW_90,
+		"back":    common.MAV_SENSOR_ROTATION_YAW_180,
+		"left":    common.MAV_SENSOR_ROTATION_YAW_270,
+		"up":      common.MAV_SENSOR_ROTATION_PITCH_90,
+		"down":    common.MAV_SENSOR_ROTATION_PITCH_270,
+	}
+	sensorTypes := map[string]common.MAV_DISTANCE_SENSOR{
+		"laser":      common.MAV_DISTANCE_SENSOR_LASER,
+		"ultrasound": common.MAV_DISTANCE_SENSOR_ULTRASOUND,
+		"infrared":   common.MAV_DISTANCE_SENSOR_INFRARED,
+		"radar":      common.MAV_DISTANCE_SENSOR_RADAR,
+		"unknown":    common.MAV_DISTANCE_SENSOR_UNKNOWN,
+	}
+
+	for _, in := range cfg.Sensors.Inputs {
+		interval := time.Duration(in.Interval) * time.Millisecond
+		var src sensors.Source
+		var err error
+		if in.Bus == "i2c" {
+			src, err = sensors.NewI2C(in.Device, uint16(in.Address), byte(in.Register), in.Length)
+		} else {
+			src, err = sensors.NewSerial(in.Device, in.Baud, 3*interval)
+		}
+		if err != nil {
+			logger.Warn("[STARTUP] Sensor %s disabled: %v", in.Name, err)
+			continue
+		}
+		sensors.Global.Add(sensors.Input{
+			Name:        in.Name,
+			Kind:        in.Kind,
+			Source:      src,
+			Interval:    interval,
+			Scale:       in.Scale,
+			Offset:      in.Offset,
+			ToFC:        in.ToFC,
+			ToServer:    in.ToServer,
+			ID:          uint8(in.ID),
+			Orientation: orientations[in.Orientation],
+			SensorType:  sensorTypes[in.SensorType],
+			MinDistance: in.MinDistance,
+			MaxDistance: in.MaxDistance,
+		})
+	}
+}
+
+// runningInContainer detects Docker, Podman and Kubernetes
+func runningInContainer() bool {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true
+	}
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Exit codes of registration runs, for provisioning scripts
+const (
+	exitRegisterUsage    = 1 // Bad flags, config or UUID list
+	exitRegisterRejected = 2 // Router refused the registration (shared secret, unknown UUID)
+	exitRegisterConflict = 3 // Router holds a secret for the drone that is not stored locally
+	exitRegisterNetwork  = 4 // Router unreachable or still failing after retries
+	exitRegisterStorage  = 5 // Secret issued but not saved
+	exitRegisterPartial  = 6 // --register-batch: at least one drone failed
+)
+
+// registrationReport is the --json output for one drone
+type registrationReport struct {
+	UUID       string     `json:"uuid"`
+	Result     string     `json:"result"` // registered, re_registered, already_registered or failed
+	SecretPath string     `json:"secretPath,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	Attempts   int        `json:"attempts,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	ExitCode   int        `json:"exitCode"`
+}
+
+func newRegistrationReport(uuid string, res *auth.RegisterResult, err error) registrationReport {
+	if err != nil {
+		return registrationReport{UUID: uuid, Result: "failed", Error: err.Error(), ExitCode: registerExitCode(err)}
+	}
+	return registrationReport{
+		UUID:       res.UUID,
+		Result:     res.Result,
+		SecretPath: res.SecretPath,
+		ExpiresAt:  res.ExpiresAt,
+		Attempts:   res.Attempts,
+	}
+}
+
+// registerExitCode maps a registration error to its exit code
+func registerExitCode(err error) int {
+	var regErr *auth.RegisterError
+	switch {
+	case errors.Is(err, auth.ErrSecretStore):
+		return exitRegisterStorage
+	case errors.As(err, &regErr) && regErr.Code == auth.ErrAlreadyRegistered:
+		return exitRegisterConflict
+	case errors.As(err, &regErr) && regErr.Code != auth.ErrRateLimited && regErr.Code != auth.ErrInternalError:
+		return exitRegisterRejected
+	}
+	return exitRegisterNetwork
+}
+
+// runRegistration registers the configured drone, or every UUID in batchFile,
+// without starting the bridge and returns the process exit code
+func runRegistration(cfg *config.Config, batchFile string, force, jsonOut bool) int {
+	if !cfg.Features.Auth {
+		logger.Error("❌ Registration requires the auth feature (features.auth: false)")
+		return exitRegisterUsage
+	}
+	if cfg.Auth.Mode == "mtls" {
+		logger.Error("❌ Registration is not used in mtls mode - identity comes from the client certificate")
+		return exitRegisterUsage
+	}
+	if cfg.Auth.SharedSecret == "" {
+		logger.Error("❌ Registration requires auth.shared_secret")
+		return exitRegisterUsage
+	}
+
+	uuids := []string{cfg.Auth.UUID}
+	if batchFile != "" {
+		var err error
+		if uuids, err = readUUIDList(batchFile); err != nil {
+			logger.Error("❌ %v", err)
+			return exitRegisterUsage
+		}
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	code, failed := 0, 0
+	for _, uuid := range uuids {
+		client := auth.NewClient(cfg.Auth.Host, cfg.Auth.Port, uuid, cfg.Auth.SharedSecret, cfg.Auth.KeepaliveInterval)
+		res, err := client.Register(force)
+		report := newRegistrationReport(uuid, res, err)
+		if jsonOut {
+			out.Encode(report)
+		}
+		if err != nil {
+			logger.Error("❌ %s: registration failed: %v", uuid, err)
+			code = report.ExitCode
+			failed++
+			continue
+		}
+		logger.Info("✅ %s: %s (secret %s)", uuid, report.Result, report.SecretPath)
+	}
+
+	if batchFile != "" {
+		logger.Info("Registered %d of %d drones", len(uuids)-failed, len(uuids))
+		if failed > 0 {
+			return exitRegisterPartial
+		}
+	}
+	return code
+}
+
+// readUUIDList reads drone UUIDs, one per line (blank lines and # comments skipped)
+func readUUIDList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read UUID list: %w", err)
+	}
+	var uuids []string
+	seen := make(map[string]bool)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !auth.ValidUUID(line) {
+			return nil, fmt.Errorf("%s:%d: invalid drone UUID %q", path, i+1, line)
+		}
+		if !seen[line] {
+			seen[line] = true
+			uuids = append(uuids, line)
+		}
+	}
+	if len(uuids) == 0 {
+		return nil, fmt.Errorf("%s lists no drone UUIDs", path)
+	}
+	return uuids, nil
+}
+
+// registerScheduledActions makes the maintenance actions available to schedule.tasks
+func registerScheduledActions(cfg *config.Config, authClient *auth.Client) {
+	scheduler.Global.RegisterAction("renew_api_key", func() error {
+		status, err := authClient.GetAPIKeyStatus()
+		if err != nil {
+			return fmt.Errorf("failed to get API key status: %w", err)
+		}
+		if status.HasActiveKey == 0x01 && time.Until(time.Unix(int64(status.ExpiresAt), 0)) > 24*time.Hour {
+			return nil // Still valid for more than a day
+		}
+		resp, err := authClient.RequestAPIKey(24)
+		if err != nil {
+			return fmt.Errorf("failed to renew API key: %w", err)
+		}
+		logger.Info("[SCHEDULE] API key renewed, expires %s", time.Unix(int64(resp.ExpiresAt), 0).Format(time.RFC3339))
+		return nil
+	})
+
+	// Logs go to stdout; on-disk logs (tlogs, journals) are rotated by the storage retention rules
+	scheduler.Global.RegisterAction("rotate_logs", func() error {
+		storage.Global.Check()
+		return nil
+	})
+
+	scheduler.Global.RegisterAction("upload_diagnostics", func() error {
+		if cfg.Schedule.DiagnosticsURL == "" && !cfg.Upload.Enabled {
+			return fmt.Errorf("neither schedule.diagnostics_url nor upload is configured")
+		}
+		body, err := json.Marshal(map[string]interface{}{
+			"uuid":     cfg.Auth.UUID,
+			"identity": identity.Global.Get(),
+			"time":     time.Now(),
+			"metrics":  metrics.Global.GetSnapshot(),
+			"health":   health.Global.Snapshot(),
+			"alerts":   alerts.Global.Recent(),
+			"storage":  storage.Global.Snapshot(),
+			"watchdog": watchdog.Global.Snapshot(),
+		})
+		if err != nil {
+			return err
+		}
+
+		// Bundles written to the diagnostics dir are shipped by the uploader
+		if cfg.Upload.Enabled {
+			dir := cfg.Upload.DiagnosticsDir
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(cfg.Storage.DataDir, dir)
+			}
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create diagnostics dir: %w", err)
+			}
+			name := filepath.Join(dir, "diag-"+time.Now().UTC().Format("20060102-150405")+".json")
+			if err := os.WriteFile(name, body, 0644); err != nil {
+				return fmt.Errorf("failed to write diagnostics bundle: %w", err)
+			}
+			logger.Info("[SCHEDULE] Diagnostics bundle written to %s", name)
+		}
+		if cfg.Schedule.DiagnosticsURL == "" {
+			return nil
+		}
+
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Post(cfg.Schedule.DiagnosticsURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("diagnostics upload failed: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("diagnostics upload rejected: HTTP %d", resp.StatusCode)
+		}
+		return nil
+	})
+
+	scheduler.Global.RegisterAction("preflight_selftest", web.RunPreflightSelfTest)
+	scheduler.Global.RegisterAction("restart_camera", camera.RestartAllCameras)
 }
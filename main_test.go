@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"DroneBridge/config"
+	"DroneBridge/internal/policy"
+)
+
+// pushConfig copies the shipped config.yaml into a temp dir with the given mode
+func pushConfig(t *testing.T, mode os.FileMode) (string, *config.Config) {
+	t.Helper()
+	data, err := os.ReadFile("config/config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, data, mode); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, cfg
+}
+
+func TestApplyRemoteConfig(t *testing.T) {
+	defer func(g *policy.Manager) { policy.Global = g }(policy.Global)
+
+	tests := []struct {
+		name      string
+		mode      os.FileMode
+		overlay   string
+		running   string // Forwarding policy in effect before the push
+		wantErr   bool
+		wantLevel string // cfg.Log.Level afterwards
+	}{
+		{"log level", 0600, "log:\n  level: debug\n", "full", false, "debug"},
+		{"group readable file", 0640, "log:\n  level: warn\n", "full", false, "warn"},
+		{"protected key", 0600, "log:\n  level: debug\nfirmware:\n  uploader: \"sh {file}\"\n", "full", true, "info"},
+		{"unknown policy", 0600, "log:\n  level: debug\nforwarding:\n  policy: nope\n", "minimal", true, "info"},
+		{"invalid filter", 0600, "log:\n  level: debug\nforwarding:\n  filters:\n    - action: deny\n      max_hz: 5\n", "full", true, "info"},
+		{"tighter policy", 0600, "log:\n  level: debug\nforwarding:\n  policy: standard\n", "full", false, "debug"},
+		{"wider policy", 0600, "log:\n  level: debug\nforwarding:\n  policy: standard\n", "minimal", true, "info"},
+		{"new wider policy", 0600, "log:\n  level: debug\nforwarding:\n  policy: standard\n  policies:\n    mine: [0, 1, 30, 31, 32]\n", "standard", true, "info"},
+		{"new narrower policy", 0600, "log:\n  level: debug\nforwarding:\n  policy: standard\n  policies:\n    mine: [0, 1]\n", "standard", false, "debug"},
+	}
+	for _, tt := range tests {
+		policy.Global = policy.New(nil, tt.running)
+		path, cfg := pushConfig(t, tt.mode)
+		before, _ := os.ReadFile(path)
+
+		_, err := applyRemoteConfig(cfg, nil, path, false, []byte(tt.overlay))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: applyRemoteConfig() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if cfg.Log.Level != tt.wantLevel {
+			t.Errorf("%s: log level = %q, want %q", tt.name, cfg.Log.Level, tt.wantLevel)
+		}
+		after, _ := os.ReadFile(path)
+		if tt.wantErr && string(after) != string(before) {
+			t.Errorf("%s: config file changed by a refused push", tt.name)
+		}
+		if !tt.wantErr && string(after) == string(before) {
+			t.Errorf("%s: config file not updated", tt.name)
+		}
+		if st, err := os.Stat(path); err != nil || st.Mode().Perm() != tt.mode {
+			t.Errorf("%s: config file mode = %v, want %v", tt.name, st.Mode().Perm(), tt.mode)
+		}
+		if _, err := os.Stat(path + ".tmp"); err == nil {
+			t.Errorf("%s: temp file left behind", tt.name)
+		}
+	}
+}
@@ -0,0 +1,469 @@
+// Command gen writes the Python and TypeScript clients in sdk/ from the routes
+// registered in web/server.go, so they follow the API instead of drifting from
+// it. Run "make sdk" (or "go generate ./web") after adding or changing a route.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// route is one http.HandleFunc registration
+type route struct {
+	Path    string
+	Prefix  bool     // Registered with a trailing slash: takes a sub-path
+	Methods []string // Methods the handler checks for (empty = unknown)
+	Doc     string   // Comment above the registration
+}
+
+// Routes that are not JSON request/response endpoints
+var skipped = map[string]bool{
+	"/":        true, // Dashboard files
+	"/metrics": true, // Prometheus text format
+}
+
+// docMethod matches "GET /api/... - description" comment lines
+var docMethod = regexp.MustCompile(`^(GET|POST|PUT|PATCH|DELETE)\s+/\S*\s*(?:-\s*)?`)
+
+func main() {
+	webDir := flag.String("web", "web", "Directory of the web package")
+	outDir := flag.String("out", "sdk", "Output directory")
+	flag.Parse()
+
+	routes, err := parseRoutes(*webDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sdk/gen: %v\n", err)
+		os.Exit(1)
+	}
+	files := map[string]string{
+		filepath.Join(*outDir, "python", "dronebridge_client.py"): pythonClient(routes),
+		filepath.Join(*outDir, "typescript", "dronebridge.ts"):    typescriptClient(routes),
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "sdk/gen: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "sdk/gen: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("sdk/gen: %d routes -> %s\n", len(routes), *outDir)
+}
+
+// parseRoutes collects the routes of server.go, resolving path constants and
+// handler methods from the rest of the package
+func parseRoutes(dir string) ([]route, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	pkg, ok := pkgs["web"]
+	if !ok {
+		return nil, fmt.Errorf("no web package in %s", dir)
+	}
+
+	consts := map[string]string{}
+	funcs := map[string]*ast.FuncDecl{}
+	var server *ast.File
+	for name, f := range pkg.Files {
+		if filepath.Base(name) == "server.go" {
+			server = f
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					funcs[d.Name.Name] = d
+				}
+			case *ast.GenDecl:
+				if d.Tok != token.CONST {
+					continue
+				}
+				for _, spec := range d.Specs {
+					vs := spec.(*ast.ValueSpec)
+					for i, n := range vs.Names {
+						if i < len(vs.Values) {
+							if s, ok := stringLit(vs.Values[i]); ok {
+								consts[n.Name] = s
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	if server == nil {
+		return nil, fmt.Errorf("no server.go in %s", dir)
+	}
+
+	// Lines that start a registration: a comment above a run of them documents the whole run
+	var calls []*ast.CallExpr
+	callLines := map[int]bool{}
+	ast.Inspect(server, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !isHandleFunc(call) || len(call.Args) != 2 {
+			return true
+		}
+		calls = append(calls, call)
+		callLines[fset.Position(call.Pos()).Line] = true
+		return true
+	})
+
+	var routes []route
+	seen := map[string]bool{}
+	for _, call := range calls {
+		path, ok := stringLit(call.Args[0])
+		if !ok {
+			if id, isIdent := call.Args[0].(*ast.Ident); isIdent {
+				path, ok = consts[id.Name]
+			}
+		}
+		if !ok || skipped[path] || strings.HasPrefix(path, "/ws/") || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		rt := route{Path: path, Prefix: strings.HasSuffix(path, "/") && path != "/"}
+		rt.Methods = handlerMethods(call.Args[1], funcs)
+		doc, method := routeDoc(fset, server.Comments, fset.Position(call.Pos()).Line, callLines)
+		rt.Doc = doc
+		if method != "" {
+			rt.Methods = []string{method}
+		}
+		routes = append(routes, rt)
+	}
+	return routes, nil
+}
+
+func isHandleFunc(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "HandleFunc" {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == "http"
+}
+
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// handlerMethods returns the http.MethodX constants a handler refers to: in
+// the call building it (handleAPIKeyOp(op, http.MethodGet)), or else in its body
+func handlerMethods(h ast.Expr, funcs map[string]*ast.FuncDecl) []string {
+	var body ast.Node
+	switch e := h.(type) {
+	case *ast.FuncLit:
+		body = e.Body
+	case *ast.Ident:
+		if fd := funcs[e.Name]; fd != nil {
+			body = fd.Body
+		}
+	case *ast.CallExpr:
+		args := make([]ast.Node, len(e.Args))
+		for i, a := range e.Args {
+			args[i] = a
+		}
+		if m := methodsIn(args...); len(m) > 0 {
+			return m
+		}
+		if id, ok := e.Fun.(*ast.Ident); ok && funcs[id.Name] != nil {
+			body = funcs[id.Name].Body
+		}
+	}
+	if body == nil {
+		return nil
+	}
+	return methodsIn(body)
+}
+
+func methodsIn(nodes ...ast.Node) []string {
+	set := map[string]bool{}
+	for _, n := range nodes {
+		ast.Inspect(n, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok || !strings.HasPrefix(sel.Sel.Name, "Method") {
+				return true
+			}
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == "http" {
+				m := strings.ToUpper(strings.TrimPrefix(sel.Sel.Name, "Method"))
+				if m != "OPTIONS" && m != "HEAD" {
+					set[m] = true
+				}
+			}
+			return true
+		})
+	}
+	out := make([]string, 0, len(set))
+	for m := range set {
+		out = append(out, m)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// routeDoc returns the comment documenting the registration on line, and the
+// method it names ("GET /api/... - ..."), if any
+func routeDoc(fset *token.FileSet, groups []*ast.CommentGroup, line int, callLines map[int]bool) (string, string) {
+	var doc *ast.CommentGroup
+	for _, g := range groups {
+		end := fset.Position(g.End()).Line
+		if end >= line {
+			break
+		}
+		between := true
+		for l := end + 1; l < line; l++ {
+			if !callLines[l] {
+				between = false
+				break
+			}
+		}
+		if between {
+			doc = g
+		}
+	}
+	if doc == nil {
+		return "", ""
+	}
+	lines := strings.Split(strings.TrimSpace(doc.Text()), "\n")
+	// A group heading several endpoints: keep the line naming this one
+	for i := len(lines) - 1; i >= 0; i-- {
+		if docMethod.MatchString(lines[i]) {
+			m := docMethod.FindStringSubmatch(lines[i])
+			return strings.TrimSpace(docMethod.ReplaceAllString(lines[i], "")), m[1]
+		}
+	}
+	return strings.Join(lines, " "), ""
+}
+
+// words splits a route path into name parts: /api/v1/drone/api-key/status -> api key status
+func words(rt route) []string {
+	p := strings.TrimPrefix(rt.Path, "/")
+	p = strings.TrimPrefix(p, "api/")
+	p = strings.TrimPrefix(p, "v1/drone/")
+	var out []string
+	for _, w := range strings.FieldsFunc(p, func(r rune) bool { return r == '/' || r == '-' || r == '_' }) {
+		out = append(out, strings.ToLower(w))
+	}
+	if rt.Prefix {
+		out = append(out, "item")
+	}
+	return out
+}
+
+// defaultMethod picks the method a generated call uses when none is given
+func defaultMethod(rt route) string {
+	if len(rt.Methods) == 0 {
+		return "GET"
+	}
+	for _, m := range rt.Methods {
+		if m == "GET" {
+			return m
+		}
+	}
+	for _, m := range rt.Methods {
+		if m == "POST" {
+			return m
+		}
+	}
+	return rt.Methods[0]
+}
+
+func methodsNote(rt route) string {
+	if len(rt.Methods) == 0 {
+		return ""
+	}
+	return "Methods: " + strings.Join(rt.Methods, ", ")
+}
+
+const header = "Code generated by sdk/gen from web/server.go; DO NOT EDIT."
+
+func pythonClient(routes []route) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", header)
+	b.WriteString(`"""Thin client for the DroneBridge HTTP API (Python 3, standard library only).
+
+    from dronebridge_client import DroneBridgeClient
+    db = DroneBridgeClient("http://drone.local:8080", token="dbt_...")
+    print(db.status())
+    db.flight_takeoff(body={"altitude": 10})
+"""
+
+import json
+import urllib.error
+import urllib.parse
+import urllib.request
+
+
+class DroneBridgeError(Exception):
+    """An error response: HTTP status plus the API's error code and message."""
+
+    def __init__(self, status, code, message):
+        super().__init__("%d %s: %s" % (status, code, message))
+        self.status = status
+        self.code = code
+        self.message = message
+
+
+class DroneBridgeClient:
+    def __init__(self, base_url="http://localhost:8080", token=None, timeout=10.0):
+        self.base_url = base_url.rstrip("/")
+        self.token = token
+        self.timeout = timeout
+
+    def request(self, method, path, body=None, params=None):
+        """Sends a request and returns the decoded JSON (or text) response."""
+        url = self.base_url + path
+        if params:
+            url += "?" + urllib.parse.urlencode(params)
+        data = None
+        headers = {"Accept": "application/json"}
+        if body is not None:
+            data = json.dumps(body).encode()
+            headers["Content-Type"] = "application/json"
+        if self.token:
+            headers["Authorization"] = "Bearer " + self.token
+        req = urllib.request.Request(url, data=data, method=method, headers=headers)
+        try:
+            with urllib.request.urlopen(req, timeout=self.timeout) as resp:
+                return _decode(resp.read(), resp.headers.get("Content-Type", ""))
+        except urllib.error.HTTPError as e:
+            payload = _decode(e.read(), e.headers.get("Content-Type", ""))
+            if isinstance(payload, dict):
+                raise DroneBridgeError(e.code, payload.get("code", ""), payload.get("message", "")) from None
+            raise DroneBridgeError(e.code, "", str(payload)) from None
+`)
+	for _, rt := range routes {
+		name := strings.Join(words(rt), "_")
+		def := defaultMethod(rt)
+		b.WriteString("\n")
+		if rt.Prefix {
+			fmt.Fprintf(&b, "    def %s(self, sub, method=%q, body=None, params=None):\n", name, def)
+		} else {
+			fmt.Fprintf(&b, "    def %s(self, method=%q, body=None, params=None):\n", name, def)
+		}
+		doc := strings.TrimSpace(strings.Join([]string{rt.Doc, methodsNote(rt)}, "\n\n        "))
+		if doc == "" {
+			doc = rt.Path
+		}
+		fmt.Fprintf(&b, "        \"\"\"%s\"\"\"\n", strings.ReplaceAll(doc, `"""`, `'''`))
+		if rt.Prefix {
+			fmt.Fprintf(&b, "        return self.request(method, %q + urllib.parse.quote(str(sub)), body, params)\n", rt.Path)
+		} else {
+			fmt.Fprintf(&b, "        return self.request(method, %q, body, params)\n", rt.Path)
+		}
+	}
+	b.WriteString(`
+
+def _decode(raw, content_type):
+    if "json" in content_type and raw:
+        return json.loads(raw)
+    return raw.decode(errors="replace")
+`)
+	return b.String()
+}
+
+func typescriptClient(routes []route) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s\n", header)
+	b.WriteString(`//
+// Thin client for the DroneBridge HTTP API (browsers, Node 18+, Deno - uses fetch).
+//
+//   const db = new DroneBridgeClient("http://drone.local:8080", { token: "dbt_..." });
+//   console.log(await db.status());
+//   await db.flightTakeoff({ body: { altitude: 10 } });
+
+export type Method = "GET" | "POST" | "PUT" | "PATCH" | "DELETE";
+
+export interface CallOptions {
+  method?: Method;
+  body?: unknown;
+  params?: Record<string, string | number | boolean>;
+}
+
+export class DroneBridgeError extends Error {
+  constructor(public status: number, public code: string, message: string) {
+    super(` + "`${status} ${code}: ${message}`" + `);
+  }
+}
+
+export class DroneBridgeClient {
+  readonly baseUrl: string;
+  token?: string;
+
+  constructor(baseUrl = "http://localhost:8080", opts: { token?: string } = {}) {
+    this.baseUrl = baseUrl.replace(/\/+$/, "");
+    this.token = opts.token;
+  }
+
+  /** Sends a request and returns the decoded JSON (or text) response. */
+  async request<T = any>(method: Method, path: string, body?: unknown, params?: CallOptions["params"]): Promise<T> {
+    let url = this.baseUrl + path;
+    if (params) {
+      const q = new URLSearchParams();
+      for (const [k, v] of Object.entries(params)) q.set(k, String(v));
+      url += "?" + q.toString();
+    }
+    const headers: Record<string, string> = { Accept: "application/json" };
+    if (body !== undefined) headers["Content-Type"] = "application/json";
+    if (this.token) headers["Authorization"] = "Bearer " + this.token;
+    const resp = await fetch(url, { method, headers, body: body === undefined ? undefined : JSON.stringify(body) });
+    const isJSON = (resp.headers.get("Content-Type") ?? "").includes("json");
+    const text = await resp.text();
+    const payload = isJSON && text ? JSON.parse(text) : text;
+    if (!resp.ok) {
+      if (typeof payload === "object" && payload !== null) {
+        throw new DroneBridgeError(resp.status, payload.code ?? "", payload.message ?? "");
+      }
+      throw new DroneBridgeError(resp.status, "", String(payload));
+    }
+    return payload as T;
+  }
+`)
+	for _, rt := range routes {
+		ws := words(rt)
+		name := ws[0]
+		for _, w := range ws[1:] {
+			name += strings.ToUpper(w[:1]) + w[1:]
+		}
+		def := defaultMethod(rt)
+		b.WriteString("\n")
+		doc := rt.Doc
+		if doc == "" {
+			doc = rt.Path
+		}
+		if note := methodsNote(rt); note != "" {
+			doc += " (" + note + ")"
+		}
+		fmt.Fprintf(&b, "  /** %s */\n", strings.ReplaceAll(doc, "*/", "* /"))
+		if rt.Prefix {
+			fmt.Fprintf(&b, "  %s<T = any>(sub: string, opts: CallOptions = {}): Promise<T> {\n", name)
+			fmt.Fprintf(&b, "    return this.request<T>(opts.method ?? %q, %q + encodeURIComponent(sub), opts.body, opts.params);\n", def, rt.Path)
+		} else {
+			fmt.Fprintf(&b, "  %s<T = any>(opts: CallOptions = {}): Promise<T> {\n", name)
+			fmt.Fprintf(&b, "    return this.request<T>(opts.method ?? %q, %q, opts.body, opts.params);\n", def, rt.Path)
+		}
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
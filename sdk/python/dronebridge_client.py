@@ -0,0 +1,478 @@
+# Code generated by sdk/gen from web/server.go; DO NOT EDIT.
+"""Thin client for the DroneBridge HTTP API (Python 3, standard library only).
+
+    from dronebridge_client import DroneBridgeClient
+    db = DroneBridgeClient("http://drone.local:8080", token="dbt_...")
+    print(db.status())
+    db.flight_takeoff(body={"altitude": 10})
+"""
+
+import json
+import urllib.error
+import urllib.parse
+import urllib.request
+
+
+class DroneBridgeError(Exception):
+    """An error response: HTTP status plus the API's error code and message."""
+
+    def __init__(self, status, code, message):
+        super().__init__("%d %s: %s" % (status, code, message))
+        self.status = status
+        self.code = code
+        self.message = message
+
+
+class DroneBridgeClient:
+    def __init__(self, base_url="http://localhost:8080", token=None, timeout=10.0):
+        self.base_url = base_url.rstrip("/")
+        self.token = token
+        self.timeout = timeout
+
+    def request(self, method, path, body=None, params=None):
+        """Sends a request and returns the decoded JSON (or text) response."""
+        url = self.base_url + path
+        if params:
+            url += "?" + urllib.parse.urlencode(params)
+        data = None
+        headers = {"Accept": "application/json"}
+        if body is not None:
+            data = json.dumps(body).encode()
+            headers["Content-Type"] = "application/json"
+        if self.token:
+            headers["Authorization"] = "Bearer " + self.token
+        req = urllib.request.Request(url, data=data, method=method, headers=headers)
+        try:
+            with urllib.request.urlopen(req, timeout=self.timeout) as resp:
+                return _decode(resp.read(), resp.headers.get("Content-Type", ""))
+        except urllib.error.HTTPError as e:
+            payload = _decode(e.read(), e.headers.get("Content-Type", ""))
+            if isinstance(payload, dict):
+                raise DroneBridgeError(e.code, payload.get("code", ""), payload.get("message", "")) from None
+            raise DroneBridgeError(e.code, "", str(payload)) from None
+
+    def status(self, method="GET", body=None, params=None):
+        """API endpoint for status"""
+        return self.request(method, "/api/status", body, params)
+
+    def dashboard(self, method="GET", body=None, params=None):
+        """API endpoint for the dashboard summary (FC section null without a Pixhawk)"""
+        return self.request(method, "/api/dashboard", body, params)
+
+    def connection(self, method="GET", body=None, params=None):
+        """API endpoint for connection status"""
+        return self.request(method, "/api/connection", body, params)
+
+    def param_set(self, method="POST", body=None, params=None):
+        """API endpoint for setting parameters
+
+        Methods: POST"""
+        return self.request(method, "/api/param/set", body, params)
+
+    def param_set_batch(self, method="POST", body=None, params=None):
+        """API endpoint to set several parameters with optional rollback
+
+        Methods: POST"""
+        return self.request(method, "/api/param/set-batch", body, params)
+
+    def calibration(self, method="GET", body=None, params=None):
+        """API endpoint for guided calibration workflows
+
+        Methods: GET, POST"""
+        return self.request(method, "/api/calibration", body, params)
+
+    def firmware(self, method="GET", body=None, params=None):
+        """API endpoint to upload and flash FC firmware
+
+        Methods: GET, POST"""
+        return self.request(method, "/api/firmware", body, params)
+
+    def files(self, method="GET", body=None, params=None):
+        """API endpoints for the workspace file manager (bearer token required)
+
+        Methods: GET"""
+        return self.request(method, "/api/files", body, params)
+
+    def files_download(self, method="GET", body=None, params=None):
+        """API endpoints for the workspace file manager (bearer token required)
+
+        Methods: GET"""
+        return self.request(method, "/api/files/download", body, params)
+
+    def files_upload(self, method="POST", body=None, params=None):
+        """API endpoints for the workspace file manager (bearer token required)
+
+        Methods: POST"""
+        return self.request(method, "/api/files/upload", body, params)
+
+    def tokens(self, method="GET", body=None, params=None):
+        """API endpoints to issue, list and revoke role-scoped API tokens (admin token required)
+
+        Methods: DELETE, GET, POST"""
+        return self.request(method, "/api/tokens", body, params)
+
+    def tokens_item(self, sub, method="GET", body=None, params=None):
+        """API endpoints to issue, list and revoke role-scoped API tokens (admin token required)
+
+        Methods: DELETE, GET, POST"""
+        return self.request(method, "/api/tokens/" + urllib.parse.quote(str(sub)), body, params)
+
+    def guided_goto(self, method="POST", body=None, params=None):
+        """API endpoint for click-to-fly in GUIDED mode
+
+        Methods: POST"""
+        return self.request(method, "/api/guided/goto", body, params)
+
+    def guided_orbit(self, method="POST", body=None, params=None):
+        """API endpoints for orbit / point of interest and the command ACK history
+
+        Methods: POST"""
+        return self.request(method, "/api/guided/orbit", body, params)
+
+    def guided_roi(self, method="POST", body=None, params=None):
+        """API endpoints for orbit / point of interest and the command ACK history
+
+        Methods: POST"""
+        return self.request(method, "/api/guided/roi", body, params)
+
+    def commands(self, method="GET", body=None, params=None):
+        """API endpoints for orbit / point of interest and the command ACK history"""
+        return self.request(method, "/api/commands", body, params)
+
+    def flight_takeoff(self, method="POST", body=None, params=None):
+        """One-click flight actions (mode change + command with ACK verification)
+
+        Methods: POST"""
+        return self.request(method, "/api/flight/takeoff", body, params)
+
+    def flight_land(self, method="POST", body=None, params=None):
+        """One-click flight actions (mode change + command with ACK verification)
+
+        Methods: POST"""
+        return self.request(method, "/api/flight/land", body, params)
+
+    def flight_rtl(self, method="POST", body=None, params=None):
+        """One-click flight actions (mode change + command with ACK verification)
+
+        Methods: POST"""
+        return self.request(method, "/api/flight/rtl", body, params)
+
+    def emergency_stop(self, method="GET", body=None, params=None):
+        """Two-operator emergency stop (force-disarm / flight termination)
+
+        Methods: DELETE, GET, POST"""
+        return self.request(method, "/api/emergency/stop", body, params)
+
+    def payload(self, method="GET", body=None, params=None):
+        """API endpoints for companion payload outputs (GPIO/PWM)
+
+        Methods: GET, POST"""
+        return self.request(method, "/api/payload", body, params)
+
+    def payload_item(self, sub, method="GET", body=None, params=None):
+        """API endpoints for companion payload outputs (GPIO/PWM)
+
+        Methods: GET, POST"""
+        return self.request(method, "/api/payload/" + urllib.parse.quote(str(sub)), body, params)
+
+    def health(self, method="GET", body=None, params=None):
+        """API endpoint for health check"""
+        return self.request(method, "/api/health", body, params)
+
+    def identity(self, method="GET", body=None, params=None):
+        """API endpoint for drone name metadata
+
+        Methods: GET, POST, PUT"""
+        return self.request(method, "/api/identity", body, params)
+
+    def fc_pin(self, method="GET", body=None, params=None):
+        """API endpoint for the pinned FC (GET) and re-pairing (POST)
+
+        Methods: GET, POST"""
+        return self.request(method, "/api/fc/pin", body, params)
+
+    def signing(self, method="GET", body=None, params=None):
+        """API endpoint for MAVLink 2 signing state (key fingerprint, counters)
+
+        Methods: GET"""
+        return self.request(method, "/api/signing", body, params)
+
+    def events(self, method="GET", body=None, params=None):
+        """API endpoint for recent session and API key events (also pushed over /ws/mavlink?events=1)
+
+        Methods: GET"""
+        return self.request(method, "/api/events", body, params)
+
+    def entitlements(self, method="GET", body=None, params=None):
+        """API endpoint for the signed feature list (premium subsystems usable on this drone)
+
+        Methods: GET"""
+        return self.request(method, "/api/entitlements", body, params)
+
+    def healthz(self, method="GET", body=None, params=None):
+        """Liveness and readiness probes for container orchestrators"""
+        return self.request(method, "/healthz", body, params)
+
+    def readyz(self, method="GET", body=None, params=None):
+        """Liveness and readiness probes for container orchestrators"""
+        return self.request(method, "/readyz", body, params)
+
+    def camera_status(self, method="GET", body=None, params=None):
+        """API endpoint for camera pipelines and encoder statistics"""
+        return self.request(method, "/api/camera/status", body, params)
+
+    def health_fc(self, method="GET", body=None, params=None):
+        """API endpoint for decoded flight controller health"""
+        return self.request(method, "/api/health/fc", body, params)
+
+    def vehicle(self, method="GET", body=None, params=None):
+        """Vehicle class, class-specific telemetry and preflight checks"""
+        return self.request(method, "/api/vehicle", body, params)
+
+    def telemetry_latest(self, method="GET", body=None, params=None):
+        """API endpoint for the latest decoded instance of any FC message type (?msg=GLOBAL_POSITION_INT)
+
+        Methods: GET"""
+        return self.request(method, "/api/telemetry/latest", body, params)
+
+    def telemetry_types(self, method="GET", body=None, params=None):
+        """API endpoint listing the message types available from /api/telemetry/latest
+
+        Methods: GET"""
+        return self.request(method, "/api/telemetry/types", body, params)
+
+    def startup_report(self, method="GET", body=None, params=None):
+        """API endpoint for the startup report (effective config and sanity warnings)"""
+        return self.request(method, "/api/startup-report", body, params)
+
+    def log_level(self, method="GET", body=None, params=None):
+        """API endpoint for runtime log levels (global, per subsystem, temporary debug)
+
+        Methods: GET, POST, PUT"""
+        return self.request(method, "/api/log/level", body, params)
+
+    def debug_chaos(self, method="GET", body=None, params=None):
+        """DEBUG: link simulation (latency/jitter/loss, forced IP change) - needs --chaos
+
+        Methods: DELETE, GET, POST, PUT"""
+        return self.request(method, "/api/debug/chaos", body, params)
+
+    def debug_chaos_ip_change(self, method="POST", body=None, params=None):
+        """DEBUG: link simulation (latency/jitter/loss, forced IP change) - needs --chaos
+
+        Methods: POST"""
+        return self.request(method, "/api/debug/chaos/ip-change", body, params)
+
+    def debug_soak(self, method="GET", body=None, params=None):
+        """DEBUG: soak test progress - needs --soak"""
+        return self.request(method, "/api/debug/soak", body, params)
+
+    def can_nodes(self, method="GET", body=None, params=None):
+        """API endpoint for DroneCAN nodes (ESCs, GPS) reported by the FC"""
+        return self.request(method, "/api/can/nodes", body, params)
+
+    def traffic(self, method="GET", body=None, params=None):
+        """API endpoint for nearby ADS-B traffic"""
+        return self.request(method, "/api/traffic", body, params)
+
+    def peers(self, method="GET", body=None, params=None):
+        """API endpoint for nearby bridges on the local mesh"""
+        return self.request(method, "/api/peers", body, params)
+
+    def discovery(self, method="GET", body=None, params=None):
+        """API endpoint for the LAN discovery beacon (GET state, POST toggle)
+
+        Methods: GET, POST, PUT"""
+        return self.request(method, "/api/discovery", body, params)
+
+    def sensors(self, method="GET", body=None, params=None):
+        """API endpoint for external I2C/serial sensors"""
+        return self.request(method, "/api/sensors", body, params)
+
+    def viewers(self, method="GET", body=None, params=None):
+        """API endpoints for viewer sessions and the audit log"""
+        return self.request(method, "/api/viewers", body, params)
+
+    def audit(self, method="GET", body=None, params=None):
+        """API endpoints for viewer sessions and the audit log
+
+        Methods: GET"""
+        return self.request(method, "/api/audit", body, params)
+
+    def forwarding_policy(self, method="GET", body=None, params=None):
+        """API endpoint to view/switch the forwarding policy
+
+        Methods: GET, POST, PUT"""
+        return self.request(method, "/api/forwarding/policy", body, params)
+
+    def privacy(self, method="GET", body=None, params=None):
+        """API endpoint to view/toggle privacy mode (position fuzzing for the cloud)
+
+        Methods: GET, POST, PUT"""
+        return self.request(method, "/api/privacy", body, params)
+
+    def channels(self, method="GET", body=None, params=None):
+        """API endpoint for per-endpoint channel statistics"""
+        return self.request(method, "/api/channels", body, params)
+
+    def stats_parse_errors(self, method="GET", body=None, params=None):
+        """MAVLink parse errors per channel with recent samples (serial noise vs dialect mismatch)
+
+        Methods: DELETE, GET"""
+        return self.request(method, "/api/stats/parse-errors", body, params)
+
+    def stats_rollup(self, method="GET", body=None, params=None):
+        """Daily/weekly statistics rollups and maintenance hours
+
+        Methods: GET"""
+        return self.request(method, "/api/stats/rollup", body, params)
+
+    def maintenance(self, method="GET", body=None, params=None):
+        """Service counters, maintenance log and reminders
+
+        Methods: GET, POST"""
+        return self.request(method, "/api/maintenance", body, params)
+
+    def maintenance_enter(self, method="POST", body=None, params=None):
+        """Enter time-limited maintenance mode: cloud forwarding paused, router told, dead-man suspended
+
+        Methods: POST"""
+        return self.request(method, "/api/maintenance/enter", body, params)
+
+    def maintenance_exit(self, method="POST", body=None, params=None):
+        """Leave maintenance mode before its timeout
+
+        Methods: POST"""
+        return self.request(method, "/api/maintenance/exit", body, params)
+
+    def maintenance_mode(self, method="GET", body=None, params=None):
+        """Maintenance mode state and remaining time
+
+        Methods: GET"""
+        return self.request(method, "/api/maintenance/mode", body, params)
+
+    def batteries(self, method="GET", body=None, params=None):
+        """Battery packs, cycles and sag history"""
+        return self.request(method, "/api/batteries", body, params)
+
+    def storage(self, method="GET", body=None, params=None):
+        """API endpoint for disk-space guard status"""
+        return self.request(method, "/api/storage", body, params)
+
+    def uploads(self, method="GET", body=None, params=None):
+        """Upload status of tlogs, video segments and diagnostics bundles"""
+        return self.request(method, "/api/uploads", body, params)
+
+    def export(self, method="GET", body=None, params=None):
+        """Time-windowed flight data export (CSV/JSON) from the flight journal
+
+        Methods: GET"""
+        return self.request(method, "/api/export", body, params)
+
+    def tiles_status(self, method="GET", body=None, params=None):
+        """Map tile cache for offline maps, its status and area pre-seeding"""
+        return self.request(method, "/api/tiles/status", body, params)
+
+    def tiles_seed(self, method="POST", body=None, params=None):
+        """Map tile cache for offline maps, its status and area pre-seeding
+
+        Methods: POST"""
+        return self.request(method, "/api/tiles/seed", body, params)
+
+    def tiles_item(self, sub, method="GET", body=None, params=None):
+        """Map tile cache for offline maps, its status and area pre-seeding
+
+        Methods: GET"""
+        return self.request(method, "/api/tiles/" + urllib.parse.quote(str(sub)), body, params)
+
+    def watchdog(self, method="GET", body=None, params=None):
+        """API endpoint for event loop watchdog status"""
+        return self.request(method, "/api/watchdog", body, params)
+
+    def control(self, method="GET", body=None, params=None):
+        """API endpoint to view control arbitration or take over control
+
+        Methods: GET, POST, PUT"""
+        return self.request(method, "/api/control", body, params)
+
+    def control_remote_piloting(self, method="GET", body=None, params=None):
+        """API endpoint to view control arbitration or take over control
+
+        Methods: GET, POST, PUT"""
+        return self.request(method, "/api/control/remote-piloting", body, params)
+
+    def schedule(self, method="GET", body=None, params=None):
+        """API endpoint for scheduled tasks (next runs, history, run now)
+
+        Methods: GET, POST"""
+        return self.request(method, "/api/schedule", body, params)
+
+    def param_request_list(self, method="POST", body=None, params=None):
+        """API endpoint to request parameter list from Pixhawk
+
+        Methods: POST"""
+        return self.request(method, "/api/param/request-list", body, params)
+
+    def param_status(self, method="GET", body=None, params=None):
+        """API endpoint to get parameter loading status and cached values"""
+        return self.request(method, "/api/param/status", body, params)
+
+    def param_list(self, method="GET", body=None, params=None):
+        """API endpoint to get all cached parameters"""
+        return self.request(method, "/api/param/list", body, params)
+
+    def param_profile(self, method="GET", body=None, params=None):
+        """API endpoints for golden airframe profiles and comparing live parameters against them
+
+        Methods: GET, POST, PUT"""
+        return self.request(method, "/api/param/profile", body, params)
+
+    def param_diff(self, method="GET", body=None, params=None):
+        """API endpoints for golden airframe profiles and comparing live parameters against them"""
+        return self.request(method, "/api/param/diff", body, params)
+
+    def param_get(self, method="GET", body=None, params=None):
+        """API endpoint to get a single cached parameter"""
+        return self.request(method, "/api/param/get", body, params)
+
+    def api_key_status(self, method="GET", body=None, params=None):
+        """Get current API key status
+
+        Methods: GET"""
+        return self.request(method, "/api/v1/drone/api-key/status", body, params)
+
+    def api_key_request(self, method="POST", body=None, params=None):
+        """Request new API key
+
+        Methods: POST"""
+        return self.request(method, "/api/v1/drone/api-key/request", body, params)
+
+    def api_key_revoke(self, method="DELETE", body=None, params=None):
+        """Revoke current API key
+
+        Methods: DELETE"""
+        return self.request(method, "/api/v1/drone/api-key/revoke", body, params)
+
+    def api_key_delete(self, method="DELETE", body=None, params=None):
+        """Delete API key completely
+
+        Methods: DELETE"""
+        return self.request(method, "/api/v1/drone/api-key/delete", body, params)
+
+    def api_key_jobs_item(self, sub, method="GET", body=None, params=None):
+        """Poll a queued API key operation
+
+        Methods: GET"""
+        return self.request(method, "/api/v1/drone/api-key/jobs/" + urllib.parse.quote(str(sub)), body, params)
+
+    def api_key_events(self, method="GET", body=None, params=None):
+        """Stream finished API key operations
+
+        Methods: GET"""
+        return self.request(method, "/api/v1/drone/api-key/events", body, params)
+
+
+def _decode(raw, content_type):
+    if "json" in content_type and raw:
+        return json.loads(raw)
+    return raw.decode(errors="replace")
@@ -0,0 +1,450 @@
+// Code generated by sdk/gen from web/server.go; DO NOT EDIT.
+//
+// Thin client for the DroneBridge HTTP API (browsers, Node 18+, Deno - uses fetch).
+//
+//   const db = new DroneBridgeClient("http://drone.local:8080", { token: "dbt_..." });
+//   console.log(await db.status());
+//   await db.flightTakeoff({ body: { altitude: 10 } });
+
+export type Method = "GET" | "POST" | "PUT" | "PATCH" | "DELETE";
+
+export interface CallOptions {
+  method?: Method;
+  body?: unknown;
+  params?: Record<string, string | number | boolean>;
+}
+
+export class DroneBridgeError extends Error {
+  constructor(public status: number, public code: string, message: string) {
+    super(`${status} ${code}: ${message}`);
+  }
+}
+
+export class DroneBridgeClient {
+  readonly baseUrl: string;
+  token?: string;
+
+  constructor(baseUrl = "http://localhost:8080", opts: { token?: string } = {}) {
+    this.baseUrl = baseUrl.replace(/\/+$/, "");
+    this.token = opts.token;
+  }
+
+  /** Sends a request and returns the decoded JSON (or text) response. */
+  async request<T = any>(method: Method, path: string, body?: unknown, params?: CallOptions["params"]): Promise<T> {
+    let url = this.baseUrl + path;
+    if (params) {
+      const q = new URLSearchParams();
+      for (const [k, v] of Object.entries(params)) q.set(k, String(v));
+      url += "?" + q.toString();
+    }
+    const headers: Record<string, string> = { Accept: "application/json" };
+    if (body !== undefined) headers["Content-Type"] = "application/json";
+    if (this.token) headers["Authorization"] = "Bearer " + this.token;
+    const resp = await fetch(url, { method, headers, body: body === undefined ? undefined : JSON.stringify(body) });
+    const isJSON = (resp.headers.get("Content-Type") ?? "").includes("json");
+    const text = await resp.text();
+    const payload = isJSON && text ? JSON.parse(text) : text;
+    if (!resp.ok) {
+      if (typeof payload === "object" && payload !== null) {
+        throw new DroneBridgeError(resp.status, payload.code ?? "", payload.message ?? "");
+      }
+      throw new DroneBridgeError(resp.status, "", String(payload));
+    }
+    return payload as T;
+  }
+
+  /** API endpoint for status */
+  status<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/status", opts.body, opts.params);
+  }
+
+  /** API endpoint for the dashboard summary (FC section null without a Pixhawk) */
+  dashboard<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/dashboard", opts.body, opts.params);
+  }
+
+  /** API endpoint for connection status */
+  connection<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/connection", opts.body, opts.params);
+  }
+
+  /** API endpoint for setting parameters (Methods: POST) */
+  paramSet<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/param/set", opts.body, opts.params);
+  }
+
+  /** API endpoint to set several parameters with optional rollback (Methods: POST) */
+  paramSetBatch<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/param/set-batch", opts.body, opts.params);
+  }
+
+  /** API endpoint for guided calibration workflows (Methods: GET, POST) */
+  calibration<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/calibration", opts.body, opts.params);
+  }
+
+  /** API endpoint to upload and flash FC firmware (Methods: GET, POST) */
+  firmware<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/firmware", opts.body, opts.params);
+  }
+
+  /** API endpoints for the workspace file manager (bearer token required) (Methods: GET) */
+  files<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/files", opts.body, opts.params);
+  }
+
+  /** API endpoints for the workspace file manager (bearer token required) (Methods: GET) */
+  filesDownload<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/files/download", opts.body, opts.params);
+  }
+
+  /** API endpoints for the workspace file manager (bearer token required) (Methods: POST) */
+  filesUpload<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/files/upload", opts.body, opts.params);
+  }
+
+  /** API endpoints to issue, list and revoke role-scoped API tokens (admin token required) (Methods: DELETE, GET, POST) */
+  tokens<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/tokens", opts.body, opts.params);
+  }
+
+  /** API endpoints to issue, list and revoke role-scoped API tokens (admin token required) (Methods: DELETE, GET, POST) */
+  tokensItem<T = any>(sub: string, opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/tokens/" + encodeURIComponent(sub), opts.body, opts.params);
+  }
+
+  /** API endpoint for click-to-fly in GUIDED mode (Methods: POST) */
+  guidedGoto<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/guided/goto", opts.body, opts.params);
+  }
+
+  /** API endpoints for orbit / point of interest and the command ACK history (Methods: POST) */
+  guidedOrbit<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/guided/orbit", opts.body, opts.params);
+  }
+
+  /** API endpoints for orbit / point of interest and the command ACK history (Methods: POST) */
+  guidedRoi<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/guided/roi", opts.body, opts.params);
+  }
+
+  /** API endpoints for orbit / point of interest and the command ACK history */
+  commands<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/commands", opts.body, opts.params);
+  }
+
+  /** One-click flight actions (mode change + command with ACK verification) (Methods: POST) */
+  flightTakeoff<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/flight/takeoff", opts.body, opts.params);
+  }
+
+  /** One-click flight actions (mode change + command with ACK verification) (Methods: POST) */
+  flightLand<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/flight/land", opts.body, opts.params);
+  }
+
+  /** One-click flight actions (mode change + command with ACK verification) (Methods: POST) */
+  flightRtl<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/flight/rtl", opts.body, opts.params);
+  }
+
+  /** Two-operator emergency stop (force-disarm / flight termination) (Methods: DELETE, GET, POST) */
+  emergencyStop<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/emergency/stop", opts.body, opts.params);
+  }
+
+  /** API endpoints for companion payload outputs (GPIO/PWM) (Methods: GET, POST) */
+  payload<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/payload", opts.body, opts.params);
+  }
+
+  /** API endpoints for companion payload outputs (GPIO/PWM) (Methods: GET, POST) */
+  payloadItem<T = any>(sub: string, opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/payload/" + encodeURIComponent(sub), opts.body, opts.params);
+  }
+
+  /** API endpoint for health check */
+  health<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/health", opts.body, opts.params);
+  }
+
+  /** API endpoint for drone name metadata (Methods: GET, POST, PUT) */
+  identity<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/identity", opts.body, opts.params);
+  }
+
+  /** API endpoint for the pinned FC (GET) and re-pairing (POST) (Methods: GET, POST) */
+  fcPin<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/fc/pin", opts.body, opts.params);
+  }
+
+  /** API endpoint for MAVLink 2 signing state (key fingerprint, counters) (Methods: GET) */
+  signing<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/signing", opts.body, opts.params);
+  }
+
+  /** API endpoint for recent session and API key events (also pushed over /ws/mavlink?events=1) (Methods: GET) */
+  events<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/events", opts.body, opts.params);
+  }
+
+  /** API endpoint for the signed feature list (premium subsystems usable on this drone) (Methods: GET) */
+  entitlements<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/entitlements", opts.body, opts.params);
+  }
+
+  /** Liveness and readiness probes for container orchestrators */
+  healthz<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/healthz", opts.body, opts.params);
+  }
+
+  /** Liveness and readiness probes for container orchestrators */
+  readyz<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/readyz", opts.body, opts.params);
+  }
+
+  /** API endpoint for camera pipelines and encoder statistics */
+  cameraStatus<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/camera/status", opts.body, opts.params);
+  }
+
+  /** API endpoint for decoded flight controller health */
+  healthFc<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/health/fc", opts.body, opts.params);
+  }
+
+  /** Vehicle class, class-specific telemetry and preflight checks */
+  vehicle<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/vehicle", opts.body, opts.params);
+  }
+
+  /** API endpoint for the latest decoded instance of any FC message type (?msg=GLOBAL_POSITION_INT) (Methods: GET) */
+  telemetryLatest<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/telemetry/latest", opts.body, opts.params);
+  }
+
+  /** API endpoint listing the message types available from /api/telemetry/latest (Methods: GET) */
+  telemetryTypes<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/telemetry/types", opts.body, opts.params);
+  }
+
+  /** API endpoint for the startup report (effective config and sanity warnings) */
+  startupReport<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/startup-report", opts.body, opts.params);
+  }
+
+  /** API endpoint for runtime log levels (global, per subsystem, temporary debug) (Methods: GET, POST, PUT) */
+  logLevel<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/log/level", opts.body, opts.params);
+  }
+
+  /** DEBUG: link simulation (latency/jitter/loss, forced IP change) - needs --chaos (Methods: DELETE, GET, POST, PUT) */
+  debugChaos<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/debug/chaos", opts.body, opts.params);
+  }
+
+  /** DEBUG: link simulation (latency/jitter/loss, forced IP change) - needs --chaos (Methods: POST) */
+  debugChaosIpChange<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/debug/chaos/ip-change", opts.body, opts.params);
+  }
+
+  /** DEBUG: soak test progress - needs --soak */
+  debugSoak<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/debug/soak", opts.body, opts.params);
+  }
+
+  /** API endpoint for DroneCAN nodes (ESCs, GPS) reported by the FC */
+  canNodes<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/can/nodes", opts.body, opts.params);
+  }
+
+  /** API endpoint for nearby ADS-B traffic */
+  traffic<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/traffic", opts.body, opts.params);
+  }
+
+  /** API endpoint for nearby bridges on the local mesh */
+  peers<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/peers", opts.body, opts.params);
+  }
+
+  /** API endpoint for the LAN discovery beacon (GET state, POST toggle) (Methods: GET, POST, PUT) */
+  discovery<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/discovery", opts.body, opts.params);
+  }
+
+  /** API endpoint for external I2C/serial sensors */
+  sensors<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/sensors", opts.body, opts.params);
+  }
+
+  /** API endpoints for viewer sessions and the audit log */
+  viewers<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/viewers", opts.body, opts.params);
+  }
+
+  /** API endpoints for viewer sessions and the audit log (Methods: GET) */
+  audit<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/audit", opts.body, opts.params);
+  }
+
+  /** API endpoint to view/switch the forwarding policy (Methods: GET, POST, PUT) */
+  forwardingPolicy<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/forwarding/policy", opts.body, opts.params);
+  }
+
+  /** API endpoint to view/toggle privacy mode (position fuzzing for the cloud) (Methods: GET, POST, PUT) */
+  privacy<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/privacy", opts.body, opts.params);
+  }
+
+  /** API endpoint for per-endpoint channel statistics */
+  channels<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/channels", opts.body, opts.params);
+  }
+
+  /** MAVLink parse errors per channel with recent samples (serial noise vs dialect mismatch) (Methods: DELETE, GET) */
+  statsParseErrors<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/stats/parse-errors", opts.body, opts.params);
+  }
+
+  /** Daily/weekly statistics rollups and maintenance hours (Methods: GET) */
+  statsRollup<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/stats/rollup", opts.body, opts.params);
+  }
+
+  /** Service counters, maintenance log and reminders (Methods: GET, POST) */
+  maintenance<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/maintenance", opts.body, opts.params);
+  }
+
+  /** Enter time-limited maintenance mode: cloud forwarding paused, router told, dead-man suspended (Methods: POST) */
+  maintenanceEnter<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/maintenance/enter", opts.body, opts.params);
+  }
+
+  /** Leave maintenance mode before its timeout (Methods: POST) */
+  maintenanceExit<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/maintenance/exit", opts.body, opts.params);
+  }
+
+  /** Maintenance mode state and remaining time (Methods: GET) */
+  maintenanceMode<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/maintenance/mode", opts.body, opts.params);
+  }
+
+  /** Battery packs, cycles and sag history */
+  batteries<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/batteries", opts.body, opts.params);
+  }
+
+  /** API endpoint for disk-space guard status */
+  storage<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/storage", opts.body, opts.params);
+  }
+
+  /** Upload status of tlogs, video segments and diagnostics bundles */
+  uploads<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/uploads", opts.body, opts.params);
+  }
+
+  /** Time-windowed flight data export (CSV/JSON) from the flight journal (Methods: GET) */
+  export<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/export", opts.body, opts.params);
+  }
+
+  /** Map tile cache for offline maps, its status and area pre-seeding */
+  tilesStatus<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/tiles/status", opts.body, opts.params);
+  }
+
+  /** Map tile cache for offline maps, its status and area pre-seeding (Methods: POST) */
+  tilesSeed<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/tiles/seed", opts.body, opts.params);
+  }
+
+  /** Map tile cache for offline maps, its status and area pre-seeding (Methods: GET) */
+  tilesItem<T = any>(sub: string, opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/tiles/" + encodeURIComponent(sub), opts.body, opts.params);
+  }
+
+  /** API endpoint for event loop watchdog status */
+  watchdog<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/watchdog", opts.body, opts.params);
+  }
+
+  /** API endpoint to view control arbitration or take over control (Methods: GET, POST, PUT) */
+  control<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/control", opts.body, opts.params);
+  }
+
+  /** API endpoint to view control arbitration or take over control (Methods: GET, POST, PUT) */
+  controlRemotePiloting<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/control/remote-piloting", opts.body, opts.params);
+  }
+
+  /** API endpoint for scheduled tasks (next runs, history, run now) (Methods: GET, POST) */
+  schedule<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/schedule", opts.body, opts.params);
+  }
+
+  /** API endpoint to request parameter list from Pixhawk (Methods: POST) */
+  paramRequestList<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/param/request-list", opts.body, opts.params);
+  }
+
+  /** API endpoint to get parameter loading status and cached values */
+  paramStatus<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/param/status", opts.body, opts.params);
+  }
+
+  /** API endpoint to get all cached parameters */
+  paramList<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/param/list", opts.body, opts.params);
+  }
+
+  /** API endpoints for golden airframe profiles and comparing live parameters against them (Methods: GET, POST, PUT) */
+  paramProfile<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/param/profile", opts.body, opts.params);
+  }
+
+  /** API endpoints for golden airframe profiles and comparing live parameters against them */
+  paramDiff<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/param/diff", opts.body, opts.params);
+  }
+
+  /** API endpoint to get a single cached parameter */
+  paramGet<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/param/get", opts.body, opts.params);
+  }
+
+  /** Get current API key status (Methods: GET) */
+  apiKeyStatus<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/v1/drone/api-key/status", opts.body, opts.params);
+  }
+
+  /** Request new API key (Methods: POST) */
+  apiKeyRequest<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "POST", "/api/v1/drone/api-key/request", opts.body, opts.params);
+  }
+
+  /** Revoke current API key (Methods: DELETE) */
+  apiKeyRevoke<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "DELETE", "/api/v1/drone/api-key/revoke", opts.body, opts.params);
+  }
+
+  /** Delete API key completely (Methods: DELETE) */
+  apiKeyDelete<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "DELETE", "/api/v1/drone/api-key/delete", opts.body, opts.params);
+  }
+
+  /** Poll a queued API key operation (Methods: GET) */
+  apiKeyJobsItem<T = any>(sub: string, opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/v1/drone/api-key/jobs/" + encodeURIComponent(sub), opts.body, opts.params);
+  }
+
+  /** Stream finished API key operations (Methods: GET) */
+  apiKeyEvents<T = any>(opts: CallOptions = {}): Promise<T> {
+    return this.request<T>(opts.method ?? "GET", "/api/v1/drone/api-key/events", opts.body, opts.params);
+  }
+}
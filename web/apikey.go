@@ -0,0 +1,255 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"DroneBridge/internal/apikeys"
+	"DroneBridge/internal/auth"
+)
+
+// API key endpoints (compatible with HBQCONNECT format). Operations are queued on
+// apikeys.Global and answered with 202 and a poll URL; the result is the body the
+// endpoint returned when it answered synchronously.
+const (
+	apiKeyJobsPath   = "/api/v1/drone/api-key/jobs/"
+	apiKeyEventsPath = "/api/v1/drone/api-key/events"
+)
+
+// setAPIKeyCORSHeaders allows the HBQCONNECT frontend to call the API key endpoints
+func setAPIKeyCORSHeaders(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "*")
+}
+
+// handleAPIKeyOp returns a handler queueing op for requests with method
+func handleAPIKeyOp(op, method string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		setAPIKeyCORSHeaders(w)
+
+		if r.Method == http.MethodOptions {
+			return
+		}
+		if r.Method != method {
+			writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+			return
+		}
+		if !apikeys.Global.Enabled() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(apiKeyError(ErrAuthUnavailable, "Auth client not initialized"))
+			return
+		}
+
+		// Serve a fresh cached status without a router round trip (?refresh=1 bypasses it)
+		if op == apikeys.OpStatus && r.URL.Query().Get("refresh") == "" {
+			if state, age, ok := apikeys.Global.CachedStatus(); ok {
+				json.NewEncoder(w).Encode(apiKeyStatusBody(state, age))
+				return
+			}
+		}
+
+		expirationHours := 0
+		if op == apikeys.OpRequest {
+			// Parse request body for expiration hours (optional)
+			var req map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&req)
+
+			expirationHours = 24 // Default
+			if v, ok := req["expiration_hours"].(float64); ok {
+				expirationHours = int(v)
+			}
+			if expirationHours < 1 {
+				expirationHours = 1
+			}
+			if expirationHours > 720 { // Max 30 days
+				expirationHours = 720
+			}
+		}
+
+		job, err := apikeys.Global.Submit(op, expirationHours)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(apiKeyError(ErrUnavailable, err.Error()))
+			return
+		}
+		if op != apikeys.OpStatus {
+			log.Printf("[WEB] API key %s queued as job %s", op, job.ID)
+		}
+
+		w.Header().Set("Location", apiKeyJobsPath+job.ID)
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"job_id":     job.ID,
+			"state":      job.State,
+			"poll_url":   apiKeyJobsPath + job.ID,
+			"events_url": apiKeyEventsPath,
+		})
+	}
+}
+
+// apiKeyJobView is a job with the HTTP status and body of its result
+func apiKeyJobView(job apikeys.Job) map[string]interface{} {
+	view := map[string]interface{}{
+		"id":       job.ID,
+		"op":       job.Op,
+		"state":    job.State,
+		"attempts": job.Attempts,
+		"created":  job.Created,
+	}
+	if job.Finished != nil {
+		view["finished"] = job.Finished
+	}
+	if job.State != apikeys.StateDone && job.State != apikeys.StateFailed {
+		return view
+	}
+
+	code, result := apiKeyJobResult(job)
+	view["code"] = code
+	view["result"] = result
+	return view
+}
+
+// apiKeyJobResult converts a finished job to the frontend format
+func apiKeyJobResult(job apikeys.Job) (int, map[string]interface{}) {
+	if job.State == apikeys.StateFailed {
+		switch job.Op {
+		case apikeys.OpStatus:
+			// Report "no key" instead of an error if the session is not ready
+			// so the frontend can gracefully show the "no key" state
+			return http.StatusOK, map[string]interface{}{
+				"has_active_key": false,
+				"status":         "none",
+				"api_key":        nil,
+				"error":          job.Error,
+				"code":           apiKeyFailureCode(job),
+			}
+		case apikeys.OpRequest:
+			if job.Error == "drone already has an active API key" {
+				return http.StatusConflict, apiKeyError(ErrConflict, job.Error)
+			}
+		}
+		return http.StatusInternalServerError, apiKeyError(apiKeyFailureCode(job), job.Error)
+	}
+
+	switch job.Op {
+	case apikeys.OpStatus:
+		state, _ := job.Result.(*auth.APIKeyStatusResponse)
+		if state == nil {
+			return http.StatusInternalServerError, apiKeyError(ErrInternal, "missing API key status")
+		}
+		return http.StatusOK, apiKeyStatusBody(state, time.Since(*job.Finished))
+	case apikeys.OpRequest:
+		state, _ := job.Result.(*auth.APIKeyResponse)
+		if state == nil {
+			return http.StatusInternalServerError, apiKeyError(ErrInternal, "missing API key")
+		}
+		return http.StatusOK, map[string]interface{}{
+			"api_key":        state.APIKey,
+			"created_at":     job.Finished.Format(time.RFC3339),
+			"expires_at":     formatUnixTimestamp(state.ExpiresAt),
+			"user_uuid":      nil,
+			"username":       nil,
+			"user_active_at": nil,
+		}
+	case apikeys.OpRevoke:
+		return http.StatusOK, map[string]interface{}{"message": "API key revoked successfully"}
+	default:
+		return http.StatusOK, map[string]interface{}{"message": "API key deleted successfully"}
+	}
+}
+
+// apiKeyError is an error body in the frontend format ("error" rather than "message")
+func apiKeyError(code ErrorCode, msg string) map[string]interface{} {
+	return map[string]interface{}{"error": msg, "code": code}
+}
+
+// apiKeyFailureCode returns the error code of a failed job
+func apiKeyFailureCode(job apikeys.Job) ErrorCode {
+	if job.NoSession {
+		return ErrSessionExpired
+	}
+	return ErrRouterRejected
+}
+
+// apiKeyStatusBody converts an API key status to the frontend format. age is how
+// long ago the router reported it.
+func apiKeyStatusBody(state *auth.APIKeyStatusResponse, age time.Duration) map[string]interface{} {
+	return map[string]interface{}{
+		"has_active_key":    state.HasActiveKey == 0x01,
+		"status":            state.Status,
+		"api_key":           state.APIKey,
+		"created_at":        formatUnixTimestamp(state.CreatedAt),
+		"expires_at":        formatUnixTimestamp(state.ExpiresAt),
+		"user_uuid":         state.UserUUID,
+		"username":          nil, // TODO: Fetch username from backend DB if needed
+		"user_active_at":    formatUnixTimestamp(state.UserActivatedAt),
+		"cache_age_seconds": age.Seconds(),
+	}
+}
+
+// handleAPIKeyJob serves GET /api/v1/drone/api-key/jobs/<id>
+func handleAPIKeyJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	setAPIKeyCORSHeaders(w)
+
+	if r.Method == http.MethodOptions {
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	job, ok := apikeys.Global.Get(strings.TrimPrefix(r.URL.Path, apiKeyJobsPath))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(apiKeyError(ErrNotFound, "unknown job"))
+		return
+	}
+	json.NewEncoder(w).Encode(apiKeyJobView(job))
+}
+
+// handleAPIKeyEvents streams finished jobs as server-sent "job" events
+func handleAPIKeyEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, ErrInternal, "Streaming not supported")
+		return
+	}
+	// The stream outlives the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	setAPIKeyCORSHeaders(w)
+	flusher.Flush()
+
+	jobs, cancel := apikeys.Global.Subscribe()
+	defer cancel()
+
+	ping := time.NewTicker(15 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case job := <-jobs:
+			data, err := json.Marshal(apiKeyJobView(job))
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: job\ndata: %s\n\n", data)
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,15 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/battery"
+)
+
+// handleBatteries serves tracked battery packs with cycles, health and per-flight sag history
+func handleBatteries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(battery.Global.Snapshot())
+}
@@ -0,0 +1,347 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/ardupilotmega"
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+
+	"DroneBridge/internal/metrics"
+)
+
+// Calibration workflow states
+const (
+	calIdle        = "idle"
+	calRunning     = "running"
+	calWaitingUser = "waiting_user" // FC asked for a vehicle position - POST /api/calibration/next when placed
+	calSuccess     = "success"
+	calFailed      = "failed"
+	calCancelled   = "cancelled"
+)
+
+// Position names requested by ArduPilot accel calibration (ACCELCAL_VEHICLE_POS)
+var accelPositions = map[int]string{
+	1: "Place vehicle level",
+	2: "Place vehicle on its LEFT side",
+	3: "Place vehicle on its RIGHT side",
+	4: "Place vehicle nose DOWN",
+	5: "Place vehicle nose UP",
+	6: "Place vehicle on its BACK",
+}
+
+var px4ProgressPattern = regexp.MustCompile(`\[cal\] progress <(\d+)>`)
+
+// CalibrationStep is one status line of a calibration run
+type CalibrationStep struct {
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+}
+
+// CalibrationStatus is the state of the current (or last) calibration run
+type CalibrationStatus struct {
+	Type        string            `json:"type"` // accel, level, compass, esc
+	State       string            `json:"state"`
+	Progress    int               `json:"progress"` // 0-100 when the FC reports it
+	Instruction string            `json:"instruction,omitempty"`
+	StartedAt   *time.Time        `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time        `json:"finishedAt,omitempty"`
+	Steps       []CalibrationStep `json:"steps"`
+
+	awaitingPos int // ACCELCAL_VEHICLE_POS requested by the FC
+}
+
+var (
+	calMu     sync.Mutex
+	calStatus = CalibrationStatus{State: calIdle, Steps: []CalibrationStep{}}
+)
+
+// calibrationParams returns the MAV_CMD_PREFLIGHT_CALIBRATION params for a workflow
+func calibrationParams(kind string) ([7]float32, error) {
+	var p [7]float32
+	switch kind {
+	case "accel":
+		p[4] = 1 // Full 6-position accelerometer calibration
+	case "level":
+		p[4] = 2 // Board level (AHRS trim)
+	case "compass":
+		p[1] = 1
+	case "esc":
+		p[6] = 1
+	default:
+		return p, fmt.Errorf("unknown calibration type %q (accel, level, compass, esc)", kind)
+	}
+	return p, nil
+}
+
+// sendCommandLong sends a COMMAND_LONG to the autopilot
+func (b *MAVLinkBridge) sendCommandLong(cmd common.MAV_CMD, p [7]float32) error {
+	return b.writeCommandLong(cmd, p, 0)
+}
+
+// writeCommandLong sends a COMMAND_LONG with the given confirmation (retry) count
+func (b *MAVLinkBridge) writeCommandLong(cmd common.MAV_CMD, p [7]float32, confirmation uint8) error {
+	if b == nil || b.node == nil {
+		return errNotInitialized
+	}
+	b.mutex.RLock()
+	connected := b.connected
+	sysID := b.pixhawkSysID
+	b.mutex.RUnlock()
+	if !connected {
+		return errNotConnected
+	}
+
+	return b.writeToFC(&common.MessageCommandLong{
+		TargetSystem:    sysID,
+		TargetComponent: 1,
+		Command:         cmd,
+		Confirmation:    confirmation,
+		Param1:          p[0],
+		Param2:          p[1],
+		Param3:          p[2],
+		Param4:          p[3],
+		Param5:          p[4],
+		Param6:          p[5],
+		Param7:          p[6],
+	})
+}
+
+// calStepLocked appends a status line (caller holds calMu)
+func calStepLocked(text string) {
+	calStatus.Steps = append(calStatus.Steps, CalibrationStep{Time: time.Now(), Text: text})
+	if len(calStatus.Steps) > 200 {
+		calStatus.Steps = calStatus.Steps[1:]
+	}
+}
+
+// calFinishLocked ends the run (caller holds calMu)
+func calFinishLocked(state, text string) {
+	now := time.Now()
+	calStatus.State = state
+	calStatus.FinishedAt = &now
+	calStatus.Instruction = ""
+	calStatus.awaitingPos = 0
+	if state == calSuccess {
+		calStatus.Progress = 100
+	}
+	calStepLocked(text)
+
+	level := "INFO"
+	if state != calSuccess {
+		level = "WARN"
+	}
+	metrics.Global.AddLog(level, fmt.Sprintf("Calibration %s: %s", calStatus.Type, text))
+}
+
+func calActiveLocked() bool {
+	return calStatus.State == calRunning || calStatus.State == calWaitingUser
+}
+
+// HandleCalibrationMessage receives calibration-related messages from the forwarder
+// (STATUSTEXT, MAG_CAL_PROGRESS, MAG_CAL_REPORT, COMMAND_ACK, COMMAND_LONG)
+func HandleCalibrationMessage(msg message.Message) {
+	calMu.Lock()
+	defer calMu.Unlock()
+	if !calActiveLocked() {
+		return
+	}
+
+	switch m := msg.(type) {
+	case *common.MessageStatustext:
+		text := strings.TrimSpace(m.Text)
+		lower := strings.ToLower(text)
+		if match := px4ProgressPattern.FindStringSubmatch(lower); match != nil {
+			calStatus.Progress, _ = strconv.Atoi(match[1])
+			return
+		}
+		switch {
+		case strings.Contains(lower, "cal") && (strings.Contains(lower, "failed") || strings.Contains(lower, "cancel")):
+			calFinishLocked(calFailed, text)
+		case strings.Contains(lower, "calibration successful") || strings.Contains(lower, "calibration done"):
+			calFinishLocked(calSuccess, text)
+		default:
+			calStepLocked(text)
+		}
+
+	case *ardupilotmega.MessageMagCalProgress:
+		calStatus.Progress = int(m.CompletionPct)
+		calStatus.Instruction = "Rotate the vehicle around all axes"
+
+	case *common.MessageMagCalReport:
+		if m.CalStatus == common.MAG_CAL_SUCCESS {
+			calFinishLocked(calSuccess, fmt.Sprintf("Compass %d calibrated (fitness %.1f)", m.CompassId, m.Fitness))
+		} else {
+			calFinishLocked(calFailed, fmt.Sprintf("Compass %d calibration failed (%s)", m.CompassId, m.CalStatus))
+		}
+
+	case *common.MessageCommandAck:
+		if m.Command != common.MAV_CMD_PREFLIGHT_CALIBRATION {
+			return
+		}
+		switch m.Result {
+		case common.MAV_RESULT_ACCEPTED:
+			// Level calibration completes with its ACK; the others only start
+			if calStatus.Type == "level" {
+				calFinishLocked(calSuccess, "Level calibration accepted")
+			} else {
+				calStepLocked("Calibration started")
+			}
+		case common.MAV_RESULT_IN_PROGRESS:
+			calStatus.Progress = int(m.Progress)
+		default:
+			calFinishLocked(calFailed, fmt.Sprintf("FC rejected calibration (%s)", m.Result))
+		}
+
+	case *common.MessageCommandLong:
+		// ArduPilot asks the GCS to place the vehicle for accel calibration
+		if m.Command != common.MAV_CMD(ardupilotmega.MAV_CMD_ACCELCAL_VEHICLE_POS) {
+			return
+		}
+		switch pos := ardupilotmega.ACCELCAL_VEHICLE_POS(m.Param1); pos {
+		case ardupilotmega.ACCELCAL_VEHICLE_POS_SUCCESS:
+			calFinishLocked(calSuccess, "Accelerometer calibration successful")
+		case ardupilotmega.ACCELCAL_VEHICLE_POS_FAILED:
+			calFinishLocked(calFailed, "Accelerometer calibration failed")
+		default:
+			calStatus.awaitingPos = int(pos)
+			calStatus.State = calWaitingUser
+			calStatus.Instruction = accelPositions[int(pos)]
+			calStatus.Progress = (int(pos) - 1) * 100 / len(accelPositions)
+			calStepLocked(calStatus.Instruction)
+		}
+	}
+}
+
+// StartCalibration begins a calibration workflow
+func (b *MAVLinkBridge) StartCalibration(kind string) error {
+	params, err := calibrationParams(kind)
+	if err != nil {
+		return err
+	}
+	// Calibrating reboots sensors and, for ESCs, spins the motors: never in flight
+	if !b.IsConnected() {
+		return errNotConnected
+	}
+	if b.IsArmed() {
+		return errAlreadyArmed
+	}
+
+	calMu.Lock()
+	if calActiveLocked() {
+		calMu.Unlock()
+		return fmt.Errorf("%s calibration already in progress", calStatus.Type)
+	}
+	now := time.Now()
+	calStatus = CalibrationStatus{
+		Type:      kind,
+		State:     calRunning,
+		StartedAt: &now,
+		Steps:     []CalibrationStep{},
+	}
+	calStepLocked(fmt.Sprintf("Sending MAV_CMD_PREFLIGHT_CALIBRATION (%s)", kind))
+	if kind == "esc" {
+		calStatus.Instruction = "Remove propellers, then follow the FC tones to connect the battery"
+	}
+	calMu.Unlock()
+
+	if err := b.sendCommandLong(common.MAV_CMD_PREFLIGHT_CALIBRATION, params); err != nil {
+		calMu.Lock()
+		calFinishLocked(calFailed, err.Error())
+		calMu.Unlock()
+		return err
+	}
+	log.Printf("[WEB] Started %s calibration", kind)
+	return nil
+}
+
+// NextCalibrationStep confirms the vehicle is in the position the FC asked for
+func (b *MAVLinkBridge) NextCalibrationStep() error {
+	calMu.Lock()
+	pos := calStatus.awaitingPos
+	if calStatus.State != calWaitingUser || pos == 0 {
+		calMu.Unlock()
+		return fmt.Errorf("calibration is not waiting for a vehicle position")
+	}
+	calStatus.State = calRunning
+	calStepLocked(fmt.Sprintf("Position %d confirmed", pos))
+	calMu.Unlock()
+
+	return b.sendCommandLong(common.MAV_CMD(ardupilotmega.MAV_CMD_ACCELCAL_VEHICLE_POS), [7]float32{float32(pos)})
+}
+
+// CancelCalibration aborts the running workflow
+func (b *MAVLinkBridge) CancelCalibration() error {
+	calMu.Lock()
+	if !calActiveLocked() {
+		calMu.Unlock()
+		return fmt.Errorf("no calibration in progress")
+	}
+	kind := calStatus.Type
+	calFinishLocked(calCancelled, "Cancelled by user")
+	calMu.Unlock()
+
+	if kind == "compass" {
+		return b.sendCommandLong(common.MAV_CMD(ardupilotmega.MAV_CMD_DO_CANCEL_MAG_CAL), [7]float32{})
+	}
+	// All-zero PREFLIGHT_CALIBRATION cancels a running calibration
+	return b.sendCommandLong(common.MAV_CMD_PREFLIGHT_CALIBRATION, [7]float32{})
+}
+
+// GetCalibrationStatus returns a copy of the calibration status
+func GetCalibrationStatus() CalibrationStatus {
+	calMu.Lock()
+	defer calMu.Unlock()
+	s := calStatus
+	s.Steps = append([]CalibrationStep(nil), calStatus.Steps...)
+	return s
+}
+
+// handleCalibration serves the calibration workflow:
+// GET status, POST {"action":"start","type":"accel|level|compass|esc"}, {"action":"next"} or {"action":"cancel"}
+func handleCalibration(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			Action string `json:"action"`
+			Type   string `json:"type"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+
+		var err error
+		switch req.Action {
+		case "start":
+			err = bridge.StartCalibration(req.Type)
+		case "next":
+			err = bridge.NextCalibrationStep()
+		case "cancel":
+			err = bridge.CancelCalibration()
+		default:
+			err = fmt.Errorf("unknown action %q (start, next, cancel)", req.Action)
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errorCode(err, ErrValidation), err.Error())
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	json.NewEncoder(w).Encode(GetCalibrationStatus())
+}
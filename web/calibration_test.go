@@ -0,0 +1,40 @@
+package web
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStartCalibrationRefused(t *testing.T) {
+	calMu.Lock()
+	prev := calStatus
+	calMu.Unlock()
+	t.Cleanup(func() {
+		calMu.Lock()
+		calStatus = prev
+		calMu.Unlock()
+	})
+
+	tests := []struct {
+		name      string
+		connected bool
+		armed     bool
+		want      error
+	}{
+		{"disconnected", false, false, errNotConnected},
+		{"armed", true, true, errAlreadyArmed},
+	}
+	for _, tt := range tests {
+		calMu.Lock()
+		calStatus = CalibrationStatus{State: calIdle, Steps: []CalibrationStep{}}
+		calMu.Unlock()
+
+		b := &MAVLinkBridge{connected: tt.connected, armed: tt.armed}
+		if err := b.StartCalibration("esc"); !errors.Is(err, tt.want) {
+			t.Errorf("%s: StartCalibration() = %v, want %v", tt.name, err, tt.want)
+		}
+		if s := GetCalibrationStatus(); s.State != calIdle {
+			t.Errorf("%s: state = %v, want %v", tt.name, s.State, calIdle)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/camera"
+)
+
+// handleCameraStatus serves per-camera running state and encoder statistics
+// (fps, bitrate, keyframe cadence, dropped/duplicated frames)
+func handleCameraStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"cameras": camera.StatusAll(),
+	})
+}
@@ -0,0 +1,23 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/dronecan"
+)
+
+// handleCANNodes serves DroneCAN node health and firmware versions forwarded by the FC
+func handleCANNodes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(dronecan.Global.Snapshot())
+}
+
+// RequestCANNodeInfo asks the FC to send UAVCAN_NODE_INFO for every node it knows
+func RequestCANNodeInfo() error {
+	_, err := bridge.SendCommand(common.MAV_CMD_UAVCAN_GET_NODE_INFO, [7]float32{})
+	return err
+}
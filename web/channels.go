@@ -0,0 +1,17 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/channels"
+)
+
+// handleChannels serves per-endpoint channel open/close statistics
+func handleChannels(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"endpoints": channels.Global.Snapshot(),
+	})
+}
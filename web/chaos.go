@@ -0,0 +1,63 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"DroneBridge/internal/chaos"
+)
+
+// handleChaos returns the link simulation state (GET), sets impairments (PUT/POST)
+// with {"latencyMs": 300, "jitterMs": 100, "lossPercent": 5}, or clears them and
+// any simulated IP (DELETE). Only available when started with --chaos.
+func handleChaos(w http.ResponseWriter, r *http.Request) {
+	if !chaos.Global.Enabled() {
+		writeError(w, http.StatusNotFound, ErrFeatureDisabled, "Link simulation disabled (start with --chaos)")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req chaos.Settings
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		if err := chaos.Global.Set(req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrValidation, err.Error())
+			return
+		}
+	case http.MethodDelete:
+		chaos.Global.Set(chaos.Settings{})
+		chaos.Global.ClearIP()
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	json.NewEncoder(w).Encode(chaos.Global.Snapshot())
+}
+
+// handleChaosIPChange makes the IP monitor see a new local IP on its next check
+// (within 5 s), running the full reconnect sequence
+func handleChaosIPChange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	ip, err := chaos.Global.ForceIPChange()
+	if err != nil {
+		writeError(w, http.StatusNotFound, ErrNotFound, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Local IP will be reported as " + ip,
+		"fakeIP":  ip,
+	})
+}
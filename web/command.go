@@ -0,0 +1,217 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
+)
+
+const (
+	commandAckTimeout = 3 * time.Second // Per attempt
+	commandRetries    = 3               // Attempts before giving up (confirmation is incremented each retry)
+)
+
+// commandWaiters holds callers waiting for a COMMAND_ACK, keyed by command
+var (
+	commandMu      sync.Mutex
+	commandWaiters = make(map[common.MAV_CMD][]chan *common.MessageCommandAck)
+
+	// prearmLines collects "PreArm:" STATUSTEXT while a self-test is running (nil otherwise)
+	prearmLines []string
+)
+
+// HandleCommandMessage receives COMMAND_ACK and STATUSTEXT from the forwarder
+func HandleCommandMessage(msg message.Message) {
+	commandMu.Lock()
+	defer commandMu.Unlock()
+
+	switch m := msg.(type) {
+	case *common.MessageCommandAck:
+		// IN_PROGRESS is followed by a final ACK, keep waiting for it
+		if m.Result == common.MAV_RESULT_IN_PROGRESS {
+			return
+		}
+		for _, ch := range commandWaiters[m.Command] {
+			select {
+			case ch <- m:
+			default:
+			}
+		}
+		delete(commandWaiters, m.Command)
+	case *common.MessageStatustext:
+		if prearmLines != nil && strings.HasPrefix(m.Text, "PreArm") {
+			prearmLines = append(prearmLines, m.Text)
+		}
+	}
+}
+
+// CommandRecord is one command sent through the command API and its outcome
+type CommandRecord struct {
+	Command  string    `json:"command"`
+	Sent     time.Time `json:"sent"`
+	Attempts int       `json:"attempts"`
+	Result   string    `json:"result,omitempty"` // MAV_RESULT name (empty if no ACK)
+	Error    string    `json:"error,omitempty"`
+}
+
+// commandHistory keeps the most recent commands for GET /api/commands
+var commandHistory []CommandRecord
+
+// SendCommand sends a COMMAND_LONG and waits for its final COMMAND_ACK, retrying
+// with an incremented confirmation field when no ACK arrives
+func (b *MAVLinkBridge) SendCommand(cmd common.MAV_CMD, p [7]float32) (common.MAV_RESULT, error) {
+	return b.sendAndWait(cmd, func(attempt int) error {
+		return b.writeCommandLong(cmd, p, uint8(attempt))
+	})
+}
+
+// SendCommandInt sends a COMMAND_INT with a global position (full lat/lon precision)
+// and waits for its final COMMAND_ACK, resending when no ACK arrives
+func (b *MAVLinkBridge) SendCommandInt(cmd common.MAV_CMD, frame common.MAV_FRAME, p [4]float32, lat, lon float64, alt float32) (common.MAV_RESULT, error) {
+	return b.sendAndWait(cmd, func(int) error {
+		if b == nil || b.node == nil {
+			return errNotInitialized
+		}
+		b.mutex.RLock()
+		connected := b.connected
+		sysID := b.pixhawkSysID
+		b.mutex.RUnlock()
+		if !connected {
+			return errNotConnected
+		}
+		return b.writeToFC(&common.MessageCommandInt{
+			TargetSystem:    sysID,
+			TargetComponent: 1,
+			Frame:           frame,
+			Command:         cmd,
+			Param1:          p[0],
+			Param2:          p[1],
+			Param3:          p[2],
+			Param4:          p[3],
+			X:               int32(lat * 1e7),
+			Y:               int32(lon * 1e7),
+			Z:               alt,
+		})
+	})
+}
+
+// sendAndWait registers for the command's ACK, then writes it up to commandRetries times
+func (b *MAVLinkBridge) sendAndWait(cmd common.MAV_CMD, write func(attempt int) error) (common.MAV_RESULT, error) {
+	rec := CommandRecord{Command: cmd.String(), Sent: time.Now()}
+	ch := make(chan *common.MessageCommandAck, 1)
+	commandMu.Lock()
+	commandWaiters[cmd] = append(commandWaiters[cmd], ch)
+	commandMu.Unlock()
+	defer func() {
+		commandMu.Lock()
+		waiters := commandWaiters[cmd]
+		for i, w := range waiters {
+			if w == ch {
+				commandWaiters[cmd] = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(commandWaiters[cmd]) == 0 {
+			delete(commandWaiters, cmd)
+		}
+		commandHistory = append(commandHistory, rec)
+		if len(commandHistory) > 50 {
+			commandHistory = commandHistory[1:]
+		}
+		commandMu.Unlock()
+	}()
+
+	for attempt := 0; attempt < commandRetries; attempt++ {
+		rec.Attempts++
+		if err := write(attempt); err != nil {
+			rec.Error = err.Error()
+			return 0, err
+		}
+		select {
+		case ack := <-ch:
+			rec.Result = ack.Result.String()
+			return ack.Result, nil
+		case <-time.After(commandAckTimeout):
+			log.Printf("[WEB] No ACK for %s (attempt %d/%d)", cmd, attempt+1, commandRetries)
+		}
+	}
+	err := fmt.Errorf("%w for %s after %d attempts", errNoCommandAck, cmd, commandRetries)
+	rec.Error = err.Error()
+	return 0, err
+}
+
+// handleCommands returns the recent command history, newest first
+func handleCommands(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	commandMu.Lock()
+	history := make([]CommandRecord, len(commandHistory))
+	for i, rec := range commandHistory {
+		history[len(commandHistory)-1-i] = rec
+	}
+	commandMu.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"commands": history,
+	})
+}
+
+// RunPreflightSelfTest asks the FC to run its pre-arm checks and reports any failures
+func RunPreflightSelfTest() error {
+	if bridge == nil {
+		return errNotInitialized
+	}
+	return bridge.runPrearmChecks()
+}
+
+// runPrearmChecks runs MAV_CMD_RUN_PREARM_CHECKS and collects "PreArm:" failures
+func (b *MAVLinkBridge) runPrearmChecks() error {
+	if b.IsArmed() {
+		return fmt.Errorf("vehicle is armed - pre-arm checks skipped")
+	}
+
+	commandMu.Lock()
+	prearmLines = []string{}
+	commandMu.Unlock()
+	defer func() {
+		commandMu.Lock()
+		prearmLines = nil
+		commandMu.Unlock()
+	}()
+
+	result, err := b.SendCommand(common.MAV_CMD_RUN_PREARM_CHECKS, [7]float32{})
+	if err != nil {
+		return err
+	}
+	if result != common.MAV_RESULT_ACCEPTED {
+		return fmt.Errorf("pre-arm checks rejected: %s", result)
+	}
+
+	// Failures are reported as STATUSTEXT shortly after the ACK
+	time.Sleep(2 * time.Second)
+
+	commandMu.Lock()
+	failures := append([]string(nil), prearmLines...)
+	commandMu.Unlock()
+
+	// The FC does not know which sensors the dashboards rely on for this vehicle class
+	_, checks := bridgePreflight()
+	for _, c := range checks {
+		if !c.Passed {
+			failures = append(failures, fmt.Sprintf("Bridge: %s %s", c.Name, c.Detail))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("pre-arm checks failed: %s", strings.Join(failures, "; "))
+	}
+	log.Printf("[WEB] ✅ Pre-arm checks passed")
+	return nil
+}
@@ -0,0 +1,73 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"DroneBridge/internal/audit"
+	"DroneBridge/internal/control"
+	"DroneBridge/internal/metrics"
+)
+
+// handleControl returns the control arbitration state (GET) or sets the owner
+// (PUT/POST, requires an admin token: issued, tokens.admin_token or control.api_token)
+func handleControl(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req struct {
+			Owner string `json:"owner"` // "local" or "cloud"
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		if err := control.Global.Takeover(req.Owner); err != nil {
+			writeError(w, http.StatusBadRequest, ErrControlDenied, err.Error())
+			return
+		}
+		id := requestIdentity(r)
+		log.Printf("[WEB] Control owner set to '%s' by %s (%s)", req.Owner, id.ID, r.RemoteAddr)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Control owner set to '%s' by %s", req.Owner, id.ID))
+		audit.Global.Record("control", "takeover", map[string]interface{}{"owner": req.Owner, "token": id.ID, "remote": r.RemoteAddr})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	json.NewEncoder(w).Encode(control.Global.Snapshot())
+}
+
+// handleRemotePiloting returns the RC override guard state (GET) or enables/disables
+// remote piloting (POST, requires an admin token: issued, tokens.admin_token or control.api_token)
+func handleRemotePiloting(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req struct {
+			Enabled bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		id := requestIdentity(r)
+		control.Global.SetRemotePiloting(req.Enabled, id.ID)
+		log.Printf("[WEB] Remote piloting set to %v by %s (%s)", req.Enabled, id.ID, r.RemoteAddr)
+		metrics.Global.AddLog("WARN", fmt.Sprintf("Remote piloting set to %v by %s", req.Enabled, id.ID))
+		audit.Global.Record("control", "remote_piloting", map[string]interface{}{"enabled": req.Enabled, "token": id.ID, "remote": r.RemoteAddr})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	json.NewEncoder(w).Encode(control.Global.GuardSnapshot())
+}
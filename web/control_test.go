@@ -0,0 +1,45 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"DroneBridge/internal/audit"
+	"DroneBridge/internal/control"
+	"DroneBridge/internal/tokens"
+)
+
+func TestRemotePilotingIdentity(t *testing.T) {
+	if err := audit.Global.Open(filepath.Join(t.TempDir(), "audit.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		audit.Global.Open("")
+		control.Global.SetRemotePiloting(false, "test")
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/api/control/remote-piloting", strings.NewReader(`{"enabled":true}`))
+	r.RemoteAddr = "10.0.0.1:5000"
+	r = r.WithContext(context.WithValue(r.Context(), identityKey{}, tokens.Identity{ID: "tok-a", Role: tokens.RoleAdmin}))
+	w := httptest.NewRecorder()
+	handleRemotePiloting(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d (%s)", w.Code, http.StatusOK, w.Body)
+	}
+
+	if g := control.Global.GuardSnapshot(); !g.RemotePiloting || g.ChangedBy != "tok-a" {
+		t.Errorf("guard = %v by %q, want true by %q", g.RemotePiloting, g.ChangedBy, "tok-a")
+	}
+	entries, err := audit.Global.Query("control", time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Event != "remote_piloting" || entries[0].Fields["token"] != "tok-a" || entries[0].Fields["enabled"] != true {
+		t.Errorf("audit entries = %+v, want one remote_piloting entry by tok-a", entries)
+	}
+}
@@ -0,0 +1,38 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"DroneBridge/internal/discovery"
+	"DroneBridge/internal/metrics"
+)
+
+// handleDiscovery shows the LAN discovery beacon (GET) or switches it on and
+// off (POST {"enabled": true})
+func handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		var req struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, `Invalid request body: expected {"enabled": true|false}`)
+			return
+		}
+		discovery.Global.SetEnabled(*req.Enabled)
+		log.Printf("[WEB] Discovery beacon set to %v by %s", *req.Enabled, r.RemoteAddr)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Discovery beacon enabled=%v", *req.Enabled))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	json.NewEncoder(w).Encode(discovery.Global.Snapshot())
+}
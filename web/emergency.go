@@ -0,0 +1,281 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/audit"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/tokens"
+)
+
+const (
+	emergencyConfirmWindow = 30 * time.Second // A second operator must confirm within this time
+	forceDisarmMagic       = 21196            // MAV_CMD_COMPONENT_ARM_DISARM param2: disarm even in flight
+)
+
+// Emergency stop actions
+const (
+	emergencyDisarm    = "disarm"    // Force-disarm: motors stop immediately, the vehicle falls
+	emergencyTerminate = "terminate" // MAV_CMD_DO_FLIGHTTERMINATION (autopilot's termination handling)
+	emergencyBoth      = "both"      // Terminate, then force-disarm
+)
+
+// emergencyRequest is an emergency stop waiting for a second operator
+type emergencyRequest struct {
+	ID          string    `json:"id,omitempty"` // Confirmation ID, only shown to admin tokens
+	Action      string    `json:"action"`
+	Reason      string    `json:"reason"`
+	RequestedBy string    `json:"requestedBy"`
+	Token       string    `json:"token"` // Identity of the requesting token (never the secret)
+	Remote      string    `json:"remote"`
+	Requested   time.Time `json:"requested"`
+	Expires     time.Time `json:"expires"`
+}
+
+// emergencyResult is the outcome of an executed emergency stop
+type emergencyResult struct {
+	emergencyRequest
+	ConfirmedBy string    `json:"confirmedBy"`
+	Executed    time.Time `json:"executed"`
+	Success     bool      `json:"success"`
+	Steps       []string  `json:"steps"`
+	Error       string    `json:"error,omitempty"`
+}
+
+var (
+	emergencyMu      sync.Mutex
+	emergencyPending *emergencyRequest
+	emergencyLast    *emergencyResult
+)
+
+// pendingEmergencyLocked returns the pending request, dropping it once expired (caller holds emergencyMu)
+func pendingEmergencyLocked(now time.Time) *emergencyRequest {
+	if emergencyPending != nil && now.After(emergencyPending.Expires) {
+		log.Printf("[EMERGENCY] Stop request %s (%s by %s) expired unconfirmed", emergencyPending.ID, emergencyPending.Action, emergencyPending.RequestedBy)
+		metrics.Global.AddLog("WARN", fmt.Sprintf("Emergency stop request by %s expired unconfirmed", emergencyPending.RequestedBy))
+		audit.Global.Record("emergency", "expired", map[string]interface{}{"id": emergencyPending.ID, "action": emergencyPending.Action, "requestedBy": emergencyPending.RequestedBy})
+		emergencyPending = nil
+	}
+	return emergencyPending
+}
+
+// EmergencyStop force-disarms and/or terminates the flight. It skips every
+// interlock: callers must have gone through the two-operator confirmation.
+func (b *MAVLinkBridge) EmergencyStop(action string) (*flightSequence, error) {
+	seq := &flightSequence{Steps: []string{}}
+	if !b.IsConnected() {
+		return seq, errNotConnected
+	}
+	if action == emergencyTerminate || action == emergencyBoth {
+		if err := seq.command(b, "Flight termination", common.MAV_CMD_DO_FLIGHTTERMINATION, [7]float32{1}); err != nil {
+			if action == emergencyTerminate {
+				return seq, err
+			}
+			// Termination may be disabled on the FC (AFS_ENABLE); still cut the motors
+			seq.step("%v - falling back to force-disarm", err)
+		}
+	}
+	if action == emergencyDisarm || action == emergencyBoth {
+		if err := seq.command(b, "Force-disarm", common.MAV_CMD_COMPONENT_ARM_DISARM, [7]float32{0, forceDisarmMagic}); err != nil {
+			return seq, err
+		}
+	}
+	return seq, nil
+}
+
+// remoteHost returns a client address without its port
+func remoteHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// handleEmergencyStop serves the two-operator emergency stop. Everything but
+// GET needs an admin token, even with tokens.required off, and the confirming
+// operator must use a different token from a different address:
+//
+//	GET                                                   pending request and last result (ID for admin tokens only)
+//	POST {"action": "disarm|terminate|both", "operator": "alice", "reason": "..."}  request a stop
+//	POST {"confirmId": "<id>", "operator": "bob"}         second operator executes it
+//	DELETE                                                cancel the pending request
+func handleEmergencyStop(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+		emergencyMu.Lock()
+		defer emergencyMu.Unlock()
+		var pending *emergencyRequest
+		if p := pendingEmergencyLocked(time.Now()); p != nil {
+			view := *p
+			if requestRole(r) != tokens.RoleAdmin {
+				view.ID = "" // Knowing the ID is enough to confirm
+			}
+			pending = &view
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"pending": pending,
+			"last":    emergencyLast,
+		})
+	case http.MethodPost:
+		var req struct {
+			Action    string `json:"action"`
+			Operator  string `json:"operator"`
+			Reason    string `json:"reason"`
+			ConfirmID string `json:"confirmId"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		req.Operator = strings.TrimSpace(req.Operator)
+		if req.Operator == "" {
+			writeError(w, http.StatusBadRequest, ErrValidation, "operator is required - the stop needs two named operators")
+			return
+		}
+		if req.ConfirmID != "" {
+			confirmEmergencyStop(w, r, req.ConfirmID, req.Operator)
+			return
+		}
+		requestEmergencyStop(w, r, req.Action, req.Operator, req.Reason)
+	case http.MethodDelete:
+		emergencyMu.Lock()
+		p := pendingEmergencyLocked(time.Now())
+		emergencyPending = nil
+		emergencyMu.Unlock()
+		if p == nil {
+			writeError(w, http.StatusNotFound, ErrNotFound, "No emergency stop is pending")
+			return
+		}
+		log.Printf("[EMERGENCY] Stop request %s cancelled by %s (%s)", p.ID, requestIdentity(r).ID, r.RemoteAddr)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Emergency stop request by %s cancelled", p.RequestedBy))
+		audit.Global.Record("emergency", "cancel", map[string]interface{}{"id": p.ID, "action": p.Action, "requestedBy": p.RequestedBy, "token": requestIdentity(r).ID, "remote": r.RemoteAddr})
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "message": "Emergency stop request cancelled"})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// requestEmergencyStop registers a stop that a second operator has to confirm
+func requestEmergencyStop(w http.ResponseWriter, r *http.Request, action, operator, reason string) {
+	switch action {
+	case emergencyDisarm, emergencyTerminate, emergencyBoth:
+	default:
+		writeError(w, http.StatusBadRequest, ErrValidation, `action must be "disarm", "terminate" or "both"`)
+		return
+	}
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		writeError(w, http.StatusInternalServerError, ErrInternal, "Failed to create confirmation ID")
+		return
+	}
+
+	now := time.Now()
+	emergencyMu.Lock()
+	if p := pendingEmergencyLocked(now); p != nil {
+		emergencyMu.Unlock()
+		writeError(w, http.StatusConflict, ErrConflict, fmt.Sprintf("Emergency stop %s by %s is already pending - confirm or cancel it", p.ID, p.RequestedBy))
+		return
+	}
+	p := &emergencyRequest{
+		ID:          hex.EncodeToString(idBytes),
+		Action:      action,
+		Reason:      reason,
+		RequestedBy: operator,
+		Token:       requestIdentity(r).ID,
+		Remote:      r.RemoteAddr,
+		Requested:   now,
+		Expires:     now.Add(emergencyConfirmWindow),
+	}
+	emergencyPending = p
+	emergencyMu.Unlock()
+
+	log.Printf("[EMERGENCY] ⚠️ Stop (%s) requested by %s from %s: %q - awaiting a second operator (id %s)", action, operator, r.RemoteAddr, reason, p.ID)
+	metrics.Global.AddLog("WARN", fmt.Sprintf("Emergency stop (%s) requested by %s: %s - awaiting confirmation", action, operator, reason))
+	audit.Global.Record("emergency", "request", map[string]interface{}{"id": p.ID, "action": action, "operator": operator, "reason": reason, "token": p.Token, "remote": r.RemoteAddr})
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Emergency stop pending - a second operator must confirm within %v", emergencyConfirmWindow),
+		"pending": p,
+	})
+}
+
+// confirmEmergencyStop executes the pending stop for a second operator
+func confirmEmergencyStop(w http.ResponseWriter, r *http.Request, id, operator string) {
+	token := requestIdentity(r).ID
+	emergencyMu.Lock()
+	p := pendingEmergencyLocked(time.Now())
+	switch {
+	case p == nil || p.ID != id:
+		emergencyMu.Unlock()
+		writeError(w, http.StatusNotFound, ErrNotFound, "No pending emergency stop with this ID (expired or cancelled?)")
+		return
+	case strings.EqualFold(p.RequestedBy, operator), p.Token == token, remoteHost(r.RemoteAddr) == remoteHost(p.Remote):
+		emergencyMu.Unlock()
+		log.Printf("[EMERGENCY] %s tried to confirm stop request %s by %s with token %s from %s", operator, id, p.RequestedBy, token, r.RemoteAddr)
+		audit.Global.Record("emergency", "self_confirm_refused", map[string]interface{}{"id": id, "operator": operator, "token": token, "remote": r.RemoteAddr})
+		writeError(w, http.StatusForbidden, ErrForbidden, "The stop must be confirmed by a different operator with a different token from a different address")
+		return
+	}
+	emergencyPending = nil
+	emergencyMu.Unlock()
+
+	log.Printf("[EMERGENCY] ⚠️ Stop %s (%s) confirmed by %s from %s - executing", id, p.Action, operator, r.RemoteAddr)
+	metrics.Global.AddLog("ERROR", fmt.Sprintf("EMERGENCY STOP (%s) requested by %s, confirmed by %s: %s", p.Action, p.RequestedBy, operator, p.Reason))
+	audit.Global.Record("emergency", "confirm", map[string]interface{}{"id": id, "action": p.Action, "requestedBy": p.RequestedBy, "confirmedBy": operator, "token": token, "remote": r.RemoteAddr})
+
+	var seq *flightSequence
+	err := errNotInitialized
+	if bridge != nil {
+		seq, err = bridge.EmergencyStop(p.Action)
+	} else {
+		seq = &flightSequence{Steps: []string{}}
+	}
+
+	res := &emergencyResult{emergencyRequest: *p, ConfirmedBy: operator, Executed: time.Now(), Success: err == nil, Steps: seq.Steps}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	emergencyMu.Lock()
+	emergencyLast = res
+	emergencyMu.Unlock()
+	for _, step := range seq.Steps {
+		log.Printf("[EMERGENCY]   %s", step)
+	}
+	audit.Global.Record("emergency", "result", map[string]interface{}{"id": id, "action": p.Action, "success": res.Success, "steps": res.Steps, "error": res.Error})
+
+	if err != nil {
+		log.Printf("[EMERGENCY] ❌ Stop %s failed: %v", id, err)
+		metrics.Global.AddLog("ERROR", fmt.Sprintf("Emergency stop failed: %v", err))
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"code":    errorCode(err, ErrInternal),
+			"message": err.Error(),
+			"result":  res,
+		})
+		return
+	}
+	log.Printf("[EMERGENCY] Stop %s executed", id)
+	metrics.Global.AddLog("ERROR", fmt.Sprintf("Emergency stop (%s) executed", p.Action))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Emergency stop (%s) executed", p.Action),
+		"result":  res,
+	})
+}
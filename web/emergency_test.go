@@ -0,0 +1,123 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"DroneBridge/internal/tokens"
+)
+
+// emergencyCall runs handleEmergencyStop as the given token from remote
+func emergencyCall(method, body, tokenID, role, remote string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/api/emergency/stop", strings.NewReader(body))
+	r.RemoteAddr = remote
+	if tokenID != "" {
+		r = r.WithContext(context.WithValue(r.Context(), identityKey{}, tokens.Identity{ID: tokenID, Role: role}))
+	}
+	w := httptest.NewRecorder()
+	handleEmergencyStop(w, r)
+	return w
+}
+
+func resetEmergency(t *testing.T) {
+	t.Helper()
+	emergencyMu.Lock()
+	emergencyPending, emergencyLast = nil, nil
+	emergencyMu.Unlock()
+	t.Cleanup(func() {
+		emergencyMu.Lock()
+		emergencyPending, emergencyLast = nil, nil
+		emergencyMu.Unlock()
+	})
+}
+
+func TestEmergencyStopConfirmation(t *testing.T) {
+	resetEmergency(t)
+
+	w := emergencyCall(http.MethodPost, `{"action":"disarm","operator":"alice","reason":"flyaway"}`, "tok-a", tokens.RoleAdmin, "10.0.0.1:5000")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("request: status %d, want %d (%s)", w.Code, http.StatusAccepted, w.Body)
+	}
+	emergencyMu.Lock()
+	id := emergencyPending.ID
+	emergencyMu.Unlock()
+
+	confirm := func(operator string) string {
+		return `{"confirmId":"` + id + `","operator":"` + operator + `"}`
+	}
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		token  string
+		remote string
+		want   int
+	}{
+		{"second request while pending", http.MethodPost, `{"action":"both","operator":"carol"}`, "tok-c", "10.0.0.3:5000", http.StatusConflict},
+		{"no operator", http.MethodPost, `{"confirmId":"` + id + `"}`, "tok-b", "10.0.0.2:5000", http.StatusBadRequest},
+		{"wrong ID", http.MethodPost, `{"confirmId":"0000","operator":"bob"}`, "tok-b", "10.0.0.2:5000", http.StatusNotFound},
+		{"same operator name", http.MethodPost, confirm("Alice"), "tok-b", "10.0.0.2:5000", http.StatusForbidden},
+		{"same token", http.MethodPost, confirm("bob"), "tok-a", "10.0.0.2:5000", http.StatusForbidden},
+		{"same address, other port", http.MethodPost, confirm("bob"), "tok-b", "10.0.0.1:6000", http.StatusForbidden},
+		// No bridge in tests: the stop is confirmed but cannot reach the FC
+		{"second operator", http.MethodPost, confirm("bob"), "tok-b", "10.0.0.2:5000", http.StatusBadGateway},
+		{"confirmed twice", http.MethodPost, confirm("dave"), "tok-d", "10.0.0.4:5000", http.StatusNotFound},
+		{"cancel with nothing pending", http.MethodDelete, "", "tok-a", "10.0.0.1:5000", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := emergencyCall(tt.method, tt.body, tt.token, tokens.RoleAdmin, tt.remote); w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+
+	emergencyMu.Lock()
+	last := emergencyLast
+	emergencyMu.Unlock()
+	if last == nil || last.RequestedBy != "alice" || last.ConfirmedBy != "bob" || last.Success {
+		t.Errorf("last result = %+v, want a failed stop requested by alice, confirmed by bob", last)
+	}
+}
+
+func TestEmergencyStopPendingView(t *testing.T) {
+	resetEmergency(t)
+
+	if w := emergencyCall(http.MethodPost, `{"action":"fall","operator":"alice"}`, "tok-a", tokens.RoleAdmin, "10.0.0.1:5000"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown action: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	emergencyCall(http.MethodPost, `{"action":"terminate","operator":"alice"}`, "tok-a", tokens.RoleAdmin, "10.0.0.1:5000")
+
+	pendingID := func(role string) (string, bool) {
+		var resp struct {
+			Pending *emergencyRequest `json:"pending"`
+		}
+		w := emergencyCall(http.MethodGet, "", "tok-x", role, "10.0.0.9:5000")
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Pending == nil {
+			return "", false
+		}
+		return resp.Pending.ID, true
+	}
+
+	// Knowing the ID is enough to confirm: only admins see it
+	if id, ok := pendingID(tokens.RoleAdmin); !ok || id == "" {
+		t.Errorf("admin view: pending %v, id %q, want the ID", ok, id)
+	}
+	if id, ok := pendingID(tokens.RoleOperator); !ok || id != "" {
+		t.Errorf("operator view: pending %v, id %q, want no ID", ok, id)
+	}
+
+	// Unconfirmed requests expire
+	emergencyMu.Lock()
+	emergencyPending.Expires = time.Now().Add(-time.Second)
+	emergencyMu.Unlock()
+	if _, ok := pendingID(tokens.RoleAdmin); ok {
+		t.Error("expired request still pending")
+	}
+}
@@ -0,0 +1,21 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/entitlements"
+)
+
+// handleEntitlements shows the signed feature list and which premium
+// subsystems are usable (GET /api/entitlements)
+func handleEntitlements(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	json.NewEncoder(w).Encode(entitlements.Global.Status())
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ErrorCode is a stable, machine-readable error identifier. Every API error body
+// carries one next to the human-readable message, e.g.
+//
+//	{"success": false, "code": "FC_NOT_CONNECTED", "message": "Not connected to Pixhawk"}
+//
+// Clients branch on the code; the message is for people and may change. Codes
+// are part of the API: add new ones, never rename or reuse them.
+type ErrorCode string
+
+// Generic codes, one per HTTP status class (see statusCode)
+const (
+	ErrBadRequest       ErrorCode = "BAD_REQUEST"
+	ErrMethodNotAllowed ErrorCode = "METHOD_NOT_ALLOWED"
+	ErrUnauthorized     ErrorCode = "UNAUTHORIZED"
+	ErrForbidden        ErrorCode = "FORBIDDEN"
+	ErrNotFound         ErrorCode = "NOT_FOUND"
+	ErrConflict         ErrorCode = "CONFLICT"
+	ErrPayloadTooLarge  ErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrStorageFull      ErrorCode = "STORAGE_FULL"
+	ErrInternal         ErrorCode = "INTERNAL_ERROR"
+	ErrUnavailable      ErrorCode = "UNAVAILABLE"
+)
+
+// Domain codes
+const (
+	ErrFeatureDisabled ErrorCode = "FEATURE_DISABLED"  // Subsystem turned off in the config or not started
+	ErrBridgeNotReady  ErrorCode = "BRIDGE_NOT_READY"  // MAVLink bridge not initialized yet
+	ErrFCNotConnected  ErrorCode = "FC_NOT_CONNECTED"  // No heartbeat from the flight controller
+	ErrVehicleArmed    ErrorCode = "VEHICLE_ARMED"     // Needs the vehicle disarmed
+	ErrVehicleDisarmed ErrorCode = "VEHICLE_DISARMED"  // Needs the vehicle armed
+	ErrCommandRejected ErrorCode = "COMMAND_REJECTED"  // FC answered with a failed COMMAND_ACK
+	ErrCommandTimeout  ErrorCode = "COMMAND_TIMEOUT"   // No COMMAND_ACK
+	ErrParamTimeout    ErrorCode = "PARAM_TIMEOUT"     // No PARAM_VALUE after PARAM_SET
+	ErrParamFrozen     ErrorCode = "PARAM_FROZEN"      // safety.freeze_params_in_flight
+	ErrParamFailed     ErrorCode = "PARAM_FAILED"      // PARAM_SET could not be sent or was not applied
+	ErrControlDenied   ErrorCode = "CONTROL_DENIED"    // Control arbitration or interlock refused the action
+	ErrAuthUnavailable ErrorCode = "AUTH_UNAVAILABLE"  // Cloud auth client not running
+	ErrSessionExpired  ErrorCode = "SESSION_EXPIRED"   // No valid router session
+	ErrRouterRejected  ErrorCode = "ROUTER_REJECTED"   // The router refused the request
+	ErrCameraFailed    ErrorCode = "CAMERA_FAILED"     // Camera/pipeline could not be started or changed
+	ErrFirmwareBusy    ErrorCode = "FIRMWARE_BUSY"     // A firmware flash is in progress
+	ErrValidation      ErrorCode = "VALIDATION_FAILED" // Request parsed but a value is out of range
+)
+
+// Errors the bridge methods wrap so handlers can pick a domain code (see errorCode)
+var (
+	errNotInitialized = errors.New("MAVLink bridge not initialized")
+	errNotConnected   = errors.New("not connected to Pixhawk")
+	errNotArmed       = errors.New("vehicle is not armed")
+	errAlreadyArmed   = errors.New("vehicle is already armed")
+	errNoCommandAck   = errors.New("no COMMAND_ACK")
+	errRejectedByFC   = errors.New("rejected by FC")
+)
+
+// errorCode returns the domain code for err, or fallback when it has none
+func errorCode(err error, fallback ErrorCode) ErrorCode {
+	switch {
+	case errors.Is(err, errNotInitialized):
+		return ErrBridgeNotReady
+	case errors.Is(err, errNotConnected):
+		return ErrFCNotConnected
+	case errors.Is(err, errNotArmed):
+		return ErrVehicleDisarmed
+	case errors.Is(err, errAlreadyArmed):
+		return ErrVehicleArmed
+	case errors.Is(err, errNoCommandAck):
+		return ErrCommandTimeout
+	case errors.Is(err, errRejectedByFC):
+		return ErrCommandRejected
+	}
+	return fallback
+}
+
+// statusCode returns the generic code for an HTTP status
+func statusCode(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusMethodNotAllowed:
+		return ErrMethodNotAllowed
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusRequestEntityTooLarge:
+		return ErrPayloadTooLarge
+	case http.StatusInsufficientStorage:
+		return ErrStorageFull
+	case http.StatusServiceUnavailable:
+		return ErrUnavailable
+	}
+	return ErrInternal
+}
+
+// writeError writes a JSON error body with a code
+func writeError(w http.ResponseWriter, status int, code ErrorCode, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"code":    code,
+		"message": msg,
+	})
+}
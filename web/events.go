@@ -0,0 +1,31 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"DroneBridge/internal/events"
+)
+
+// handleEvents lists the most recent session and API key events, oldest first
+// (GET /api/events?limit=50). Live events: /ws/mavlink?events=1.
+func handleEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, ErrValidation, "limit must be a non-negative number")
+			return
+		}
+		limit = n
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"events": events.Global.Recent(limit)})
+}
@@ -0,0 +1,146 @@
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"DroneBridge/internal/journal"
+)
+
+// Export limits
+const (
+	exportDefaultWindow = time.Hour
+	exportMaxWindow     = 7 * 24 * time.Hour
+	exportMaxEntries    = 500000
+)
+
+// parseExportTime accepts RFC3339 or Unix seconds
+func parseExportTime(v string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// handleExport serves GET /api/export?from=&to=&types=gps,battery,events&format=csv|json
+// from/to are RFC3339 or Unix seconds (default: the last hour)
+func handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	to := time.Now()
+	if v := q.Get("to"); v != "" {
+		t, err := parseExportTime(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, "to must be RFC3339 or Unix seconds")
+			return
+		}
+		to = t
+	}
+	from := to.Add(-exportDefaultWindow)
+	if v := q.Get("from"); v != "" {
+		t, err := parseExportTime(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, "from must be RFC3339 or Unix seconds")
+			return
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, "from must be before to")
+		return
+	}
+	if to.Sub(from) > exportMaxWindow {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("window must not exceed %v", exportMaxWindow))
+		return
+	}
+
+	var types []string
+	if v := q.Get("types"); v != "" {
+		for _, t := range strings.Split(v, ",") {
+			t = strings.TrimSpace(t)
+			switch t {
+			case journal.TypeGPS, journal.TypeBattery, journal.TypeEvent, journal.TypeAuth:
+				types = append(types, t)
+			case "":
+			default:
+				writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("unknown type %q (gps, battery, events, auth)", t))
+				return
+			}
+		}
+	}
+
+	format := q.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, "format must be json or csv")
+		return
+	}
+
+	entries, err := journal.Global.Query(from, to, types, exportMaxEntries)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrInternal, err.Error())
+		return
+	}
+
+	name := fmt.Sprintf("flight_%s_%s.%s", from.UTC().Format("20060102T150405Z"), to.UTC().Format("20060102T150405Z"), format)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"from":      from.UTC(),
+			"to":        to.UTC(),
+			"count":     len(entries),
+			"truncated": len(entries) >= exportMaxEntries,
+			"entries":   entries,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	writeExportCSV(w, entries)
+}
+
+// writeExportCSV writes one row per entry: time, type, then the union of all
+// data fields in alphabetical order (empty where a type has no such field)
+func writeExportCSV(w http.ResponseWriter, entries []journal.Entry) {
+	fieldSet := make(map[string]bool)
+	for _, e := range entries {
+		for k := range e.Data {
+			fieldSet[k] = true
+		}
+	}
+	fields := make([]string, 0, len(fieldSet))
+	for k := range fieldSet {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"time", "type"}, fields...))
+	row := make([]string, 2+len(fields))
+	for _, e := range entries {
+		row[0] = e.Time.UTC().Format(time.RFC3339Nano)
+		row[1] = e.Type
+		for i, k := range fields {
+			row[2+i] = ""
+			if v, ok := e.Data[k]; ok {
+				row[2+i] = fmt.Sprint(v)
+			}
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+}
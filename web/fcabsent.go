@@ -0,0 +1,97 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"DroneBridge/internal/metrics"
+)
+
+// Reasons the FC is absent. FC-derived fields are null while one applies, so
+// "no data" is never mistaken for a real zero (system ID 0, mode 0, disarmed).
+const (
+	FCAbsentNoBridge    = "bridge_not_initialized" // MAVLink bridge not started
+	FCAbsentBench       = "bench_mode"             // Running without a Pixhawk (ethernet.allow_missing_pixhawk)
+	FCAbsentNoHeartbeat = "no_heartbeat"           // Waiting for the first Pixhawk heartbeat
+)
+
+// benchMode is set when the bridge was started without a Pixhawk
+var benchMode atomic.Bool
+
+// SetBenchMode marks the bridge as running without a Pixhawk, so the API
+// reports bench mode instead of a pending connection
+func SetBenchMode(enabled bool) {
+	benchMode.Store(enabled)
+}
+
+// fcAbsent returns why there is no FC data, or "" when the FC is connected
+func fcAbsent() string {
+	switch {
+	case bridge == nil:
+		return FCAbsentNoBridge
+	case bridge.IsConnected():
+		return ""
+	case benchMode.Load():
+		return FCAbsentBench
+	}
+	return FCAbsentNoHeartbeat
+}
+
+// fcAbsentMessage explains an FC absence reason to people
+func fcAbsentMessage(reason string) string {
+	switch reason {
+	case FCAbsentNoBridge:
+		return "MAVLink bridge not initialized"
+	case FCAbsentBench:
+		return "Running without a Pixhawk (bench mode)"
+	case FCAbsentNoHeartbeat:
+		return "Waiting for Pixhawk connection..."
+	}
+	return ""
+}
+
+// handleDashboard serves the dashboard summary. Bridge-side state is always
+// present; the "fc" section is null while the FC is absent, with the reason in
+// "fcAbsent", so bench operation without a Pixhawk shows no fake telemetry.
+func handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	snapshot := metrics.Global.GetSnapshot()
+	resp := map[string]interface{}{
+		"bridge": map[string]interface{}{
+			"uptime":         snapshot["uptime"],
+			"currentIp":      snapshot["current_ip"],
+			"authStatus":     snapshot["auth_status"],
+			"uplinkState":    snapshot["uplink_state"],
+			"reconnectState": snapshot["reconnect_state"],
+			"sentPackets":    snapshot["sent_packets"],
+			"failedPackets":  snapshot["failed_packets"],
+		},
+		"benchMode": benchMode.Load(),
+		"fc":        nil,
+	}
+
+	reason := fcAbsent()
+	if reason != "" {
+		resp["fcAbsent"] = reason
+		resp["fcAbsentMessage"] = fcAbsentMessage(reason)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	typ, _, pos, _ := vehicleSnapshot()
+	class := vehicleClass(typ)
+	mode, customMode := currentFlightMode()
+	resp["fc"] = map[string]interface{}{
+		"systemId":   bridge.GetSystemID(),
+		"armed":      bridge.IsArmed(),
+		"class":      class,
+		"mavType":    typ.String(),
+		"flightMode": mode,
+		"customMode": customMode,
+		"telemetry":  vehicleTelemetry(class, pos),
+	}
+	json.NewEncoder(w).Encode(resp)
+}
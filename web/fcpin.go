@@ -0,0 +1,58 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"DroneBridge/internal/audit"
+	"DroneBridge/internal/fcpin"
+	"DroneBridge/internal/metrics"
+)
+
+// handleFCPin shows the pinned FC and the refused ones (GET) or re-pairs the
+// drone (POST {"action": "repair"}) so the next FC heard is pinned instead
+func handleFCPin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			Action string `json:"action"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, "Invalid request body")
+			return
+		}
+		if req.Action != "repair" {
+			writeError(w, http.StatusBadRequest, ErrValidation, fmt.Sprintf("unknown action %q (use \"repair\")", req.Action))
+			return
+		}
+		status := fcpin.Global.Status()
+		if !status.Enabled {
+			writeError(w, http.StatusServiceUnavailable, ErrFeatureDisabled, "FC pinning is disabled (ethernet.pin_fc)")
+			return
+		}
+		previous := status.Pin
+		if err := fcpin.Global.Repair(); err != nil {
+			writeError(w, http.StatusInternalServerError, ErrInternal, err.Error())
+			return
+		}
+		log.Printf("[WEB] FC pin cleared by %s", r.RemoteAddr)
+		metrics.Global.AddLog("WARN", "FC pin cleared - the next FC heard will be pinned")
+		details := map[string]interface{}{"remote": r.RemoteAddr}
+		if previous != nil {
+			details["sysId"] = previous.SysID
+			details["uid"] = previous.UID
+		}
+		audit.Global.Record("fc_pin", "repair", details)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	json.NewEncoder(w).Encode(fcpin.Global.Status())
+}
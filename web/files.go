@@ -0,0 +1,327 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"DroneBridge/internal/audit"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/tokens"
+)
+
+// FileSettings controls the workspace file manager
+type FileSettings struct {
+	Root        string // Workspace directory; nothing outside it is reachable
+	MaxFileSize int64  // Largest single upload in bytes
+	Quota       int64  // Total size of the workspace in bytes
+}
+
+// FileEntry is one item of a directory listing
+type FileEntry struct {
+	Name     string    `json:"name"`
+	Dir      bool      `json:"dir"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+var (
+	filesMu       sync.Mutex // Serialises uploads so quota checks can't race
+	filesSettings FileSettings
+
+	errOutsideWorkspace = errors.New("path is outside the workspace")
+	errHiddenPath       = errors.New("hidden files are not accessible")
+)
+
+// SetFileSettings configures the workspace file manager
+func SetFileSettings(s FileSettings) {
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	filesSettings = s
+}
+
+func currentFileSettings() FileSettings {
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	return filesSettings
+}
+
+// resolveWorkspacePath maps a request path ("landing_config.json", "templates/a.png")
+// to a file under root. Dot components are rejected (no "..", no dotfiles such as
+// secrets), and symlinks must not lead out of the workspace.
+func resolveWorkspacePath(root, rel string) (string, error) {
+	rel = strings.Trim(filepath.ToSlash(rel), "/")
+	if rel != "" {
+		for _, part := range strings.Split(rel, "/") {
+			if part == ".." {
+				return "", errOutsideWorkspace
+			}
+			if strings.HasPrefix(part, ".") {
+				return "", errHiddenPath
+			}
+		}
+	}
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(rootAbs); err == nil {
+		rootAbs = resolved
+	}
+	full := filepath.Join(rootAbs, filepath.FromSlash(rel))
+
+	// Resolve symlinks on the deepest existing ancestor (the target may not exist yet)
+	existing, rest := full, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+	resolved = filepath.Join(resolved, rest)
+	if resolved != rootAbs && !strings.HasPrefix(resolved, rootAbs+string(filepath.Separator)) {
+		return "", errOutsideWorkspace
+	}
+	return resolved, nil
+}
+
+// workspaceUsage sums the sizes of regular files under root
+func workspaceUsage(root string) int64 {
+	var total int64
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// authorizeFiles checks that the request carries an admin token (issued,
+// tokens.admin_token or files.api_token, see tokenGuard); it writes the error
+// response and returns false when the request may not proceed
+func authorizeFiles(w http.ResponseWriter, r *http.Request) bool {
+	if requestRole(r) == tokens.RoleAdmin {
+		return true
+	}
+	log.Printf("[WEB] Rejected file manager request from %s: no admin token", r.RemoteAddr)
+	filesError(w, http.StatusUnauthorized, "admin token required")
+	return false
+}
+
+func filesError(w http.ResponseWriter, code int, msg string) {
+	writeError(w, code, statusCode(code), msg)
+}
+
+// pathError maps a resolution or filesystem error to a response
+func pathError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errOutsideWorkspace), errors.Is(err, errHiddenPath):
+		filesError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, fs.ErrNotExist):
+		filesError(w, http.StatusNotFound, "not found")
+	default:
+		filesError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// handleFiles lists a workspace directory: GET /api/files?path=templates
+func handleFiles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	s := currentFileSettings()
+	if !authorizeFiles(w, r) {
+		return
+	}
+	dir, err := resolveWorkspacePath(s.Root, r.URL.Query().Get("path"))
+	if err != nil {
+		pathError(w, err)
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		pathError(w, err)
+		return
+	}
+
+	list := []FileEntry{}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		entry := FileEntry{Name: e.Name(), Dir: e.IsDir(), Modified: info.ModTime()}
+		if !e.IsDir() {
+			entry.Size = info.Size()
+		}
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Dir != list[j].Dir {
+			return list[i].Dir
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"path":        strings.Trim(filepath.ToSlash(r.URL.Query().Get("path")), "/"),
+		"entries":     list,
+		"usedBytes":   workspaceUsage(s.Root),
+		"quotaBytes":  s.Quota,
+		"maxFileSize": s.MaxFileSize,
+	})
+}
+
+// handleFileDownload sends a workspace file: GET /api/files/download?path=landing_config.json
+func handleFileDownload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	s := currentFileSettings()
+	if !authorizeFiles(w, r) {
+		return
+	}
+	path, err := resolveWorkspacePath(s.Root, r.URL.Query().Get("path"))
+	if err != nil {
+		pathError(w, err)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		pathError(w, err)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		pathError(w, err)
+		return
+	}
+	if !info.Mode().IsRegular() {
+		filesError(w, http.StatusBadRequest, "not a regular file")
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", info.Name()))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// handleFileUpload stores a file in the workspace, replacing an existing one:
+// POST /api/files/upload (multipart: "file", optional "path" of the target directory)
+func handleFileUpload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	s := currentFileSettings()
+	if !authorizeFiles(w, r) {
+		return
+	}
+
+	// Multipart overhead is small; anything past this cannot be within the limit
+	r.Body = http.MaxBytesReader(w, r.Body, s.MaxFileSize+64<<10)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			filesError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("file exceeds %d bytes", s.MaxFileSize))
+			return
+		}
+		filesError(w, http.StatusBadRequest, fmt.Sprintf("missing file: %v", err))
+		return
+	}
+	defer file.Close()
+	if header.Size > s.MaxFileSize {
+		filesError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("file exceeds %d bytes", s.MaxFileSize))
+		return
+	}
+
+	rel := filepath.ToSlash(filepath.Join(r.FormValue("path"), filepath.Base(header.Filename)))
+	path, err := resolveWorkspacePath(s.Root, rel)
+	if err != nil {
+		pathError(w, err)
+		return
+	}
+
+	filesMu.Lock()
+	defer filesMu.Unlock()
+
+	// Quota: current usage, minus the file being replaced, plus the upload
+	used := workspaceUsage(s.Root)
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			filesError(w, http.StatusConflict, "a directory with that name exists")
+			return
+		}
+		used -= info.Size()
+	}
+	if s.Quota > 0 && used+header.Size > s.Quota {
+		filesError(w, http.StatusInsufficientStorage, fmt.Sprintf("workspace quota exceeded (%d of %d bytes used)", used, s.Quota))
+		return
+	}
+
+	// Write next to the target and rename, so a failed upload never leaves a partial file
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		filesError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		filesError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	size, err := io.Copy(tmp, file)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		filesError(w, http.StatusInternalServerError, fmt.Sprintf("failed to store file: %v", err))
+		return
+	}
+
+	log.Printf("[WEB] Workspace file %s uploaded by %s (%d bytes)", rel, r.RemoteAddr, size)
+	metrics.Global.AddLog("INFO", fmt.Sprintf("Workspace file %s uploaded (%d bytes)", rel, size))
+	audit.Global.Record("files", "upload", map[string]interface{}{"path": rel, "size": size, "remote": r.RemoteAddr})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"path":    rel,
+		"size":    size,
+	})
+}
@@ -0,0 +1,340 @@
+package web
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/metrics"
+)
+
+// Firmware flashing states
+const (
+	fwIdle      = "idle"
+	fwVerifying = "verifying"
+	fwRebooting = "rebooting"
+	fwFlashing  = "flashing"
+	fwSuccess   = "success"
+	fwFailed    = "failed"
+)
+
+// FirmwareSettings controls firmware upload and flashing
+type FirmwareSettings struct {
+	UploadDir  string // Where uploaded images are stored
+	Uploader   string // Uploader command; {file} and {port} are substituted (empty = flashing disabled)
+	SerialPort string // Serial port of the FC bootloader
+	MaxSize    int64  // Largest accepted upload in bytes
+}
+
+// FirmwareStatus is the state of the current (or last) flashing run
+type FirmwareStatus struct {
+	State      string     `json:"state"`
+	File       string     `json:"file,omitempty"`
+	SHA256     string     `json:"sha256,omitempty"`
+	Size       int64      `json:"size,omitempty"`
+	Phase      string     `json:"phase,omitempty"` // Erase, Program, Verify (from the uploader)
+	Progress   float64    `json:"progress"`
+	Message    string     `json:"message,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Output     []string   `json:"output"` // Last uploader output lines
+}
+
+var (
+	fwMu       sync.Mutex
+	fwSettings = FirmwareSettings{UploadDir: "firmware", MaxSize: 16 << 20}
+	fwStatus   = FirmwareStatus{State: fwIdle, Output: []string{}}
+
+	fwProgressPattern = regexp.MustCompile(`(Erase|Program|Verify)\s*:.*?(\d+(?:\.\d+)?)%`)
+)
+
+// SetFirmwareSettings configures firmware upload and flashing
+func SetFirmwareSettings(s FirmwareSettings) {
+	fwMu.Lock()
+	defer fwMu.Unlock()
+	if s.UploadDir == "" {
+		s.UploadDir = "firmware"
+	}
+	if s.MaxSize <= 0 {
+		s.MaxSize = 16 << 20
+	}
+	fwSettings = s
+}
+
+// verifyFirmwareImage checks the integrity of a PX4 .px4 / ArduPilot .apj image
+// (JSON with a zlib-compressed, base64-encoded image and its expected size).
+// Raw .bin/.hex images have no embedded metadata and are only checked by SHA-256.
+func verifyFirmwareImage(path string) error {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".apj" && ext != ".px4" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var img struct {
+		Image     string `json:"image"`
+		ImageSize int    `json:"image_size"`
+		BoardID   int    `json:"board_id"`
+	}
+	if err := json.Unmarshal(data, &img); err != nil {
+		return fmt.Errorf("invalid %s file: %w", ext, err)
+	}
+	compressed, err := base64.StdEncoding.DecodeString(img.Image)
+	if err != nil {
+		return fmt.Errorf("invalid image encoding: %w", err)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return fmt.Errorf("invalid image compression: %w", err)
+	}
+	n, err := io.Copy(io.Discard, zr)
+	if err != nil {
+		return fmt.Errorf("corrupt image: %w", err)
+	}
+	if img.ImageSize > 0 && int(n) != img.ImageSize {
+		return fmt.Errorf("image size %d does not match declared %d", n, img.ImageSize)
+	}
+	return nil
+}
+
+func fwSetLocked(state, msg string) {
+	fwStatus.State = state
+	fwStatus.Message = msg
+	if state == fwSuccess || state == fwFailed {
+		now := time.Now()
+		fwStatus.FinishedAt = &now
+		level := "INFO"
+		if state == fwFailed {
+			level = "ERROR"
+		}
+		metrics.Global.AddLog(level, "Firmware: "+msg)
+	}
+}
+
+func fwOutputLocked(line string) {
+	fwStatus.Output = append(fwStatus.Output, line)
+	if len(fwStatus.Output) > 50 {
+		fwStatus.Output = fwStatus.Output[1:]
+	}
+}
+
+// uploaderArgs splits the uploader command line and then fills in {file} and
+// {port} inside each argument, so an uploaded file name stays one argument
+// however many spaces or dashes it holds
+func uploaderArgs(uploader, file, port string) []string {
+	r := strings.NewReplacer("{file}", file, "{port}", port)
+	args := strings.Fields(uploader)
+	for i, a := range args {
+		args[i] = r.Replace(a)
+	}
+	return args
+}
+
+// flashFirmware reboots the FC into its bootloader and runs the uploader
+func (b *MAVLinkBridge) flashFirmware(path string, settings FirmwareSettings) {
+	fail := func(msg string) {
+		fwMu.Lock()
+		fwSetLocked(fwFailed, msg)
+		fwMu.Unlock()
+		log.Printf("[FIRMWARE] ❌ %s", msg)
+	}
+
+	if err := verifyFirmwareImage(path); err != nil {
+		fail(err.Error())
+		return
+	}
+
+	// The vehicle may have been armed while the image was uploaded and verified
+	if b.IsArmed() {
+		fail("vehicle was armed before the reboot to bootloader - flashing aborted")
+		return
+	}
+
+	// Reboot into bootloader (best effort - the uploader also retries the serial sync)
+	fwMu.Lock()
+	fwSetLocked(fwRebooting, "Rebooting FC into bootloader")
+	fwMu.Unlock()
+	if err := b.sendCommandLong(common.MAV_CMD_PREFLIGHT_REBOOT_SHUTDOWN, [7]float32{3}); err != nil {
+		log.Printf("[FIRMWARE] Reboot to bootloader not sent: %v", err)
+	}
+	time.Sleep(3 * time.Second)
+
+	args := uploaderArgs(settings.Uploader, path, settings.SerialPort)
+	cmd := exec.Command(args[0], args[1:]...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	cmd.Stderr = cmd.Stdout
+
+	fwMu.Lock()
+	fwSetLocked(fwFlashing, "Running uploader")
+	fwMu.Unlock()
+	log.Printf("[FIRMWARE] Flashing %s: %q", filepath.Base(path), args)
+
+	if err := cmd.Start(); err != nil {
+		fail(fmt.Sprintf("failed to start uploader: %v", err))
+		return
+	}
+
+	// Uploaders redraw progress bars with \r - split on both line endings
+	scanner := bufio.NewScanner(stdout)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fwMu.Lock()
+		if m := fwProgressPattern.FindStringSubmatch(line); m != nil {
+			fwStatus.Phase = m[1]
+			fwStatus.Progress, _ = strconv.ParseFloat(m[2], 64)
+		} else {
+			fwOutputLocked(line)
+		}
+		fwMu.Unlock()
+	}
+
+	if err := cmd.Wait(); err != nil {
+		fail(fmt.Sprintf("uploader failed: %v", err))
+		return
+	}
+	fwMu.Lock()
+	fwStatus.Progress = 100
+	fwSetLocked(fwSuccess, fmt.Sprintf("Flashed %s", filepath.Base(path)))
+	fwMu.Unlock()
+	log.Printf("[FIRMWARE] ✅ Flashed %s", filepath.Base(path))
+}
+
+// handleFirmware returns flashing status (GET) or uploads and flashes an image
+// (POST multipart: "firmware" file, optional "sha256" hex checksum)
+func handleFirmware(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		// Armed-state interlock
+		if bridge.IsArmed() {
+			writeError(w, http.StatusConflict, ErrVehicleArmed, "vehicle is armed - disarm before flashing firmware")
+			return
+		}
+
+		// Check and claim the flasher in one step so two uploads cannot both start
+		fwMu.Lock()
+		settings := fwSettings
+		if settings.Uploader == "" {
+			fwMu.Unlock()
+			writeError(w, http.StatusServiceUnavailable, ErrFeatureDisabled, "firmware.uploader is not configured")
+			return
+		}
+		if fwStatus.State == fwVerifying || fwStatus.State == fwRebooting || fwStatus.State == fwFlashing {
+			fwMu.Unlock()
+			writeError(w, http.StatusConflict, ErrFirmwareBusy, "firmware update already in progress")
+			return
+		}
+		now := time.Now()
+		fwStatus = FirmwareStatus{State: fwVerifying, Message: "Receiving upload", StartedAt: &now, Output: []string{}}
+		fwMu.Unlock()
+
+		// Any rejection from here on releases the flasher again
+		reject := func(code int, msg string) {
+			fwMu.Lock()
+			fwSetLocked(fwFailed, msg)
+			fwMu.Unlock()
+			writeError(w, code, statusCode(code), msg)
+		}
+
+		// Multipart overhead is small; anything past this cannot be within the limit
+		r.Body = http.MaxBytesReader(w, r.Body, settings.MaxSize+64<<10)
+		file, header, err := r.FormFile("firmware")
+		if err != nil {
+			var tooBig *http.MaxBytesError
+			if errors.As(err, &tooBig) {
+				reject(http.StatusRequestEntityTooLarge, fmt.Sprintf("firmware image exceeds %d bytes", settings.MaxSize))
+				return
+			}
+			reject(http.StatusBadRequest, fmt.Sprintf("missing firmware file: %v", err))
+			return
+		}
+		defer file.Close()
+		if header.Size > settings.MaxSize {
+			reject(http.StatusRequestEntityTooLarge, fmt.Sprintf("firmware image exceeds %d bytes", settings.MaxSize))
+			return
+		}
+
+		if err := os.MkdirAll(settings.UploadDir, 0755); err != nil {
+			reject(http.StatusInternalServerError, err.Error())
+			return
+		}
+		path := filepath.Join(settings.UploadDir, filepath.Base(header.Filename))
+		out, err := os.Create(path)
+		if err != nil {
+			reject(http.StatusInternalServerError, err.Error())
+			return
+		}
+		hash := sha256.New()
+		size, err := io.Copy(io.MultiWriter(out, hash), file)
+		out.Close()
+		if err != nil {
+			reject(http.StatusInternalServerError, fmt.Sprintf("failed to store firmware: %v", err))
+			return
+		}
+		sum := hex.EncodeToString(hash.Sum(nil))
+		if want := strings.ToLower(r.FormValue("sha256")); want != "" && want != sum {
+			os.Remove(path)
+			reject(http.StatusBadRequest, fmt.Sprintf("checksum mismatch: got %s, expected %s", sum, want))
+			return
+		}
+
+		fwMu.Lock()
+		fwStatus.File = filepath.Base(path)
+		fwStatus.SHA256 = sum
+		fwStatus.Size = size
+		fwStatus.Message = "Verifying image"
+		fwMu.Unlock()
+		log.Printf("[WEB] Firmware %s uploaded (%d bytes, sha256 %s)", header.Filename, size, sum)
+
+		go bridge.flashFirmware(path, settings)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	fwMu.Lock()
+	s := fwStatus
+	s.Output = append([]string(nil), fwStatus.Output...)
+	fwMu.Unlock()
+	json.NewEncoder(w).Encode(s)
+}
@@ -0,0 +1,31 @@
+package web
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUploaderArgs(t *testing.T) {
+	const uploader = "python3 px_uploader.py --port {port} {file}"
+	tests := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{"plain name", "/tmp/fw/arducopter.apj", []string{"python3", "px_uploader.py", "--port", "/dev/ttyACM0", "/tmp/fw/arducopter.apj"}},
+		{"spaces in name", "/tmp/fw/my fw.apj", []string{"python3", "px_uploader.py", "--port", "/dev/ttyACM0", "/tmp/fw/my fw.apj"}},
+		{"injected option", "/tmp/fw/x --baud-bootloader 1 --force.apj", []string{"python3", "px_uploader.py", "--port", "/dev/ttyACM0", "/tmp/fw/x --baud-bootloader 1 --force.apj"}},
+		{"placeholder in name", "/tmp/fw/{port}.apj", []string{"python3", "px_uploader.py", "--port", "/dev/ttyACM0", "/tmp/fw/{port}.apj"}},
+	}
+	for _, tt := range tests {
+		if got := uploaderArgs(uploader, tt.file, "/dev/ttyACM0"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: uploaderArgs() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Placeholders inside an argument
+	got := uploaderArgs("flash --image={file}", "/tmp/a b.px4", "")
+	if want := []string{"flash", "--image=/tmp/a b.px4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("uploaderArgs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/metrics"
+)
+
+// modeChangeTimeout is how long to wait for the heartbeat to report a requested mode
+const modeChangeTimeout = 3 * time.Second
+
+// flightSequence records the steps of a one-click flight action for the API response
+type flightSequence struct {
+	Steps []string
+}
+
+func (s *flightSequence) step(format string, args ...interface{}) {
+	s.Steps = append(s.Steps, fmt.Sprintf(format, args...))
+}
+
+// command sends a COMMAND_LONG and fails unless it is accepted
+func (s *flightSequence) command(b *MAVLinkBridge, name string, cmd common.MAV_CMD, p [7]float32) error {
+	result, err := b.SendCommand(cmd, p)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if result != common.MAV_RESULT_ACCEPTED {
+		return fmt.Errorf("%s %w: %s", name, errRejectedByFC, result)
+	}
+	s.step("%s accepted", name)
+	return nil
+}
+
+// setMode switches to the mode an action uses on the connected autopilot
+// (actionMode) with MAV_CMD_DO_SET_MODE and waits until the heartbeat reports it
+func (s *flightSequence) setMode(b *MAVLinkBridge, action string) error {
+	ap, typ := vehicleAutopilot()
+	name, err := actionMode(ap, typ, action)
+	if err != nil {
+		return err
+	}
+	mode, ok := flightModeNumber(ap, typ, name)
+	if !ok {
+		return fmt.Errorf("%s mode is not available for this vehicle type", name)
+	}
+	if _, current, _, _ := vehicleSnapshot(); current == mode {
+		s.step("Already in %s", name)
+		return nil
+	}
+
+	if err := s.command(b, "Mode "+name, common.MAV_CMD_DO_SET_MODE, setModeParams(ap, mode)); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(modeChangeTimeout)
+	for time.Now().Before(deadline) {
+		if _, current, _, _ := vehicleSnapshot(); current == mode {
+			s.step("Vehicle reports %s", name)
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("vehicle did not enter %s within %v", name, modeChangeTimeout)
+}
+
+// takeoffParams returns the MAV_CMD_NAV_TAKEOFF parameters for alt meters above
+// home. ArduPilot takes the altitude above home (in GUIDED); PX4 takes it above
+// mean sea level and switches to AUTO.TAKEOFF itself.
+func takeoffParams(ap common.MAV_AUTOPILOT, typ common.MAV_TYPE, alt float64, homePos *VehiclePosition) ([7]float32, error) {
+	switch ap {
+	case common.MAV_AUTOPILOT_PX4:
+		if homePos == nil {
+			return [7]float32{}, fmt.Errorf("home position unknown - cannot set the takeoff altitude")
+		}
+		nan := float32(math.NaN()) // Current yaw and position
+		return [7]float32{0, 0, 0, nan, nan, nan, float32(homePos.AltMSL + alt)}, nil
+	default:
+		if _, err := actionMode(ap, typ, actionGuided); err != nil {
+			return [7]float32{}, err
+		}
+		return [7]float32{0, 0, 0, 0, 0, 0, float32(alt)}, nil
+	}
+}
+
+// Takeoff runs pre-arm checks, switches to GUIDED (ArduPilot), arms and climbs to alt meters
+func (b *MAVLinkBridge) Takeoff(alt float64) (*flightSequence, error) {
+	seq := &flightSequence{Steps: []string{}}
+
+	guidedMu.RLock()
+	maxAlt := guidedSettings.MaxAltitude
+	guidedMu.RUnlock()
+	if alt < 1 || alt > maxAlt {
+		return seq, fmt.Errorf("altitude must be between 1 and %.0f m", maxAlt)
+	}
+	if !b.IsConnected() {
+		return seq, errNotConnected
+	}
+	ap, typ := vehicleAutopilot()
+	if typ == common.MAV_TYPE_FIXED_WING {
+		return seq, fmt.Errorf("one-click takeoff is not supported for fixed-wing vehicles")
+	}
+	if b.IsArmed() {
+		return seq, errAlreadyArmed
+	}
+	_, _, _, homePos := vehicleSnapshot()
+	params, err := takeoffParams(ap, typ, alt, homePos)
+	if err != nil {
+		return seq, err
+	}
+
+	if err := b.runPrearmChecks(); err != nil {
+		return seq, err
+	}
+	seq.step("Pre-arm checks passed")
+
+	if ap != common.MAV_AUTOPILOT_PX4 {
+		if err := seq.setMode(b, actionGuided); err != nil {
+			return seq, err
+		}
+	}
+	if err := seq.command(b, "Arm", common.MAV_CMD_COMPONENT_ARM_DISARM, [7]float32{1}); err != nil {
+		return seq, err
+	}
+	if err := seq.command(b, fmt.Sprintf("Takeoff to %.1f m", alt), common.MAV_CMD_NAV_TAKEOFF, params); err != nil {
+		return seq, err
+	}
+	return seq, nil
+}
+
+// Land switches to the vehicle's land mode
+func (b *MAVLinkBridge) Land() (*flightSequence, error) {
+	seq := &flightSequence{Steps: []string{}}
+	if !b.IsArmed() {
+		return seq, errNotArmed
+	}
+	if _, typ := vehicleAutopilot(); typ == common.MAV_TYPE_FIXED_WING {
+		return seq, fmt.Errorf("fixed-wing vehicles cannot land on command - use RTL")
+	}
+	return seq, seq.setMode(b, actionLand)
+}
+
+// RTL switches to return-to-launch
+func (b *MAVLinkBridge) RTL() (*flightSequence, error) {
+	seq := &flightSequence{Steps: []string{}}
+	if !b.IsArmed() {
+		return seq, errNotArmed
+	}
+	return seq, seq.setMode(b, actionRTL)
+}
+
+// ReturnToLaunch switches the vehicle to RTL outside the web API (dead-man watchdog)
+func ReturnToLaunch() error {
+	if bridge == nil {
+		return errNotInitialized
+	}
+	_, err := bridge.RTL()
+	return err
+}
+
+// handleFlight serves POST /api/flight/takeoff ({"altitude": m}), /api/flight/land and /api/flight/rtl
+func handleFlight(action string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+			return
+		}
+		if bridge == nil {
+			writeError(w, http.StatusServiceUnavailable, ErrBridgeNotReady, "MAVLink bridge not initialized")
+			return
+		}
+
+		var seq *flightSequence
+		var err error
+		switch action {
+		case "takeoff":
+			var req struct {
+				Altitude float64 `json:"altitude"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+				return
+			}
+			seq, err = bridge.Takeoff(req.Altitude)
+		case "land":
+			seq, err = bridge.Land()
+		case "rtl":
+			seq, err = bridge.RTL()
+		}
+
+		if err != nil {
+			log.Printf("[WEB] Flight %s failed: %v", action, err)
+			metrics.Global.AddLog("WARN", fmt.Sprintf("Flight %s failed: %v", action, err))
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"success": false,
+				"code":    errorCode(err, ErrValidation),
+				"message": err.Error(),
+				"steps":   seq.Steps,
+			})
+			return
+		}
+
+		log.Printf("[WEB] Flight %s commanded", action)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Flight %s commanded", action))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": fmt.Sprintf("%s commanded", action),
+			"steps":   seq.Steps,
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/metrics"
+)
+
+// positionMaxAge is how old the last GLOBAL_POSITION_INT may be for distance checks
+const positionMaxAge = 5 * time.Second
+
+// GuidedSettings limits what a guided goto may ask of the vehicle
+type GuidedSettings struct {
+	MaxDistance float64 // Max meters from the current position (0 = unlimited)
+	MaxAltitude float64 // Max meters above home
+	MaxSpeed    float64 // Max requested ground speed in m/s
+}
+
+// GotoRequest is the body of POST /api/guided/goto
+type GotoRequest struct {
+	Lat   float64 `json:"lat"`
+	Lon   float64 `json:"lon"`
+	Alt   float64 `json:"alt"`             // Meters above home
+	Speed float64 `json:"speed,omitempty"` // Optional ground speed in m/s
+}
+
+var (
+	guidedMu       sync.RWMutex
+	guidedSettings = GuidedSettings{MaxDistance: 1000, MaxAltitude: 120, MaxSpeed: 15}
+)
+
+// SetGuidedSettings configures the guided goto safety limits
+func SetGuidedSettings(s GuidedSettings) {
+	guidedMu.Lock()
+	defer guidedMu.Unlock()
+	guidedSettings = s
+}
+
+// fenceParam returns a cached parameter value (ok=false if not loaded)
+func (b *MAVLinkBridge) fenceParam(name string) (float64, bool) {
+	p, ok := b.GetCachedParameter(name)
+	return p.ParamValue, ok
+}
+
+// checkGoto validates a goto target against vehicle state, configured limits and the FC geofence
+func (b *MAVLinkBridge) checkGoto(req GotoRequest) error {
+	guidedMu.RLock()
+	maxSpeed := guidedSettings.MaxSpeed
+	guidedMu.RUnlock()
+
+	if req.Speed < 0 || req.Speed > maxSpeed {
+		return fmt.Errorf("speed must be between 0 and %.1f m/s", maxSpeed)
+	}
+	if err := b.checkTarget(req.Lat, req.Lon, req.Alt, 0); err != nil {
+		return err
+	}
+	if !b.IsArmed() {
+		return errNotArmed
+	}
+	ap, typ := vehicleAutopilot()
+	name, err := actionMode(ap, typ, actionGuided)
+	if err != nil {
+		return err
+	}
+	guided, _ := flightModeNumber(ap, typ, name)
+	if _, mode, _, _ := vehicleSnapshot(); mode != guided {
+		return fmt.Errorf("vehicle is not in %s mode", name)
+	}
+	return nil
+}
+
+// checkTarget validates a target position (altitude above home) against the
+// configured limits, the distance from the vehicle and the FC geofence. reach is
+// how far from the target the vehicle will fly (an orbit radius, 0 for a goto)
+func (b *MAVLinkBridge) checkTarget(lat, lon, alt, reach float64) error {
+	guidedMu.RLock()
+	limits := guidedSettings
+	guidedMu.RUnlock()
+
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 || (lat == 0 && lon == 0) {
+		return fmt.Errorf("invalid coordinates %.7f, %.7f", lat, lon)
+	}
+	if alt <= 0 || alt > limits.MaxAltitude {
+		return fmt.Errorf("altitude must be between 0 and %.0f m above home", limits.MaxAltitude)
+	}
+	if !b.IsConnected() {
+		return errNotConnected
+	}
+	_, _, pos, homePos := vehicleSnapshot()
+	if pos == nil || time.Since(pos.Received) > positionMaxAge {
+		return fmt.Errorf("no recent vehicle position")
+	}
+	if d := distanceMeters(pos.Lat, pos.Lon, lat, lon) + reach; limits.MaxDistance > 0 && d > limits.MaxDistance {
+		return fmt.Errorf("target is %.0f m away (max %.0f m)", d, limits.MaxDistance)
+	}
+
+	return b.checkFence(lat, lon, alt, reach, homePos)
+}
+
+// checkFence validates a target against the FC geofence. A fence that is enabled
+// but cannot be evaluated here (a polygon, a parameter not loaded yet) refuses the
+// target rather than letting the FC discover the breach in flight
+func (b *MAVLinkBridge) checkFence(lat, lon, alt, reach float64, homePos *VehiclePosition) error {
+	param := func(name string) (float64, error) {
+		v, ok := b.fenceParam(name)
+		if !ok {
+			return 0, fmt.Errorf("%s not loaded - cannot check the geofence", name)
+		}
+		return v, nil
+	}
+	fromHome := func() (float64, error) {
+		if homePos == nil {
+			return 0, fmt.Errorf("home position unknown - cannot check circular geofence")
+		}
+		return distanceMeters(homePos.Lat, homePos.Lon, lat, lon) + reach, nil
+	}
+
+	// ArduPilot: FENCE_TYPE bit 0 = max altitude, bit 1 = circle around home,
+	// bit 2 = polygons, bit 3 = min altitude
+	if enabled, ok := b.fenceParam("FENCE_ENABLE"); ok && enabled != 0 {
+		v, err := param("FENCE_TYPE")
+		if err != nil {
+			return err
+		}
+		fenceType := int(v)
+		if fenceType&^0b1011 != 0 {
+			return fmt.Errorf("geofence type %d includes polygon fences the bridge cannot check", fenceType)
+		}
+		if fenceType&1 != 0 {
+			maxAlt, err := param("FENCE_ALT_MAX")
+			if err != nil {
+				return err
+			}
+			if alt > maxAlt {
+				return fmt.Errorf("altitude %.0f m exceeds geofence maximum %.0f m", alt, maxAlt)
+			}
+		}
+		if fenceType&8 != 0 {
+			minAlt, err := param("FENCE_ALT_MIN")
+			if err != nil {
+				return err
+			}
+			if alt < minAlt {
+				return fmt.Errorf("altitude %.0f m is below geofence minimum %.0f m", alt, minAlt)
+			}
+		}
+		if fenceType&2 != 0 {
+			radius, err := param("FENCE_RADIUS")
+			if err != nil {
+				return err
+			}
+			d, err := fromHome()
+			if err != nil {
+				return err
+			}
+			if d > radius {
+				return fmt.Errorf("target is %.0f m from home, outside the %.0f m geofence", d, radius)
+			}
+		}
+	}
+
+	// PX4: cylinder around home (0 = off). Polygon fences are part of the fence
+	// mission, which the FC enforces on its own
+	if maxVer, ok := b.fenceParam("GF_MAX_VER_DIST"); ok && maxVer > 0 && alt > maxVer {
+		return fmt.Errorf("altitude %.0f m exceeds geofence maximum %.0f m", alt, maxVer)
+	}
+	if maxHor, ok := b.fenceParam("GF_MAX_HOR_DIST"); ok && maxHor > 0 {
+		d, err := fromHome()
+		if err != nil {
+			return err
+		}
+		if d > maxHor {
+			return fmt.Errorf("target is %.0f m from home, outside the %.0f m geofence", d, maxHor)
+		}
+	}
+	return nil
+}
+
+// GuidedGoto flies the vehicle to a position: a GUIDED target on ArduPilot, a
+// reposition from Hold on PX4
+func (b *MAVLinkBridge) GuidedGoto(req GotoRequest) error {
+	if b == nil || b.node == nil {
+		return errNotInitialized
+	}
+	if err := b.checkGoto(req); err != nil {
+		return err
+	}
+
+	send := b.positionTarget
+	if ap, _ := vehicleAutopilot(); ap == common.MAV_AUTOPILOT_PX4 {
+		send = b.reposition
+	}
+	if err := send(req); err != nil {
+		return err
+	}
+
+	log.Printf("[WEB] Guided goto %.7f, %.7f at %.1f m", req.Lat, req.Lon, req.Alt)
+	metrics.Global.AddLog("INFO", fmt.Sprintf("Guided goto %.7f, %.7f at %.1f m", req.Lat, req.Lon, req.Alt))
+	return nil
+}
+
+// positionTarget sends an ArduPilot GUIDED target (SET_POSITION_TARGET_GLOBAL_INT)
+func (b *MAVLinkBridge) positionTarget(req GotoRequest) error {
+	if req.Speed > 0 {
+		// DO_CHANGE_SPEED: param1 = ground speed, param2 = speed in m/s, param3 = no throttle change
+		result, err := b.SendCommand(common.MAV_CMD_DO_CHANGE_SPEED, [7]float32{1, float32(req.Speed), -1})
+		if err != nil {
+			return err
+		}
+		if result != common.MAV_RESULT_ACCEPTED {
+			return fmt.Errorf("speed change rejected: %s", result)
+		}
+	}
+
+	b.mutex.RLock()
+	sysID := b.pixhawkSysID
+	b.mutex.RUnlock()
+
+	err := b.writeToFC(&common.MessageSetPositionTargetGlobalInt{
+		TargetSystem:    sysID,
+		TargetComponent: 1,
+		CoordinateFrame: common.MAV_FRAME_GLOBAL_RELATIVE_ALT_INT,
+		// Position only - ignore velocity, acceleration and yaw
+		TypeMask: common.POSITION_TARGET_TYPEMASK_VX_IGNORE | common.POSITION_TARGET_TYPEMASK_VY_IGNORE |
+			common.POSITION_TARGET_TYPEMASK_VZ_IGNORE | common.POSITION_TARGET_TYPEMASK_AX_IGNORE |
+			common.POSITION_TARGET_TYPEMASK_AY_IGNORE | common.POSITION_TARGET_TYPEMASK_AZ_IGNORE |
+			common.POSITION_TARGET_TYPEMASK_YAW_IGNORE | common.POSITION_TARGET_TYPEMASK_YAW_RATE_IGNORE,
+		LatInt: int32(req.Lat * 1e7),
+		LonInt: int32(req.Lon * 1e7),
+		Alt:    float32(req.Alt),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send position target: %w", err)
+	}
+	return nil
+}
+
+// reposition sends a PX4 goto (MAV_CMD_DO_REPOSITION), which flies to the target
+// and holds there. PX4 takes the altitude as AMSL
+func (b *MAVLinkBridge) reposition(req GotoRequest) error {
+	_, _, _, homePos := vehicleSnapshot()
+	if homePos == nil {
+		return fmt.Errorf("home position unknown - cannot convert the target altitude")
+	}
+	// param1: ground speed (-1 = default), param2: flags, param3: reserved, param4: yaw (NaN = keep)
+	speed := float32(-1)
+	if req.Speed > 0 {
+		speed = float32(req.Speed)
+	}
+	params := [4]float32{speed, float32(common.MAV_DO_REPOSITION_FLAGS_CHANGE_MODE), 0, float32(math.NaN())}
+	result, err := b.SendCommandInt(common.MAV_CMD_DO_REPOSITION, common.MAV_FRAME_GLOBAL_INT, params, req.Lat, req.Lon, float32(homePos.AltMSL+req.Alt))
+	if err != nil {
+		return err
+	}
+	if result != common.MAV_RESULT_ACCEPTED {
+		return fmt.Errorf("reposition rejected: %s", result)
+	}
+	return nil
+}
+
+// handleGuidedGoto accepts a click-to-fly target (POST {"lat", "lon", "alt", "speed"})
+func handleGuidedGoto(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req GotoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	if err := bridge.GuidedGoto(req); err != nil {
+		writeError(w, http.StatusConflict, errorCode(err, ErrValidation), err.Error())
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Flying to %.7f, %.7f at %.1f m", req.Lat, req.Lon, req.Alt),
+	})
+}
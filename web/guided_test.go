@@ -0,0 +1,155 @@
+package web
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+)
+
+const testLat, testLon = 47.397742, 8.545594
+
+// north returns the latitude m meters north of testLat
+func north(m float64) float64 {
+	return testLat + m/earthRadius*180/math.Pi
+}
+
+// guidedBridge returns a connected, disarmed bridge holding the given FC
+// parameters, with the vehicle hovering over home at testLat, testLon
+func guidedBridge(t *testing.T, limits GuidedSettings, params map[string]float64) *MAVLinkBridge {
+	t.Helper()
+	b := &MAVLinkBridge{connected: true, paramCache: make(map[string]CachedParameter)}
+	for name, v := range params {
+		b.paramCache[name] = CachedParameter{ParamId: name, ParamValue: v}
+	}
+
+	guidedMu.Lock()
+	prevLimits := guidedSettings
+	guidedSettings = limits
+	guidedMu.Unlock()
+	vehicleMu.Lock()
+	prevPos, prevHome := position, home
+	position = &VehiclePosition{Lat: testLat, Lon: testLon, AltRel: 20, Received: time.Now()}
+	home = &VehiclePosition{Lat: testLat, Lon: testLon}
+	vehicleMu.Unlock()
+	t.Cleanup(func() {
+		guidedMu.Lock()
+		guidedSettings = prevLimits
+		guidedMu.Unlock()
+		vehicleMu.Lock()
+		position, home = prevPos, prevHome
+		vehicleMu.Unlock()
+	})
+	return b
+}
+
+func TestOrbitLimits(t *testing.T) {
+	fence := map[string]float64{"FENCE_ENABLE": 1, "FENCE_TYPE": 2, "FENCE_RADIUS": 300}
+	tests := []struct {
+		name    string
+		maxDist float64
+		params  map[string]float64
+		center  float64 // Meters north of the vehicle and home
+		radius  float64
+		wantErr string // "" = passes every check (then refused as not armed)
+	}{
+		{"inside the fence", 1000, fence, 250, 30, ""},
+		{"center inside, circle outside the fence", 1000, fence, 250, 60, "outside the 300 m geofence"},
+		{"inside max distance", 1000, nil, 900, 50, ""},
+		{"center inside, circle beyond max distance", 1000, nil, 900, 150, "max 1000 m"},
+		{"radius above max distance", 1000, nil, 0, 1500, "between 5 and 1000 m"},
+		{"radius too small", 1000, nil, 100, 2, "at least 5 m"},
+		{"radius too small, no max distance", 0, nil, 100, 2, "at least 5 m"},
+		{"large radius, no max distance", 0, nil, 100, 5000, ""},
+	}
+	for _, tt := range tests {
+		b := guidedBridge(t, GuidedSettings{MaxDistance: tt.maxDist, MaxAltitude: 120, MaxSpeed: 15}, tt.params)
+		_, err := b.Orbit(OrbitRequest{Lat: north(tt.center), Lon: testLon, Alt: 30, Radius: tt.radius})
+		if tt.wantErr == "" {
+			if !errors.Is(err, errNotArmed) {
+				t.Errorf("%s: Orbit() = %v, want %v", tt.name, err, errNotArmed)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: Orbit() = %v, want an error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGotoGeofence(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]float64
+		north   float64
+		alt     float64
+		wantErr string
+	}{
+		{"no fence", nil, 500, 50, ""},
+		{"fence disabled", map[string]float64{"FENCE_ENABLE": 0, "FENCE_TYPE": 4}, 500, 50, ""},
+		{"circle inside", map[string]float64{"FENCE_ENABLE": 1, "FENCE_TYPE": 3, "FENCE_RADIUS": 300, "FENCE_ALT_MAX": 100}, 250, 50, ""},
+		{"circle outside", map[string]float64{"FENCE_ENABLE": 1, "FENCE_TYPE": 2, "FENCE_RADIUS": 300}, 350, 50, "outside the 300 m geofence"},
+		{"above max altitude", map[string]float64{"FENCE_ENABLE": 1, "FENCE_TYPE": 1, "FENCE_ALT_MAX": 40}, 100, 50, "exceeds geofence maximum 40 m"},
+		{"below min altitude", map[string]float64{"FENCE_ENABLE": 1, "FENCE_TYPE": 8, "FENCE_ALT_MIN": 60}, 100, 50, "below geofence minimum 60 m"},
+		{"polygon", map[string]float64{"FENCE_ENABLE": 1, "FENCE_TYPE": 7, "FENCE_RADIUS": 300, "FENCE_ALT_MAX": 100}, 100, 50, "polygon fences"},
+		{"type not loaded", map[string]float64{"FENCE_ENABLE": 1}, 100, 50, "FENCE_TYPE not loaded"},
+		{"radius not loaded", map[string]float64{"FENCE_ENABLE": 1, "FENCE_TYPE": 2}, 100, 50, "FENCE_RADIUS not loaded"},
+		{"PX4 inside", map[string]float64{"GF_MAX_HOR_DIST": 300, "GF_MAX_VER_DIST": 100}, 250, 50, ""},
+		{"PX4 horizontal", map[string]float64{"GF_MAX_HOR_DIST": 300, "GF_MAX_VER_DIST": 100}, 350, 50, "outside the 300 m geofence"},
+		{"PX4 vertical", map[string]float64{"GF_MAX_HOR_DIST": 300, "GF_MAX_VER_DIST": 40}, 100, 50, "exceeds geofence maximum 40 m"},
+		{"PX4 fence off", map[string]float64{"GF_MAX_HOR_DIST": 0, "GF_MAX_VER_DIST": 0}, 500, 50, ""},
+	}
+	for _, tt := range tests {
+		b := guidedBridge(t, GuidedSettings{MaxDistance: 1000, MaxAltitude: 120, MaxSpeed: 15}, tt.params)
+		err := b.checkTarget(north(tt.north), testLon, tt.alt, 0)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: checkTarget() = %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: checkTarget() = %v, want an error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestGotoMode(t *testing.T) {
+	vehicleMu.RLock()
+	prevType, prevAP, prevMode := vehicleType, autopilot, flightMode
+	vehicleMu.RUnlock()
+	t.Cleanup(func() { HandleFlightMode(prevType, prevAP, prevMode) })
+
+	px4Hold, _ := flightModeNumber(common.MAV_AUTOPILOT_PX4, common.MAV_TYPE_QUADROTOR, "AUTO.LOITER")
+	px4Mission, _ := flightModeNumber(common.MAV_AUTOPILOT_PX4, common.MAV_TYPE_QUADROTOR, "AUTO.MISSION")
+	tests := []struct {
+		name    string
+		ap      common.MAV_AUTOPILOT
+		mode    uint32
+		wantErr string
+	}{
+		{"ArduPilot GUIDED", common.MAV_AUTOPILOT_ARDUPILOTMEGA, 4, ""},
+		{"ArduPilot LOITER", common.MAV_AUTOPILOT_ARDUPILOTMEGA, 5, "not in GUIDED mode"},
+		{"PX4 Hold", common.MAV_AUTOPILOT_PX4, px4Hold, ""},
+		{"PX4 Mission", common.MAV_AUTOPILOT_PX4, px4Mission, "not in AUTO.LOITER mode"},
+		{"other autopilot", common.MAV_AUTOPILOT_GENERIC, 0, "not supported"},
+	}
+	for _, tt := range tests {
+		b := guidedBridge(t, GuidedSettings{MaxDistance: 1000, MaxAltitude: 120, MaxSpeed: 15}, nil)
+		b.armed = true
+		HandleFlightMode(common.MAV_TYPE_QUADROTOR, tt.ap, tt.mode)
+		err := b.checkGoto(GotoRequest{Lat: north(100), Lon: testLon, Alt: 30})
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: checkGoto() = %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: checkGoto() = %v, want an error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/health"
+)
+
+// handleFCHealth serves decoded flight controller health (SYS_STATUS sensors, EKF, vibration)
+func handleFCHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	snapshot := health.Global.Snapshot()
+	snapshot["connected"] = bridge.IsConnected()
+	snapshot["flightMode"], snapshot["customMode"] = currentFlightMode()
+	if reason := fcAbsent(); reason != "" {
+		snapshot["flightMode"], snapshot["customMode"] = nil, nil
+		snapshot["fcAbsent"] = reason
+		snapshot["fcAbsentMessage"] = fcAbsentMessage(reason)
+	}
+	json.NewEncoder(w).Encode(snapshot)
+}
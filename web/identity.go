@@ -0,0 +1,38 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"DroneBridge/internal/identity"
+	"DroneBridge/internal/metrics"
+)
+
+// handleIdentity returns the drone name metadata (GET) or updates it (PUT/POST)
+func handleIdentity(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req identity.Identity
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		if err := identity.Global.Update(req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrValidation, err.Error())
+			return
+		}
+		log.Printf("[WEB] Drone identity updated: %s", identity.Global.DisplayName())
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Drone identity updated: %s", identity.Global.DisplayName()))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	json.NewEncoder(w).Encode(identity.Global.Get())
+}
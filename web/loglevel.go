@@ -0,0 +1,122 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"DroneBridge/internal/logger"
+	"DroneBridge/internal/metrics"
+)
+
+// maxTemporaryLevelMinutes caps "debug for N minutes" so a forgotten request can't last for days
+const maxTemporaryLevelMinutes = 24 * 60
+
+// logLevelState describes the current global, temporary and per-subsystem levels
+func logLevelState() map[string]interface{} {
+	state := map[string]interface{}{
+		"level":      logger.GetLevelString(),
+		"subsystems": logger.SubsystemLevels(),
+	}
+	if revertTo, at, ok := logger.TemporaryLevel(); ok {
+		state["temporary"] = map[string]interface{}{
+			"revertTo":         revertTo.String(),
+			"revertAt":         at,
+			"remainingSeconds": int(time.Until(at).Seconds()),
+		}
+	}
+	return state
+}
+
+// handleLogLevel returns the log levels (GET) or changes them at runtime (PUT/POST) with
+// {"level": "debug", "minutes": 10, "subsystems": {"MESH": "debug", "AUTH": ""}}.
+// With minutes the global level reverts after that time; an empty subsystem level
+// removes the override.
+func handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req struct {
+			Level      string            `json:"level"`
+			Minutes    int               `json:"minutes"`
+			Subsystems map[string]string `json:"subsystems"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		if err := applyLogLevels(req.Level, req.Minutes, req.Subsystems); err != nil {
+			writeError(w, http.StatusBadRequest, ErrValidation, err.Error())
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	json.NewEncoder(w).Encode(logLevelState())
+}
+
+// applyLogLevels validates the whole request before changing anything
+func applyLogLevels(levelStr string, minutes int, subsystems map[string]string) error {
+	if minutes < 0 || minutes > maxTemporaryLevelMinutes {
+		return fmt.Errorf("minutes must be between 0 and %d", maxTemporaryLevelMinutes)
+	}
+	if minutes > 0 && levelStr == "" {
+		return fmt.Errorf("minutes requires a level")
+	}
+	var level logger.Level
+	if levelStr != "" {
+		var err error
+		if level, err = logger.ParseLevel(levelStr); err != nil {
+			return err
+		}
+	}
+	subLevels := make(map[string]logger.Level, len(subsystems))
+	for tag, s := range subsystems {
+		tag = strings.Trim(tag, "[] ")
+		if tag == "" {
+			return fmt.Errorf("subsystem names must not be empty")
+		}
+		if s == "" {
+			continue
+		}
+		l, err := logger.ParseLevel(s)
+		if err != nil {
+			return fmt.Errorf("subsystem %s: %w", tag, err)
+		}
+		subLevels[tag] = l
+	}
+
+	var changes []string
+	if levelStr != "" {
+		if minutes > 0 {
+			logger.SetLevelFor(level, time.Duration(minutes)*time.Minute)
+			changes = append(changes, fmt.Sprintf("level %s for %d min", level, minutes))
+		} else {
+			logger.SetLevel(level)
+			changes = append(changes, fmt.Sprintf("level %s", level))
+		}
+	}
+	for tag, s := range subsystems {
+		tag = strings.Trim(tag, "[] ")
+		if s == "" {
+			logger.ClearSubsystemLevel(tag)
+			changes = append(changes, fmt.Sprintf("%s default", strings.ToUpper(tag)))
+		} else {
+			logger.SetSubsystemLevel(tag, subLevels[tag])
+			changes = append(changes, fmt.Sprintf("%s %s", strings.ToUpper(tag), subLevels[tag]))
+		}
+	}
+	if len(changes) > 0 {
+		log.Printf("[WEB] Log levels changed: %s", strings.Join(changes, ", "))
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Log levels changed: %s", strings.Join(changes, ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"DroneBridge/internal/audit"
+	"DroneBridge/internal/maintenance"
+	"DroneBridge/internal/metrics"
+)
+
+// handleMaintenance shows service counters and the maintenance log (GET) or logs
+// a maintenance action (POST {"item": "props", "action": "replaced", "note": "..."})
+func handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			Item   string `json:"item"`
+			Action string `json:"action"`
+			Note   string `json:"note"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, "Invalid request body")
+			return
+		}
+		ev, err := maintenance.Global.Log(req.Item, req.Action, req.Note)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, err.Error())
+			return
+		}
+		log.Printf("[WEB] Maintenance logged by %s: %s %s", r.RemoteAddr, ev.Item, ev.Action)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Maintenance: %s %s", ev.Item, ev.Action))
+		audit.Global.Record("maintenance", ev.Action, map[string]interface{}{"item": ev.Item, "note": ev.Note, "remote": r.RemoteAddr})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	json.NewEncoder(w).Encode(maintenance.Global.Status())
+}
+
+// handleMaintenanceEnter starts or extends maintenance mode (POST {"minutes": 30,
+// "reason": "motor swap"}; minutes defaults to maintenance.mode_minutes). Needs
+// an admin token and is refused while the vehicle is armed.
+func handleMaintenanceEnter(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req struct {
+		Minutes float64 `json:"minutes"`
+		Reason  string  `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, `Invalid request body: expected {"minutes": 30, "reason": "..."}`)
+			return
+		}
+	}
+	if req.Minutes < 0 {
+		writeError(w, http.StatusBadRequest, ErrValidation, "minutes must not be negative")
+		return
+	}
+	if req.Reason == "" {
+		req.Reason = "bench work"
+	}
+	// Maintenance mode cuts cloud telemetry and suspends the dead-man actions
+	if bridge != nil && bridge.IsArmed() {
+		writeError(w, http.StatusConflict, ErrVehicleArmed, "vehicle is armed - disarm before entering maintenance mode")
+		return
+	}
+	by := requestIdentity(r).ID + "@" + r.RemoteAddr
+	status, err := maintenance.Mode.Enter(time.Duration(req.Minutes*float64(time.Minute)), req.Reason, by)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrValidation, err.Error())
+		return
+	}
+	log.Printf("[WEB] Maintenance mode entered by %s until %s: %s", by, status.Until.Format(time.RFC3339), req.Reason)
+	audit.Global.Record("maintenance", "enter", map[string]interface{}{"until": status.Until, "reason": req.Reason, "token": requestIdentity(r).ID, "remote": r.RemoteAddr})
+
+	json.NewEncoder(w).Encode(status)
+}
+
+// handleMaintenanceExit ends maintenance mode before its timeout (POST)
+func handleMaintenanceExit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if maintenance.Mode.Exit("exit requested") {
+		log.Printf("[WEB] Maintenance mode exited by %s", r.RemoteAddr)
+		audit.Global.Record("maintenance", "exit", map[string]interface{}{"remote": r.RemoteAddr})
+	}
+	json.NewEncoder(w).Encode(maintenance.Mode.Status())
+}
+
+// handleMaintenanceMode shows whether maintenance mode is on and until when (GET)
+func handleMaintenanceMode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	json.NewEncoder(w).Encode(maintenance.Mode.Status())
+}
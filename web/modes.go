@@ -0,0 +1,164 @@
+package web
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+)
+
+// ArduPilot custom_mode numbers by vehicle class
+var (
+	copterModes = map[uint32]string{
+		0: "STABILIZE", 1: "ACRO", 2: "ALT_HOLD", 3: "AUTO", 4: "GUIDED", 5: "LOITER", 6: "RTL",
+		7: "CIRCLE", 9: "LAND", 11: "DRIFT", 13: "SPORT", 14: "FLIP", 15: "AUTOTUNE", 16: "POSHOLD",
+		17: "BRAKE", 18: "THROW", 19: "AVOID_ADSB", 20: "GUIDED_NOGPS", 21: "SMART_RTL", 22: "FLOWHOLD",
+		23: "FOLLOW", 24: "ZIGZAG", 25: "SYSTEMID", 26: "AUTOROTATE", 27: "AUTO_RTL", 28: "TURTLE",
+	}
+	planeModes = map[uint32]string{
+		0: "MANUAL", 1: "CIRCLE", 2: "STABILIZE", 3: "TRAINING", 4: "ACRO", 5: "FBWA", 6: "FBWB",
+		7: "CRUISE", 8: "AUTOTUNE", 10: "AUTO", 11: "RTL", 12: "LOITER", 13: "TAKEOFF", 14: "AVOID_ADSB",
+		15: "GUIDED", 17: "QSTABILIZE", 18: "QHOVER", 19: "QLOITER", 20: "QLAND", 21: "QRTL",
+		22: "QAUTOTUNE", 23: "QACRO", 24: "THERMAL", 25: "LOITER_ALT_QLAND",
+	}
+	roverModes = map[uint32]string{
+		0: "MANUAL", 1: "ACRO", 3: "STEERING", 4: "HOLD", 5: "LOITER", 6: "FOLLOW", 7: "SIMPLE",
+		8: "DOCK", 9: "CIRCLE", 10: "AUTO", 11: "RTL", 12: "SMART_RTL", 15: "GUIDED",
+	}
+	subModes = map[uint32]string{
+		0: "STABILIZE", 1: "ACRO", 2: "ALT_HOLD", 3: "AUTO", 4: "GUIDED", 7: "CIRCLE", 9: "SURFACE",
+		16: "POSHOLD", 19: "MANUAL", 20: "MOTOR_DETECT", 21: "SURFTRAK",
+	}
+)
+
+// PX4 packs main mode and, for AUTO, the sub mode into custom_mode bytes 2 and 3
+var (
+	px4MainModes = map[uint32]string{
+		1: "MANUAL", 2: "ALTCTL", 3: "POSCTL", 4: "AUTO", 5: "ACRO", 6: "OFFBOARD", 7: "STABILIZED",
+		8: "RATTITUDE", 9: "SIMPLE", 10: "TERMINATION",
+	}
+	px4AutoModes = map[uint32]string{
+		1: "READY", 2: "TAKEOFF", 3: "LOITER", 4: "MISSION", 5: "RTL", 6: "LAND", 8: "FOLLOW_TARGET",
+		9: "PRECLAND", 10: "VTOL_TAKEOFF",
+	}
+)
+
+const px4MainAuto = 4
+
+// arduPilotModes returns the custom mode table for the vehicle type
+func arduPilotModes(typ common.MAV_TYPE) map[uint32]string {
+	switch {
+	case isFixedWing(typ):
+		return planeModes
+	case typ == common.MAV_TYPE_GROUND_ROVER || typ == common.MAV_TYPE_SURFACE_BOAT:
+		return roverModes
+	case typ == common.MAV_TYPE_SUBMARINE:
+		return subModes
+	}
+	return copterModes
+}
+
+// flightModeName decodes HEARTBEAT custom_mode for the autopilot and vehicle type,
+// e.g. "LOITER" (ArduCopter) or "AUTO.MISSION" (PX4)
+func flightModeName(autopilot common.MAV_AUTOPILOT, typ common.MAV_TYPE, customMode uint32) string {
+	if autopilot == common.MAV_AUTOPILOT_PX4 {
+		main := (customMode >> 16) & 0xFF
+		sub := (customMode >> 24) & 0xFF
+		name, ok := px4MainModes[main]
+		if !ok {
+			return fmt.Sprintf("PX4_MODE_%d", main)
+		}
+		if main == px4MainAuto {
+			if subName, ok := px4AutoModes[sub]; ok {
+				return name + "." + subName
+			}
+		}
+		return name
+	}
+	if name, ok := arduPilotModes(typ)[customMode]; ok {
+		return name
+	}
+	return fmt.Sprintf("MODE_%d", customMode)
+}
+
+// flightModeNumber returns the custom_mode the heartbeat reports for a named
+// mode: ArduPilot names per vehicle type, PX4 names as flightModeName decodes
+// them ("POSCTL", "AUTO.RTL"). Other autopilots have no known mode numbers.
+func flightModeNumber(autopilot common.MAV_AUTOPILOT, typ common.MAV_TYPE, name string) (uint32, bool) {
+	switch autopilot {
+	case common.MAV_AUTOPILOT_ARDUPILOTMEGA:
+		for mode, n := range arduPilotModes(typ) {
+			if n == name {
+				return mode, true
+			}
+		}
+	case common.MAV_AUTOPILOT_PX4:
+		mainName, subName, _ := strings.Cut(name, ".")
+		for main, n := range px4MainModes {
+			if n != mainName {
+				continue
+			}
+			if subName == "" {
+				return main << 16, true
+			}
+			if main != px4MainAuto {
+				return 0, false
+			}
+			for sub, n := range px4AutoModes {
+				if n == subName {
+					return main<<16 | sub<<24, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+// setModeParams returns the MAV_CMD_DO_SET_MODE parameters for a custom_mode from
+// flightModeNumber: ArduPilot takes the mode number in param2, PX4 the main mode
+// in param2 and the sub mode in param3
+func setModeParams(autopilot common.MAV_AUTOPILOT, customMode uint32) [7]float32 {
+	p := [7]float32{float32(common.MAV_MODE_FLAG_CUSTOM_MODE_ENABLED), float32(customMode)}
+	if autopilot == common.MAV_AUTOPILOT_PX4 {
+		p[1] = float32((customMode >> 16) & 0xFF)
+		p[2] = float32((customMode >> 24) & 0xFF)
+	}
+	return p
+}
+
+// Flight actions whose mode depends on the autopilot
+const (
+	actionGuided = "guided" // Fly to position targets from the bridge
+	actionLand   = "land"
+	actionRTL    = "rtl"
+)
+
+// actionMode returns the mode name an action uses on the connected autopilot
+func actionMode(autopilot common.MAV_AUTOPILOT, typ common.MAV_TYPE, action string) (string, error) {
+	switch autopilot {
+	case common.MAV_AUTOPILOT_ARDUPILOTMEGA:
+		switch action {
+		case actionGuided:
+			return "GUIDED", nil
+		case actionLand:
+			if isFixedWing(typ) {
+				return "QLAND", nil
+			}
+			return "LAND", nil
+		case actionRTL:
+			return "RTL", nil
+		}
+	case common.MAV_AUTOPILOT_PX4:
+		switch action {
+		case actionGuided:
+			return "AUTO.LOITER", nil // Hold: PX4 repositions from there (DO_REPOSITION)
+		case actionLand:
+			return "AUTO.LAND", nil
+		case actionRTL:
+			return "AUTO.RTL", nil
+		}
+	default:
+		return "", fmt.Errorf("flight modes of autopilot %s are not supported (ArduPilot and PX4 only)", autopilot)
+	}
+	return "", fmt.Errorf("unknown flight action %q", action)
+}
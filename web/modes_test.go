@@ -0,0 +1,88 @@
+package web
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+)
+
+func TestActionModes(t *testing.T) {
+	const (
+		ardupilot = common.MAV_AUTOPILOT_ARDUPILOTMEGA
+		px4       = common.MAV_AUTOPILOT_PX4
+		copter    = common.MAV_TYPE_QUADROTOR
+		vtol      = common.MAV_TYPE_VTOL_TILTROTOR
+	)
+	tests := []struct {
+		name       string
+		ap         common.MAV_AUTOPILOT
+		typ        common.MAV_TYPE
+		action     string
+		wantMode   string
+		wantNumber uint32
+		wantParams [3]float32 // DO_SET_MODE param1..3
+	}{
+		{"ArduCopter RTL", ardupilot, copter, actionRTL, "RTL", 6, [3]float32{1, 6, 0}},
+		{"ArduCopter land", ardupilot, copter, actionLand, "LAND", 9, [3]float32{1, 9, 0}},
+		{"ArduCopter guided", ardupilot, copter, actionGuided, "GUIDED", 4, [3]float32{1, 4, 0}},
+		{"QuadPlane land", ardupilot, vtol, actionLand, "QLAND", 20, [3]float32{1, 20, 0}},
+		{"ArduPlane RTL", ardupilot, common.MAV_TYPE_FIXED_WING, actionRTL, "RTL", 11, [3]float32{1, 11, 0}},
+		{"PX4 RTL", px4, copter, actionRTL, "AUTO.RTL", 4<<16 | 5<<24, [3]float32{1, 4, 5}},
+		{"PX4 land", px4, copter, actionLand, "AUTO.LAND", 4<<16 | 6<<24, [3]float32{1, 4, 6}},
+		{"PX4 hold", px4, copter, actionGuided, "AUTO.LOITER", 4<<16 | 3<<24, [3]float32{1, 4, 3}},
+		{"PX4 VTOL RTL", px4, vtol, actionRTL, "AUTO.RTL", 4<<16 | 5<<24, [3]float32{1, 4, 5}},
+	}
+	for _, tt := range tests {
+		mode, err := actionMode(tt.ap, tt.typ, tt.action)
+		if err != nil || mode != tt.wantMode {
+			t.Errorf("%s: actionMode() = %q, %v, want %q", tt.name, mode, err, tt.wantMode)
+			continue
+		}
+		n, ok := flightModeNumber(tt.ap, tt.typ, mode)
+		if !ok || n != tt.wantNumber {
+			t.Errorf("%s: flightModeNumber(%q) = %#x, %v, want %#x", tt.name, mode, n, ok, tt.wantNumber)
+			continue
+		}
+		// The heartbeat check compares against the same number
+		if got := flightModeName(tt.ap, tt.typ, n); got != mode {
+			t.Errorf("%s: flightModeName(%#x) = %q, want %q", tt.name, n, got, mode)
+		}
+		p := setModeParams(tt.ap, n)
+		if got := [3]float32{p[0], p[1], p[2]}; got != tt.wantParams {
+			t.Errorf("%s: setModeParams() = %v, want %v", tt.name, got, tt.wantParams)
+		}
+	}
+
+	// Autopilots without known mode numbers are refused, not sent ArduPilot numbers
+	for _, ap := range []common.MAV_AUTOPILOT{common.MAV_AUTOPILOT_GENERIC, common.MAV_AUTOPILOT_INVALID} {
+		if mode, err := actionMode(ap, copter, actionRTL); err == nil {
+			t.Errorf("actionMode(%s) = %q, want an error", ap, mode)
+		}
+		if _, ok := flightModeNumber(ap, copter, "RTL"); ok {
+			t.Errorf("flightModeNumber(%s, RTL) found a mode", ap)
+		}
+	}
+	if _, ok := flightModeNumber(px4, copter, "POSCTL.RTL"); ok {
+		t.Error("flightModeNumber() accepted a sub mode outside AUTO")
+	}
+}
+
+func TestTakeoffParams(t *testing.T) {
+	home := &VehiclePosition{AltMSL: 480}
+
+	p, err := takeoffParams(common.MAV_AUTOPILOT_ARDUPILOTMEGA, common.MAV_TYPE_QUADROTOR, 20, home)
+	if err != nil || p[6] != 20 {
+		t.Errorf("ArduPilot: takeoff altitude %v, %v, want 20 (above home)", p[6], err)
+	}
+	p, err = takeoffParams(common.MAV_AUTOPILOT_PX4, common.MAV_TYPE_QUADROTOR, 20, home)
+	if err != nil || p[6] != 500 || !math.IsNaN(float64(p[4])) || !math.IsNaN(float64(p[5])) {
+		t.Errorf("PX4: takeoff params %v, %v, want 500 m AMSL at the current position", p, err)
+	}
+	if _, err := takeoffParams(common.MAV_AUTOPILOT_PX4, common.MAV_TYPE_QUADROTOR, 20, nil); err == nil {
+		t.Error("PX4 without home: no error")
+	}
+	if _, err := takeoffParams(common.MAV_AUTOPILOT_GENERIC, common.MAV_TYPE_QUADROTOR, 20, home); err == nil {
+		t.Error("generic autopilot: no error")
+	}
+}
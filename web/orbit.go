@@ -0,0 +1,188 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/metrics"
+)
+
+// minOrbitRadius keeps orbits from being flown on top of the point of interest
+const minOrbitRadius = 5.0
+
+// OrbitRequest is the body of POST /api/guided/orbit
+type OrbitRequest struct {
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	Alt       float64 `json:"alt"`                 // Meters above home
+	Radius    float64 `json:"radius"`              // Meters
+	Velocity  float64 `json:"velocity,omitempty"`  // Tangential m/s (0 = vehicle default)
+	Clockwise *bool   `json:"clockwise,omitempty"` // Default true
+	Orbits    float64 `json:"orbits,omitempty"`    // Number of orbits (0 = until changed)
+}
+
+// ROIRequest is the body of POST /api/guided/roi
+type ROIRequest struct {
+	Lat   float64 `json:"lat"`
+	Lon   float64 `json:"lon"`
+	Alt   float64 `json:"alt"`             // Meters above home (0 = ground level)
+	Clear bool    `json:"clear,omitempty"` // Cancel the ROI instead
+}
+
+// Orbit commands an orbit around a point of interest, facing its center (MAV_CMD_DO_ORBIT)
+func (b *MAVLinkBridge) Orbit(req OrbitRequest) (common.MAV_RESULT, error) {
+	guidedMu.RLock()
+	limits := guidedSettings
+	guidedMu.RUnlock()
+
+	if req.Radius < minOrbitRadius {
+		return 0, fmt.Errorf("radius must be at least %.0f m", minOrbitRadius)
+	}
+	if limits.MaxDistance > 0 && req.Radius > limits.MaxDistance {
+		return 0, fmt.Errorf("radius must be between %.0f and %.0f m", minOrbitRadius, limits.MaxDistance)
+	}
+	if req.Velocity < 0 || req.Velocity > limits.MaxSpeed {
+		return 0, fmt.Errorf("velocity must be between 0 and %.1f m/s", limits.MaxSpeed)
+	}
+	if req.Orbits < 0 {
+		return 0, fmt.Errorf("orbits must not be negative")
+	}
+	// The whole circle, not just its center, has to stay inside the limits
+	if err := b.checkTarget(req.Lat, req.Lon, req.Alt, req.Radius); err != nil {
+		return 0, err
+	}
+	if !b.IsArmed() {
+		return 0, errNotArmed
+	}
+
+	// param1: radius (negative = counter-clockwise), param2: velocity (NaN = default),
+	// param3: yaw behaviour, param4: orbits (0 = forever)
+	radius := float32(req.Radius)
+	if req.Clockwise != nil && !*req.Clockwise {
+		radius = -radius
+	}
+	velocity := float32(math.NaN())
+	if req.Velocity > 0 {
+		velocity = float32(req.Velocity)
+	}
+	params := [4]float32{radius, velocity, float32(common.ORBIT_YAW_BEHAVIOUR_HOLD_FRONT_TO_CIRCLE_CENTER), float32(req.Orbits)}
+
+	result, err := b.SendCommandInt(common.MAV_CMD_DO_ORBIT, common.MAV_FRAME_GLOBAL_RELATIVE_ALT_INT, params, req.Lat, req.Lon, float32(req.Alt))
+	if err == nil && result == common.MAV_RESULT_ACCEPTED {
+		log.Printf("[WEB] Orbit %.0f m around %.7f, %.7f at %.1f m", req.Radius, req.Lat, req.Lon, req.Alt)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Orbit %.0f m around %.7f, %.7f", req.Radius, req.Lat, req.Lon))
+	}
+	return result, err
+}
+
+// SetROI points the vehicle/camera at a location (MAV_CMD_DO_SET_ROI_LOCATION) or clears it
+func (b *MAVLinkBridge) SetROI(req ROIRequest) (common.MAV_RESULT, error) {
+	if req.Clear {
+		return b.SendCommand(common.MAV_CMD_DO_SET_ROI_NONE, [7]float32{})
+	}
+
+	guidedMu.RLock()
+	limits := guidedSettings
+	guidedMu.RUnlock()
+
+	if req.Lat < -90 || req.Lat > 90 || req.Lon < -180 || req.Lon > 180 || (req.Lat == 0 && req.Lon == 0) {
+		return 0, fmt.Errorf("invalid coordinates %.7f, %.7f", req.Lat, req.Lon)
+	}
+	if req.Alt < 0 || req.Alt > limits.MaxAltitude {
+		return 0, fmt.Errorf("altitude must be between 0 and %.0f m above home", limits.MaxAltitude)
+	}
+	if !b.IsConnected() {
+		return 0, errNotConnected
+	}
+	_, _, pos, _ := vehicleSnapshot()
+	if pos != nil && time.Since(pos.Received) <= positionMaxAge && limits.MaxDistance > 0 {
+		if d := distanceMeters(pos.Lat, pos.Lon, req.Lat, req.Lon); d > limits.MaxDistance {
+			return 0, fmt.Errorf("point of interest is %.0f m away (max %.0f m)", d, limits.MaxDistance)
+		}
+	}
+
+	result, err := b.SendCommandInt(common.MAV_CMD_DO_SET_ROI_LOCATION, common.MAV_FRAME_GLOBAL_RELATIVE_ALT_INT, [4]float32{}, req.Lat, req.Lon, float32(req.Alt))
+	if err == nil && result == common.MAV_RESULT_ACCEPTED {
+		log.Printf("[WEB] ROI set to %.7f, %.7f at %.1f m", req.Lat, req.Lon, req.Alt)
+	}
+	return result, err
+}
+
+// writeCommandResult encodes the outcome of an ACK-tracked command
+func writeCommandResult(w http.ResponseWriter, result common.MAV_RESULT, err error, okMessage string) {
+	if err != nil {
+		writeError(w, http.StatusConflict, errorCode(err, ErrValidation), err.Error())
+		return
+	}
+	if result != common.MAV_RESULT_ACCEPTED {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"code":    ErrCommandRejected,
+			"result":  result.String(),
+			"message": fmt.Sprintf("Command rejected by FC: %s", result),
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"result":  result.String(),
+		"message": okMessage,
+	})
+}
+
+// handleOrbit commands an orbit around a point of interest (POST)
+func handleOrbit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var req OrbitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	if bridge == nil {
+		writeCommandResult(w, 0, errNotInitialized, "")
+		return
+	}
+
+	result, err := bridge.Orbit(req)
+	writeCommandResult(w, result, err, fmt.Sprintf("Orbiting %.7f, %.7f at %.0f m radius", req.Lat, req.Lon, req.Radius))
+}
+
+// handleROI points the camera at a location or clears the ROI (POST)
+func handleROI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var req ROIRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	if bridge == nil {
+		writeCommandResult(w, 0, errNotInitialized, "")
+		return
+	}
+
+	result, err := bridge.SetROI(req)
+	message := fmt.Sprintf("ROI set to %.7f, %.7f", req.Lat, req.Lon)
+	if req.Clear {
+		message = "ROI cleared"
+	}
+	writeCommandResult(w, result, err, message)
+}
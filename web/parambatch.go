@@ -0,0 +1,173 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/metrics"
+)
+
+// ParamBatchRequest is a list of parameter changes applied in order
+type ParamBatchRequest struct {
+	Params   []ParamSetRequest `json:"params"`
+	Rollback bool              `json:"rollback"` // Restore already-applied params if any change fails
+}
+
+// ParamBatchResult is the outcome for a single parameter of a batch
+type ParamBatchResult struct {
+	ParamName string    `json:"paramName"`
+	Requested float64   `json:"requested"`
+	Previous  *float64  `json:"previous,omitempty"` // Cached value before the batch (nil = unknown)
+	NewValue  *float64  `json:"newValue,omitempty"` // Value confirmed by PARAM_VALUE
+	Status    string    `json:"status"`             // applied, failed, skipped, rolled_back, rollback_failed
+	Code      ErrorCode `json:"code,omitempty"`     // Why a change failed or could not be rolled back
+	Message   string    `json:"message,omitempty"`
+}
+
+// ParamBatchResponse is the per-parameter report of a batch
+type ParamBatchResponse struct {
+	Success    bool               `json:"success"`
+	RolledBack bool               `json:"rolledBack"`
+	Results    []ParamBatchResult `json:"results"`
+}
+
+// mavParamTypeName maps a MAV_PARAM_TYPE back to the type string accepted by SetParameter
+func mavParamTypeName(t int) string {
+	switch common.MAV_PARAM_TYPE(t) {
+	case common.MAV_PARAM_TYPE_REAL32:
+		return "REAL32"
+	case common.MAV_PARAM_TYPE_UINT32:
+		return "UINT32"
+	case common.MAV_PARAM_TYPE_INT16:
+		return "INT16"
+	case common.MAV_PARAM_TYPE_UINT16:
+		return "UINT16"
+	case common.MAV_PARAM_TYPE_INT8:
+		return "INT8"
+	case common.MAV_PARAM_TYPE_UINT8:
+		return "UINT8"
+	default:
+		return "INT32"
+	}
+}
+
+// paramMatches reports whether a confirmed value equals the requested one (float32 precision)
+func paramMatches(requested, confirmed float64) bool {
+	return math.Abs(requested-confirmed) <= 1e-6*math.Max(1, math.Abs(requested))
+}
+
+// SetParameterBatch applies changes sequentially, verifying each against PARAM_VALUE.
+// On the first failure the remaining changes are skipped and, if rollback is set,
+// applied changes are restored to their previously cached values in reverse order.
+func (b *MAVLinkBridge) SetParameterBatch(req ParamBatchRequest) *ParamBatchResponse {
+	resp := &ParamBatchResponse{Success: true, Results: make([]ParamBatchResult, len(req.Params))}
+	prevTypes := make([]string, len(req.Params))
+
+	failed := false
+	for i, p := range req.Params {
+		res := &resp.Results[i]
+		res.ParamName = p.ParamName
+		res.Requested = p.ParamValue
+
+		if prev, ok := b.GetCachedParameter(p.ParamName); ok {
+			v := prev.ParamValue
+			res.Previous = &v
+			prevTypes[i] = mavParamTypeName(prev.ParamType)
+		}
+
+		if failed {
+			res.Status = "skipped"
+			continue
+		}
+
+		r := b.SetParameter(p.ParamName, p.ParamValue, p.ParamType)
+		switch {
+		case !r.Success:
+			res.Status = "failed"
+			res.Code = r.Code
+			res.Message = r.Message
+		case !paramMatches(p.ParamValue, r.NewValue):
+			res.Status = "failed"
+			res.Code = ErrParamFailed
+			res.Message = fmt.Sprintf("FC confirmed %v instead of %v", r.NewValue, p.ParamValue)
+			v := r.NewValue
+			res.NewValue = &v
+		default:
+			res.Status = "applied"
+			v := r.NewValue
+			res.NewValue = &v
+			continue
+		}
+		failed = true
+		resp.Success = false
+	}
+
+	if !failed || !req.Rollback {
+		return resp
+	}
+
+	// Roll back in reverse order, including a failed param the FC may have partially accepted
+	resp.RolledBack = true
+	for i := len(resp.Results) - 1; i >= 0; i-- {
+		res := &resp.Results[i]
+		if res.Status != "applied" && !(res.Status == "failed" && res.NewValue != nil) {
+			continue
+		}
+		if res.Previous == nil {
+			res.Status = "rollback_failed"
+			res.Code = ErrParamFailed
+			res.Message = "previous value unknown (parameter was not cached)"
+			continue
+		}
+		r := b.SetParameter(res.ParamName, *res.Previous, prevTypes[i])
+		if r.Success && paramMatches(*res.Previous, r.NewValue) {
+			res.Status = "rolled_back"
+		} else {
+			res.Status = "rollback_failed"
+			res.Code = r.Code
+			if r.Success {
+				res.Code = ErrParamFailed
+			}
+			res.Message = r.Message
+		}
+	}
+	return resp
+}
+
+// handleParamSetBatch applies a list of parameter changes with optional rollback
+func handleParamSetBatch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req ParamBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+	if len(req.Params) == 0 {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, "No parameters in batch")
+		return
+	}
+
+	if bridge == nil {
+		writeError(w, http.StatusServiceUnavailable, ErrBridgeNotReady, "MAVLink bridge not initialized")
+		return
+	}
+
+	log.Printf("[WEB] Received param batch: %d params (rollback=%v)", len(req.Params), req.Rollback)
+	resp := bridge.SetParameterBatch(req)
+	if !resp.Success {
+		metrics.Global.AddLog("WARN", fmt.Sprintf("Param batch failed (rolled back: %v)", resp.RolledBack))
+	}
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,232 @@
+package web
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"DroneBridge/internal/metrics"
+)
+
+// profileDir holds golden parameter profiles, one JSON file per airframe type
+var profileDir = "profiles"
+
+var airframeNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// SetProfileDir sets where golden parameter profiles are stored
+func SetProfileDir(dir string) {
+	profileDir = dir
+}
+
+// ParamDeviation is a parameter whose live value differs from the golden profile
+type ParamDeviation struct {
+	ParamId  string  `json:"paramId"`
+	Expected float64 `json:"expected"`
+	Actual   float64 `json:"actual"`
+}
+
+// ParamDiffReport compares the live parameter cache against a golden profile
+type ParamDiffReport struct {
+	Airframe   string           `json:"airframe"`
+	Checked    int              `json:"checked"`
+	Matched    int              `json:"matched"`
+	Deviations []ParamDeviation `json:"deviations"`
+	Missing    []string         `json:"missing"` // In the profile but not in the live cache
+	Complete   bool             `json:"complete"`
+	Message    string           `json:"message,omitempty"`
+}
+
+func profilePath(airframe string) (string, error) {
+	if !airframeNamePattern.MatchString(airframe) {
+		return "", fmt.Errorf("invalid airframe name %q", airframe)
+	}
+	return filepath.Join(profileDir, airframe+".json"), nil
+}
+
+// parseParamProfile accepts a JSON object {"NAME": value}, a Mission Planner
+// .param file (NAME,VALUE) or a QGroundControl .params file (tab separated)
+func parseParamProfile(data []byte) (map[string]float64, error) {
+	profile := make(map[string]float64)
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		if err := json.Unmarshal(data, &profile); err != nil {
+			return nil, fmt.Errorf("invalid JSON profile: %w", err)
+		}
+		return profile, nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '\t' || r == ' ' })
+		var name, value string
+		switch len(fields) {
+		case 2: // Mission Planner: NAME,VALUE
+			name, value = fields[0], fields[1]
+		case 5: // QGC: SYSID COMPID NAME VALUE TYPE
+			name, value = fields[2], fields[3]
+		default:
+			return nil, fmt.Errorf("line %d: unrecognized format %q", line, text)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid value for %s: %v", line, name, err)
+		}
+		profile[name] = v
+	}
+	if len(profile) == 0 {
+		return nil, fmt.Errorf("profile contains no parameters")
+	}
+	return profile, scanner.Err()
+}
+
+func loadParamProfile(airframe string) (map[string]float64, error) {
+	path, err := profilePath(airframe)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no golden profile for airframe %q: %w", airframe, err)
+	}
+	profile := make(map[string]float64)
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("corrupt golden profile for airframe %q: %w", airframe, err)
+	}
+	return profile, nil
+}
+
+// handleParamProfile uploads (POST/PUT ?airframe=X, body in JSON, .param or .params format),
+// returns (GET ?airframe=X) or lists (GET) golden parameter profiles
+func handleParamProfile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	airframe := r.URL.Query().Get("airframe")
+
+	switch r.Method {
+	case http.MethodGet:
+		if airframe == "" {
+			matches, _ := filepath.Glob(filepath.Join(profileDir, "*.json"))
+			names := make([]string, 0, len(matches))
+			for _, m := range matches {
+				names = append(names, strings.TrimSuffix(filepath.Base(m), ".json"))
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"profiles": names})
+			return
+		}
+		profile, err := loadParamProfile(airframe)
+		if err != nil {
+			writeError(w, http.StatusNotFound, ErrNotFound, err.Error())
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"airframe": airframe, "params": profile})
+
+	case http.MethodPost, http.MethodPut:
+		path, err := profilePath(airframe)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, err.Error())
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(r.Body, 4<<20))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Failed to read body: %v", err))
+			return
+		}
+		profile, err := parseParamProfile(data)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, ErrValidation, err.Error())
+			return
+		}
+		out, _ := json.MarshalIndent(profile, "", "  ")
+		if err := os.MkdirAll(profileDir, 0755); err == nil {
+			err = os.WriteFile(path, out, 0644)
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, ErrInternal, err.Error())
+			return
+		}
+		log.Printf("[WEB] Golden profile for airframe '%s' saved (%d params)", airframe, len(profile))
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Golden profile '%s' uploaded (%d params)", airframe, len(profile)))
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "airframe": airframe, "count": len(profile)})
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleParamDiff compares the live parameter cache against a golden profile
+// (GET ?airframe=X[&tolerance=0.0001])
+func handleParamDiff(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	airframe := r.URL.Query().Get("airframe")
+	if airframe == "" {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, "Missing 'airframe' parameter")
+		return
+	}
+	tolerance := 1e-4
+	if t := r.URL.Query().Get("tolerance"); t != "" {
+		v, err := strconv.ParseFloat(t, 64)
+		if err != nil || v < 0 {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, "Invalid 'tolerance' parameter")
+			return
+		}
+		tolerance = v
+	}
+
+	profile, err := loadParamProfile(airframe)
+	if err != nil {
+		writeError(w, http.StatusNotFound, ErrNotFound, err.Error())
+		return
+	}
+
+	report := ParamDiffReport{
+		Airframe:   airframe,
+		Deviations: []ParamDeviation{},
+		Missing:    []string{},
+	}
+	status := bridge.GetParameterListStatus(false)
+	report.Complete = !status.Loading && status.TotalCount > 0 && status.ReceivedCount >= status.TotalCount
+	if !report.Complete {
+		report.Message = "Live parameter cache is incomplete - request the parameter list first"
+	}
+
+	for name, expected := range profile {
+		report.Checked++
+		param, ok := bridge.GetCachedParameter(name)
+		if !ok {
+			report.Missing = append(report.Missing, name)
+			continue
+		}
+		// Relative tolerance for large values, absolute for values near zero
+		if math.Abs(param.ParamValue-expected) <= tolerance*math.Max(1, math.Abs(expected)) {
+			report.Matched++
+			continue
+		}
+		report.Deviations = append(report.Deviations, ParamDeviation{
+			ParamId:  name,
+			Expected: expected,
+			Actual:   param.ParamValue,
+		})
+	}
+	sort.Strings(report.Missing)
+	sort.Slice(report.Deviations, func(i, j int) bool { return report.Deviations[i].ParamId < report.Deviations[j].ParamId })
+
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,29 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/parseerrors"
+)
+
+// handleParseErrors serves MAVLink parse errors per channel with a diagnosis
+// (line noise vs dialect mismatch) and the most recent samples; DELETE clears them
+func handleParseErrors(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		parseerrors.Global.Reset()
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	channels, samples := parseerrors.Global.Snapshot()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"channels": channels,
+		"samples":  samples,
+	})
+}
@@ -0,0 +1,72 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/payload"
+)
+
+// payloadVehicleState collects the flight state payload interlocks are checked against
+func payloadVehicleState() payload.VehicleState {
+	st := payload.VehicleState{}
+	if bridge != nil {
+		st.Connected = bridge.IsConnected()
+		st.Armed = bridge.IsArmed()
+	}
+	if _, _, pos, _ := vehicleSnapshot(); pos != nil && time.Since(pos.Received) <= positionMaxAge {
+		st.HasPosition = true
+		st.AltRel = pos.AltRel
+	}
+	return st
+}
+
+// handlePayload serves GET /api/payload (all actions) and
+// POST /api/payload/<name> with {"command": "on"|"off"|"trigger"}
+func handlePayload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/payload"), "/")
+	if name == "" {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"actions": payload.Global.Snapshot(),
+			"vehicle": payloadVehicleState(),
+		})
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var req struct {
+		Command string `json:"command"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	if err := payload.Global.Command(name, req.Command, payloadVehicleState(), "web "+r.RemoteAddr); err != nil {
+		log.Printf("[WEB] Payload %s %s failed: %v", name, req.Command, err)
+		metrics.Global.AddLog("WARN", fmt.Sprintf("Payload %s %s failed: %v", name, req.Command, err))
+		writeError(w, http.StatusConflict, ErrControlDenied, err.Error())
+		return
+	}
+
+	metrics.Global.AddLog("INFO", fmt.Sprintf("Payload %s %s", name, req.Command))
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("%s %s", name, req.Command),
+	})
+}
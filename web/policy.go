@@ -0,0 +1,49 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/policy"
+	"DroneBridge/internal/transform"
+)
+
+// handleForwardingPolicy returns the active forwarding policy (GET) or switches it (PUT/POST)
+func handleForwardingPolicy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req struct {
+			Policy string `json:"policy"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		if err := policy.Global.SetActive(req.Policy); err != nil {
+			writeError(w, http.StatusBadRequest, ErrValidation, err.Error())
+			return
+		}
+		log.Printf("[WEB] Forwarding policy switched to '%s'", req.Policy)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Forwarding policy switched to '%s'", req.Policy))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"active":      policy.Global.Active(),
+		"policies":    policy.Global.List(),
+		"shaper":      policy.Shaper.Snapshot(),
+		"localParams": policy.LocalParams.Snapshot(),
+		"filters":     policy.Filters.Snapshot(),
+		"profiles":    policy.Profiles.Snapshot(),
+		"transforms":  transform.Global.Snapshot(),
+	})
+}
@@ -0,0 +1,48 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"DroneBridge/internal/audit"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/transform"
+)
+
+// handlePrivacy shows privacy mode (GET) or switches it (POST
+// {"enabled": true, "precisionMeters": 500}; precisionMeters is optional)
+func handlePrivacy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		var req struct {
+			Enabled         *bool    `json:"enabled"`
+			PrecisionMeters *float64 `json:"precisionMeters"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, `Invalid request body: expected {"enabled": true|false, "precisionMeters": 100}`)
+			return
+		}
+		precision := transform.Privacy.Status().PrecisionMeters
+		if req.PrecisionMeters != nil {
+			precision = *req.PrecisionMeters
+		}
+		if err := transform.Privacy.Set(*req.Enabled, precision); err != nil {
+			writeError(w, http.StatusBadRequest, ErrValidation, err.Error())
+			return
+		}
+		log.Printf("[WEB] Privacy mode set to %v (%gm) by %s", *req.Enabled, precision, r.RemoteAddr)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("Privacy mode enabled=%v precision=%gm", *req.Enabled, precision))
+		audit.Global.Record("privacy", "set", map[string]interface{}{"enabled": *req.Enabled, "precisionMeters": precision, "remote": r.RemoteAddr})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	json.NewEncoder(w).Encode(transform.Privacy.Status())
+}
@@ -0,0 +1,89 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"DroneBridge/internal/auth"
+	"DroneBridge/internal/watchdog"
+)
+
+// startupDone is set once main has brought up every enabled subsystem
+var startupDone atomic.Bool
+
+// SetReady marks startup as complete for the readiness probe
+func SetReady() {
+	startupDone.Store(true)
+}
+
+// handleHealthz is the liveness probe: it fails while a watched event loop is stalled
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	var stalled []string
+	for _, l := range watchdog.Global.Snapshot() {
+		if l.Stalled {
+			stalled = append(stalled, l.Name)
+		}
+	}
+	if len(stalled) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"alive":   len(stalled) == 0,
+		"stalled": stalled,
+	})
+}
+
+// handleReadyz returns the readiness probe. The bridge is ready once startup has
+// finished and, when auth is enabled, the cloud session is authenticated. The FC
+// link is reported but not required so a rebooting autopilot does not cycle the pod.
+func handleReadyz(authClient *auth.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		checks := map[string]bool{
+			"startup": startupDone.Load(),
+			"fc":      bridge != nil && bridge.IsConnected(),
+		}
+		ready := checks["startup"]
+		if authClient != nil {
+			checks["auth"] = authClient.IsAuthenticated()
+			ready = ready && checks["auth"]
+		}
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"ready":  ready,
+			"checks": checks,
+		})
+	}
+}
+
+// StartProbeServer serves only /healthz and /readyz, for deployments that run
+// without the web dashboard. authClient is nil when auth is disabled.
+func StartProbeServer(port int, authClient *auth.Client) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.HandleFunc("/readyz", handleReadyz(authClient))
+
+	server := &http.Server{
+		Addr:         fmt.Sprintf("0.0.0.0:%d", port),
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 5 * time.Second,
+	}
+	log.Printf("[WEB] Starting probe server on http://%s", server.Addr)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("[WEB] Probe server error: %v", err)
+		}
+	}()
+}
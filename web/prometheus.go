@@ -0,0 +1,132 @@
+package web
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"DroneBridge/internal/auth"
+	"DroneBridge/internal/camera"
+	"DroneBridge/internal/metrics"
+)
+
+// writeMetric writes one metric family with a single sample per label set
+func writeMetric(w io.Writer, name, typ, help string, samples map[string]float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	for labels, value := range samples {
+		fmt.Fprintf(w, "%s%s %s\n", name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+	}
+}
+
+// boolValue converts a state to a 0/1 gauge value
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// handlePrometheus serves metrics in the Prometheus text exposition format.
+// The auth gauge is left out when auth is disabled (authClient nil).
+func handlePrometheus(authClient *auth.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writeLinkMetrics(w, authClient)
+		writeEventMetrics(w)
+		writeCameraMetrics(w)
+	}
+}
+
+// writeLinkMetrics writes the forwarding counters and the FC and cloud session state
+func writeLinkMetrics(w io.Writer, authClient *auth.Client) {
+	sentCounts, failedCounts := metrics.Global.PacketCounts()
+	sent := make(map[string]float64, len(sentCounts))
+	for msgType, n := range sentCounts {
+		sent[fmt.Sprintf("{type=%q}", msgType)] = float64(n)
+	}
+	failed := make(map[string]float64, len(failedCounts))
+	for msgType, n := range failedCounts {
+		failed[fmt.Sprintf("{type=%q}", msgType)] = float64(n)
+	}
+	writeMetric(w, "dronebridge_messages_sent_total", "counter", "Messages forwarded to the router, per message type.", sent)
+	writeMetric(w, "dronebridge_messages_failed_total", "counter", "Messages that could not be forwarded to the router, per message type.", failed)
+
+	writeMetric(w, "dronebridge_pixhawk_connected", "gauge", "Whether the flight controller is connected.",
+		map[string]float64{"": boolValue(bridge != nil && bridge.IsConnected())})
+	if authClient != nil {
+		writeMetric(w, "dronebridge_auth_authenticated", "gauge", "Whether the cloud session is authenticated.",
+			map[string]float64{"": boolValue(authClient.IsAuthenticated())})
+	}
+	if expires := metrics.Global.SessionExpiry(); !expires.IsZero() {
+		writeMetric(w, "dronebridge_session_expiry_timestamp_seconds", "gauge", "Unix time the cloud session expires.",
+			map[string]float64{"": float64(expires.Unix())})
+		writeMetric(w, "dronebridge_session_expiry_seconds", "gauge", "Seconds until the cloud session expires (negative once expired).",
+			map[string]float64{"": time.Until(expires).Seconds()})
+	}
+}
+
+// writeEventMetrics writes the node event streams and how their consumer loops keep up
+func writeEventMetrics(w io.Writer) {
+	depth := map[string]float64{}
+	maxDepth := map[string]float64{}
+	capacity := map[string]float64{}
+	events := map[string]float64{}
+	backpressure := map[string]float64{}
+	blocked := map[string]float64{}
+	stalls := map[string]float64{}
+	lost := map[string]float64{}
+	for source, st := range metrics.Global.EventStages() {
+		labels := fmt.Sprintf("{source=%q,consumer=%q}", source, st.Consumer)
+		depth[labels] = float64(st.Depth)
+		maxDepth[labels] = float64(st.MaxDepth)
+		capacity[labels] = float64(st.Capacity)
+		events[labels] = float64(st.Events)
+		backpressure[labels] = float64(st.Backpressure)
+		blocked[labels] = st.BlockedMs / 1000
+		stalls[labels] = float64(st.Stalls)
+		lost[labels] = float64(st.Lost)
+	}
+
+	writeMetric(w, "dronebridge_event_queue_depth", "gauge", "Events waiting for the consumer loop.", depth)
+	writeMetric(w, "dronebridge_event_queue_max_depth", "gauge", "Highest event queue depth seen.", maxDepth)
+	writeMetric(w, "dronebridge_event_queue_capacity", "gauge", "Event queue size.", capacity)
+	writeMetric(w, "dronebridge_events_total", "counter", "Frames handled by the consumer loop.", events)
+	writeMetric(w, "dronebridge_event_backpressure_total", "counter", "Times the event queue was full and the node's reader blocked.", backpressure)
+	writeMetric(w, "dronebridge_event_blocked_seconds_total", "counter", "Time the node's reader was blocked on a full event queue.", blocked)
+	writeMetric(w, "dronebridge_event_stalls_total", "counter", "Events the consumer loop took more than 50ms to handle.", stalls)
+	writeMetric(w, "dronebridge_event_lost_frames_total", "counter", "Frames missing from the MAVLink sequence numbers.", lost)
+}
+
+// writeCameraMetrics writes the camera pipeline and encoder statistics
+func writeCameraMetrics(w io.Writer) {
+	fps := map[string]float64{}
+	bitrate := map[string]float64{}
+	keyframe := map[string]float64{}
+	frames := map[string]float64{}
+	dropped := map[string]float64{}
+	duplicated := map[string]float64{}
+	running := map[string]float64{}
+	for _, c := range camera.StatusAll() {
+		labels := fmt.Sprintf("{camera=\"%d\"}", c.ID)
+		fps[labels] = c.Stats.FPS
+		bitrate[labels] = c.Stats.BitrateKbps * 1000
+		keyframe[labels] = c.Stats.KeyframeInterval
+		frames[labels] = float64(c.Stats.Frames)
+		dropped[labels] = float64(c.Stats.DroppedFrames)
+		duplicated[labels] = float64(c.Stats.DuplicatedFrames)
+		running[labels] = 0
+		if c.Running {
+			running[labels] = 1
+		}
+	}
+
+	writeMetric(w, "dronebridge_camera_running", "gauge", "Whether the camera pipeline is running.", running)
+	writeMetric(w, "dronebridge_video_fps", "gauge", "Encoded frames per second.", fps)
+	writeMetric(w, "dronebridge_video_bitrate_bps", "gauge", "Encoded bitrate in bits per second.", bitrate)
+	writeMetric(w, "dronebridge_video_keyframe_interval_seconds", "gauge", "Average time between keyframes.", keyframe)
+	writeMetric(w, "dronebridge_video_frames_total", "counter", "Encoded frames since the pipeline started.", frames)
+	writeMetric(w, "dronebridge_video_dropped_frames_total", "counter", "Frames dropped before the encoder.", dropped)
+	writeMetric(w, "dronebridge_video_duplicated_frames_total", "counter", "Frames duplicated because the camera could not keep up.", duplicated)
+}
@@ -0,0 +1,31 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"DroneBridge/internal/rollup"
+)
+
+// handleStatsRollup serves daily/weekly statistics rollups (GET ?days=N, default 14)
+func handleStatsRollup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	days := 14
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, "Invalid days")
+			return
+		}
+		days = n
+	}
+	json.NewEncoder(w).Encode(rollup.Global.Snapshot(days))
+}
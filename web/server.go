@@ -1,5 +1,8 @@
 package web
 
+// Client SDKs in sdk/ are generated from the routes registered below
+//go:generate go run ../sdk/gen -web . -out ../sdk
+
 import (
 	"embed"
 	"encoding/json"
@@ -13,9 +16,18 @@ import (
 
 	"github.com/bluenviron/gomavlib/v3"
 	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+	"github.com/bluenviron/gomavlib/v3/pkg/message"
 
+	"DroneBridge/internal/apikeys"
 	"DroneBridge/internal/auth"
+	"DroneBridge/internal/deadman"
+	"DroneBridge/internal/echo"
+	"DroneBridge/internal/identity"
+	"DroneBridge/internal/maintenance"
+	"DroneBridge/internal/mavlink_custom"
 	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/policy"
+	"DroneBridge/internal/safety"
 )
 
 //go:embed static/*
@@ -34,17 +46,21 @@ type ParamSetRequest struct {
 
 // ParamSetResponse represents the response from setting a parameter
 type ParamSetResponse struct {
-	Success   bool    `json:"success"`
-	Message   string  `json:"message"`
-	ParamName string  `json:"paramName"`
-	NewValue  float64 `json:"newValue,omitempty"`
+	Success   bool      `json:"success"`
+	Code      ErrorCode `json:"code,omitempty"` // Set on failure
+	Message   string    `json:"message"`
+	ParamName string    `json:"paramName"`
+	NewValue  float64   `json:"newValue,omitempty"`
 }
 
 // ConnectionStatus represents the current connection state
 type ConnectionStatus struct {
-	Connected bool   `json:"connected"`
-	SystemID  uint8  `json:"systemId"`
-	Message   string `json:"message"`
+	Connected  bool    `json:"connected"`
+	SystemID   *uint8  `json:"systemId"` // Null while the FC is absent
+	Message    string  `json:"message"`
+	FlightMode string  `json:"flightMode,omitempty"` // Decoded from HEARTBEAT custom_mode, e.g. "LOITER"
+	CustomMode *uint32 `json:"customMode"`
+	FCAbsent   string  `json:"fcAbsent,omitempty"` // Why there is no FC data (see FCAbsentNoBridge)
 }
 
 // CachedParameter represents a parameter with its current value from Pixhawk
@@ -53,6 +69,8 @@ type CachedParameter struct {
 	ParamValue float64 `json:"paramValue"`
 	ParamType  int     `json:"paramType"`
 	ParamIndex uint16  `json:"paramIndex"`
+
+	updated time.Time // When the last PARAM_VALUE for this parameter arrived
 }
 
 // ParameterListStatus represents the status of parameter loading
@@ -63,6 +81,7 @@ type ParameterListStatus struct {
 	Progress      float64           `json:"progress"`
 	Parameters    []CachedParameter `json:"parameters,omitempty"`
 	LastUpdated   string            `json:"lastUpdated,omitempty"`
+	FCAbsent      string            `json:"fcAbsent,omitempty"` // Why no parameters can be loaded
 }
 
 // MAVLinkBridge handles MAVLink communication for parameter setting
@@ -70,6 +89,7 @@ type MAVLinkBridge struct {
 	node            *gomavlib.Node
 	pixhawkSysID    uint8
 	connected       bool
+	armed           bool // From HEARTBEAT base_mode
 	mutex           sync.RWMutex
 	responseTimeout time.Duration
 
@@ -83,6 +103,9 @@ type MAVLinkBridge struct {
 
 	// Channel to receive PARAM_VALUE messages from forwarder
 	paramValueCh chan *common.MessageParamValue
+
+	// Writes to the FC through the forwarder (follows ethernet hotplug); nil = node only
+	fcWriter func(message.Message) error
 }
 
 var bridge *MAVLinkBridge
@@ -101,6 +124,16 @@ func InitMAVLinkBridge(node *gomavlib.Node) {
 	})
 }
 
+// SetFCWriter routes API-originated messages through the forwarder, so they
+// also reach an FC whose ethernet link came up after startup
+func SetFCWriter(write func(message.Message) error) {
+	if bridge != nil {
+		bridge.mutex.Lock()
+		bridge.fcWriter = write
+		bridge.mutex.Unlock()
+	}
+}
+
 // HandleParamValue receives PARAM_VALUE message from forwarder
 func HandleParamValue(msg *common.MessageParamValue) {
 	if bridge != nil && bridge.paramValueCh != nil {
@@ -125,6 +158,25 @@ func HandleHeartbeat(sysID uint8) {
 	}
 }
 
+// HandleArmedState receives the armed flag from Pixhawk heartbeats
+func HandleArmedState(armed bool) {
+	if bridge != nil {
+		bridge.mutex.Lock()
+		bridge.armed = armed
+		bridge.mutex.Unlock()
+	}
+}
+
+// IsArmed reports whether the vehicle was armed in its last heartbeat
+func (b *MAVLinkBridge) IsArmed() bool {
+	if b == nil {
+		return false
+	}
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.armed
+}
+
 func (b *MAVLinkBridge) processParamValues() {
 	for msg := range b.paramValueCh {
 		// Decode value based on type
@@ -147,6 +199,7 @@ func (b *MAVLinkBridge) processParamValues() {
 			ParamValue: decodedValue,
 			ParamType:  int(msg.ParamType),
 			ParamIndex: msg.ParamIndex,
+			updated:    time.Now(),
 		}
 
 		b.paramTotal = int(msg.ParamCount)
@@ -163,6 +216,34 @@ func (b *MAVLinkBridge) processParamValues() {
 	}
 }
 
+// writeToFC sends a dashboard/API-originated message to the Pixhawk, counted in the web_to_fc band
+func (b *MAVLinkBridge) writeToFC(msg message.Message) error {
+	policy.LocalParams.LocalRequest(msg.GetID()) // Before writing: the FC may answer immediately
+	echo.Global.Record(msg)
+	b.mutex.RLock()
+	write := b.fcWriter
+	b.mutex.RUnlock()
+	if write == nil {
+		write = b.node.WriteMessageAll
+	}
+	if err := write(msg); err != nil {
+		return err
+	}
+	metrics.Global.AddBand(metrics.BandWebToFC, mavlink_custom.MessageSize(msg))
+	return nil
+}
+
+// SendStatusText shows a message on the GCS via the FC (STATUSTEXT, truncated to 50 characters)
+func SendStatusText(severity common.MAV_SEVERITY, text string) error {
+	if bridge == nil {
+		return errNotInitialized
+	}
+	if len(text) > 50 {
+		text = text[:50]
+	}
+	return bridge.writeToFC(&common.MessageStatustext{Severity: severity, Text: text})
+}
+
 func (b *MAVLinkBridge) IsConnected() bool {
 	if b == nil {
 		return false
@@ -202,7 +283,7 @@ func GetPixhawkSystemID() uint8 {
 // RequestParameterList sends PARAM_REQUEST_LIST to Pixhawk
 func (b *MAVLinkBridge) RequestParameterList() error {
 	if b == nil || b.node == nil {
-		return fmt.Errorf("MAVLink bridge not initialized")
+		return errNotInitialized
 	}
 
 	b.mutex.RLock()
@@ -211,7 +292,7 @@ func (b *MAVLinkBridge) RequestParameterList() error {
 	b.mutex.RUnlock()
 
 	if !connected {
-		return fmt.Errorf("not connected to Pixhawk")
+		return errNotConnected
 	}
 
 	// Clear cache and start loading
@@ -230,7 +311,7 @@ func (b *MAVLinkBridge) RequestParameterList() error {
 
 	log.Printf("[WEB] Sending PARAM_REQUEST_LIST to system %d", sysID)
 
-	err := b.node.WriteMessageAll(msg)
+	err := b.writeToFC(msg)
 	if err != nil {
 		b.paramCacheMutex.Lock()
 		b.paramLoading = false
@@ -291,11 +372,21 @@ func (b *MAVLinkBridge) SetParameter(paramName string, paramValue float64, param
 	if b == nil || b.node == nil {
 		return &ParamSetResponse{
 			Success:   false,
+			Code:      ErrBridgeNotReady,
 			Message:   "MAVLink bridge not initialized",
 			ParamName: paramName,
 		}
 	}
 
+	if err := safety.Global.CheckParamSet(paramName, safety.SourceWeb); err != nil {
+		return &ParamSetResponse{
+			Success:   false,
+			Code:      ErrParamFrozen,
+			Message:   err.Error(),
+			ParamName: paramName,
+		}
+	}
+
 	b.mutex.RLock()
 	connected := b.connected
 	sysID := b.pixhawkSysID
@@ -304,6 +395,7 @@ func (b *MAVLinkBridge) SetParameter(paramName string, paramValue float64, param
 	if !connected {
 		return &ParamSetResponse{
 			Success:   false,
+			Code:      ErrFCNotConnected,
 			Message:   "Not connected to Pixhawk",
 			ParamName: paramName,
 		}
@@ -333,10 +425,11 @@ func (b *MAVLinkBridge) SetParameter(paramName string, paramValue float64, param
 
 	log.Printf("[WEB] Sending PARAM_SET: %s = %v (type: %s)", paramName, paramValue, paramType)
 
-	err := b.node.WriteMessageAll(paramMsg)
+	err := b.writeToFC(paramMsg)
 	if err != nil {
 		return &ParamSetResponse{
 			Success:   false,
+			Code:      ErrParamFailed,
 			Message:   fmt.Sprintf("Failed to send PARAM_SET: %v", err),
 			ParamName: paramName,
 		}
@@ -360,11 +453,10 @@ func (b *MAVLinkBridge) waitForParamResponse(paramName string) *ParamSetResponse
 		case <-ticker.C:
 			b.paramCacheMutex.RLock()
 			param, exists := b.paramCache[paramName]
-			lastUpdate := b.paramLastUpdate
 			b.paramCacheMutex.RUnlock()
 
-			// Check if we got an update after sending the request
-			if exists && lastUpdate.After(startTime) {
+			// Check if we got a PARAM_VALUE for this parameter after sending the request
+			if exists && param.updated.After(startTime) {
 				log.Printf("[WEB] PARAM_VALUE received: %s = %v", paramName, param.ParamValue)
 				return &ParamSetResponse{
 					Success:   true,
@@ -377,6 +469,7 @@ func (b *MAVLinkBridge) waitForParamResponse(paramName string) *ParamSetResponse
 		case <-timeout:
 			return &ParamSetResponse{
 				Success:   false,
+				Code:      ErrParamTimeout,
 				Message:   "Timeout waiting for parameter confirmation",
 				ParamName: paramName,
 			}
@@ -452,28 +545,32 @@ func StartServer(port int, authClient *auth.Client, droneUUID string) {
 	http.HandleFunc("/api/status", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Cache-Control", "no-cache")
-		json.NewEncoder(w).Encode(metrics.Global.GetSnapshot())
+		snapshot := metrics.Global.GetSnapshot()
+		snapshot["fc_connected"] = bridge.IsConnected()
+		if reason := fcAbsent(); reason != "" {
+			snapshot["fc_absent"] = reason
+		}
+		json.NewEncoder(w).Encode(snapshot)
 	})
 
+	// API endpoint for the dashboard summary (FC section null without a Pixhawk)
+	http.HandleFunc("/api/dashboard", handleDashboard)
+
 	// API endpoint for connection status
 	http.HandleFunc("/api/connection", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Cache-Control", "no-cache")
 
-		status := ConnectionStatus{
-			Connected: false,
-			SystemID:  0,
-			Message:   "MAVLink bridge not initialized",
-		}
-
-		if bridge != nil {
-			status.Connected = bridge.IsConnected()
-			status.SystemID = bridge.GetSystemID()
-			if status.Connected {
-				status.Message = fmt.Sprintf("Connected to Pixhawk (System ID: %d)", status.SystemID)
-			} else {
-				status.Message = "Waiting for Pixhawk connection..."
-			}
+		status := ConnectionStatus{FCAbsent: fcAbsent()}
+		if status.FCAbsent != "" {
+			status.Message = fcAbsentMessage(status.FCAbsent)
+		} else {
+			sysID := bridge.GetSystemID()
+			mode, customMode := currentFlightMode()
+			status.Connected = true
+			status.SystemID = &sysID
+			status.Message = fmt.Sprintf("Connected to Pixhawk (System ID: %d)", sysID)
+			status.FlightMode, status.CustomMode = mode, &customMode
 		}
 
 		json.NewEncoder(w).Encode(status)
@@ -485,13 +582,13 @@ func StartServer(port int, authClient *auth.Client, droneUUID string) {
 		w.Header().Set("Cache-Control", "no-cache")
 
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
 			return
 		}
 
 		var req ParamSetRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
 			return
 		}
 
@@ -503,6 +600,7 @@ func StartServer(port int, authClient *auth.Client, droneUUID string) {
 		} else {
 			response = &ParamSetResponse{
 				Success:   false,
+				Code:      ErrBridgeNotReady,
 				Message:   "MAVLink bridge not initialized",
 				ParamName: req.ParamName,
 			}
@@ -511,37 +609,198 @@ func StartServer(port int, authClient *auth.Client, droneUUID string) {
 		json.NewEncoder(w).Encode(response)
 	})
 
+	// API endpoint to set several parameters with optional rollback
+	http.HandleFunc("/api/param/set-batch", handleParamSetBatch)
+
+	// API endpoint for guided calibration workflows
+	http.HandleFunc("/api/calibration", handleCalibration)
+
+	// API endpoint to upload and flash FC firmware
+	http.HandleFunc("/api/firmware", handleFirmware)
+
+	// API endpoints for the workspace file manager (bearer token required)
+	http.HandleFunc("/api/files", handleFiles)
+	http.HandleFunc("/api/files/download", handleFileDownload)
+	http.HandleFunc("/api/files/upload", handleFileUpload)
+
+	// API endpoints to issue, list and revoke role-scoped API tokens (admin token required)
+	http.HandleFunc("/api/tokens", handleTokens)
+	http.HandleFunc("/api/tokens/", handleTokens)
+
+	// API endpoint for click-to-fly in GUIDED mode
+	http.HandleFunc("/api/guided/goto", handleGuidedGoto)
+
+	// API endpoints for orbit / point of interest and the command ACK history
+	http.HandleFunc("/api/guided/orbit", handleOrbit)
+	http.HandleFunc("/api/guided/roi", handleROI)
+	http.HandleFunc("/api/commands", handleCommands)
+
+	// One-click flight actions (mode change + command with ACK verification)
+	http.HandleFunc("/api/flight/takeoff", handleFlight("takeoff"))
+	http.HandleFunc("/api/flight/land", handleFlight("land"))
+	http.HandleFunc("/api/flight/rtl", handleFlight("rtl"))
+
+	// Two-operator emergency stop (force-disarm / flight termination)
+	http.HandleFunc("/api/emergency/stop", handleEmergencyStop)
+
+	// API endpoints for companion payload outputs (GPIO/PWM)
+	http.HandleFunc("/api/payload", handlePayload)
+	http.HandleFunc("/api/payload/", handlePayload)
+
 	// API endpoint for health check
 	http.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Cache-Control", "no-cache")
-		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+		id := identity.Global.Get()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":          "ok",
+			"uuid":            id.UUID,
+			"name":            id.Name,
+			"airframe":        id.Airframe,
+			"tailNumber":      id.TailNumber,
+			"maintenanceDue":  maintenance.Global.Due(),
+			"maintenanceMode": maintenance.Mode.Active(),
+			"deadman":         deadman.Global.Snapshot(),
+			"paramFreeze":     safety.Global.Snapshot(),
+		})
 	})
 
+	// API endpoint for drone name metadata
+	http.HandleFunc("/api/identity", handleIdentity)
+
+	// API endpoint for the pinned FC (GET) and re-pairing (POST)
+	http.HandleFunc("/api/fc/pin", handleFCPin)
+
+	// API endpoint for MAVLink 2 signing state (key fingerprint, counters)
+	http.HandleFunc("/api/signing", handleSigning)
+
+	// API endpoint for recent session and API key events (also pushed over /ws/mavlink?events=1)
+	http.HandleFunc("/api/events", handleEvents)
+
+	// API endpoint for the signed feature list (premium subsystems usable on this drone)
+	http.HandleFunc("/api/entitlements", handleEntitlements)
+
+	// Liveness and readiness probes for container orchestrators
+	http.HandleFunc("/healthz", handleHealthz)
+	http.HandleFunc("/readyz", handleReadyz(authClient))
+
+	// API endpoint for camera pipelines and encoder statistics
+	http.HandleFunc("/api/camera/status", handleCameraStatus)
+
+	// Prometheus scrape endpoint (forwarding counters, FC/session state, cameras)
+	http.HandleFunc("/metrics", handlePrometheus(authClient))
+
+	// API endpoint for decoded flight controller health
+	http.HandleFunc("/api/health/fc", handleFCHealth)
+
+	// Vehicle class, class-specific telemetry and preflight checks
+	http.HandleFunc("/api/vehicle", handleVehicle)
+
+	// API endpoint for the latest decoded instance of any FC message type (?msg=GLOBAL_POSITION_INT)
+	http.HandleFunc("/api/telemetry/latest", handleTelemetryLatest)
+
+	// API endpoint listing the message types available from /api/telemetry/latest
+	http.HandleFunc("/api/telemetry/types", handleTelemetryTypes)
+
+	// API endpoint for the startup report (effective config and sanity warnings)
+	http.HandleFunc("/api/startup-report", handleStartupReport)
+
+	// API endpoint for runtime log levels (global, per subsystem, temporary debug)
+	http.HandleFunc("/api/log/level", handleLogLevel)
+
+	// DEBUG: link simulation (latency/jitter/loss, forced IP change) - needs --chaos
+	http.HandleFunc("/api/debug/chaos", handleChaos)
+	http.HandleFunc("/api/debug/chaos/ip-change", handleChaosIPChange)
+	// DEBUG: soak test progress - needs --soak
+	http.HandleFunc("/api/debug/soak", handleSoak)
+
+	// API endpoint for DroneCAN nodes (ESCs, GPS) reported by the FC
+	http.HandleFunc("/api/can/nodes", handleCANNodes)
+
+	// API endpoint for nearby ADS-B traffic
+	http.HandleFunc("/api/traffic", handleTraffic)
+
+	// API endpoint for nearby bridges on the local mesh
+	http.HandleFunc("/api/peers", handlePeers)
+
+	// API endpoint for the LAN discovery beacon (GET state, POST toggle)
+	http.HandleFunc("/api/discovery", handleDiscovery)
+
+	// API endpoint for external I2C/serial sensors
+	http.HandleFunc("/api/sensors", handleSensors)
+
+	// API endpoints for viewer sessions and the audit log
+	http.HandleFunc("/api/viewers", handleViewers)
+	http.HandleFunc("/api/audit", handleAudit)
+
+	// API endpoint to view/switch the forwarding policy
+	http.HandleFunc("/api/forwarding/policy", handleForwardingPolicy)
+
+	// API endpoint to view/toggle privacy mode (position fuzzing for the cloud)
+	http.HandleFunc("/api/privacy", handlePrivacy)
+
+	// API endpoint for per-endpoint channel statistics
+	http.HandleFunc("/api/channels", handleChannels)
+
+	// MAVLink parse errors per channel with recent samples (serial noise vs dialect mismatch)
+	http.HandleFunc("/api/stats/parse-errors", handleParseErrors)
+
+	// Daily/weekly statistics rollups and maintenance hours
+	http.HandleFunc("/api/stats/rollup", handleStatsRollup)
+
+	// Service counters, maintenance log and reminders
+	http.HandleFunc("/api/maintenance", handleMaintenance)
+	// Enter time-limited maintenance mode: cloud forwarding paused, router told, dead-man suspended
+	http.HandleFunc("/api/maintenance/enter", handleMaintenanceEnter)
+	// Leave maintenance mode before its timeout
+	http.HandleFunc("/api/maintenance/exit", handleMaintenanceExit)
+	// Maintenance mode state and remaining time
+	http.HandleFunc("/api/maintenance/mode", handleMaintenanceMode)
+
+	// Battery packs, cycles and sag history
+	http.HandleFunc("/api/batteries", handleBatteries)
+
+	// API endpoint for disk-space guard status
+	http.HandleFunc("/api/storage", handleStorage)
+	// Upload status of tlogs, video segments and diagnostics bundles
+	http.HandleFunc("/api/uploads", handleUploads)
+
+	// Time-windowed flight data export (CSV/JSON) from the flight journal
+	http.HandleFunc("/api/export", handleExport)
+
+	// Map tile cache for offline maps, its status and area pre-seeding
+	http.HandleFunc("/api/tiles/status", handleTilesStatus)
+	http.HandleFunc("/api/tiles/seed", handleTilesSeed)
+	http.HandleFunc("/api/tiles/", handleTile)
+
+	// API endpoint for event loop watchdog status
+	http.HandleFunc("/api/watchdog", handleWatchdog)
+
+	// API endpoint to view control arbitration or take over control
+	http.HandleFunc("/api/control", handleControl)
+	http.HandleFunc("/api/control/remote-piloting", handleRemotePiloting)
+
+	// API endpoint for scheduled tasks (next runs, history, run now)
+	http.HandleFunc("/api/schedule", handleSchedule)
+
 	// API endpoint to request parameter list from Pixhawk
 	http.HandleFunc("/api/param/request-list", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Cache-Control", "no-cache")
 
 		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
 			return
 		}
 
 		if bridge == nil {
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": "MAVLink bridge not initialized",
-			})
+			writeError(w, http.StatusServiceUnavailable, ErrBridgeNotReady, "MAVLink bridge not initialized")
 			return
 		}
 
 		err := bridge.RequestParameterList()
 		if err != nil {
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"success": false,
-				"message": err.Error(),
-			})
+			writeError(w, http.StatusServiceUnavailable, errorCode(err, ErrUnavailable), err.Error())
 			return
 		}
 
@@ -559,11 +818,12 @@ func StartServer(port int, authClient *auth.Client, droneUUID string) {
 		includeParams := r.URL.Query().Get("include") == "params"
 
 		if bridge == nil {
-			json.NewEncoder(w).Encode(&ParameterListStatus{Loading: false})
+			json.NewEncoder(w).Encode(&ParameterListStatus{Loading: false, FCAbsent: FCAbsentNoBridge})
 			return
 		}
 
 		status := bridge.GetParameterListStatus(includeParams)
+		status.FCAbsent = fcAbsent()
 		json.NewEncoder(w).Encode(status)
 	})
 
@@ -581,6 +841,10 @@ func StartServer(port int, authClient *auth.Client, droneUUID string) {
 		json.NewEncoder(w).Encode(status.Parameters)
 	})
 
+	// API endpoints for golden airframe profiles and comparing live parameters against them
+	http.HandleFunc("/api/param/profile", handleParamProfile)
+	http.HandleFunc("/api/param/diff", handleParamDiff)
+
 	// API endpoint to get a single cached parameter
 	http.HandleFunc("/api/param/get", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -588,7 +852,7 @@ func StartServer(port int, authClient *auth.Client, droneUUID string) {
 
 		paramName := r.URL.Query().Get("name")
 		if paramName == "" {
-			http.Error(w, "Missing 'name' parameter", http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, ErrBadRequest, "Missing 'name' parameter")
 			return
 		}
 
@@ -613,220 +877,28 @@ func StartServer(port int, authClient *auth.Client, droneUUID string) {
 		})
 	})
 
-	// Helper function to set CORS headers
-	setCORSHeaders := func(w http.ResponseWriter) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "*")
-	}
-
 	// API Key Management Endpoints (compatible with HBQCONNECT format)
+	// Requests are queued until the auth session is ready and answered with 202 + poll URL
 	// GET /api/v1/drone/api-key/status - Get current API key status
-	http.HandleFunc("/api/v1/drone/api-key/status", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Cache-Control", "no-cache")
-		setCORSHeaders(w)
-
-		if r.Method == http.MethodOptions {
-			return
-		}
-
-		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
-		if authClient == nil {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error": "Auth client not initialized",
-			})
-			return
-		}
-
-		// Try to get API key status with retries
-		var state *auth.APIKeyStatusResponse
-		var err error
-		maxRetries := 3
-		retryDelay := 500 * time.Millisecond
-
-		for attempt := 0; attempt < maxRetries; attempt++ {
-			state, err = authClient.GetAPIKeyStatus()
-			if err == nil {
-				break
-			}
-			if attempt < maxRetries-1 {
-				time.Sleep(retryDelay)
-			}
-		}
-
-		if err != nil {
-			// Return a "no key" response instead of error if session is not ready
-			// This allows frontend to gracefully show "no key" state
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"has_active_key": false,
-				"status":         "none",
-				"api_key":        nil,
-				"error":          err.Error(),
-			})
-			return
-		}
-
-		// Convert response to frontend format
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"has_active_key": state.HasActiveKey == 0x01,
-			"status":         state.Status,
-			"api_key":        state.APIKey,
-			"created_at":     formatUnixTimestamp(state.CreatedAt),
-			"expires_at":     formatUnixTimestamp(state.ExpiresAt),
-			"user_uuid":      state.UserUUID,
-			"username":       nil, // TODO: Fetch username from backend DB if needed
-			"user_active_at": formatUnixTimestamp(state.UserActivatedAt),
-		})
-	})
-
+	http.HandleFunc("/api/v1/drone/api-key/status", handleAPIKeyOp(apikeys.OpStatus, http.MethodGet))
 	// POST /api/v1/drone/api-key/request - Request new API key
-	http.HandleFunc("/api/v1/drone/api-key/request", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Cache-Control", "no-cache")
-		setCORSHeaders(w)
-
-		if r.Method == http.MethodOptions {
-			return
-		}
-
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
-		if authClient == nil {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error": "Auth client not initialized",
-			})
-			return
-		}
-
-		// Parse request body for expiration hours (optional)
-		var req map[string]interface{}
-		json.NewDecoder(r.Body).Decode(&req)
-
-		expirationHours := 24 // Default
-		if exp, ok := req["expiration_hours"]; ok {
-			if v, ok := exp.(float64); ok {
-				expirationHours = int(v)
-			}
-		}
-
-		// Validate expiration range
-		if expirationHours < 1 {
-			expirationHours = 1
-		}
-		if expirationHours > 720 { // Max 30 days
-			expirationHours = 720
-		}
-
-		state, err := authClient.RequestAPIKey(expirationHours)
-		if err != nil {
-			if err.Error() == "drone already has an active API key" {
-				w.WriteHeader(http.StatusConflict)
-			} else {
-				w.WriteHeader(http.StatusInternalServerError)
-			}
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error": err.Error(),
-			})
-			return
-		}
-
-		// Convert response to frontend format
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"api_key":        state.APIKey,
-			"created_at":     time.Now().Format(time.RFC3339),
-			"expires_at":     formatUnixTimestamp(state.ExpiresAt),
-			"user_uuid":      nil,
-			"username":       nil,
-			"user_active_at": nil,
-		})
-	})
-
+	http.HandleFunc("/api/v1/drone/api-key/request", handleAPIKeyOp(apikeys.OpRequest, http.MethodPost))
 	// DELETE /api/v1/drone/api-key/revoke - Revoke current API key
-	http.HandleFunc("/api/v1/drone/api-key/revoke", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Cache-Control", "no-cache")
-		setCORSHeaders(w)
-
-		if r.Method == http.MethodOptions {
-			return
-		}
-
-		if r.Method != http.MethodDelete {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
-		if authClient == nil {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error": "Auth client not initialized",
-			})
-			return
-		}
-
-		if err := authClient.RevokeAPIKey(); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error": err.Error(),
-			})
-			return
-		}
-
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": "API key revoked successfully",
-		})
-	})
-
+	http.HandleFunc("/api/v1/drone/api-key/revoke", handleAPIKeyOp(apikeys.OpRevoke, http.MethodDelete))
 	// DELETE /api/v1/drone/api-key/delete - Delete API key completely
-	http.HandleFunc("/api/v1/drone/api-key/delete", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Cache-Control", "no-cache")
-		setCORSHeaders(w)
-
-		if r.Method == http.MethodOptions {
-			return
-		}
-
-		if r.Method != http.MethodDelete {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
+	http.HandleFunc("/api/v1/drone/api-key/delete", handleAPIKeyOp(apikeys.OpDelete, http.MethodDelete))
+	// GET /api/v1/drone/api-key/jobs/<id> - Poll a queued API key operation
+	http.HandleFunc(apiKeyJobsPath, handleAPIKeyJob)
+	// GET /api/v1/drone/api-key/events - Stream finished API key operations
+	http.HandleFunc(apiKeyEventsPath, handleAPIKeyEvents)
 
-		if authClient == nil {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error": "Auth client not initialized",
-			})
-			return
-		}
-
-		if err := authClient.DeleteAPIKey(); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]interface{}{
-				"error": err.Error(),
-			})
-			return
-		}
-
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"message": "API key deleted successfully",
-		})
-	})
+	// GET /ws/mavlink - Raw MAVLink v2 frames over WebSocket for browser GCS clients
+	http.HandleFunc("/ws/mavlink", handleMAVLinkWS)
 
 	// Create HTTP server with optimized settings
 	server := &http.Server{
 		Addr:           fmt.Sprintf("0.0.0.0:%d", port),
-		Handler:        http.DefaultServeMux,
+		Handler:        tokenGuard(http.DefaultServeMux),
 		ReadTimeout:    15 * time.Second,
 		WriteTimeout:   15 * time.Second,
 		IdleTimeout:    60 * time.Second,
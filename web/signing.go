@@ -0,0 +1,21 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/signing"
+)
+
+// handleSigning shows the MAVLink 2 signing state: whether a key is present,
+// its fingerprint (compare with the router) and how many frames were signed/rejected
+func handleSigning(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	json.NewEncoder(w).Encode(signing.Global.Status())
+}
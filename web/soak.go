@@ -0,0 +1,20 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/soak"
+)
+
+// handleSoak serves the progress of a --soak run: samples, baseline, peaks and
+// the failure reason once a leak threshold is exceeded
+func handleSoak(w http.ResponseWriter, r *http.Request) {
+	if !soak.Global.Active() {
+		writeError(w, http.StatusNotFound, ErrFeatureDisabled, "No soak test running (start with --soak <duration>)")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(soak.Global.Snapshot())
+}
@@ -0,0 +1,22 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/startup"
+)
+
+// handleStartupReport serves the startup summary: effective config, interfaces,
+// endpoints, secret presence, cameras and sanity warnings
+func handleStartupReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	report := startup.Current()
+	if report == nil {
+		writeError(w, http.StatusServiceUnavailable, ErrUnavailable, "startup not complete")
+		return
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,27 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/storage"
+	"DroneBridge/internal/upload"
+)
+
+// handleStorage serves free space, per-class usage and pruning totals
+func handleStorage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(storage.Global.Snapshot())
+}
+
+// handleUploads serves the uploader status and the upload state of every artifact
+// of the uploaded classes
+func handleUploads(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"uploader":  upload.Global.Snapshot(),
+		"artifacts": storage.Global.Artifacts(upload.Global.Classes(), 0),
+	})
+}
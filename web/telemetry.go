@@ -0,0 +1,167 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+
+	"DroneBridge/internal/health"
+)
+
+// hudState is the last VFR_HUD from the FC
+type hudState struct {
+	airspeed    float32
+	groundspeed float32
+	heading     int16
+	throttle    uint16
+	alt         float32
+	climb       float32
+	received    time.Time
+}
+
+// terrainState is the last TERRAIN_REPORT from the FC
+type terrainState struct {
+	terrainHeight float32 // Terrain AMSL under the vehicle
+	currentHeight float32 // Vehicle height above terrain
+	received      time.Time
+}
+
+var (
+	telemetryMu sync.RWMutex
+	hud         *hudState
+	terrain     *terrainState
+)
+
+// HandleVFRHud receives VFR_HUD from the forwarder
+func HandleVFRHud(m *common.MessageVfrHud) {
+	telemetryMu.Lock()
+	defer telemetryMu.Unlock()
+	hud = &hudState{
+		airspeed:    m.Airspeed,
+		groundspeed: m.Groundspeed,
+		heading:     m.Heading,
+		throttle:    m.Throttle,
+		alt:         m.Alt,
+		climb:       m.Climb,
+		received:    time.Now(),
+	}
+}
+
+// HandleTerrainReport receives TERRAIN_REPORT from the forwarder
+func HandleTerrainReport(m *common.MessageTerrainReport) {
+	telemetryMu.Lock()
+	defer telemetryMu.Unlock()
+	terrain = &terrainState{
+		terrainHeight: m.TerrainHeight,
+		currentHeight: m.CurrentHeight,
+		received:      time.Now(),
+	}
+}
+
+// vehicleClass maps the HEARTBEAT MAV_TYPE to the class that decides telemetry
+// composition and preflight checks ("" until a vehicle heartbeat arrived)
+func vehicleClass(typ common.MAV_TYPE) string {
+	switch {
+	case isFixedWing(typ):
+		return health.ClassPlane
+	case typ == common.MAV_TYPE_GROUND_ROVER || typ == common.MAV_TYPE_SURFACE_BOAT:
+		return health.ClassRover
+	case typ == common.MAV_TYPE_SUBMARINE:
+		return health.ClassSub
+	}
+	switch typ {
+	case common.MAV_TYPE_QUADROTOR, common.MAV_TYPE_HEXAROTOR, common.MAV_TYPE_OCTOROTOR,
+		common.MAV_TYPE_TRICOPTER, common.MAV_TYPE_HELICOPTER, common.MAV_TYPE_COAXIAL,
+		common.MAV_TYPE_DECAROTOR, common.MAV_TYPE_DODECAROTOR:
+		return health.ClassCopter
+	}
+	return ""
+}
+
+// vehicleTelemetry returns the telemetry fields that make sense for the vehicle class:
+// airspeed for planes, height above terrain for copters, depth for subs and no
+// altitude at all for surface vehicles
+func vehicleTelemetry(class string, pos *VehiclePosition) map[string]interface{} {
+	telemetryMu.RLock()
+	defer telemetryMu.RUnlock()
+
+	t := map[string]interface{}{}
+	if pos != nil {
+		t["lat"] = pos.Lat
+		t["lon"] = pos.Lon
+		if class != health.ClassRover && class != health.ClassSub {
+			t["altRel"] = pos.AltRel
+			t["altMsl"] = pos.AltMSL
+		}
+	}
+	if hud != nil {
+		t["groundspeed"] = hud.groundspeed
+		t["heading"] = hud.heading
+		t["throttle"] = hud.throttle
+		switch class {
+		case health.ClassPlane:
+			t["airspeed"] = hud.airspeed
+			t["climb"] = hud.climb
+		case health.ClassSub:
+			t["depth"] = -hud.alt // ArduSub reports depth as negative altitude
+			t["climb"] = hud.climb
+		case health.ClassRover:
+		default:
+			t["climb"] = hud.climb
+		}
+	}
+	if terrain != nil && class == health.ClassCopter {
+		t["terrainAlt"] = terrain.currentHeight
+		t["terrainHeight"] = terrain.terrainHeight
+	}
+	return t
+}
+
+// bridgePreflight returns the bridge-side preflight checks for the current vehicle
+func bridgePreflight() (string, []health.PreflightCheck) {
+	typ, _, _, _ := vehicleSnapshot()
+	class := vehicleClass(typ)
+	return class, health.Global.Preflight(class)
+}
+
+// handleVehicle serves the vehicle class, decoded mode, class-specific telemetry
+// and preflight checks, so dashboards need no per-type logic
+func handleVehicle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	typ, _, pos, _ := vehicleSnapshot()
+	class, checks := bridgePreflight()
+	mode, customMode := currentFlightMode()
+
+	ready := true
+	for _, c := range checks {
+		ready = ready && c.Passed
+	}
+
+	resp := map[string]interface{}{
+		"connected":  bridge.IsConnected(),
+		"armed":      bridge.IsArmed(),
+		"class":      class,
+		"mavType":    typ.String(),
+		"flightMode": mode,
+		"customMode": customMode,
+		"telemetry":  vehicleTelemetry(class, pos),
+		"preflight": map[string]interface{}{
+			"ready":  ready,
+			"checks": checks,
+		},
+	}
+	// Without an FC the vehicle fields are unknown, not zero
+	if reason := fcAbsent(); reason != "" {
+		for _, k := range []string{"armed", "class", "mavType", "flightMode", "customMode", "telemetry"} {
+			resp[k] = nil
+		}
+		resp["fcAbsent"] = reason
+		resp["fcAbsentMessage"] = fcAbsentMessage(reason)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
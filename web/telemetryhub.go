@@ -0,0 +1,44 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/telemetry"
+)
+
+// handleTelemetryLatest returns the most recent decoded instance of a message
+// type as a field map (GET /api/telemetry/latest?msg=GLOBAL_POSITION_INT).
+// msg is the MAVLink name or numeric ID; see /api/telemetry/types for what is cached.
+func handleTelemetryLatest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	name := r.URL.Query().Get("msg")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, "msg is required (e.g. ?msg=GLOBAL_POSITION_INT)")
+		return
+	}
+	snap, ok := telemetry.Global.Latest(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, ErrNotFound, "No "+name+" received from the flight controller")
+		return
+	}
+	json.NewEncoder(w).Encode(snap)
+}
+
+// handleTelemetryTypes lists the message types cached by the telemetry hub
+func handleTelemetryTypes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"types": telemetry.Global.Types()})
+}
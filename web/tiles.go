@@ -0,0 +1,88 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"DroneBridge/internal/tiles"
+)
+
+// handleTile serves GET /api/tiles/{z}/{x}/{y}.png from the tile cache
+func handleTile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if !tiles.Global.Enabled() {
+		writeError(w, http.StatusNotFound, ErrFeatureDisabled, "Tile cache disabled")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/tiles/"), "/")
+	if len(parts) != 3 || !strings.HasSuffix(parts[2], ".png") {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, "Expected /api/tiles/{z}/{x}/{y}.png")
+		return
+	}
+	z, errZ := strconv.Atoi(parts[0])
+	x, errX := strconv.Atoi(parts[1])
+	y, errY := strconv.Atoi(strings.TrimSuffix(parts[2], ".png"))
+	if errZ != nil || errX != nil || errY != nil {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, "Tile coordinates must be integers")
+		return
+	}
+	if err := tiles.ValidTile(z, x, y); err != nil {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, err.Error())
+		return
+	}
+
+	data, source, err := tiles.Global.Get(z, x, y)
+	if err != nil {
+		// Not cached and no internet: the map shows a blank tile
+		writeError(w, http.StatusNotFound, ErrNotFound, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("X-Tile-Source", source)
+	w.Write(data)
+}
+
+// handleTilesStatus serves cache hit counters and pre-seed progress
+func handleTilesStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(tiles.Global.Snapshot())
+}
+
+// handleTilesSeed starts a background pre-seed of an area:
+// POST /api/tiles/seed {"minLat","minLon","maxLat","maxLon","minZoom","maxZoom"}
+func handleTilesSeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+		return
+	}
+	var req struct {
+		tiles.Area
+		MinZoom int `json:"minZoom"`
+		MaxZoom int `json:"maxZoom"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, "Invalid request body")
+		return
+	}
+	if err := tiles.Global.StartSeed(req.Area, req.MinZoom, req.MaxZoom); err != nil {
+		writeError(w, http.StatusBadRequest, ErrBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Seeding %d tiles", tiles.CountTiles(req.Area, req.MinZoom, req.MaxZoom)),
+		"status":  tiles.Global.Snapshot().Seed,
+	})
+}
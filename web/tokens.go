@@ -0,0 +1,218 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"DroneBridge/internal/audit"
+	"DroneBridge/internal/metrics"
+	"DroneBridge/internal/tokens"
+)
+
+type identityKey struct{}
+
+// tokensRequired rejects API requests without a valid token (tokens.required)
+var tokensRequired atomic.Bool
+
+// SetTokensRequired makes every API request need an issued token or the admin token
+func SetTokensRequired(required bool) {
+	tokensRequired.Store(required)
+}
+
+// requestIdentity returns who the request authenticated as (zero = no token)
+func requestIdentity(r *http.Request) tokens.Identity {
+	id, _ := r.Context().Value(identityKey{}).(tokens.Identity)
+	return id
+}
+
+// requestRole returns the role the request authenticated with ("" = none)
+func requestRole(r *http.Request) string {
+	return requestIdentity(r).Role
+}
+
+// guardedRoutes command the flight controller or change safety, privacy or link
+// state. Any method but GET/HEAD needs a token of at least the listed role, even
+// without tokens.required. An entry also covers the paths below it.
+var guardedRoutes = []struct {
+	path string
+	role string
+}{
+	// Stop or take over the vehicle, replace its firmware, suspend the failsafes
+	{"/api/emergency", tokens.RoleAdmin},
+	{"/api/firmware", tokens.RoleAdmin},
+	{"/api/control", tokens.RoleAdmin},
+	{"/api/maintenance/enter", tokens.RoleAdmin},
+	// Change who the drone trusts and what it reveals, or inject faults
+	{"/api/fc/pin", tokens.RoleAdmin},
+	{"/api/signing", tokens.RoleAdmin},
+	{"/api/debug/chaos", tokens.RoleAdmin},
+	{"/api/privacy", tokens.RoleAdmin},
+	{"/api/identity", tokens.RoleAdmin},
+	{"/api/schedule", tokens.RoleAdmin}, // Runs maintenance tasks (key renewal, diagnostics upload)
+	{"/api/tokens", tokens.RoleAdmin},
+	{"/api/v1/drone/api-key", tokens.RoleAdmin}, // Request, revoke or delete the router API key
+	// Fly, configure or actuate the vehicle and shape its link
+	{"/api/flight", tokens.RoleOperator},
+	{"/api/guided", tokens.RoleOperator}, // goto, orbit, ROI
+	{"/api/param/set", tokens.RoleOperator},
+	{"/api/param/set-batch", tokens.RoleOperator},
+	{"/api/param/profile", tokens.RoleOperator},
+	{"/api/calibration", tokens.RoleOperator},
+	{"/api/payload", tokens.RoleOperator},
+	{"/api/forwarding/policy", tokens.RoleOperator},
+	{"/api/maintenance/exit", tokens.RoleOperator},
+	{"/api/log/level", tokens.RoleOperator},
+	{"/api/discovery", tokens.RoleOperator},
+	{"/api/maintenance", tokens.RoleOperator}, // Service log; enter/exit are listed above
+	{"/api/stats/parse-errors", tokens.RoleOperator},
+	{"/api/tiles/seed", tokens.RoleOperator},
+}
+
+// guardedRole returns the role a guarded route needs for r ("" = not guarded)
+func guardedRole(r *http.Request) string {
+	path := r.URL.Path
+	if strings.HasPrefix(path, "/api/files") {
+		return tokens.RoleAdmin // Reads reach the file system too
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+		return ""
+	}
+	for _, g := range guardedRoutes {
+		if path == g.path || strings.HasPrefix(path, g.path+"/") {
+			return g.role
+		}
+	}
+	return ""
+}
+
+// requiredRole is the least role that may make a request ("" = no token needed)
+func requiredRole(r *http.Request) string {
+	path := r.URL.Path
+	if r.Method == http.MethodOptions || (!strings.HasPrefix(path, "/api/") && !strings.HasPrefix(path, "/ws/") && path != "/metrics") {
+		return "" // CORS preflight, dashboard files and orchestrator probes
+	}
+	if role := guardedRole(r); role != "" {
+		return role
+	}
+	switch {
+	case strings.HasPrefix(path, "/ws/"):
+		return tokens.RoleTelemetry // Sending to the FC is checked per connection
+	case strings.HasPrefix(path, "/api/tokens"):
+		return tokens.RoleAdmin
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		return tokens.RoleTelemetry
+	case strings.HasPrefix(path, "/api/camera/"):
+		return tokens.RoleCamera
+	default:
+		return tokens.RoleAdmin
+	}
+}
+
+// tokenGuard authenticates bearer tokens and enforces their role. Requests
+// without one pass unchanged unless tokens.required is set or the route is
+// guarded (guardedRoutes), so the dashboard keeps working. Handlers don't
+// repeat these checks.
+// files.api_token and control.api_token are scoped admin credentials for their routes.
+func tokenGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		need := requiredRole(r)
+		if need == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		bearer := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if bearer == "" && strings.HasPrefix(r.URL.Path, "/ws/") {
+			bearer = r.URL.Query().Get("token") // Browsers can't set headers on WebSocket requests
+		}
+		if id, ok := tokens.Global.Identify(bearer, r.URL.Path); ok {
+			if !tokens.Allows(id.Role, need) {
+				tokenError(w, http.StatusForbidden, fmt.Sprintf("%s token may not do this (needs %s)", id.Role, need))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
+			return
+		}
+		if tokens.IsToken(bearer) {
+			tokenError(w, http.StatusUnauthorized, "invalid or expired API token")
+			return
+		}
+		if tokensRequired.Load() {
+			tokenError(w, http.StatusUnauthorized, "API token required")
+			return
+		}
+		if guardedRole(r) != "" {
+			log.Printf("[WEB] Rejected %s %s from %s: %s token required", r.Method, r.URL.Path, r.RemoteAddr, need)
+			tokenError(w, http.StatusUnauthorized, fmt.Sprintf("%s token required for this route", need))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func tokenError(w http.ResponseWriter, code int, msg string) {
+	writeError(w, code, statusCode(code), msg)
+}
+
+// handleTokens manages API tokens (admin only): GET lists them, POST
+// {"name": "fleet-backend", "role": "telemetry", "expiresHours": 720} issues one
+// and returns its secret once, DELETE /api/tokens/<id> revokes one
+func handleTokens(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if requestRole(r) != tokens.RoleAdmin {
+		tokenError(w, http.StatusUnauthorized, "admin token required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(tokens.Global.List())
+	case http.MethodPost:
+		var req struct {
+			Name         string `json:"name"`
+			Role         string `json:"role"`
+			ExpiresHours int    `json:"expiresHours"` // 0 = never
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, ErrBadRequest, fmt.Sprintf("Invalid request body: %v", err))
+			return
+		}
+		t, secret, err := tokens.Global.Create(req.Name, req.Role, time.Duration(req.ExpiresHours)*time.Hour)
+		if err != nil {
+			tokenError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("[WEB] API token %s (%s, %s) created by %s", t.ID, t.Name, t.Role, r.RemoteAddr)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("API token '%s' (%s) created", t.Name, t.Role))
+		audit.Global.Record("tokens", "create", map[string]interface{}{"id": t.ID, "name": t.Name, "role": t.Role, "remote": r.RemoteAddr})
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"token":   t,
+			"secret":  secret, // Shown once; only its hash is stored
+		})
+	case http.MethodDelete:
+		id := strings.TrimPrefix(r.URL.Path, "/api/tokens/")
+		if id == "" || id == r.URL.Path {
+			tokenError(w, http.StatusBadRequest, "use DELETE /api/tokens/<id>")
+			return
+		}
+		if err := tokens.Global.Revoke(id); err != nil {
+			tokenError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		log.Printf("[WEB] API token %s revoked by %s", id, r.RemoteAddr)
+		metrics.Global.AddLog("INFO", fmt.Sprintf("API token %s revoked", id))
+		audit.Global.Record("tokens", "revoke", map[string]interface{}{"id": id, "remote": r.RemoteAddr})
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, ErrMethodNotAllowed, "Method not allowed")
+	}
+}
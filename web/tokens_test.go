@@ -0,0 +1,220 @@
+package web
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"DroneBridge/internal/tokens"
+)
+
+// serverRoutes walks the http.HandleFunc registrations of server.go and
+// returns each path with the methods its handler refers to. A handler that
+// neither checks the method nor reads the body is read-only (nil).
+func serverRoutes(t *testing.T) map[string][]string {
+	t.Helper()
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consts := map[string]string{}
+	funcs := map[string]*ast.FuncDecl{}
+	var server *ast.File
+	for name, f := range pkgs["web"].Files {
+		if strings.HasSuffix(name, "server.go") {
+			server = f
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					funcs[d.Name.Name] = d
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if vs, ok := spec.(*ast.ValueSpec); ok && d.Tok == token.CONST {
+						for i, n := range vs.Names {
+							if i < len(vs.Values) {
+								if s, ok := stringLit(vs.Values[i]); ok {
+									consts[n.Name] = s
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	routes := map[string][]string{}
+	ast.Inspect(server, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 2 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "HandleFunc" {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); !ok || x.Name != "http" {
+			return true
+		}
+		path, ok := stringLit(call.Args[0])
+		if !ok {
+			if id, isIdent := call.Args[0].(*ast.Ident); isIdent {
+				path, ok = consts[id.Name]
+			}
+		}
+		if !ok {
+			t.Fatalf("route %s: path is not a constant", fset.Position(call.Pos()))
+		}
+		routes[path] = handlerMethods(call.Args[1], funcs)
+		return true
+	})
+	return routes
+}
+
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// handlerMethods returns the http.MethodX constants a handler refers to, in
+// the call building it or in its body
+func handlerMethods(h ast.Expr, funcs map[string]*ast.FuncDecl) []string {
+	var nodes []ast.Node
+	switch e := h.(type) {
+	case *ast.FuncLit:
+		nodes = append(nodes, e.Body)
+	case *ast.Ident:
+		if fd := funcs[e.Name]; fd != nil {
+			nodes = append(nodes, fd.Body)
+		}
+	case *ast.CallExpr:
+		for _, a := range e.Args {
+			nodes = append(nodes, a)
+		}
+		if id, ok := e.Fun.(*ast.Ident); ok && funcs[id.Name] != nil {
+			nodes = append(nodes, funcs[id.Name].Body)
+		}
+	}
+	set := map[string]bool{}
+	readsBody := false
+	for _, n := range nodes {
+		ast.Inspect(n, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if sel.Sel.Name == "Body" {
+				readsBody = true
+			}
+			if x, ok := sel.X.(*ast.Ident); ok && x.Name == "http" && strings.HasPrefix(sel.Sel.Name, "Method") {
+				set[strings.ToUpper(strings.TrimPrefix(sel.Sel.Name, "Method"))] = true
+			}
+			return true
+		})
+	}
+	if len(set) == 0 {
+		if readsBody {
+			return []string{http.MethodPost} // Any method reaches the handler
+		}
+		return nil
+	}
+	var out []string
+	for m := range set {
+		out = append(out, m)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// openRoutes take non-GET requests without a token (unless tokens.required)
+var openRoutes = map[string]bool{
+	"/":                       true, // Dashboard files (CORS preflight)
+	"/api/param/request-list": true, // Only reads the parameters from the FC
+	"/ws/mavlink":             true, // Sending to the FC is checked per connection
+}
+
+func TestEveryStateChangingRouteIsGuarded(t *testing.T) {
+	routes := serverRoutes(t)
+	if len(routes) < 50 {
+		t.Fatalf("found only %d routes in server.go", len(routes))
+	}
+	for path, methods := range routes {
+		if openRoutes[path] {
+			continue
+		}
+		for _, m := range methods {
+			if m == http.MethodGet || m == http.MethodHead || m == http.MethodOptions {
+				continue
+			}
+			r := httptest.NewRequest(m, path, nil)
+			if guardedRole(r) == "" {
+				t.Errorf("%s %s is not in guardedRoutes", m, path)
+			}
+		}
+	}
+}
+
+func TestTokenGuard(t *testing.T) {
+	if err := tokens.Global.Load(filepath.Join(t.TempDir(), "tokens.json"), "bootstrap-secret"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tokens.Global.Load("", "") })
+	_, telemetry, _ := tokens.Global.Create("dash", tokens.RoleTelemetry, 0)
+	_, operator, _ := tokens.Global.Create("ops", tokens.RoleOperator, 0)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		bearer   string
+		required bool
+		want     int
+	}{
+		{"anonymous read", http.MethodGet, "/api/status", "", false, http.StatusOK},
+		{"anonymous read with tokens.required", http.MethodGet, "/api/status", "", true, http.StatusUnauthorized},
+		{"anonymous landing", http.MethodPost, "/api/flight/land", "", false, http.StatusUnauthorized},
+		{"anonymous task run", http.MethodPost, "/api/schedule", "", false, http.StatusUnauthorized},
+		{"anonymous identity change", http.MethodPut, "/api/identity", "", false, http.StatusUnauthorized},
+		{"anonymous profile write", http.MethodPost, "/api/param/profile", "", false, http.StatusUnauthorized},
+		{"anonymous beacon toggle", http.MethodPost, "/api/discovery", "", false, http.StatusUnauthorized},
+		{"telemetry landing", http.MethodPost, "/api/flight/land", telemetry, false, http.StatusForbidden},
+		{"operator landing", http.MethodPost, "/api/flight/land", operator, false, http.StatusOK},
+		{"operator beacon toggle", http.MethodPost, "/api/discovery", operator, false, http.StatusOK},
+		{"operator task run", http.MethodPost, "/api/schedule", operator, false, http.StatusForbidden},
+		{"operator identity change", http.MethodPut, "/api/identity", operator, false, http.StatusForbidden},
+		{"admin task run", http.MethodPost, "/api/schedule", "bootstrap-secret", false, http.StatusOK},
+		{"invalid token", http.MethodGet, "/api/status", "dbt_0000", false, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		SetTokensRequired(tt.required)
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.bearer != "" {
+			r.Header.Set("Authorization", "Bearer "+tt.bearer)
+		}
+		w := httptest.NewRecorder()
+		tokenGuard(ok).ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: %s %s = %d, want %d", tt.name, tt.method, tt.path, w.Code, tt.want)
+		}
+	}
+	SetTokensRequired(false)
+}
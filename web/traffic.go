@@ -0,0 +1,15 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"DroneBridge/internal/traffic"
+)
+
+// handleTraffic serves tracked ADS-B traffic with closest-point-of-approach data
+func handleTraffic(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(traffic.Global.Snapshot())
+}
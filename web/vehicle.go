@@ -0,0 +1,123 @@
+package web
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gomavlib/v3/pkg/dialects/common"
+)
+
+const earthRadius = 6371000.0 // meters
+
+// VehiclePosition is a global position reported by the FC
+type VehiclePosition struct {
+	Lat      float64   `json:"lat"`
+	Lon      float64   `json:"lon"`
+	AltMSL   float64   `json:"altMsl"`
+	AltRel   float64   `json:"altRel"` // Above home
+	Received time.Time `json:"received"`
+}
+
+// vehicleState holds the flight state the command APIs check before sending anything
+var (
+	vehicleMu   sync.RWMutex
+	vehicleType common.MAV_TYPE
+	autopilot   common.MAV_AUTOPILOT
+	flightMode  uint32 // HEARTBEAT custom_mode
+	position    *VehiclePosition
+	home        *VehiclePosition
+)
+
+// VehicleArmed reports whether the FC link is up and the vehicle armed, for
+// subsystems outside the web package that must not act in flight
+func VehicleArmed() (connected, armed bool) {
+	return bridge.IsConnected(), bridge.IsArmed()
+}
+
+// HandleFlightMode receives the vehicle type, autopilot and custom mode from Pixhawk heartbeats
+func HandleFlightMode(typ common.MAV_TYPE, ap common.MAV_AUTOPILOT, customMode uint32) {
+	vehicleMu.Lock()
+	defer vehicleMu.Unlock()
+	vehicleType = typ
+	autopilot = ap
+	flightMode = customMode
+}
+
+// currentFlightMode returns the decoded flight mode name ("" before the first heartbeat)
+func currentFlightMode() (string, uint32) {
+	vehicleMu.RLock()
+	defer vehicleMu.RUnlock()
+	if autopilot == 0 && vehicleType == 0 && flightMode == 0 {
+		return "", 0
+	}
+	return flightModeName(autopilot, vehicleType, flightMode), flightMode
+}
+
+// HandlePosition receives GLOBAL_POSITION_INT from the forwarder
+func HandlePosition(m *common.MessageGlobalPositionInt) {
+	vehicleMu.Lock()
+	defer vehicleMu.Unlock()
+	position = &VehiclePosition{
+		Lat:      float64(m.Lat) / 1e7,
+		Lon:      float64(m.Lon) / 1e7,
+		AltMSL:   float64(m.Alt) / 1000,
+		AltRel:   float64(m.RelativeAlt) / 1000,
+		Received: time.Now(),
+	}
+}
+
+// HandleHomePosition receives HOME_POSITION from the forwarder
+func HandleHomePosition(m *common.MessageHomePosition) {
+	vehicleMu.Lock()
+	defer vehicleMu.Unlock()
+	home = &VehiclePosition{
+		Lat:      float64(m.Latitude) / 1e7,
+		Lon:      float64(m.Longitude) / 1e7,
+		AltMSL:   float64(m.Altitude) / 1000,
+		Received: time.Now(),
+	}
+}
+
+// vehicleSnapshot returns copies of the current vehicle state (positions may be nil)
+func vehicleSnapshot() (typ common.MAV_TYPE, mode uint32, pos, homePos *VehiclePosition) {
+	vehicleMu.RLock()
+	defer vehicleMu.RUnlock()
+	if position != nil {
+		p := *position
+		pos = &p
+	}
+	if home != nil {
+		h := *home
+		homePos = &h
+	}
+	return vehicleType, flightMode, pos, homePos
+}
+
+// vehicleAutopilot returns the autopilot and vehicle type from the last heartbeat
+func vehicleAutopilot() (common.MAV_AUTOPILOT, common.MAV_TYPE) {
+	vehicleMu.RLock()
+	defer vehicleMu.RUnlock()
+	return autopilot, vehicleType
+}
+
+// isFixedWing reports whether ArduPlane mode numbers apply to this vehicle type
+func isFixedWing(typ common.MAV_TYPE) bool {
+	switch typ {
+	case common.MAV_TYPE_FIXED_WING, common.MAV_TYPE_VTOL_TAILSITTER_DUOROTOR, common.MAV_TYPE_VTOL_TAILSITTER_QUADROTOR,
+		common.MAV_TYPE_VTOL_TILTROTOR, common.MAV_TYPE_VTOL_FIXEDROTOR, common.MAV_TYPE_VTOL_TAILSITTER,
+		common.MAV_TYPE_VTOL_TILTWING:
+		return true
+	}
+	return false
+}
+
+// distanceMeters is the great-circle distance between two points
+func distanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	p1 := lat1 * math.Pi / 180
+	p2 := lat2 * math.Pi / 180
+	dp := (lat2 - lat1) * math.Pi / 180
+	dl := (lon2 - lon1) * math.Pi / 180
+	a := math.Sin(dp/2)*math.Sin(dp/2) + math.Cos(p1)*math.Cos(p2)*math.Sin(dl/2)*math.Sin(dl/2)
+	return 2 * earthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,103 @@
+package web
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/websocket"
+
+	"DroneBridge/internal/entitlements"
+	"DroneBridge/internal/tokens"
+	"DroneBridge/internal/wsproxy"
+)
+
+// eventConn sends JSON events as text messages next to the binary frames
+type eventConn struct {
+	*websocket.Conn
+}
+
+// WriteText implements wsproxy.TextWriter
+func (c eventConn) WriteText(data []byte) error {
+	return websocket.Message.Send(c.Conn, string(data))
+}
+
+var (
+	wsOriginsMu      sync.RWMutex
+	wsAllowedOrigins []string
+)
+
+// SetWSAllowedOrigins sets the browser origins besides the dashboard's own that
+// may open /ws/mavlink (web.ws_allowed_origins)
+func SetWSAllowedOrigins(origins []string) {
+	wsOriginsMu.Lock()
+	defer wsOriginsMu.Unlock()
+	wsAllowedOrigins = nil
+	for _, o := range origins {
+		if o = strings.TrimRight(strings.TrimSpace(o), "/"); o != "" {
+			wsAllowedOrigins = append(wsAllowedOrigins, o)
+		}
+	}
+}
+
+// wsOriginAllowed reports whether the WebSocket request comes from the
+// dashboard's own origin, a configured one, or a non-browser client (no Origin).
+// Browsers send cookies and cached credentials cross-site, so any other page
+// must not be able to open the socket.
+func wsOriginAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	wsOriginsMu.RLock()
+	defer wsOriginsMu.RUnlock()
+	for _, o := range wsAllowedOrigins {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleMAVLinkWS bridges raw MAVLink v2 frames over a WebSocket for browser
+// GCS clients: GET /ws/mavlink (binary messages, one or more frames each).
+// Clients see the Pixhawk telemetry the forwarding policy lets through. Frames
+// they send reach the Pixhawk only with an admin token, and then pass the same
+// role, safety and arbitration checks as the REST command routes; emergency
+// stops must go through /api/emergency. Browser pages on other origins are
+// refused unless listed in web.ws_allowed_origins. With ?events=1 session and API key events (see /api/events) arrive as JSON
+// text messages as well. Needs the relay entitlement.
+func handleMAVLinkWS(w http.ResponseWriter, r *http.Request) {
+	if !entitlements.Global.Allowed(entitlements.FeatureRelay) {
+		writeError(w, http.StatusForbidden, ErrFeatureDisabled, "MAVLink relay is not entitled for this drone")
+		return
+	}
+	if !wsOriginAllowed(r) {
+		writeError(w, http.StatusForbidden, ErrForbidden, "WebSocket origin not allowed")
+		return
+	}
+	role := requestRole(r)
+	canSend := role == tokens.RoleAdmin
+	withEvents := r.URL.Query().Get("events") == "1"
+
+	srv := websocket.Server{
+		// The origin was checked above
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			ws.PayloadType = websocket.BinaryFrame
+			if err := wsproxy.Global.Serve(eventConn{ws}, r.RemoteAddr, role, canSend, withEvents); err != nil {
+				log.Printf("[WEB] Rejected MAVLink WebSocket from %s: %v", r.RemoteAddr, err)
+			}
+		},
+	}
+	srv.ServeHTTP(w, r)
+}